@@ -0,0 +1,275 @@
+// admin.go: HTTP admin endpoint for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAdminKeyLimit is the most keys GET /keys returns when no limit is given
+const DefaultAdminKeyLimit = 1000
+
+// HandlerOption customizes the admin handler returned by Handler
+type HandlerOption func(*handlerOptions)
+
+// handlerOptions holds the settings applied by HandlerOption
+type handlerOptions struct {
+	readOnly   bool
+	showValues bool
+}
+
+// WithHandlerReadOnly leaves out the mutating routes (DELETE /entry/{key}, POST /clear and
+// POST /clear/expired)
+func WithHandlerReadOnly() HandlerOption {
+	return func(o *handlerOptions) { o.readOnly = true }
+}
+
+// WithHandlerValues makes GET /entry/{key} and GET /dump include values. Reading one with
+// /entry counts as a cache access, and values that cannot be encoded as JSON are reported
+// as an error.
+func WithHandlerValues() HandlerOption {
+	return func(o *handlerOptions) { o.showValues = true }
+}
+
+// AdminStats is the body of GET /stats
+type AdminStats struct {
+	Stats  CacheStats         `json:"stats"`
+	Shards []ShardStats       `json:"shards"`
+	Config AdminConfigSummary `json:"config"`
+	// HotKeys lists the most read keys when CacheConfig.TrackHotKeys is set
+	HotKeys []KeyFrequency `json:"hot_keys,omitempty"`
+}
+
+// AdminConfigSummary describes the settings a running cache uses
+type AdminConfigSummary struct {
+	EvictionPolicy    string `json:"eviction_policy"`
+	AdmissionPolicy   string `json:"admission_policy"`
+	CacheSize         int    `json:"cache_size"`
+	ShardCount        int    `json:"shard_count"`
+	TTL               string `json:"ttl"`
+	EnableCompression bool   `json:"enable_compression"`
+	MaxMemoryBytes    int64  `json:"max_memory_bytes"`
+}
+
+// AdminEntry is the body of GET /entry/{key}
+type AdminEntry struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+	Cost int64  `json:"cost"`
+	// ExpiresAt and TTL are omitted for entries that never expire
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	TTL       string      `json:"ttl,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	// Info is the rest of the entry's metadata (see StrategicCache.GetEntryInfo)
+	Info EntryInfo `json:"info"`
+}
+
+// AdminDumpRecord is one line of GET /dump
+type AdminDumpRecord struct {
+	Key string `json:"key"`
+	// ExpiresAt is omitted for entries that never expire
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	// Error explains why Value is missing when WithHandlerValues is set
+	Error string `json:"error,omitempty"`
+}
+
+// Handler returns an http.Handler exposing cache statistics and basic operations:
+//
+//	GET    /stats               CacheStats, shard stats and a config summary
+//	GET    /keys?prefix=&limit= live keys, sorted, at most limit (default DefaultAdminKeyLimit)
+//	GET    /entry/{key}         entry metadata (404 if absent)
+//	GET    /dump                every live entry as JSON lines of AdminDumpRecord
+//	GET    /explain/{key}?size= what Set would do with a value of size bytes (see Explain)
+//	DELETE /entry/{key}         removes the entry from memory, not from the Backend
+//	POST   /clear?shard=        removes every entry, or those of one shard (see ClearShard)
+//	POST   /clear/expired       removes the expired entries, reporting {"removed": n}
+//
+// Responses are JSON. Mount it under a prefix with http.StripPrefix, and put it behind
+// whatever authentication the service uses: the handler performs none.
+func Handler(sc *StrategicCache, opts ...HandlerOption) http.Handler {
+	var o handlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, sc.adminStats())
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		limit := DefaultAdminKeyLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeAdminError(w, http.StatusBadRequest, errors.New("limit must be a non-negative integer"))
+				return
+			}
+			limit = n
+		}
+		writeAdminJSON(w, http.StatusOK, sc.adminKeys(r.URL.Query().Get("prefix"), limit))
+	})
+	mux.HandleFunc("GET /entry/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		info, ok := sc.GetEntryInfo(key)
+		if !ok {
+			writeAdminError(w, http.StatusNotFound, ErrNotFound)
+			return
+		}
+		entry := AdminEntry{Key: key, Size: info.Size, Cost: info.Cost, Info: info}
+		if !info.ExpiresAt.IsZero() {
+			entry.ExpiresAt = &info.ExpiresAt
+			entry.TTL = info.ExpiresAt.Sub(sc.clock.Now()).Round(time.Millisecond).String()
+		}
+		if o.showValues {
+			value, err := sc.getLocal(sc.HashKey(key))
+			if err != nil {
+				writeAdminError(w, http.StatusNotFound, err)
+				return
+			}
+			entry.Value = value
+		}
+		writeAdminJSON(w, http.StatusOK, entry)
+	})
+	mux.HandleFunc("GET /dump", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		sc.writeAdminDump(w, o.showValues)
+	})
+	mux.HandleFunc("GET /explain/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		size, err := strconv.Atoi(r.URL.Query().Get("size"))
+		if err != nil || size < 0 {
+			writeAdminError(w, http.StatusBadRequest, errors.New("size must be a non-negative integer"))
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, sc.Explain(r.PathValue("key"), make([]byte, size)))
+	})
+	if !o.readOnly {
+		mux.HandleFunc("DELETE /entry/{key...}", func(w http.ResponseWriter, r *http.Request) {
+			if !sc.deleteLocal(sc.HashKey(r.PathValue("key"))) {
+				writeAdminError(w, http.StatusNotFound, ErrNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+		mux.HandleFunc("POST /clear", func(w http.ResponseWriter, r *http.Request) {
+			s := r.URL.Query().Get("shard")
+			if s == "" {
+				sc.Clear()
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			shard, err := strconv.Atoi(s)
+			if err != nil {
+				writeAdminError(w, http.StatusBadRequest, errors.New("shard must be an integer"))
+				return
+			}
+			if err := sc.ClearShard(shard); err != nil {
+				writeAdminError(w, http.StatusBadRequest, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+		mux.HandleFunc("POST /clear/expired", func(w http.ResponseWriter, r *http.Request) {
+			writeAdminJSON(w, http.StatusOK, map[string]int{"removed": sc.ClearExpired()})
+		})
+	}
+	return mux
+}
+
+// adminStats builds the body of GET /stats
+func (sc *StrategicCache) adminStats() AdminStats {
+	admission := sc.config.AdmissionPolicy
+	if admission == "" {
+		admission = "always"
+	}
+	return AdminStats{
+		Stats:  sc.GetStats(),
+		Shards: sc.ShardStats(),
+		Config: AdminConfigSummary{
+			EvictionPolicy:    sc.PolicyName(),
+			AdmissionPolicy:   admission,
+			CacheSize:         sc.config.CacheSize,
+			ShardCount:        int(sc.shardCount),
+			TTL:               sc.entryTTL().String(),
+			EnableCompression: sc.config.EnableCompression,
+			MaxMemoryBytes:    sc.config.MaxMemoryBytes,
+		},
+		HotKeys: sc.HotKeys(),
+	}
+}
+
+// adminKeys returns the sorted live keys starting with prefix, at most limit of them
+func (sc *StrategicCache) adminKeys(prefix string, limit int) map[string]interface{} {
+	var keys []string
+	for _, key := range sc.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	total := len(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	if keys == nil {
+		keys = []string{}
+	}
+	return map[string]interface{}{
+		"keys":      keys,
+		"count":     total,
+		"truncated": total > len(keys),
+	}
+}
+
+// writeAdminDump writes a line for every live entry, one shard at a time through Range,
+// so the dump is never held in memory whole. Values are left out unless withValues is set.
+func (sc *StrategicCache) writeAdminDump(w io.Writer, withValues bool) {
+	sc.Range(func(key string, value interface{}) bool {
+		record := AdminDumpRecord{Key: key}
+		if info, ok := sc.GetEntryInfo(key); ok && !info.ExpiresAt.IsZero() {
+			record.ExpiresAt = &info.ExpiresAt
+		}
+		if withValues {
+			record.Value = value
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			record.Value, record.Error = nil, err.Error()
+			data, _ = json.Marshal(record)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err == nil // Stop once the client has gone away
+	})
+}
+
+// writeAdminJSON writes v as a JSON response with the given status
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// writeAdminError writes err as a JSON error response
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
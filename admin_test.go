@@ -0,0 +1,243 @@
+// admin_test.go: Tests for the HTTP admin handler
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func adminRequest(t *testing.T, h http.Handler, method, path string, out interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// fillAdminCache stores the five users and one session the handler tests expect
+func fillAdminCache(cache *StrategicCache) {
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("user:%d", i), i)
+	}
+	cache.Set("session/a", "token")
+}
+
+func TestHandler_Stats(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	fillAdminCache(cache)
+	var stats AdminStats
+	if code := adminRequest(t, Handler(cache), "GET", "/stats", &stats); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if stats.Stats.Keys != 6 || len(stats.Shards) != 4 || stats.Config.EvictionPolicy != "lru" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestHandler_KeysAndEntry(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+			fillAdminCache(cache)
+			h := Handler(cache)
+
+			var keys struct {
+				Keys      []string `json:"keys"`
+				Count     int      `json:"count"`
+				Truncated bool     `json:"truncated"`
+			}
+			adminRequest(t, h, "GET", "/keys?prefix=user:&limit=2", &keys)
+			if keys.Count != 5 || len(keys.Keys) != 2 || !keys.Truncated || keys.Keys[0] != "user:0" {
+				t.Errorf("unexpected keys response: %+v", keys)
+			}
+
+			var entry AdminEntry
+			if code := adminRequest(t, h, "GET", "/entry/session/a", &entry); code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", code)
+			}
+			if entry.Key != "session/a" || entry.ExpiresAt == nil || entry.Value != nil {
+				t.Errorf("expected metadata without the value, got %+v", entry)
+			}
+			if entry.Info.Key != "session/a" || entry.Info.Size == 0 || entry.Info.ExpiresAt.IsZero() {
+				t.Errorf("expected the entry info, got %+v", entry.Info)
+			}
+			if code := adminRequest(t, h, "GET", "/entry/missing", nil); code != http.StatusNotFound {
+				t.Errorf("expected 404 for a missing key, got %d", code)
+			}
+		})
+	}
+}
+
+func TestHandler_Mutations(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+	fillAdminCache(cache)
+	h := Handler(cache)
+
+	if code := adminRequest(t, h, "DELETE", "/entry/user:1", nil); code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", code)
+	}
+	if _, ok := cache.Get("user:1"); ok {
+		t.Error("expected user:1 to be deleted")
+	}
+	if code := adminRequest(t, h, "DELETE", "/entry/user:1", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting a missing key, got %d", code)
+	}
+	var removed map[string]int
+	if code := adminRequest(t, h, "POST", "/clear/expired", &removed); code != http.StatusOK || removed["removed"] != 0 {
+		t.Errorf("expected 200 removing nothing, got %d %v", code, removed)
+	}
+	for _, bad := range []string{"/clear?shard=x", "/clear?shard=-1", "/clear?shard=4"} {
+		if code := adminRequest(t, h, "POST", bad, nil); code != http.StatusBadRequest {
+			t.Errorf("POST %s: expected 400, got %d", bad, code)
+		}
+	}
+	keys, inShard0 := cache.Len(), cache.ShardStats()[0].Keys
+	if code := adminRequest(t, h, "POST", "/clear?shard=0", nil); code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", code)
+	}
+	if n := cache.Len(); n != keys-inShard0 {
+		t.Errorf("expected only the %d keys of shard 0 cleared, got %d of %d keys left", inShard0, n, keys)
+	}
+	if code := adminRequest(t, h, "POST", "/clear", nil); code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", code)
+	}
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("expected an empty cache after /clear, got %v", keys)
+	}
+}
+
+func TestHandler_ReadOnlyAndValues(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	fillAdminCache(cache)
+	h := Handler(cache, WithHandlerReadOnly(), WithHandlerValues())
+
+	if code := adminRequest(t, h, "DELETE", "/entry/user:1", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for DELETE in read-only mode, got %d", code)
+	}
+	if code := adminRequest(t, h, "POST", "/clear", nil); code == http.StatusNoContent {
+		t.Error("expected /clear to be unavailable in read-only mode")
+	}
+	if _, ok := cache.Get("user:1"); !ok {
+		t.Error("expected user:1 to survive in read-only mode")
+	}
+
+	var entry AdminEntry
+	adminRequest(t, h, "GET", "/entry/session/a", &entry)
+	if entry.Value != "token" {
+		t.Errorf("expected the value with WithHandlerValues, got %+v", entry)
+	}
+}
+
+func TestHandler_Dump(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+			fillAdminCache(cache)
+			cache.Set("unencodable", math.Inf(1)) // JSON has no infinity
+
+			for _, withValues := range []bool{false, true} {
+				var opts []HandlerOption
+				if withValues {
+					opts = append(opts, WithHandlerValues())
+				}
+				rec := httptest.NewRecorder()
+				Handler(cache, opts...).ServeHTTP(rec, httptest.NewRequest("GET", "/dump", nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("expected 200, got %d", rec.Code)
+				}
+
+				records := map[string]AdminDumpRecord{}
+				dec := json.NewDecoder(rec.Body)
+				for dec.More() {
+					var r AdminDumpRecord
+					if err := dec.Decode(&r); err != nil {
+						t.Fatal(err)
+					}
+					records[r.Key] = r
+				}
+				if len(records) != len(cache.Keys()) {
+					t.Fatalf("expected %d records, got %d", len(cache.Keys()), len(records))
+				}
+				session := records["session/a"]
+				if session.ExpiresAt == nil {
+					t.Errorf("expected an expiration, got %+v", session)
+				}
+				if withValues && session.Value != "token" || !withValues && session.Value != nil {
+					t.Errorf("values=%v: unexpected value %+v", withValues, session)
+				}
+				if r := records["unencodable"]; withValues && r.Error == "" {
+					t.Errorf("expected an error for an unencodable value, got %+v", r)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Explain(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", MaxValueSize: 1024})
+	t.Cleanup(cache.Close)
+	h := Handler(cache, WithHandlerReadOnly())
+
+	var e Explanation
+	if code := adminRequest(t, h, "GET", "/explain/user:1?size=100", &e); code != http.StatusOK || !e.Stored || e.Size != 100 || len(e.Steps) == 0 {
+		t.Errorf("expected a stored value of 100 bytes, got %d %+v", code, e)
+	}
+	if code := adminRequest(t, h, "GET", "/explain/user:1?size=4096", &e); code != http.StatusOK || e.Stored || e.Error != ErrValueTooLarge.Error() {
+		t.Errorf("expected ErrValueTooLarge, got %d %+v", code, e)
+	}
+	if _, ok := cache.Get("user:1"); ok {
+		t.Error("expected explain to store nothing")
+	}
+	if code := adminRequest(t, h, "GET", "/explain/user:1?size=big", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad size, got %d", code)
+	}
+}
@@ -0,0 +1,150 @@
+// admission_test.go: Tests for admission policies on the sharded cache path
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// hotSetHitRate warms a hot working set, then keeps using it while a one-pass scan
+// streams through the cache, and returns the hit rate on hot keys during the scan
+func hotSetHitRate(t *testing.T, admission string) float64 {
+	t.Helper()
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       100,
+		ShardCount:      1,
+		EvictionPolicy:  "lru",
+		AdmissionPolicy: admission,
+	})
+	defer cache.Close()
+
+	// Cache-aside access: a miss is followed by a write
+	access := func(key string) bool {
+		if _, ok := cache.Get(key); ok {
+			return true
+		}
+		cache.Set(key, key)
+		return false
+	}
+
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 80; i++ {
+			access(fmt.Sprintf("hot%d", i))
+		}
+	}
+
+	hits := 0
+	for i := 0; i < 2000; i++ {
+		access(fmt.Sprintf("scan%d", i))
+		if access(fmt.Sprintf("hot%d", i%80)) {
+			hits++
+		}
+	}
+	return float64(hits) / 2000
+}
+
+func TestTinyLFUAdmission_ScanDoesNotFlushHotSet(t *testing.T) {
+	lru := hotSetHitRate(t, "always")
+	tinylfu := hotSetHitRate(t, "tinylfu")
+	t.Logf("hot set hit rate during scan: lru=%.2f tinylfu=%.2f", lru, tinylfu)
+
+	if lru > 0.5 {
+		t.Errorf("expected the scan to flush plain LRU, hit rate %.2f", lru)
+	}
+	if tinylfu < 0.8 {
+		t.Errorf("expected tinylfu admission to keep the hot set, hit rate %.2f", tinylfu)
+	}
+}
+
+func TestTinyLFUAdmission_AdmitsWhileShardHasRoom(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       100,
+		ShardCount:      1,
+		EvictionPolicy:  "lru",
+		AdmissionPolicy: "tinylfu",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		if !cache.Set(fmt.Sprintf("key%d", i), i) {
+			t.Fatalf("expected key%d to be admitted while the shard has room", i)
+		}
+	}
+	if cache.shards[0].sketch == nil {
+		t.Error("expected a frequency sketch on the shard")
+	}
+
+	// Updates of existing keys are never subject to admission
+	if !cache.Set("key0", "updated") {
+		t.Error("expected update of an existing key to succeed")
+	}
+}
+
+func TestSizeAwareAdmission_Allow(t *testing.T) {
+	fill := 0.95
+	policy := &SizeAwareAdmissionPolicy{
+		MaxSize:     1024,
+		Utilization: 0.9,
+		FillRatio:   func(string) float64 { return fill },
+	}
+	small, large := strings.Repeat("s", 100), strings.Repeat("L", 8192)
+
+	if !policy.Allow("small", small) {
+		t.Error("expected a small value to be admitted at 95% utilization")
+	}
+	if policy.Allow("large", large) {
+		t.Error("expected a large value to be rejected at 95% utilization")
+	}
+
+	fill = 0.5
+	if !policy.Allow("large", large) {
+		t.Error("expected a large value to be admitted at 50% utilization")
+	}
+}
+
+func TestSizeAwareAdmission_ShardUnderPressure(t *testing.T) {
+	for _, eviction := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(eviction, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:        true,
+				CacheSize:            100,
+				ShardCount:           1,
+				EvictionPolicy:       eviction,
+				AdmissionPolicy:      "size-aware",
+				SizeAwareMaxSize:     1024,
+				SizeAwareUtilization: 0.75,
+			})
+			defer cache.Close()
+
+			large := strings.Repeat("L", 8192)
+			if !cache.Set("early-large", large) {
+				t.Fatal("expected a large value to be admitted into an empty shard")
+			}
+			// W-TinyLFU fills its protected segment only on reuse, so stay clear of 100%
+			for i := 0; i < 94; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+			if fill := cache.shardFillRatio("small"); fill <= 0.75 {
+				t.Fatalf("expected the shard to be under pressure, fill ratio %.2f", fill)
+			}
+
+			if !cache.Set("small", "small") {
+				t.Error("expected a small value to be admitted under pressure")
+			}
+			if cache.Set("large", large) {
+				t.Error("expected a large value to be rejected under pressure")
+			}
+			if _, ok := cache.Get("large"); ok {
+				t.Error("rejected value should not be cached")
+			}
+		})
+	}
+}
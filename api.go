@@ -7,6 +7,7 @@
 package metis
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -24,7 +25,7 @@ type Stats struct {
 }
 
 // New creates a new cache with automatic configuration loading
-// Priority: Go config > JSON config > defaults
+// Priority: Go config > JSON config > defaults, then $METIS_* environment overrides
 func New() *Cache {
 	config := loadConfig()
 	return &Cache{
@@ -61,18 +62,11 @@ func (c *Cache) Size() int {
 // Stats returns simplified cache statistics
 func (c *Cache) Stats() Stats {
 	s := c.strategic.GetStats()
-
-	total := s.Hits + s.Misses
-	hitRate := 0.0
-	if total > 0 {
-		hitRate = float64(s.Hits) / float64(total) * 100.0
-	}
-
 	return Stats{
 		Size:    s.Keys,
 		Hits:    s.Hits,
 		Misses:  s.Misses,
-		HitRate: hitRate,
+		HitRate: s.HitRate() * 100,
 	}
 }
 
@@ -81,6 +75,12 @@ func (c *Cache) Close() {
 	c.strategic.Close()
 }
 
+// CloseWithContext closes the cache, returning ctx.Err() if background work does not
+// finish before ctx ends (see StrategicCache.CloseWithContext)
+func (c *Cache) CloseWithContext(ctx context.Context) error {
+	return c.strategic.CloseWithContext(ctx)
+}
+
 // String returns a human-readable representation of cache stats
 func (s Stats) String() string {
 	return fmt.Sprintf("Cache Stats: %d items, %d hits, %d misses, %.1f%% hit rate",
@@ -0,0 +1,717 @@
+// arc.go: Adaptive Replacement Cache (ARC) implementation for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ARC implements the Adaptive Replacement Cache eviction policy.
+// Each shard keeps two resident lists (T1: seen once, T2: seen at least twice)
+// and two ghost lists (B1, B2) holding only the keys recently evicted from them.
+// Ghost hits move the adaptive target p, the preferred size of T1.
+type ARC struct {
+	shards    []*ARCShard
+	shardMask uint32
+	ttl       atomic.Int64 // time.Duration; zero or negative disables expiration
+}
+
+// ARCShard is a single ARC instance guarding its own key space
+type ARCShard struct {
+	mu       sync.Mutex
+	capacity int
+	p        int // Adaptive target size of T1
+	t1       *list.List
+	t2       *list.List
+	b1       *list.List
+	b2       *list.List
+	items    map[string]*list.Element // Keys resident in or remembered by any list
+	ttl      atomic.Int64             // time.Duration, set by ARC.SetTTL
+	bytes    int64
+	hits     int64
+	misses   int64
+	evicted  int64
+	expired  int64
+	// idleExpired counts entries dropped because they went unread longer than maxIdle
+	idleExpired int64
+	maxIdle     int64 // Idle timeout in nanoseconds, 0 = none (see ARC.setMaxIdle)
+	pinned      int   // Resident entries exempted from eviction (see ARC.setPinned)
+	// count is the number of resident entries (T1 and T2), read by Size without mu
+	count atomic.Int64
+	// evictQueue holds evicted entries until mu is released (see StrategicCache.OnEvict)
+	evictQueue evictQueue
+	clock      Clock // Stamps and checks expiration, set by ARC.setClock
+	// lazyCleanup is how many entries each write examines for expiration (see ARC.setLazyCleanup)
+	lazyCleanup int
+	// window counts recent hits, misses and evictions (see ARC.setStatsWindow)
+	window *statsWindow
+}
+
+// arcEntry is the element payload; value is nil for ghost entries
+type arcEntry struct {
+	key       string
+	value     interface{}
+	size      int
+	expiresAt int64 // UnixNano, 0 = never
+	slide     int64 // Sliding TTL in nanoseconds, 0 = fixed expiration
+	// accessedAt is the last read or write in UnixNano, tracked while the shard has a maxIdle
+	accessedAt int64
+	pinned     bool // Exempt from eviction and expiration (see ARC.setPinned)
+	list       *list.List
+}
+
+// expiry reports whether entry has expired at now (UnixNano) and, if so, whether
+// its idle timeout rather than its TTL expired it. Pinned entries never expire.
+func (shard *ARCShard) expiry(entry *arcEntry, now int64) (expired, idle bool) {
+	if entry.pinned {
+		return false, false
+	}
+	idle = idleFirst(now, entry.accessedAt, entry.expiresAt, shard.maxIdle)
+	return idle || (entry.expiresAt > 0 && now > entry.expiresAt), idle
+}
+
+// NewARC creates an ARC cache holding up to maxSize entries split across shards
+func NewARC(maxSize, shardCount int) *ARC {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	if shardCount <= 0 {
+		shardCount = 1
+	}
+	// Round to a power of two for mask-based shard selection
+	shardCount = nextPowerOf2(shardCount)
+
+	shardSize := max(1, maxSize/shardCount)
+	arc := &ARC{
+		shards:    make([]*ARCShard, shardCount),
+		shardMask: uint32(shardCount - 1), // nosec G115 - shardCount is a small positive power of two
+	}
+	for i := range arc.shards {
+		arc.shards[i] = &ARCShard{
+			capacity: shardSize,
+			t1:       list.New(),
+			t2:       list.New(),
+			b1:       list.New(),
+			b2:       list.New(),
+			items:    make(map[string]*list.Element, shardSize*2),
+			clock:    realClock{},
+		}
+	}
+	return arc
+}
+
+// SetTTL sets the time-to-live for entries stored from now on (zero or negative disables expiration).
+// It is safe to call while the cache is in use; existing entries keep their expiration.
+func (arc *ARC) SetTTL(ttl time.Duration) {
+	arc.ttl.Store(int64(ttl))
+	for _, shard := range arc.shards {
+		shard.ttl.Store(int64(ttl))
+	}
+}
+
+// setClock replaces the time source of every shard; it must be called before the cache is used
+func (arc *ARC) setClock(c Clock) {
+	for _, shard := range arc.shards {
+		shard.clock = c
+	}
+}
+
+// setMaxIdle makes every shard expire entries left unread for longer than d (0 = never);
+// it must be called before the cache is used
+func (arc *ARC) setMaxIdle(d time.Duration) {
+	for _, shard := range arc.shards {
+		shard.maxIdle = int64(d)
+	}
+}
+
+// setLazyCleanup makes every write to a shard examine up to n entries for expiration
+// (0 = none, see CacheConfig.DisableBackgroundCleanup); it must be called before the cache is used
+func (arc *ARC) setLazyCleanup(n int) {
+	for _, shard := range arc.shards {
+		shard.lazyCleanup = n
+	}
+}
+
+// setStatsWindow gives every shard windowed counters made by newWindow (see
+// StrategicCache.WindowedStats); it must be called before the cache is used
+func (arc *ARC) setStatsWindow(newWindow func() *statsWindow) {
+	for _, shard := range arc.shards {
+		shard.window = newWindow()
+	}
+}
+
+// setPinned marks or unmarks a live resident entry as exempt from eviction and expiration,
+// reporting whether the key was found (see StrategicCache.Pin)
+func (arc *ARC) setPinned(key string, pinned bool) bool {
+	shard := arc.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, exists := shard.items[key]
+	if !exists {
+		return false
+	}
+	entry := elem.Value.(*arcEntry)
+	if entry.list != shard.t1 && entry.list != shard.t2 {
+		return false
+	}
+	if expired, _ := shard.expiry(entry, shard.clock.Now().UnixNano()); expired {
+		return false
+	}
+	if entry.pinned != pinned {
+		entry.pinned = pinned
+		if pinned {
+			shard.pinned++
+		} else {
+			shard.pinned--
+		}
+	}
+	return true
+}
+
+// setEvictHandler installs the handler for entries evicted from any shard (see StrategicCache.OnEvict)
+func (arc *ARC) setEvictHandler(fn func(evictedEntry)) {
+	for _, shard := range arc.shards {
+		shard.evictQueue.setHandler(fn)
+	}
+}
+
+// getShard selects the shard for a key
+func (arc *ARC) getShard(key string) *ARCShard {
+	return arc.shards[arc.shardIndex(key)]
+}
+
+// shardIndex returns the index of the shard for a key using FNV-1a
+func (arc *ARC) shardIndex(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h & arc.shardMask
+}
+
+// Get retrieves a value from the cache
+func (arc *ARC) Get(key string) (interface{}, bool) {
+	return arc.getAt(arc.shardIndex(key), key)
+}
+
+// getAt is Get on the shard at index, the key's shardIndex
+func (arc *ARC) getAt(index uint32, key string) (interface{}, bool) {
+	if key == "" {
+		return nil, false
+	}
+	return arc.shards[index].Get(key)
+}
+
+// Set stores a value in the cache
+func (arc *ARC) Set(key string, value interface{}) bool {
+	if key == "" {
+		return false
+	}
+	return arc.getShard(key).Set(key, value)
+}
+
+// setExpiring stores a value that expires at expiresAt (UnixNano); 0 applies the cache TTL.
+// A positive slide (nanoseconds) restarts the TTL on every read.
+func (arc *ARC) setExpiring(key string, value interface{}, expiresAt, slide int64) bool {
+	return arc.setExpiringAt(arc.shardIndex(key), key, value, expiresAt, slide)
+}
+
+// setExpiringAt is setExpiring on the shard at index, the key's shardIndex
+func (arc *ARC) setExpiringAt(index uint32, key string, value interface{}, expiresAt, slide int64) bool {
+	if key == "" {
+		return false
+	}
+	return arc.shards[index].setExpiring(key, value, expiresAt, slide)
+}
+
+// Delete removes a key from the cache
+func (arc *ARC) Delete(key string) bool {
+	return arc.deleteAt(arc.shardIndex(key), key)
+}
+
+// deleteAt is Delete on the shard at index, the key's shardIndex
+func (arc *ARC) deleteAt(index uint32, key string) bool {
+	if key == "" {
+		return false
+	}
+	return arc.shards[index].Delete(key)
+}
+
+// Clear removes all entries and ghost keys from the cache
+func (arc *ARC) Clear() {
+	for _, shard := range arc.shards {
+		shard.Clear()
+	}
+}
+
+// RemoveExpired sweeps every shard, dropping expired entries, and returns how many were removed
+func (arc *ARC) RemoveExpired() int {
+	removed := 0
+	for _, shard := range arc.shards {
+		removed += shard.RemoveExpired()
+	}
+	return removed
+}
+
+// Size returns the number of resident entries, summing a counter per shard without locks
+func (arc *ARC) Size() int {
+	var total int64
+	for _, shard := range arc.shards {
+		total += shard.count.Load()
+	}
+	return int(total)
+}
+
+// Len returns the number of resident entries; it is the same as Size
+func (arc *ARC) Len() int {
+	return arc.Size()
+}
+
+// Close is a no-op: ARC runs no background goroutines. It lets ARC satisfy Cacher.
+func (arc *ARC) Close() {}
+
+// FillRatio returns the resident entries of the shard holding key over its capacity
+func (arc *ARC) FillRatio(key string) float64 {
+	shard := arc.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return float64(shard.t1.Len()+shard.t2.Len()) / float64(shard.capacity)
+}
+
+// Keys returns the keys of all live resident entries, in no particular order
+func (arc *ARC) Keys() []string {
+	var keys []string
+	for _, shard := range arc.shards {
+		for _, e := range shard.entries() {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Range calls fn for each live resident entry until fn returns false. Each shard is
+// copied under its lock before fn runs, so fn may safely call back into the cache.
+func (arc *ARC) Range(fn func(key string, value interface{}) bool) {
+	for _, shard := range arc.shards {
+		for _, e := range shard.entries() {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// entries copies the shard's unexpired T1 and T2 entries; ghosts hold no value
+func (shard *ARCShard) entries() []entrySnapshot {
+	now := shard.clock.Now().UnixNano()
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	items := make([]entrySnapshot, 0, shard.t1.Len()+shard.t2.Len())
+	for _, l := range []*list.List{shard.t2, shard.t1} {
+		for elem := l.Front(); elem != nil; elem = elem.Next() {
+			entry := elem.Value.(*arcEntry)
+			if expired, _ := shard.expiry(entry, now); expired {
+				continue
+			}
+			items = append(items, entrySnapshot{key: entry.key, value: entry.value, expiresAt: entry.expiresAt})
+		}
+	}
+	return items
+}
+
+// Target returns the sum of the adaptive T1 targets across shards
+func (arc *ARC) Target() int {
+	total := 0
+	for _, shard := range arc.shards {
+		shard.mu.Lock()
+		total += shard.p
+		shard.mu.Unlock()
+	}
+	return total
+}
+
+// GetStats returns statistics in the same format as StrategicCache
+func (arc *ARC) GetStats() CacheStats {
+	var stats CacheStats
+	for _, s := range arc.ShardStats() {
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Keys += s.Keys
+		stats.Evictions += s.Evictions
+		stats.Expirations += s.Expirations
+		stats.IdleExpirations += s.IdleExpirations
+		stats.Pinned += s.Pinned
+		stats.MemoryBytes += s.MemoryBytes
+		stats.TotalCost += s.TotalCost
+		stats.ARCTarget += s.ARCTarget
+	}
+	stats.Size = int64(stats.Keys)
+	return stats
+}
+
+// ResetStats zeroes the hit, miss, eviction and expiration counters of every shard,
+// leaving the cached entries and the adaptive target untouched
+func (arc *ARC) ResetStats() {
+	for _, shard := range arc.shards {
+		shard.mu.Lock()
+		shard.hits = 0
+		shard.misses = 0
+		shard.evicted = 0
+		shard.expired = 0
+		shard.idleExpired = 0
+		shard.mu.Unlock()
+	}
+}
+
+// ShardStats returns per-shard statistics in the same format as StrategicCache
+func (arc *ARC) ShardStats() []ShardStats {
+	stats := make([]ShardStats, len(arc.shards))
+	for i, shard := range arc.shards {
+		shard.mu.Lock()
+		keys := shard.t1.Len() + shard.t2.Len()
+		stats[i] = ShardStats{
+			Index:           i,
+			Keys:            keys,
+			Hits:            shard.hits,
+			Misses:          shard.misses,
+			Evictions:       shard.evicted,
+			Expirations:     shard.expired,
+			IdleExpirations: shard.idleExpired,
+			Pinned:          shard.pinned,
+			MemoryBytes:     shard.bytes,
+			TotalCost:       int64(keys),
+			ARCTarget:       int64(shard.p),
+		}
+		shard.mu.Unlock()
+	}
+	return stats
+}
+
+// Stats returns detailed ARC statistics, including list lengths for tuning
+func (arc *ARC) Stats() map[string]interface{} {
+	var t1, t2, b1, b2, p int
+	for _, shard := range arc.shards {
+		shard.mu.Lock()
+		t1 += shard.t1.Len()
+		t2 += shard.t2.Len()
+		b1 += shard.b1.Len()
+		b2 += shard.b2.Len()
+		p += shard.p
+		shard.mu.Unlock()
+	}
+	return map[string]interface{}{
+		"shards": len(arc.shards),
+		"p":      p,
+		"t1":     t1,
+		"t2":     t2,
+		"b1":     b1,
+		"b2":     b2,
+	}
+}
+
+// Get retrieves a value from the shard, promoting hits to T2
+func (shard *ARCShard) Get(key string) (interface{}, bool) {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, exists := shard.items[key]
+	if !exists {
+		shard.misses++
+		if shard.window != nil {
+			shard.window.miss()
+		}
+		return nil, false
+	}
+	entry := elem.Value.(*arcEntry)
+	if entry.list != shard.t1 && entry.list != shard.t2 {
+		shard.misses++ // Ghost keys hold no value
+		if shard.window != nil {
+			shard.window.miss()
+		}
+		return nil, false
+	}
+	now := shard.clock.Now().UnixNano()
+	if expired, idle := shard.expiry(entry, now); expired {
+		shard.drop(elem)
+		if idle {
+			shard.idleExpired++
+		} else {
+			shard.expired++
+		}
+		shard.misses++
+		if shard.window != nil {
+			shard.window.miss()
+		}
+		return nil, false
+	}
+
+	entry.accessedAt = now
+	if entry.slide > 0 {
+		entry.expiresAt = now + entry.slide
+	}
+	shard.moveTo(elem, shard.t2)
+	shard.hits++
+	if shard.window != nil {
+		shard.window.hit()
+	}
+	return entry.value, true
+}
+
+// Set stores a value in the shard following the ARC replacement rules
+func (shard *ARCShard) Set(key string, value interface{}) bool {
+	return shard.setExpiring(key, value, 0, 0)
+}
+
+// setExpiring stores a value in the shard expiring at expiresAt (UnixNano, 0 = the shard TTL)
+// and sliding by slide nanoseconds on each read (0 = fixed)
+func (shard *ARCShard) setExpiring(key string, value interface{}, expiresAt, slide int64) bool {
+	attrs := nodeAttrs{size: calculateSize(value), expiresAt: expiresAt, slide: slide}
+	if ttl := time.Duration(shard.ttl.Load()); expiresAt == 0 && ttl > 0 {
+		attrs.expiresAt = shard.clock.Now().Add(ttl).UnixNano()
+	}
+
+	defer shard.evictQueue.flush() // Runs after the unlock below
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.lazyCleanup > 0 {
+		shard.reclaimExpiredLocked(shard.lazyCleanup)
+	}
+	return shard.setLocked(key, value, attrs)
+}
+
+// reclaimExpiredLocked examines up to n entries, ghosts included, in map order, drops
+// the expired ones and returns how many it dropped. The caller must hold mu.
+func (shard *ARCShard) reclaimExpiredLocked(n int) int {
+	now := shard.clock.Now().UnixNano()
+	removed := 0
+	for _, elem := range shard.items {
+		if n--; n < 0 {
+			break
+		}
+		entry := elem.Value.(*arcEntry)
+		if entry.list != shard.t1 && entry.list != shard.t2 {
+			continue
+		}
+		if expired, idle := shard.expiry(entry, now); expired {
+			shard.drop(elem)
+			if idle {
+				shard.idleExpired++
+			} else {
+				shard.expired++
+			}
+			removed++
+		}
+	}
+	return removed
+}
+
+// RemoveExpired sweeps the shard, dropping every expired entry, and returns how many were removed
+func (shard *ARCShard) RemoveExpired() int {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return shard.reclaimExpiredLocked(len(shard.items))
+}
+
+// setBatch stores items under a single acquisition of the shard lock; ARC has no
+// admission filter, so every item is stored and item.frequency is ignored
+func (shard *ARCShard) setBatch(items []warmItem) int {
+	defer shard.evictQueue.flush() // Runs after the unlock below
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	for _, item := range items {
+		shard.setLocked(item.key, item.value, item.attrs)
+	}
+	return len(items)
+}
+
+// setLocked stores a sized value in T1 or T2, adapting to ghost hits. The caller must hold mu.
+func (shard *ARCShard) setLocked(key string, value interface{}, attrs nodeAttrs) bool {
+	if shard.maxIdle > 0 {
+		attrs.accessedAt = shard.clock.Now().UnixNano()
+	}
+	if elem, exists := shard.items[key]; exists {
+		entry := elem.Value.(*arcEntry)
+		switch entry.list {
+		case shard.t1, shard.t2:
+			// Resident: update in place and treat as a repeated access
+			shard.bytes += int64(attrs.size - entry.size)
+			entry.value, entry.size, entry.expiresAt, entry.slide, entry.accessedAt = value, attrs.size, attrs.expiresAt, attrs.slide, attrs.accessedAt
+			shard.moveTo(elem, shard.t2)
+			return true
+		case shard.b1:
+			// Recency ghost hit: grow T1's share
+			shard.p = min(shard.capacity, shard.p+max(1, shard.b2.Len()/max(1, shard.b1.Len())))
+			shard.replace(false)
+		case shard.b2:
+			// Frequency ghost hit: shrink T1's share
+			shard.p = max(0, shard.p-max(1, shard.b1.Len()/max(1, shard.b2.Len())))
+			shard.replace(true)
+		}
+		entry.value, entry.size, entry.expiresAt, entry.slide, entry.accessedAt = value, attrs.size, attrs.expiresAt, attrs.slide, attrs.accessedAt
+		shard.bytes += int64(attrs.size)
+		shard.moveTo(elem, shard.t2)
+		return true
+	}
+
+	// Brand new key: keep |T1|+|B1| <= c and the whole directory <= 2c
+	l1 := shard.t1.Len() + shard.b1.Len()
+	total := l1 + shard.t2.Len() + shard.b2.Len()
+	if l1 >= shard.capacity {
+		if shard.t1.Len() < shard.capacity {
+			shard.drop(shard.b1.Back())
+			shard.replace(false)
+		} else if victim := shard.oldestUnpinned(shard.t1); victim != nil {
+			entry := victim.Value.(*arcEntry)
+			shard.evictQueue.push(evictedEntry{key: entry.key, value: entry.value})
+			shard.drop(victim)
+			shard.evicted++
+			if shard.window != nil {
+				shard.window.evict()
+			}
+		}
+	} else if total >= shard.capacity {
+		if total >= 2*shard.capacity {
+			shard.drop(shard.b2.Back())
+		}
+		shard.replace(false)
+	}
+
+	entry := &arcEntry{key: key, value: value, size: attrs.size, expiresAt: attrs.expiresAt, slide: attrs.slide, accessedAt: attrs.accessedAt, list: shard.t1}
+	shard.items[key] = shard.t1.PushFront(entry)
+	shard.count.Add(1)
+	shard.bytes += int64(attrs.size)
+	return true
+}
+
+// Delete removes a key from the shard, forgetting any ghost entry as well
+func (shard *ARCShard) Delete(key string) bool {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, exists := shard.items[key]
+	if !exists {
+		return false
+	}
+	resident := shard.resident(elem.Value.(*arcEntry).list)
+	shard.drop(elem)
+	return resident
+}
+
+// Clear removes all entries and resets the adaptive target
+func (shard *ARCShard) Clear() {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.t1.Init()
+	shard.t2.Init()
+	shard.b1.Init()
+	shard.b2.Init()
+	shard.items = make(map[string]*list.Element, shard.capacity*2)
+	shard.count.Store(0)
+	shard.p = 0
+	shard.bytes = 0
+	shard.hits = 0
+	shard.misses = 0
+	shard.evicted = 0
+	shard.expired = 0
+	shard.idleExpired = 0
+	shard.pinned = 0
+}
+
+// replace evicts the LRU entry of T1 or T2 into its ghost list.
+// inB2 reports whether the key being inserted was found in B2. The caller must hold mu.
+func (shard *ARCShard) replace(inB2 bool) {
+	if victim, ghost := shard.replaceVictim(inB2, shard.p); victim != nil {
+		shard.demote(victim, ghost)
+	}
+}
+
+// replaceVictim returns the entry replace would evict with T1's target at p, and the
+// ghost list it would go to, or nil when every entry is pinned. The caller must hold mu.
+func (shard *ARCShard) replaceVictim(inB2 bool, p int) (*list.Element, *list.List) {
+	t1Len := shard.t1.Len()
+	t1Victim, t2Victim := shard.oldestUnpinned(shard.t1), shard.oldestUnpinned(shard.t2)
+	if t1Victim != nil && (t1Len > p || (inB2 && t1Len == p)) {
+		return t1Victim, shard.b1
+	} else if t2Victim != nil {
+		return t2Victim, shard.b2
+	}
+	return t1Victim, shard.b1
+}
+
+// oldestUnpinned returns the least recently used unpinned entry of a resident list,
+// or nil when every entry is pinned. The caller must hold mu.
+func (shard *ARCShard) oldestUnpinned(l *list.List) *list.Element {
+	for elem := l.Back(); elem != nil; elem = elem.Prev() {
+		if !elem.Value.(*arcEntry).pinned {
+			return elem
+		}
+	}
+	return nil
+}
+
+// demote turns a resident entry into a ghost on the given list. The caller must hold mu.
+func (shard *ARCShard) demote(elem *list.Element, ghost *list.List) {
+	entry := elem.Value.(*arcEntry)
+	shard.evictQueue.push(evictedEntry{key: entry.key, value: entry.value})
+	shard.bytes -= int64(entry.size)
+	entry.value, entry.size, entry.expiresAt = nil, 0, 0
+	shard.moveTo(elem, ghost)
+	shard.evicted++
+	if shard.window != nil {
+		shard.window.evict()
+	}
+}
+
+// moveTo moves an element to the front of target, updating its map pointer.
+// The caller must hold mu.
+func (shard *ARCShard) moveTo(elem *list.Element, target *list.List) {
+	entry := elem.Value.(*arcEntry)
+	if entry.list == target {
+		target.MoveToFront(elem)
+		return
+	}
+	if wasResident, resident := shard.resident(entry.list), shard.resident(target); wasResident != resident {
+		if resident {
+			shard.count.Add(1)
+		} else {
+			shard.count.Add(-1)
+		}
+	}
+	entry.list.Remove(elem)
+	entry.list = target
+	shard.items[entry.key] = target.PushFront(entry)
+}
+
+// resident reports whether l holds resident entries (T1 or T2) rather than ghosts
+func (shard *ARCShard) resident(l *list.List) bool {
+	return l == shard.t1 || l == shard.t2
+}
+
+// drop removes an element from its list and the map entirely. The caller must hold mu.
+func (shard *ARCShard) drop(elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*arcEntry)
+	if shard.resident(entry.list) {
+		shard.count.Add(-1)
+	}
+	entry.list.Remove(elem)
+	delete(shard.items, entry.key)
+	shard.bytes -= int64(entry.size)
+	if entry.pinned {
+		shard.pinned--
+	}
+}
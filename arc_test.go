@@ -0,0 +1,175 @@
+// arc_test.go: Tests for the ARC eviction policy
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestARC_BasicOperations(t *testing.T) {
+	arc := NewARC(100, 1)
+
+	if !arc.Set("key1", "value1") {
+		t.Fatal("expected Set to succeed")
+	}
+	if v, ok := arc.Get("key1"); !ok || v != "value1" {
+		t.Errorf("expected value1, got %v (found=%v)", v, ok)
+	}
+	if !arc.Delete("key1") {
+		t.Error("expected Delete to report the key")
+	}
+	if _, ok := arc.Get("key1"); ok {
+		t.Error("expected key1 to be gone after Delete")
+	}
+	if arc.Set("", "value") {
+		t.Error("expected empty key to be rejected")
+	}
+}
+
+func TestARC_ScanDoesNotFlushFrequentKeys(t *testing.T) {
+	arc := NewARC(10, 1)
+
+	// Two accesses move the hot keys into T2
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		arc.Set(key, i)
+		arc.Get(key)
+	}
+
+	// A one-off scan larger than the cache only churns T1
+	for i := 0; i < 50; i++ {
+		arc.Set(fmt.Sprintf("scan%d", i), i)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, ok := arc.Get(fmt.Sprintf("hot%d", i)); !ok {
+			t.Errorf("hot%d evicted by a scan", i)
+		}
+	}
+	if size := arc.Size(); size > 10 {
+		t.Errorf("expected at most 10 resident entries, got %d", size)
+	}
+}
+
+func TestARC_GhostHitAdaptsTarget(t *testing.T) {
+	arc := NewARC(4, 1)
+
+	for i := 0; i < 4; i++ {
+		arc.Set(fmt.Sprintf("key%d", i), i)
+	}
+	arc.Get("key3") // Give T2 an entry so replacement can favor T1
+	arc.Set("key4", 4)
+	if p := arc.Target(); p != 0 {
+		t.Fatalf("expected initial target 0, got %d", p)
+	}
+
+	// key0 was evicted to B1; asking for it again grows T1's target
+	if _, ok := arc.Get("key0"); ok {
+		t.Fatal("expected key0 to be a ghost")
+	}
+	arc.Set("key0", 0)
+	if p := arc.Target(); p < 1 {
+		t.Errorf("expected B1 ghost hit to grow the target, got %d", p)
+	}
+	if v, ok := arc.Get("key0"); !ok || v != 0 {
+		t.Errorf("expected key0 to be resident again, got %v (found=%v)", v, ok)
+	}
+}
+
+func TestARC_GhostListsBounded(t *testing.T) {
+	const capacity = 8
+	arc := NewARC(capacity, 1)
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key%d", i%40)
+		if _, ok := arc.Get(key); !ok {
+			arc.Set(key, i)
+		}
+	}
+
+	stats := arc.Stats()
+	t1, t2, b1, b2 := stats["t1"].(int), stats["t2"].(int), stats["b1"].(int), stats["b2"].(int)
+	if t1+t2 > capacity {
+		t.Errorf("resident entries %d exceed capacity %d", t1+t2, capacity)
+	}
+	if t1+b1 > capacity {
+		t.Errorf("T1+B1 = %d exceeds capacity %d", t1+b1, capacity)
+	}
+	if total := t1 + t2 + b1 + b2; total > 2*capacity {
+		t.Errorf("directory size %d exceeds twice the capacity", total)
+	}
+	if p := stats["p"].(int); p < 0 || p > capacity {
+		t.Errorf("target %d out of range", p)
+	}
+}
+
+func TestARC_TTLExpiration(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	arc := NewARC(10, 1)
+	arc.setClock(clock)
+	arc.SetTTL(20 * time.Millisecond)
+
+	arc.Set("key", "value")
+	clock.Advance(40 * time.Millisecond)
+
+	if _, ok := arc.Get("key"); ok {
+		t.Error("expected key to expire")
+	}
+	if stats := arc.GetStats(); stats.Expirations != 1 || stats.Keys != 0 {
+		t.Errorf("expected 1 expiration and no keys, got %+v", stats)
+	}
+}
+
+func TestStrategicCache_ARCDelegation(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		EvictionPolicy: "arc",
+	})
+	defer cache.Close()
+
+	if cache.arc == nil {
+		t.Fatal("expected ARC to be initialized")
+	}
+	if name := cache.PolicyName(); name != "arc" {
+		t.Errorf("expected policy name arc, got %s", name)
+	}
+
+	for i := 0; i < 500; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i)
+	}
+	cache.Get("key499")
+	cache.Get("missing")
+
+	stats := cache.GetStats()
+	if stats.Keys > 100 || stats.Keys == 0 {
+		t.Errorf("expected between 1 and 100 keys, got %d", stats.Keys)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+	if stats.Evictions == 0 {
+		t.Error("expected evictions to be counted")
+	}
+	if len(cache.ShardStats()) != 4 {
+		t.Errorf("expected 4 shard stats, got %d", len(cache.ShardStats()))
+	}
+
+	cache.Delete("key499")
+	if _, ok := cache.Get("key499"); ok {
+		t.Error("expected key499 to be deleted")
+	}
+	cache.Clear()
+	if stats := cache.GetStats(); stats.Keys != 0 || stats.MemoryBytes != 0 {
+		t.Errorf("expected empty cache after Clear, got %+v", stats)
+	}
+}
@@ -0,0 +1,149 @@
+// backend.go: Write-through backend support for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Backend is a slower store fronted by the cache, set with CacheConfig.Backend.
+// Load must return ErrNotFound (or an error wrapping it) for keys the backend does not hold.
+// Methods are called concurrently and should honor the context's deadline.
+type Backend interface {
+	Load(ctx context.Context, key string) (interface{}, error)
+	Store(ctx context.Context, key string, value interface{}) error
+	Delete(ctx context.Context, key string) error
+}
+
+// GetCtx retrieves a value like GetE, passing ctx to the Backend when the key is not
+// in memory. A canceled or expired context is reported before the backend is called,
+// and as ctx.Err() rather than ErrBackend when the backend fails after it is done.
+// Keys found in memory are returned without looking at ctx.
+func (sc *StrategicCache) GetCtx(ctx context.Context, key string) (interface{}, error) {
+	value, err := sc.getLocal(sc.HashKey(key))
+	sc.traceGet(key, value, err)
+	if err != nil && sc.config.Backend != nil {
+		return sc.loadThrough(ctx, key, err)
+	}
+	return value, err
+}
+
+// loadThrough loads a key missing from memory from the Backend and caches it.
+// missErr is the error from the in-memory lookup, returned as-is unless it is a miss.
+func (sc *StrategicCache) loadThrough(ctx context.Context, key string, missErr error) (interface{}, error) {
+	if errors.Is(missErr, ErrNegativeEntry) || !errors.Is(missErr, ErrNotFound) && !errors.Is(missErr, ErrExpired) {
+		return nil, missErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err := sc.throughBreaker(ctx, func() (err error) {
+		value, err = sc.config.Backend.Load(ctx, key)
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, missErr
+	}
+	if errors.Is(err, ErrBackendUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("%w: load %q: %w", ErrBackend, key, err)
+	}
+
+	// A value the cache cannot hold is still returned
+	sc.traceOp(TraceSet, key, value)
+	_ = sc.setE(sc.HashKey(key), value, defaultSetOptions)
+	return sc.copyOnRead(value, true)
+}
+
+// SetCtx stores a value like SetE, passing ctx to the Backend. A canceled or expired
+// context is reported before the backend is called, and as ctx.Err() rather than
+// ErrBackend when the backend fails after it is done; the cache is then left unchanged.
+// With write-behind, a full buffer is waited on until ctx is done instead of returning
+// ErrWriteBehindFull, unless ctx can never be canceled. Without a Backend, ctx is not used.
+func (sc *StrategicCache) SetCtx(ctx context.Context, key string, value interface{}) error {
+	return sc.setThrough(ctx, sc.HashKey(key), value, defaultSetOptions)
+}
+
+// setThrough stores a value in the Backend, if any, and then in memory. The cache is
+// only updated once the backend accepted the value, so the two never disagree.
+func (sc *StrategicCache) setThrough(ctx context.Context, hk HashedKey, value interface{}, opts setOptions) error {
+	if sc.config.EnableLatencyTracking {
+		defer sc.latencyShard(hk).latency.set.since(time.Now())
+	}
+	sc.traceOp(TraceSet, hk.key, value)
+	if sc.config.Backend != nil {
+		if err := sc.checkWritable(); err != nil {
+			return err
+		}
+		if err := sc.storeThrough(ctx, hk.key, value); err != nil {
+			return err
+		}
+	}
+	if err := sc.setE(hk, value, opts); err != nil {
+		return err
+	}
+	if !opts.fill {
+		sc.publishInvalidation(hk.key)
+	}
+	return nil
+}
+
+// storeThrough writes a value to the Backend, or queues it with write-behind,
+// unless the cache is closed
+func (sc *StrategicCache) storeThrough(ctx context.Context, key string, value interface{}) error {
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	if closed {
+		return ErrCacheClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if sc.writeBehind != nil {
+		return sc.writeBehind.enqueue(ctx, BackendWrite{Key: key, Value: value})
+	}
+	err := sc.throughBreaker(ctx, func() error { return sc.config.Backend.Store(ctx, key, value) })
+	if errors.Is(err, ErrBackendUnavailable) {
+		return err
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: store %q: %w", ErrBackend, key, err)
+	}
+	return nil
+}
+
+// deleteThrough removes a key from the Backend, or queues the removal with write-behind
+func (sc *StrategicCache) deleteThrough(ctx context.Context, key string) error {
+	if sc.writeBehind != nil {
+		return sc.writeBehind.enqueue(ctx, BackendWrite{Key: key, Delete: true})
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := sc.throughBreaker(ctx, func() error { return sc.config.Backend.Delete(ctx, key) })
+	if errors.Is(err, ErrBackendUnavailable) {
+		return err
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: delete %q: %w", ErrBackend, key, err)
+	}
+	return nil
+}
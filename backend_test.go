@@ -0,0 +1,281 @@
+// backend_test.go: Tests for write-through Backend support
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapBackend is an in-memory Backend that counts calls and can be made to fail
+type mapBackend struct {
+	mu     sync.Mutex
+	data   map[string]interface{}
+	loads  int
+	stores int
+	fail   error
+	delay  time.Duration
+}
+
+func newMapBackend() *mapBackend {
+	return &mapBackend{data: make(map[string]interface{})}
+}
+
+func (b *mapBackend) Load(ctx context.Context, key string) (interface{}, error) {
+	if b.delay > 0 {
+		select {
+		case <-time.After(b.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.loads++
+	if b.fail != nil {
+		return nil, b.fail
+	}
+	value, ok := b.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func (b *mapBackend) Store(ctx context.Context, key string, value interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stores++
+	if b.fail != nil {
+		return b.fail
+	}
+	b.data[key] = value
+	return nil
+}
+
+func (b *mapBackend) Delete(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fail != nil {
+		return b.fail
+	}
+	delete(b.data, key)
+	return nil
+}
+
+func (b *mapBackend) get(key string) (interface{}, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	value, ok := b.data[key]
+	return value, ok
+}
+
+func newBackendCache(policy string, backend Backend) *StrategicCache {
+	return NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: policy,
+		Backend:        backend,
+	})
+}
+
+func TestBackend_ReadThrough(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			backend := newMapBackend()
+			backend.data["user:1"] = "alice"
+			cache := newBackendCache(policy, backend)
+			defer cache.Close()
+
+			if v, ok := cache.Get("user:1"); !ok || v != "alice" {
+				t.Fatalf("expected the miss to load alice from the backend, got %v (found %v)", v, ok)
+			}
+			if v, ok := cache.Get("user:1"); !ok || v != "alice" {
+				t.Fatalf("expected user:1 to stay cached, got %v (found %v)", v, ok)
+			}
+			if backend.loads != 1 {
+				t.Errorf("expected the loaded value to be cached after 1 load, got %d loads", backend.loads)
+			}
+
+			if _, err := cache.GetE("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for a key absent from both tiers, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackend_WriteThrough(t *testing.T) {
+	backend := newMapBackend()
+	cache := newBackendCache("wtinylfu", backend)
+	defer cache.Close()
+
+	if err := cache.SetE("k", "v"); err != nil {
+		t.Fatalf("SetE: %v", err)
+	}
+	if v, ok := backend.get("k"); !ok || v != "v" {
+		t.Errorf("expected Set to store k in the backend, got %v (found %v)", v, ok)
+	}
+	if !cache.SetWithOptions("weighted", 1, WithCost(2)) {
+		t.Error("expected SetWithOptions to succeed")
+	}
+	if _, ok := backend.get("weighted"); !ok {
+		t.Error("expected SetWithOptions to store in the backend")
+	}
+
+	if err := cache.DeleteE("k"); err != nil {
+		t.Fatalf("DeleteE: %v", err)
+	}
+	if _, ok := backend.get("k"); ok {
+		t.Error("expected Delete to remove k from the backend")
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Error("expected k to be gone from both tiers")
+	}
+}
+
+func TestBackend_Bytes(t *testing.T) {
+	backend := newMapBackend()
+	cache := newBackendCache("lru", backend)
+	defer cache.Close()
+
+	if !cache.SetBytes("raw", []byte("payload")) {
+		t.Fatal("expected SetBytes to succeed")
+	}
+	if _, ok := backend.get("raw"); !ok {
+		t.Error("expected SetBytes to store in the backend")
+	}
+	cache.deleteLocal(cache.HashKey("raw"))
+	if b, ok := cache.GetBytes("raw"); !ok || string(b) != "payload" {
+		t.Errorf("expected GetBytes to load from the backend, got %q (found %v)", b, ok)
+	}
+}
+
+func TestBackend_ErrorsAreDistinct(t *testing.T) {
+	backend := newMapBackend()
+	cache := newBackendCache("wtinylfu", backend)
+	defer cache.Close()
+
+	failure := errors.New("connection refused")
+	backend.fail = failure
+
+	_, err := cache.GetE("k")
+	if !errors.Is(err, ErrBackend) || !errors.Is(err, failure) {
+		t.Errorf("expected a load failure to wrap ErrBackend and the cause, got %v", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("expected a backend failure not to look like a miss")
+	}
+
+	if err := cache.SetE("k", "v"); !errors.Is(err, ErrBackend) {
+		t.Errorf("expected a store failure to wrap ErrBackend, got %v", err)
+	}
+	if _, err := cache.getLocal(cache.HashKey("k")); err == nil {
+		t.Error("expected a value the backend rejected not to be cached")
+	}
+	if err := cache.DeleteE("k"); !errors.Is(err, ErrBackend) {
+		t.Errorf("expected a delete failure to wrap ErrBackend, got %v", err)
+	}
+}
+
+func TestBackend_GetCtx(t *testing.T) {
+	backend := newMapBackend()
+	backend.data["slow"] = "value"
+	backend.delay = time.Second
+	cache := newBackendCache("wtinylfu", backend)
+	defer cache.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cache.GetCtx(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to reach the backend, got %v", err)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := cache.GetCtx(canceled, "slow"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled before calling the backend, got %v", err)
+	}
+
+	cache.Set("local", 1)
+	if v, err := cache.GetCtx(canceled, "local"); err != nil || v != 1 {
+		t.Errorf("expected in-memory hits to ignore the context, got %v (err %v)", v, err)
+	}
+}
+
+// ctxBackend is a Backend whose Store waits for its delay or the context
+type ctxBackend struct {
+	*mapBackend
+	storeDelay time.Duration
+}
+
+func (b *ctxBackend) Store(ctx context.Context, key string, value interface{}) error {
+	select {
+	case <-time.After(b.storeDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return b.mapBackend.Store(ctx, key, value)
+}
+
+func TestBackend_SetCtx(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			backend := &ctxBackend{mapBackend: newMapBackend(), storeDelay: time.Second}
+			cache := newBackendCache(policy, backend)
+			defer cache.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := cache.SetCtx(ctx, "slow", 1)
+			if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrBackend) {
+				t.Errorf("expected context.DeadlineExceeded rather than a backend error, got %v", err)
+			}
+			if _, ok := cache.Get("slow"); ok {
+				t.Error("expected the cache unchanged when the backend store did not complete")
+			}
+
+			canceled, cancelNow := context.WithCancel(context.Background())
+			cancelNow()
+			if err := cache.SetCtx(canceled, "k", 1); !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+			if backend.stores != 0 {
+				t.Errorf("expected no backend call with a canceled context, got %d", backend.stores)
+			}
+
+			backend.storeDelay = 0
+			if err := cache.SetCtx(context.Background(), "k", 2); err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := backend.get("k"); !ok || v != 2 {
+				t.Errorf("expected the backend to hold 2, got %v", v)
+			}
+			if v, _ := cache.Get("k"); v != 2 {
+				t.Errorf("expected the cache to hold 2, got %v", v)
+			}
+		})
+	}
+}
+
+func TestSetCtx_WithoutBackend(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cache.SetCtx(canceled, "k", 1); err != nil {
+		t.Errorf("expected in-memory stores to ignore the context, got %v", err)
+	}
+	if v, err := cache.GetCtx(canceled, "k"); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (err %v)", v, err)
+	}
+}
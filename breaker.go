@@ -0,0 +1,242 @@
+// breaker.go: Circuit breaker around Backend and loader calls for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBackendUnavailable is returned without calling the Backend or loader while the
+// circuit breaker is open; it matches ErrBackend too
+var ErrBackendUnavailable = fmt.Errorf("%w: circuit breaker is open", ErrBackend)
+
+// Circuit breaker defaults, used when the corresponding CacheConfig field is zero
+const (
+	DefaultBreakerOpenDuration   = 5 * time.Second
+	DefaultBreakerHalfOpenProbes = 1
+)
+
+// BreakerState is the state of the circuit breaker (see CacheConfig.BreakerThreshold)
+type BreakerState int32
+
+// Circuit breaker states
+const (
+	BreakerClosed   BreakerState = iota // Calls reach the backend
+	BreakerOpen                         // Calls fail fast with ErrBackendUnavailable
+	BreakerHalfOpen                     // A few probe calls test whether the backend recovered
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("state(%d)", int32(s))
+}
+
+// MarshalText encodes the state by name, as in JSON stats
+func (s BreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// BreakerStats describes the circuit breaker (see CacheConfig.BreakerThreshold)
+type BreakerStats struct {
+	State BreakerState `json:"state"`
+	// ConsecutiveFailures is the current run of failed calls while closed
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// Opens counts the times the breaker opened, including after a failed probe
+	Opens int64 `json:"opens"`
+	// Rejected counts calls failed with ErrBackendUnavailable
+	Rejected int64 `json:"rejected"`
+}
+
+// breaker counts consecutive failures of backend and loader calls. Past threshold it
+// opens and rejects calls for openFor, then lets up to probes calls through half-open: one
+// failure opens it again, probes successes close it.
+type breaker struct {
+	threshold int
+	probes    int
+	openFor   time.Duration
+	clock     Clock
+	logger    Logger
+
+	mu       sync.Mutex
+	state    BreakerState
+	gen      uint64 // Incremented on every transition, so late results of older calls are ignored
+	failures int
+	openedAt time.Time
+	probing  int // Probes in flight while half-open
+	passed   int // Probes that succeeded while half-open
+	opens    int64
+	rejected int64
+	onChange func(from, to BreakerState)
+}
+
+// newBreaker returns the breaker for config, or nil when BreakerThreshold is not set
+func newBreaker(config CacheConfig, clock Clock, logger Logger) *breaker {
+	if config.BreakerThreshold <= 0 {
+		return nil
+	}
+	b := &breaker{
+		threshold: config.BreakerThreshold,
+		probes:    config.BreakerHalfOpenProbes,
+		openFor:   config.BreakerOpenDuration,
+		clock:     clock,
+		logger:    logger,
+	}
+	if b.probes <= 0 {
+		b.probes = DefaultBreakerHalfOpenProbes
+	}
+	if b.openFor <= 0 {
+		b.openFor = DefaultBreakerOpenDuration
+	}
+	return b
+}
+
+// allow reports whether a call may proceed, returning the generation to pass to done
+func (b *breaker) allow() (uint64, error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == BreakerOpen && b.clock.Now().Sub(b.openedAt) >= b.openFor {
+		b.transition(BreakerHalfOpen)
+	}
+	var err error
+	switch {
+	case b.state == BreakerOpen, b.state == BreakerHalfOpen && b.probing >= b.probes:
+		b.rejected++
+		err = ErrBackendUnavailable
+	case b.state == BreakerHalfOpen:
+		b.probing++
+	}
+	gen, to, fn := b.gen, b.state, b.onChange
+	b.mu.Unlock()
+
+	b.notify(fn, from, to)
+	return gen, err
+}
+
+// done records the result of a call allowed in generation gen. Abandoned calls, whose
+// caller gave up on them, count neither way but free their probe slot.
+func (b *breaker) done(gen uint64, failed, abandoned bool) {
+	b.mu.Lock()
+	from := b.state
+	if gen == b.gen {
+		switch b.state {
+		case BreakerClosed:
+			if abandoned {
+				break
+			}
+			if !failed {
+				b.failures = 0
+			} else if b.failures++; b.failures >= b.threshold {
+				b.transition(BreakerOpen)
+			}
+		case BreakerHalfOpen:
+			b.probing--
+			if abandoned {
+				break
+			}
+			if failed {
+				b.transition(BreakerOpen)
+			} else if b.passed++; b.passed >= b.probes {
+				b.transition(BreakerClosed)
+			}
+		}
+	}
+	to, fn := b.state, b.onChange
+	b.mu.Unlock()
+
+	b.notify(fn, from, to)
+}
+
+// transition moves to state, resetting the counters of the state left; b.mu must be held
+func (b *breaker) transition(state BreakerState) {
+	b.state = state
+	b.gen++
+	b.failures, b.probing, b.passed = 0, 0, 0
+	if state == BreakerOpen {
+		b.openedAt = b.clock.Now()
+		b.opens++
+	}
+}
+
+// notify logs a change of state and passes it to the OnBreakerStateChange handler
+func (b *breaker) notify(fn func(from, to BreakerState), from, to BreakerState) {
+	if from == to {
+		return
+	}
+	if to == BreakerOpen {
+		b.logger.Warn("metis: backend circuit breaker opened", "from", from.String(), "open_for", b.openFor)
+	} else {
+		b.logger.Info("metis: backend circuit breaker state changed", "from", from.String(), "to", to.String())
+	}
+	if fn != nil {
+		fn(from, to)
+	}
+}
+
+func (b *breaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.state
+	if state == BreakerOpen && b.clock.Now().Sub(b.openedAt) >= b.openFor {
+		state = BreakerHalfOpen // As the next call will find it
+	}
+	return BreakerStats{State: state, ConsecutiveFailures: b.failures, Opens: b.opens, Rejected: b.rejected}
+}
+
+// throughBreaker runs call under the circuit breaker, if any. Errors matching ErrNotFound
+// count as successes, errors once ctx is done as abandoned calls, and panics as failures.
+func (sc *StrategicCache) throughBreaker(ctx context.Context, call func() error) (err error) {
+	b := sc.breaker
+	if b == nil {
+		return call()
+	}
+	gen, err := b.allow()
+	if err != nil {
+		return err
+	}
+	returned := false
+	defer func() {
+		failed := !returned || err != nil && !errors.Is(err, ErrNotFound)
+		b.done(gen, failed, returned && err != nil && ctx.Err() != nil)
+	}()
+	err = call()
+	returned = true
+	return err
+}
+
+// BreakerStats returns the state and counters of the circuit breaker; the zero value
+// when CacheConfig.BreakerThreshold is not set
+func (sc *StrategicCache) BreakerStats() BreakerStats {
+	if sc.breaker == nil {
+		return BreakerStats{}
+	}
+	return sc.breaker.stats()
+}
+
+// OnBreakerStateChange sets fn to be called on each change of the circuit breaker state.
+// fn runs on the goroutine whose call caused the change, after the breaker released its
+// lock, so it may call back into the cache. An open breaker turns half-open on the first
+// call after BreakerOpenDuration. Passing nil removes the handler.
+func (sc *StrategicCache) OnBreakerStateChange(fn func(from, to BreakerState)) {
+	if sc.breaker == nil {
+		return
+	}
+	sc.breaker.mu.Lock()
+	sc.breaker.onChange = fn
+	sc.breaker.mu.Unlock()
+}
@@ -0,0 +1,245 @@
+// breaker_test.go: Tests for the Backend circuit breaker
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func (b *mapBackend) setFail(err error) {
+	b.mu.Lock()
+	b.fail = err
+	b.mu.Unlock()
+}
+
+func TestBreaker_FlappingBackend(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			backend := newMapBackend()
+			backend.data["user:1"] = "alice"
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:       true,
+				CacheSize:           1000,
+				TTL:                 time.Hour,
+				EvictionPolicy:      policy,
+				Backend:             backend,
+				BreakerThreshold:    3,
+				BreakerOpenDuration: 10 * time.Second,
+				Clock:               clock,
+			})
+			defer cache.Close()
+
+			var mu sync.Mutex
+			var transitions []string
+			cache.OnBreakerStateChange(func(from, to BreakerState) {
+				mu.Lock()
+				transitions = append(transitions, from.String()+"->"+to.String())
+				mu.Unlock()
+			})
+
+			backend.setFail(errors.New("connection refused"))
+			for i := 0; i < 3; i++ {
+				if _, err := cache.GetE(fmt.Sprintf("k%d", i)); !errors.Is(err, ErrBackend) || errors.Is(err, ErrBackendUnavailable) {
+					t.Fatalf("failure %d: expected the backend error, got %v", i, err)
+				}
+			}
+			if _, err := cache.GetE("user:1"); !errors.Is(err, ErrBackendUnavailable) || !errors.Is(err, ErrBackend) {
+				t.Fatalf("expected ErrBackendUnavailable once open, got %v", err)
+			}
+			if err := cache.SetE("user:2", "bob"); !errors.Is(err, ErrBackendUnavailable) {
+				t.Errorf("expected writes to fail fast too, got %v", err)
+			}
+			if _, ok := cache.Get("user:2"); ok {
+				t.Error("expected a rejected write to leave the cache unchanged")
+			}
+			if backend.loads != 3 || backend.stores != 0 {
+				t.Errorf("expected no backend calls while open, got %d loads and %d stores", backend.loads, backend.stores)
+			}
+			if stats := cache.BreakerStats(); stats.State != BreakerOpen || stats.Opens != 1 || stats.Rejected != 3 {
+				t.Errorf("unexpected stats while open: %+v", stats)
+			}
+
+			// The probe after the open duration fails, so the breaker opens again
+			clock.Advance(10 * time.Second)
+			if stats := cache.BreakerStats(); stats.State != BreakerHalfOpen {
+				t.Errorf("expected half-open after the open duration, got %v", stats.State)
+			}
+			if _, err := cache.GetE("user:1"); errors.Is(err, ErrBackendUnavailable) || !errors.Is(err, ErrBackend) {
+				t.Fatalf("expected the probe to reach the backend, got %v", err)
+			}
+			if _, err := cache.GetE("user:1"); !errors.Is(err, ErrBackendUnavailable) {
+				t.Fatalf("expected a failed probe to open the breaker again, got %v", err)
+			}
+
+			// The backend recovers, and the next probe closes the breaker
+			backend.setFail(nil)
+			clock.Advance(10 * time.Second)
+			if v, err := cache.GetE("user:1"); err != nil || v != "alice" {
+				t.Fatalf("expected the probe to load alice, got %v (err %v)", v, err)
+			}
+			if err := cache.SetE("user:2", "bob"); err != nil {
+				t.Errorf("expected writes to reach the backend once closed, got %v", err)
+			}
+
+			stats := cache.BreakerStats()
+			if stats.State != BreakerClosed || stats.Opens != 2 || stats.ConsecutiveFailures != 0 {
+				t.Errorf("unexpected stats once recovered: %+v", stats)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+			if fmt.Sprint(transitions) != fmt.Sprint(want) {
+				t.Errorf("expected transitions %v, got %v", want, transitions)
+			}
+		})
+	}
+}
+
+func TestBreaker_MissesAndCancellationsDoNotCount(t *testing.T) {
+	backend := newMapBackend()
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:       true,
+		CacheSize:           1000,
+		TTL:                 time.Hour,
+		EvictionPolicy:      "lru",
+		Backend:             backend,
+		BreakerThreshold:    3,
+		BreakerOpenDuration: 10 * time.Second,
+	})
+	defer cache.Close()
+
+	// Keys the backend does not hold are successful calls
+	backend.setFail(errors.New("timeout"))
+	_, _ = cache.GetE("a")
+	_, _ = cache.GetE("b")
+	backend.setFail(nil)
+	if _, err := cache.GetE("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if stats := cache.BreakerStats(); stats.ConsecutiveFailures != 0 {
+		t.Errorf("expected a miss to reset the failures, got %+v", stats)
+	}
+
+	// Calls the caller gave up on say nothing about the backend
+	backend.delay = time.Second
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := cache.GetCtx(ctx, "slow")
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+		}
+	}
+	if stats := cache.BreakerStats(); stats.State != BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("expected canceled calls not to count, got %+v", stats)
+	}
+}
+
+func TestBreaker_LoadOrCompute(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:       true,
+		CacheSize:           1000,
+		TTL:                 time.Hour,
+		EvictionPolicy:      "wtinylfu",
+		BreakerThreshold:    3,
+		BreakerOpenDuration: 10 * time.Second,
+		Clock:               clock,
+	})
+	defer cache.Close()
+
+	calls := 0
+	failing := func(key string) (interface{}, error) {
+		calls++
+		return nil, errors.New("database down")
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = cache.LoadOrCompute(fmt.Sprintf("k%d", i), failing)
+	}
+	if calls != 3 {
+		t.Errorf("expected the loader to stop being called once the breaker opened, got %d calls", calls)
+	}
+	if _, err := cache.LoadOrCompute("k", failing); !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("expected ErrBackendUnavailable, got %v", err)
+	}
+
+	clock.Advance(10 * time.Second)
+	v, err := cache.LoadOrCompute("k", func(key string) (interface{}, error) { return "v", nil })
+	if err != nil || v != "v" {
+		t.Fatalf("expected the probe to load v, got %v (err %v)", v, err)
+	}
+	if state := cache.BreakerStats().State; state != BreakerClosed {
+		t.Errorf("expected a successful probe to close the breaker, got %v", state)
+	}
+}
+
+func TestBreaker_HalfOpenProbes(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	b := newBreaker(CacheConfig{BreakerThreshold: 1, BreakerHalfOpenProbes: 2}, clock, noopLogger{})
+	if b.openFor != DefaultBreakerOpenDuration {
+		t.Errorf("expected the default open duration, got %v", b.openFor)
+	}
+
+	gen, _ := b.allow()
+	b.done(gen, true, false)
+	clock.Advance(DefaultBreakerOpenDuration)
+
+	first, err1 := b.allow()
+	second, err2 := b.allow()
+	if err1 != nil || err2 != nil {
+		t.Fatalf("expected two probes, got %v and %v", err1, err2)
+	}
+	if _, err := b.allow(); !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("expected a third concurrent probe to be rejected, got %v", err)
+	}
+
+	// An abandoned probe frees its slot without counting
+	b.done(first, false, true)
+	third, err := b.allow()
+	if err != nil {
+		t.Fatalf("expected the freed slot to admit a probe, got %v", err)
+	}
+	b.done(second, false, false)
+	if state := b.stats().State; state != BreakerHalfOpen {
+		t.Errorf("expected one success of two to keep the breaker half-open, got %v", state)
+	}
+	b.done(third, false, false)
+	if state := b.stats().State; state != BreakerClosed {
+		t.Errorf("expected two successes to close the breaker, got %v", state)
+	}
+
+	// Results of calls from before the last transition are ignored
+	b.done(gen, true, false)
+	if stats := b.stats(); stats.ConsecutiveFailures != 0 || stats.State != BreakerClosed {
+		t.Errorf("expected a stale result to be ignored, got %+v", stats)
+	}
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	backend := newMapBackend()
+	backend.fail = errors.New("down")
+	cache := newBackendCache("lru", backend)
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, err := cache.GetE("k"); errors.Is(err, ErrBackendUnavailable) {
+			t.Fatal("expected no breaker without BreakerThreshold")
+		}
+	}
+	if stats := cache.BreakerStats(); stats != (BreakerStats{}) {
+		t.Errorf("expected zero stats without a breaker, got %+v", stats)
+	}
+	cache.OnBreakerStateChange(func(from, to BreakerState) {}) // No breaker to attach to
+}
@@ -0,0 +1,136 @@
+// bytes.go: Byte-slice fast paths for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "context"
+
+// SetBytes stores a byte slice, skipping the reflection and serializer used by Set.
+// Without compression the slice is stored as-is, so the caller must not modify it
+// after the call. With EnableCompression the bytes are compressed directly by the codec.
+func (sc *StrategicCache) SetBytes(key string, value []byte) bool {
+	return sc.setBytes(key, value, defaultSetOptions)
+}
+
+// setBytes is SetBytes applying per-entry options
+func (sc *StrategicCache) setBytes(key string, value []byte, opts setOptions) bool {
+	if !sc.config.EnableCaching {
+		return false
+	}
+
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return false
+	}
+	sc.closedMu.RUnlock()
+	if sc.checkWritable() != nil {
+		return false
+	}
+
+	// W-TinyLFU and ARC store values as-is and size byte slices by length
+	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") || sc.arc != nil {
+		return sc.set(key, value, opts)
+	}
+
+	if sc.config.Backend != nil && sc.storeThrough(context.Background(), key, value) != nil {
+		return false
+	}
+
+	maxKeySize, maxValueSize := sc.sizeLimits()
+	if maxKeySize > 0 && len(key) > maxKeySize {
+		return false
+	}
+	if maxValueSize > 0 && len(value) > maxValueSize {
+		return false
+	}
+	// Box the slice once for both the admission policy and the entry
+	var data interface{} = value
+	if !sc.admissionPolicy().Allow(key, data) {
+		return false
+	}
+
+	v := storedValue{data: data, size: len(value)}
+	if sc.config.EnableCompression {
+		codec := sc.valueCodec()
+		encoded, err := compressFramed(value, tagBytes, codec)
+		if err != nil {
+			return false
+		}
+		v = storedValue{
+			data:       encoded,
+			size:       len(encoded),
+			compressed: true,
+			rawSize:    len(value),
+			skipped:    skipsCompression(codec, len(value)),
+			checksum:   sc.checksum(encoded),
+		}
+	}
+	if sc.store(sc.HashKey(key), v, sc.resolveExpiry(opts)) != nil {
+		return false
+	}
+	sc.tagKey(key, opts)
+	if !opts.fill {
+		sc.publishInvalidation(key)
+	}
+	return true
+}
+
+// GetBytes returns a byte slice stored with SetBytes, or with Set when the value is a []byte.
+// It reports false for missing keys and for values of any other type.
+// The returned slice may share memory with the cache and must not be modified,
+// unless CopyOnRead is enabled.
+func (sc *StrategicCache) GetBytes(key string) ([]byte, bool) {
+	if !sc.config.EnableCaching {
+		return nil, false
+	}
+
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return nil, false
+	}
+	sc.closedMu.RUnlock()
+
+	data, compressed, ok := sc.getStored(sc.HashKey(key))
+	if !ok {
+		if sc.config.Backend == nil {
+			return nil, false
+		}
+		// Fall through to the backend, as Get does
+		value, err := sc.loadThrough(context.Background(), key, ErrNotFound)
+		b, ok := value.([]byte)
+		return b, err == nil && ok
+	}
+
+	b, ok := data.([]byte)
+	switch v := data.(type) {
+	case *streamValue:
+		var err error
+		if b, err = v.bytes(); err != nil {
+			sc.logUndecodable(key)
+		}
+		ok = err == nil
+	case *chunkManifest:
+		value, err := sc.assemble(key, v)
+		b, ok = value.([]byte)
+		ok = ok && err == nil
+	}
+	if compressed {
+		var tag byte
+		if tag, b, ok = decompressFramed(data); !ok {
+			sc.logUndecodable(key)
+		}
+		ok = ok && tag == tagBytes
+	}
+	if !ok {
+		return nil, false
+	}
+	if sc.config.CopyOnRead {
+		b = append([]byte(nil), b...)
+	}
+	return b, true
+}
@@ -0,0 +1,98 @@
+// bytes_test.go: Tests for the byte-slice fast paths
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytes_RoundTripAllPaths(t *testing.T) {
+	large := bytes.Repeat([]byte("serialized-protobuf"), 50)
+	configs := map[string]CacheConfig{
+		"lru":            {EvictionPolicy: "lru"},
+		"lru-compressed": {EvictionPolicy: "lru", EnableCompression: true},
+		"wtinylfu":       {EvictionPolicy: "wtinylfu"},
+		"arc":            {EvictionPolicy: "arc"},
+	}
+	for name, config := range configs {
+		config.EnableCaching = true
+		config.CacheSize = 100
+		config.ShardCount = 2
+		cache := NewStrategicCache(config)
+
+		for _, value := range [][]byte{large, []byte("tiny"), {}} {
+			if !cache.SetBytes("key", value) {
+				t.Fatalf("%s: SetBytes failed", name)
+			}
+			got, ok := cache.GetBytes("key")
+			if !ok || !bytes.Equal(got, value) {
+				t.Errorf("%s: expected %d bytes back, got %d (%v)", name, len(value), len(got), ok)
+			}
+			// The generic path sees the same bytes
+			if generic, ok := cache.Get("key"); !ok || !bytes.Equal(generic.([]byte), value) {
+				t.Errorf("%s: expected Get to return the bytes, got %v", name, generic)
+			}
+		}
+
+		// GetBytes reads []byte values stored with Set, and rejects other types
+		cache.Set("generic", []byte("via set"))
+		if got, ok := cache.GetBytes("generic"); !ok || string(got) != "via set" {
+			t.Errorf("%s: expected GetBytes to read a []byte stored with Set, got %q (%v)", name, got, ok)
+		}
+		cache.Set("string", "not bytes")
+		if _, ok := cache.GetBytes("string"); ok {
+			t.Errorf("%s: expected GetBytes to reject a string value", name)
+		}
+		if _, ok := cache.GetBytes("missing"); ok {
+			t.Errorf("%s: expected a miss for an unknown key", name)
+		}
+		cache.Close()
+	}
+}
+
+func TestBytes_StoredWithoutCopy(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru"})
+	defer cache.Close()
+
+	value := []byte("shared")
+	cache.SetBytes("key", value)
+	got, _ := cache.GetBytes("key")
+	if &got[0] != &value[0] {
+		t.Error("expected GetBytes to return the slice passed to SetBytes")
+	}
+	if stats := cache.GetStats(); stats.MemoryBytes != int64(len(value)) {
+		t.Errorf("expected memory accounting by length, got %d", stats.MemoryBytes)
+	}
+}
+
+func TestBytes_LimitsAndCompressionStats(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         10,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		MaxKeySize:        8,
+		MaxValueSize:      2048,
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	if cache.SetBytes("a-very-long-key", []byte("x")) {
+		t.Error("expected MaxKeySize to apply")
+	}
+	if cache.SetBytes("big", make([]byte, 4096)) {
+		t.Error("expected MaxValueSize to apply")
+	}
+
+	value := bytes.Repeat([]byte("abc"), 300)
+	cache.SetBytes("key", value)
+	stats := cache.CompressionStats()
+	if stats.CompressedEntries != 1 || stats.UncompressedBytes != int64(len(value)) || stats.CompressedBytes >= int64(len(value)) {
+		t.Errorf("expected SetBytes to compress and be tracked, got %+v", stats)
+	}
+}
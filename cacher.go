@@ -0,0 +1,33 @@
+// cacher.go: Interface shared by the Metis cache implementations
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+// Cacher is the method set shared by StrategicCache, WTinyLFU and ARC. Depend on it instead
+// of a concrete type to swap in another implementation, such as a fake in tests.
+type Cacher interface {
+	// Get returns the value stored for key, if present and unexpired
+	Get(key string) (interface{}, bool)
+	// Set stores a value, reporting whether it was accepted
+	Set(key string, value interface{}) bool
+	// Delete removes key, reporting whether it was present
+	Delete(key string) bool
+	// Clear removes every entry
+	Clear()
+	// Len returns the number of entries held
+	Len() int
+	// GetStats returns the cache statistics
+	GetStats() CacheStats
+	// Close releases background resources; the cache must not be used afterwards
+	Close()
+}
+
+// Every cache implementation satisfies Cacher
+var (
+	_ Cacher = (*StrategicCache)(nil)
+	_ Cacher = (*WTinyLFU)(nil)
+	_ Cacher = (*ARC)(nil)
+)
@@ -0,0 +1,89 @@
+// cacher_test.go: Conformance tests for the Cacher implementations
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// cacherImplementations builds every Cacher implementation with room for 1000 entries
+var cacherImplementations = map[string]func() Cacher{
+	"StrategicCache/lru": func() Cacher {
+		return NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Hour, EvictionPolicy: "lru"})
+	},
+	"StrategicCache/wtinylfu": func() Cacher {
+		return NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Hour, EvictionPolicy: "wtinylfu"})
+	},
+	"StrategicCache/arc": func() Cacher {
+		return NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Hour, EvictionPolicy: "arc"})
+	},
+	"WTinyLFU": func() Cacher { return NewWTinyLFU(1000, 4) },
+	"ARC":      func() Cacher { return NewARC(1000, 4) },
+}
+
+func TestCacher_Conformance(t *testing.T) {
+	for name, newCache := range cacherImplementations {
+		t.Run(name, func(t *testing.T) {
+			c := newCache()
+			defer c.Close()
+
+			if _, ok := c.Get("missing"); ok {
+				t.Error("expected a miss for an unknown key")
+			}
+			if !c.Set("a", 1) || !c.Set("b", "two") {
+				t.Fatal("expected Set to accept small values")
+			}
+			if v, ok := c.Get("a"); !ok || v != 1 {
+				t.Errorf("expected a=1, got %v (found %v)", v, ok)
+			}
+			if !c.Set("a", 10) {
+				t.Fatal("expected Set to accept an update")
+			}
+			if v, _ := c.Get("a"); v != 10 {
+				t.Errorf("expected the updated value 10, got %v", v)
+			}
+			if n := c.Len(); n != 2 {
+				t.Errorf("expected Len 2, got %d", n)
+			}
+			if stats := c.GetStats(); stats.Keys != 2 || stats.Hits < 2 || stats.Misses < 1 {
+				t.Errorf("expected 2 keys, at least 2 hits and 1 miss, got %+v", stats)
+			}
+
+			if !c.Delete("a") {
+				t.Error("expected Delete to report a present key")
+			}
+			if c.Delete("a") {
+				t.Error("expected Delete to report a missing key")
+			}
+			if _, ok := c.Get("a"); ok {
+				t.Error("expected a to be gone after Delete")
+			}
+
+			for i := 0; i < 10; i++ {
+				c.Set(fmt.Sprintf("k%d", i), i)
+			}
+			c.Clear()
+			if n := c.Len(); n != 0 {
+				t.Errorf("expected Len 0 after Clear, got %d", n)
+			}
+			if _, ok := c.Get("b"); ok {
+				t.Error("expected b to be gone after Clear")
+			}
+		})
+	}
+}
+
+func TestCacher_StrategicCacheAfterClose(t *testing.T) {
+	c := cacherImplementations["StrategicCache/lru"]()
+	c.Set("a", 1)
+	c.Close()
+	if c.Len() != 0 || c.Delete("a") || c.Set("b", 2) {
+		t.Error("expected a closed cache to hold nothing and reject writes")
+	}
+}
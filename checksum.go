@@ -0,0 +1,43 @@
+// checksum.go: Checksums of compressed entries for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "hash/crc32"
+
+// checksum returns the CRC32 that reads of a compressed entry verify, or 0 when
+// DisableChecksums is set
+func (sc *StrategicCache) checksum(data []byte) uint32 {
+	if sc.config.DisableChecksums {
+		return 0
+	}
+	return crc32.ChecksumIEEE(data)
+}
+
+// intact reports whether data, read from key's compressed entry in shard, still
+// matches the entry's checksum sum. On a mismatch the entry is counted in
+// CorruptedEntries, logged and removed, unless a write has replaced it meanwhile.
+func (sc *StrategicCache) intact(shard *cacheShard, key string, data interface{}, sum uint32) bool {
+	if sc.config.DisableChecksums {
+		return true
+	}
+	b, ok := data.([]byte)
+	if !ok || crc32.ChecksumIEEE(b) == sum {
+		return true
+	}
+
+	sc.corruptedEntries.Add(1)
+	sc.logger.Error("metis: checksum mismatch, dropping corrupted entry", "key", key)
+	shard.mu.Lock()
+	if entry, exists := shard.data[key]; exists && entry.Compressed {
+		if b, ok := entry.Data.([]byte); ok && crc32.ChecksumIEEE(b) != entry.checksum {
+			shard.removeEntry(key, entry)
+			sc.entryPool.Put(entry)
+		}
+	}
+	shard.mu.Unlock()
+	return false
+}
@@ -0,0 +1,102 @@
+// checksum_test.go: Tests for checksums of compressed entries
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flipByte corrupts the stored bytes of key in place, as a stray write would
+func flipByte(t *testing.T, cache *StrategicCache, key string) {
+	t.Helper()
+	shard := cache.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	entry, ok := shard.data[key]
+	if !ok || !entry.Compressed {
+		t.Fatalf("expected a compressed entry for %s", key)
+	}
+	data := entry.Data.([]byte)
+	data[len(data)/2] ^= 0x40
+}
+
+func TestChecksum_DetectsFlippedByte(t *testing.T) {
+	logger := &captureLogger{}
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", EnableCompression: true, Logger: logger})
+	defer cache.Close()
+
+	value := strings.Repeat("metis ", 50)
+	cache.Set("k", value)
+	cache.Set("other", value)
+	if v, ok := cache.Get("k"); !ok || v != value {
+		t.Fatalf("expected the intact value, got %v (%v)", v, ok)
+	}
+
+	flipByte(t, cache, "k")
+	if _, err := cache.GetE("k"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a corrupted entry, got %v", err)
+	}
+	stats := cache.GetStats()
+	if stats.CorruptedEntries != 1 {
+		t.Errorf("expected 1 corrupted entry, got %d", stats.CorruptedEntries)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected the corrupted entry removed, got %d entries", cache.Len())
+	}
+	if r, ok := logger.find("error", "checksum mismatch"); !ok || r.field("key") != "k" {
+		t.Errorf("expected a checksum error naming the key, got %+v", logger.records)
+	}
+
+	// The removed entry is an ordinary miss from now on
+	if _, ok := cache.Get("k"); ok {
+		t.Error("expected k to stay missing")
+	}
+	if n := cache.GetStats().CorruptedEntries; n != 1 {
+		t.Errorf("expected the counter unchanged by a plain miss, got %d", n)
+	}
+	cache.Set("k", value)
+	if v, ok := cache.Get("k"); !ok || v != value {
+		t.Errorf("expected k stored again, got %v (%v)", v, ok)
+	}
+	if v, ok := cache.Get("other"); !ok || v != value {
+		t.Errorf("expected other untouched, got %v (%v)", v, ok)
+	}
+
+	cache.ResetStats()
+	if n := cache.GetStats().CorruptedEntries; n != 0 {
+		t.Errorf("expected ResetStats to zero the counter, got %d", n)
+	}
+}
+
+func TestChecksum_GetBytes(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", EnableCompression: true})
+	defer cache.Close()
+
+	cache.SetBytes("blob", []byte(strings.Repeat("payload", 40)))
+	flipByte(t, cache, "blob")
+	if _, ok := cache.GetBytes("blob"); ok {
+		t.Fatal("expected a corrupted blob to read as missing")
+	}
+	if n := cache.GetStats().CorruptedEntries; n != 1 {
+		t.Errorf("expected 1 corrupted entry, got %d", n)
+	}
+}
+
+func TestChecksum_Disabled(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", EnableCompression: true, DisableChecksums: true})
+	defer cache.Close()
+
+	cache.Set("k", strings.Repeat("metis ", 50))
+	flipByte(t, cache, "k")
+	cache.Get("k")
+	if n := cache.GetStats().CorruptedEntries; n != 0 {
+		t.Errorf("expected no checksum verification, got %d corrupted entries", n)
+	}
+}
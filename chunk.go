@@ -0,0 +1,201 @@
+// chunk.go: Chunked storage of large values for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// chunkKeyPrefix starts the derived keys chunks are stored under: the prefix, the
+// chunk index, a NUL and the key of the value
+const chunkKeyPrefix = "\x00chunk\x00"
+
+// chunkManifest is stored under the key of a value split by ChunkThreshold. It
+// describes the chunks, which must all carry its generation to be assembled.
+type chunkManifest struct {
+	gen        uint64
+	count      int  // Number of chunks
+	size       int  // Length of the encoded value
+	compressed bool // The encoding is encodeCompressed's, else encodeTyped's payload
+	tag        byte // Type tag of an uncompressed encoding
+}
+
+// chunkPiece is one chunk of an encoded value, stored under chunkKey
+type chunkPiece struct {
+	gen   uint64 // Generation of the manifest it belongs to
+	count int    // Number of chunks of the value, to remove them all after an eviction
+	data  []byte
+}
+
+// chunkKey returns the key chunk i of key's value is stored under
+func chunkKey(key string, i int) string {
+	return chunkKeyPrefix + strconv.Itoa(i) + "\x00" + key
+}
+
+// isChunkKey reports whether key is one a chunk is stored under, which callers never see
+func isChunkKey(key string) bool {
+	return strings.HasPrefix(key, chunkKeyPrefix)
+}
+
+// chunkOwner returns the key of the value a chunk key belongs to
+func chunkOwner(chunk string) (string, bool) {
+	rest, ok := strings.CutPrefix(chunk, chunkKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	_, key, ok := strings.Cut(rest, "\x00")
+	return key, ok
+}
+
+// chunks reports whether value is large enough to be split (see CacheConfig.ChunkThreshold)
+func (sc *StrategicCache) chunks(value interface{}) bool {
+	return sc.config.ChunkThreshold > 0 && !isInternal(value) && calculateSize(value) > sc.config.ChunkThreshold
+}
+
+// setChunked stores value as chunks of ChunkThreshold bytes under derived keys, then the
+// manifest under hk. The chunks land on whichever shards their keys hash to, so no
+// shard holds more than a chunk of the value. A failure leaves hk missing.
+func (sc *StrategicCache) setChunked(hk HashedKey, value interface{}, opts setOptions) (int, error) {
+	if _, maxValueSize := sc.sizeLimits(); maxValueSize > 0 && calculateSize(value) > maxValueSize {
+		return 0, ErrValueTooLarge
+	}
+	m := &chunkManifest{gen: sc.chunkGen.Add(1)}
+	var data []byte
+	var err error
+	if sc.config.EnableCompression {
+		data, _, err = encodeCompressed(value, sc.valueCodec(), sc.serializer)
+		m.compressed = true
+	} else {
+		m.tag, data, err = encodeTyped(value, sc.serializer)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrNotSerializable, err)
+		sc.logger.Warn("metis: cannot serialize value", "key", hk.key, "error", err)
+		return 0, err
+	}
+
+	size := sc.config.ChunkThreshold
+	m.size = len(data)
+	m.count = (len(data) + size - 1) / size
+	for i := 0; i < m.count; i++ {
+		chunk := data[i*size : min((i+1)*size, len(data))]
+		piece := chunkPiece{gen: m.gen, count: m.count, data: chunk[:len(chunk):len(chunk)]}
+		if err := sc.setEntry(sc.HashKey(chunkKey(hk.key, i)), piece, opts); err != nil {
+			sc.deleteLocal(hk)
+			return 0, err
+		}
+	}
+	if err := sc.setEntry(hk, m, opts); err != nil {
+		sc.deleteLocal(hk)
+		return 0, err
+	}
+	return m.count, nil
+}
+
+// assemble reads the chunks of m, stored under key, and decodes the value. A chunk that
+// is missing or belongs to another generation makes the whole value a miss.
+func (sc *StrategicCache) assemble(key string, m *chunkManifest) (interface{}, error) {
+	return sc.decodeChunks(key, m, func(i int) interface{} {
+		value, _, _ := sc.getStored(sc.HashKey(chunkKey(key, i)))
+		return value
+	})
+}
+
+// assembleFrom is assemble reading the chunks from pieces (see storedChunks), so the
+// reads are not counted as accesses
+func (sc *StrategicCache) assembleFrom(key string, m *chunkManifest, pieces map[string]chunkPiece) (interface{}, error) {
+	return sc.decodeChunks(key, m, func(i int) interface{} {
+		if piece, ok := pieces[chunkKey(key, i)]; ok {
+			return piece
+		}
+		return nil
+	})
+}
+
+// storedChunks collects the live chunks held by the cache by key, copied the way
+// snapshots copy entries. It returns nil when ChunkThreshold is not set.
+func (sc *StrategicCache) storedChunks() map[string]chunkPiece {
+	if sc.config.ChunkThreshold <= 0 {
+		return nil
+	}
+	return chunksOf(sc.snapshotShards())
+}
+
+// chunksOf collects the chunks among snapshot records by key
+func chunksOf(shards [][]snapshotRecord) map[string]chunkPiece {
+	pieces := make(map[string]chunkPiece)
+	for _, records := range shards {
+		for _, r := range records {
+			if piece, ok := r.value.(chunkPiece); ok {
+				pieces[r.key] = piece
+			}
+		}
+	}
+	return pieces
+}
+
+// decodeChunks joins the chunks of m returned by chunk (nil for a missing one) and
+// decodes the value
+func (sc *StrategicCache) decodeChunks(key string, m *chunkManifest, chunk func(i int) interface{}) (interface{}, error) {
+	data := make([]byte, 0, m.size)
+	for i := 0; i < m.count; i++ {
+		piece, isPiece := chunk(i).(chunkPiece)
+		if !isPiece || piece.gen != m.gen {
+			return nil, ErrNotFound
+		}
+		data = append(data, piece.data...)
+	}
+	if len(data) != m.size {
+		return nil, ErrNotFound
+	}
+
+	if m.compressed {
+		value, ok := decodeCompressed(data, sc.serializer)
+		if !ok {
+			sc.logUndecodable(key)
+			return nil, ErrNotSerializable
+		}
+		return value, nil
+	}
+	if m.tag == tagBytes {
+		return data, nil // Already a fresh copy
+	}
+	value, err := decodeTyped(m.tag, data, sc.serializer)
+	if err != nil {
+		sc.logUndecodable(key)
+		return nil, ErrNotSerializable
+	}
+	return value, nil
+}
+
+// deleteChunks removes the chunks of key's value from index from on, up to the first
+// one missing
+func (sc *StrategicCache) deleteChunks(key string, from int) {
+	for i := from; sc.deleteEntry(sc.HashKey(chunkKey(key, i))); i++ {
+	}
+}
+
+// dropChunked removes what is left of a chunked value once its manifest or one of its
+// chunks has been evicted, so that a partial value is never assembled
+func (sc *StrategicCache) dropChunked(e evictedEntry) {
+	switch v := e.value.(type) {
+	case *chunkManifest:
+		sc.deleteChunks(e.key, 0)
+	case chunkPiece:
+		key, ok := chunkOwner(e.key)
+		if !ok {
+			return
+		}
+		sc.untag(key)
+		sc.deleteEntry(sc.HashKey(key))
+		for i := 0; i < v.count; i++ {
+			sc.deleteEntry(sc.HashKey(chunkKey(key, i)))
+		}
+	}
+}
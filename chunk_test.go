@@ -0,0 +1,240 @@
+// chunk_test.go: Tests for chunked storage of large values
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chunkKeys returns the chunk entries held by cache
+func chunkKeys(cache *StrategicCache) []string {
+	var keys []string
+	for key := range cache.storedChunks() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestChunk_RoundTrip(t *testing.T) {
+	blob := bytes.Repeat([]byte("0123456789abcdef"), 640) // 10 KiB, 10 chunks
+	text := strings.Repeat("metis ", 1000)
+
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		for _, compress := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/compression=%v", policy, compress), func(t *testing.T) {
+				cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: policy, EnableCompression: compress, ChunkThreshold: 1024, DisableBackgroundCleanup: true})
+				defer cache.Close()
+
+				cache.Set("blob", blob)
+				cache.Set("text", text)
+				cache.Set("small", "fits in one entry")
+				if v, ok := cache.Get("blob"); !ok || !bytes.Equal(v.([]byte), blob) {
+					t.Fatalf("expected the blob reassembled, got %T (%v)", v, ok)
+				}
+				if b, ok := cache.GetBytes("blob"); !ok || !bytes.Equal(b, blob) {
+					t.Errorf("expected GetBytes to reassemble the blob, got %d bytes (%v)", len(b), ok)
+				}
+				if v, ok := cache.Get("text"); !ok || v != text {
+					t.Errorf("expected the text reassembled with its type, got %T (%v)", v, ok)
+				}
+				if v, ok := cache.Get("small"); !ok || v != "fits in one entry" {
+					t.Errorf("expected the small value stored whole, got %v (%v)", v, ok)
+				}
+
+				if !compress {
+					stored := make(map[string]bool)
+					for _, key := range chunkKeys(cache) {
+						stored[key] = true
+					}
+					shards := make(map[uint32]bool)
+					for i := 0; i < 10; i++ {
+						shards[cache.HashKey(chunkKey("blob", i)).shard] = true
+						if !stored[chunkKey("blob", i)] {
+							t.Errorf("expected chunk %d of the blob stored", i)
+						}
+					}
+					if len(shards) < 2 {
+						t.Errorf("expected the chunks spread across shards, got %d", len(shards))
+					}
+				}
+				visited := 0
+				cache.Range(func(key string, value interface{}) bool {
+					visited++
+					return true
+				})
+				if visited != 3 {
+					t.Errorf("expected Range to visit the three values and no chunk, got %d entries", visited)
+				}
+
+				cache.Delete("blob")
+				if _, ok := cache.Get("blob"); ok {
+					t.Error("expected the blob deleted")
+				}
+				cache.Set("text", "short now")
+				if keys := chunkKeys(cache); len(keys) != 0 {
+					t.Errorf("expected no chunks left, got %d", len(keys))
+				}
+				if n := cache.Len(); n != 2 {
+					t.Errorf("expected 2 entries left, got %d (%v)", n, cache.Keys())
+				}
+			})
+		}
+	}
+}
+
+func TestChunk_HiddenFromCallers(t *testing.T) {
+	blob := bytes.Repeat([]byte("x"), 3000) // 3 chunks
+
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: policy, ChunkThreshold: 1024})
+			defer cache.Close()
+			cache.Set("big", blob)
+			cache.Set("small", "value")
+
+			if len(chunkKeys(cache)) != 3 {
+				t.Fatalf("expected 3 chunks stored, got %v", chunkKeys(cache))
+			}
+			if keys := cache.Keys(); len(keys) != 2 {
+				t.Errorf("expected Keys to leave chunks out, got %q", keys)
+			}
+			if n := cache.Len(); n != 2 {
+				t.Errorf("expected Len 2, got %d", n)
+			}
+			if n := cache.GetStats().Keys; n != 2 {
+				t.Errorf("expected 2 keys in GetStats, got %d", n)
+			}
+			if value, ok := cache.Get(chunkKey("big", 0)); ok {
+				t.Errorf("expected a miss for a chunk key, got %T", value)
+			}
+
+			seen := map[string]interface{}{}
+			cache.Range(func(key string, value interface{}) bool {
+				seen[key] = value
+				return true
+			})
+			if len(seen) != 2 || !bytes.Equal(seen["big"].([]byte), blob) {
+				t.Errorf("expected Range to visit the reassembled value and nothing else, got %d entries", len(seen))
+			}
+
+			path := filepath.Join(t.TempDir(), "snapshot")
+			if err := cache.SaveToFile(path); err != nil {
+				t.Fatalf("SaveToFile: %v", err)
+			}
+			restored := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: policy, ChunkThreshold: 1024})
+			defer restored.Close()
+			if n, err := restored.LoadFromFile(path); err != nil || n != 2 {
+				t.Fatalf("expected 2 entries loaded, got %d, %v", n, err)
+			}
+			if value, ok := restored.Get("big"); !ok || !bytes.Equal(value.([]byte), blob) {
+				t.Error("expected the chunked value to survive a snapshot")
+			}
+		})
+	}
+}
+
+func TestChunk_ShorterValueDropsTrailingChunks(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: "lru", ChunkThreshold: 100})
+	defer cache.Close()
+
+	cache.Set("k", make([]byte, 1000))
+	cache.Set("k", make([]byte, 250))
+	if n := len(chunkKeys(cache)); n != 3 {
+		t.Errorf("expected the 3 chunks of the shorter value, got %d", n)
+	}
+	if b, ok := cache.GetBytes("k"); !ok || len(b) != 250 {
+		t.Errorf("expected the shorter value, got %d bytes (%v)", len(b), ok)
+	}
+}
+
+func TestChunk_MissingChunkIsAMiss(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: policy, ChunkThreshold: 64})
+			defer cache.Close()
+
+			cache.Set("k", strings.Repeat("x", 1000))
+			cache.deleteEntry(cache.HashKey(chunkKey("k", 5)))
+			if v, ok := cache.Get("k"); ok {
+				t.Errorf("expected a miss with a chunk missing, got %d bytes", len(v.(string)))
+			}
+			if _, ok := cache.GetBytes("k"); ok {
+				t.Error("expected GetBytes to miss with a chunk missing")
+			}
+		})
+	}
+}
+
+func TestChunk_EvictionDropsWholeValue(t *testing.T) {
+	value := bytes.Repeat([]byte{7}, 4096)
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 64, ShardCount: 4, TTL: time.Minute, EvictionPolicy: policy, ChunkThreshold: 512, DisableBackgroundCleanup: true})
+			defer cache.Close()
+
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("big-%d", i)
+				cache.Set(key, value)
+				cache.Set(fmt.Sprintf("small-%d", i), i)
+
+				// Every value is either whole or gone, and gone values leave no chunks
+				for j := 0; j <= i; j++ {
+					key := fmt.Sprintf("big-%d", j)
+					if b, ok := cache.GetBytes(key); ok && !bytes.Equal(b, value) {
+						t.Fatalf("expected %s whole or missing, got %d bytes", key, len(b))
+					}
+				}
+			}
+			stored := make(map[string]bool)
+			for _, key := range cache.Keys() {
+				stored[key] = true
+			}
+			for _, ck := range chunkKeys(cache) {
+				owner, _ := chunkOwner(ck)
+				if !stored[owner] {
+					t.Errorf("expected the chunks of an evicted value removed, found %q", ck)
+				}
+			}
+		})
+	}
+}
+
+func TestChunk_ConcurrentSetDeleteGet(t *testing.T) {
+	a := bytes.Repeat([]byte("a"), 5000)
+	b := bytes.Repeat([]byte("b"), 3000)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute, EvictionPolicy: "lru", ChunkThreshold: 256})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 300; i++ {
+				switch (w + i) % 3 {
+				case 0:
+					cache.Set("k", a)
+				case 1:
+					cache.Set("k", b)
+				default:
+					cache.Delete("k")
+				}
+				if v, ok := cache.GetBytes("k"); ok && !bytes.Equal(v, a) && !bytes.Equal(v, b) {
+					t.Errorf("expected a whole value or a miss, got %d bytes", len(v))
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
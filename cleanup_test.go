@@ -0,0 +1,99 @@
+// cleanup_test.go: Tests for the background expiration sweep
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// goroutinesStarted returns how many goroutines NewStrategicCache leaves running for config
+func goroutinesStarted(t *testing.T, config CacheConfig) int {
+	t.Helper()
+	before := settledGoroutines()
+	cache := NewStrategicCache(config)
+	defer cache.Close()
+	return settledGoroutines() - before
+}
+
+// settledGoroutines returns runtime.NumGoroutine once it stops changing, so goroutines
+// still starting, or exiting from earlier tests, are not miscounted
+func settledGoroutines() int {
+	n := runtime.NumGoroutine()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		m := runtime.NumGoroutine()
+		if m == n {
+			break
+		}
+		n = m
+	}
+	return n
+}
+
+func TestCleanup_GoroutinesIndependentOfShardCount(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			config := CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       10000,
+				TTL:             time.Minute,
+				CleanupInterval: time.Minute,
+				EvictionPolicy:  policy,
+			}
+			config.ShardCount = 1
+			few := goroutinesStarted(t, config)
+			config.ShardCount = 128
+			many := goroutinesStarted(t, config)
+
+			if few != 1 || many != 1 {
+				t.Errorf("expected one cleanup goroutine per cache, got %d with 1 shard and %d with 128", few, many)
+			}
+		})
+	}
+}
+
+func TestCleanup_SweepsEveryShard(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       10000,
+				ShardCount:      64,
+				EvictionPolicy:  policy,
+				TTL:             time.Minute,
+				CleanupInterval: 10 * time.Second,
+				Clock:           clock,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 500; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+			clock.Advance(50 * time.Second)
+			cache.Set("fresh", "v")
+
+			// The sweep runs on the cleanup goroutine, so keep ticking until it catches up
+			deadline := time.Now().Add(200 * time.Millisecond)
+			for cache.GetStats().Keys > 1 && time.Now().Before(deadline) {
+				clock.Advance(10 * time.Second)
+				time.Sleep(time.Millisecond)
+			}
+			if keys := cache.GetStats().Keys; keys != 1 {
+				t.Errorf("expected only the fresh key after a sweep, %d keys remain", keys)
+			}
+			if _, ok := cache.Get("fresh"); !ok {
+				t.Error("expected the unexpired key to survive the sweep")
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// clear_test.go: Tests for clearing one shard or the expired entries
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestClearShard_OnlyTargetShard(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 4000, ShardCount: 4, EvictionPolicy: policy, TTL: time.Hour})
+			defer cache.Close()
+			for i := 0; i < 400; i++ {
+				cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithTags("tag"))
+			}
+
+			before := cache.ShardStats()
+			target := -1
+			for _, s := range before {
+				if s.Keys > 0 {
+					target = s.Index
+					break
+				}
+			}
+			if target < 0 {
+				t.Fatal("expected a shard holding keys")
+			}
+			if err := cache.ClearShard(target); err != nil {
+				t.Fatalf("ClearShard(%d): %v", target, err)
+			}
+
+			after := cache.ShardStats()
+			for i, s := range after {
+				want := before[i].Keys
+				if i == target {
+					want = 0
+				}
+				if s.Keys != want {
+					t.Errorf("shard %d: expected %d keys, got %d", i, want, s.Keys)
+				}
+			}
+			if got, want := cache.Len(), 400-before[target].Keys; got != want {
+				t.Errorf("expected %d keys left, got %d", want, got)
+			}
+			if tagged := cache.GetStats().TaggedKeys; tagged != cache.Len() {
+				t.Errorf("expected the tag index pruned to the %d keys left, got %d", cache.Len(), tagged)
+			}
+		})
+	}
+}
+
+func TestClearShard_Bounds(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 4, EvictionPolicy: policy, TTL: time.Hour})
+			cache.Set("k", 1)
+			n := len(cache.ShardStats())
+			for _, i := range []int{-1, n, n + 100} {
+				if err := cache.ClearShard(i); !errors.Is(err, ErrInvalidShard) {
+					t.Errorf("ClearShard(%d): expected ErrInvalidShard, got %v", i, err)
+				}
+			}
+			if _, ok := cache.Get("k"); !ok {
+				t.Error("expected a rejected ClearShard to leave the cache alone")
+			}
+			cache.Close()
+			if err := cache.ClearShard(0); !errors.Is(err, ErrCacheClosed) {
+				t.Errorf("expected ErrCacheClosed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClearExpired(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:            true,
+				CacheSize:                1000,
+				ShardCount:               4,
+				TTL:                      time.Hour,
+				EvictionPolicy:           policy,
+				DisableBackgroundCleanup: true,
+				Clock:                    clock,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 30; i++ {
+				cache.SetWithOptions(fmt.Sprintf("short%d", i), i, WithTTL(time.Minute))
+			}
+			for i := 0; i < 20; i++ {
+				cache.Set(fmt.Sprintf("live%d", i), i)
+			}
+			if n := cache.ClearExpired(); n != 0 {
+				t.Errorf("expected nothing removed before the TTL, got %d", n)
+			}
+
+			clock.Advance(2 * time.Minute)
+			if n := cache.ClearExpired(); n != 30 {
+				t.Errorf("expected the 30 expired entries removed, got %d", n)
+			}
+			if n := cache.Len(); n != 20 {
+				t.Errorf("expected the 20 live entries kept, got %d", n)
+			}
+			if stats := cache.GetStats(); stats.Expirations != 30 {
+				t.Errorf("expected 30 expirations, got %d", stats.Expirations)
+			}
+			if n := cache.ClearExpired(); n != 0 {
+				t.Errorf("expected a second pass to find nothing, got %d", n)
+			}
+
+			cache.Close()
+			if n := cache.ClearExpired(); n != 0 {
+				t.Errorf("expected 0 once closed, got %d", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// clock.go: Time source for TTL handling in Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "time"
+
+// Clock is the time source a cache uses to stamp and check entry expiration and to drive
+// the expired-entry cleanup. Install one with CacheConfig.Clock; the metistest package
+// provides a fake that tests advance by hand instead of sleeping.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTicker delivers the time on the returned channel every d until stop is called
+	NewTicker(d time.Duration) (ticks <-chan time.Time, stop func())
+}
+
+// realClock is the default Clock, backed by the time package
+type realClock struct{}
+
+// Now returns time.Now()
+func (realClock) Now() time.Time { return time.Now() }
+
+// NewTicker wraps time.NewTicker
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
@@ -0,0 +1,75 @@
+// clock_test.go: Tests for Clock injection
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+var _ Clock = (*fakeclock.Clock)(nil)
+
+func TestClock_DefaultsToSystemClock(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10})
+	defer cache.Close()
+	if _, ok := cache.clock.(realClock); !ok {
+		t.Errorf("expected the system clock by default, got %T", cache.clock)
+	}
+}
+
+func TestClock_CleanupRoutineSweepsOnFakeTicks(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      2,
+				EvictionPolicy:  policy,
+				TTL:             time.Minute,
+				CleanupInterval: 10 * time.Second,
+				Clock:           clock,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 20; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+			clock.Advance(30 * time.Second)
+			if keys := cache.GetStats().Keys; keys != 20 {
+				t.Fatalf("expected nothing swept before the TTL, %d keys remain", keys)
+			}
+
+			// The sweep runs on the cleanup goroutines, so keep ticking until they catch up
+			deadline := time.Now().Add(time.Second)
+			for cache.GetStats().Keys > 0 && time.Now().Before(deadline) {
+				clock.Advance(10 * time.Second)
+				time.Sleep(time.Millisecond)
+			}
+			if keys := cache.GetStats().Keys; keys != 0 {
+				t.Errorf("expected the sweep to drop every expired key, %d remain", keys)
+			}
+		})
+	}
+}
+
+func TestClock_AdminEntryTTL(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, TTL: time.Minute, Clock: clock})
+	defer cache.Close()
+
+	cache.Set("k", "v")
+	clock.Advance(15 * time.Second)
+	var entry AdminEntry
+	adminRequest(t, Handler(cache), "GET", "/entry/k", &entry)
+	if entry.TTL != "45s" || !entry.ExpiresAt.Equal(clock.Now().Add(45*time.Second)) {
+		t.Errorf("expected 45s left on the fake clock, got %+v", entry)
+	}
+}
@@ -0,0 +1,249 @@
+// cluster.go: Consistent hashing over several cache instances for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultVirtualNodes is the number of ring points per node when ClusterOptions.VirtualNodes
+// is not set, enough to keep each node's share of the keys within a few percent of even
+const DefaultVirtualNodes = 160
+
+// ErrNoNodes is returned by a ClusterClient without nodes
+var ErrNoNodes = errors.New("metis: cluster has no nodes")
+
+// ClusterOptions configures a ClusterClient
+type ClusterOptions struct {
+	// VirtualNodes is the number of points each node takes on the hash ring. More points
+	// spread keys more evenly at the cost of a larger ring. Default: DefaultVirtualNodes.
+	VirtualNodes int
+}
+
+// ClusterClient spreads keys over several RemoteCache nodes with a consistent-hash ring,
+// so adding or removing one of N nodes moves only about 1/N of the keys. Nodes are placed
+// by name: clients in different processes given the same names and VirtualNodes route
+// every key to the same node. ClusterClient is itself a RemoteCache, so it can be the L2
+// of a TieredCache. Keys are not migrated when nodes change; a moved key reads as a miss
+// until it is set again.
+type ClusterClient struct {
+	vnodes int
+	mu     sync.Mutex // Serializes AddNode and RemoveNode
+	ring   atomic.Pointer[hashRing]
+}
+
+var _ RemoteCache = (*ClusterClient)(nil)
+
+// hashRing is an immutable ring, replaced as a whole when nodes change
+type hashRing struct {
+	points []ringPoint // Sorted by hash
+	nodes  map[string]RemoteCache
+}
+
+// ringPoint is one virtual node
+type ringPoint struct {
+	hash uint64
+	node string
+}
+
+// NewClusterClient creates a ClusterClient over nodes, keyed by their names
+func NewClusterClient(nodes map[string]RemoteCache, opts ClusterOptions) *ClusterClient {
+	if opts.VirtualNodes <= 0 {
+		opts.VirtualNodes = DefaultVirtualNodes
+	}
+	cc := &ClusterClient{vnodes: opts.VirtualNodes}
+	cc.ring.Store(newHashRing(nodes, cc.vnodes))
+	return cc
+}
+
+// newHashRing places vnodes points for each of nodes
+func newHashRing(nodes map[string]RemoteCache, vnodes int) *hashRing {
+	r := &hashRing{points: make([]ringPoint, 0, len(nodes)*vnodes), nodes: make(map[string]RemoteCache, len(nodes))}
+	for name, node := range nodes {
+		r.nodes[name] = node
+		for i := 0; i < vnodes; i++ {
+			r.points = append(r.points, ringPoint{hash: ringHash(name + "#" + strconv.Itoa(i)), node: name})
+		}
+	}
+	// Ties between points are broken by name, so every client builds the same ring
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].node < r.points[j].node
+	})
+	return r
+}
+
+// ringHash is FNV-1a finished with the MurmurHash3 mixer, which spreads the near-identical
+// names of virtual nodes over the whole ring. Unlike the shard Hasher it is unseeded, so
+// every process agrees on it.
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// owner returns the name and node holding key: the first point clockwise from its hash
+func (r *hashRing) owner(key string) (string, RemoteCache, bool) {
+	if len(r.points) == 0 {
+		return "", nil, false
+	}
+	h := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	name := r.points[i].node
+	return name, r.nodes[name], true
+}
+
+// Node returns the name of the node key is routed to, or "" without nodes
+func (cc *ClusterClient) Node(key string) string {
+	name, _, _ := cc.ring.Load().owner(key)
+	return name
+}
+
+// Nodes returns the names of the nodes, sorted
+func (cc *ClusterClient) Nodes() []string {
+	r := cc.ring.Load()
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddNode adds a node under name. Only keys whose ring position now falls to it move,
+// about 1/N of them. It fails if name is already in use.
+func (cc *ClusterClient) AddNode(name string, node RemoteCache) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	r := cc.ring.Load()
+	if _, ok := r.nodes[name]; ok {
+		return fmt.Errorf("cluster node %q already exists", name)
+	}
+	nodes := make(map[string]RemoteCache, len(r.nodes)+1)
+	for n, c := range r.nodes {
+		nodes[n] = c
+	}
+	nodes[name] = node
+	cc.ring.Store(newHashRing(nodes, cc.vnodes))
+	return nil
+}
+
+// RemoveNode removes the node named name, reporting whether it was present. Its keys
+// move to the other nodes; the keys of the other nodes stay where they are.
+func (cc *ClusterClient) RemoveNode(name string) bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	r := cc.ring.Load()
+	if _, ok := r.nodes[name]; !ok {
+		return false
+	}
+	nodes := make(map[string]RemoteCache, len(r.nodes)-1)
+	for n, c := range r.nodes {
+		if n != name {
+			nodes[n] = c
+		}
+	}
+	cc.ring.Store(newHashRing(nodes, cc.vnodes))
+	return true
+}
+
+// Get returns the value for key from the node it is routed to. Misses are ErrNotFound;
+// node failures are wrapped in ErrBackend.
+func (cc *ClusterClient) Get(ctx context.Context, key string) ([]byte, error) {
+	name, node, ok := cc.ring.Load().owner(key)
+	if !ok {
+		return nil, ErrNoNodes
+	}
+	value, err := node.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: node %q get %q: %w", ErrBackend, name, key, err)
+	}
+	return value, err
+}
+
+// Set stores a value on the node key is routed to
+func (cc *ClusterClient) Set(ctx context.Context, key string, value []byte) error {
+	name, node, ok := cc.ring.Load().owner(key)
+	if !ok {
+		return ErrNoNodes
+	}
+	if err := node.Set(ctx, key, value); err != nil {
+		return fmt.Errorf("%w: node %q set %q: %w", ErrBackend, name, key, err)
+	}
+	return nil
+}
+
+// Delete removes key from the node it is routed to
+func (cc *ClusterClient) Delete(ctx context.Context, key string) error {
+	name, node, ok := cc.ring.Load().owner(key)
+	if !ok {
+		return ErrNoNodes
+	}
+	if err := node.Delete(ctx, key); err != nil {
+		return fmt.Errorf("%w: node %q delete %q: %w", ErrBackend, name, key, err)
+	}
+	return nil
+}
+
+// localNode is the RemoteCache returned by LocalNode
+type localNode struct {
+	cache *StrategicCache
+}
+
+// LocalNode adapts an in-process cache to RemoteCache, so it can be a ClusterClient node
+// or the L2 of a TieredCache. Values are byte slices stored with SetE and read with
+// GetBytes; the slices Get returns may share memory with the cache.
+func LocalNode(c *StrategicCache) RemoteCache {
+	return localNode{cache: c}
+}
+
+// Get implements RemoteCache
+func (n localNode) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	value, ok := n.cache.GetBytes(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// Set implements RemoteCache
+func (n localNode) Set(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.cache.SetE(key, value)
+}
+
+// Delete implements RemoteCache
+func (n localNode) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.cache.Delete(key)
+	return nil
+}
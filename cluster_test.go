@@ -0,0 +1,189 @@
+// cluster_test.go: Tests for the consistent-hashing cluster client
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newClusterNodes returns n in-memory nodes named node0, node1...
+func newClusterNodes(t *testing.T, n int) map[string]RemoteCache {
+	t.Helper()
+	nodes := make(map[string]RemoteCache, n)
+	for i := 0; i < n; i++ {
+		cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100000, TTL: time.Hour, EvictionPolicy: "lru"})
+		t.Cleanup(cache.Close)
+		nodes[fmt.Sprintf("node%d", i)] = LocalNode(cache)
+	}
+	return nodes
+}
+
+// owners returns the node each of n keys is routed to
+func owners(cc *ClusterClient, n int) map[string]string {
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("user:%d", i)
+		m[key] = cc.Node(key)
+	}
+	return m
+}
+
+func TestCluster_RoutesAndStores(t *testing.T) {
+	nodes := newClusterNodes(t, 3)
+	cc := NewClusterClient(nodes, ClusterOptions{})
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		if err := cc.Set(ctx, fmt.Sprintf("k%d", i), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("k%d", i)
+		value, err := cc.Get(ctx, key)
+		if err != nil || string(value) != fmt.Sprint(i) {
+			t.Fatalf("Get(%s) = %q, %v", key, value, err)
+		}
+		// Only the owner holds the key
+		for name, node := range nodes {
+			_, err := node.Get(ctx, key)
+			if held := err == nil; held != (name == cc.Node(key)) {
+				t.Errorf("%s: expected only %s to hold it, %s holds it: %v", key, cc.Node(key), name, held)
+			}
+		}
+	}
+
+	if err := cc.Delete(ctx, "k1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := cc.Get(ctx, "k1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after Delete, got %v", err)
+	}
+	if got := cc.Nodes(); fmt.Sprint(got) != "[node0 node1 node2]" {
+		t.Errorf("unexpected nodes %v", got)
+	}
+}
+
+func TestCluster_RemoveNodeRemapsOneNth(t *testing.T) {
+	const keys, n = 20000, 5
+	cc := NewClusterClient(newClusterNodes(t, n), ClusterOptions{})
+	before := owners(cc, keys)
+
+	// Each node gets close to an even share
+	share := make(map[string]int)
+	for _, node := range before {
+		share[node]++
+	}
+	for node, count := range share {
+		if count < keys/n*7/10 || count > keys/n*13/10 {
+			t.Errorf("expected %s to hold about %d keys, got %d", node, keys/n, count)
+		}
+	}
+
+	if !cc.RemoveNode("node2") || cc.RemoveNode("node2") {
+		t.Fatal("expected node2 to be removed once")
+	}
+	moved := 0
+	for key, node := range owners(cc, keys) {
+		if node != before[key] {
+			moved++
+			if before[key] != "node2" {
+				t.Fatalf("%s moved from %s, which was not removed", key, before[key])
+			}
+		}
+	}
+	if frac := float64(moved) / keys; frac < 0.7/n || frac > 1.3/n {
+		t.Errorf("expected about 1/%d of the keys to move, got %.3f", n, frac)
+	}
+	if moved != share["node2"] {
+		t.Errorf("expected exactly node2's %d keys to move, got %d", share["node2"], moved)
+	}
+}
+
+func TestCluster_AddNodeOnlyTakesKeys(t *testing.T) {
+	const keys = 20000
+	nodes := newClusterNodes(t, 5)
+	extra := nodes["node4"]
+	delete(nodes, "node4")
+	cc := NewClusterClient(nodes, ClusterOptions{VirtualNodes: 64})
+	before := owners(cc, keys)
+
+	if err := cc.AddNode("node4", extra); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := cc.AddNode("node4", extra); err == nil {
+		t.Error("expected a duplicate name to be rejected")
+	}
+	moved := 0
+	for key, node := range owners(cc, keys) {
+		if node != before[key] {
+			moved++
+			if node != "node4" {
+				t.Fatalf("%s moved to %s instead of the new node", key, node)
+			}
+		}
+	}
+	if frac := float64(moved) / keys; frac < 0.1 || frac > 0.3 {
+		t.Errorf("expected about 1/5 of the keys to move, got %.3f", frac)
+	}
+
+	// A client built with the same names routes the same way
+	same := NewClusterClient(newClusterNodes(t, 5), ClusterOptions{VirtualNodes: 64})
+	for key, node := range owners(same, 1000) {
+		if cc.Node(key) != node {
+			t.Fatalf("expected %s on %s in both clients, got %s", key, node, cc.Node(key))
+		}
+	}
+}
+
+// failingNode is a RemoteCache whose calls all fail
+type failingNode struct{}
+
+func (failingNode) Get(context.Context, string) ([]byte, error) { return nil, errors.New("down") }
+func (failingNode) Set(context.Context, string, []byte) error   { return errors.New("down") }
+func (failingNode) Delete(context.Context, string) error        { return errors.New("down") }
+
+func TestCluster_Errors(t *testing.T) {
+	ctx := context.Background()
+	empty := NewClusterClient(nil, ClusterOptions{})
+	if _, err := empty.Get(ctx, "k"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("expected ErrNoNodes, got %v", err)
+	}
+	if err := empty.Set(ctx, "k", nil); !errors.Is(err, ErrNoNodes) || empty.Node("k") != "" {
+		t.Errorf("expected ErrNoNodes, got %v", err)
+	}
+
+	cc := NewClusterClient(map[string]RemoteCache{"bad": failingNode{}}, ClusterOptions{})
+	if _, err := cc.Get(ctx, "k"); !errors.Is(err, ErrBackend) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a node failure wrapped in ErrBackend, got %v", err)
+	}
+	if err := cc.Set(ctx, "k", []byte("v")); !errors.Is(err, ErrBackend) {
+		t.Errorf("expected ErrBackend, got %v", err)
+	}
+}
+
+func TestCluster_AsTieredL2(t *testing.T) {
+	cc := NewClusterClient(newClusterNodes(t, 3), ClusterOptions{})
+	l1 := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour, EvictionPolicy: "lru"})
+	defer l1.Close()
+	tc := NewTieredCache(l1, cc, TieredOptions{WriteThrough: true})
+
+	ctx := context.Background()
+	if err := tc.Set(ctx, "k", []byte("value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	l1.Clear()
+	value, err := tc.Get(ctx, "k")
+	if err != nil || !bytes.Equal(value.([]byte), []byte("value")) {
+		t.Errorf("expected the value from the cluster, got %v, %v", value, err)
+	}
+}
@@ -11,18 +11,20 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
 
 // CLITestHelper provides utilities for testing the CLI application
 type CLITestHelper struct {
-	t       *testing.T
+	t       testing.TB
 	mainDir string // Directory where main.go is located
+	binary  string // CLI built by buildCLI, reused across runs
 }
 
 // NewCLITestHelper creates a new CLI test helper
-func NewCLITestHelper(t *testing.T) *CLITestHelper {
+func NewCLITestHelper(t testing.TB) *CLITestHelper {
 	mainDir, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("Failed to get working directory: %v", err)
@@ -34,69 +36,46 @@ func NewCLITestHelper(t *testing.T) *CLITestHelper {
 	}
 }
 
-// RunCLIWithInput runs the CLI with simulated user input using subprocess
-func (h *CLITestHelper) RunCLIWithInput(input string) (string, string, int) {
-	// Create temporary directory for test execution
-	tempDir := h.t.TempDir()
-
-	// Build command to run CLI from the main directory but execute in temp directory
-	// This ensures metis.json is created in the temp directory
-	mainPath := filepath.Join(h.mainDir, "main.go")
-
-	// Validate path to prevent command injection
-	// Check for shell metacharacters and ensure it's a valid Go file
-	if strings.ContainsAny(mainPath, ";&|`$(){}[]<>\"'") {
-		h.t.Fatalf("Invalid path containing shell metacharacters: %s", mainPath)
+// buildCLI compiles the CLI into a temporary directory. main.go imports the metis
+// module, so it cannot be run with "go run" from a directory outside the module.
+func (h *CLITestHelper) buildCLI() string {
+	if h.binary != "" {
+		return h.binary
 	}
-
-	// Ensure the file exists and is a Go file
-	if !strings.HasSuffix(mainPath, ".go") {
-		h.t.Fatalf("Invalid file extension: %s", mainPath)
+	binary := filepath.Join(h.t.TempDir(), "metis-cli")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
 	}
 
-	// Use exec.Command with hardcoded "go" and "run" commands, only mainPath is variable
-	// The mainPath is validated above and contains only safe characters
-	cmd := exec.Command("go", "run", mainPath)
-	cmd.Dir = tempDir // Run from temp directory
-	cmd.Stdin = strings.NewReader(input)
-
-	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
-	exitCode := 0
-
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			// If it's not an ExitError, it's likely a build/execution error
-			exitCode = 1
-		}
+	// Use exec.Command with hardcoded "go" and "build" commands, only the output path is variable
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = h.mainDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		h.t.Fatalf("Failed to build CLI: %v\n%s", err, output)
 	}
+	h.binary = binary
+	return binary
+}
 
-	return string(output), "", exitCode
+// RunCLIWithInput runs the CLI with simulated user input using subprocess
+func (h *CLITestHelper) RunCLIWithInput(input string) (string, string, int) {
+	// Create temporary directory for test execution
+	// This ensures metis.json is created in the temp directory
+	return h.RunCLIWithInputInDir(input, h.t.TempDir())
 }
 
 // RunCLIWithInputInDir runs CLI in a specific directory
 func (h *CLITestHelper) RunCLIWithInputInDir(input string, workDir string) (string, string, int) {
-	mainPath := filepath.Join(h.mainDir, "main.go")
-
-	// Validate path to prevent command injection
-	// Check for shell metacharacters and ensure it's a valid Go file
-	if strings.ContainsAny(mainPath, ";&|`$(){}[]<>\"'") {
-		h.t.Fatalf("Invalid path containing shell metacharacters: %s", mainPath)
-	}
-
-	// Ensure the file exists and is a Go file
-	if !strings.HasSuffix(mainPath, ".go") {
-		h.t.Fatalf("Invalid file extension: %s", mainPath)
-	}
+	return h.RunCLIWithArgsInDir(input, workDir)
+}
 
-	// Use exec.Command with hardcoded "go" and "run" commands, only mainPath is variable
-	// The mainPath is validated above and contains only safe characters
-	cmd := exec.Command("go", "run", mainPath)
+// RunCLIWithArgsInDir runs CLI with command-line arguments in a specific directory
+func (h *CLITestHelper) RunCLIWithArgsInDir(input string, workDir string, args ...string) (string, string, int) {
+	cmd := exec.Command(h.buildCLI(), args...)
 	cmd.Dir = workDir
 	cmd.Stdin = strings.NewReader(input)
 
+	// Capture both stdout and stderr
 	output, err := cmd.CombinedOutput()
 	exitCode := 0
 
@@ -104,6 +83,7 @@ func (h *CLITestHelper) RunCLIWithInputInDir(input string, workDir string) (stri
 		if exitError, ok := err.(*exec.ExitError); ok {
 			exitCode = exitError.ExitCode()
 		} else {
+			// If it's not an ExitError, it's likely an execution error
 			exitCode = 1
 		}
 	}
@@ -0,0 +1,219 @@
+// /cmd/metis-cli/generate.go: Non-interactive generation and validation of config files
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// Exit codes of generate and validate
+const (
+	exitOK      = 0
+	exitInvalid = 1 // The configuration is invalid, or has warnings under -strict
+	exitUsage   = 2 // Bad flags or arguments
+)
+
+// presets are the names accepted by generate -preset, with the presets of the
+// interactive menu
+var presets = map[string]func() metis.CacheConfig{
+	"development":      metis.DevelopmentConfig,
+	"web":              metis.WebAppConfig,
+	"high-performance": metis.HighPerformanceConfig,
+	"low-memory":       metis.LowMemoryConfig,
+}
+
+// presetNames returns the preset names, sorted
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runGenerate writes a config file from a preset and flags without prompting, and
+// returns the exit code
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	preset := fs.String("preset", "", "Start from a preset: "+strings.Join(presetNames(), ", "))
+	out := fs.String("out", "metis.json", "File to write, or - for stdout")
+	format := fs.String("format", "", "json, yaml or toml (default: from the -out extension)")
+	cacheSize := fs.Int("cache-size", 0, "Maximum number of entries")
+	ttl := fs.String("ttl", "", "Default TTL (e.g. 30m, 1h, 0s for the library default)")
+	policy := fs.String("policy", "", "Eviction policy: lru, wtinylfu or arc")
+	shards := fs.Int("shards", 0, "Number of shards (a power of two)")
+	compression := fs.Bool("compression", false, "Compress large values")
+	maxValueSize := fs.Int("max-value-size", 0, "Largest value in bytes (0 = no limit)")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	var config SimpleConfig
+	if *preset != "" {
+		newPreset, ok := presets[*preset]
+		if !ok {
+			fmt.Fprintf(stderr, "Error: unknown preset %q (want one of %s)\n", *preset, strings.Join(presetNames(), ", "))
+			return exitUsage
+		}
+		config = presetConfig(newPreset())
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cache-size":
+			config.CacheSize = *cacheSize
+		case "ttl":
+			config.TTL = *ttl
+		case "policy":
+			config.EvictionPolicy = *policy
+		case "shards":
+			config.ShardCount = *shards
+		case "compression":
+			config.EnableCompression = *compression
+		case "max-value-size":
+			config.MaxValueSize = *maxValueSize
+		}
+	})
+
+	if config.TTL != "" {
+		if _, err := time.ParseDuration(config.TTL); err != nil {
+			fmt.Fprintf(stderr, "Error: -ttl %q is not a duration (e.g. 30m, 1h30m, 90s)\n", config.TTL)
+			return exitUsage
+		}
+	}
+	if *format == "" {
+		*format = metis.ConfigFormatFromPath(*out)
+	}
+	data, err := metis.MarshalConfig(config, *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	// Check the file with the library loader before it replaces anything
+	dir := os.TempDir()
+	if *out != "-" {
+		dir = filepath.Dir(*out)
+	}
+	tmp, err := os.CreateTemp(dir, ".metis-*."+*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitInvalid
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitInvalid
+	}
+	warnings, err := validateConfigFile(tmp.Name())
+	if err != nil {
+		printProblems(stderr, err, nil)
+		return exitInvalid
+	}
+	printProblems(stderr, nil, warnings)
+
+	if *out == "-" {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitInvalid
+	}
+	fmt.Fprintf(stdout, "✅ Generated %s\n", *out)
+	return exitOK
+}
+
+// runValidate checks config files with the library loader and returns the exit code
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	strict := fs.Bool("strict", false, "Fail on warnings too")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "Error: validate needs a config file, e.g. metis-cli validate metis.json")
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, path := range fs.Args() {
+		warnings, err := validateConfigFile(path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stdout, "❌ %s is invalid\n", path)
+			code = exitInvalid
+		case len(warnings) > 0 && *strict:
+			fmt.Fprintf(stdout, "❌ %s has warnings\n", path)
+			code = exitInvalid
+		default:
+			fmt.Fprintf(stdout, "✅ %s is valid\n", path)
+		}
+		printProblems(stdout, err, warnings)
+	}
+	return code
+}
+
+// validateConfigFile loads path through metis.LoadConfigFile, which reports malformed
+// files, durations that do not parse and settings CacheConfig.Validate rejects. The
+// warnings are settings that load but probably do not do what was meant.
+func validateConfigFile(path string) (warnings []string, err error) {
+	config, err := metis.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if n := config.ShardCount; n > 0 && n&(n-1) != 0 {
+		next := 1
+		for next < n {
+			next <<= 1
+		}
+		warnings = append(warnings, fmt.Sprintf("shard_count %d is not a power of two: W-TinyLFU and ARC round it up to %d, so set that or %d", n, next, next/2))
+	}
+	if config.CacheSize > 0 && config.ShardCount > config.CacheSize {
+		warnings = append(warnings, fmt.Sprintf("shard_count %d is larger than cache_size %d, so some shards can hold nothing", config.ShardCount, config.CacheSize))
+	}
+	return warnings, nil
+}
+
+// printProblems writes each joined error and warning on its own line
+func printProblems(w io.Writer, err error, warnings []string) {
+	if err != nil {
+		var joined interface{ Unwrap() []error }
+		errs := []error{err}
+		if errors.As(err, &joined) {
+			errs = joined.Unwrap()
+		}
+		for _, e := range errs {
+			fmt.Fprintf(w, "  error: %v\n", e)
+		}
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "  warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,133 @@
+// /cmd/metis-cli/generate_test.go: Tests for the generate and validate commands
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// TestRunGenerate writes presets and flag overrides without prompting
+func TestRunGenerate(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("preset", func(t *testing.T) {
+		out := filepath.Join(dir, "web.json")
+		var stdout, stderr bytes.Buffer
+		if code := runGenerate([]string{"-preset", "web", "-out", out}, &stdout, &stderr); code != exitOK {
+			t.Fatalf("exit code %d: %s", code, stderr.String())
+		}
+		config, err := metis.LoadConfigFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := metis.WebAppConfig(); config.CacheSize != want.CacheSize || config.TTL != want.TTL || config.ShardCount != want.ShardCount {
+			t.Errorf("expected the web preset, got %+v", config)
+		}
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		out := filepath.Join(dir, "custom.yaml")
+		var stdout, stderr bytes.Buffer
+		args := []string{"-cache-size", "50000", "-ttl", "30m", "-policy", "arc", "-shards", "32", "-out", out}
+		if code := runGenerate(args, &stdout, &stderr); code != exitOK {
+			t.Fatalf("exit code %d: %s", code, stderr.String())
+		}
+		config, err := metis.LoadConfigFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.CacheSize != 50000 || config.TTL != 30*time.Minute || config.EvictionPolicy != "arc" || config.ShardCount != 32 {
+			t.Errorf("expected the flags in the file, got %+v", config)
+		}
+	})
+
+	t.Run("preset_with_override_to_stdout", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := runGenerate([]string{"-preset", "development", "-policy", "lru", "-out", "-"}, &stdout, &stderr); code != exitOK {
+			t.Fatalf("exit code %d: %s", code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), `"eviction_policy": "lru"`) || !strings.Contains(stdout.String(), `"cache_size": 1000`) {
+			t.Errorf("expected the preset with the override, got %s", stdout.String())
+		}
+	})
+
+	for name, args := range map[string][]string{
+		"unknown_policy": {"-policy", "lfu"},
+		"bad_ttl":        {"-ttl", "ten minutes"},
+		"unknown_preset": {"-preset", "huge"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := filepath.Join(dir, name+".json")
+			var stdout, stderr bytes.Buffer
+			if code := runGenerate(append(args, "-out", out), &stdout, &stderr); code == exitOK {
+				t.Fatalf("expected a failure, got %s", stdout.String())
+			}
+			if _, err := os.Stat(out); !os.IsNotExist(err) {
+				t.Errorf("expected no file for an invalid configuration, got %v", err)
+			}
+			if stderr.Len() == 0 {
+				t.Error("expected an explanation on stderr")
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".metis-") {
+			t.Errorf("temporary file %s left behind", e.Name())
+		}
+	}
+}
+
+// TestRunValidate reports errors and warnings with matching exit codes
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := write("valid.json", `{"cache_size": 1000, "ttl": "10m", "shard_count": 16}`)
+	unknownPolicy := write("policy.json", `{"cache_size": 1000, "eviction_policy": "lfu"}`)
+	badTTL := write("ttl.json", `{"cache_size": 1000, "ttl": "10 minutes"}`)
+	oddShards := write("shards.json", `{"cache_size": 1000, "shard_count": 24}`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		expected string
+	}{
+		{"valid", []string{valid}, exitOK, "is valid"},
+		{"unknown_policy", []string{unknownPolicy}, exitInvalid, `unknown EvictionPolicy "lfu"`},
+		{"bad_ttl", []string{badTTL}, exitInvalid, "invalid TTL format"},
+		{"shards_warning", []string{oddShards}, exitOK, "not a power of two: W-TinyLFU and ARC round it up to 32"},
+		{"shards_strict", []string{"-strict", oddShards}, exitInvalid, "has warnings"},
+		{"several_files", []string{valid, unknownPolicy}, exitInvalid, "policy.json is invalid"},
+		{"missing_file", []string{filepath.Join(dir, "missing.json")}, exitInvalid, "is invalid"},
+		{"no_file", nil, exitUsage, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runValidate(tt.args, &stdout, &stderr); code != tt.code {
+				t.Errorf("expected exit code %d, got %d: %s%s", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.expected) {
+				t.Errorf("output missing %q:\n%s", tt.expected, stdout.String())
+			}
+		})
+	}
+}
@@ -8,24 +8,39 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/agilira/metis"
 )
 
-// SimpleConfig represents a basic configuration for Metis cache
-type SimpleConfig struct {
-	CacheSize         int    `json:"cache_size"`
-	TTL               string `json:"ttl,omitempty"`
-	EvictionPolicy    string `json:"eviction_policy,omitempty"`
-	ShardCount        int    `json:"shard_count,omitempty"`
-	EnableCompression bool   `json:"enable_compression,omitempty"`
-	MaxValueSize      int    `json:"max_value_size,omitempty"`
-}
+// SimpleConfig is the metis config file schema, shared with the library loader
+type SimpleConfig = metis.SimpleConfig
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "generate":
+			os.Exit(runGenerate(os.Args[2:], os.Stdout, os.Stderr))
+		case "validate":
+			os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+		case "plan":
+			os.Exit(runPlan(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	format := flag.String("format", metis.FormatJSON, "config file format: json, yaml or toml")
+	flag.Parse()
+	if *format != metis.FormatJSON && *format != metis.FormatYAML && *format != metis.FormatTOML {
+		fmt.Printf("Unsupported format %q: use json, yaml or toml\n", *format)
+		os.Exit(2)
+	}
+	fileName := "metis." + *format
+
 	fmt.Println("🚀 Metis Configuration Generator")
 	fmt.Println("===================================")
 	fmt.Println()
@@ -48,31 +63,13 @@ func main() {
 
 	switch choice {
 	case "1":
-		config = SimpleConfig{
-			CacheSize: 1000,
-			TTL:       "10m",
-		}
+		config = presetConfig(metis.DevelopmentConfig())
 	case "2":
-		config = SimpleConfig{
-			CacheSize:      50000,
-			TTL:            "30m",
-			EvictionPolicy: "wtinylfu",
-			ShardCount:     32,
-		}
+		config = presetConfig(metis.WebAppConfig())
 	case "3":
-		config = SimpleConfig{
-			CacheSize:      1000000,
-			TTL:            "0s",
-			EvictionPolicy: "wtinylfu",
-			ShardCount:     128,
-		}
+		config = presetConfig(metis.HighPerformanceConfig())
 	case "4":
-		config = SimpleConfig{
-			CacheSize:         10000,
-			TTL:               "1h",
-			EnableCompression: true,
-			MaxValueSize:      524288, // 512KB
-		}
+		config = presetConfig(metis.LowMemoryConfig())
 	case "5":
 		config = customConfig(reader)
 	case "6":
@@ -80,28 +77,52 @@ func main() {
 		os.Exit(0)
 	default:
 		fmt.Println("Invalid choice, using development defaults")
-		config = SimpleConfig{CacheSize: 1000}
+		config = presetConfig(metis.DevelopmentConfig())
 	}
 
-	// Generate metis.json
-	data, err := json.MarshalIndent(config, "", "  ")
+	// Generate the config file
+	data, err := metis.MarshalConfig(config, *format)
 	if err != nil {
 		fmt.Printf("Error generating config: %v\n", err)
 		return
 	}
 
-	err = os.WriteFile("metis.json", data, 0600)
+	err = os.WriteFile(fileName, data, 0600)
 	if err != nil {
-		fmt.Printf("Error writing metis.json: %v\n", err)
+		fmt.Printf("Error writing %s: %v\n", fileName, err)
 		return
 	}
 
-	fmt.Println("\n✅ Generated metis.json successfully!")
+	fmt.Printf("\n✅ Generated %s successfully!\n", fileName)
 	fmt.Println("📝 Content:")
-	fmt.Println(string(data))
+	fmt.Println(strings.TrimRight(string(data), "\n"))
 	fmt.Println("\n🚀 You can now use metis.New() in your code!")
 }
 
+// presetConfig converts one of the metis presets to its config file form
+func presetConfig(preset metis.CacheConfig) SimpleConfig {
+	return SimpleConfig{
+		CacheSize:         preset.CacheSize,
+		TTL:               formatDuration(preset.TTL),
+		EvictionPolicy:    preset.EvictionPolicy,
+		ShardCount:        preset.ShardCount,
+		EnableCompression: preset.EnableCompression,
+		MaxValueSize:      preset.MaxValueSize,
+	}
+}
+
+// formatDuration formats d without trailing zero units ("10m" rather than "10m0s")
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
 func customConfig(reader *bufio.Reader) SimpleConfig {
 	var config SimpleConfig
 
@@ -9,9 +9,13 @@ import (
 	"bufio"
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/agilira/metis"
 )
 
 // TestSimpleConfig tests the SimpleConfig struct and JSON marshaling
@@ -110,8 +114,10 @@ func TestPresetConfigurations(t *testing.T) {
 		{
 			choice: "1",
 			expected: SimpleConfig{
-				CacheSize: 1000,
-				TTL:       "10m",
+				CacheSize:      1000,
+				TTL:            "10m",
+				EvictionPolicy: "wtinylfu",
+				ShardCount:     128,
 			},
 		},
 		{
@@ -137,44 +143,24 @@ func TestPresetConfigurations(t *testing.T) {
 			expected: SimpleConfig{
 				CacheSize:         10000,
 				TTL:               "1h",
+				EvictionPolicy:    "wtinylfu",
+				ShardCount:        128,
 				EnableCompression: true,
 				MaxValueSize:      524288,
 			},
 		},
 	}
 
+	presets := map[string]func() metis.CacheConfig{
+		"1": metis.DevelopmentConfig,
+		"2": metis.WebAppConfig,
+		"3": metis.HighPerformanceConfig,
+		"4": metis.LowMemoryConfig,
+	}
+
 	for _, tt := range tests {
 		t.Run("choice_"+tt.choice, func(t *testing.T) {
-			var config SimpleConfig
-
-			switch tt.choice {
-			case "1":
-				config = SimpleConfig{
-					CacheSize: 1000,
-					TTL:       "10m",
-				}
-			case "2":
-				config = SimpleConfig{
-					CacheSize:      50000,
-					TTL:            "30m",
-					EvictionPolicy: "wtinylfu",
-					ShardCount:     32,
-				}
-			case "3":
-				config = SimpleConfig{
-					CacheSize:      1000000,
-					TTL:            "0s",
-					EvictionPolicy: "wtinylfu",
-					ShardCount:     128,
-				}
-			case "4":
-				config = SimpleConfig{
-					CacheSize:         10000,
-					TTL:               "1h",
-					EnableCompression: true,
-					MaxValueSize:      524288,
-				}
-			}
+			config := presetConfig(presets[tt.choice]())
 
 			// Verify each field matches expected
 			if config.CacheSize != tt.expected.CacheSize {
@@ -199,6 +185,44 @@ func TestPresetConfigurations(t *testing.T) {
 	}
 }
 
+// TestPresetRoundTrip tests that a generated config file loads back as the library preset in every format
+func TestPresetRoundTrip(t *testing.T) {
+	presets := map[string]metis.CacheConfig{
+		"development":      metis.DevelopmentConfig(),
+		"web-application":  metis.WebAppConfig(),
+		"high-performance": metis.HighPerformanceConfig(),
+		"low-memory":       metis.LowMemoryConfig(),
+	}
+
+	for name, preset := range presets {
+		for _, format := range []string{metis.FormatJSON, metis.FormatYAML, metis.FormatTOML} {
+			t.Run(name+"_"+format, func(t *testing.T) {
+				data, err := metis.MarshalConfig(presetConfig(preset), format)
+				if err != nil {
+					t.Fatalf("Failed to marshal config: %v", err)
+				}
+				path := filepath.Join(t.TempDir(), "metis."+format)
+				if err := os.WriteFile(path, data, 0600); err != nil {
+					t.Fatalf("Failed to write %s: %v", path, err)
+				}
+
+				loaded, err := metis.LoadConfigFile(path)
+				if err != nil {
+					t.Fatalf("LoadConfigFile failed: %v", err)
+				}
+				if !reflect.DeepEqual(loaded, preset) {
+					t.Errorf("Loaded config drifted from preset:\n got  %+v\n want %+v", loaded, preset)
+				}
+
+				t.Setenv(metis.ConfigEnvVar, path)
+				if loaded := metis.LoadConfig(); !reflect.DeepEqual(loaded, preset) {
+					t.Errorf("New's config drifted from preset:\n got  %+v\n want %+v", loaded, preset)
+				}
+			})
+		}
+	}
+}
+
 // TestConfigGeneration tests the JSON file generation process
 func TestConfigGeneration(t *testing.T) {
 	// Create temporary directory for test
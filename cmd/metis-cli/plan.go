@@ -0,0 +1,190 @@
+// /cmd/metis-cli/plan.go: Capacity planning from the expected workload
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/agilira/metis"
+)
+
+// Shard sizing used by plan: shards per core, and the fewest entries worth a shard
+const (
+	shardsPerCore   = 4
+	minShardEntries = 256
+)
+
+// Plan is the recommendation of the plan command
+type Plan struct {
+	Entries       int     `json:"entries"`        // Distinct keys in the workload
+	AvgValueSize  int     `json:"avg_value_size"` // Bytes per value
+	Cores         int     `json:"cores"`
+	TargetHitRate float64 `json:"target_hit_rate"`
+	CacheSize     int     `json:"cache_size"`
+	ShardCount    int     `json:"shard_count"`
+	Policy        string  `json:"policy"`
+	// Estimate is the footprint of the recommended cache, Full that of one holding every entry
+	Estimate metis.MemoryEstimate `json:"estimate"`
+	Full     metis.MemoryEstimate `json:"full"`
+}
+
+// runPlan recommends a CacheSize and ShardCount and returns the exit code
+func runPlan(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	entries := fs.Int("entries", 0, "Distinct keys the service reads")
+	avgValueSize := fs.Int("avg-value-size", 1024, "Average value size in bytes, as stored (after compression)")
+	cores := fs.Int("cores", runtime.NumCPU(), "CPU cores serving cache traffic")
+	targetHitRate := fs.Float64("target-hit-rate", 0.9, "Hit rate to size the cache for, 0 to 1 (1 = hold every entry)")
+	policy := fs.String("policy", "wtinylfu", "Eviction policy: lru, wtinylfu or arc")
+	out := fs.String("out", "", "Also write the recommendation to this config file")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	switch {
+	case *entries <= 0:
+		fmt.Fprintln(stderr, "Error: plan needs -entries, the number of distinct keys")
+		return exitUsage
+	case *avgValueSize < 0:
+		fmt.Fprintln(stderr, "Error: -avg-value-size must not be negative")
+		return exitUsage
+	case *cores <= 0:
+		fmt.Fprintln(stderr, "Error: -cores must be positive")
+		return exitUsage
+	case *targetHitRate <= 0 || *targetHitRate > 1:
+		fmt.Fprintln(stderr, "Error: -target-hit-rate must be within (0,1]")
+		return exitUsage
+	}
+	if err := (metis.CacheConfig{EvictionPolicy: *policy}).Validate(); err != nil {
+		printProblems(stderr, err, nil)
+		return exitUsage
+	}
+
+	plan := newPlan(*entries, *avgValueSize, *cores, *targetHitRate, *policy)
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(plan, "", "  ")
+		fmt.Fprintln(stdout, string(data))
+	} else {
+		printPlan(stdout, plan)
+	}
+
+	if *out != "" {
+		config := SimpleConfig{CacheSize: plan.CacheSize, ShardCount: plan.ShardCount, EvictionPolicy: plan.Policy}
+		data, err := metis.MarshalConfig(config, metis.ConfigFormatFromPath(*out))
+		if err == nil {
+			err = os.WriteFile(*out, data, 0600)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitInvalid
+		}
+		if !*jsonOutput {
+			fmt.Fprintf(stdout, "\n✅ Wrote the recommendation to %s\n", *out)
+		}
+	}
+	return exitOK
+}
+
+// newPlan sizes a cache for the workload
+func newPlan(entries, avgValueSize, cores int, targetHitRate float64, policy string) Plan {
+	plan := Plan{
+		Entries:       entries,
+		AvgValueSize:  avgValueSize,
+		Cores:         cores,
+		TargetHitRate: targetHitRate,
+		CacheSize:     cacheSizeForHitRate(entries, targetHitRate),
+		Policy:        policy,
+	}
+	plan.ShardCount = recommendShards(plan.CacheSize, cores)
+
+	config := metis.CacheConfig{CacheSize: plan.CacheSize, ShardCount: plan.ShardCount, EvictionPolicy: policy}
+	plan.Estimate = metis.EstimateMemory(config, avgValueSize)
+	config.CacheSize = entries
+	plan.Full = metis.EstimateMemory(config, avgValueSize)
+	return plan
+}
+
+// cacheSizeForHitRate returns how many of entries keys to cache for the hit rate. It assumes
+// Zipf popularity with exponent 1, common in web traffic, where the k most read of n keys
+// take about ln(k)/ln(n) of the reads, so k = n^hitRate; a frequency-aware policy such as
+// W-TinyLFU keeps close to those k keys. The result is rounded up to two significant digits.
+func cacheSizeForHitRate(entries int, hitRate float64) int {
+	if hitRate >= 1 || entries < 10 {
+		return entries
+	}
+	size := math.Pow(float64(entries), hitRate)
+	unit := math.Pow(10, math.Max(0, math.Floor(math.Log10(size))-1))
+	return min(entries, int(math.Ceil(size/unit)*unit))
+}
+
+// recommendShards returns a power of two near shardsPerCore shards per core, halved
+// while shards would hold fewer than minShardEntries entries
+func recommendShards(cacheSize, cores int) int {
+	shards := 1
+	for shards < cores*shardsPerCore {
+		shards <<= 1
+	}
+	for shards > 1 && cacheSize/shards < minShardEntries {
+		shards >>= 1
+	}
+	return shards
+}
+
+func printPlan(w io.Writer, plan Plan) {
+	fmt.Fprintln(w, "📐 Metis Capacity Plan")
+	fmt.Fprintln(w, "======================")
+	fmt.Fprintf(w, "Workload: %d keys of ~%d bytes, %d cores, target hit rate %.0f%%\n\n",
+		plan.Entries, plan.AvgValueSize, plan.Cores, plan.TargetHitRate*100)
+
+	fmt.Fprintln(w, "Recommendation:")
+	fmt.Fprintf(w, "- cache_size:      %d (%.1f%% of the keys)\n", plan.CacheSize, float64(plan.CacheSize)/float64(plan.Entries)*100)
+	fmt.Fprintf(w, "- shard_count:     %d\n", plan.ShardCount)
+	fmt.Fprintf(w, "- eviction_policy: %s\n\n", plan.Policy)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Estimated memory\tRecommended\tEvery key\t")
+	for _, row := range []struct {
+		name      string
+		rec, full int64
+	}{
+		{"Values", plan.Estimate.ValueBytes, plan.Full.ValueBytes},
+		{"Keys", plan.Estimate.KeyBytes, plan.Full.KeyBytes},
+		{"Entry overhead", plan.Estimate.EntryOverhead, plan.Full.EntryOverhead},
+		{"Map overhead", plan.Estimate.MapOverhead, plan.Full.MapOverhead},
+		{"Frequency sketch", plan.Estimate.SketchBytes, plan.Full.SketchBytes},
+		{"Shards", plan.Estimate.ShardBytes, plan.Full.ShardBytes},
+		{"Total", plan.Estimate.TotalBytes, plan.Full.TotalBytes},
+	} {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", row.name, formatBytes(row.rec), formatBytes(row.full))
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w, "\nThe hit rate assumes Zipf-like popularity; measure with metis-debug bench -distribution zipf.")
+	fmt.Fprintln(w, "Allow 10-30% more for Go runtime overhead, and size values after compression.")
+}
+
+// formatBytes prints n in the largest binary unit that keeps it at least 1
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
@@ -0,0 +1,100 @@
+// /cmd/metis-cli/plan_test.go: Tests for the plan command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agilira/metis"
+)
+
+// TestRunPlan recommends a configuration and writes it on request
+func TestRunPlan(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "metis.json")
+	var stdout, stderr bytes.Buffer
+	args := []string{"-entries", "1000000", "-avg-value-size", "2048", "-cores", "16", "-target-hit-rate", "0.9", "-out", out}
+	if code := runPlan(args, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	for _, expected := range []string{"cache_size:      260000", "shard_count:     64", "Frequency sketch", "Total", "Wrote the recommendation"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("output missing %q:\n%s", expected, stdout.String())
+		}
+	}
+
+	config, err := metis.LoadConfigFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.CacheSize != 260000 || config.ShardCount != 64 || config.EvictionPolicy != "wtinylfu" {
+		t.Errorf("expected the recommendation in the file, got %+v", config)
+	}
+
+	stdout.Reset()
+	if code := runPlan([]string{"-entries", "5000", "-json", "-policy", "lru"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var plan Plan
+	if err := json.Unmarshal(stdout.Bytes(), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if plan.Estimate.Policy != "lru" || plan.Estimate.TotalBytes >= plan.Full.TotalBytes {
+		t.Errorf("expected a smaller lru cache than the full one, got %+v", plan)
+	}
+}
+
+// TestRunPlan_InvalidFlags rejects settings that cannot be planned
+func TestRunPlan_InvalidFlags(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no_entries":     {},
+		"hit_rate":       {"-entries", "100", "-target-hit-rate", "1.5"},
+		"cores":          {"-entries", "100", "-cores", "0"},
+		"unknown_policy": {"-entries", "100", "-policy", "lfu"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runPlan(args, &stdout, &stderr); code != exitUsage {
+				t.Errorf("expected exit code %d, got %d", exitUsage, code)
+			}
+		})
+	}
+}
+
+func TestCacheSizeForHitRate(t *testing.T) {
+	tests := []struct {
+		entries int
+		hitRate float64
+		want    int
+	}{
+		{1000000, 0.9, 260000}, // 1e6^0.9 = 251189, rounded up to 2 significant digits
+		{1000000, 1, 1000000},
+		{10000, 0.5, 100},
+		{5, 0.5, 5},
+	}
+	for _, tt := range tests {
+		if got := cacheSizeForHitRate(tt.entries, tt.hitRate); got != tt.want {
+			t.Errorf("cacheSizeForHitRate(%d, %g) = %d, want %d", tt.entries, tt.hitRate, got, tt.want)
+		}
+	}
+}
+
+func TestRecommendShards(t *testing.T) {
+	tests := []struct{ cacheSize, cores, want int }{
+		{260000, 16, 64},
+		{260000, 6, 32}, // 24 rounded up to a power of two
+		{1000, 16, 2},   // Shards of at least 256 entries
+		{100, 8, 1},
+	}
+	for _, tt := range tests {
+		if got := recommendShards(tt.cacheSize, tt.cores); got != tt.want {
+			t.Errorf("recommendShards(%d, %d) = %d, want %d", tt.cacheSize, tt.cores, got, tt.want)
+		}
+	}
+}
@@ -7,8 +7,11 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/agilira/metis"
 )
 
 // TestCLISubprocess tests CLI execution using subprocess approach
@@ -259,12 +262,86 @@ func TestCLIErrorScenarios(t *testing.T) {
 	})
 }
 
+// TestCLIFormatFlag tests the --format flag for every supported format
+func TestCLIFormatFlag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping format flag tests in short mode")
+	}
+
+	helper := NewCLITestHelper(t)
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			stdout, _, exitCode := helper.RunCLIWithArgsInDir("2\n", tempDir, "--format", format)
+			if exitCode != 0 {
+				t.Fatalf("CLI failed with exit code %d. Stdout: %s", exitCode, stdout)
+			}
+
+			fileName := "metis." + format
+			helper.AssertContains(stdout, "Generated "+fileName+" successfully!")
+			if !helper.CheckFileExistsInDir(fileName, tempDir) {
+				t.Fatalf("%s was not created", fileName)
+			}
+
+			config, err := metis.LoadConfigFile(filepath.Join(tempDir, fileName))
+			if err != nil {
+				t.Fatalf("LoadConfigFile failed: %v", err)
+			}
+			if config != metis.WebAppConfig() {
+				t.Errorf("Loaded config differs from WebAppConfig: %+v", config)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		tempDir := t.TempDir()
+		stdout, _, exitCode := helper.RunCLIWithArgsInDir("2\n", tempDir, "--format", "xml")
+		if exitCode == 0 {
+			t.Error("Expected a non-zero exit code for an unsupported format")
+		}
+		helper.AssertContains(stdout, "Unsupported format")
+		if helper.CheckFileExistsInDir("metis.xml", tempDir) {
+			t.Error("No file should be written for an unsupported format")
+		}
+	})
+}
+
+// TestCLISubprocessNonInteractive checks generate and validate exit codes without input
+func TestCLISubprocessNonInteractive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping subprocess tests in short mode")
+	}
+
+	helper := NewCLITestHelper(t)
+	tempDir := t.TempDir()
+
+	output, _, exitCode := helper.RunCLIWithArgsInDir("", tempDir, "generate", "--preset", "web", "--shards", "24", "--out", "metis.json")
+	if exitCode != 0 {
+		t.Fatalf("generate failed with exit code %d: %s", exitCode, output)
+	}
+	helper.AssertContains(output, "not a power of two")
+	helper.AssertNotContains(output, "Choose (1-6)")
+
+	if output, _, exitCode = helper.RunCLIWithArgsInDir("", tempDir, "validate", "metis.json"); exitCode != 0 {
+		t.Errorf("validate failed with exit code %d: %s", exitCode, output)
+	}
+	if output, _, exitCode = helper.RunCLIWithArgsInDir("", tempDir, "validate", "--strict", "metis.json"); exitCode != 1 {
+		t.Errorf("expected exit code 1 with --strict, got %d: %s", exitCode, output)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "bad.json"), []byte(`{"eviction_policy": "fifo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if output, _, exitCode = helper.RunCLIWithArgsInDir("", tempDir, "validate", "bad.json"); exitCode != 1 {
+		t.Errorf("expected exit code 1 for an invalid file, got %d: %s", exitCode, output)
+	}
+	helper.AssertContains(output, `unknown EvictionPolicy "fifo"`)
+}
+
 // BenchmarkCLISubprocess benchmarks subprocess CLI execution
 func BenchmarkCLISubprocess(b *testing.B) {
-	helper := &CLITestHelper{
-		t:       &testing.T{},
-		mainDir: func() string { dir, _ := os.Getwd(); return dir }(),
-	}
+	helper := NewCLITestHelper(b)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
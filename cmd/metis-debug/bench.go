@@ -0,0 +1,158 @@
+// /cmd/metis-debug/bench.go: Configurable benchmark against a local cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/internal/bench"
+)
+
+// BenchReport is the -json output of the bench command
+type BenchReport struct {
+	Workload bench.Workload `json:"workload"`
+	Cache    BenchCache     `json:"cache"`
+	Result   bench.Result   `json:"result"`
+}
+
+// BenchCache describes the cache a benchmark ran against
+type BenchCache struct {
+	EvictionPolicy    string `json:"eviction_policy"`
+	CacheSize         int    `json:"cache_size"`
+	ShardCount        int    `json:"shard_count"`
+	EnableCompression bool   `json:"enable_compression"`
+}
+
+func cmdBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	workloadFlags := addWorkloadFlags(fs, 10*time.Second)
+	size := fs.Int("size", 0, "Cache size in entries (default: -keys)")
+	policy := fs.String("policy", "wtinylfu", "Eviction policy: lru, lfu, fifo, wtinylfu or arc")
+	shards := fs.Int("shards", 16, "Number of shards")
+	compression := fs.Bool("compression", false, "Enable compression")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+
+	workload := workloadFlags()
+	config := metis.CacheConfig{
+		EnableCaching:         true,
+		CacheSize:             *size,
+		TTL:                   5 * time.Minute,
+		EvictionPolicy:        *policy,
+		ShardCount:            *shards,
+		EnableCompression:     *compression,
+		EnableLatencyTracking: true,
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = workload.Keys
+	}
+
+	report, err := runBench(config, workload)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	printBenchReport(report)
+}
+
+// addWorkloadFlags defines the workload flags shared by bench and compare on fs and
+// returns a function building the workload once fs is parsed
+func addWorkloadFlags(fs *flag.FlagSet, duration time.Duration) func() bench.Workload {
+	d := fs.Duration("duration", duration, "How long to run the workload")
+	workers := fs.Int("workers", runtime.NumCPU(), "Concurrent goroutines issuing operations")
+	keys := fs.Int("keys", 10000, "Size of the key space")
+	valueSize := fs.Int("value-size", 64, "Bytes in each value")
+	readRatio := fs.Float64("read-ratio", 0.9, "Fraction of operations that are Gets, 0 to 1")
+	distribution := fs.String("distribution", bench.Uniform, "Key distribution: uniform or zipf")
+	seed := fs.Int64("seed", 1, "Seed of the key choices")
+
+	return func() bench.Workload {
+		return bench.Workload{
+			Duration:     *d,
+			Workers:      *workers,
+			Keys:         *keys,
+			ValueSize:    *valueSize,
+			ReadRatio:    *readRatio,
+			Distribution: *distribution,
+			Seed:         *seed,
+			Warmup:       *keys / 10,
+		}
+	}
+}
+
+// runBench builds a cache from config and runs workload against it
+func runBench(config metis.CacheConfig, workload bench.Workload) (BenchReport, error) {
+	if err := workload.Validate(); err != nil {
+		return BenchReport{}, err
+	}
+	cache, err := metis.NewStrategicCacheE(config)
+	if err != nil {
+		return BenchReport{}, err
+	}
+	defer cache.Close()
+
+	result, err := bench.Run(cache, workload)
+	if err != nil {
+		return BenchReport{}, err
+	}
+	return BenchReport{
+		Workload: workload,
+		Cache: BenchCache{
+			EvictionPolicy:    config.EvictionPolicy,
+			CacheSize:         config.CacheSize,
+			ShardCount:        config.ShardCount,
+			EnableCompression: config.EnableCompression,
+		},
+		Result: result,
+	}, nil
+}
+
+func printBenchReport(report BenchReport) {
+	w, c, r := report.Workload, report.Cache, report.Result
+
+	fmt.Printf("=== Metis Benchmark ===\n\n")
+	fmt.Printf("Workload:\n")
+	fmt.Printf("- Duration: %v, %d workers\n", w.Duration, w.Workers)
+	fmt.Printf("- Keys: %s (%s), %d-byte values\n", formatNumber(int64(w.Keys)), w.Distribution, w.ValueSize)
+	fmt.Printf("- Reads: %.0f%%\n\n", w.ReadRatio*100)
+
+	fmt.Printf("Cache Configuration:\n")
+	fmt.Printf("- Policy: %s\n", c.EvictionPolicy)
+	fmt.Printf("- Size: %d entries\n", c.CacheSize)
+	fmt.Printf("- Shards: %d\n", c.ShardCount)
+	fmt.Printf("- Compression: %v\n\n", c.EnableCompression)
+
+	fmt.Printf("Results:\n")
+	fmt.Printf("- Operations: %s\n", formatNumber(r.Ops))
+	fmt.Printf("- Operations/sec: %s\n", formatNumber(int64(r.OpsPerSec)))
+	fmt.Printf("- Hit Rate: %.1f%%\n", r.HitRate*100)
+	fmt.Printf("- Get Latency: %s\n", formatLatency(r.Latency.Get))
+	fmt.Printf("- Set Latency: %s\n", formatLatency(r.Latency.Set))
+	fmt.Printf("- Cached: %d entries, %.1f MB\n", r.Keys, float64(r.MemoryBytes)/1024/1024)
+}
+
+// formatLatency prints the percentiles of one operation
+func formatLatency(op metis.OpLatency) string {
+	if op.Count == 0 {
+		return "no operations"
+	}
+	return fmt.Sprintf("p50=%v p90=%v p99=%v p999=%v", op.P50, op.P90, op.P99, op.P999)
+}
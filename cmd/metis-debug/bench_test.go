@@ -0,0 +1,50 @@
+// bench_test.go: Tests for the metis-debug bench command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/internal/bench"
+)
+
+// TestCmdBench runs a short benchmark in both output formats
+func TestCmdBench(t *testing.T) {
+	args := []string{"-duration", "50ms", "-workers", "2", "-keys", "1000", "-policy", "lru", "-distribution", "zipf"}
+
+	output := captureOutput(func() { cmdBench(args) })
+	for _, expected := range []string{"Metis Benchmark", "Policy: lru", "(zipf)", "Operations/sec:", "Hit Rate:", "Get Latency: p50="} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output missing %q:\n%s", expected, output)
+		}
+	}
+
+	output = captureOutput(func() { cmdBench(append(args, "-json", "-read-ratio", "0.5")) })
+	var report BenchReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if report.Result.Ops == 0 || report.Result.Latency.Set.Count == 0 {
+		t.Errorf("expected operations with Set latencies, got %+v", report.Result)
+	}
+	if report.Workload.ReadRatio != 0.5 || report.Cache.CacheSize != 1000 {
+		t.Errorf("expected the flags in the report, got %+v", report)
+	}
+}
+
+// TestRunBench_InvalidWorkload rejects settings that cannot run
+func TestRunBench_InvalidWorkload(t *testing.T) {
+	workload := bench.Workload{Duration: time.Second, Workers: 1, Keys: 10, Distribution: "gaussian"}
+	report, err := runBench(metis.CacheConfig{EnableCaching: true, CacheSize: 10}, workload)
+	if err == nil || !strings.Contains(err.Error(), "distribution") {
+		t.Errorf("expected a distribution error, got %v (%+v)", err, report)
+	}
+}
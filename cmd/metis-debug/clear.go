@@ -0,0 +1,93 @@
+// /cmd/metis-debug/clear.go: Clear a running cache, one of its shards or its expired entries
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func cmdClear(args []string) {
+	fs := flag.NewFlagSet("clear", flag.ContinueOnError)
+	addr := fs.String("addr", "", "Address of the metis.Handler of a running service (e.g. localhost:8080/cache)")
+	shard := fs.Int("shard", -1, "Clear only this shard, numbered as in inspect's shard stats")
+	expired := fs.Bool("expired", false, "Remove only the expired entries")
+	all := fs.Bool("all", false, "Clear every entry (required when neither -shard nor -expired is given)")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *addr == "" {
+		fmt.Fprintln(os.Stderr, "Error: clear needs -addr")
+		os.Exit(2)
+	}
+	if *expired && *shard >= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -shard and -expired cannot be combined")
+		os.Exit(2)
+	}
+	if !*expired && *shard < 0 && !*all {
+		fmt.Fprintln(os.Stderr, "Error: clear needs -shard, -expired or, to remove every entry, -all")
+		os.Exit(2)
+	}
+
+	msg, err := clearCache(*addr, *shard, *expired)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(msg)
+}
+
+// clearCache posts to the /clear routes of the metis.Handler at addr: the expired entries
+// when expired is set, else shard when it is not negative, else every entry. It returns
+// a line describing what was removed.
+func clearCache(addr string, shard int, expired bool) (string, error) {
+	url := handlerURL(addr) + "/clear"
+	switch {
+	case expired:
+		url += "/expired"
+	case shard >= 0:
+		url += "?shard=" + strconv.Itoa(shard)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", nil) // nosec G107 - the URL is supplied by the operator
+	if err != nil {
+		return "", fmt.Errorf("cannot reach %s (is the service running and serving the cache there?): %w", url, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return "", fmt.Errorf("posting %s: %s (is the handler read-only?)", url, resp.Status)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("posting %s: %s %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	switch {
+	case expired:
+		var result struct {
+			Removed int `json:"removed"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return "", fmt.Errorf("decoding %s: %w", url, err)
+		}
+		return fmt.Sprintf("Removed %s expired entries from %s", formatNumber(int64(result.Removed)), addr), nil
+	case shard >= 0:
+		return fmt.Sprintf("Cleared shard %d of %s", shard, addr), nil
+	default:
+		return fmt.Sprintf("Cleared every entry of %s", addr), nil
+	}
+}
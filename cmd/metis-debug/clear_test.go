@@ -0,0 +1,93 @@
+// clear_test.go: Tests for the metis-debug clear command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/metistest"
+)
+
+// newClearTarget serves a cache of n entries on a fake clock, the first expired entries
+// of which expire after a minute
+func newClearTarget(t *testing.T, n, expired int, opts ...metis.HandlerOption) (*metis.StrategicCache, *metistest.Clock, string) {
+	t.Helper()
+	clock := metistest.NewClock(time.Time{})
+	cache := metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:            true,
+		CacheSize:                10000,
+		ShardCount:               4,
+		TTL:                      time.Hour,
+		EvictionPolicy:           "lru",
+		DisableBackgroundCleanup: true,
+		Clock:                    clock,
+	})
+	t.Cleanup(cache.Close)
+	for i := 0; i < n; i++ {
+		if i < expired {
+			cache.SetWithOptions(fmt.Sprintf("user:%d", i), "value", metis.WithTTL(time.Minute))
+		} else {
+			cache.Set(fmt.Sprintf("user:%d", i), "value")
+		}
+	}
+	server := httptest.NewServer(metis.Handler(cache, opts...))
+	t.Cleanup(server.Close)
+	return cache, clock, server.URL
+}
+
+func TestClearCache_Expired(t *testing.T) {
+	cache, clock, addr := newClearTarget(t, 100, 40)
+	clock.Advance(2 * time.Minute)
+
+	msg, err := clearCache(addr, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg, "Removed 40 expired entries") {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if n := cache.Len(); n != 60 {
+		t.Errorf("expected the 60 live entries kept, got %d", n)
+	}
+}
+
+func TestClearCache_Shard(t *testing.T) {
+	cache, _, addr := newClearTarget(t, 100, 0)
+	keys, inShard := cache.Len(), cache.ShardStats()[2].Keys
+
+	if _, err := clearCache(addr, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := cache.Len(); n != keys-inShard {
+		t.Errorf("expected only the %d keys of shard 2 cleared, got %d of %d left", inShard, n, keys)
+	}
+	if _, err := clearCache(addr, 9, false); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out-of-range error, got %v", err)
+	}
+
+	if _, err := clearCache(addr, -1, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected every entry cleared, got %d", n)
+	}
+}
+
+func TestClearCache_ReadOnlyHandler(t *testing.T) {
+	cache, _, addr := newClearTarget(t, 10, 0, metis.WithHandlerReadOnly())
+	if _, err := clearCache(addr, -1, false); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected a read-only hint, got %v", err)
+	}
+	if n := cache.Len(); n != 10 {
+		t.Errorf("expected the cache untouched, got %d entries", n)
+	}
+}
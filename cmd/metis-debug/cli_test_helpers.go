@@ -36,8 +36,8 @@ func (h *CLITestHelper) RunCommand(args ...string) (stdout, stderr string, exitC
 	}
 
 	// Build command arguments safely
-	// Start with fixed commands: "go", "run", "main.go"
-	cmdArgs := []string{"run", "main.go"}
+	// Start with fixed commands: "go", "run", "." (the package spans several files)
+	cmdArgs := []string{"run", "."}
 
 	// Add test arguments (these are controlled test inputs)
 	for _, arg := range args {
@@ -0,0 +1,205 @@
+// /cmd/metis-debug/compare.go: Side-by-side benchmark of two configurations
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/internal/bench"
+)
+
+// CompareReport is the -json output of the compare command
+type CompareReport struct {
+	Workload bench.Workload `json:"workload"`
+	A        CompareSide    `json:"a"`
+	B        CompareSide    `json:"b"`
+	// Deltas holds the change from A to B of each metric, in percent of A
+	Deltas []MetricDelta `json:"deltas"`
+	// Threshold is the -threshold flag; Regressions lists the metrics of B worse than
+	// A by more than it
+	Threshold   float64  `json:"threshold"`
+	Regressions []string `json:"regressions"`
+}
+
+// CompareSide is one of the configurations compared and its result
+type CompareSide struct {
+	Config string       `json:"config"`
+	Result bench.Result `json:"result"`
+}
+
+// MetricDelta is the change of one metric between the two configurations
+type MetricDelta struct {
+	Metric       string  `json:"metric"`
+	A            float64 `json:"a"`
+	B            float64 `json:"b"`
+	DeltaPercent float64 `json:"delta_percent"` // (B - A) / A * 100, 0 when A is 0
+	HigherBetter bool    `json:"higher_better"`
+}
+
+// regressed reports whether B is worse than A by more than threshold percent
+func (d MetricDelta) regressed(threshold float64) bool {
+	if d.HigherBetter {
+		return d.DeltaPercent < -threshold
+	}
+	return d.DeltaPercent > threshold
+}
+
+func cmdCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	configA := fs.String("config-a", "", "Config file of the baseline (JSON, YAML or TOML)")
+	configB := fs.String("config-b", "", "Config file of the candidate")
+	workloadFlags := addWorkloadFlags(fs, 10*time.Second)
+	threshold := fs.Float64("threshold", 0, "Fail when B is worse than A by more than this percentage on any metric (0 = never)")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *configA == "" || *configB == "" {
+		fmt.Fprintln(os.Stderr, "Error: compare needs -config-a and -config-b")
+		os.Exit(2)
+	}
+
+	report, err := runCompare(*configA, *configB, workloadFlags(), *threshold)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printCompareReport(report)
+	}
+	if len(report.Regressions) > 0 {
+		os.Exit(1)
+	}
+}
+
+// runCompare runs workload against a cache built from each config file in turn, with
+// the same seed, and compares the results
+func runCompare(pathA, pathB string, workload bench.Workload, threshold float64) (CompareReport, error) {
+	if err := workload.Validate(); err != nil {
+		return CompareReport{}, err
+	}
+
+	report := CompareReport{Workload: workload, Threshold: threshold, Regressions: []string{}}
+	for _, side := range []struct {
+		path string
+		dst  *CompareSide
+	}{{pathA, &report.A}, {pathB, &report.B}} {
+		result, err := benchConfigFile(side.path, workload)
+		if err != nil {
+			return CompareReport{}, err
+		}
+		*side.dst = CompareSide{Config: side.path, Result: result}
+	}
+
+	a, b := report.A.Result, report.B.Result
+	report.Deltas = []MetricDelta{
+		newMetricDelta("ops_per_sec", a.OpsPerSec, b.OpsPerSec, true),
+		newMetricDelta("hit_rate", a.HitRate, b.HitRate, true),
+		newMetricDelta("get_p99_ns", float64(a.Latency.Get.P99), float64(b.Latency.Get.P99), false),
+		newMetricDelta("set_p99_ns", float64(a.Latency.Set.P99), float64(b.Latency.Set.P99), false),
+		newMetricDelta("memory_bytes", float64(a.MemoryBytes), float64(b.MemoryBytes), false),
+	}
+	if threshold > 0 {
+		for _, d := range report.Deltas {
+			if d.regressed(threshold) {
+				report.Regressions = append(report.Regressions, d.Metric)
+			}
+		}
+	}
+	return report, nil
+}
+
+// benchConfigFile runs workload against a cache built from the config file at path
+func benchConfigFile(path string, workload bench.Workload) (bench.Result, error) {
+	config, err := metis.LoadConfigFile(path)
+	if err != nil {
+		return bench.Result{}, err
+	}
+	config.EnableLatencyTracking = true
+	cache := metis.NewStrategicCache(config)
+	defer cache.Close()
+	return bench.Run(cache, workload)
+}
+
+// newMetricDelta computes the change of a metric from a to b
+func newMetricDelta(metric string, a, b float64, higherBetter bool) MetricDelta {
+	d := MetricDelta{Metric: metric, A: a, B: b, HigherBetter: higherBetter}
+	if a != 0 {
+		d.DeltaPercent = (b - a) / a * 100
+	}
+	return d
+}
+
+func printCompareReport(report CompareReport) {
+	w := report.Workload
+	fmt.Printf("=== Metis Compare ===\n\n")
+	fmt.Printf("A: %s\n", report.A.Config)
+	fmt.Printf("B: %s\n", report.B.Config)
+	fmt.Printf("Workload: %v, %d workers, %s keys (%s), %d-byte values, %.0f%% reads, seed %d\n\n",
+		w.Duration, w.Workers, formatNumber(int64(w.Keys)), w.Distribution, w.ValueSize, w.ReadRatio*100, w.Seed)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "Metric\tA\tB\tDelta\t")
+	for _, d := range report.Deltas {
+		mark := ""
+		if d.regressed(report.Threshold) && report.Threshold > 0 {
+			mark = "  REGRESSION"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%+.1f%%%s\t\n", metricLabel(d.Metric), formatMetric(d.Metric, d.A), formatMetric(d.Metric, d.B), d.DeltaPercent, mark)
+	}
+	_ = tw.Flush()
+
+	if report.Threshold > 0 {
+		if len(report.Regressions) > 0 {
+			fmt.Printf("\nB regresses by more than %.1f%% on: %v\n", report.Threshold, report.Regressions)
+		} else {
+			fmt.Printf("\nNo regression beyond %.1f%%\n", report.Threshold)
+		}
+	}
+}
+
+// metricLabel returns the table label of a metric
+func metricLabel(metric string) string {
+	switch metric {
+	case "ops_per_sec":
+		return "Ops/sec"
+	case "hit_rate":
+		return "Hit rate"
+	case "get_p99_ns":
+		return "Get p99"
+	case "set_p99_ns":
+		return "Set p99"
+	case "memory_bytes":
+		return "Memory"
+	}
+	return metric
+}
+
+// formatMetric prints a metric value in its unit
+func formatMetric(metric string, v float64) string {
+	switch metric {
+	case "ops_per_sec":
+		return formatNumber(int64(v))
+	case "hit_rate":
+		return fmt.Sprintf("%.1f%%", v*100)
+	case "get_p99_ns", "set_p99_ns":
+		return time.Duration(v).String()
+	case "memory_bytes":
+		return fmt.Sprintf("%.1f MB", v/1024/1024)
+	}
+	return fmt.Sprintf("%g", v)
+}
@@ -0,0 +1,87 @@
+// compare_test.go: Tests for the metis-debug compare command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/bench"
+)
+
+// writeConfig writes a JSON config file into dir and returns its path
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRunCompare runs the same workload against two configurations
+func TestRunCompare(t *testing.T) {
+	dir := t.TempDir()
+	a := writeConfig(t, dir, "a.json", `{"cache_size": 10000, "eviction_policy": "lru"}`)
+	b := writeConfig(t, dir, "b.json", `{"cache_size": 100, "eviction_policy": "lru"}`)
+	workload := bench.Workload{Duration: 50 * time.Millisecond, Workers: 2, Keys: 5000, ValueSize: 16, ReadRatio: 0.9, Distribution: bench.Uniform, Seed: 1, Warmup: 5000}
+
+	report, err := runCompare(a, b, workload, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.A.Config != a || report.B.Config != b || report.A.Result.Ops == 0 || report.B.Result.Ops == 0 {
+		t.Fatalf("expected both configurations to run, got %+v", report)
+	}
+	if len(report.Deltas) != 5 {
+		t.Fatalf("expected 5 deltas, got %+v", report.Deltas)
+	}
+	// A 100-entry cache over 5000 keys misses far more than a 10000-entry one
+	found := false
+	for _, m := range report.Regressions {
+		found = found || m == "hit_rate"
+	}
+	if !found {
+		t.Errorf("expected a hit rate regression, got %v (deltas %+v)", report.Regressions, report.Deltas)
+	}
+
+	output := captureOutput(func() { printCompareReport(report) })
+	for _, expected := range []string{"Metis Compare", "Ops/sec", "Hit rate", "Get p99", "Memory", "REGRESSION"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output missing %q:\n%s", expected, output)
+		}
+	}
+}
+
+// TestRunCompare_InvalidConfig reports a config file that cannot be loaded
+func TestRunCompare_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	a := writeConfig(t, dir, "a.json", `{"cache_size": 100}`)
+	workload := bench.Workload{Duration: 10 * time.Millisecond, Workers: 1, Keys: 10, Distribution: bench.Uniform}
+
+	if _, err := runCompare(a, filepath.Join(dir, "missing.json"), workload, 0); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+// TestNewMetricDelta checks the delta direction of both kinds of metric
+func TestNewMetricDelta(t *testing.T) {
+	throughput := newMetricDelta("ops_per_sec", 1000, 800, true)
+	if throughput.DeltaPercent != -20 || !throughput.regressed(10) || throughput.regressed(25) {
+		t.Errorf("unexpected throughput delta %+v", throughput)
+	}
+	latency := newMetricDelta("get_p99_ns", 1000, 800, false)
+	if latency.regressed(10) {
+		t.Errorf("expected lower latency not to regress, got %+v", latency)
+	}
+	if zero := newMetricDelta("memory_bytes", 0, 100, false); zero.DeltaPercent != 0 {
+		t.Errorf("expected no delta from zero, got %+v", zero)
+	}
+}
@@ -0,0 +1,262 @@
+// /cmd/metis-debug/dump.go: Dump the contents of a running cache and restore them locally
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// dumpFormat is the version written in DumpHeader.Format
+const dumpFormat = 1
+
+// restoreBatch is how many entries restore hands to Warm at a time
+const restoreBatch = 1000
+
+// snapshotMagic starts the files written by StrategicCache.SaveToFile
+const snapshotMagic = "METISNAP"
+
+// DumpHeader is the first line of a dump file; every following line is a
+// metis.AdminDumpRecord
+type DumpHeader struct {
+	Format     int       `json:"metis_dump"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+	Values     bool      `json:"values"`
+	HashedKeys bool      `json:"hashed_keys"`
+}
+
+// DumpSummary reports what dump wrote
+type DumpSummary struct {
+	Entries int
+	// MissingValues counts entries dumped without a value although -values was set,
+	// because the service does not use metis.WithHandlerValues or the value has no
+	// JSON form
+	MissingValues int
+}
+
+// RestoreSummary reports what restore loaded
+type RestoreSummary struct {
+	Read     int `json:"read"`     // Entries in the dump
+	Restored int `json:"restored"` // Entries stored in the cache
+	Expired  int `json:"expired"`  // Entries skipped because they expired after the dump
+	Rejected int `json:"rejected"` // Entries the cache would not hold (size limits, memory budget)
+}
+
+func cmdDump(args []string) {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	addr := fs.String("addr", "", "Address of the metis.Handler of a running service (e.g. localhost:8080/cache)")
+	out := fs.String("out", "", "File to write the dump to (- for stdout)")
+	values := fs.Bool("values", false, "Include the values (the service must use metis.WithHandlerValues)")
+	hashKeys := fs.Bool("hash-keys", false, "Replace every key with its SHA-256 hash")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *addr == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "Error: dump needs -addr and -out")
+		os.Exit(2)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	summary, err := dumpCache(*addr, w, *values, *hashKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if summary.MissingValues > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d entries have no value; serve the cache with metis.WithHandlerValues() to dump them\n", summary.MissingValues)
+	}
+	if *out != "-" {
+		fmt.Printf("Dumped %s entries from %s to %s\n", formatNumber(int64(summary.Entries)), *addr, *out)
+	}
+}
+
+// dumpCache copies GET /dump of the metis.Handler at addr to w, one record at a time,
+// after a DumpHeader line. Values are dropped unless values is set, and keys are hashed
+// when hashKeys is set.
+func dumpCache(addr string, w io.Writer, values, hashKeys bool) (DumpSummary, error) {
+	url := handlerURL(addr) + "/dump"
+	resp, err := http.Get(url) // nosec G107 - the URL is supplied by the operator
+	if err != nil {
+		return DumpSummary{}, fmt.Errorf("cannot reach %s (is the service running and serving the cache there?): %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DumpSummary{}, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	header := DumpHeader{Format: dumpFormat, Source: addr, CreatedAt: time.Now().UTC(), Values: values, HashedKeys: hashKeys}
+	if err := enc.Encode(header); err != nil {
+		return DumpSummary{}, err
+	}
+
+	var summary DumpSummary
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var record metis.AdminDumpRecord
+		if err := dec.Decode(&record); err != nil {
+			return summary, fmt.Errorf("decoding %s: %w", url, err)
+		}
+		if hashKeys {
+			record.Key = hashKey(record.Key)
+		}
+		if !values {
+			record.Value, record.Error = nil, ""
+		} else if record.Value == nil {
+			summary.MissingValues++
+		}
+		if err := enc.Encode(record); err != nil {
+			return summary, err
+		}
+		summary.Entries++
+	}
+	return summary, bw.Flush()
+}
+
+// hashKey redacts key as "sha256:" and the first 16 bytes of its SHA-256 in hex. The
+// same key always gives the same hash, so the key layout of the cache is preserved.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:16])
+}
+
+func cmdRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	in := fs.String("in", "", "Dump written by 'metis-debug dump', or a snapshot written by SaveToFile")
+	configPath := fs.String("config", "", "Config file of the local cache (default: metis.NewE's sources)")
+	snapshot := fs.String("snapshot", "", "Save the restored cache to this file with SaveToFile")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "Error: restore needs -in")
+		os.Exit(2)
+	}
+
+	var cache *metis.StrategicCache
+	var err error
+	if *configPath != "" {
+		cache, err = metis.NewFromFile(*configPath)
+	} else {
+		cache, err = metis.NewE()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer cache.Close()
+
+	summary, err := restoreCache(cache, *in)
+	if err == nil && *snapshot != "" {
+		err = cache.SaveToFile(*snapshot)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	stats := cache.GetStats()
+	fmt.Printf("Restored %s of %s entries from %s (%d expired, %d rejected)\n",
+		formatNumber(int64(summary.Restored)), formatNumber(int64(summary.Read)), *in, summary.Expired, summary.Rejected)
+	fmt.Printf("Cache: %s keys, %.1f MB (%s)\n", formatNumber(int64(stats.Keys)), float64(stats.MemoryBytes)/1024/1024, cache.PolicyName())
+	if *snapshot != "" {
+		fmt.Printf("Snapshot saved to %s\n", *snapshot)
+	}
+}
+
+// restoreCache loads the dump or snapshot at path into cache. Snapshots go through
+// LoadFromFile; dumps are decoded a line at a time and stored with Warm, restoreBatch
+// entries at a time. Entries of a dump without values are stored with a nil value.
+func restoreCache(cache *metis.StrategicCache, path string) (RestoreSummary, error) {
+	f, err := os.Open(path) // nosec G304 - the path is supplied by the operator
+	if err != nil {
+		return RestoreSummary{}, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(len(snapshotMagic)); string(magic) == snapshotMagic {
+		loaded, err := cache.LoadFromFile(path)
+		return RestoreSummary{Read: loaded, Restored: loaded}, err
+	}
+
+	dec := json.NewDecoder(r)
+	var header DumpHeader
+	if err := dec.Decode(&header); err != nil || header.Format == 0 {
+		return RestoreSummary{}, fmt.Errorf("%s: not a metis-debug dump or snapshot", path)
+	}
+	if header.Format != dumpFormat {
+		return RestoreSummary{}, fmt.Errorf("%s: unsupported dump format %d", path, header.Format)
+	}
+
+	var summary RestoreSummary
+	batch := make([]metis.WarmEntry, 0, restoreBatch)
+	flush := func() error {
+		admitted, err := cache.Warm(batch)
+		if errors.Is(err, metis.ErrCacheClosed) || errors.Is(err, metis.ErrCachingDisabled) {
+			return err
+		}
+		summary.Restored += admitted
+		summary.Rejected += len(batch) - admitted
+		batch = batch[:0]
+		return nil
+	}
+
+	now := time.Now()
+	for dec.More() {
+		var record metis.AdminDumpRecord
+		if err := dec.Decode(&record); err != nil {
+			return summary, fmt.Errorf("%s: entry %d: %w", path, summary.Read+1, err)
+		}
+		summary.Read++
+
+		entry := metis.WarmEntry{Key: record.Key, Value: record.Value}
+		if record.ExpiresAt != nil {
+			if entry.TTL = record.ExpiresAt.Sub(now); entry.TTL <= 0 {
+				summary.Expired++
+				continue
+			}
+		}
+		if batch = append(batch, entry); len(batch) == restoreBatch {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, flush()
+}
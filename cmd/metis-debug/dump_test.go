@@ -0,0 +1,171 @@
+// dump_test.go: Tests for the metis-debug dump and restore commands
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// newDumpSource serves a cache of n entries through metis.Handler
+func newDumpSource(t *testing.T, n int, opts ...metis.HandlerOption) string {
+	t.Helper()
+	cache := metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	t.Cleanup(cache.Close)
+	for i := 0; i < n; i++ {
+		cache.Set(fmt.Sprintf("user:%d", i), "value")
+	}
+	server := httptest.NewServer(metis.Handler(cache, opts...))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// newRestoreTarget builds an empty local cache
+func newRestoreTarget(t *testing.T) *metis.StrategicCache {
+	t.Helper()
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 10000, TTL: time.Hour, EvictionPolicy: "wtinylfu"})
+	t.Cleanup(cache.Close)
+	return cache
+}
+
+// TestDumpRestore round-trips a running cache through a dump file
+func TestDumpRestore(t *testing.T) {
+	addr := newDumpSource(t, 2500, metis.WithHandlerValues())
+	path := filepath.Join(t.TempDir(), "cache.dump")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := dumpCache(addr, f, true, false)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Entries != 2500 || summary.MissingValues != 0 {
+		t.Fatalf("expected 2500 entries with values, got %+v", summary)
+	}
+
+	cache := newRestoreTarget(t)
+	restored, err := restoreCache(cache, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Read != 2500 || restored.Restored != 2500 {
+		t.Fatalf("expected 2500 restored entries, got %+v", restored)
+	}
+	if v, ok := cache.Get("user:0"); !ok || v != "value" {
+		t.Errorf("expected the value to be restored, got %v, %v", v, ok)
+	}
+}
+
+// TestDump_HashKeysWithoutValues redacts keys and drops values
+func TestDump_HashKeysWithoutValues(t *testing.T) {
+	addr := newDumpSource(t, 10, metis.WithHandlerValues())
+
+	var buf bytes.Buffer
+	if _, err := dumpCache(addr, &buf, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "user:") || strings.Contains(buf.String(), `"value"`) {
+		t.Fatalf("expected hashed keys and no values:\n%s", buf.String())
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	scanner.Scan()
+	var header DumpHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || !header.HashedKeys || header.Values {
+		t.Fatalf("unexpected header %q: %v", scanner.Text(), err)
+	}
+	scanner.Scan()
+	var record metis.AdminDumpRecord
+	if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(record.Key, "sha256:") || record.ExpiresAt == nil {
+		t.Errorf("expected a hashed key with its expiration, got %+v", record)
+	}
+	if hashKey("user:1") != hashKey("user:1") || hashKey("user:1") == hashKey("user:2") {
+		t.Error("expected hashKey to be deterministic and distinct")
+	}
+}
+
+// TestDump_MissingValues counts values the service does not expose
+func TestDump_MissingValues(t *testing.T) {
+	addr := newDumpSource(t, 5)
+
+	var buf bytes.Buffer
+	summary, err := dumpCache(addr, &buf, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.MissingValues != 5 {
+		t.Errorf("expected 5 entries without values, got %+v", summary)
+	}
+}
+
+// TestRestore_Snapshot loads a file written by SaveToFile
+func TestRestore_Snapshot(t *testing.T) {
+	source := newRestoreTarget(t)
+	source.Set("a", "1")
+	source.Set("b", "2")
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	if err := source.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newRestoreTarget(t)
+	summary, err := restoreCache(cache, path)
+	if err != nil || summary.Restored != 2 {
+		t.Fatalf("expected 2 entries from the snapshot, got %+v, %v", summary, err)
+	}
+}
+
+// TestRestore_Expired skips entries that expired after the dump
+func TestRestore_Expired(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	_ = enc.Encode(DumpHeader{Format: dumpFormat})
+	_ = enc.Encode(metis.AdminDumpRecord{Key: "old", ExpiresAt: &expired})
+	_ = enc.Encode(metis.AdminDumpRecord{Key: "new", Value: "v"})
+	path := filepath.Join(t.TempDir(), "cache.dump")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newRestoreTarget(t)
+	summary, err := restoreCache(cache, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Read != 2 || summary.Restored != 1 || summary.Expired != 1 {
+		t.Errorf("expected one restored and one expired entry, got %+v", summary)
+	}
+
+	if err := os.WriteFile(path, []byte("not a dump\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restoreCache(cache, path); err == nil {
+		t.Error("expected an error for a file that is not a dump")
+	}
+}
@@ -0,0 +1,112 @@
+// /cmd/metis-debug/explain.go: Ask a running cache what Set would do with a key
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+func cmdExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	addr := fs.String("addr", "", "Address of the metis.Handler of a running service (e.g. localhost:8080/cache)")
+	key := fs.String("key", "", "Key to explain")
+	valueSize := fs.Int("value-size", 0, "Size in bytes of the value to explain")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *addr == "" || *key == "" {
+		fmt.Fprintln(os.Stderr, "Error: explain needs -addr and -key")
+		os.Exit(2)
+	}
+	if *valueSize < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -value-size cannot be negative")
+		os.Exit(2)
+	}
+
+	e, err := fetchExplanation(*addr, *key, *valueSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		printJSON(e)
+		return
+	}
+	fmt.Print(formatExplanation(e))
+}
+
+// fetchExplanation asks the metis.Handler at addr what Set would do with a value of
+// size bytes under key
+func fetchExplanation(addr, key string, size int) (metis.Explanation, error) {
+	u := fmt.Sprintf("%s/explain/%s?size=%d", handlerURL(addr), url.PathEscape(key), size)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(u) // nosec G107 - the URL is supplied by the operator
+	if err != nil {
+		return metis.Explanation{}, fmt.Errorf("cannot reach %s (is the service running and serving the cache there?): %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return metis.Explanation{}, fmt.Errorf("reading %s: %s %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var e metis.Explanation
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return metis.Explanation{}, fmt.Errorf("decoding %s: %w", u, err)
+	}
+	return e, nil
+}
+
+// formatExplanation renders an explanation as one line per check, then the verdict
+func formatExplanation(e metis.Explanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Set(%q, %d bytes) on shard %d (%s)\n\n", e.Key, e.Size, e.Shard, e.Policy)
+	for _, step := range e.Steps {
+		mark := "✓"
+		if !step.Passed {
+			mark = "✗"
+		}
+		fmt.Fprintf(&b, "  %s %-13s %s\n", mark, step.Check, step.Detail)
+	}
+
+	b.WriteString("\nResult: ")
+	switch {
+	case !e.Stored:
+		fmt.Fprintf(&b, "rejected (%s)\n", e.Error)
+	case e.Update:
+		b.WriteString("stored, replacing the resident value\n")
+	default:
+		b.WriteString("stored\n")
+	}
+	if e.Victim != nil {
+		verb := "Evicts"
+		if !e.Stored {
+			verb = "Keeps"
+		}
+		fmt.Fprintf(&b, "%s: %q", verb, e.Victim.Key)
+		if e.Victim.Segment != "" {
+			fmt.Fprintf(&b, " in %s", e.Victim.Segment)
+		}
+		if e.Victim.Frequency > 0 {
+			fmt.Fprintf(&b, ", estimated at %d accesses", e.Victim.Frequency)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+// explain_test.go: Tests for the metis-debug explain command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+func TestExplain_Remote(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      4,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+		MaxValueSize:   1024,
+	})
+	t.Cleanup(cache.Close)
+	for i := 0; i < 4; i++ {
+		cache.Set(fmt.Sprintf("user:%d", i), "value")
+	}
+	server := httptest.NewServer(metis.Handler(cache, metis.WithHandlerReadOnly()))
+	t.Cleanup(server.Close)
+
+	e, err := fetchExplanation(server.URL, "user/new key", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Key != "user/new key" || !e.Stored || e.Victim == nil || e.Victim.Key != "user:0" {
+		t.Errorf("expected the oldest key evicted, got %+v", e)
+	}
+	out := formatExplanation(e)
+	for _, want := range []string{`Set("user/new key", 100 bytes) on shard 0 (lru)`, "✓ value_size", "Result: stored", `Evicts: "user:0"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	e, err = fetchExplanation(server.URL, "user:9", 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := formatExplanation(e); !strings.Contains(out, "✗ value_size") || !strings.Contains(out, "rejected (metis: value too large)") {
+		t.Errorf("expected a value_size rejection, got:\n%s", out)
+	}
+	if cache.Len() != 4 {
+		t.Errorf("expected explain to leave the cache unchanged, got %d keys", cache.Len())
+	}
+}
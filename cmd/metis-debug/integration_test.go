@@ -44,7 +44,7 @@ func TestCLIIntegration(t *testing.T) {
 	})
 
 	t.Run("inspect_estimated_integration", func(t *testing.T) {
-		stdout, stderr, exitCode := helper.RunCommand("inspect")
+		stdout, stderr, exitCode := helper.RunCommand("inspect", "-local")
 
 		helper.AssertExitCode(exitCode, 0)
 		helper.AssertContains(stdout, "Cache Performance Analysis")
@@ -57,7 +57,7 @@ func TestCLIIntegration(t *testing.T) {
 	})
 
 	t.Run("inspect_json_integration", func(t *testing.T) {
-		stdout, stderr, exitCode := helper.RunCommand("inspect", "-json")
+		stdout, stderr, exitCode := helper.RunCommand("inspect", "-local", "-json")
 
 		helper.AssertExitCode(exitCode, 0)
 		jsonData := helper.AssertValidJSON(stdout)
@@ -161,7 +161,7 @@ func TestCLIPerformance(t *testing.T) {
 
 	t.Run("memory_efficiency", func(t *testing.T) {
 		// Test estimated mode (should be very fast)
-		stdout, stderr, exitCode := helper.RunCommand("inspect")
+		stdout, stderr, exitCode := helper.RunCommand("inspect", "-local")
 
 		helper.AssertExitCode(exitCode, 0)
 		helper.AssertContains(stdout, "Cache Performance Analysis")
@@ -177,11 +177,11 @@ func TestCLIOutputFormats(t *testing.T) {
 
 	t.Run("text_vs_json_consistency", func(t *testing.T) {
 		// Get text output
-		textOut, _, textExit := helper.RunCommand("inspect")
+		textOut, _, textExit := helper.RunCommand("inspect", "-local")
 		helper.AssertExitCode(textExit, 0)
 
 		// Get JSON output
-		jsonOut, _, jsonExit := helper.RunCommand("inspect", "-json")
+		jsonOut, _, jsonExit := helper.RunCommand("inspect", "-local", "-json")
 		helper.AssertExitCode(jsonExit, 0)
 
 		// Parse JSON
@@ -228,6 +228,16 @@ func TestCLIErrorHandling(t *testing.T) {
 		helper.AssertContains(stdout, "USAGE:")
 	})
 
+	t.Run("inspect_without_mode", func(t *testing.T) {
+		_, stderr, exitCode := helper.RunCommand("inspect")
+
+		if exitCode == 0 { // go run reports any failure as 1
+			t.Error("expected inspect without -addr or -local to fail")
+		}
+		helper.AssertContains(stderr, "-addr")
+		helper.AssertContains(stderr, "-local")
+	})
+
 	t.Run("help_variations", func(t *testing.T) {
 		helpVariations := []string{"help", "-h", "--help"}
 
@@ -10,8 +10,10 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +34,20 @@ func main() {
 	switch command {
 	case "inspect":
 		cmdInspect(os.Args[2:])
+	case "bench":
+		cmdBench(os.Args[2:])
+	case "compare":
+		cmdCompare(os.Args[2:])
+	case "dump":
+		cmdDump(os.Args[2:])
+	case "restore":
+		cmdRestore(os.Args[2:])
+	case "replay":
+		cmdReplay(os.Args[2:])
+	case "clear":
+		cmdClear(os.Args[2:])
+	case "explain":
+		cmdExplain(os.Args[2:])
 	case "version":
 		cmdVersion()
 	case "help", "-h", "--help":
@@ -48,12 +64,58 @@ func showHelp() {
 	fmt.Println("USAGE: metis-debug <command> [flags]")
 	fmt.Println("COMMANDS:")
 	fmt.Println("  inspect     Show cache statistics and performance analysis")
+	fmt.Println("  bench       Run a configurable workload against a local cache")
+	fmt.Println("  compare     Run the same workload against two config files and diff the results")
+	fmt.Println("  dump        Write the keys (and values) of a running cache to a file")
+	fmt.Println("  restore     Load a dump or snapshot into a local cache")
+	fmt.Println("  replay      Replay a trace recorded with StartTrace against a local cache")
+	fmt.Println("  clear       Clear a running cache, one shard or only its expired entries")
+	fmt.Println("  explain     Show what Set would do with a key on a running cache, and why")
 	fmt.Println("  version     Show version information")
 	fmt.Println("  help        Show this help")
 	fmt.Println("\nINSPECT FLAGS:")
+	fmt.Println("  -addr       Read stats from a running service's metis.Handler (e.g. localhost:8080/cache)")
+	fmt.Println("              or expvar endpoint (e.g. localhost:8080/debug/vars)")
+	fmt.Println("  -var        expvar name of the cache (default: the only one published)")
+	fmt.Println("  -local      Analyze a cache built in this process instead of a running service")
+	fmt.Println("  -real       With -local, measure a real Metis cache (default: estimated)")
+	fmt.Println("  -watch      With -addr, refresh every interval (e.g. 2s) with rates, until Ctrl-C")
+	fmt.Println("  -json-lines With -watch, print one JSON object per interval")
 	fmt.Println("  -json       Output in JSON format")
 	fmt.Println("  -v          Enable verbose output")
-	fmt.Println("  -real       Use real Metis cache measurements (default: estimated)")
+	fmt.Println("\nBENCH FLAGS:")
+	fmt.Println("  -duration, -workers, -keys, -size, -value-size, -read-ratio,")
+	fmt.Println("  -distribution uniform|zipf, -seed, -policy, -shards, -compression, -json")
+	fmt.Println("  (run 'metis-debug bench -h' for details)")
+	fmt.Println("\nCOMPARE FLAGS:")
+	fmt.Println("  -config-a, -config-b  Config files of the baseline and the candidate")
+	fmt.Println("  -threshold  Exit 1 when B is worse than A by more than this percentage")
+	fmt.Println("  the bench workload flags, and -json")
+	fmt.Println("\nDUMP FLAGS:")
+	fmt.Println("  -addr       Address of the service's metis.Handler")
+	fmt.Println("  -out        File to write (- for stdout)")
+	fmt.Println("  -values     Include values (needs metis.WithHandlerValues on the service)")
+	fmt.Println("  -hash-keys  Replace keys with their SHA-256 hash")
+	fmt.Println("\nRESTORE FLAGS:")
+	fmt.Println("  -in         Dump or snapshot file to load")
+	fmt.Println("  -config     Config file of the local cache")
+	fmt.Println("  -snapshot   Save the restored cache with SaveToFile")
+	fmt.Println("  -json       Output in JSON format")
+	fmt.Println("\nREPLAY FLAGS:")
+	fmt.Println("  -trace      Trace file to replay")
+	fmt.Println("  -config     Config file of the cache to evaluate")
+	fmt.Println("  -scale      Scale cache_size by the sample rate of the trace (default true)")
+	fmt.Println("  -json       Output in JSON format")
+	fmt.Println("\nCLEAR FLAGS:")
+	fmt.Println("  -addr       Address of the service's metis.Handler")
+	fmt.Println("  -shard      Clear only this shard")
+	fmt.Println("  -expired    Remove only the expired entries")
+	fmt.Println("  -all        Clear every entry")
+	fmt.Println("\nEXPLAIN FLAGS:")
+	fmt.Println("  -addr       Address of the service's metis.Handler")
+	fmt.Println("  -key        Key to explain")
+	fmt.Println("  -value-size Size in bytes of the value (default 0)")
+	fmt.Println("  -json       Output in JSON format")
 }
 
 func cmdVersion() {
@@ -66,11 +128,39 @@ func cmdInspect(args []string) {
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
 	verbose := fs.Bool("v", false, "Enable verbose output")
 	realData := fs.Bool("real", false, "Use real Metis cache instead of mock data")
+	addr := fs.String("addr", "", "Address of a metis.Handler or expvar endpoint to read stats from")
+	varName := fs.String("var", "", "expvar name of the cache, with an expvar -addr")
+	local := fs.Bool("local", false, "Analyze a cache built in this process")
+	watch := fs.Duration("watch", 0, "With -addr, refresh the stats every interval (e.g. 2s) until Ctrl-C")
+	jsonLines := fs.Bool("json-lines", false, "With -watch, print one JSON object per interval")
 
 	if err := fs.Parse(args); err != nil {
 		return
 	}
 
+	if *watch > 0 {
+		if *addr == "" {
+			fmt.Fprintln(os.Stderr, "Error: -watch needs -addr")
+			os.Exit(2)
+		}
+		if err := watchUntilInterrupt(*addr, *varName, *watch, *jsonLines); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *addr != "" {
+		if err := showRemoteStats(*addr, *varName, *jsonOutput, *verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if !*local && !*realData {
+		fmt.Fprintln(os.Stderr, "Error: inspect needs -addr to read a running service, or -local to analyze a cache built by metis-debug")
+		os.Exit(2)
+	}
+
 	performHealthCheck(*jsonOutput)
 	if *realData {
 		showRealStats(*jsonOutput, *verbose)
@@ -80,21 +170,86 @@ func cmdInspect(args []string) {
 }
 
 func performHealthCheck(jsonOutput bool) {
-	health := map[string]interface{}{
-		"status": "passed",
-		"checks": map[string]bool{
-			"memory_usage_ok":     true,
-			"admission_filter_ok": true,
-		},
-		"timestamp": time.Now().Format(time.RFC3339),
-	}
-
+	health := HealthReport{Status: "passed", Timestamp: time.Now().Format(time.RFC3339)}
 	if jsonOutput {
 		return // Will be included in showStats JSON output
 	}
 
 	fmt.Println("=== Cache Performance Analysis ===")
-	fmt.Printf("Health Check: ✓ %s\n\n", strings.ToUpper(health["status"].(string)))
+	fmt.Printf("Health Check: ✓ %s\n\n", strings.ToUpper(health.Status))
+}
+
+// EstimatedMetrics are the typical figures inspect -local reports for W-TinyLFU
+type EstimatedMetrics struct {
+	Type           string  `json:"type"`
+	OpsPerSec      int64   `json:"estimated_ops_per_sec"`
+	SetLatencyNs   int64   `json:"avg_set_latency_ns"`
+	GetLatencyNs   int64   `json:"avg_get_latency_ns"`
+	HitRatePercent float64 `json:"hit_rate_percent"`
+}
+
+// estimatedMetrics is what inspect -local prints without -real
+var estimatedMetrics = EstimatedMetrics{
+	Type:           "W-TinyLFU (Estimated)",
+	OpsPerSec:      3500000,
+	SetLatencyNs:   133,
+	GetLatencyNs:   80,
+	HitRatePercent: 92.5,
+}
+
+// MemoryReport summarizes runtime.MemStats for inspect -json
+type MemoryReport struct {
+	AllocMB    float64 `json:"alloc_mb"`
+	TotalAlloc uint64  `json:"total_alloc"`
+	NumGC      uint32  `json:"num_gc"`
+	NextGCMB   float64 `json:"next_gc_mb"`
+}
+
+func newMemoryReport(mem *runtime.MemStats) MemoryReport {
+	return MemoryReport{
+		AllocMB:    float64(mem.Alloc) / 1024 / 1024,
+		TotalAlloc: mem.TotalAlloc,
+		NumGC:      mem.NumGC,
+		NextGCMB:   float64(mem.NextGC) / 1024 / 1024,
+	}
+}
+
+// RuntimeReport describes the Go runtime for inspect -json
+type RuntimeReport struct {
+	GoVersion string `json:"go_version"`
+	Arch      string `json:"arch"`
+	OS        string `json:"os"`
+	NumCPU    int    `json:"num_cpu"`
+}
+
+func newRuntimeReport() RuntimeReport {
+	return RuntimeReport{GoVersion: runtime.Version(), Arch: runtime.GOARCH, OS: runtime.GOOS, NumCPU: runtime.NumCPU()}
+}
+
+// HealthReport is the outcome of performHealthCheck
+type HealthReport struct {
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+}
+
+// InspectReport is the output of inspect -json. Cache holds EstimatedMetrics or
+// RealMetrics; the statistics of a real cache are reported by the library's own types.
+type InspectReport struct {
+	Cache       interface{}               `json:"cache"`
+	Stats       *metis.CacheStats         `json:"stats,omitempty"`
+	Shards      []metis.ShardStats        `json:"shards,omitempty"`
+	Compression *metis.CompressionStats   `json:"compression,omitempty"`
+	Config      *metis.AdminConfigSummary `json:"config,omitempty"`
+	HotKeys     []metis.KeyFrequency      `json:"hot_keys,omitempty"`
+	Memory      MemoryReport              `json:"memory"`
+	Runtime     RuntimeReport             `json:"runtime"`
+	Health      *HealthReport             `json:"health,omitempty"`
+}
+
+// printJSON prints v indented
+func printJSON(v interface{}) {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(data))
 }
 
 func showStats(jsonOutput bool, verbose bool) {
@@ -102,33 +257,12 @@ func showStats(jsonOutput bool, verbose bool) {
 	runtime.ReadMemStats(&mem)
 
 	if jsonOutput {
-		stats := map[string]interface{}{
-			"cache": map[string]interface{}{
-				"estimated_ops_per_sec": 3500000,
-				"avg_set_latency_ns":    133,
-				"avg_get_latency_ns":    80,
-				"hit_rate_percent":      92.5,
-				"type":                  "W-TinyLFU (Estimated)",
-			},
-			"memory": map[string]interface{}{
-				"alloc_mb":    float64(mem.Alloc) / 1024 / 1024,
-				"total_alloc": mem.TotalAlloc,
-				"num_gc":      mem.NumGC,
-				"next_gc_mb":  float64(mem.NextGC) / 1024 / 1024,
-			},
-			"runtime": map[string]interface{}{
-				"go_version": runtime.Version(),
-				"arch":       runtime.GOARCH,
-				"os":         runtime.GOOS,
-				"num_cpu":    runtime.NumCPU(),
-			},
-			"health": map[string]interface{}{
-				"status":    "passed",
-				"timestamp": time.Now().Format(time.RFC3339),
-			},
-		}
-		data, _ := json.MarshalIndent(stats, "", "  ")
-		fmt.Println(string(data))
+		printJSON(InspectReport{
+			Cache:   estimatedMetrics,
+			Memory:  newMemoryReport(&mem),
+			Runtime: newRuntimeReport(),
+			Health:  &HealthReport{Status: "passed", Timestamp: time.Now().Format(time.RFC3339)},
+		})
 	} else {
 		fmt.Printf("Runtime Information:\n")
 		fmt.Printf("- Go Version: %s\n", runtime.Version())
@@ -143,10 +277,10 @@ func showStats(jsonOutput bool, verbose bool) {
 		fmt.Printf("- Next GC Target: %.1f MB\n\n", float64(mem.NextGC)/1024/1024)
 
 		fmt.Printf("Cache Performance Metrics:\n")
-		fmt.Printf("- Estimated Operations/sec: %s\n", "3,500,000")
-		fmt.Printf("- Average Set Latency: %d ns\n", 133)
-		fmt.Printf("- Average Get Latency: %d ns\n", 80)
-		fmt.Printf("- Estimated Hit Rate: %.1f%%\n", 92.5)
+		fmt.Printf("- Estimated Operations/sec: %s\n", formatNumber(estimatedMetrics.OpsPerSec))
+		fmt.Printf("- Average Set Latency: %d ns\n", estimatedMetrics.SetLatencyNs)
+		fmt.Printf("- Average Get Latency: %d ns\n", estimatedMetrics.GetLatencyNs)
+		fmt.Printf("- Estimated Hit Rate: %.1f%%\n", estimatedMetrics.HitRatePercent)
 		fmt.Printf("- Cache Type: W-TinyLFU with admission filter\n")
 	}
 }
@@ -165,6 +299,7 @@ func showRealStats(jsonOutput bool, verbose bool) {
 		EvictionPolicy:    "wtinylfu",
 		ShardCount:        16,
 		AdmissionPolicy:   "always",
+		TrackHotKeys:      10,
 	}
 
 	cache := metis.NewStrategicCache(config)
@@ -177,39 +312,27 @@ func showRealStats(jsonOutput bool, verbose bool) {
 	runtime.ReadMemStats(&mem)
 
 	if jsonOutput {
-		stats := map[string]interface{}{
-			"cache": map[string]interface{}{
-				"type":                "W-TinyLFU (Real)",
-				"real_ops_per_sec":    realMetrics.OpsPerSec,
-				"real_set_latency_ns": realMetrics.SetLatencyNs,
-				"real_get_latency_ns": realMetrics.GetLatencyNs,
-				"cache_size":          realMetrics.CacheSize,
-				"hit_rate_percent":    realMetrics.HitRate,
-				"total_operations":    realMetrics.TotalOps,
-				"eviction_policy":     config.EvictionPolicy,
-			},
-			"memory": map[string]interface{}{
-				"alloc_mb":    float64(mem.Alloc) / 1024 / 1024,
-				"total_alloc": mem.TotalAlloc,
-				"num_gc":      mem.NumGC,
-				"next_gc_mb":  float64(mem.NextGC) / 1024 / 1024,
-			},
-			"runtime": map[string]interface{}{
-				"go_version": runtime.Version(),
-				"arch":       runtime.GOARCH,
-				"os":         runtime.GOOS,
-				"num_cpu":    runtime.NumCPU(),
-			},
-			"config": map[string]interface{}{
-				"cache_size":         config.CacheSize,
-				"eviction_policy":    config.EvictionPolicy,
-				"shard_count":        config.ShardCount,
-				"enable_compression": config.EnableCompression,
-				"ttl_minutes":        int(config.TTL.Minutes()),
+		stats := cache.GetStats()
+		report := InspectReport{
+			Cache: realMetrics,
+			Stats: &stats,
+			Config: &metis.AdminConfigSummary{
+				EvictionPolicy:    cache.PolicyName(),
+				AdmissionPolicy:   config.AdmissionPolicy,
+				CacheSize:         config.CacheSize,
+				ShardCount:        cache.EffectiveConfig().ShardCount,
+				TTL:               config.TTL.String(),
+				EnableCompression: config.EnableCompression,
+				MaxMemoryBytes:    config.MaxMemoryBytes,
 			},
+			HotKeys: cache.HotKeys(),
+			Memory:  newMemoryReport(&mem),
+			Runtime: newRuntimeReport(),
+		}
+		if verbose {
+			report.Shards = cache.ShardStats()
 		}
-		data, _ := json.MarshalIndent(stats, "", "  ")
-		fmt.Println(string(data))
+		printJSON(report)
 	} else {
 		fmt.Printf("=== REAL Metis Cache Analysis ===\n\n")
 
@@ -227,6 +350,8 @@ func showRealStats(jsonOutput bool, verbose bool) {
 		fmt.Printf("- Hit Rate: %.1f%%\n", realMetrics.HitRate)
 		fmt.Printf("- Cache Utilization: %d/%d entries\n\n", realMetrics.CacheSize, config.CacheSize)
 
+		printHotKeys(cache.HotKeys())
+
 		fmt.Printf("Runtime Information:\n")
 		fmt.Printf("- Go Version: %s\n", runtime.Version())
 		fmt.Printf("- Architecture: %s\n", runtime.GOARCH)
@@ -241,14 +366,163 @@ func showRealStats(jsonOutput bool, verbose bool) {
 	}
 }
 
+// showRemoteStats reads the stats of a running cache from addr and prints them. addr is
+// either a metis.Handler, read with GET /stats, or an expvar endpoint ending in
+// /debug/vars, where the cache is the variable varName or the only one published.
+func showRemoteStats(addr, varName string, jsonOutput bool, verbose bool) error {
+	stats, err := fetchRemoteStats(addr, varName)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		printJSON(stats)
+		return nil
+	}
+
+	fmt.Printf("=== Metis Cache at %s ===\n\n", addr)
+	fmt.Printf("Cache Configuration:\n")
+	if stats.Config.AdmissionPolicy != "" {
+		fmt.Printf("- Policy: %s (admission: %s)\n", stats.Config.EvictionPolicy, stats.Config.AdmissionPolicy)
+	} else {
+		fmt.Printf("- Policy: %s\n", stats.Config.EvictionPolicy)
+	}
+	if stats.Config.CacheSize > 0 { // Not published through expvar
+		fmt.Printf("- Size: %d entries\n", stats.Config.CacheSize)
+	}
+	fmt.Printf("- Shards: %d\n", stats.Config.ShardCount)
+	fmt.Printf("- TTL: %s\n", stats.Config.TTL)
+	if stats.Config.CacheSize > 0 {
+		fmt.Printf("- Compression: %v\n", stats.Config.EnableCompression)
+	}
+	fmt.Println()
+
+	fmt.Printf("Cache Statistics:\n")
+	fmt.Printf("- Keys: %d\n", stats.Stats.Keys)
+	fmt.Printf("- Hits: %d\n", stats.Stats.Hits)
+	fmt.Printf("- Misses: %d\n", stats.Stats.Misses)
+	fmt.Printf("- Hit Rate: %.1f%%\n", stats.Stats.HitRate()*100)
+	fmt.Printf("- Evictions: %d\n", stats.Stats.Evictions)
+	fmt.Printf("- Expirations: %d\n", stats.Stats.Expirations)
+	fmt.Printf("- Memory: %.1f MB\n", float64(stats.Stats.MemoryBytes)/1024/1024)
+
+	if verbose {
+		fmt.Printf("\nShards:\n")
+		for _, shard := range stats.Shards {
+			fmt.Printf("- %s\n", shard)
+		}
+	}
+	if len(stats.HotKeys) > 0 {
+		fmt.Println()
+		printHotKeys(stats.HotKeys)
+	}
+	return nil
+}
+
+// handlerURL turns the -addr of a metis.Handler into a base URL
+func handlerURL(addr string) string {
+	url := strings.TrimSuffix(addr, "/")
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	return url
+}
+
+// fetchRemoteStats reads the stats of the metis.Handler or expvar endpoint at addr
+func fetchRemoteStats(addr, varName string) (metis.AdminStats, error) {
+	url := handlerURL(addr)
+	if strings.HasSuffix(url, "/debug/vars") {
+		return fetchExpvarStats(url, varName)
+	}
+	var stats metis.AdminStats
+	err := fetchJSON(url+"/stats", &stats)
+	return stats, err
+}
+
+// fetchJSON decodes the body of GET url into v
+func fetchJSON(url string, v interface{}) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url) // nosec G107 - the URL is supplied by the operator
+	if err != nil {
+		return fmt.Errorf("cannot reach %s (is the service running and serving the cache there?): %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return nil
+}
+
+// fetchExpvarStats reads the metis.ExpvarSnapshot published as varName at url, or the
+// only snapshot published when varName is empty. expvar carries no per-shard figures
+// or admission and size settings, so those are left empty.
+func fetchExpvarStats(url, varName string) (metis.AdminStats, error) {
+	var vars map[string]json.RawMessage
+	if err := fetchJSON(url, &vars); err != nil {
+		return metis.AdminStats{}, err
+	}
+
+	var names []string
+	snapshots := make(map[string]metis.ExpvarSnapshot)
+	for name, raw := range vars {
+		var snap metis.ExpvarSnapshot
+		if json.Unmarshal(raw, &snap) == nil && snap.EvictionPolicy != "" {
+			names = append(names, name)
+			snapshots[name] = snap
+		}
+	}
+	sort.Strings(names)
+
+	if varName == "" {
+		switch len(names) {
+		case 0:
+			return metis.AdminStats{}, fmt.Errorf("no metis cache published at %s (see StrategicCache.PublishExpvar)", url)
+		case 1:
+			varName = names[0]
+		default:
+			return metis.AdminStats{}, fmt.Errorf("several caches published at %s, choose one with -var: %s", url, strings.Join(names, ", "))
+		}
+	}
+	snap, ok := snapshots[varName]
+	if !ok {
+		return metis.AdminStats{}, fmt.Errorf("no metis cache published as %q at %s", varName, url)
+	}
+
+	return metis.AdminStats{
+		Stats: snap.Stats,
+		Config: metis.AdminConfigSummary{
+			EvictionPolicy: snap.EvictionPolicy,
+			ShardCount:     snap.ShardCount,
+			TTL:            snap.TTL,
+		},
+	}, nil
+}
+
+// printHotKeys lists the most read keys reported by metis.StrategicCache.HotKeys
+func printHotKeys(keys []metis.KeyFrequency) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Printf("Hot Keys:\n")
+	for _, k := range keys {
+		fmt.Printf("- %s: ~%d reads\n", k.Key, k.Count)
+	}
+	fmt.Println()
+}
+
 // RealMetrics holds real performance measurements
 type RealMetrics struct {
-	OpsPerSec    int64
-	SetLatencyNs int64
-	GetLatencyNs int64
-	HitRate      float64
-	CacheSize    int
-	TotalOps     int64
+	Type           string  `json:"type"`
+	OpsPerSec      int64   `json:"real_ops_per_sec"`
+	SetLatencyNs   int64   `json:"real_set_latency_ns"`
+	GetLatencyNs   int64   `json:"real_get_latency_ns"`
+	HitRate        float64 `json:"hit_rate_percent"`
+	CacheSize      int     `json:"cache_size"`
+	TotalOps       int64   `json:"total_operations"`
+	EvictionPolicy string  `json:"eviction_policy"`
 }
 
 // measureRealPerformance performs actual cache operations and measures performance
@@ -294,12 +568,14 @@ func measureRealPerformance(cache *metis.StrategicCache) RealMetrics {
 	cacheSize := testKeys // Simplified for now
 
 	return RealMetrics{
-		OpsPerSec:    opsPerSec,
-		SetLatencyNs: setLatencyNs,
-		GetLatencyNs: getLatencyNs,
-		HitRate:      hitRate,
-		CacheSize:    cacheSize,
-		TotalOps:     numOps * 2,
+		Type:           "W-TinyLFU (Real)",
+		EvictionPolicy: cache.PolicyName(),
+		OpsPerSec:      opsPerSec,
+		SetLatencyNs:   setLatencyNs,
+		GetLatencyNs:   getLatencyNs,
+		HitRate:        hitRate,
+		CacheSize:      cacheSize,
+		TotalOps:       numOps * 2,
 	}
 }
 
@@ -9,11 +9,16 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"expvar"
 	"fmt"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/agilira/metis"
 )
 
 // TestMain runs setup and teardown for all tests
@@ -306,6 +311,11 @@ func TestShowRealStats(t *testing.T) {
 				if _, ok := jsonData["config"]; !ok {
 					t.Error("JSON missing config section in real mode")
 				}
+
+				// The cache's own CacheStats, with the computed hit rate
+				if stats, ok := jsonData["stats"].(map[string]interface{}); !ok || stats["hits"] == nil || stats["hit_rate"] == nil {
+					t.Errorf("JSON missing stats section with hits and hit_rate in real mode, got %v", jsonData["stats"])
+				}
 			} else {
 				// Test text output
 				expectedStrings := []string{
@@ -375,16 +385,16 @@ func TestCmdInspectFlagParsing(t *testing.T) {
 		args []string
 	}{
 		{
-			name: "no flags",
-			args: []string{},
+			name: "local flag",
+			args: []string{"-local"},
 		},
 		{
 			name: "json flag",
-			args: []string{"-json"},
+			args: []string{"-local", "-json"},
 		},
 		{
 			name: "verbose flag",
-			args: []string{"-v"},
+			args: []string{"-local", "-v"},
 		},
 		{
 			name: "real flag",
@@ -411,6 +421,86 @@ func TestCmdInspectFlagParsing(t *testing.T) {
 	}
 }
 
+// TestShowRemoteStats reads stats from a metis.Handler over HTTP
+func TestShowRemoteStats(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", TrackHotKeys: 5})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+
+	server := httptest.NewServer(metis.Handler(cache))
+	defer server.Close()
+
+	var err error
+	output := captureOutput(func() {
+		err = showRemoteStats(strings.TrimPrefix(server.URL, "http://"), "", false, true)
+	})
+	if err != nil {
+		t.Fatalf("showRemoteStats: %v", err)
+	}
+	for _, expected := range []string{"Policy: lru", "Keys: 1", "Hits: 1", "Shards:", "Hot Keys:", "- a: ~1 reads"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output missing %q:\n%s", expected, output)
+		}
+	}
+
+	output = captureOutput(func() {
+		err = showRemoteStats(server.URL, "", true, false)
+	})
+	var stats metis.AdminStats
+	if err != nil || json.Unmarshal([]byte(output), &stats) != nil || stats.Stats.Keys != 1 {
+		t.Errorf("expected JSON stats with 1 key, got %q (err %v)", output, err)
+	}
+
+	if err := showRemoteStats(server.URL+"/missing", "", false, false); err == nil {
+		t.Error("expected an error for a bad endpoint")
+	}
+}
+
+// TestShowRemoteStats_Expvar reads stats published with PublishExpvar
+func TestShowRemoteStats_Expvar(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	defer cache.Close()
+	// expvar names outlive the test, so each run publishes under its own
+	cache.PublishExpvar(fmt.Sprintf("metis_debug_test_%d", time.Now().UnixNano()))
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("b")
+
+	server := httptest.NewServer(expvar.Handler())
+	defer server.Close()
+	addr := server.URL + "/debug/vars"
+
+	var err error
+	output := captureOutput(func() {
+		err = showRemoteStats(addr, "", false, false)
+	})
+	if err != nil {
+		t.Fatalf("showRemoteStats: %v", err)
+	}
+	for _, expected := range []string{"Policy: lru\n", "Keys: 1", "Hits: 1", "Misses: 1", "Hit Rate: 50.0%"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output missing %q:\n%s", expected, output)
+		}
+	}
+
+	if err := showRemoteStats(addr, "other", false, false); err == nil || !strings.Contains(err.Error(), `"other"`) {
+		t.Errorf("expected an error naming the missing variable, got %v", err)
+	}
+}
+
+// TestShowRemoteStats_Unreachable reports an address nothing listens on
+func TestShowRemoteStats_Unreachable(t *testing.T) {
+	server := httptest.NewServer(nil)
+	addr := server.URL
+	server.Close()
+
+	err := showRemoteStats(addr, "", false, false)
+	if err == nil || !strings.Contains(err.Error(), "cannot reach") {
+		t.Errorf("expected a cannot reach error, got %v", err)
+	}
+}
+
 // BenchmarkShowStats benchmarks the stats function
 func BenchmarkShowStats(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,189 @@
+// /cmd/metis-debug/replay.go: Replay a recorded trace against a local cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// ReplayReport is the outcome of the replay command
+type ReplayReport struct {
+	Trace      string  `json:"trace"`
+	Config     string  `json:"config,omitempty"`
+	Policy     string  `json:"policy"`
+	CacheSize  int     `json:"cache_size"`  // As replayed, after scaling by the sample rate
+	SampleRate float64 `json:"sample_rate"` // Fraction of the keys the trace holds
+	// TraceDuration is how long the recording ran, up to its last record
+	TraceDuration time.Duration `json:"trace_duration"`
+	Gets          int64         `json:"gets"`
+	Sets          int64         `json:"sets"`
+	Deletes       int64         `json:"deletes"`
+	// RecordedHitRate is the hit rate of the Gets in the recording, HitRate that of the replay
+	RecordedHitRate float64            `json:"recorded_hit_rate"`
+	HitRate         float64            `json:"hit_rate"`
+	Elapsed         time.Duration      `json:"elapsed"`
+	OpsPerSec       float64            `json:"ops_per_sec"`
+	Latency         metis.LatencyStats `json:"latency"`
+	MemoryBytes     int64              `json:"memory_bytes"`
+}
+
+func cmdReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	tracePath := fs.String("trace", "", "Trace recorded with StrategicCache.StartTrace")
+	configPath := fs.String("config", "", "Config file of the cache to evaluate (default: the configuration metis.New would load)")
+	scale := fs.Bool("scale", true, "Scale cache_size by the sample rate of the trace")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *tracePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: replay needs -trace")
+		os.Exit(2)
+	}
+
+	config := metis.LoadConfig()
+	if *configPath != "" {
+		var err error
+		if config, err = metis.LoadConfigFile(*configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := runReplay(*tracePath, config, *scale)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	report.Config = *configPath
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	printReplayReport(report)
+}
+
+// runReplay replays the trace at path, as fast as it can, against a cache built from
+// config. With scale, CacheSize is multiplied by the sample rate of the trace, so that
+// a cache holding a sample of the keys gets the same share of them. Keys are rebuilt
+// from their hashes. A Get that hit in the recording but misses in the replay is followed
+// by a Set of the recorded value size, as the application would have loaded the value
+// and stored it; after a Get that also missed in the recording, that Set is in the trace.
+func runReplay(path string, config metis.CacheConfig, scale bool) (ReplayReport, error) {
+	f, err := os.Open(path) // nosec G304 - the path is supplied by the operator
+	if err != nil {
+		return ReplayReport{}, err
+	}
+	defer f.Close()
+	tr, err := metis.NewTraceReader(f)
+	if err != nil {
+		return ReplayReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+	if scale && tr.Header.SampleRate > 0 && tr.Header.SampleRate < 1 {
+		config.CacheSize = max(1, int(float64(config.CacheSize)*tr.Header.SampleRate))
+	}
+	config.EnableLatencyTracking = true
+	cache, err := metis.NewStrategicCacheE(config)
+	if err != nil {
+		return ReplayReport{}, err
+	}
+	defer cache.Close()
+
+	report := ReplayReport{Trace: path, Policy: cache.PolicyName(), CacheSize: config.CacheSize, SampleRate: tr.Header.SampleRate}
+	var values []byte // Values are prefixes of one buffer, sized as recorded
+	value := func(size int) []byte {
+		if size > len(values) {
+			values = make([]byte, size)
+		}
+		return values[:size]
+	}
+
+	var hits, recordedHits int64
+	start := time.Now()
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("%s: record %d: %w", path, report.Gets+report.Sets+report.Deletes+1, err)
+		}
+		report.TraceDuration = rec.Time
+
+		key := strconv.FormatUint(rec.KeyHash, 36)
+		switch rec.Op {
+		case metis.TraceGet:
+			report.Gets++
+			if rec.Hit {
+				recordedHits++
+			}
+			if _, ok := cache.Get(key); ok {
+				hits++
+			} else if rec.Hit {
+				cache.Set(key, value(rec.ValueSize))
+			}
+		case metis.TraceSet:
+			report.Sets++
+			cache.Set(key, value(rec.ValueSize))
+		case metis.TraceDelete:
+			report.Deletes++
+			cache.Delete(key)
+		}
+	}
+	report.Elapsed = time.Since(start)
+
+	if ops := report.Gets + report.Sets + report.Deletes; ops > 0 {
+		report.OpsPerSec = float64(ops) / report.Elapsed.Seconds()
+	}
+	if report.Gets > 0 {
+		report.HitRate = float64(hits) / float64(report.Gets)
+		report.RecordedHitRate = float64(recordedHits) / float64(report.Gets)
+	}
+	report.Latency = cache.LatencyStats()
+	report.MemoryBytes = cache.GetStats().MemoryBytes
+	return report, nil
+}
+
+func printReplayReport(r ReplayReport) {
+	fmt.Printf("=== Metis Replay ===\n\n")
+	fmt.Printf("Trace: %s\n", r.Trace)
+	fmt.Printf("- Recorded: %v, %s gets, %s sets, %s deletes\n",
+		r.TraceDuration.Round(time.Millisecond), formatNumber(r.Gets), formatNumber(r.Sets), formatNumber(r.Deletes))
+	if r.SampleRate < 1 {
+		fmt.Printf("- Sampled: %.1f%% of the keys\n", r.SampleRate*100)
+	}
+	fmt.Println()
+
+	fmt.Printf("Cache Configuration:\n")
+	if r.Config != "" {
+		fmt.Printf("- Config: %s\n", r.Config)
+	}
+	fmt.Printf("- Policy: %s\n", r.Policy)
+	fmt.Printf("- Size: %d entries\n\n", r.CacheSize)
+
+	fmt.Printf("Results:\n")
+	fmt.Printf("- Hit Rate: %.1f%% (recorded: %.1f%%)\n", r.HitRate*100, r.RecordedHitRate*100)
+	fmt.Printf("- Operations/sec: %s\n", formatNumber(int64(r.OpsPerSec)))
+	fmt.Printf("- Get Latency: %s\n", formatLatency(r.Latency.Get))
+	fmt.Printf("- Set Latency: %s\n", formatLatency(r.Latency.Set))
+	fmt.Printf("- Memory: %.1f MB\n", float64(r.MemoryBytes)/1024/1024)
+}
@@ -0,0 +1,107 @@
+// replay_test.go: Tests for the metis-debug replay command
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// recordTrace runs a cache-aside workload of skewed reads against an LRU cache of size
+// entries while recording it, and returns the trace file
+func recordTrace(t *testing.T, size int, opts ...metis.TraceOption) string {
+	t.Helper()
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: size, ShardCount: 1, TTL: time.Hour, EvictionPolicy: "lru"})
+	defer cache.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.trace")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := cache.StartTrace(f, opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	z := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, 4999)
+	for i := 0; i < 50000; i++ {
+		key := fmt.Sprintf("user:%d", z.Uint64())
+		if _, ok := cache.Get(key); !ok {
+			cache.Set(key, make([]byte, 100))
+		}
+		if i%1000 == 0 {
+			cache.Delete(key)
+		}
+	}
+	if err := cache.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRunReplay replays a trace against the recorded configuration and a larger one
+func TestRunReplay(t *testing.T) {
+	path := recordTrace(t, 500)
+
+	same, err := runReplay(path, metis.CacheConfig{EnableCaching: true, CacheSize: 500, ShardCount: 1, TTL: time.Hour, EvictionPolicy: "lru"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Gets != 50000 || same.Deletes != 50 || same.Sets == 0 {
+		t.Errorf("unexpected operation counts %+v", same)
+	}
+	if math.Abs(same.HitRate-same.RecordedHitRate) > 0.001 {
+		t.Errorf("expected the recorded configuration to replay the recorded hit rate %.4f, got %.4f", same.RecordedHitRate, same.HitRate)
+	}
+	if same.Latency.Get.Count != same.Gets || same.Policy != "lru" {
+		t.Errorf("expected Get latencies of the lru cache, got %+v", same)
+	}
+
+	larger, err := runReplay(path, metis.CacheConfig{EnableCaching: true, CacheSize: 5000, TTL: time.Hour, EvictionPolicy: "arc"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if larger.HitRate <= same.HitRate {
+		t.Errorf("expected a larger cache to hit more than %.3f, got %.3f", same.HitRate, larger.HitRate)
+	}
+}
+
+// TestRunReplay_Scale scales the cache to the sampled share of the keys
+func TestRunReplay_Scale(t *testing.T) {
+	path := recordTrace(t, 500, metis.WithTraceSampleRate(0.5))
+	config := metis.CacheConfig{EnableCaching: true, CacheSize: 500, TTL: time.Hour, EvictionPolicy: "lru"}
+
+	scaled, err := runReplay(path, config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scaled.SampleRate != 0.5 || scaled.CacheSize != 250 {
+		t.Errorf("expected a cache of 250 for half the keys, got %d at %v", scaled.CacheSize, scaled.SampleRate)
+	}
+	unscaled, _ := runReplay(path, config, false)
+	if unscaled.CacheSize != 500 {
+		t.Errorf("expected cache_size as configured without -scale, got %d", unscaled.CacheSize)
+	}
+}
+
+func TestRunReplay_NotATrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metis.json")
+	if err := os.WriteFile(path, []byte(`{"cache_size": 100}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runReplay(path, metis.CacheConfig{EnableCaching: true}, true); err == nil {
+		t.Error("expected an error for a file that is not a trace")
+	}
+}
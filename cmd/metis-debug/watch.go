@@ -0,0 +1,153 @@
+// /cmd/metis-debug/watch.go: Live refreshing stats of a running cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// clearScreen moves the cursor home and clears the terminal before each redraw
+const clearScreen = "\033[H\033[2J"
+
+// WatchSample is one interval of inspect -watch, and one line of -json-lines
+type WatchSample struct {
+	Time     time.Time `json:"time"`
+	Interval float64   `json:"interval_seconds"`
+	Keys     int       `json:"keys"`
+	// Rates over the interval; Gets are the hits and misses the cache counted
+	GetsPerSec        float64 `json:"gets_per_sec"`
+	HitRate           float64 `json:"hit_rate"` // Hits / Gets in the interval, 0 to 1
+	EvictionsPerSec   float64 `json:"evictions_per_sec"`
+	ExpirationsPerSec float64 `json:"expirations_per_sec"`
+	MemoryBytes       int64   `json:"memory_bytes"`
+	MemoryGrowth      int64   `json:"memory_growth_bytes"` // Change of MemoryBytes over the interval
+	// Lifetime totals, as inspect shows them without -watch
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	// Error is set instead of the figures when the stats could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// watchUntilInterrupt runs inspect -watch on stdout until Ctrl-C or SIGTERM
+func watchUntilInterrupt(addr, varName string, interval time.Duration, jsonLines bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	redraw := !jsonLines && isTerminal(os.Stdout)
+	err := watchRemoteStats(ctx, os.Stdout, addr, varName, interval, jsonLines, redraw)
+	if redraw {
+		fmt.Println() // Leave the prompt below the last frame and the ^C
+	}
+	return err
+}
+
+// watchRemoteStats reads the stats at addr every interval and writes the rates since the
+// previous read to out, as a redrawn screen, appended text blocks or JSON lines, until
+// ctx is done. Only the first read failing is an error; later failures are reported in
+// the output and the next interval tries again.
+func watchRemoteStats(ctx context.Context, out io.Writer, addr, varName string, interval time.Duration, jsonLines, redraw bool) error {
+	prev, err := fetchRemoteStats(addr, varName)
+	if err != nil {
+		return err
+	}
+	prevTime := time.Now()
+	if !jsonLines {
+		writeWatchFrame(out, addr, interval, newWatchSample(prev, prev, 0, prevTime), redraw)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			stats, err := fetchRemoteStats(addr, varName)
+			var sample WatchSample
+			if err != nil {
+				sample = WatchSample{Time: now, Error: err.Error()}
+			} else {
+				sample = newWatchSample(prev, stats, now.Sub(prevTime), now)
+				prev, prevTime = stats, now
+			}
+
+			if jsonLines {
+				data, _ := json.Marshal(sample)
+				fmt.Fprintln(out, string(data))
+			} else {
+				writeWatchFrame(out, addr, interval, sample, redraw)
+			}
+		}
+	}
+}
+
+// newWatchSample computes the rates between two reads elapsed apart; the first frame
+// passes the same stats twice and no elapsed time, and gets zero rates
+func newWatchSample(prev, cur metis.AdminStats, elapsed time.Duration, now time.Time) WatchSample {
+	delta := cur.Stats.Delta(prev.Stats)
+	rates := delta.Rates(elapsed)
+	return WatchSample{
+		Time:              now,
+		Interval:          elapsed.Seconds(),
+		Keys:              cur.Stats.Keys,
+		GetsPerSec:        rates.OpsPerSec,
+		HitRate:           delta.HitRate,
+		EvictionsPerSec:   rates.EvictionsPerSec,
+		ExpirationsPerSec: rates.ExpirationsPerSec,
+		MemoryBytes:       cur.Stats.MemoryBytes,
+		MemoryGrowth:      delta.MemoryGrowth,
+		Hits:              cur.Stats.Hits,
+		Misses:            cur.Stats.Misses,
+		Evictions:         cur.Stats.Evictions,
+	}
+}
+
+// writeWatchFrame writes one sample; with redraw it replaces the previous frame
+func writeWatchFrame(out io.Writer, addr string, interval time.Duration, s WatchSample, redraw bool) {
+	if redraw {
+		fmt.Fprint(out, clearScreen)
+	} else {
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "=== Metis Cache at %s, %s, every %v (Ctrl-C to quit) ===\n\n", addr, s.Time.Format("15:04:05"), interval)
+	if s.Error != "" {
+		fmt.Fprintf(out, "Cannot read stats: %s\n", s.Error)
+		return
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Keys:\t%s\n", formatNumber(int64(s.Keys)))
+	fmt.Fprintf(tw, "Memory:\t%.1f MB (%+.1f MB)\n", float64(s.MemoryBytes)/1024/1024, float64(s.MemoryGrowth)/1024/1024)
+	if s.Interval == 0 {
+		fmt.Fprintf(tw, "Rates:\tafter the first interval\n")
+	} else {
+		fmt.Fprintf(tw, "Gets/sec:\t%s\n", formatNumber(int64(s.GetsPerSec)))
+		fmt.Fprintf(tw, "Hit rate:\t%.1f%%\n", s.HitRate*100)
+		fmt.Fprintf(tw, "Evictions/sec:\t%.1f\n", s.EvictionsPerSec)
+		fmt.Fprintf(tw, "Expirations/sec:\t%.1f\n", s.ExpirationsPerSec)
+	}
+	fmt.Fprintf(tw, "Lifetime:\t%s hits, %s misses, %s evictions\n",
+		formatNumber(s.Hits), formatNumber(s.Misses), formatNumber(s.Evictions))
+	_ = tw.Flush()
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
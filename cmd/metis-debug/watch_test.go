@@ -0,0 +1,97 @@
+// watch_test.go: Tests for inspect -watch
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// TestWatchRemoteStats_JSONLines emits one sample per interval with rates
+func TestWatchRemoteStats_JSONLines(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Hour, EvictionPolicy: "lru"})
+	defer cache.Close()
+	cache.Set("k", "v")
+	server := httptest.NewServer(metis.Handler(cache))
+	defer server.Close()
+
+	// Keep reading while the watch runs
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cache.Get("k")
+				cache.Get("missing")
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	if err := watchRemoteStats(ctx, &out, server.URL, "", 50*time.Millisecond, true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected a line per interval, got %q", out.String())
+	}
+	var sample WatchSample
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &sample); err != nil {
+		t.Fatal(err)
+	}
+	if sample.GetsPerSec <= 0 || sample.HitRate < 0.3 || sample.HitRate > 0.7 || sample.Keys != 1 {
+		t.Errorf("expected rates from the reads, got %+v", sample)
+	}
+}
+
+// TestWatchRemoteStats_Text redraws frames and fails fast on an unreachable address
+func TestWatchRemoteStats_Text(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour})
+	defer cache.Close()
+	server := httptest.NewServer(metis.Handler(cache))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	if err := watchRemoteStats(ctx, &out, server.URL, "", 30*time.Millisecond, false, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{clearScreen, "Rates:", "Gets/sec:", "Evictions/sec:", "Lifetime:"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output missing %q:\n%s", expected, out.String())
+		}
+	}
+
+	server.Close()
+	if err := watchRemoteStats(context.Background(), &out, server.URL, "", time.Second, false, false); err == nil {
+		t.Error("expected an error for an unreachable address")
+	}
+}
+
+// TestNewWatchSample_CounterReset treats a counter that went down as reset
+func TestNewWatchSample_CounterReset(t *testing.T) {
+	prev := metis.AdminStats{Stats: metis.CacheStats{Hits: 1000, Misses: 1000, MemoryBytes: 2048}}
+	cur := metis.AdminStats{Stats: metis.CacheStats{Hits: 30, Misses: 10, MemoryBytes: 1024}}
+	s := newWatchSample(prev, cur, 2*time.Second, time.Now())
+	if s.GetsPerSec != 20 || s.HitRate != 0.75 || s.MemoryGrowth != -1024 {
+		t.Errorf("unexpected sample after a reset: %+v", s)
+	}
+}
@@ -0,0 +1,152 @@
+// histogram.go: Fixed-bucket latency histograms and the per-second timeline
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// latencySteps are the bucket bounds within each decade, so that a percentile
+// reported as the upper bound of its bucket is at most 25% above the true value
+var latencySteps = [...]int64{10, 12, 15, 20, 25, 30, 40, 50, 60, 70, 80, 90}
+
+// latencyDecades is how many decades the buckets span, from 10ns up to 10s
+const latencyDecades = 9
+
+// latencyBounds are the upper bounds of the buckets in nanoseconds. Durations above
+// the last bound fall in one extra overflow bucket.
+var latencyBounds = func() (bounds [latencyDecades * len(latencySteps)]int64) {
+	scale := int64(1)
+	for d := 0; d < latencyDecades; d++ {
+		for i, step := range latencySteps {
+			bounds[d*len(latencySteps)+i] = step * scale
+		}
+		scale *= 10
+	}
+	return bounds
+}()
+
+// latencyRecorder counts operation latencies in fixed buckets. It is safe for
+// concurrent use, and its size does not depend on the number of operations.
+type latencyRecorder struct {
+	counts [len(latencyBounds) + 1]atomic.Int64
+	total  atomic.Int64 // Sum of the durations in nanoseconds, for the average
+	max    atomic.Int64
+}
+
+// Record adds one operation latency
+func (r *latencyRecorder) Record(d time.Duration) {
+	ns := max(0, d.Nanoseconds())
+	i := sort.Search(len(latencyBounds), func(i int) bool { return latencyBounds[i] >= ns })
+	r.counts[i].Add(1)
+	r.total.Add(ns)
+	for cur := r.max.Load(); ns > cur && !r.max.CompareAndSwap(cur, ns); cur = r.max.Load() {
+	}
+}
+
+// Snapshot copies the current counts
+func (r *latencyRecorder) Snapshot() latencySnapshot {
+	var s latencySnapshot
+	for i := range r.counts {
+		n := r.counts[i].Load()
+		s.counts[i] = n
+		s.Count += n
+	}
+	s.Total = time.Duration(r.total.Load())
+	s.Max = time.Duration(r.max.Load())
+	return s
+}
+
+// latencySnapshot is a point-in-time copy of a latencyRecorder
+type latencySnapshot struct {
+	counts [len(latencyBounds) + 1]int64
+	Count  int64
+	Total  time.Duration
+	Max    time.Duration // Largest latency ever recorded, also in the results of Sub
+}
+
+// Sub returns the operations recorded between prev and s
+func (s latencySnapshot) Sub(prev latencySnapshot) latencySnapshot {
+	for i := range s.counts {
+		s.counts[i] -= prev.counts[i]
+	}
+	s.Count -= prev.Count
+	s.Total -= prev.Total
+	return s
+}
+
+// Avg returns the average latency
+func (s latencySnapshot) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Percentile returns the upper bound of the bucket holding the q-th latency, 0 < q <= 1,
+// capped at the largest latency seen, which is also the answer for the overflow bucket
+func (s latencySnapshot) Percentile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(s.Count)))
+	var seen int64
+	for i, n := range s.counts {
+		if seen += n; seen >= rank && i < len(latencyBounds) {
+			return min(time.Duration(latencyBounds[i]), s.Max)
+		} else if seen >= rank {
+			break
+		}
+	}
+	return s.Max
+}
+
+// timelinePoint is one interval of the benchmark
+type timelinePoint struct {
+	Second      int     `json:"second"` // Seconds since the start, at the end of the interval
+	OpsPerSec   float64 `json:"ops_per_sec"`
+	GetP99Ns    int64   `json:"get_p99_ns"`
+	SetP99Ns    int64   `json:"set_p99_ns"`
+	HeapAllocMB uint64  `json:"heap_alloc_mb"`
+}
+
+// recordTimeline samples the operation count, the Get and Set p99 and the heap every
+// interval until stop is closed. Each point covers the operations of its own interval
+// only, so a throughput dip or a latency spike shows at the second it happened.
+func recordTimeline(stop <-chan struct{}, interval time.Duration, totalOps *int64, getStat, setStat *latencyRecorder) []timelinePoint {
+	var timeline []timelinePoint
+	start := time.Now()
+	prevTime, prevOps := start, atomic.LoadInt64(totalOps)
+	prevGet, prevSet := getStat.Snapshot(), setStat.Snapshot()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return timeline
+		case now := <-ticker.C:
+			ops := atomic.LoadInt64(totalOps)
+			get, set := getStat.Snapshot(), setStat.Snapshot()
+			var mem runtime.MemStats
+			runtime.ReadMemStats(&mem)
+
+			timeline = append(timeline, timelinePoint{
+				Second:      int(now.Sub(start).Round(time.Second) / time.Second),
+				OpsPerSec:   float64(ops-prevOps) / now.Sub(prevTime).Seconds(),
+				GetP99Ns:    get.Sub(prevGet).Percentile(0.99).Nanoseconds(),
+				SetP99Ns:    set.Sub(prevSet).Percentile(0.99).Nanoseconds(),
+				HeapAllocMB: mem.HeapAlloc / 1024 / 1024,
+			})
+			prevTime, prevOps, prevGet, prevSet = now, ops, get, set
+		}
+	}
+}
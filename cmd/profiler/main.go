@@ -9,16 +9,19 @@ package main
 import (
 	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/agilira/metis"
+	"github.com/agilira/metis/internal/bench"
 )
 
 // Configuration constants for the profiler
@@ -35,6 +38,35 @@ const (
 )
 
 func main() {
+	sweep := flag.Bool("sweep", false, "Replay one seeded key trace against every cache size and policy, instead of the benchmark")
+	sizes := flag.String("sizes", "1k,5k,10k,50k,100k", "Cache sizes of -sweep, comma-separated")
+	policies := flag.String("policies", "lru,wtinylfu,arc", "Eviction policies of -sweep, comma-separated")
+	ops := flag.Int("ops", 1_000_000, "Operations in the -sweep trace")
+	keys := flag.Int("keys", 200_000, "Key space of the -sweep trace")
+	distribution := flag.String("distribution", bench.Zipf, "Key distribution of the -sweep trace: uniform or zipf")
+	readRatio := flag.Float64("read-ratio", 0.9, "Fraction of Gets in the -sweep trace, 0 to 1")
+	seed := flag.Int64("seed", 1, "Seed of the -sweep trace")
+	out := flag.String("out", "metis_sweep.csv", "CSV file of the -sweep results")
+	flag.Parse()
+
+	if *sweep {
+		if err := sweepToFile(*out, *sizes, *policies, sweepConfig{
+			Ops:    *ops,
+			Shards: shardCount,
+			Workload: bench.Workload{
+				Keys:         *keys,
+				ValueSize:    valueSize,
+				ReadRatio:    *readRatio,
+				Distribution: *distribution,
+				Seed:         *seed,
+			},
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
 	cache := metis.NewStrategicCache(metis.CacheConfig{
@@ -75,10 +107,12 @@ func main() {
 
 	fmt.Println("[BENCHMARK] Starting benchmark workload")
 
-	var setStat, getStat opStat
+	var setStat, getStat latencyRecorder
 	var totalOps int64
 	var wg sync.WaitGroup
 	stop := make(chan struct{})
+	timelineDone := make(chan []timelinePoint, 1)
+	go func() { timelineDone <- recordTimeline(stop, time.Second, &totalOps, &getStat, &setStat) }()
 
 	// Pre-allocate values for reuse
 	valuePool := make([][]byte, 100) // Reduced pool size
@@ -128,14 +162,18 @@ func main() {
 	fmt.Println("[BENCHMARK] Stopping workers...")
 	close(stop)
 	wg.Wait()
+	timeline := <-timelineDone
 	fmt.Println("[BENCHMARK] All workers stopped")
 
 	runtime.ReadMemStats(&memStats)
+	set, get := setStat.Snapshot(), getStat.Snapshot()
 
 	fmt.Println("--- Results ---")
 	fmt.Printf("Total operations: %d\n", totalOps)
-	fmt.Printf("Set:  avg=%v min=%v max=%v\n", setStat.Avg(), setStat.Min, setStat.Max)
-	fmt.Printf("Get:  avg=%v min=%v max=%v\n", getStat.Avg(), getStat.Min, getStat.Max)
+	fmt.Printf("Set:  avg=%v p50=%v p90=%v p99=%v p999=%v max=%v\n",
+		set.Avg(), set.Percentile(0.50), set.Percentile(0.90), set.Percentile(0.99), set.Percentile(0.999), set.Max)
+	fmt.Printf("Get:  avg=%v p50=%v p90=%v p99=%v p999=%v max=%v\n",
+		get.Avg(), get.Percentile(0.50), get.Percentile(0.90), get.Percentile(0.99), get.Percentile(0.999), get.Max)
 	fmt.Printf("Ops/sec: %.2f\n", float64(totalOps)/duration.Seconds())
 	fmt.Printf("Heap alloc: %d MB, GCs: %d, GC fraction: %.2f%%\n",
 		memStats.HeapAlloc/1024/1024, memStats.NumGC, memStats.GCCPUFraction*100)
@@ -150,27 +188,56 @@ func main() {
 		// Write CSV data - ignore write errors for profiling tool
 		_ = writer.Write([]string{"metric", "value"})
 		_ = writer.Write([]string{"total_ops", fmt.Sprintf("%d", totalOps)})
-		_ = writer.Write([]string{"set_avg_ns", fmt.Sprintf("%d", setStat.Avg().Nanoseconds())})
-		_ = writer.Write([]string{"get_avg_ns", fmt.Sprintf("%d", getStat.Avg().Nanoseconds())})
+		for _, op := range []struct {
+			name  string
+			stats latencySnapshot
+		}{{"set", set}, {"get", get}} {
+			_ = writer.Write([]string{op.name + "_avg_ns", fmt.Sprintf("%d", op.stats.Avg().Nanoseconds())})
+			for _, p := range percentiles {
+				_ = writer.Write([]string{op.name + "_" + p.name + "_ns", fmt.Sprintf("%d", op.stats.Percentile(p.q).Nanoseconds())})
+			}
+		}
 		_ = writer.Write([]string{"ops_per_sec", fmt.Sprintf("%.2f", float64(totalOps)/duration.Seconds())})
 		_ = writer.Write([]string{"heap_alloc_mb", fmt.Sprintf("%d", memStats.HeapAlloc/1024/1024)})
 		_ = writer.Write([]string{"gc_count", fmt.Sprintf("%d", memStats.NumGC)})
 		_ = writer.Write([]string{"gc_fraction", fmt.Sprintf("%.2f", memStats.GCCPUFraction*100)})
 	}
 
+	// Export the timeline, one row per second
+	timelineFile, err := os.Create("metis_timeline.csv")
+	if err == nil {
+		defer timelineFile.Close()
+		writer := csv.NewWriter(timelineFile)
+		defer writer.Flush()
+
+		_ = writer.Write([]string{"second", "ops_per_sec", "get_p99_ns", "set_p99_ns", "heap_alloc_mb"})
+		for _, point := range timeline {
+			_ = writer.Write([]string{
+				fmt.Sprintf("%d", point.Second),
+				fmt.Sprintf("%.2f", point.OpsPerSec),
+				fmt.Sprintf("%d", point.GetP99Ns),
+				fmt.Sprintf("%d", point.SetP99Ns),
+				fmt.Sprintf("%d", point.HeapAllocMB),
+			})
+		}
+	}
+
 	// Export JSON
 	jsonData := map[string]interface{}{
 		"total_ops":     totalOps,
-		"set_avg_ns":    setStat.Avg().Nanoseconds(),
-		"set_min_ns":    setStat.Min.Nanoseconds(),
-		"set_max_ns":    setStat.Max.Nanoseconds(),
-		"get_avg_ns":    getStat.Avg().Nanoseconds(),
-		"get_min_ns":    getStat.Min.Nanoseconds(),
-		"get_max_ns":    getStat.Max.Nanoseconds(),
+		"set_avg_ns":    set.Avg().Nanoseconds(),
+		"set_max_ns":    set.Max.Nanoseconds(),
+		"get_avg_ns":    get.Avg().Nanoseconds(),
+		"get_max_ns":    get.Max.Nanoseconds(),
 		"ops_per_sec":   float64(totalOps) / duration.Seconds(),
 		"heap_alloc_mb": memStats.HeapAlloc / 1024 / 1024,
 		"gc_count":      memStats.NumGC,
 		"gc_fraction":   memStats.GCCPUFraction * 100,
+		"timeline":      timeline,
+	}
+	for _, p := range percentiles {
+		jsonData["set_"+p.name+"_ns"] = set.Percentile(p.q).Nanoseconds()
+		jsonData["get_"+p.name+"_ns"] = get.Percentile(p.q).Nanoseconds()
 	}
 	jsonFile, err := os.Create("metis_results.json")
 	if err == nil {
@@ -182,33 +249,31 @@ func main() {
 	}
 }
 
-// Global memory statistics for reporting
-var memStats runtime.MemStats
-
-// opStat keeps track of latency metrics for an operation type
-type opStat struct {
-	Min   time.Duration
-	Max   time.Duration
-	Total time.Duration
-	Count int64
-}
+// sweepToFile runs -sweep and writes its CSV to path
+func sweepToFile(path, sizes, policies string, cfg sweepConfig) error {
+	var err error
+	if cfg.Sizes, err = parseSizes(sizes); err != nil {
+		return err
+	}
+	cfg.Policies = strings.Split(policies, ",")
 
-// Record registers a single operation latency into the statistics
-func (s *opStat) Record(d time.Duration) {
-	if s.Count == 0 || d < s.Min {
-		s.Min = d
+	f, err := os.Create(path) // nosec G304 - the path is supplied by the operator
+	if err != nil {
+		return err
 	}
-	if d > s.Max {
-		s.Max = d
+	defer f.Close()
+	if err := runSweep(f, os.Stdout, cfg); err != nil {
+		return err
 	}
-	s.Total += d
-	s.Count++
+	fmt.Printf("[SWEEP] Results written to %s\n", path)
+	return f.Close()
 }
 
-// Avg returns the average latency for the recorded operations
-func (s *opStat) Avg() time.Duration {
-	if s.Count == 0 {
-		return 0
-	}
-	return time.Duration(int64(s.Total) / s.Count)
-}
+// Global memory statistics for reporting
+var memStats runtime.MemStats
+
+// percentiles are the latency percentiles written to the CSV and JSON results
+var percentiles = []struct {
+	name string
+	q    float64
+}{{"p50", 0.50}, {"p90", 0.90}, {"p99", 0.99}, {"p999", 0.999}}
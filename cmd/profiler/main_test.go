@@ -11,93 +11,137 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/agilira/metis"
 )
 
-// TestOpStat_Record tests the Record method of opStat
-func TestOpStat_Record(t *testing.T) {
-	stat := &opStat{}
+// TestLatencyRecorder_Record tests the Record method of latencyRecorder
+func TestLatencyRecorder_Record(t *testing.T) {
+	var stat latencyRecorder
 
-	// Test first record
-	duration1 := 100 * time.Millisecond
-	stat.Record(duration1)
+	stat.Record(100 * time.Millisecond)
+	stat.Record(50 * time.Millisecond)
+	stat.Record(200 * time.Millisecond)
 
-	if stat.Count != 1 {
-		t.Errorf("Expected count 1, got %d", stat.Count)
+	s := stat.Snapshot()
+	if s.Count != 3 {
+		t.Errorf("Expected count 3, got %d", s.Count)
 	}
-	if stat.Min != duration1 {
-		t.Errorf("Expected min %v, got %v", duration1, stat.Min)
+	if s.Max != 200*time.Millisecond {
+		t.Errorf("Expected max %v, got %v", 200*time.Millisecond, s.Max)
 	}
-	if stat.Max != duration1 {
-		t.Errorf("Expected max %v, got %v", duration1, stat.Max)
+	if s.Total != 350*time.Millisecond {
+		t.Errorf("Expected total %v, got %v", 350*time.Millisecond, s.Total)
 	}
-	if stat.Total != duration1 {
-		t.Errorf("Expected total %v, got %v", duration1, stat.Total)
+	if s.Avg() != 350*time.Millisecond/3 {
+		t.Errorf("Expected avg %v, got %v", 350*time.Millisecond/3, s.Avg())
 	}
+}
 
-	// Test second record (shorter)
-	duration2 := 50 * time.Millisecond
-	stat.Record(duration2)
+// TestLatencyRecorder_Percentiles tests that percentiles fall on the bucket bounds
+func TestLatencyRecorder_Percentiles(t *testing.T) {
+	var stat latencyRecorder
 
-	if stat.Count != 2 {
-		t.Errorf("Expected count 2, got %d", stat.Count)
+	if p := stat.Snapshot().Percentile(0.99); p != 0 {
+		t.Errorf("Expected p99 0 for an empty recorder, got %v", p)
 	}
-	if stat.Min != duration2 {
-		t.Errorf("Expected min %v, got %v", duration2, stat.Min)
+
+	// 990 fast operations and 10 slow ones: p50 and p90 are fast, p99.9 is slow
+	for i := 0; i < 990; i++ {
+		stat.Record(95 * time.Nanosecond)
 	}
-	if stat.Max != duration1 {
-		t.Errorf("Expected max %v, got %v", duration1, stat.Max)
+	for i := 0; i < 10; i++ {
+		stat.Record(3 * time.Millisecond)
 	}
-	if stat.Total != duration1+duration2 {
-		t.Errorf("Expected total %v, got %v", duration1+duration2, stat.Total)
+
+	s := stat.Snapshot()
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.50, 100 * time.Nanosecond}, // 95ns is in the (90ns, 100ns] bucket
+		{0.90, 100 * time.Nanosecond},
+		{0.99, 100 * time.Nanosecond},
+		{0.999, 3 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := s.Percentile(tt.q); got != tt.want {
+			t.Errorf("Percentile(%g) = %v, want %v", tt.q, got, tt.want)
+		}
 	}
 
-	// Test third record (longer)
-	duration3 := 200 * time.Millisecond
-	stat.Record(duration3)
+	// Durations past the last bucket report the largest one seen
+	stat.Record(time.Minute)
+	if p := stat.Snapshot().Percentile(1); p != time.Minute {
+		t.Errorf("Expected the overflow bucket to report %v, got %v", time.Minute, p)
+	}
+}
 
-	if stat.Count != 3 {
-		t.Errorf("Expected count 3, got %d", stat.Count)
+// TestLatencyRecorder_Concurrent tests that no operation is lost across goroutines
+func TestLatencyRecorder_Concurrent(t *testing.T) {
+	var stat latencyRecorder
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				stat.Record(time.Duration(id*1000+j) * time.Nanosecond)
+			}
+		}(i)
 	}
-	if stat.Min != duration2 {
-		t.Errorf("Expected min %v, got %v", duration2, stat.Min)
+	wg.Wait()
+
+	if s := stat.Snapshot(); s.Count != 8000 || s.Max != 7999*time.Nanosecond {
+		t.Errorf("Expected 8000 operations up to 7.999µs, got %d up to %v", s.Count, s.Max)
 	}
-	if stat.Max != duration3 {
-		t.Errorf("Expected max %v, got %v", duration3, stat.Max)
+}
+
+// TestLatencySnapshot_Sub tests the interval between two snapshots
+func TestLatencySnapshot_Sub(t *testing.T) {
+	var stat latencyRecorder
+	stat.Record(time.Millisecond)
+	prev := stat.Snapshot()
+	stat.Record(time.Microsecond)
+	stat.Record(time.Microsecond)
+
+	interval := stat.Snapshot().Sub(prev)
+	if interval.Count != 2 || interval.Avg() != time.Microsecond {
+		t.Errorf("Expected 2 operations of 1µs, got %d of %v", interval.Count, interval.Avg())
 	}
-	if stat.Total != duration1+duration2+duration3 {
-		t.Errorf("Expected total %v, got %v", duration1+duration2+duration3, stat.Total)
+	if p := interval.Percentile(0.99); p != time.Microsecond {
+		t.Errorf("Expected the interval p99 to leave out earlier operations, got %v", p)
 	}
 }
 
-// TestOpStat_Avg tests the Avg method of opStat
-func TestOpStat_Avg(t *testing.T) {
-	stat := &opStat{}
-
-	// Test empty stat
-	avg := stat.Avg()
-	if avg != 0 {
-		t.Errorf("Expected avg 0 for empty stat, got %v", avg)
+// TestRecordTimeline tests that the timeline has one point per interval
+func TestRecordTimeline(t *testing.T) {
+	var getStat, setStat latencyRecorder
+	var totalOps int64
+	stop := make(chan struct{})
+	done := make(chan []timelinePoint, 1)
+	go func() { done <- recordTimeline(stop, 20*time.Millisecond, &totalOps, &getStat, &setStat) }()
+
+	deadline := time.Now().Add(110 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		getStat.Record(time.Microsecond)
+		setStat.Record(2 * time.Microsecond)
+		atomic.AddInt64(&totalOps, 2)
+		time.Sleep(time.Millisecond)
 	}
+	close(stop)
+	timeline := <-done
 
-	// Test single record
-	duration1 := 100 * time.Millisecond
-	stat.Record(duration1)
-	avg = stat.Avg()
-	if avg != duration1 {
-		t.Errorf("Expected avg %v, got %v", duration1, avg)
+	if len(timeline) < 3 {
+		t.Fatalf("Expected a point every 20ms over 110ms, got %d", len(timeline))
 	}
-
-	// Test multiple records
-	duration2 := 200 * time.Millisecond
-	stat.Record(duration2)
-	expectedAvg := time.Duration((100+200)/2) * time.Millisecond
-	avg = stat.Avg()
-	if avg != expectedAvg {
-		t.Errorf("Expected avg %v, got %v", expectedAvg, avg)
+	for i, point := range timeline {
+		if point.OpsPerSec <= 0 || point.GetP99Ns != 1000 || point.SetP99Ns != 2000 {
+			t.Errorf("Point %d: unexpected %+v", i, point)
+		}
 	}
 }
 
@@ -10,38 +10,6 @@ import (
 	"github.com/agilira/metis"
 )
 
-func TestOpStat(t *testing.T) {
-	stat := &opStat{}
-
-	// Test recording operations
-	stat.Record(time.Millisecond)
-	stat.Record(2 * time.Millisecond)
-	stat.Record(3 * time.Millisecond)
-
-	if stat.Count != 3 {
-		t.Errorf("Expected count 3, got %d", stat.Count)
-	}
-
-	if stat.Min != time.Millisecond {
-		t.Errorf("Expected min %v, got %v", time.Millisecond, stat.Min)
-	}
-
-	if stat.Max != 3*time.Millisecond {
-		t.Errorf("Expected max %v, got %v", 3*time.Millisecond, stat.Max)
-	}
-
-	expected := 2 * time.Millisecond // (1+2+3)/3 = 2
-	if stat.Avg() != expected {
-		t.Errorf("Expected average %v, got %v", expected, stat.Avg())
-	}
-
-	// Test zero case
-	emptyStat := &opStat{}
-	if emptyStat.Avg() != 0 {
-		t.Errorf("Expected zero average for empty opStat, got %v", emptyStat.Avg())
-	}
-}
-
 func TestCacheConfig(t *testing.T) {
 	// Test that we can create a valid cache config like main() does
 	config := metis.CacheConfig{
@@ -82,9 +50,7 @@ func TestCacheConfig(t *testing.T) {
 func TestStatisticsCollection(t *testing.T) {
 	// Test the statistics collection logic that would be used in main()
 
-	setStat := &opStat{}
-	getStat := &opStat{}
-	deleteStat := &opStat{}
+	var setStat, getStat, deleteStat latencyRecorder
 
 	// Simulate operations
 	setStat.Record(time.Microsecond * 100)
@@ -96,30 +62,37 @@ func TestStatisticsCollection(t *testing.T) {
 
 	deleteStat.Record(time.Microsecond * 80)
 
+	set, get, del := setStat.Snapshot(), getStat.Snapshot(), deleteStat.Snapshot()
+
 	// Verify statistics
-	if setStat.Count != 3 {
-		t.Errorf("Expected 3 set operations, got %d", setStat.Count)
+	if set.Count != 3 {
+		t.Errorf("Expected 3 set operations, got %d", set.Count)
 	}
 
-	if getStat.Count != 2 {
-		t.Errorf("Expected 2 get operations, got %d", getStat.Count)
+	if get.Count != 2 {
+		t.Errorf("Expected 2 get operations, got %d", get.Count)
 	}
 
-	if deleteStat.Count != 1 {
-		t.Errorf("Expected 1 delete operation, got %d", deleteStat.Count)
+	if del.Count != 1 {
+		t.Errorf("Expected 1 delete operation, got %d", del.Count)
 	}
 
 	// Test averages
 	expectedSetAvg := time.Microsecond * 150 // (100+150+200)/3
-	if setStat.Avg() != expectedSetAvg {
-		t.Errorf("Expected set average %v, got %v", expectedSetAvg, setStat.Avg())
+	if set.Avg() != expectedSetAvg {
+		t.Errorf("Expected set average %v, got %v", expectedSetAvg, set.Avg())
 	}
 
 	expectedGetAvg := time.Microsecond * 62 // (50+75)/2 = 62.5 -> 62
-	actualGetAvg := getStat.Avg()
+	actualGetAvg := get.Avg()
 	if actualGetAvg < time.Microsecond*60 || actualGetAvg > time.Microsecond*65 {
 		t.Errorf("Expected get average around %v, got %v", expectedGetAvg, actualGetAvg)
 	}
+
+	// Test percentiles: the median set is in the (120µs, 150µs] bucket
+	if p50 := set.Percentile(0.5); p50 != 150*time.Microsecond {
+		t.Errorf("Expected set p50 %v, got %v", 150*time.Microsecond, p50)
+	}
 }
 
 func TestConfigFileHandling(t *testing.T) {
@@ -182,31 +155,31 @@ func TestWorkloadPatterns(t *testing.T) {
 	})
 
 	// Test read-heavy workload simulation
-	readStat := &opStat{}
+	var readStat latencyRecorder
 	for i := 0; i < 10; i++ {
 		start := time.Now()
 		_, _ = cache.Get("nonexistent")
 		readStat.Record(time.Since(start))
 	}
 
-	if readStat.Count != 10 {
-		t.Errorf("Expected 10 read operations, got %d", readStat.Count)
+	if n := readStat.Snapshot().Count; n != 10 {
+		t.Errorf("Expected 10 read operations, got %d", n)
 	}
 
 	// Test write-heavy workload simulation
-	writeStat := &opStat{}
+	var writeStat latencyRecorder
 	for i := 0; i < 5; i++ {
 		start := time.Now()
 		_ = cache.Set("key"+string(rune('0'+i)), "value")
 		writeStat.Record(time.Since(start))
 	}
 
-	if writeStat.Count != 5 {
-		t.Errorf("Expected 5 write operations, got %d", writeStat.Count)
+	if n := writeStat.Snapshot().Count; n != 5 {
+		t.Errorf("Expected 5 write operations, got %d", n)
 	}
 
 	// Test balanced workload
-	mixedStat := &opStat{}
+	var mixedStat latencyRecorder
 	for i := 0; i < 6; i++ {
 		if i%2 == 0 {
 			start := time.Now()
@@ -219,8 +192,8 @@ func TestWorkloadPatterns(t *testing.T) {
 		}
 	}
 
-	if mixedStat.Count != 6 {
-		t.Errorf("Expected 6 mixed operations, got %d", mixedStat.Count)
+	if n := mixedStat.Snapshot().Count; n != 6 {
+		t.Errorf("Expected 6 mixed operations, got %d", n)
 	}
 }
 
@@ -259,8 +232,8 @@ func TestMainConstants(t *testing.T) {
 	}
 }
 
-func BenchmarkOpStatRecord(b *testing.B) {
-	stat := &opStat{}
+func BenchmarkLatencyRecorderRecord(b *testing.B) {
+	var stat latencyRecorder
 	duration := time.Microsecond
 
 	b.ResetTimer()
@@ -269,15 +242,16 @@ func BenchmarkOpStatRecord(b *testing.B) {
 	}
 }
 
-func BenchmarkOpStatAvg(b *testing.B) {
-	stat := &opStat{}
+func BenchmarkLatencySnapshotPercentile(b *testing.B) {
+	var stat latencyRecorder
 	// Pre-populate with some data
 	for i := 0; i < 1000; i++ {
-		stat.Record(time.Microsecond)
+		stat.Record(time.Duration(i) * time.Microsecond)
 	}
+	s := stat.Snapshot()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = stat.Avg()
+		_ = s.Percentile(0.99)
 	}
 }
@@ -0,0 +1,174 @@
+// codec.go: Pluggable compression codecs for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Codec compresses cached values when EnableCompression is set.
+// Every stored value records the ID of the codec that wrote it, so entries stay
+// readable after CacheConfig.CompressionCodec changes as long as the codec is registered.
+type Codec interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+	// ID identifies the codec in stored values; it must be unique and non-zero
+	ID() byte
+}
+
+// GzipCodecID is the ID of the built-in gzip codec
+const GzipCodecID byte = 1
+
+// DefaultCompressionCodec is the codec used when CacheConfig.CompressionCodec is empty
+const DefaultCompressionCodec = "gzip"
+
+// gzipCodec is the built-in codec, backed by compressGzipLevel and decompressGzip.
+// Values carry the codec header, so the gzip body is written without one.
+// Zero settings use DefaultCompressionMinSize and DefaultCompressionLevel.
+type gzipCodec struct {
+	minSize int
+	level   int
+}
+
+// Compress gzips data, storing bodies under the minimum size as-is
+func (c gzipCodec) Compress(data []byte) ([]byte, error) {
+	minSize, level := c.minSize, c.level
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+	if level == 0 {
+		level = DefaultCompressionLevel
+	}
+	return compressGzipLevel(data, minSize, level)
+}
+
+// Decompress restores data written by Compress
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	return decompressGzip(data)
+}
+
+// ID returns GzipCodecID
+func (gzipCodec) ID() byte { return GzipCodecID }
+
+// Codec registry, keyed by name for configuration and by ID for decoding
+var (
+	codecMu     sync.RWMutex
+	codecByName = map[string]Codec{DefaultCompressionCodec: gzipCodec{}}
+	codecByID   = map[byte]Codec{GzipCodecID: gzipCodec{}}
+)
+
+// RegisterCodec makes a codec selectable by name via CacheConfig.CompressionCodec.
+// Names and IDs must be unique; register codecs before creating caches that use them.
+func RegisterCodec(name string, codec Codec) error {
+	if name == "" || codec == nil {
+		return fmt.Errorf("codec name and implementation are required")
+	}
+	if codec.ID() == 0 {
+		return fmt.Errorf("codec %q: ID 0 is reserved", name)
+	}
+
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	if _, exists := codecByName[name]; exists {
+		return fmt.Errorf("codec %q is already registered", name)
+	}
+	if existing, exists := codecByID[codec.ID()]; exists {
+		return fmt.Errorf("codec %q: ID %d is already used by %T", name, codec.ID(), existing)
+	}
+	codecByName[name] = codec
+	codecByID[codec.ID()] = codec
+	return nil
+}
+
+// LookupCodec returns the codec registered under name
+func LookupCodec(name string) (Codec, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	codec, ok := codecByName[name]
+	return codec, ok
+}
+
+// lookupCodecID returns the codec that wrote a value
+func lookupCodecID(id byte) (Codec, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	codec, ok := codecByID[id]
+	return codec, ok
+}
+
+// valueHeaderMagic starts the 4-byte header of compressed values,
+// followed by the codec ID and the type tag
+const valueHeaderMagic = "MV"
+
+// encodeCompressed encodes and compresses a value for storage, recording the codec and type.
+// It also returns the encoded size before compression.
+func encodeCompressed(value interface{}, codec Codec, serializer Serializer) ([]byte, int, error) {
+	tag, payload, err := encodeTyped(value, serializer)
+	if err != nil {
+		return nil, 0, err
+	}
+	out, err := compressFramed(payload, tag, codec)
+	return out, len(payload), err
+}
+
+// compressFramed compresses an encoded payload behind the codec and type header
+func compressFramed(payload []byte, tag byte, codec Codec) ([]byte, error) {
+	body, err := codec.Compress(payload)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(valueHeaderMagic)+2+len(body))
+	out = append(out, valueHeaderMagic...)
+	out = append(out, codec.ID(), tag)
+	return append(out, body...), nil
+}
+
+// skipsCompression reports whether codec stores a payload of n bytes as-is
+// because it is under the gzip CompressionMinSize threshold
+func skipsCompression(codec Codec, n int) bool {
+	gz, ok := codec.(gzipCodec)
+	if !ok {
+		return false
+	}
+	minSize := gz.minSize
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+	return n < minSize
+}
+
+// decompressFramed reverses compressFramed, returning the type tag and encoded payload
+func decompressFramed(data interface{}) (byte, []byte, bool) {
+	dataBytes, ok := data.([]byte)
+	if !ok || len(dataBytes) < len(valueHeaderMagic)+2 || string(dataBytes[:len(valueHeaderMagic)]) != valueHeaderMagic {
+		return 0, nil, false
+	}
+	codecID, tag := dataBytes[len(valueHeaderMagic)], dataBytes[len(valueHeaderMagic)+1]
+	codec, ok := lookupCodecID(codecID)
+	if !ok {
+		return 0, nil, false
+	}
+	payload, err := codec.Decompress(dataBytes[len(valueHeaderMagic)+2:])
+	if err != nil {
+		return 0, nil, false
+	}
+	return tag, payload, true
+}
+
+// decodeCompressed restores a value stored compressed by Set, with its original type
+func decodeCompressed(data interface{}, serializer Serializer) (interface{}, bool) {
+	tag, payload, ok := decompressFramed(data)
+	if !ok {
+		return nil, false
+	}
+	value, err := decodeTyped(tag, payload, serializer)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,125 @@
+// codec_test.go: Tests for the compression codec registry
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// reverseCodec is a trivial codec that stores bytes reversed
+type reverseCodec struct{ id byte }
+
+func (c reverseCodec) Compress(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out, nil
+}
+
+func (c reverseCodec) Decompress(data []byte) ([]byte, error) { return c.Compress(data) }
+
+func (c reverseCodec) ID() byte { return c.id }
+
+func TestRegisterCodec_Validation(t *testing.T) {
+	if err := RegisterCodec("", reverseCodec{id: 200}); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+	if err := RegisterCodec("zero", reverseCodec{id: 0}); err == nil {
+		t.Error("expected an error for the reserved ID 0")
+	}
+	if err := RegisterCodec("gzip", reverseCodec{id: 201}); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if err := RegisterCodec("gzip-clash", reverseCodec{id: GzipCodecID}); err == nil {
+		t.Error("expected an error for a duplicate ID")
+	}
+}
+
+func TestCodec_EntriesReadableAcrossCodecs(t *testing.T) {
+	if _, ok := LookupCodec("reverse-test"); !ok {
+		if err := RegisterCodec("reverse-test", reverseCodec{id: 250}); err != nil {
+			t.Fatalf("RegisterCodec: %v", err)
+		}
+	}
+	reverse, ok := LookupCodec("reverse-test")
+	if !ok {
+		t.Fatal("expected the registered codec to be found")
+	}
+
+	value := strings.Repeat("abc", 50)
+	fromReverse, _, err := encodeCompressed(value, reverse, GobSerializer{})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	fromGzip, _, err := encodeCompressed(value, gzipCodec{}, GobSerializer{})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if fromReverse[2] != 250 || fromGzip[2] != GzipCodecID {
+		t.Errorf("expected the header to record the codec ID, got %d and %d", fromReverse[2], fromGzip[2])
+	}
+
+	for _, data := range [][]byte{fromReverse, fromGzip} {
+		got, ok := decodeCompressed(data, GobSerializer{})
+		if !ok || got != value {
+			t.Errorf("expected %q from codec %d, got %v, %v", value, data[2], got, ok)
+		}
+	}
+
+	// Switching the configured codec keeps decoding by the stored ID
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         10,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		CompressionCodec:  "reverse-test",
+	})
+	defer cache.Close()
+	cache.Set("key", value)
+	cache.codec = gzipCodec{}
+	if got, ok := cache.Get("key"); !ok || got != value {
+		t.Errorf("expected the entry to stay readable after a codec change, got %v, %v", got, ok)
+	}
+}
+
+func TestCodec_UnknownIDIsMiss(t *testing.T) {
+	data, _, err := encodeCompressed("value", gzipCodec{}, GobSerializer{})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	data[2] = 99
+	if _, ok := decodeCompressed(data, GobSerializer{}); ok {
+		t.Error("expected a value from an unregistered codec to be unreadable")
+	}
+}
+
+func TestGzipCodec_RoundTrip(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("short"), []byte(strings.Repeat("long payload ", 20))} {
+		compressed, err := gzipCodec{}.Compress(data)
+		if err != nil {
+			t.Fatalf("compress: %v", err)
+		}
+		out, err := gzipCodec{}.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("decompress: %v", err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("round trip mismatch: want %q, got %q", data, out)
+		}
+	}
+}
+
+func TestValidateConfig_UnknownCodec(t *testing.T) {
+	result := ValidateConfig(CacheConfig{CacheSize: 100, ShardCount: 1, CompressionCodec: "missing"})
+	if result.IsValid {
+		t.Error("expected an unregistered codec to be invalid")
+	}
+}
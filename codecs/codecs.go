@@ -0,0 +1,87 @@
+// codecs.go: zstd and snappy compression codecs for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package codecs registers the "zstd" and "snappy" compression codecs with Metis.
+// Import it for its side effects and select a codec with CacheConfig.CompressionCodec:
+//
+//	import _ "github.com/agilira/metis/codecs"
+//
+// It lives in its own module so the core metis package keeps zero
+// third-party dependencies.
+package codecs
+
+import (
+	"github.com/agilira/metis"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec IDs recorded in stored values; the built-in gzip codec uses metis.GzipCodecID
+const (
+	ZstdCodecID   byte = 2
+	SnappyCodecID byte = 3
+)
+
+// Zstd favours compression ratio; its encoder and decoder are safe for concurrent use
+type Zstd struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// NewZstd creates a zstd codec with the default compression level
+func NewZstd() (*Zstd, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Zstd{encoder: encoder, decoder: decoder}, nil
+}
+
+// Compress encodes data as a single zstd frame
+func (z *Zstd) Compress(data []byte) ([]byte, error) {
+	return z.encoder.EncodeAll(data, nil), nil
+}
+
+// Decompress decodes a zstd frame
+func (z *Zstd) Decompress(data []byte) ([]byte, error) {
+	return z.decoder.DecodeAll(data, nil)
+}
+
+// ID returns ZstdCodecID
+func (z *Zstd) ID() byte { return ZstdCodecID }
+
+// Snappy favours speed over compression ratio
+type Snappy struct{}
+
+// Compress encodes data in the snappy block format
+func (Snappy) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+// Decompress decodes a snappy block
+func (Snappy) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+// ID returns SnappyCodecID
+func (Snappy) ID() byte { return SnappyCodecID }
+
+func init() {
+	z, err := NewZstd()
+	if err != nil {
+		panic("metis/codecs: " + err.Error())
+	}
+	if err := metis.RegisterCodec("zstd", z); err != nil {
+		panic("metis/codecs: " + err.Error())
+	}
+	if err := metis.RegisterCodec("snappy", Snappy{}); err != nil {
+		panic("metis/codecs: " + err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+// codecs_test.go: Tests for the zstd and snappy codecs
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package codecs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/agilira/metis"
+)
+
+func TestCodecs_Registered(t *testing.T) {
+	for name, id := range map[string]byte{"zstd": ZstdCodecID, "snappy": SnappyCodecID} {
+		codec, ok := metis.LookupCodec(name)
+		if !ok {
+			t.Errorf("expected %s to be registered", name)
+			continue
+		}
+		if codec.ID() != id {
+			t.Errorf("%s: expected ID %d, got %d", name, id, codec.ID())
+		}
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("metis compression codec ", 200))
+	for _, name := range []string{"zstd", "snappy"} {
+		codec, _ := metis.LookupCodec(name)
+		compressed, err := codec.Compress(data)
+		if err != nil {
+			t.Fatalf("%s: compress: %v", name, err)
+		}
+		if len(compressed) >= len(data) {
+			t.Errorf("%s: expected compression, %d >= %d bytes", name, len(compressed), len(data))
+		}
+		out, err := codec.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("%s: decompress: %v", name, err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("%s: round trip mismatch", name)
+		}
+	}
+}
+
+func TestCodecs_StrategicCache(t *testing.T) {
+	for _, name := range []string{"zstd", "snappy"} {
+		t.Run(name, func(t *testing.T) {
+			cache := metis.NewStrategicCache(metis.CacheConfig{
+				EnableCaching:     true,
+				CacheSize:         100,
+				ShardCount:        1,
+				EvictionPolicy:    "lru",
+				EnableCompression: true,
+				CompressionCodec:  name,
+			})
+			defer cache.Close()
+
+			value := strings.Repeat("payload ", 100)
+			cache.Set("text", value)
+			cache.Set("number", 42)
+
+			if got, ok := cache.Get("text"); !ok || got != value {
+				t.Errorf("expected text to round trip, got %v, %v", got, ok)
+			}
+			if got, ok := cache.Get("number"); !ok || got != 42 {
+				t.Errorf("expected 42 to round trip as int, got %#v, %v", got, ok)
+			}
+			if stats := cache.GetStats(); stats.MemoryBytes >= int64(len(value)) {
+				t.Errorf("expected compressed storage below %d bytes, got %d", len(value), stats.MemoryBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/agilira/metis/codecs
+
+go 1.23.11
+
+require (
+	github.com/agilira/metis v1.0.0
+	github.com/klauspost/compress v1.17.9
+)
+
+replace github.com/agilira/metis => ../
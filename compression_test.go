@@ -8,6 +8,11 @@ package metis
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -205,24 +210,19 @@ func TestCompressionEdgeCases(t *testing.T) {
 		}
 	}()
 
-	// Test with very large header (should be truncated or error)
+	// Headers of any length up to 255 bytes round-trip exactly
 	largeHeader := "VERYLARGEHEADER"
 	data := []byte("test")
 	compressed, err := compressGzipWithHeader(data, largeHeader)
 	if err != nil {
-		t.Logf("Expected error for large header: %v", err)
-	} else {
-		// If it doesn't error, verify it handles the large header correctly
-		decompressedHeader, _, err := decompressGzipWithHeader(compressed)
-		if err != nil {
-			t.Logf("Decompression failed after large header compression: %v", err)
-		} else {
-			// The current implementation expects 4-byte headers, but writes the full header
-			// This is a bug in the implementation, but we test the current behavior
-			if len(decompressedHeader) != 4 {
-				t.Logf("Expected 4-byte header, got %d bytes (implementation bug)", len(decompressedHeader))
-			}
-		}
+		t.Fatalf("Expected no error for large header, got %v", err)
+	}
+	decompressedHeader, decompressedData, err := decompressGzipWithHeader(compressed)
+	if err != nil {
+		t.Fatalf("Decompression failed after large header compression: %v", err)
+	}
+	if decompressedHeader != largeHeader || !bytes.Equal(decompressedData, data) {
+		t.Errorf("Expected %q/%q, got %q/%q", largeHeader, data, decompressedHeader, decompressedData)
 	}
 
 	// Test with special characters in header
@@ -233,7 +233,7 @@ func TestCompressionEdgeCases(t *testing.T) {
 		t.Errorf("Expected no error for special header, got %v", err)
 	}
 
-	decompressedHeader, decompressedData, err := decompressGzipWithHeader(compressed)
+	decompressedHeader, decompressedData, err = decompressGzipWithHeader(compressed)
 	if err != nil {
 		t.Errorf("Expected no error for special header decompression, got %v", err)
 	}
@@ -417,13 +417,8 @@ func TestCompressionWithSpecialData(t *testing.T) {
 		return
 	}
 
-	// The current implementation only reads the first 4 bytes of the header
-	expectedHeader := header
-	if len(header) > 4 {
-		expectedHeader = header[:4]
-	}
-	if decompressedHeader != expectedHeader {
-		t.Errorf("Header mismatch for null data: expected %s, got %s", expectedHeader, decompressedHeader)
+	if decompressedHeader != header {
+		t.Errorf("Header mismatch for null data: expected %s, got %s", header, decompressedHeader)
 	}
 	if !bytes.Equal(decompressedData, nullData) {
 		t.Errorf("Data mismatch for null data")
@@ -444,13 +439,8 @@ func TestCompressionWithSpecialData(t *testing.T) {
 		return
 	}
 
-	// The current implementation only reads the first 4 bytes of the header
-	expectedHeader2 := header
-	if len(header) > 4 {
-		expectedHeader2 = header[:4]
-	}
-	if decompressedHeader != expectedHeader2 {
-		t.Errorf("Header mismatch for same byte data: expected %s, got %s", expectedHeader2, decompressedHeader)
+	if decompressedHeader != header {
+		t.Errorf("Header mismatch for same byte data: expected %s, got %s", header, decompressedHeader)
 	}
 	if !bytes.Equal(decompressedData, sameByteData) {
 		t.Errorf("Data mismatch for same byte data")
@@ -474,20 +464,15 @@ func TestCompressionWithSpecialData(t *testing.T) {
 
 	decompressedHeader, decompressedData, err = decompressGzipWithHeader(compressed)
 	if err != nil {
-		t.Logf("Decompression failed for alternating data: %v", err)
+		t.Errorf("Decompression failed for alternating data: %v", err)
 		return
 	}
 
-	// The current implementation only reads the first 4 bytes of the header
-	expectedHeader3 := header
-	if len(header) > 4 {
-		expectedHeader3 = header[:4]
-	}
-	if decompressedHeader != expectedHeader3 {
-		t.Logf("Header mismatch for alternating data: expected %s, got %s", expectedHeader3, decompressedHeader)
+	if decompressedHeader != header {
+		t.Errorf("Header mismatch for alternating data: expected %s, got %s", header, decompressedHeader)
 	}
 	if !bytes.Equal(decompressedData, altData) {
-		t.Logf("Data mismatch for alternating data")
+		t.Errorf("Data mismatch for alternating data")
 	}
 }
 
@@ -501,20 +486,17 @@ func TestCompression_ErrorHandling(t *testing.T) {
 	// Test compression with large data
 	compressed, err := compressGzipWithHeader(largeData, "TEST")
 	if err != nil {
-		t.Logf("Compression error (expected for large data): %v", err)
-	} else {
-		// If compression succeeds, test decompression
-		header, payload, err := decompressGzipWithHeader(compressed)
-		if err != nil {
-			t.Logf("Decompression error: %v", err)
-		} else {
-			if header != "TEST" {
-				t.Logf("Expected header 'TEST', got '%s'", header)
-			}
-			if len(payload) != len(largeData) {
-				t.Logf("Expected payload length %d, got %d", len(largeData), len(payload))
-			}
-		}
+		t.Fatalf("Compression error for large data: %v", err)
+	}
+	header, payload, err := decompressGzipWithHeader(compressed)
+	if err != nil {
+		t.Fatalf("Decompression error: %v", err)
+	}
+	if header != "TEST" {
+		t.Errorf("Expected header 'TEST', got '%s'", header)
+	}
+	if !bytes.Equal(payload, largeData) {
+		t.Errorf("Expected payload length %d to round-trip, got %d", len(largeData), len(payload))
 	}
 }
 
@@ -547,3 +529,288 @@ func TestDecompression_ErrorHandling(t *testing.T) {
 		t.Logf("Expected nil payload")
 	}
 }
+
+type compressedProfile struct {
+	Name string
+	Tags []string
+}
+
+func init() {
+	gob.Register(compressedProfile{})
+}
+
+func TestCompressedValues_RoundTripExactTypes(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	values := map[string]interface{}{
+		"nil":     nil,
+		"empty":   "",
+		"digits":  "12345", // A numeric string must stay a string
+		"bool":    "true",
+		"int":     -42,
+		"int8":    int8(-8),
+		"uint16":  uint16(65535),
+		"float32": float32(0.1),
+		"float64": 1e-300,
+		"bytes":   []byte{0, 1, 2, 255},
+		"large":   strings.Repeat("compressible ", 100),
+		"struct":  compressedProfile{Name: "alice", Tags: []string{"a", "b"}},
+		"box":     PrimitiveBox{V: int64(7)},
+	}
+	for key, value := range values {
+		if !cache.Set(key, value) {
+			t.Fatalf("Set failed for %s", key)
+		}
+	}
+
+	for key, want := range values {
+		got, ok := cache.Get(key)
+		if !ok {
+			t.Errorf("Get failed for %s", key)
+			continue
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(want) || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: want %#v (%T), got %#v (%T)", key, want, want, got, got)
+		}
+	}
+}
+
+func TestCompressedValues_StoredCompressed(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	value := strings.Repeat("compressible ", 100)
+	cache.Set("key", value)
+
+	shard := cache.getShard("key")
+	entry := shard.data["key"]
+	if entry == nil || !entry.Compressed {
+		t.Fatal("expected the entry to be stored compressed")
+	}
+	if stored := len(entry.Data.([]byte)); stored >= len(value) {
+		t.Errorf("expected compressed size below %d bytes, got %d", len(value), stored)
+	}
+	if got := cache.GetStats().MemoryBytes; got != int64(entry.Size) || entry.Size >= len(value) {
+		t.Errorf("expected memory accounting to use the compressed size %d, got %d", entry.Size, got)
+	}
+}
+
+func TestCompressionMinSize_SmallValueStoredRaw(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:      true,
+		CacheSize:          100,
+		ShardCount:         1,
+		EvictionPolicy:     "lru",
+		EnableCompression:  true,
+		CompressionMinSize: 256,
+	})
+	defer cache.Close()
+
+	value := strings.Repeat("x", 100)
+	cache.Set("key", value)
+
+	entry := cache.getShard("key").data["key"]
+	if entry == nil || !entry.Compressed {
+		t.Fatal("expected the entry to carry the codec header")
+	}
+	body := entry.Data.([]byte)[len(valueHeaderMagic)+2:]
+	if string(body) != value {
+		t.Errorf("expected a 100-byte value to be stored uncompressed below MinSize=256, got %d body bytes", len(body))
+	}
+	if got, ok := cache.Get("key"); !ok || got != value {
+		t.Errorf("expected the raw value back, got %v, %v", got, ok)
+	}
+}
+
+func TestCompressionLevel_ChangesOutputSize(t *testing.T) {
+	var payload bytes.Buffer
+	for i := 0; payload.Len() < 1<<20; i++ {
+		fmt.Fprintf(&payload, "record-%d:%s;", i%1000, strings.Repeat("ab", i%7))
+	}
+	data := payload.Bytes()
+
+	fast, err := gzipCodec{level: gzip.BestSpeed}.Compress(data)
+	if err != nil {
+		t.Fatalf("BestSpeed: %v", err)
+	}
+	best, err := gzipCodec{level: gzip.BestCompression}.Compress(data)
+	if err != nil {
+		t.Fatalf("BestCompression: %v", err)
+	}
+	if len(fast) == len(best) {
+		t.Errorf("expected BestSpeed and BestCompression to differ, both %d bytes", len(fast))
+	}
+	if len(best) > len(fast) {
+		t.Errorf("expected BestCompression (%d bytes) to be no larger than BestSpeed (%d bytes)", len(best), len(fast))
+	}
+
+	for _, level := range []int{gzip.BestSpeed, gzip.BestCompression} {
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:     true,
+			CacheSize:         10,
+			ShardCount:        1,
+			EvictionPolicy:    "lru",
+			EnableCompression: true,
+			CompressionLevel:  level,
+		})
+		cache.Set("key", string(data))
+		if got, ok := cache.Get("key"); !ok || got != string(data) {
+			t.Errorf("level %d: expected the payload back", level)
+		}
+		cache.Close()
+	}
+}
+
+func TestCompressionSettings_InvalidFallBackToDefaults(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:      true,
+		EvictionPolicy:     "lru",
+		EnableCompression:  true,
+		CompressionMinSize: -1,
+		CompressionLevel:   42,
+	})
+	defer cache.Close()
+
+	if cache.config.CompressionMinSize != DefaultCompressionMinSize || cache.config.CompressionLevel != DefaultCompressionLevel {
+		t.Errorf("expected defaults, got MinSize=%d Level=%d", cache.config.CompressionMinSize, cache.config.CompressionLevel)
+	}
+	if result := ValidateConfig(CacheConfig{CacheSize: 100, ShardCount: 1, CompressionLevel: 42}); result.IsValid {
+		t.Error("expected an out-of-range CompressionLevel to be invalid")
+	}
+}
+
+func TestCompressionStats_TrackStoredEntries(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         2,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	large := strings.Repeat("compressible ", 100)
+	cache.Set("a", large)
+	cache.Set("small", "tiny")
+
+	stats := cache.CompressionStats()
+	if stats.CompressedEntries != 1 || stats.SkippedEntries != 1 {
+		t.Fatalf("expected one compressed and one skipped entry, got %+v", stats)
+	}
+	if stats.UncompressedBytes != int64(len(large)) {
+		t.Errorf("expected %d bytes before compression, got %d", len(large), stats.UncompressedBytes)
+	}
+	if stats.CompressedBytes != int64(cache.getShard("a").data["a"].Size) || stats.Ratio() >= 1 {
+		t.Errorf("expected the stored size to be tracked with a ratio below 1, got %+v (ratio %.2f)", stats, stats.Ratio())
+	}
+
+	// Overwriting a compressed entry with a small value moves it to skipped
+	cache.Set("a", "tiny")
+	if stats := cache.CompressionStats(); stats.CompressedEntries != 0 || stats.SkippedEntries != 2 || stats.CompressedBytes != 0 || stats.UncompressedBytes != 0 {
+		t.Errorf("expected the overwrite to replace the compressed entry, got %+v", stats)
+	}
+
+	// Eviction and Delete release their entries' contribution
+	cache.Set("b", large)
+	if stats := cache.CompressionStats(); stats.CompressedEntries != 1 || stats.SkippedEntries != 1 {
+		t.Errorf("expected the evicted entry to be untracked, got %+v", stats)
+	}
+	cache.Delete("b")
+	if stats := cache.CompressionStats(); stats.CompressedEntries != 0 || stats.CompressedBytes != 0 || stats.UncompressedBytes != 0 {
+		t.Errorf("expected Delete to untrack the entry, got %+v", stats)
+	}
+	cache.Clear()
+	if stats := cache.CompressionStats(); stats != (CompressionStats{}) {
+		t.Errorf("expected Clear to reset compression stats, got %+v", stats)
+	}
+}
+
+func TestCompressionStats_InExpvar(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	cache.Set("key", strings.Repeat("compressible ", 100))
+	snapshot := cache.expvarSnapshot()
+	if snapshot.Compression.CompressedEntries != 1 || snapshot.Compression != cache.CompressionStats() {
+		t.Errorf("expected compression stats in the expvar snapshot, got %+v", snapshot.Compression)
+	}
+}
+
+// headerEnd returns the offset of the payload written by compressGzipWithHeader
+func headerEnd(header string) int {
+	return len(headerMagic) + 2 + len(header)
+}
+
+func TestCompressGzipWithHeader_VariableLengthRoundTrip(t *testing.T) {
+	payloads := [][]byte{nil, []byte("short"), bytes.Repeat([]byte("compressible "), 100)}
+	headers := []string{"", "A", "TEST", "FIVE5", strings.Repeat("h", 100), strings.Repeat("x", maxHeaderLength)}
+
+	for _, header := range headers {
+		for _, payload := range payloads {
+			compressed, err := compressGzipWithHeader(payload, header)
+			if err != nil {
+				t.Fatalf("header of %d bytes: %v", len(header), err)
+			}
+			gotHeader, gotPayload, err := decompressGzipWithHeader(compressed)
+			if err != nil {
+				t.Fatalf("header of %d bytes: %v", len(header), err)
+			}
+			if gotHeader != header || !bytes.Equal(gotPayload, payload) {
+				t.Errorf("expected exact round trip for a %d-byte header and %d-byte payload, got %q and %d bytes",
+					len(header), len(payload), gotHeader, len(gotPayload))
+			}
+		}
+	}
+
+	if _, err := compressGzipWithHeader([]byte("data"), strings.Repeat("x", maxHeaderLength+1)); err == nil {
+		t.Error("expected an error for a header longer than 255 bytes")
+	}
+}
+
+func TestDecompressGzipWithHeader_LegacyFormat(t *testing.T) {
+	payload := bytes.Repeat([]byte("legacy payload "), 20)
+
+	var buf bytes.Buffer
+	buf.WriteString("OLD1")
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, legacy := range [][]byte{buf.Bytes(), []byte("OLD2raw")} {
+		header, got, err := decompressGzipWithHeader(legacy)
+		if err != nil {
+			t.Fatalf("expected legacy data to stay readable: %v", err)
+		}
+		if header != string(legacy[:4]) || !bytes.Equal(got, legacy[4:]) && !bytes.Equal(got, payload) {
+			t.Errorf("unexpected legacy decode: %q, %q", header, got)
+		}
+	}
+
+	// A truncated length-prefixed header is an error, not a legacy header
+	truncated := append([]byte(headerMagic), headerVersion, 10, 'a')
+	if _, _, err := decompressGzipWithHeader(truncated); err == nil {
+		t.Error("expected an error for a truncated header")
+	}
+}
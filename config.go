@@ -7,7 +7,6 @@
 package metis
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,18 +15,60 @@ import (
 	"time"
 )
 
-// SimpleConfig represents the complete configuration from metis.json
+// SimpleConfig represents the complete configuration from metis.json, metis.yaml or metis.toml.
+// It is the single schema shared by the config loader and metis-cli.
 type SimpleConfig struct {
-	CacheSize         int    `json:"cache_size"`
-	TTL               string `json:"ttl"`
-	CleanupInterval   string `json:"cleanup_interval"`
-	EnableCompression bool   `json:"enable_compression"`
-	EvictionPolicy    string `json:"eviction_policy"`
-	ShardCount        int    `json:"shard_count"`
-	AdmissionPolicy   string `json:"admission_policy"`
-	MaxKeySize        int    `json:"max_key_size"`
-	MaxValueSize      int    `json:"max_value_size"`
-	MaxShardSize      int    `json:"max_shard_size"`
+	CacheSize            int     `json:"cache_size"`
+	TTL                  string  `json:"ttl,omitempty"`
+	CleanupInterval      string  `json:"cleanup_interval,omitempty"`
+	EnableCompression    bool    `json:"enable_compression,omitempty"`
+	CompressionCodec     string  `json:"compression_codec,omitempty"`
+	CompressionMinSize   int     `json:"compression_min_size,omitempty"`
+	CompressionLevel     int     `json:"compression_level,omitempty"`
+	DisableChecksums     bool    `json:"disable_checksums,omitempty"`
+	ChunkThreshold       int     `json:"chunk_threshold,omitempty"`
+	EvictionPolicy       string  `json:"eviction_policy,omitempty"`
+	ShardCount           int     `json:"shard_count,omitempty"`
+	AdmissionPolicy      string  `json:"admission_policy,omitempty"`
+	KeyHasher            string  `json:"key_hasher,omitempty"`
+	AdmissionProbability float64 `json:"admission_probability,omitempty"`
+	MaxKeySize           int     `json:"max_key_size,omitempty"`
+	MaxValueSize         int     `json:"max_value_size,omitempty"`
+	MaxShardSize         int     `json:"max_shard_size,omitempty"`
+	MaxMemoryBytes       int64   `json:"max_memory_bytes,omitempty"`
+	WindowRatio          float64 `json:"window_ratio,omitempty"`
+	ProbationRatio       float64 `json:"probation_ratio,omitempty"`
+	AdaptiveWindow       bool    `json:"adaptive_window,omitempty"`
+	CopyOnRead           bool    `json:"copy_on_read,omitempty"`
+	SlidingTTL           bool    `json:"sliding_ttl,omitempty"`
+	MaxIdleTime          string  `json:"max_idle_time,omitempty"`
+	SketchDepth          int     `json:"sketch_depth,omitempty"`
+	SketchWidth          int     `json:"sketch_width,omitempty"`
+	SizeAwareMaxSize     int     `json:"size_aware_max_size,omitempty"`
+	SizeAwareUtilization float64 `json:"size_aware_utilization,omitempty"`
+	SnapshotPath         string  `json:"snapshot_path,omitempty"`
+	SnapshotInterval     string  `json:"snapshot_interval,omitempty"`
+
+	WriteBehind              bool   `json:"write_behind,omitempty"`
+	WriteBehindBufferSize    int    `json:"write_behind_buffer_size,omitempty"`
+	WriteBehindBatchSize     int    `json:"write_behind_batch_size,omitempty"`
+	WriteBehindFlushInterval string `json:"write_behind_flush_interval,omitempty"`
+	WriteBehindWorkers       int    `json:"write_behind_workers,omitempty"`
+	WriteBehindRetries       int    `json:"write_behind_retries,omitempty"`
+	WriteBehindRetryBackoff  string `json:"write_behind_retry_backoff,omitempty"`
+
+	BreakerThreshold      int    `json:"breaker_threshold,omitempty"`
+	BreakerOpenDuration   string `json:"breaker_open_duration,omitempty"`
+	BreakerHalfOpenProbes int    `json:"breaker_half_open_probes,omitempty"`
+
+	DisableBackgroundCleanup bool `json:"disable_background_cleanup,omitempty"`
+	LazyCleanupBatch         int  `json:"lazy_cleanup_batch,omitempty"`
+
+	StatsWindow        string `json:"stats_window,omitempty"`
+	StatsWindowBuckets int    `json:"stats_window_buckets,omitempty"`
+
+	EnableLatencyTracking bool `json:"enable_latency_tracking,omitempty"`
+	TrackHotKeys          int  `json:"track_hot_keys,omitempty"`
 }
 
 // Global configuration state
@@ -51,8 +92,16 @@ func GetGlobalConfig() *CacheConfig {
 	return globalConfig
 }
 
-// loadConfig loads configuration with priority: Go config > JSON config > defaults
+// loadConfig loads configuration with priority: Go config > JSON config > defaults,
+// then applies $METIS_* environment overrides, skipping malformed values
 func loadConfig() CacheConfig {
+	config := loadBaseConfig()
+	_ = config.ApplyEnv(EnvPrefix) // New has no error path; NewE reports malformed values
+	return config
+}
+
+// loadBaseConfig loads configuration with priority: Go config > JSON config > defaults
+func loadBaseConfig() CacheConfig {
 	// Check if power user has set global config via Go file
 	if config := GetGlobalConfig(); config != nil {
 		return *config
@@ -67,24 +116,60 @@ func loadConfig() CacheConfig {
 	return getDefaultConfig()
 }
 
-// loadJSONConfig loads configuration from metis.json
+// ConfigEnvVar names the environment variable holding an explicit config file path.
+// When set, it takes precedence over searching for metis.json.
+const ConfigEnvVar = "METIS_CONFIG"
+
+// loadJSONConfig loads configuration from $METIS_CONFIG or metis.json
 func loadJSONConfig() (CacheConfig, error) {
-	configPath := findConfigFile()
+	configPath, err := configFilePath()
+	if err != nil {
+		return CacheConfig{}, err
+	}
 	if configPath == "" {
 		return CacheConfig{}, fmt.Errorf("metis.json not found")
 	}
+	return parseConfigFile(configPath)
+}
 
-	if filepath.Base(configPath) != "metis.json" || strings.Contains(configPath, "..") {
-		return CacheConfig{}, fmt.Errorf("invalid config file path: %s", configPath)
+// configFilePath returns the config file to load: $METIS_CONFIG when set, otherwise the
+// nearest metis.json, or "" when there is none
+func configFilePath() (string, error) {
+	if envPath := os.Getenv(ConfigEnvVar); envPath != "" {
+		return filepath.Clean(envPath), nil
 	}
-	// nosec G304 - configPath is validated above to prevent path traversal
+
+	configPath := findConfigFile()
+	if configPath == "" {
+		return "", nil
+	}
+	if !isConfigFileName(filepath.Base(configPath)) || strings.Contains(configPath, "..") {
+		return "", fmt.Errorf("invalid config file path: %s", configPath)
+	}
+	return configPath, nil
+}
+
+// isConfigFileName reports whether name is one of the files New searches for
+func isConfigFileName(name string) bool {
+	for _, candidate := range configFileNames {
+		if name == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// parseConfigFile reads a config file in the format given by its extension
+// and maps it onto the default configuration
+func parseConfigFile(configPath string) (CacheConfig, error) {
+	// nosec G304 - configPath is either a validated metis.json or supplied by the application
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return CacheConfig{}, fmt.Errorf("failed to read %s: %v", configPath, err)
 	}
 
 	var simpleConfig SimpleConfig
-	if err := json.Unmarshal(data, &simpleConfig); err != nil {
+	if err := UnmarshalConfig(data, ConfigFormatFromPath(configPath), &simpleConfig); err != nil {
 		return CacheConfig{}, fmt.Errorf("failed to parse %s: %v", configPath, err)
 	}
 
@@ -112,8 +197,95 @@ func loadJSONConfig() (CacheConfig, error) {
 		}
 	}
 
+	if simpleConfig.MaxIdleTime != "" {
+		if maxIdle, err := time.ParseDuration(simpleConfig.MaxIdleTime); err == nil {
+			config.MaxIdleTime = maxIdle
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid max_idle_time format in %s: %v", configPath, err)
+		}
+	}
+
+	if simpleConfig.StatsWindow != "" {
+		if statsWindow, err := time.ParseDuration(simpleConfig.StatsWindow); err == nil {
+			config.StatsWindow = statsWindow
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid stats_window format in %s: %v", configPath, err)
+		}
+	}
+
+	if simpleConfig.SnapshotInterval != "" {
+		if snapshotInterval, err := time.ParseDuration(simpleConfig.SnapshotInterval); err == nil {
+			config.SnapshotInterval = snapshotInterval
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid snapshot_interval format in %s: %v", configPath, err)
+		}
+	}
+
+	if simpleConfig.WriteBehindFlushInterval != "" {
+		if interval, err := time.ParseDuration(simpleConfig.WriteBehindFlushInterval); err == nil {
+			config.WriteBehindFlushInterval = interval
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid write_behind_flush_interval format in %s: %v", configPath, err)
+		}
+	}
+
+	if simpleConfig.WriteBehindRetryBackoff != "" {
+		if backoff, err := time.ParseDuration(simpleConfig.WriteBehindRetryBackoff); err == nil {
+			config.WriteBehindRetryBackoff = backoff
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid write_behind_retry_backoff format in %s: %v", configPath, err)
+		}
+	}
+
+	if simpleConfig.BreakerOpenDuration != "" {
+		if openFor, err := time.ParseDuration(simpleConfig.BreakerOpenDuration); err == nil {
+			config.BreakerOpenDuration = openFor
+		} else {
+			return CacheConfig{}, fmt.Errorf("invalid breaker_open_duration format in %s: %v", configPath, err)
+		}
+	}
+
 	// Apply boolean and string configurations
 	config.EnableCompression = simpleConfig.EnableCompression
+	config.DisableChecksums = simpleConfig.DisableChecksums
+	config.AdaptiveWindow = simpleConfig.AdaptiveWindow
+	config.CopyOnRead = simpleConfig.CopyOnRead
+	config.SlidingTTL = simpleConfig.SlidingTTL
+	config.DisableBackgroundCleanup = simpleConfig.DisableBackgroundCleanup
+	config.EnableLatencyTracking = simpleConfig.EnableLatencyTracking
+	config.WriteBehind = simpleConfig.WriteBehind
+
+	if simpleConfig.CompressionCodec != "" {
+		config.CompressionCodec = simpleConfig.CompressionCodec
+	}
+
+	if simpleConfig.SnapshotPath != "" {
+		config.SnapshotPath = simpleConfig.SnapshotPath
+	}
+
+	if simpleConfig.CompressionMinSize > 0 {
+		config.CompressionMinSize = simpleConfig.CompressionMinSize
+	}
+
+	if simpleConfig.LazyCleanupBatch > 0 {
+		config.LazyCleanupBatch = simpleConfig.LazyCleanupBatch
+	}
+
+	if simpleConfig.StatsWindowBuckets > 0 {
+		config.StatsWindowBuckets = simpleConfig.StatsWindowBuckets
+	}
+
+	if simpleConfig.TrackHotKeys > 0 {
+		config.TrackHotKeys = simpleConfig.TrackHotKeys
+	}
+
+	if simpleConfig.ChunkThreshold > 0 {
+		config.ChunkThreshold = simpleConfig.ChunkThreshold
+	}
+
+	if simpleConfig.CompressionLevel != 0 {
+		config.CompressionLevel = simpleConfig.CompressionLevel
+	}
 
 	if simpleConfig.EvictionPolicy != "" {
 		config.EvictionPolicy = simpleConfig.EvictionPolicy
@@ -127,6 +299,14 @@ func loadJSONConfig() (CacheConfig, error) {
 		config.AdmissionPolicy = simpleConfig.AdmissionPolicy
 	}
 
+	if simpleConfig.KeyHasher != "" {
+		config.KeyHasher = simpleConfig.KeyHasher
+	}
+
+	if simpleConfig.AdmissionProbability > 0 {
+		config.AdmissionProbability = simpleConfig.AdmissionProbability
+	}
+
 	if simpleConfig.MaxKeySize > 0 {
 		config.MaxKeySize = simpleConfig.MaxKeySize
 	}
@@ -139,10 +319,63 @@ func loadJSONConfig() (CacheConfig, error) {
 		config.MaxShardSize = simpleConfig.MaxShardSize
 	}
 
+	if simpleConfig.MaxMemoryBytes > 0 {
+		config.MaxMemoryBytes = simpleConfig.MaxMemoryBytes
+	}
+
+	if simpleConfig.WindowRatio > 0 {
+		config.WindowRatio = simpleConfig.WindowRatio
+	}
+
+	if simpleConfig.ProbationRatio > 0 {
+		config.ProbationRatio = simpleConfig.ProbationRatio
+	}
+
+	if simpleConfig.SketchDepth > 0 {
+		config.SketchDepth = simpleConfig.SketchDepth
+	}
+
+	if simpleConfig.SketchWidth > 0 {
+		config.SketchWidth = simpleConfig.SketchWidth
+	}
+
+	if simpleConfig.SizeAwareMaxSize > 0 {
+		config.SizeAwareMaxSize = simpleConfig.SizeAwareMaxSize
+	}
+
+	if simpleConfig.SizeAwareUtilization > 0 {
+		config.SizeAwareUtilization = simpleConfig.SizeAwareUtilization
+	}
+
+	if simpleConfig.WriteBehindBufferSize > 0 {
+		config.WriteBehindBufferSize = simpleConfig.WriteBehindBufferSize
+	}
+
+	if simpleConfig.WriteBehindBatchSize > 0 {
+		config.WriteBehindBatchSize = simpleConfig.WriteBehindBatchSize
+	}
+
+	if simpleConfig.WriteBehindWorkers > 0 {
+		config.WriteBehindWorkers = simpleConfig.WriteBehindWorkers
+	}
+
+	if simpleConfig.WriteBehindRetries != 0 {
+		config.WriteBehindRetries = simpleConfig.WriteBehindRetries
+	}
+
+	if simpleConfig.BreakerThreshold > 0 {
+		config.BreakerThreshold = simpleConfig.BreakerThreshold
+	}
+
+	if simpleConfig.BreakerHalfOpenProbes > 0 {
+		config.BreakerHalfOpenProbes = simpleConfig.BreakerHalfOpenProbes
+	}
+
 	return config, nil
 }
 
-// findConfigFile searches for metis.json in current and parent directories
+// findConfigFile searches for metis.json, metis.yaml, metis.yml or metis.toml
+// in current and parent directories
 func findConfigFile() string {
 	// Start from current directory
 	dir, err := os.Getwd()
@@ -152,9 +385,11 @@ func findConfigFile() string {
 
 	// Search up to 5 parent directories
 	for i := 0; i < 5; i++ {
-		configPath := filepath.Join(dir, "metis.json")
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath
+		for _, name := range configFileNames {
+			configPath := filepath.Join(dir, name)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath
+			}
 		}
 
 		// Move to parent directory
@@ -185,6 +420,72 @@ func getDefaultConfig() CacheConfig {
 	}
 }
 
+// LoadConfigFile loads a JSON, YAML or TOML config file, detected from the extension,
+// merged over the defaults and validated with CacheConfig.Validate
+func LoadConfigFile(path string) (CacheConfig, error) {
+	config, err := parseConfigFile(filepath.Clean(path))
+	if err != nil {
+		return CacheConfig{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return CacheConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// NewFromFile creates a cache from a JSON, YAML or TOML config file at path, with $METIS_*
+// environment overrides applied on top. Malformed files, malformed environment values
+// and invalid settings are reported as errors.
+func NewFromFile(path string) (*StrategicCache, error) {
+	config, err := parseConfigFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ApplyEnv(EnvPrefix); err != nil {
+		return nil, err
+	}
+	return newFromFileConfig(path, config)
+}
+
+// NewE creates a cache with the same configuration sources as New
+// (Go config > $METIS_CONFIG or metis.json > defaults, then $METIS_* overrides), but returns
+// the StrategicCache and reports malformed or invalid configuration instead of ignoring it.
+func NewE() (*StrategicCache, error) {
+	config := getDefaultConfig()
+	configPath := ""
+	if global := GetGlobalConfig(); global != nil {
+		config = *global
+	} else {
+		path, err := configFilePath()
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			if config, err = parseConfigFile(path); err != nil {
+				return nil, err
+			}
+			configPath = path
+		}
+	}
+
+	if err := config.ApplyEnv(EnvPrefix); err != nil {
+		return nil, err
+	}
+	if configPath == "" {
+		return NewStrategicCacheE(config)
+	}
+	return newFromFileConfig(configPath, config)
+}
+
+// newFromFileConfig validates a configuration loaded from path and creates the cache
+func newFromFileConfig(path string, config CacheConfig) (*StrategicCache, error) {
+	cache, err := NewStrategicCacheE(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cache, nil
+}
+
 // LoadConfig loads the current configuration (for debugging/inspection)
 func LoadConfig() CacheConfig {
 	return loadConfig()
@@ -196,8 +497,12 @@ func GetConfigSource() string {
 		return "Go configuration (metis_config.go)"
 	}
 
-	if findConfigFile() != "" {
-		return "JSON configuration (metis.json)"
+	if envPath := os.Getenv(ConfigEnvVar); envPath != "" {
+		return strings.ToUpper(ConfigFormatFromPath(envPath)) + " configuration ($" + ConfigEnvVar + ")"
+	}
+
+	if configPath := findConfigFile(); configPath != "" {
+		return strings.ToUpper(ConfigFormatFromPath(configPath)) + " configuration (" + filepath.Base(configPath) + ")"
 	}
 
 	return "Default configuration"
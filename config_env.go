@@ -0,0 +1,139 @@
+// config_env.go: Environment-variable overrides for Metis configuration
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix New, NewE and NewFromFile use for environment overrides,
+// e.g. METIS_CACHE_SIZE
+const EnvPrefix = "METIS"
+
+// ApplyEnv overrides fields from environment variables named prefix + "_" + the field's
+// metis.json key in upper case, e.g. METIS_CACHE_SIZE, METIS_TTL, METIS_EVICTION_POLICY,
+// METIS_SHARD_COUNT and METIS_ENABLE_COMPRESSION. Unset or empty variables are ignored.
+// Malformed values leave their field unchanged and are reported together; every error
+// wraps ErrInvalidConfig.
+func (c *CacheConfig) ApplyEnv(prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "_")
+	var errs []error
+
+	lookup := func(key string) (string, string, bool) {
+		name := prefix + "_" + key
+		value := strings.TrimSpace(os.Getenv(name))
+		return name, value, value != ""
+	}
+	invalid := func(name, value, kind string, err error) {
+		errs = append(errs, fmt.Errorf("%w: %s=%q is not a valid %s: %v", ErrInvalidConfig, name, value, kind, err))
+	}
+
+	setInt := func(key string, field *int) {
+		if name, value, ok := lookup(key); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				invalid(name, value, "integer", err)
+				return
+			}
+			*field = n
+		}
+	}
+	setInt64 := func(key string, field *int64) {
+		if name, value, ok := lookup(key); ok {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				invalid(name, value, "integer", err)
+				return
+			}
+			*field = n
+		}
+	}
+	setFloat := func(key string, field *float64) {
+		if name, value, ok := lookup(key); ok {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				invalid(name, value, "number", err)
+				return
+			}
+			*field = f
+		}
+	}
+	setBool := func(key string, field *bool) {
+		if name, value, ok := lookup(key); ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				invalid(name, value, "boolean", err)
+				return
+			}
+			*field = b
+		}
+	}
+	setDuration := func(key string, field *time.Duration) {
+		if name, value, ok := lookup(key); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				invalid(name, value, "duration", err)
+				return
+			}
+			*field = d
+		}
+	}
+	setString := func(key string, field *string) {
+		if _, value, ok := lookup(key); ok {
+			*field = value
+		}
+	}
+
+	setInt("CACHE_SIZE", &c.CacheSize)
+	setDuration("TTL", &c.TTL)
+	setDuration("CLEANUP_INTERVAL", &c.CleanupInterval)
+	setString("EVICTION_POLICY", &c.EvictionPolicy)
+	setString("ADMISSION_POLICY", &c.AdmissionPolicy)
+	setString("KEY_HASHER", &c.KeyHasher)
+	setInt("SHARD_COUNT", &c.ShardCount)
+	setBool("ENABLE_COMPRESSION", &c.EnableCompression)
+	setString("COMPRESSION_CODEC", &c.CompressionCodec)
+	setInt("COMPRESSION_MIN_SIZE", &c.CompressionMinSize)
+	setInt("COMPRESSION_LEVEL", &c.CompressionLevel)
+	setBool("DISABLE_CHECKSUMS", &c.DisableChecksums)
+	setInt("CHUNK_THRESHOLD", &c.ChunkThreshold)
+	setInt("MAX_KEY_SIZE", &c.MaxKeySize)
+	setInt("MAX_VALUE_SIZE", &c.MaxValueSize)
+	setInt("MAX_SHARD_SIZE", &c.MaxShardSize)
+	setInt64("MAX_MEMORY_BYTES", &c.MaxMemoryBytes)
+	setFloat("WINDOW_RATIO", &c.WindowRatio)
+	setFloat("PROBATION_RATIO", &c.ProbationRatio)
+	setBool("ADAPTIVE_WINDOW", &c.AdaptiveWindow)
+	setBool("COPY_ON_READ", &c.CopyOnRead)
+	setBool("SLIDING_TTL", &c.SlidingTTL)
+	setDuration("MAX_IDLE_TIME", &c.MaxIdleTime)
+	setBool("DISABLE_BACKGROUND_CLEANUP", &c.DisableBackgroundCleanup)
+	setInt("LAZY_CLEANUP_BATCH", &c.LazyCleanupBatch)
+	setDuration("STATS_WINDOW", &c.StatsWindow)
+	setInt("STATS_WINDOW_BUCKETS", &c.StatsWindowBuckets)
+	setBool("ENABLE_LATENCY_TRACKING", &c.EnableLatencyTracking)
+	setInt("TRACK_HOT_KEYS", &c.TrackHotKeys)
+	setString("SNAPSHOT_PATH", &c.SnapshotPath)
+	setDuration("SNAPSHOT_INTERVAL", &c.SnapshotInterval)
+	setBool("WRITE_BEHIND", &c.WriteBehind)
+	setInt("WRITE_BEHIND_BUFFER_SIZE", &c.WriteBehindBufferSize)
+	setInt("WRITE_BEHIND_BATCH_SIZE", &c.WriteBehindBatchSize)
+	setDuration("WRITE_BEHIND_FLUSH_INTERVAL", &c.WriteBehindFlushInterval)
+	setInt("WRITE_BEHIND_WORKERS", &c.WriteBehindWorkers)
+	setInt("WRITE_BEHIND_RETRIES", &c.WriteBehindRetries)
+	setDuration("WRITE_BEHIND_RETRY_BACKOFF", &c.WriteBehindRetryBackoff)
+	setInt("BREAKER_THRESHOLD", &c.BreakerThreshold)
+	setDuration("BREAKER_OPEN_DURATION", &c.BreakerOpenDuration)
+	setInt("BREAKER_HALF_OPEN_PROBES", &c.BreakerHalfOpenProbes)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,130 @@
+// config_env_test.go: Tests for environment-variable configuration overrides
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestApplyEnv_Overrides tests that each supported variable reaches its field
+func TestApplyEnv_Overrides(t *testing.T) {
+	t.Setenv("APP_CACHE_SIZE", "2500")
+	t.Setenv("APP_TTL", "90s")
+	t.Setenv("APP_EVICTION_POLICY", "arc")
+	t.Setenv("APP_SHARD_COUNT", "8")
+	t.Setenv("APP_ENABLE_COMPRESSION", "true")
+	t.Setenv("APP_MAX_MEMORY_BYTES", "1048576")
+	t.Setenv("APP_WINDOW_RATIO", "0.05")
+
+	config := DevelopmentConfig()
+	if err := config.ApplyEnv("APP"); err != nil {
+		t.Fatalf("ApplyEnv failed: %v", err)
+	}
+
+	if config.CacheSize != 2500 || config.TTL != 90*time.Second || config.EvictionPolicy != "arc" ||
+		config.ShardCount != 8 || !config.EnableCompression || config.MaxMemoryBytes != 1048576 || config.WindowRatio != 0.05 {
+		t.Errorf("overrides not applied: %+v", config)
+	}
+	// Fields without a variable are left alone
+	if config.AdmissionPolicy != "always" {
+		t.Errorf("expected AdmissionPolicy to be unchanged, got %q", config.AdmissionPolicy)
+	}
+}
+
+// TestApplyEnv_PrefixUnderscore tests that a trailing underscore in the prefix is optional
+func TestApplyEnv_PrefixUnderscore(t *testing.T) {
+	t.Setenv("APP_CACHE_SIZE", "42")
+
+	config := CacheConfig{}
+	if err := config.ApplyEnv("APP_"); err != nil || config.CacheSize != 42 {
+		t.Errorf("expected CacheSize 42, got %d (err %v)", config.CacheSize, err)
+	}
+}
+
+// TestApplyEnv_Malformed tests that malformed values are reported and skipped
+func TestApplyEnv_Malformed(t *testing.T) {
+	t.Setenv("APP_CACHE_SIZE", "lots")
+	t.Setenv("APP_TTL", "5 minutes")
+	t.Setenv("APP_ENABLE_COMPRESSION", "sometimes")
+	t.Setenv("APP_SHARD_COUNT", "16")
+
+	config := DevelopmentConfig()
+	err := config.ApplyEnv("APP")
+	if err == nil {
+		t.Fatal("expected an error for malformed values")
+	}
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected the error to wrap ErrInvalidConfig, got %v", err)
+	}
+	for _, name := range []string{"APP_CACHE_SIZE", "APP_TTL", "APP_ENABLE_COMPRESSION"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected the error to name %s, got %v", name, err)
+		}
+	}
+
+	// Well-formed values still apply; malformed ones leave the field unchanged
+	if config.ShardCount != 16 || config.CacheSize != 1000 || config.TTL != 10*time.Minute {
+		t.Errorf("unexpected config after partial override: %+v", config)
+	}
+}
+
+// TestNewE_EnvOverridesFile tests that environment overrides win over metis.json
+func TestNewE_EnvOverridesFile(t *testing.T) {
+	configMutex.Lock()
+	originalGlobalConfig := globalConfig
+	globalConfig = nil
+	configMutex.Unlock()
+	defer func() {
+		configMutex.Lock()
+		globalConfig = originalGlobalConfig
+		configMutex.Unlock()
+	}()
+
+	path := filepath.Join(t.TempDir(), "metis.json")
+	if err := os.WriteFile(path, []byte(`{"cache_size": 5000, "ttl": "1h", "shard_count": 16}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv(ConfigEnvVar, path)
+	t.Setenv("METIS_CACHE_SIZE", "7000")
+	t.Setenv("METIS_TTL", "5m")
+
+	cache, err := NewE()
+	if err != nil {
+		t.Fatalf("NewE failed: %v", err)
+	}
+	if cache.config.CacheSize != 7000 || cache.config.TTL != 5*time.Minute || cache.config.ShardCount != 16 {
+		t.Errorf("expected env to override the file, got %+v", cache.config)
+	}
+	cache.Close()
+
+	cache, err = NewFromFile(path)
+	if err != nil {
+		t.Fatalf("NewFromFile failed: %v", err)
+	}
+	if cache.config.CacheSize != 7000 {
+		t.Errorf("expected NewFromFile to apply env overrides, got %d", cache.config.CacheSize)
+	}
+	cache.Close()
+
+	if config := LoadConfig(); config.CacheSize != 7000 || config.TTL != 5*time.Minute {
+		t.Errorf("expected New's configuration to apply env overrides, got %+v", config)
+	}
+
+	// New ignores a malformed override; NewE reports it
+	t.Setenv("METIS_TTL", "later")
+	if config := LoadConfig(); config.TTL != time.Hour || config.CacheSize != 7000 {
+		t.Errorf("expected the malformed TTL to be skipped, got %+v", config)
+	}
+	if _, err := NewE(); err == nil || !strings.Contains(err.Error(), "METIS_TTL") {
+		t.Errorf("expected NewE to report METIS_TTL, got %v", err)
+	}
+}
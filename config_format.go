@@ -0,0 +1,209 @@
+// config_format.go: JSON, YAML and TOML config file formats for Metis
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Config file formats, selected from the file extension by ConfigFormatFromPath
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// configFileNames are the files searched for by New, in order of preference
+var configFileNames = []string{"metis.json", "metis.yaml", "metis.yml", "metis.toml"}
+
+// ConfigFormatFromPath returns the format of a config file from its extension:
+// .yaml and .yml are YAML, .toml is TOML and anything else is JSON
+func ConfigFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// MarshalConfig encodes config in the given format, omitting unset optional keys.
+// YAML and TOML files use the same keys as metis.json and hold only top-level scalars.
+func MarshalConfig(config SimpleConfig, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(config, "", "  ")
+	case FormatYAML, FormatTOML:
+	default:
+		return nil, fmt.Errorf("unsupported config format %q (want %q, %q or %q)", format, FormatJSON, FormatYAML, FormatTOML)
+	}
+
+	separator := ": "
+	if format == FormatTOML {
+		separator = " = "
+	}
+
+	var buf bytes.Buffer
+	v := reflect.ValueOf(config)
+	for i := 0; i < v.NumField(); i++ {
+		name, omitEmpty := jsonFieldName(v.Type().Field(i))
+		field := v.Field(i)
+		if name == "" || omitEmpty && field.IsZero() {
+			continue
+		}
+
+		buf.WriteString(name)
+		buf.WriteString(separator)
+		switch field.Kind() {
+		case reflect.String:
+			buf.WriteString(strconv.Quote(field.String()))
+		case reflect.Float64:
+			buf.WriteString(strconv.FormatFloat(field.Float(), 'f', -1, 64))
+		default:
+			fmt.Fprint(&buf, field.Interface())
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalConfig decodes a config file in the given format into config
+func UnmarshalConfig(data []byte, format string, config *SimpleConfig) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, config)
+	case FormatYAML, FormatTOML:
+	default:
+		return fmt.Errorf("unsupported config format %q (want %q, %q or %q)", format, FormatJSON, FormatYAML, FormatTOML)
+	}
+
+	values, err := parseFlatConfig(data, format)
+	if err != nil {
+		return err
+	}
+	// Re-encode as JSON so every format shares the SimpleConfig schema and type checks
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, config)
+}
+
+// parseFlatConfig parses a YAML or TOML document of top-level "key: value" or
+// "key = value" lines into scalar values
+func parseFlatConfig(data []byte, format string) (map[string]interface{}, error) {
+	separator := ":"
+	if format == FormatTOML {
+		separator = "="
+	}
+
+	values := make(map[string]interface{})
+	for i, rawLine := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(stripComment(rawLine), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || format == FormatYAML && trimmed == "---" {
+			continue
+		}
+		if trimmed != line {
+			return nil, fmt.Errorf("line %d: nested values are not supported", lineNo)
+		}
+		if format == FormatTOML && strings.HasPrefix(trimmed, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", lineNo)
+		}
+
+		key, rawValue, found := strings.Cut(trimmed, separator)
+		key = strings.TrimSpace(key)
+		rawValue = strings.TrimSpace(rawValue)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected key%svalue", lineNo, separator)
+		}
+		if rawValue == "" {
+			if format == FormatYAML {
+				return nil, fmt.Errorf("line %d: %q has no value (nested values are not supported)", lineNo, key)
+			}
+			return nil, fmt.Errorf("line %d: %q has no value", lineNo, key)
+		}
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+
+		value, err := parseScalar(rawValue, format)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", lineNo, key, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// stripComment removes a trailing # comment that is not inside quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseScalar parses a quoted string, boolean or number. YAML also accepts
+// unquoted strings such as durations (ttl: 10m); TOML requires strings to be quoted.
+func parseScalar(raw, format string) (interface{}, error) {
+	switch {
+	case raw[0] == '"':
+		return strconv.Unquote(raw)
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f, nil
+	}
+	if format == FormatTOML {
+		return nil, fmt.Errorf("strings must be quoted, got %s", raw)
+	}
+	return raw, nil
+}
+
+// jsonFieldName returns the json tag name of a field and whether it has omitempty
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, options == "omitempty"
+}
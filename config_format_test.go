@@ -0,0 +1,188 @@
+// config_format_test.go: Tests for JSON, YAML and TOML config files
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to name in a temporary directory and returns its path
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestLoadConfigFile_AllFormats tests that the same settings load identically from every format
+func TestLoadConfigFile_AllFormats(t *testing.T) {
+	files := map[string]string{
+		"metis.json": `{
+  "cache_size": 5000,
+  "ttl": "1h30m",
+  "eviction_policy": "arc",
+  "shard_count": 16,
+  "enable_compression": true,
+  "window_ratio": 0.05
+}`,
+		"metis.yaml": `---
+# service cache
+cache_size: 5000
+ttl: 1h30m            # unquoted durations are strings
+eviction_policy: "arc"
+shard_count: 16
+enable_compression: true
+window_ratio: 0.05
+`,
+		"metis.yml": "cache_size: 5000\nttl: '1h30m'\neviction_policy: arc\nshard_count: 16\nenable_compression: true\nwindow_ratio: 0.05\n",
+		"metis.toml": `# service cache
+cache_size = 5000
+ttl = "1h30m"
+eviction_policy = "arc"  # "#" inside strings is fine: "a#b"
+shard_count = 16
+enable_compression = true
+window_ratio = 0.05
+`,
+	}
+
+	for name, content := range files {
+		config, err := LoadConfigFile(writeConfigFile(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: LoadConfigFile failed: %v", name, err)
+		}
+		if config.CacheSize != 5000 || config.TTL != 90*time.Minute || config.EvictionPolicy != "arc" ||
+			config.ShardCount != 16 || !config.EnableCompression || config.WindowRatio != 0.05 {
+			t.Errorf("%s: unexpected config %+v", name, config)
+		}
+		// Unset keys keep their defaults
+		if config.AdmissionPolicy != "always" {
+			t.Errorf("%s: expected the default AdmissionPolicy, got %q", name, config.AdmissionPolicy)
+		}
+	}
+}
+
+// TestMarshalConfig_RoundTrip tests that MarshalConfig output loads back unchanged
+func TestMarshalConfig_RoundTrip(t *testing.T) {
+	original := SimpleConfig{
+		CacheSize:            20000,
+		TTL:                  "45m",
+		EvictionPolicy:       "wtinylfu",
+		AdmissionPolicy:      "size-aware",
+		ShardCount:           64,
+		CompressionCodec:     "gzip",
+		MaxMemoryBytes:       1 << 30,
+		ProbationRatio:       0.25,
+		SizeAwareUtilization: 0.9,
+		CopyOnRead:           true,
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		data, err := MarshalConfig(original, format)
+		if err != nil {
+			t.Fatalf("%s: MarshalConfig failed: %v", format, err)
+		}
+		if format != FormatJSON && strings.Contains(string(data), "max_key_size") {
+			t.Errorf("%s: expected unset keys to be omitted:\n%s", format, data)
+		}
+
+		var decoded SimpleConfig
+		if err := UnmarshalConfig(data, format, &decoded); err != nil {
+			t.Fatalf("%s: UnmarshalConfig failed: %v\n%s", format, err, data)
+		}
+		if decoded != original {
+			t.Errorf("%s: round trip changed the config:\n got  %+v\n want %+v", format, decoded, original)
+		}
+	}
+
+	if _, err := MarshalConfig(original, "xml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+// TestLoadConfigFile_Errors tests that malformed files report the problem and its line
+func TestLoadConfigFile_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"nested.yaml", "cache_size: 100\nlimits:\n  max_key_size: 10\n", "nested values are not supported"},
+		{"indented.yaml", "cache_size: 100\n  ttl: 1m\n", "line 2"},
+		{"list.yaml", "cache_size: 100\n- item\n", "line 2"},
+		{"duplicate.yml", "cache_size: 100\ncache_size: 200\n", "duplicate key"},
+		{"empty.toml", "ttl =\n", "has no value"},
+		{"bad_ttl.yaml", "ttl: soon\n", "invalid TTL format"},
+		{"wrong_type.yaml", "cache_size: many\n", "cache_size"},
+		{"table.toml", "[metis]\ncache_size = 100\n", "tables are not supported"},
+		{"unquoted.toml", "ttl = 10m\n", "strings must be quoted"},
+		{"syntax.toml", "cache_size 100\n", "line 1"},
+		{"policy.toml", "eviction_policy = \"mru\"\n", "EvictionPolicy"},
+	}
+
+	for _, tc := range cases {
+		_, err := LoadConfigFile(writeConfigFile(t, tc.name, tc.content))
+		if err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) || !strings.Contains(err.Error(), tc.name) {
+			t.Errorf("%s: expected an error naming the file and mentioning %q, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	_, err := LoadConfigFile(writeConfigFile(t, "policy.json", `{"eviction_policy": "mru"}`))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected validation errors to wrap ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestFindConfigFile_YAML tests that New's search also finds metis.yaml and metis.toml
+func TestFindConfigFile_YAML(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	configMutex.Lock()
+	originalGlobalConfig := globalConfig
+	globalConfig = nil
+	configMutex.Unlock()
+	defer func() {
+		configMutex.Lock()
+		globalConfig = originalGlobalConfig
+		configMutex.Unlock()
+	}()
+	t.Setenv(ConfigEnvVar, "")
+
+	dir := filepath.Dir(writeConfigFile(t, "metis.yaml", "cache_size: 3000\nttl: 2m\n"))
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	if config := LoadConfig(); config.CacheSize != 3000 || config.TTL != 2*time.Minute {
+		t.Errorf("expected metis.yaml to be loaded, got %+v", config)
+	}
+	if source := GetConfigSource(); source != "YAML configuration (metis.yaml)" {
+		t.Errorf("unexpected config source %q", source)
+	}
+
+	// metis.json takes precedence when both exist
+	if err := os.WriteFile("metis.json", []byte(`{"cache_size": 4000}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if config := LoadConfig(); config.CacheSize != 4000 {
+		t.Errorf("expected metis.json to take precedence, got %d", config.CacheSize)
+	}
+}
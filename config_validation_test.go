@@ -8,6 +8,7 @@ package metis
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -508,3 +509,160 @@ func TestLoadJSONConfig_PathTraversalValidation(t *testing.T) {
 		t.Errorf("expected valid config to load successfully, got error: %v", err)
 	}
 }
+
+// cliPresets is the exact metis.json emitted by cmd/metis-cli for each preset
+var cliPresets = map[string]struct {
+	json        string
+	cacheSize   int
+	ttl         time.Duration
+	shards      int
+	compression bool
+}{
+	"development": {`{
+  "cache_size": 1000,
+  "ttl": "10m",
+  "eviction_policy": "wtinylfu",
+  "shard_count": 128
+}`, 1000, 10 * time.Minute, 128, false},
+	"web-application": {`{
+  "cache_size": 50000,
+  "ttl": "30m",
+  "eviction_policy": "wtinylfu",
+  "shard_count": 32
+}`, 50000, 30 * time.Minute, 32, false},
+	"high-performance": {`{
+  "cache_size": 1000000,
+  "ttl": "0s",
+  "eviction_policy": "wtinylfu",
+  "shard_count": 128
+}`, 1000000, 10 * time.Minute, 128, false},
+	"memory-constrained": {`{
+  "cache_size": 10000,
+  "ttl": "1h",
+  "eviction_policy": "wtinylfu",
+  "shard_count": 128,
+  "enable_compression": true,
+  "max_value_size": 524288
+}`, 10000, time.Hour, 128, true},
+}
+
+// TestNewFromFile_CLIPresets loads every metis-cli preset through NewFromFile
+func TestNewFromFile_CLIPresets(t *testing.T) {
+	for name, preset := range cliPresets {
+		path := filepath.Join(t.TempDir(), "metis.json")
+		if err := os.WriteFile(path, []byte(preset.json), 0600); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+
+		cache, err := NewFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: NewFromFile failed: %v", name, err)
+		}
+		// A zero TTL is replaced by NewStrategicCache's default
+		config := cache.config
+		if config.CacheSize != preset.cacheSize || config.TTL != preset.ttl || config.ShardCount != preset.shards ||
+			config.EnableCompression != preset.compression {
+			t.Errorf("%s: unexpected config %+v", name, config)
+		}
+		if !cache.Set("key", "value") {
+			t.Errorf("%s: expected a working cache", name)
+		}
+		cache.Close()
+	}
+}
+
+// TestNewFromFile_Errors tests that malformed files are reported instead of ignored
+func TestNewFromFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"syntax.json": `{"cache_size": 1000,`,
+		"ttl.json":    `{"cache_size": 1000, "ttl": "thirty minutes"}`,
+		"policy.json": `{"cache_size": 1000, "eviction_policy": "lfru"}`,
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+		if cache, err := NewFromFile(path); err == nil || cache != nil {
+			t.Errorf("%s: expected an error", name)
+		} else if !strings.Contains(err.Error(), name) {
+			t.Errorf("%s: expected the error to name the file, got %v", name, err)
+		}
+	}
+
+	if _, err := NewFromFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, err := NewFromFile(filepath.Join(dir, "policy.json")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected validation errors to wrap ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestNewE_SearchPath tests METIS_CONFIG and the metis.json search
+func TestNewE_SearchPath(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	workDir := t.TempDir()
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Setenv(ConfigEnvVar, "")
+
+	// Clear global config first
+	configMutex.Lock()
+	originalGlobalConfig := globalConfig
+	globalConfig = nil
+	configMutex.Unlock()
+	defer func() {
+		configMutex.Lock()
+		globalConfig = originalGlobalConfig
+		configMutex.Unlock()
+	}()
+
+	// No file: defaults
+	cache, err := NewE()
+	if err != nil {
+		t.Fatalf("expected defaults without a config file, got %v", err)
+	}
+	cache.Close()
+
+	// metis.json in the working directory
+	if err := os.WriteFile("metis.json", []byte(cliPresets["web-application"].json), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	cache, err = NewE()
+	if err != nil || cache.config.CacheSize != 50000 || cache.config.TTL != 30*time.Minute {
+		t.Fatalf("expected metis.json to be loaded, got %v", err)
+	}
+	cache.Close()
+
+	// METIS_CONFIG takes precedence, with any file name
+	envPath := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(envPath, []byte(cliPresets["memory-constrained"].json), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv(ConfigEnvVar, envPath)
+	cache, err = NewE()
+	if err != nil || cache.config.CacheSize != 10000 || !cache.config.EnableCompression {
+		t.Fatalf("expected $METIS_CONFIG to be loaded, got %v", err)
+	}
+	cache.Close()
+	if source := GetConfigSource(); !strings.Contains(source, ConfigEnvVar) {
+		t.Errorf("expected the config source to name %s, got %q", ConfigEnvVar, source)
+	}
+
+	// A malformed file is an error for NewE, but New falls back to defaults
+	if err := os.WriteFile(envPath, []byte(`{"ttl": "soon"}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if _, err := NewE(); err == nil {
+		t.Error("expected NewE to report the malformed file")
+	}
+	legacy := New()
+	legacy.Close()
+}
@@ -7,6 +7,8 @@
 package metis
 
 import (
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"runtime"
 	"time"
@@ -52,6 +54,77 @@ func ValidateConfig(config CacheConfig) ConfigValidationResult {
 		result.Suggestions = append(result.Suggestions, fmt.Sprintf("Consider increasing shard count to %d for better concurrency", numCPU))
 	}
 
+	// W-TinyLFU window ratio must leave room for both segments
+	if config.WindowRatio != 0 && (config.WindowRatio <= 0 || config.WindowRatio >= 1) {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, fmt.Sprintf("WindowRatio must be within (0,1), got %.2f", config.WindowRatio))
+	}
+	if config.ProbationRatio != 0 && (config.ProbationRatio <= 0 || config.ProbationRatio >= 1) {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, fmt.Sprintf("ProbationRatio must be within (0,1), got %.2f", config.ProbationRatio))
+	}
+
+	// Sketch dimensions
+	if config.SketchDepth < 0 || config.SketchWidth < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "SketchDepth and SketchWidth must not be negative")
+	}
+	if config.SketchDepth > 16 {
+		result.Suggestions = append(result.Suggestions, fmt.Sprintf("SketchDepth %d adds hashing cost with little accuracy gain; 4-8 rows are usually enough", config.SketchDepth))
+	}
+
+	// Size-aware admission thresholds
+	if config.SizeAwareMaxSize < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "SizeAwareMaxSize must not be negative")
+	}
+	if config.SizeAwareUtilization < 0 || config.SizeAwareUtilization > 1 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, fmt.Sprintf("SizeAwareUtilization must be within [0,1], got %.2f", config.SizeAwareUtilization))
+	}
+
+	// Compression codec must be registered
+	if config.CompressionCodec != "" {
+		if _, ok := LookupCodec(config.CompressionCodec); !ok {
+			result.IsValid = false
+			result.Warnings = append(result.Warnings, fmt.Sprintf("CompressionCodec %q is not registered; gzip will be used", config.CompressionCodec))
+		}
+	}
+
+	// Gzip compression settings
+	if config.CompressionMinSize < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "CompressionMinSize must not be negative")
+	}
+	if config.CompressionLevel < gzip.HuffmanOnly || config.CompressionLevel > gzip.BestCompression {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CompressionLevel must be within [%d,%d], got %d", gzip.HuffmanOnly, gzip.BestCompression, config.CompressionLevel))
+	}
+
+	// Background snapshots need somewhere to go
+	if config.SnapshotInterval < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "SnapshotInterval must not be negative")
+	}
+	if config.SnapshotInterval > 0 && config.SnapshotPath == "" {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "SnapshotInterval is set but SnapshotPath is empty; no snapshots will be written")
+	}
+
+	// Write-behind queue settings
+	if config.WriteBehindBufferSize < 0 || config.WriteBehindBatchSize < 0 || config.WriteBehindWorkers < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "WriteBehindBufferSize, WriteBehindBatchSize and WriteBehindWorkers must not be negative")
+	}
+	if config.WriteBehindFlushInterval < 0 || config.WriteBehindRetryBackoff < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "WriteBehindFlushInterval and WriteBehindRetryBackoff must not be negative")
+	}
+	if config.BreakerThreshold < 0 || config.BreakerHalfOpenProbes < 0 || config.BreakerOpenDuration < 0 {
+		result.IsValid = false
+		result.Warnings = append(result.Warnings, "BreakerThreshold, BreakerHalfOpenProbes and BreakerOpenDuration must not be negative")
+	}
+
 	// TTL validation
 	if config.TTL > 24*time.Hour {
 		result.Suggestions = append(result.Suggestions, "Very long TTL (>24h) may cause memory issues for large datasets")
@@ -70,6 +143,130 @@ func ValidateConfig(config CacheConfig) ConfigValidationResult {
 	return result
 }
 
+// Validate reports values that NewStrategicCache would silently replace or ignore,
+// joining one error per problem. Zero values select defaults and are always valid.
+// Every error wraps ErrInvalidConfig.
+func (c CacheConfig) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidConfig}, args...)...))
+	}
+
+	if c.CacheSize < 0 {
+		invalid("CacheSize must not be negative, got %d", c.CacheSize)
+	}
+	if c.ShardCount < 0 {
+		invalid("ShardCount must not be negative, got %d", c.ShardCount)
+	}
+	if c.ShardCount > 1<<30 {
+		invalid("ShardCount %d exceeds the maximum of %d", c.ShardCount, 1<<30)
+	}
+	if c.MaxShardSize < 0 {
+		invalid("MaxShardSize must not be negative, got %d", c.MaxShardSize)
+	}
+	if c.CacheSize > 0 && c.MaxShardSize > c.CacheSize {
+		invalid("MaxShardSize %d is larger than CacheSize %d", c.MaxShardSize, c.CacheSize)
+	}
+	if c.TTL < 0 {
+		invalid("TTL must not be negative, got %s", c.TTL)
+	}
+	if c.CleanupInterval < 0 {
+		invalid("CleanupInterval must not be negative, got %s", c.CleanupInterval)
+	}
+	if c.MaxIdleTime < 0 {
+		invalid("MaxIdleTime must not be negative, got %s", c.MaxIdleTime)
+	}
+	if c.LazyCleanupBatch < 0 {
+		invalid("LazyCleanupBatch must not be negative, got %d", c.LazyCleanupBatch)
+	}
+	if c.StatsWindow < 0 {
+		invalid("StatsWindow must not be negative, got %s", c.StatsWindow)
+	}
+	if c.StatsWindowBuckets < 0 {
+		invalid("StatsWindowBuckets must not be negative, got %d", c.StatsWindowBuckets)
+	}
+	if c.TrackHotKeys < 0 {
+		invalid("TrackHotKeys must not be negative, got %d", c.TrackHotKeys)
+	}
+	if c.MaxKeySize < 0 || c.MaxValueSize < 0 || c.MaxMemoryBytes < 0 {
+		invalid("MaxKeySize, MaxValueSize and MaxMemoryBytes must not be negative")
+	}
+
+	if c.CustomEvictionPolicy == nil {
+		switch c.EvictionPolicy {
+		case "", "default", "lru", "wtinylfu", "arc":
+		default:
+			invalid("unknown EvictionPolicy %q (want \"lru\", \"wtinylfu\" or \"arc\")", c.EvictionPolicy)
+		}
+	}
+	if c.CustomAdmissionPolicy == nil {
+		switch c.AdmissionPolicy {
+		case "", "always", "never", "probabilistic", "tinylfu", "size-aware":
+		default:
+			invalid("unknown AdmissionPolicy %q (want \"always\", \"never\", \"probabilistic\", \"tinylfu\" or \"size-aware\")", c.AdmissionPolicy)
+		}
+	}
+	if c.CustomKeyHasher == nil {
+		switch c.KeyHasher {
+		case "", "maphash", "legacy":
+		default:
+			invalid("unknown KeyHasher %q (want \"maphash\" or \"legacy\")", c.KeyHasher)
+		}
+	}
+	// -1 is the documented "unset" value
+	if c.AdmissionProbability > 1 || c.AdmissionProbability < 0 && c.AdmissionProbability != -1 {
+		invalid("AdmissionProbability must be within [0,1], got %g", c.AdmissionProbability)
+	}
+
+	if c.WindowRatio < 0 || c.WindowRatio >= 1 {
+		invalid("WindowRatio must be within (0,1), got %g", c.WindowRatio)
+	}
+	if c.ProbationRatio < 0 || c.ProbationRatio >= 1 {
+		invalid("ProbationRatio must be within (0,1), got %g", c.ProbationRatio)
+	}
+	if c.SketchDepth < 0 || c.SketchWidth < 0 {
+		invalid("SketchDepth and SketchWidth must not be negative")
+	}
+	if c.SizeAwareMaxSize < 0 {
+		invalid("SizeAwareMaxSize must not be negative, got %d", c.SizeAwareMaxSize)
+	}
+	if c.SizeAwareUtilization < 0 || c.SizeAwareUtilization > 1 {
+		invalid("SizeAwareUtilization must be within [0,1], got %g", c.SizeAwareUtilization)
+	}
+
+	if c.CompressionCodec != "" {
+		if _, ok := LookupCodec(c.CompressionCodec); !ok {
+			invalid("CompressionCodec %q is not registered", c.CompressionCodec)
+		}
+	}
+	if c.CompressionMinSize < 0 {
+		invalid("CompressionMinSize must not be negative, got %d", c.CompressionMinSize)
+	}
+	if c.CompressionLevel < gzip.HuffmanOnly || c.CompressionLevel > gzip.BestCompression {
+		invalid("CompressionLevel must be within [%d,%d], got %d", gzip.HuffmanOnly, gzip.BestCompression, c.CompressionLevel)
+	}
+	if c.ChunkThreshold < 0 {
+		invalid("ChunkThreshold must not be negative, got %d", c.ChunkThreshold)
+	}
+	if c.SnapshotInterval < 0 {
+		invalid("SnapshotInterval must not be negative, got %s", c.SnapshotInterval)
+	}
+	if c.SnapshotInterval > 0 && c.SnapshotPath == "" {
+		invalid("SnapshotInterval requires SnapshotPath")
+	}
+	if c.WriteBehindBufferSize < 0 || c.WriteBehindBatchSize < 0 || c.WriteBehindWorkers < 0 {
+		invalid("WriteBehindBufferSize, WriteBehindBatchSize and WriteBehindWorkers must not be negative")
+	}
+	if c.WriteBehindFlushInterval < 0 || c.WriteBehindRetryBackoff < 0 {
+		invalid("WriteBehindFlushInterval and WriteBehindRetryBackoff must not be negative")
+	}
+	if c.BreakerThreshold < 0 || c.BreakerHalfOpenProbes < 0 || c.BreakerOpenDuration < 0 {
+		invalid("BreakerThreshold, BreakerHalfOpenProbes and BreakerOpenDuration must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
 // estimateMemoryUsage provides rough memory usage estimation
 func estimateMemoryUsage(config CacheConfig) int64 {
 	// Rough estimation: 200 bytes per entry (key + value + metadata)
@@ -7,6 +7,7 @@
 package metis
 
 import (
+	"errors"
 	"runtime"
 	"strings"
 	"testing"
@@ -440,3 +441,67 @@ func TestGenerateOptimizedConfig_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheConfigValidate(t *testing.T) {
+	valid := []CacheConfig{
+		{},
+		{CacheSize: 1000, ShardCount: 16, EvictionPolicy: "arc", AdmissionPolicy: "tinylfu", TTL: time.Minute},
+		{EvictionPolicy: "default", AdmissionPolicy: "probabilistic", AdmissionProbability: 0.3},
+		{AdmissionProbability: -1},
+		{EvictionPolicy: "custom-name", CustomEvictionPolicy: &LRUPolicy{}},
+	}
+	for _, config := range valid {
+		if err := config.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", config, err)
+		}
+	}
+
+	invalid := map[string]CacheConfig{
+		"eviction policy":  {EvictionPolicy: "lfu"},
+		"admission policy": {AdmissionPolicy: "sometimes"},
+		"probability":      {AdmissionPolicy: "probabilistic", AdmissionProbability: 1.5},
+		"negative prob":    {AdmissionProbability: -0.2},
+		"max shard size":   {CacheSize: 100, MaxShardSize: 200},
+		"negative ttl":     {TTL: -time.Second},
+		"negative shards":  {ShardCount: -4},
+		"huge shards":      {ShardCount: 1<<30 + 1},
+		"window ratio":     {WindowRatio: 1.2},
+		"unknown codec":    {CompressionCodec: "lz4-missing"},
+	}
+	for name, config := range invalid {
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected the error to wrap ErrInvalidConfig, got %v", name, err)
+		}
+	}
+
+	// Every problem is reported
+	err := CacheConfig{EvictionPolicy: "lfu", TTL: -time.Second}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `"lfu"`) || !strings.Contains(err.Error(), "TTL") {
+		t.Errorf("expected both problems in the error, got %v", err)
+	}
+}
+
+func TestNewStrategicCacheE(t *testing.T) {
+	cache, err := NewStrategicCacheE(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	if err != nil || cache == nil {
+		t.Fatalf("expected a cache, got %v", err)
+	}
+	cache.Close()
+
+	cache, err = NewStrategicCacheE(CacheConfig{EnableCaching: true, EvictionPolicy: "lruu"})
+	if cache != nil || !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a typo, got %v, %v", cache, err)
+	}
+
+	// The original constructor keeps falling back silently
+	legacy := NewStrategicCache(CacheConfig{EnableCaching: true, EvictionPolicy: "lruu"})
+	defer legacy.Close()
+	if legacy.PolicyName() != "lru" {
+		t.Errorf("expected NewStrategicCache to fall back to lru, got %s", legacy.PolicyName())
+	}
+}
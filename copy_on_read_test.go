@@ -0,0 +1,107 @@
+// copy_on_read_test.go: Tests for the CopyOnRead option
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"reflect"
+	"testing"
+)
+
+type copyNode struct {
+	Name     string
+	Children []*copyNode
+	Parent   *copyNode
+	Labels   map[string]string
+	hidden   int
+}
+
+func TestCopyOnRead_MapMutationDoesNotLeak(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:  true,
+			CacheSize:      100,
+			ShardCount:     2,
+			EvictionPolicy: policy,
+			CopyOnRead:     true,
+		})
+
+		cache.Set("counts", map[string]int{"a": 1})
+		first, _ := cache.Get("counts")
+		first.(map[string]int)["a"] = 100
+		first.(map[string]int)["b"] = 2
+
+		second, ok := cache.Get("counts")
+		if !ok || !reflect.DeepEqual(second, map[string]int{"a": 1}) {
+			t.Errorf("%s: expected the cached map to be unchanged, got %v", policy, second)
+		}
+		cache.Close()
+	}
+}
+
+func TestCopyOnRead_DisabledSharesValue(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru"})
+	defer cache.Close()
+
+	cache.Set("counts", map[string]int{"a": 1})
+	first, _ := cache.Get("counts")
+	first.(map[string]int)["a"] = 100
+	if second, _ := cache.Get("counts"); second.(map[string]int)["a"] != 100 {
+		t.Error("expected Get to return the stored map when CopyOnRead is off")
+	}
+}
+
+func TestCopyOnRead_NestedValuesAndRange(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru", CopyOnRead: true})
+	defer cache.Close()
+
+	root := &copyNode{Name: "root", Labels: map[string]string{"k": "v"}, hidden: 7}
+	root.Children = []*copyNode{{Name: "child"}}
+	cache.Set("tree", root)
+	cache.Set("bytes", []byte("abc"))
+
+	got, _ := cache.Get("tree")
+	clone := got.(*copyNode)
+	if clone == root || clone.Children[0] == root.Children[0] {
+		t.Fatal("expected pointers to be cloned")
+	}
+	if clone.hidden != 7 || clone.Labels["k"] != "v" {
+		t.Errorf("expected field values to be preserved, got %+v", clone)
+	}
+	clone.Children[0].Name = "changed"
+	clone.Labels["k"] = "changed"
+	if root.Children[0].Name != "child" || root.Labels["k"] != "v" {
+		t.Error("expected mutations of the copy to leave the stored value intact")
+	}
+
+	cache.Range(func(key string, value interface{}) bool {
+		if key == "bytes" {
+			value.([]byte)[0] = 'z'
+		}
+		return true
+	})
+	if b, _ := cache.GetBytes("bytes"); string(b) != "abc" {
+		t.Errorf("expected Range to hand out copies, got %q", b)
+	}
+}
+
+func TestDeepCopy_Cycle(t *testing.T) {
+	root := &copyNode{Name: "root"}
+	root.Children = []*copyNode{{Name: "child", Parent: root}}
+
+	clone := deepCopy(root).(*copyNode)
+	if clone == root || clone.Children[0].Parent != clone {
+		t.Error("expected the cycle to point at the cloned root")
+	}
+}
+
+func TestDeepCopy_PrimitivesAndNil(t *testing.T) {
+	for _, value := range []interface{}{nil, "s", 42, 3.5, true, []int(nil), map[string]int(nil), (*copyNode)(nil)} {
+		if got := deepCopy(value); !reflect.DeepEqual(got, value) {
+			t.Errorf("expected %#v to be copied unchanged, got %#v", value, got)
+		}
+	}
+}
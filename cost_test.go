@@ -0,0 +1,184 @@
+// cost_test.go: Tests for per-entry cost via SetWithOptions
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSetWithOptions_ClassicPathEvictsByCost(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("flag%d", i), true)
+	}
+	if !cache.SetWithOptions("page", "<html></html>", WithCost(8)) {
+		t.Fatal("expected weighted entry to be stored")
+	}
+
+	stats := cache.GetStats()
+	if stats.TotalCost > 10 {
+		t.Errorf("total cost %d exceeds CacheSize", stats.TotalCost)
+	}
+	if stats.Keys != 3 {
+		t.Errorf("expected page plus 2 flags, got %d keys", stats.Keys)
+	}
+	if stats.TotalCost != 10 {
+		t.Errorf("expected total cost 10, got %d", stats.TotalCost)
+	}
+
+	// LRU order decides which flags made room
+	if _, ok := cache.Get("flag0"); ok {
+		t.Error("expected oldest flag to be evicted")
+	}
+	if _, ok := cache.Get("flag4"); !ok {
+		t.Error("expected newest flag to survive")
+	}
+}
+
+func TestSetWithOptions_ClassicPathRejectsOverweight(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	cache.Set("flag", true)
+	if cache.SetWithOptions("page", "x", WithCost(11)) {
+		t.Error("expected entry costlier than the shard to be rejected")
+	}
+	if _, ok := cache.Get("flag"); !ok {
+		t.Error("rejected entry should not evict anything")
+	}
+}
+
+func TestSetWithOptions_ClassicPathUpdateAdjustsCost(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("flag%d", i), true)
+	}
+	cache.SetWithOptions("flag4", true, WithCost(8))
+
+	stats := cache.GetStats()
+	if stats.TotalCost != 10 || stats.Keys != 3 {
+		t.Errorf("expected 3 keys with total cost 10, got %d keys and cost %d", stats.Keys, stats.TotalCost)
+	}
+	if _, ok := cache.Get("flag4"); !ok {
+		t.Error("updated entry must not evict itself")
+	}
+
+	// Shrinking the cost and deleting release capacity
+	cache.SetWithOptions("flag4", true, WithCost(2))
+	if got := cache.GetStats().TotalCost; got != 4 {
+		t.Errorf("expected total cost 4 after shrinking, got %d", got)
+	}
+	cache.Delete("flag4")
+	if got := cache.GetStats().TotalCost; got != 2 {
+		t.Errorf("expected total cost 2 after delete, got %d", got)
+	}
+	cache.Clear()
+	if got := cache.GetStats().TotalCost; got != 0 {
+		t.Errorf("expected total cost 0 after clear, got %d", got)
+	}
+}
+
+func TestSetWithOptions_DefaultCostMatchesSet(t *testing.T) {
+	plain := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 8, ShardCount: 1, EvictionPolicy: "lru"})
+	defer plain.Close()
+	weighted := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 8, ShardCount: 1, EvictionPolicy: "lru"})
+	defer weighted.Close()
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		plain.Set(key, i)
+		weighted.SetWithOptions(key, i, WithCost(0)) // below 1 means the default
+	}
+
+	ps, ws := plain.GetStats(), weighted.GetStats()
+	if ps.Keys != ws.Keys || ps.Evictions != ws.Evictions || ps.TotalCost != ws.TotalCost {
+		t.Errorf("default cost diverged from Set: %+v vs %+v", ps, ws)
+	}
+	if ws.TotalCost != int64(ws.Keys) {
+		t.Errorf("expected total cost to equal keys, got %d and %d", ws.TotalCost, ws.Keys)
+	}
+}
+
+func TestSetWithOptions_WTinyLFUPathBoundsCost(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       1000,
+		ShardCount:      1,
+		EvictionPolicy:  "wtinylfu",
+		AdmissionPolicy: "always",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 200; i++ {
+		cache.SetWithOptions(fmt.Sprintf("page%d", i), i, WithCost(10))
+	}
+
+	stats := cache.GetStats()
+	maxCost := int64(cache.wtinylfu.MaxSize())
+	if stats.TotalCost > maxCost {
+		t.Errorf("total cost %d exceeds capacity %d", stats.TotalCost, maxCost)
+	}
+	if stats.TotalCost != int64(stats.Keys)*10 {
+		t.Errorf("expected total cost %d for %d keys, got %d", stats.Keys*10, stats.Keys, stats.TotalCost)
+	}
+	if stats.Keys >= 200 {
+		t.Errorf("expected weighted entries to be evicted, got %d keys", stats.Keys)
+	}
+
+	if cache.SetWithOptions("huge", 1, WithCost(maxCost+1)) {
+		t.Error("expected entry costlier than the cache to be rejected")
+	}
+}
+
+func TestSetWithOptions_TTL(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			cache.SetWithOptions("short", 1, WithTTL(30*time.Millisecond))
+			cache.SetWithOptions("long", 2, WithTTL(0))
+			clock.Advance(60 * time.Millisecond)
+			if _, ok := cache.Get("short"); ok {
+				t.Error("expected the entry with WithTTL to expire")
+			}
+			if _, ok := cache.Get("long"); !ok {
+				t.Error("expected WithTTL(0) to keep the cache TTL")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// custom_policy_test.go: Tests for user-supplied eviction and admission policies
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// tmpFirstPolicy evicts the least recent "tmp_" key, falling back to plain LRU
+type tmpFirstPolicy struct {
+	calls int
+}
+
+func (p *tmpFirstPolicy) EvictKey(cache map[string]*CacheEntry, ll *list.List) string {
+	p.calls++
+	for e := ll.Back(); e != nil; e = e.Prev() {
+		if entry := e.Value.(*CacheEntry); strings.HasPrefix(entry.Key, "tmp_") {
+			return entry.Key
+		}
+	}
+	return (&LRUPolicy{}).EvictKey(cache, ll)
+}
+
+// prefixAdmission only admits keys with the given prefix
+type prefixAdmission struct {
+	prefix string
+}
+
+func (p prefixAdmission) Allow(key string, value interface{}) bool {
+	return strings.HasPrefix(key, p.prefix)
+}
+
+func TestCustomEvictionPolicy_DrivesEviction(t *testing.T) {
+	policy := &tmpFirstPolicy{}
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:        true,
+		CacheSize:            4,
+		ShardCount:           1,
+		EvictionPolicy:       "wtinylfu", // Overridden by the custom policy
+		CustomEvictionPolicy: policy,
+	})
+	defer cache.Close()
+
+	if cache.wtinylfu != nil {
+		t.Fatal("custom policy should take precedence over the named policy")
+	}
+	if name := cache.PolicyName(); name != "custom" {
+		t.Errorf("expected policy name custom, got %s", name)
+	}
+
+	cache.Set("user_1", 1)
+	cache.Set("tmp_a", "a")
+	cache.Set("user_2", 2)
+	cache.Set("tmp_b", "b")
+
+	// Cache is full: the new entries push out tmp_ keys first, even though user_1 is oldest
+	cache.Set("user_3", 3)
+	cache.Set("user_4", 4)
+
+	for _, key := range []string{"tmp_a", "tmp_b"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("expected %s to be evicted first", key)
+		}
+	}
+	for _, key := range []string{"user_1", "user_2", "user_3", "user_4"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s to survive", key)
+		}
+	}
+
+	// With no tmp_ keys left, the fallback evicts the least recently used entry
+	cache.Get("user_1")
+	cache.Set("user_5", 5)
+	if _, ok := cache.Get("user_2"); ok {
+		t.Error("expected LRU fallback to evict user_2")
+	}
+	if policy.calls != 3 {
+		t.Errorf("expected the policy to be consulted 3 times, got %d", policy.calls)
+	}
+	if evictions := cache.GetStats().Evictions; evictions != 3 {
+		t.Errorf("expected 3 evictions, got %d", evictions)
+	}
+}
+
+func TestCustomAdmissionPolicy_TakesPrecedence(t *testing.T) {
+	for _, evictionPolicy := range []string{"lru", "wtinylfu"} {
+		t.Run(evictionPolicy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:         true,
+				CacheSize:             1000,
+				ShardCount:            4,
+				EvictionPolicy:        evictionPolicy,
+				AdmissionPolicy:       "never", // Overridden by the custom policy
+				CustomAdmissionPolicy: prefixAdmission{prefix: "keep_"},
+			})
+			defer cache.Close()
+
+			for i := 0; i < 10; i++ {
+				cache.Set(fmt.Sprintf("keep_%d", i), i)
+				cache.Set(fmt.Sprintf("drop_%d", i), i)
+			}
+
+			if keys := cache.GetStats().Keys; keys != 10 {
+				t.Errorf("expected only 10 admitted keys, got %d", keys)
+			}
+			if _, ok := cache.Get("drop_0"); ok {
+				t.Error("expected drop_0 to be rejected by admission")
+			}
+			if _, ok := cache.Get("keep_0"); !ok {
+				t.Error("expected keep_0 to be admitted")
+			}
+		})
+	}
+}
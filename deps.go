@@ -0,0 +1,66 @@
+// deps.go: Dependency-based invalidation for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+// MaxDependencyDepth is how many levels of dependents Invalidate removes below the key
+// it was called with. Dependents further down are left cached.
+const MaxDependencyDepth = 32
+
+// WithDependsOn makes an entry a dependent of parentKeys, so that Invalidate on any of
+// them removes it, along with the entries that depend on it in turn. The parents do not
+// have to be cached. A later Set of the key replaces its parents, and one without
+// WithDependsOn removes them.
+func WithDependsOn(parentKeys ...string) SetOption {
+	return func(o *setOptions) {
+		o.dependsOn = append(o.dependsOn, parentKeys...)
+	}
+}
+
+// Invalidate removes key and, transitively, every entry stored with WithDependsOn on it,
+// down to MaxDependencyDepth levels, and returns how many entries were cached. Each key
+// is visited once, so cycles and diamond-shaped dependencies are safe. Entries are
+// removed from memory only: a Backend is not updated.
+func (sc *StrategicCache) Invalidate(key string) int {
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	if closed {
+		return 0
+	}
+
+	deps := sc.deps.Load()
+	visited := map[string]struct{}{key: {}}
+	level := []string{key}
+	removed := 0
+	for depth := 0; len(level) > 0; depth++ {
+		var next []string
+		for _, k := range level {
+			sc.traceOp(TraceDelete, k, nil)
+			if sc.deleteLocal(sc.HashKey(k)) {
+				removed++
+			}
+			if deps == nil || depth == MaxDependencyDepth {
+				continue
+			}
+			for _, child := range deps.take(k) {
+				if _, seen := visited[child]; !seen {
+					visited[child] = struct{}{}
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+	return removed
+}
+
+// keepDependent reports whether key must stay in the dependency index: while it is cached,
+// or while other keys depend on it, so that an evicted or expired entry in the middle of
+// a chain does not cut its dependents off from Invalidate on its parents
+func (sc *StrategicCache) keepDependent(key string) bool {
+	return sc.isLive(key) || sc.deps.Load().hasTag(key)
+}
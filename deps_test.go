@@ -0,0 +1,154 @@
+// deps_test.go: Tests for dependency-based invalidation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestInvalidate_Diamond(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			// report:2024 -> page:1, page:2 -> summary (depends on both pages)
+			cache.Set("report:2024", "r")
+			cache.SetWithOptions("report:2024:page:1", "p1", WithDependsOn("report:2024"))
+			cache.SetWithOptions("report:2024:page:2", "p2", WithDependsOn("report:2024"))
+			cache.SetWithOptions("report:2024:summary", "s", WithDependsOn("report:2024:page:1", "report:2024:page:2"))
+			cache.SetWithOptions("report:2023:page:1", "old", WithDependsOn("report:2023"))
+
+			if stats := cache.GetStats(); stats.DependentKeys != 4 {
+				t.Errorf("expected 4 dependent keys, got %+v", stats)
+			}
+			if n := cache.Invalidate("report:2024"); n != 4 {
+				t.Errorf("expected the report and its 3 dependents invalidated once each, got %d", n)
+			}
+			for _, key := range []string{"report:2024", "report:2024:page:1", "report:2024:page:2", "report:2024:summary"} {
+				if _, ok := cache.Get(key); ok {
+					t.Errorf("expected %s to be invalidated", key)
+				}
+			}
+			if _, ok := cache.Get("report:2023:page:1"); !ok {
+				t.Error("expected entries of other parents to survive")
+			}
+			if stats := cache.GetStats(); stats.DependentKeys != 1 {
+				t.Errorf("expected only the 2023 page indexed, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestInvalidate_UncachedParentsAndCycles(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	// The parent is only a name: invalidating it removes its dependents
+	cache.SetWithOptions("a", 1, WithDependsOn("group"))
+	cache.SetWithOptions("b", 2, WithDependsOn("a"))
+	cache.SetWithOptions("c", 3, WithDependsOn("b"))
+	cache.SetWithOptions("a", 1, WithDependsOn("group", "c")) // a -> b -> c -> a
+	if n := cache.Invalidate("group"); n != 3 {
+		t.Errorf("expected a, b and c invalidated, got %d", n)
+	}
+	if n := cache.Invalidate("missing"); n != 0 {
+		t.Errorf("expected nothing to invalidate, got %d", n)
+	}
+}
+
+func TestInvalidate_DepthLimit(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	cache.Set("k0", 0)
+	for i := 1; i <= MaxDependencyDepth+2; i++ {
+		cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithDependsOn(fmt.Sprintf("k%d", i-1)))
+	}
+	if n := cache.Invalidate("k0"); n != MaxDependencyDepth+1 {
+		t.Errorf("expected %d entries invalidated, got %d", MaxDependencyDepth+1, n)
+	}
+	if _, ok := cache.Get(fmt.Sprintf("k%d", MaxDependencyDepth+1)); !ok {
+		t.Error("expected dependents below the depth limit to survive")
+	}
+}
+
+func TestInvalidate_OverwriteAndEviction(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			cache.SetWithOptions("child", 1, WithDependsOn("parent"))
+			cache.Set("child", 2) // A Set without WithDependsOn drops the parents
+			if n := cache.Invalidate("parent"); n != 0 {
+				t.Errorf("expected the overwritten child to leave the parent, got %d invalidated", n)
+			}
+
+			// An expired entry in the middle of a chain still links its dependents
+			cache.SetWithOptions("mid", 1, WithDependsOn("root"), WithTTL(time.Second))
+			cache.SetWithOptions("leaf", 2, WithDependsOn("mid"))
+			clock.Advance(2 * time.Second)
+			cache.deps.Load().prune(cache.keepDependent) // As the cleanup sweep would
+			cache.Invalidate("root")
+			if _, ok := cache.Get("leaf"); ok {
+				t.Error("expected the leaf invalidated through its expired parent")
+			}
+		})
+	}
+}
+
+func TestDependencies_EvictionsLeaveTheIndex(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 2000; i++ {
+				cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithDependsOn(fmt.Sprintf("parent%d", i%10)))
+			}
+			if stats := cache.GetStats(); stats.DependentKeys != cache.Len() {
+				t.Errorf("expected the index to hold the %d cached keys, got %d", cache.Len(), stats.DependentKeys)
+			}
+		})
+	}
+}
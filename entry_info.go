@@ -0,0 +1,167 @@
+// entry_info.go: Per-key metadata for debugging
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "time"
+
+// EntryInfo describes a cached entry without its value. Fields a storage path does
+// not keep are left zero: W-TinyLFU and ARC do not record write times or access
+// counts, and track LastAccess only with MaxIdleTime.
+type EntryInfo struct {
+	Key        string        `json:"key"`
+	Size       int           `json:"size"`                  // Estimated bytes, as stored (after compression)
+	Cost       int64         `json:"cost"`                  // Weight against CacheSize
+	Compressed bool          `json:"compressed"`            // Stored compressed (sharded path only)
+	WrittenAt  time.Time     `json:"written_at"`            // Last Set, zero if not tracked
+	ExpiresAt  time.Time     `json:"expires_at"`            // Zero for entries that never expire
+	LastAccess time.Time     `json:"last_access"`           // Last read or write, zero if not tracked
+	SlidingTTL time.Duration `json:"sliding_ttl,omitempty"` // TTL restarted by each read, 0 = fixed
+	// AccessCount counts reads and writes on the sharded path. Frequency is the
+	// W-TinyLFU admission sketch's estimate, which ages with the sketch.
+	AccessCount int64    `json:"access_count,omitempty"`
+	Frequency   uint32   `json:"frequency,omitempty"`
+	Pinned      bool     `json:"pinned"`
+	Priority    Priority `json:"priority"`
+	// Segment is where the entry sits: "window", "probation" or "protected" on
+	// W-TinyLFU, "t1" or "t2" on ARC, empty on the sharded path
+	Segment string `json:"segment,omitempty"`
+}
+
+// GetEntryInfo returns the metadata of a live entry without reading its value:
+// it counts no access, moves nothing in the eviction order and decodes nothing.
+// It reports false for missing and expired keys.
+func (sc *StrategicCache) GetEntryInfo(key string) (EntryInfo, bool) {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return EntryInfo{}, false
+	}
+	sc.closedMu.RUnlock()
+
+	if sc.usesWTinyLFU() {
+		return sc.wtinylfu.entryInfo(key)
+	}
+	if sc.arc != nil {
+		return sc.arc.entryInfo(key)
+	}
+
+	shard := sc.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.drainReads()
+	entry, exists := shard.data[key]
+	if !exists {
+		return EntryInfo{}, false
+	}
+	if expired, _ := sc.expiry(entry, sc.clock.Now()); expired {
+		return EntryInfo{}, false
+	}
+	return EntryInfo{
+		Key:         key,
+		Size:        entry.Size,
+		Cost:        entry.cost(),
+		Compressed:  entry.Compressed,
+		WrittenAt:   unixTime(entry.writtenAt),
+		ExpiresAt:   entry.Timestamp,
+		LastAccess:  entry.LastAccess,
+		SlidingTTL:  entry.slide,
+		AccessCount: entry.AccessCount,
+		Pinned:      entry.pinned,
+		Priority:    entry.Priority,
+	}, true
+}
+
+// entryInfo returns the metadata of a live entry without counting an access
+func (wt *WTinyLFU) entryInfo(key string) (EntryInfo, bool) {
+	shard := wt.getShard(key)
+	now := shard.clock.Now().UnixNano()
+	shard.readMu.RLock()
+	defer shard.readMu.RUnlock()
+
+	segments := []struct {
+		name string
+		lru  *FastLRU
+	}{
+		{"window", shard.windowCache},
+		{"probation", shard.mainCache.probation},
+		{"protected", shard.mainCache.protected},
+	}
+	for _, segment := range segments {
+		if info, ok := segment.lru.entryInfo(key, now); ok {
+			info.Segment = segment.name
+			info.Frequency = shard.admissionFilter.Estimate(key)
+			return info, true
+		}
+	}
+	return EntryInfo{}, false
+}
+
+// entryInfo returns the metadata of a live item without counting an access
+func (lru *FastLRU) entryInfo(key string, now int64) (EntryInfo, bool) {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+
+	node, exists := lru.data[key]
+	if !exists {
+		return EntryInfo{}, false
+	}
+	if expired, _ := lru.expiry(node, now); expired {
+		return EntryInfo{}, false
+	}
+	return EntryInfo{
+		Key:        key,
+		Size:       node.size,
+		Cost:       node.cost,
+		ExpiresAt:  unixTime(node.expiresAt),
+		LastAccess: unixTime(node.accessedAt),
+		SlidingTTL: time.Duration(node.slide),
+		Pinned:     node.pinned,
+		Priority:   node.priority,
+	}, true
+}
+
+// entryInfo returns the metadata of a live resident entry without counting an access
+func (arc *ARC) entryInfo(key string) (EntryInfo, bool) {
+	shard := arc.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, exists := shard.items[key]
+	if !exists {
+		return EntryInfo{}, false
+	}
+	entry := elem.Value.(*arcEntry)
+	segment := "t1"
+	switch entry.list {
+	case shard.t1:
+	case shard.t2:
+		segment = "t2"
+	default:
+		return EntryInfo{}, false // Ghost keys hold no value
+	}
+	if expired, _ := shard.expiry(entry, shard.clock.Now().UnixNano()); expired {
+		return EntryInfo{}, false
+	}
+	return EntryInfo{
+		Key:        key,
+		Size:       entry.size,
+		Cost:       1,
+		ExpiresAt:  unixTime(entry.expiresAt),
+		LastAccess: unixTime(entry.accessedAt),
+		SlidingTTL: time.Duration(entry.slide),
+		Pinned:     entry.pinned,
+		Segment:    segment,
+	}, true
+}
+
+// unixTime converts UnixNano to a time, keeping 0 as the zero time
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
@@ -0,0 +1,121 @@
+// entry_info_test.go: Tests for entry metadata inspection
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestGetEntryInfo_Sharded(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         1000,
+		TTL:               time.Minute,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		Clock:             clock,
+	})
+	defer cache.Close()
+	written := clock.Now()
+	cache.SetWithOptions("doc", strings.Repeat("metis ", 100), WithPriority(PriorityHigh))
+	clock.Advance(10 * time.Second)
+	cache.Get("doc")
+	cache.Pin("doc")
+
+	info, ok := cache.GetEntryInfo("doc")
+	if !ok {
+		t.Fatal("expected info for a cached key")
+	}
+	if !info.WrittenAt.Equal(written) || !info.ExpiresAt.Equal(written.Add(time.Minute)) || !info.LastAccess.Equal(clock.Now()) {
+		t.Errorf("unexpected times: written %v, expires %v, accessed %v", info.WrittenAt, info.ExpiresAt, info.LastAccess)
+	}
+	if !info.Compressed || info.Size == 0 || info.Size >= 600 {
+		t.Errorf("expected a compressed size under the raw 600 bytes, got %+v", info)
+	}
+	if info.AccessCount != 2 || !info.Pinned || info.Priority != PriorityHigh || info.Cost != 1 {
+		t.Errorf("expected 2 accesses, pinned, high priority, cost 1, got %+v", info)
+	}
+
+	// Inspecting is not an access
+	hits := cache.GetStats().Hits
+	if again, _ := cache.GetEntryInfo("doc"); again.AccessCount != 2 || cache.GetStats().Hits != hits {
+		t.Error("expected GetEntryInfo not to count an access")
+	}
+
+	// A rewrite moves WrittenAt
+	clock.Advance(10 * time.Second)
+	cache.Set("doc", "short")
+	if info, _ := cache.GetEntryInfo("doc"); !info.WrittenAt.Equal(clock.Now()) {
+		t.Errorf("expected the rewrite time, got %v", info.WrittenAt)
+	}
+}
+
+func TestGetEntryInfo_Policies(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+			cache.SetWithOptions("a", "value", WithSlidingTTL(30*time.Second))
+
+			info, ok := cache.GetEntryInfo("a")
+			if !ok || info.Key != "a" || info.Size == 0 {
+				t.Fatalf("expected info for a cached key, got %+v (found %v)", info, ok)
+			}
+			if !info.ExpiresAt.Equal(clock.Now().Add(30*time.Second)) || info.SlidingTTL != 30*time.Second {
+				t.Errorf("expected a 30s sliding TTL, got %+v", info)
+			}
+			if policy != "lru" && info.Segment == "" {
+				t.Errorf("expected the %s segment to be reported", policy)
+			}
+			if policy == "wtinylfu" && info.Frequency == 0 {
+				t.Error("expected the sketch frequency on W-TinyLFU")
+			}
+
+			if _, ok := cache.GetEntryInfo("missing"); ok {
+				t.Error("expected no info for a missing key")
+			}
+			clock.Advance(time.Minute)
+			if _, ok := cache.GetEntryInfo("a"); ok {
+				t.Error("expected no info for an expired key")
+			}
+		})
+	}
+}
+
+func TestEntryInfo_JSON(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	cache.Set("a", 1)
+	info, _ := cache.GetEntryInfo("a")
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded EntryInfo
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Key != "a" || !decoded.ExpiresAt.Equal(info.ExpiresAt) {
+		t.Errorf("expected the info to round-trip through JSON, got %s (err %v)", data, err)
+	}
+}
@@ -9,38 +9,62 @@ package metis
 import (
 	"container/list"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// PoolStats reports how well an object pool recycles: the sharded path's EntryPool,
+// or the nodes of W-TinyLFU (see CacheStats.EntryPool)
+type PoolStats struct {
+	Gets int64 `json:"gets"` // Objects taken from the pool
+	Puts int64 `json:"puts"` // Objects returned for reuse
+	News int64 `json:"news"` // Gets the pool had nothing for, which allocated
+	// ReuseRatio is the share of Gets served by a recycled object (0 before any Get)
+	ReuseRatio float64 `json:"reuse_ratio"`
+}
+
+// newPoolStats fills in the reuse ratio of a pool's counters
+func newPoolStats(gets, puts, news int64) PoolStats {
+	stats := PoolStats{Gets: gets, Puts: puts, News: news}
+	if gets > 0 {
+		stats.ReuseRatio = float64(gets-news) / float64(gets)
+	}
+	return stats
+}
+
+// add sums the counters of two pools
+func (ps PoolStats) add(other PoolStats) PoolStats {
+	return newPoolStats(ps.Gets+other.Gets, ps.Puts+other.Puts, ps.News+other.News)
+}
+
 // EntryPool manages a pool of CacheEntry objects for reuse
 type EntryPool struct {
-	pool sync.Pool
+	pool             sync.Pool
+	gets, puts, news atomic.Int64
 }
 
 // NewEntryPool creates a new EntryPool
 func NewEntryPool() *EntryPool {
-	return &EntryPool{
-		pool: sync.Pool{
-			New: func() interface{} {
-				return &CacheEntry{
-					Data:        nil,
-					Timestamp:   time.Time{},
-					AccessCount: 0,
-					llElem:      nil,
-					Key:         "",
-					IsNil:       false,
-				}
-			},
-		},
+	ep := &EntryPool{}
+	ep.pool.New = func() interface{} {
+		ep.news.Add(1)
+		return &CacheEntry{}
 	}
+	return ep
 }
 
 // Get retrieves a CacheEntry from the pool
 func (ep *EntryPool) Get() *CacheEntry {
+	ep.gets.Add(1)
 	entry := ep.pool.Get().(*CacheEntry)
 	return entry
 }
 
+// Stats reports the pool's traffic since it was created
+func (ep *EntryPool) Stats() PoolStats {
+	return newPoolStats(ep.gets.Load(), ep.puts.Load(), ep.news.Load())
+}
+
 // Put returns a CacheEntry to the pool after resetting its fields
 func (ep *EntryPool) Put(entry *CacheEntry) {
 	if entry == nil {
@@ -53,7 +77,15 @@ func (ep *EntryPool) Put(entry *CacheEntry) {
 	entry.llElem = nil
 	entry.Key = ""
 	entry.IsNil = false
-
+	entry.Cost = 0
+	entry.rawSize = 0
+	entry.compressionSkipped = false
+	entry.slide = 0
+	entry.pinned = false
+	entry.Priority = PriorityNormal
+	entry.writtenAt = 0
+
+	ep.puts.Add(1)
 	ep.pool.Put(entry) // Return the *same* entry to the pool
 }
 
@@ -115,4 +147,7 @@ func (ep *EntryPool) ResetEntry(entry *CacheEntry) {
 	entry.llElem = nil
 	entry.Key = ""
 	entry.IsNil = false
+	entry.Cost = 0
+	entry.rawSize = 0
+	entry.compressionSkipped = false
 }
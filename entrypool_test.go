@@ -7,6 +7,7 @@
 package metis
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -240,3 +241,101 @@ func TestEntryPool_Reuse(t *testing.T) {
 		t.Error("Expected IsNil to be false")
 	}
 }
+
+func TestEntryPool_Stats(t *testing.T) {
+	pool := NewEntryPool()
+	if stats := pool.Stats(); stats != (PoolStats{}) {
+		t.Errorf("expected zero stats for a new pool, got %+v", stats)
+	}
+
+	entry := pool.Get()
+	pool.Put(entry)
+	pool.Get()
+	stats := pool.Stats()
+	if stats.Gets != 2 || stats.Puts != 1 {
+		t.Errorf("expected 2 gets and 1 put, got %+v", stats)
+	}
+	if stats.News < 1 || stats.News > 2 {
+		t.Errorf("expected 1 or 2 allocations, got %d", stats.News)
+	}
+	if want := float64(stats.Gets-stats.News) / float64(stats.Gets); stats.ReuseRatio != want {
+		t.Errorf("expected a reuse ratio of %v, got %v", want, stats.ReuseRatio)
+	}
+}
+
+// churn overwrites a small cache with distinct keys, so every Set evicts
+func churn(cache *StrategicCache, n int) {
+	for i := 0; i < n; i++ {
+		cache.Set(fmt.Sprintf("churn_%d", i), i)
+	}
+}
+
+func TestEntryPool_ReusedUnderChurn(t *testing.T) {
+	if raceEnabled {
+		t.Skip("reuse needs sync.Pool to keep items")
+	}
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, ShardCount: 1, EvictionPolicy: policy, TTL: time.Hour})
+			defer cache.Close()
+
+			churn(cache, 5000)
+			stats := cache.GetStats().EntryPool
+			if stats.Gets < 4000 || stats.Puts < 4000 {
+				t.Fatalf("expected the pool used by every insert and eviction, got %+v", stats)
+			}
+			if stats.ReuseRatio < 0.5 {
+				t.Errorf("expected most inserts served by evicted objects, got %+v", stats)
+			}
+
+			cache.ResetStats()
+			if got := cache.GetStats().EntryPool; got.Gets < stats.Gets {
+				t.Errorf("expected pool stats kept across ResetStats, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestEntryPool_RecycledNodesKeepValues(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 50, ShardCount: 1, EvictionPolicy: "wtinylfu", TTL: time.Hour})
+	defer cache.Close()
+
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("k%d", i)
+			cache.Set(key, key)
+			if i%3 == 0 {
+				cache.Delete(fmt.Sprintf("k%d", i/2))
+			}
+		}
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("k%d", i)
+			if value, ok := cache.Get(key); ok && value != key {
+				t.Fatalf("round %d: expected %s to hold its own value, got %v", round, key, value)
+			}
+		}
+	}
+	cache.Clear()
+	churn(cache, 200)
+	if n := cache.Len(); n == 0 || n > 50 {
+		t.Errorf("expected the cache usable after Clear, got %d entries", n)
+	}
+}
+
+func BenchmarkChurn(b *testing.B) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		b.Run(policy, func(b *testing.B) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 4, EvictionPolicy: policy, TTL: time.Hour})
+			defer cache.Close()
+			keys := make([]string, 1<<16)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("churn_%d", i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(keys[i&(len(keys)-1)], i)
+			}
+		})
+	}
+}
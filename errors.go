@@ -0,0 +1,51 @@
+// errors.go: Sentinel errors for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by SetE, GetE and configuration validation; match them with errors.Is
+var (
+	ErrCachingDisabled = errors.New("metis: caching is disabled")
+	ErrCacheClosed     = errors.New("metis: cache is closed")
+	ErrKeyTooLarge     = errors.New("metis: key exceeds MaxKeySize")
+	// ErrValueTooLarge covers MaxValueSize, a shard's memory budget and entries costlier than a shard
+	ErrValueTooLarge   = errors.New("metis: value too large")
+	ErrNotSerializable = errors.New("metis: value cannot be serialized")
+	ErrNotAdmitted     = errors.New("metis: value rejected by the admission policy")
+	ErrNotFound        = errors.New("metis: key not found")
+	// ErrNotAdmittedFrequency is returned on W-TinyLFU when the admission filter estimated
+	// the entry the value would displace more frequent; it matches ErrNotAdmitted too
+	ErrNotAdmittedFrequency = fmt.Errorf("%w: a more frequent entry was kept", ErrNotAdmitted)
+	// ErrExpired is returned when the entry's TTL elapsed; caches that expire entries
+	// in the background may report ErrNotFound instead
+	ErrExpired = errors.New("metis: key expired")
+	// ErrInvalidConfig wraps every error returned by CacheConfig.Validate and NewStrategicCacheE
+	ErrInvalidConfig = errors.New("metis: invalid configuration")
+	// ErrImmutableConfig is returned by UpdateConfig for settings fixed at construction
+	ErrImmutableConfig = errors.New("metis: setting cannot change at runtime")
+	// ErrBackend wraps errors returned by CacheConfig.Backend, so they can be told apart from misses
+	ErrBackend = errors.New("metis: backend error")
+	// ErrInvalidSnapshot is returned by LoadFromFile for truncated, corrupt or unknown snapshot files
+	ErrInvalidSnapshot = errors.New("metis: invalid snapshot")
+	// ErrLoaderPanicked is returned by LoadOrCompute to callers that waited on a loader
+	// which panicked; the panic itself propagates in the goroutine that ran it
+	ErrLoaderPanicked = errors.New("metis: loader panicked")
+	// ErrInvalidShard is returned by ClearShard for an index outside the shards ShardStats reports
+	ErrInvalidShard = errors.New("metis: shard index out of range")
+)
+
+// admitted maps the bool result of a policy-specific Set to an error
+func admitted(ok bool) error {
+	if !ok {
+		return ErrNotAdmitted
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// errors_test.go: Tests for SetE and GetE sentinel errors
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSetE_SentinelErrors(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:  true,
+			CacheSize:      100,
+			ShardCount:     1,
+			EvictionPolicy: policy,
+			MaxKeySize:     8,
+			MaxValueSize:   16,
+		})
+
+		if err := cache.SetE("key", "value"); err != nil {
+			t.Errorf("%s: expected success, got %v", policy, err)
+		}
+		if err := cache.SetE("a-very-long-key", "value"); !errors.Is(err, ErrKeyTooLarge) {
+			t.Errorf("%s: expected ErrKeyTooLarge, got %v", policy, err)
+		}
+		if err := cache.SetE("key", strings.Repeat("x", 32)); !errors.Is(err, ErrValueTooLarge) {
+			t.Errorf("%s: expected ErrValueTooLarge, got %v", policy, err)
+		}
+
+		cache.Close()
+		if err := cache.SetE("key", "value"); !errors.Is(err, ErrCacheClosed) {
+			t.Errorf("%s: expected ErrCacheClosed, got %v", policy, err)
+		}
+		if cache.Set("key", "value") {
+			t.Errorf("%s: expected Set to keep returning false on a closed cache", policy)
+		}
+	}
+}
+
+func TestSetE_NotSerializableAndNotAdmitted(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru"})
+	defer cache.Close()
+
+	if err := cache.SetE("fn", func() {}); !errors.Is(err, ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable for a func, got %v", err)
+	}
+
+	compressed := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru", EnableCompression: true})
+	defer compressed.Close()
+	type unregistered struct{ A int }
+	if err := compressed.SetE("struct", unregistered{A: 1}); !errors.Is(err, ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable for an unregistered gob type, got %v", err)
+	}
+
+	never := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru", AdmissionPolicy: "never"})
+	defer never.Close()
+	if err := never.SetE("key", "value"); !errors.Is(err, ErrNotAdmitted) {
+		t.Errorf("expected ErrNotAdmitted, got %v", err)
+	}
+
+	disabled := NewStrategicCache(CacheConfig{CacheSize: 10})
+	defer disabled.Close()
+	if err := disabled.SetE("key", "value"); !errors.Is(err, ErrCachingDisabled) {
+		t.Errorf("expected ErrCachingDisabled, got %v", err)
+	}
+}
+
+func TestGetE_SentinelErrors(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       10,
+		ShardCount:      1,
+		EvictionPolicy:  "lru",
+		TTL:             20 * time.Millisecond,
+		CleanupInterval: time.Hour,
+		Clock:           clock,
+	})
+
+	cache.Set("key", "value")
+	if value, err := cache.GetE("key"); err != nil || value != "value" {
+		t.Errorf("expected the value, got %v, %v", value, err)
+	}
+	if _, err := cache.GetE("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	clock.Advance(40 * time.Millisecond)
+	if _, err := cache.GetE("key"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected Get to report the expired key as missing")
+	}
+
+	cache.Close()
+	if _, err := cache.GetE("key"); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed, got %v", err)
+	}
+
+	wt := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, EvictionPolicy: "wtinylfu"})
+	defer wt.Close()
+	if _, err := wt.GetE("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound from W-TinyLFU, got %v", err)
+	}
+}
+
+func TestSetWithOptionsE_AdmissionReasons(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     1,
+		TTL:            time.Minute,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+
+	// Fill the cache with keys read often enough to beat any newcomer
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		cache.Set(key, i)
+		for j := 0; j < 5; j++ {
+			cache.Get(key)
+		}
+	}
+
+	// Too costly for the window, and for the room left in main, so it contests main
+	// against a hotter victim
+	err := cache.SetWithOptionsE("cold", "value", WithCost(50))
+	if !errors.Is(err, ErrNotAdmittedFrequency) || !errors.Is(err, ErrNotAdmitted) {
+		t.Fatalf("expected ErrNotAdmittedFrequency, got %v", err)
+	}
+	if n := cache.wtinylfu.AdmissionRejects(); n == 0 {
+		t.Error("expected the rejection to be counted")
+	}
+	if n := cache.wtinylfu.Stats()["admission_rejects"]; n != cache.wtinylfu.AdmissionRejects() {
+		t.Errorf("expected admission_rejects in Stats, got %v", n)
+	}
+
+	// Costlier than the whole cache: a size rejection, not an admission one
+	err = cache.SetWithOptionsE("huge", "value", WithCost(1000))
+	if !errors.Is(err, ErrValueTooLarge) || errors.Is(err, ErrNotAdmitted) {
+		t.Errorf("expected ErrValueTooLarge, got %v", err)
+	}
+
+	cache.ResetStats()
+	if n := cache.wtinylfu.AdmissionRejects(); n != 0 {
+		t.Errorf("expected ResetStats to zero admission rejects, got %d", n)
+	}
+}
@@ -0,0 +1,132 @@
+// estimate.go: Memory footprint estimation for capacity planning
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/list"
+	"reflect"
+)
+
+// estimatedKeyLen is the key length EstimateMemory assumes, in bytes
+const estimatedKeyLen = 32
+
+// Go maps keep 8 slots per bucket, with a tophash byte per slot and an overflow
+// pointer, and grow once they average 6.5 entries per bucket
+const (
+	mapBucketSlots = 8
+	mapLoadFactor  = 6.5
+)
+
+// MemoryEstimate is the approximate heap used by a full cache, broken down by structure.
+// It is computed from the sizes of the cache's own types, so it follows them as they change.
+type MemoryEstimate struct {
+	Policy     string `json:"policy"`      // Storage path the configuration selects: lru, wtinylfu or arc
+	Entries    int    `json:"entries"`     // Entries in a full cache (CacheSize)
+	ShardCount int    `json:"shard_count"` // Shards actually built (rounded up to a power of two by W-TinyLFU and ARC)
+	// EntryOverhead is the per-entry bookkeeping (entry structs, list nodes and, for ARC,
+	// the ghost entries remembering evicted keys)
+	EntryOverhead int64 `json:"entry_overhead_bytes"`
+	MapOverhead   int64 `json:"map_overhead_bytes"` // Hash map buckets indexing the entries
+	KeyBytes      int64 `json:"key_bytes"`          // Key contents, assuming 32-byte keys
+	ValueBytes    int64 `json:"value_bytes"`        // Value contents and their interface boxing
+	// SketchBytes is the frequency sketches and doorkeepers of W-TinyLFU or "tinylfu" admission
+	SketchBytes int64 `json:"sketch_bytes"`
+	ShardBytes  int64 `json:"shard_bytes"` // Fixed per-shard structures
+	TotalBytes  int64 `json:"total_bytes"`
+}
+
+// PerEntry returns the total bytes divided by the number of entries
+func (e MemoryEstimate) PerEntry() float64 {
+	if e.Entries == 0 {
+		return 0
+	}
+	return float64(e.TotalBytes) / float64(e.Entries)
+}
+
+// EstimateMemory estimates the heap used by a cache built from config once it holds
+// CacheSize entries whose values average avgValueSize bytes as stored, that is after
+// compression when EnableCompression is set. Defaults are applied as NewStrategicCache
+// applies them. The figure leaves out Go runtime overhead such as allocator size classes
+// and garbage awaiting collection, which typically add 10-30%.
+func EstimateMemory(config CacheConfig, avgValueSize int) MemoryEstimate {
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+	if config.ShardCount <= 0 {
+		config.ShardCount = 32
+	}
+	if avgValueSize < 0 {
+		avgValueSize = 0
+	}
+
+	e := MemoryEstimate{Entries: config.CacheSize, ShardCount: config.ShardCount}
+	n := int64(config.CacheSize)
+	e.KeyBytes = n * estimatedKeyLen
+	// Values are held in an interface: strings and byte slices box their header too
+	e.ValueBytes = n * (int64(avgValueSize) + sizeOf[[]byte]())
+	pointerMap := mapBytesPerEntry(sizeOf[string](), sizeOf[uintptr]())
+
+	switch {
+	case config.EvictionPolicy == "wtinylfu" || (config.EvictionPolicy == "" || config.EvictionPolicy == "default") && config.CacheSize >= 1000:
+		e.Policy = "wtinylfu"
+		e.ShardCount = nextPowerOf2(config.ShardCount)
+		e.EntryOverhead = n * sizeOf[fastNode]()
+		e.MapOverhead = int64(float64(n) * pointerMap)
+		shardSize := max(1, config.CacheSize/e.ShardCount)
+		e.SketchBytes = int64(e.ShardCount) * sketchBytes(max(1, shardSize/10), config.SketchDepth, config.SketchWidth)
+		// Each shard owns a window and a two-segment main cache
+		e.ShardBytes = int64(e.ShardCount) * (sizeOf[WTinyLFUShard]() + 3*sizeOf[FastLRU]() + sizeOf[FastSLRU]() + sizeOf[FastTinyLFU]())
+	case config.EvictionPolicy == "arc":
+		e.Policy = "arc"
+		e.ShardCount = nextPowerOf2(config.ShardCount)
+		// The ghost lists remember up to CacheSize evicted keys, without their values
+		node := sizeOf[arcEntry]() + sizeOf[list.Element]()
+		e.EntryOverhead = 2 * n * node
+		e.MapOverhead = int64(2 * float64(n) * pointerMap)
+		e.KeyBytes *= 2
+		e.ShardBytes = int64(e.ShardCount) * (sizeOf[ARCShard]() + 4*sizeOf[list.List]())
+	default:
+		e.Policy = "lru"
+		e.EntryOverhead = n * (sizeOf[CacheEntry]() + sizeOf[list.Element]())
+		e.MapOverhead = int64(float64(n) * pointerMap)
+		if config.AdmissionPolicy == "tinylfu" {
+			maxShardSize := config.MaxShardSize
+			if maxShardSize <= 0 {
+				maxShardSize = config.CacheSize / config.ShardCount
+			}
+			e.SketchBytes = int64(config.ShardCount) * sketchBytes(max(1, maxShardSize), 0, 0)
+		}
+		e.ShardBytes = int64(config.ShardCount) * (sizeOf[cacheShard]() + sizeOf[list.List]())
+	}
+
+	e.TotalBytes = e.EntryOverhead + e.MapOverhead + e.KeyBytes + e.ValueBytes + e.SketchBytes + e.ShardBytes
+	return e
+}
+
+// mapBytesPerEntry is the average bucket space a map entry takes at the growth load factor
+func mapBytesPerEntry(keySize, valueSize int64) float64 {
+	bucket := mapBucketSlots*(1+keySize+valueSize) + sizeOf[uintptr]()
+	return float64(bucket) / mapLoadFactor
+}
+
+// sketchBytes is the size of the FastTinyLFU NewFastTinyLFUWithDimensions builds
+func sketchBytes(size, depth, width int) int64 {
+	if depth <= 0 {
+		depth = DefaultSketchDepth
+	}
+	if width <= 0 {
+		width = size * DefaultSketchWidthFactor
+	}
+	rows := int64(depth) * (int64(width)*sizeOf[uint32]() + sizeOf[[]uint32]())
+	doorkeeper := int64((nextPowerOf2(size*10*8)+63)/64) * sizeOf[uint64]()
+	return rows + doorkeeper
+}
+
+// sizeOf is the size in bytes of a value of type T, not counting memory it points to
+func sizeOf[T any]() int64 {
+	return int64(reflect.TypeOf((*T)(nil)).Elem().Size())
+}
@@ -0,0 +1,115 @@
+// estimate_test.go: Tests for memory estimation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestEstimateMemory_Breakdown(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			config := CacheConfig{CacheSize: 100000, ShardCount: 24, EvictionPolicy: policy}
+			e := EstimateMemory(config, 100)
+			if e.Policy != policy || e.Entries != 100000 {
+				t.Fatalf("unexpected estimate %+v", e)
+			}
+			if sum := e.EntryOverhead + e.MapOverhead + e.KeyBytes + e.ValueBytes + e.SketchBytes + e.ShardBytes; sum != e.TotalBytes {
+				t.Errorf("expected the parts to add up to %d, got %d", e.TotalBytes, sum)
+			}
+			if (e.SketchBytes > 0) != (policy == "wtinylfu") {
+				t.Errorf("expected a sketch only for wtinylfu, got %d bytes", e.SketchBytes)
+			}
+			if policy != "lru" && e.ShardCount != 32 {
+				t.Errorf("expected 24 shards rounded up to 32, got %d", e.ShardCount)
+			}
+
+			// Values add their size per entry, and twice the entries roughly double the total
+			bigger := EstimateMemory(config, 1100)
+			if bigger.ValueBytes-e.ValueBytes != 1000*100000 {
+				t.Errorf("expected 1000 more bytes per value, got %d", bigger.ValueBytes-e.ValueBytes)
+			}
+			config.CacheSize *= 2
+			if doubled := EstimateMemory(config, 100); doubled.TotalBytes < e.TotalBytes*19/10 {
+				t.Errorf("expected about twice %d bytes for twice the entries, got %d", e.TotalBytes, doubled.TotalBytes)
+			}
+		})
+	}
+}
+
+func TestEstimateMemory_SketchDimensions(t *testing.T) {
+	config := CacheConfig{CacheSize: 100000, ShardCount: 16, EvictionPolicy: "wtinylfu"}
+	base := EstimateMemory(config, 64).SketchBytes
+	config.SketchDepth = 8
+	if deeper := EstimateMemory(config, 64).SketchBytes; deeper <= base {
+		t.Errorf("expected a deeper sketch to be larger, got %d <= %d", deeper, base)
+	}
+	config.SketchDepth, config.SketchWidth = 0, 16
+	if narrow := EstimateMemory(config, 64).SketchBytes; narrow >= base {
+		t.Errorf("expected a narrow sketch to be smaller, got %d >= %d", narrow, base)
+	}
+
+	lru := CacheConfig{CacheSize: 100000, ShardCount: 16, EvictionPolicy: "lru", AdmissionPolicy: "tinylfu"}
+	if EstimateMemory(lru, 64).SketchBytes == 0 {
+		t.Error("expected a sketch for tinylfu admission")
+	}
+}
+
+func TestEstimateMemory_Defaults(t *testing.T) {
+	e := EstimateMemory(CacheConfig{}, -1)
+	if e.Entries != 10000 || e.ShardCount != 32 || e.Policy != "wtinylfu" || e.PerEntry() <= 0 {
+		t.Errorf("expected NewStrategicCache's defaults, got %+v", e)
+	}
+	if small := EstimateMemory(CacheConfig{CacheSize: 500}, 10); small.Policy != "lru" {
+		t.Errorf("expected small default caches on the lru path, got %s", small.Policy)
+	}
+	if (MemoryEstimate{}).PerEntry() != 0 {
+		t.Error("expected zero per entry for an empty estimate")
+	}
+}
+
+// TestEstimateMemory_MatchesHeap fills real caches and compares the heap they use with
+// the estimate, which must stay within a factor of two
+func TestEstimateMemory_MatchesHeap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("fills large caches")
+	}
+	const entries, valueSize = 50000, 200
+
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			config := CacheConfig{EnableCaching: true, CacheSize: entries, ShardCount: 16, TTL: time.Hour, EvictionPolicy: policy, DisableBackgroundCleanup: true}
+			before := heapInUse()
+			cache := NewStrategicCache(config)
+			for i := 0; i < entries*2; i++ { // Twice CacheSize, so ARC's ghost lists fill too
+				key := fmt.Sprintf("key:%028d", i) // 32 bytes, as the estimate assumes
+				cache.Set(key, make([]byte, valueSize))
+			}
+			measured := heapInUse() - before
+			runtime.KeepAlive(cache)
+			cache.Close()
+
+			estimated := EstimateMemory(config, valueSize).TotalBytes
+			if measured < estimated/2 || measured > estimated*2 {
+				t.Errorf("estimated %d bytes, measured %d", estimated, measured)
+			}
+			t.Logf("estimated %d bytes, measured %d", estimated, measured)
+		})
+	}
+}
+
+// heapInUse returns the live heap after a full collection
+func heapInUse() int64 {
+	runtime.GC()
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return int64(m.HeapAlloc)
+}
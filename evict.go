@@ -0,0 +1,131 @@
+// evict.go: Eviction notifications for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// evictedEntry is an entry removed to make room, waiting to be reported.
+// compressed marks sharded-path data that must be decoded first.
+type evictedEntry struct {
+	key        string
+	value      interface{}
+	compressed bool
+}
+
+// evictQueue buffers entries evicted while a shard is locked, so the handler runs
+// once the lock is released. push is a no-op until a handler is set.
+type evictQueue struct {
+	enabled atomic.Bool
+	pending atomic.Bool
+	mu      sync.Mutex
+	handler func(evictedEntry)
+	entries []evictedEntry
+}
+
+// setHandler replaces the handler; nil stops buffering and drops pending entries
+func (q *evictQueue) setHandler(fn func(evictedEntry)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handler = fn
+	q.enabled.Store(fn != nil)
+	if fn == nil {
+		q.entries = nil
+		q.pending.Store(false)
+	}
+}
+
+// push buffers an evicted entry. It may be called with the shard locked.
+func (q *evictQueue) push(e evictedEntry) {
+	if !q.enabled.Load() {
+		return
+	}
+	q.mu.Lock()
+	q.entries = append(q.entries, e)
+	q.pending.Store(true)
+	q.mu.Unlock()
+}
+
+// flush reports the buffered entries to the handler. It must be called without the shard
+// lock held and costs a single atomic load when nothing is pending.
+func (q *evictQueue) flush() {
+	if !q.pending.Load() {
+		return
+	}
+	q.mu.Lock()
+	entries, handler := q.entries, q.handler
+	q.entries = nil
+	q.pending.Store(false)
+	q.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+	for _, e := range entries {
+		handler(e)
+	}
+}
+
+// OnEvict sets fn to be called for each entry removed to make room for another, whether
+// for capacity, cost or the memory budget. Expired, deleted and cleared entries are not
+// reported. fn runs on the goroutine whose operation caused the eviction, after the cache
+// has released its locks, so it may call back into the cache. Passing nil removes the handler.
+func (sc *StrategicCache) OnEvict(fn func(key string, value interface{})) {
+	sc.evictMu.Lock()
+	sc.onEvict = fn
+	sc.evictMu.Unlock()
+	sc.installEvictHandler()
+}
+
+// installEvictHandler sets the handler of every storage path to report evictions to the
+// OnEvict function and remove evicted keys from the tag index, or clears it when neither
+// is in use, so that evictions are only buffered when someone listens
+func (sc *StrategicCache) installEvictHandler() {
+	sc.evictMu.Lock()
+	defer sc.evictMu.Unlock()
+	fn, tags, deps := sc.onEvict, sc.tags.Load(), sc.deps.Load()
+
+	var handler func(evictedEntry)
+	if fn != nil || tags != nil || deps != nil || sc.config.ChunkThreshold > 0 {
+		handler = func(e evictedEntry) {
+			if sc.config.ChunkThreshold > 0 {
+				sc.dropChunked(e)
+			}
+			if tags != nil {
+				tags.drop(e.key, sc.isLive)
+			}
+			if deps != nil {
+				deps.drop(e.key, sc.keepDependent)
+			}
+			if fn == nil || isInternal(e.value) {
+				return
+			}
+			value := e.value
+			if e.compressed {
+				decoded, ok := decodeCompressed(value, sc.serializer)
+				if !ok {
+					sc.logUndecodable(e.key)
+					return
+				}
+				value = decoded
+			}
+			fn(e.key, value)
+		}
+	}
+
+	if sc.wtinylfu != nil {
+		sc.wtinylfu.setEvictHandler(handler)
+	}
+	if sc.arc != nil {
+		sc.arc.setEvictHandler(handler)
+	}
+	for i := range sc.shards {
+		sc.shards[i].evictQueue.setHandler(handler)
+	}
+}
@@ -0,0 +1,144 @@
+// evict_test.go: Tests for OnEvict eviction notifications
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// evictRecorder collects OnEvict calls
+type evictRecorder struct {
+	mu      sync.Mutex
+	evicted map[string]interface{}
+}
+
+func newEvictRecorder() *evictRecorder {
+	return &evictRecorder{evicted: make(map[string]interface{})}
+}
+
+func (r *evictRecorder) record(key string, value interface{}) {
+	r.mu.Lock()
+	r.evicted[key] = value
+	r.mu.Unlock()
+}
+
+func (r *evictRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.evicted)
+}
+
+func TestOnEvict_AllPolicies(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:     true,
+				CacheSize:         20,
+				ShardCount:        1,
+				TTL:               time.Hour,
+				EvictionPolicy:    policy,
+				EnableCompression: policy == "lru",
+			})
+			defer cache.Close()
+
+			recorder := newEvictRecorder()
+			cache.OnEvict(recorder.record)
+			for i := 0; i < 200; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), fmt.Sprintf("v%d", i))
+			}
+
+			if recorder.count() == 0 {
+				t.Fatal("expected evictions to be reported")
+			}
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			for key, value := range recorder.evicted {
+				if want := "v" + key[1:]; value != want {
+					t.Errorf("expected %s to be reported with %q, got %v", key, want, value)
+				}
+				if _, ok := cache.Get(key); ok && policy != "wtinylfu" {
+					// W-TinyLFU may re-admit a key later; the others only evict once here
+					t.Errorf("expected evicted key %s to be gone", key)
+				}
+			}
+		})
+	}
+}
+
+func TestOnEvict_IgnoresDeleteAndExpiry(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     1,
+		TTL:            20 * time.Millisecond,
+		EvictionPolicy: "lru",
+		Clock:          clock,
+	})
+	defer cache.Close()
+
+	recorder := newEvictRecorder()
+	cache.OnEvict(recorder.record)
+	cache.Set("deleted", 1)
+	cache.Delete("deleted")
+	cache.Set("expired", 2)
+	clock.Advance(40 * time.Millisecond)
+	cache.Get("expired")
+
+	if n := recorder.count(); n != 0 {
+		t.Errorf("expected no evictions reported, got %d", n)
+	}
+}
+
+func TestOnEvict_CanCallBackIntoCache(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      4,
+		ShardCount:     1,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.OnEvict(func(key string, value interface{}) {
+			// Would deadlock if the handler ran with the shard locked
+			cache.Get(key)
+			cache.Delete("unrelated")
+		})
+		for i := 0; i < 100; i++ {
+			cache.Set(fmt.Sprintf("k%d", i), i)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler calling back into the cache deadlocked")
+	}
+}
+
+func TestOnEvict_RemoveHandler(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 4, ShardCount: 1, EvictionPolicy: "arc"})
+	defer cache.Close()
+
+	recorder := newEvictRecorder()
+	cache.OnEvict(recorder.record)
+	cache.OnEvict(nil)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if n := recorder.count(); n != 0 {
+		t.Errorf("expected no evictions after removing the handler, got %d", n)
+	}
+}
@@ -0,0 +1,368 @@
+// explain.go: Dry runs of the Set decision pipeline for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+)
+
+// Explanation is Explain's account of what Set would do with a key and value
+type Explanation struct {
+	Key    string `json:"key"`
+	Policy string `json:"policy"` // Eviction policy, as PolicyName reports it
+	Shard  int    `json:"shard"`
+	Size   int    `json:"size"` // Value size in bytes, as MaxValueSize measures it
+	// Stored reports whether Set would store the value. When it would not, Err is the
+	// error SetE would return.
+	Stored bool   `json:"stored"`
+	Err    error  `json:"-"`
+	Error  string `json:"error,omitempty"` // Err's message
+	// Update reports that the key is resident, so Set would replace its value in place
+	Update bool `json:"update"`
+	// Reason is the detail of the step that decided the outcome
+	Reason string `json:"reason"`
+	// Steps are the checks Set makes, in order, up to the first that fails
+	Steps []ExplainStep `json:"steps"`
+	// Frequency is the admission filter's estimate of the key's accesses, counting this
+	// Set; 0 when no filter is consulted
+	Frequency uint32 `json:"frequency,omitempty"`
+	// Victim is the entry Set would evict to make room or, when the admission filter
+	// rejects the value, the entry it keeps instead; nil when there is room
+	Victim *ExplainVictim `json:"victim,omitempty"`
+}
+
+// ExplainStep is one check of the Set pipeline
+type ExplainStep struct {
+	// Check is one of enabled, writable, key_size, value_size, chunking, serializable,
+	// admission, memory and capacity
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// ExplainVictim is an entry competing with the explained value for room in its shard
+type ExplainVictim struct {
+	Key string `json:"key"`
+	// Segment is where the entry lives: window, probation or protected on W-TinyLFU, T1
+	// or T2 on ARC, empty on the other policies
+	Segment   string `json:"segment,omitempty"`
+	Frequency uint32 `json:"frequency,omitempty"` // Admission filter estimate, 0 when not consulted
+}
+
+// pass records a check that Set would get through
+func (e *Explanation) pass(check, format string, args ...interface{}) {
+	e.Steps = append(e.Steps, ExplainStep{Check: check, Passed: true, Detail: fmt.Sprintf(format, args...)})
+}
+
+// reject records the check that would make Set fail with err
+func (e *Explanation) reject(check string, err error, format string, args ...interface{}) Explanation {
+	e.Steps = append(e.Steps, ExplainStep{Check: check, Detail: fmt.Sprintf(format, args...)})
+	e.Err, e.Error = err, err.Error()
+	e.Reason = e.Steps[len(e.Steps)-1].Detail
+	return *e
+}
+
+// store records that Set would store the value
+func (e *Explanation) store() Explanation {
+	e.Stored = true
+	e.Reason = e.Steps[len(e.Steps)-1].Detail
+	return *e
+}
+
+// Explain reports what Set(key, value) would do, without doing it: the size limits, the
+// admission policy's verdict, whether the shard is full and which entry would be evicted,
+// with the admission filter's frequency estimates when one decides. The cache and its
+// statistics are left unchanged, but the answer can go stale as soon as it is returned,
+// and a ProbabilisticAdmissionPolicy rolls its own dice, so a retry may differ. A custom
+// AdmissionPolicy is asked through Allow, as Set would. Values are explained with the
+// default cost and priority, and compression is not applied to their size.
+func (sc *StrategicCache) Explain(key string, value interface{}) Explanation {
+	hk := sc.HashKey(key)
+	e := Explanation{Key: key, Policy: sc.PolicyName(), Shard: int(hk.shard), Size: calculateSize(value)}
+
+	if !sc.config.EnableCaching {
+		return e.reject("enabled", ErrCachingDisabled, "EnableCaching is off")
+	}
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	if closed {
+		return e.reject("enabled", ErrCacheClosed, "the cache is closed")
+	}
+	e.pass("enabled", "caching is enabled")
+	if sc.readOnly.Load() {
+		return e.reject("writable", ErrReadOnly, "the cache is read-only (see SetReadOnly)")
+	}
+	e.pass("writable", "the cache accepts writes")
+
+	maxKeySize, maxValueSize := sc.sizeLimits()
+	if maxKeySize > 0 && len(key) > maxKeySize {
+		return e.reject("key_size", ErrKeyTooLarge, "the key is %d bytes, over MaxKeySize (%d)", len(key), maxKeySize)
+	}
+	e.pass("key_size", "the key is %d bytes%s", len(key), limitNote(maxKeySize, "MaxKeySize"))
+	if maxValueSize > 0 && e.Size > maxValueSize {
+		return e.reject("value_size", ErrValueTooLarge, "the value is %d bytes, over MaxValueSize (%d)", e.Size, maxValueSize)
+	}
+	e.pass("value_size", "the value is %d bytes%s", e.Size, limitNote(maxValueSize, "MaxValueSize"))
+
+	if sc.chunks(value) {
+		e.pass("chunking", "the value is over ChunkThreshold (%d bytes): it is split into chunks stored under their own keys, each admitted by the shard it hashes to",
+			sc.config.ChunkThreshold)
+		return e.store()
+	}
+	if sc.wtinylfu == nil && sc.arc == nil && value != nil {
+		if kind := reflect.TypeOf(value).Kind(); kind == reflect.Func || kind == reflect.Chan {
+			return e.reject("serializable", ErrNotSerializable, "a %s cannot be stored", kind)
+		}
+	}
+	if !sc.explainAdmission(&e, key, value) {
+		return e
+	}
+
+	switch {
+	case sc.usesWTinyLFU():
+		return sc.explainWTinyLFU(&e, hk)
+	case sc.arc != nil:
+		return sc.explainARC(&e, hk)
+	default:
+		return sc.explainSharded(&e, hk)
+	}
+}
+
+// limitNote describes a size limit for a passed check
+func limitNote(limit int, name string) string {
+	if limit <= 0 {
+		return fmt.Sprintf(", with no %s", name)
+	}
+	return fmt.Sprintf(", within %s (%d)", name, limit)
+}
+
+// explainAdmission records the admission policy's verdict, reporting whether it admits
+func (sc *StrategicCache) explainAdmission(e *Explanation, key string, value interface{}) bool {
+	switch p := sc.admissionPolicy().(type) {
+	case *AlwaysAdmitPolicy:
+		e.pass("admission", "the always policy admits every value")
+	case *TinyLFUAdmissionPolicy:
+		e.pass("admission", "the tinylfu policy decides against a victim once the shard is full (see capacity)")
+	case *NeverAdmitPolicy:
+		e.reject("admission", ErrNotAdmitted, "the never policy admits no value")
+		return false
+	case *ProbabilisticAdmissionPolicy:
+		if p.Probability >= 1 {
+			e.pass("admission", "the admission probability is %g: every value is admitted", p.Probability)
+			break
+		}
+		roll := 0.0
+		if p.Probability > 0 {
+			roll = SecureFloat64()
+		}
+		if roll >= p.Probability {
+			e.reject("admission", ErrNotAdmitted, "rolled %.3f, not under the admission probability %g; a retry rolls again", roll, p.Probability)
+			return false
+		}
+		e.pass("admission", "rolled %.3f, under the admission probability %g", roll, p.Probability)
+	case *SizeAwareAdmissionPolicy:
+		fill := 1.0
+		if p.FillRatio != nil {
+			fill = p.FillRatio(key)
+		}
+		switch {
+		case p.MaxSize <= 0:
+			e.pass("admission", "the size-aware policy has no MaxSize: every value is admitted")
+		case fill <= p.Utilization:
+			e.pass("admission", "the shard is %.0f%% full, not over %.0f%%: values of any size are admitted", fill*100, p.Utilization*100)
+		case e.Size <= p.MaxSize:
+			e.pass("admission", "the shard is %.0f%% full, over %.0f%%, but the value is within %d bytes", fill*100, p.Utilization*100, p.MaxSize)
+		default:
+			e.reject("admission", ErrNotAdmitted, "the shard is %.0f%% full, over %.0f%%, and the value is over %d bytes", fill*100, p.Utilization*100, p.MaxSize)
+			return false
+		}
+	default:
+		if !p.Allow(key, value) {
+			e.reject("admission", ErrNotAdmitted, "the custom policy %T rejects the value", p)
+			return false
+		}
+		e.pass("admission", "the custom policy %T admits the value", p)
+	}
+	return true
+}
+
+// explainSharded records the capacity decision of the LRU and custom policies
+func (sc *StrategicCache) explainSharded(e *Explanation, hk HashedKey) Explanation {
+	maxShardCost, err := sc.checkStorable(storedValue{size: e.Size}, setOptions{cost: 1})
+	if err != nil {
+		return e.reject("memory", err, "the value is larger than a shard's memory budget (%d bytes)", sc.shardMemoryBudget)
+	}
+
+	shard := &sc.shards[hk.shard]
+	shard.mu.Lock() // selectVictim applies buffered reads to the recency list
+	defer shard.mu.Unlock()
+
+	if _, ok := shard.data[hk.key]; ok {
+		e.Update = true
+		e.pass("capacity", "the key is resident in shard %d: its value is replaced in place", hk.shard)
+		return e.store()
+	}
+	if sc.shardMemoryBudget > 0 && shard.memoryBytes+int64(e.Size) > sc.shardMemoryBudget {
+		e.pass("memory", "shard %d holds %d of its %d byte budget: entries are evicted until the value fits", hk.shard, shard.memoryBytes, sc.shardMemoryBudget)
+	} else if sc.shardMemoryBudget > 0 {
+		e.pass("memory", "shard %d holds %d of its %d byte budget: the value fits", hk.shard, shard.memoryBytes, sc.shardMemoryBudget)
+	}
+
+	cost := shard.totalCost()
+	full := cost+1 > maxShardCost
+	if !full && !(sc.shardMemoryBudget > 0 && shard.memoryBytes+int64(e.Size) > sc.shardMemoryBudget) {
+		e.pass("capacity", "shard %d has room (%d of %d)", hk.shard, cost, maxShardCost)
+		return e.store()
+	}
+	victim := sc.selectVictim(shard)
+	if victim == "" {
+		e.pass("capacity", "shard %d is full but every entry is pinned: the value is stored over capacity", hk.shard)
+		return e.store()
+	}
+	e.Victim = &ExplainVictim{Key: victim}
+	if shard.sketch != nil && full {
+		e.Frequency = shard.sketch.Estimate(hk.key) + 1
+		e.Victim.Frequency = shard.sketch.Estimate(victim)
+		if e.Frequency < e.Victim.Frequency {
+			return e.reject("capacity", ErrNotAdmitted, "shard %d is full; TinyLFU estimates the key at %d accesses and the victim %q at %d: the victim is kept",
+				hk.shard, e.Frequency, victim, e.Victim.Frequency)
+		}
+		e.pass("capacity", "shard %d is full; TinyLFU estimates the key at %d accesses and the victim %q at %d: %q is evicted",
+			hk.shard, e.Frequency, victim, e.Victim.Frequency, victim)
+		return e.store()
+	}
+	e.pass("capacity", "shard %d is full (%d of %d): the %s victim %q is evicted", hk.shard, cost, maxShardCost, e.Policy, victim)
+	return e.store()
+}
+
+// explainWTinyLFU records where W-TinyLFU would place the value. New keys always enter the
+// window; a full window pushes its oldest entry into a contest for main.
+func (sc *StrategicCache) explainWTinyLFU(e *Explanation, hk HashedKey) Explanation {
+	shard := sc.wtinylfu.shards[hk.shard]
+	if shard.maxBytes > 0 && int64(e.Size) > shard.maxBytes {
+		return e.reject("memory", ErrValueTooLarge, "the value is larger than a shard's memory budget (%d bytes)", shard.maxBytes)
+	}
+
+	shard.writeMu.Lock()
+	defer shard.writeMu.Unlock()
+	filter := shard.admissionFilter
+	e.Frequency = filter.Estimate(hk.key) + 1
+
+	if shard.windowCache.Exists(hk.key) || shard.mainCache.Exists(hk.key) {
+		e.Update = true
+		e.pass("capacity", "the key is resident in shard %d: its value is replaced in place", hk.shard)
+		return e.store()
+	}
+	if shard.maxBytes > 0 && shard.MemoryBytes()+int64(e.Size) > shard.maxBytes {
+		e.pass("memory", "shard %d holds %d of its %d byte budget: probation entries are evicted first until the value fits", hk.shard, shard.MemoryBytes(), shard.maxBytes)
+	}
+	if windowCost := shard.windowCache.Cost(); windowCost+1 <= int64(shard.windowSize) {
+		e.pass("capacity", "the window of shard %d has room (%d of %d): the value enters the window", hk.shard, windowCost, shard.windowSize)
+		return e.store()
+	}
+
+	candidate, candidatePriority := shard.windowCache.oldest()
+	if candidate == "" {
+		e.pass("capacity", "the window of shard %d is full of pinned entries: the value enters it over capacity", hk.shard)
+		return e.store()
+	}
+	candidateFreq := filter.Estimate(candidate)
+	if shard.mainSize == 0 {
+		e.Victim = &ExplainVictim{Key: candidate, Segment: "window", Frequency: candidateFreq}
+		e.pass("capacity", "the value enters the window of shard %d, evicting its oldest entry %q: the shard has no main segment", hk.shard, candidate)
+		return e.store()
+	}
+
+	probation := shard.mainCache.probation
+	if shard.mainCache.Cost()+1 <= int64(shard.mainSize) && (probation.maxSize <= 0 || probation.Cost()+1 <= int64(probation.maxSize)) {
+		e.pass("capacity", "the value enters the window of shard %d; its oldest entry %q moves to probation, which has room", hk.shard, candidate)
+		return e.store()
+	}
+	victim, victimPriority := probation.oldest()
+	segment := "probation"
+	if victim == "" {
+		victim, victimPriority = shard.mainCache.protected.oldest()
+		segment = "protected"
+	}
+	if victim == "" {
+		e.pass("capacity", "the value enters the window of shard %d; its oldest entry %q moves to main, whose entries are all pinned", hk.shard, candidate)
+		return e.store()
+	}
+
+	victimFreq := filter.Estimate(victim)
+	var outcome string
+	switch {
+	case candidatePriority > victimPriority:
+		outcome = fmt.Sprintf("its higher priority moves %q to main and evicts %q", candidate, victim)
+	case candidatePriority < victimPriority:
+		outcome = fmt.Sprintf("the higher priority of %q keeps it and evicts %q", victim, candidate)
+	case candidateFreq >= victimFreq:
+		outcome = fmt.Sprintf("the admission filter moves %q to main and evicts %q", candidate, victim)
+	default:
+		outcome = fmt.Sprintf("the admission filter keeps %q and evicts %q", victim, candidate)
+	}
+	if candidatePriority > victimPriority || candidatePriority == victimPriority && candidateFreq >= victimFreq {
+		e.Victim = &ExplainVictim{Key: victim, Segment: segment, Frequency: victimFreq}
+	} else {
+		e.Victim = &ExplainVictim{Key: candidate, Segment: "window", Frequency: candidateFreq}
+	}
+	e.pass("capacity", "the value enters the window of shard %d, pushing out its oldest entry %q (estimated at %d accesses) against %s's oldest %q (%d): %s",
+		hk.shard, candidate, candidateFreq, segment, victim, victimFreq, outcome)
+	return e.store()
+}
+
+// explainARC records which entry ARC would demote to make room. ARC admits every value.
+func (sc *StrategicCache) explainARC(e *Explanation, hk HashedKey) Explanation {
+	shard := sc.arc.shards[hk.shard]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	var victim *list.Element
+	if elem, exists := shard.items[hk.key]; exists {
+		switch elem.Value.(*arcEntry).list {
+		case shard.t1, shard.t2:
+			e.Update = true
+			e.pass("capacity", "the key is resident in shard %d: its value is replaced in place and moves to T2", hk.shard)
+			return e.store()
+		case shard.b1:
+			p := min(shard.capacity, shard.p+max(1, shard.b2.Len()/max(1, shard.b1.Len())))
+			victim, _ = shard.replaceVictim(false, p)
+			e.pass("capacity", "the key was recently evicted from T1 (ghost in B1): T1's target grows to %d and the value enters T2", p)
+		case shard.b2:
+			p := max(0, shard.p-max(1, shard.b1.Len()/max(1, shard.b2.Len())))
+			victim, _ = shard.replaceVictim(true, p)
+			e.pass("capacity", "the key was recently evicted from T2 (ghost in B2): T1's target shrinks to %d and the value enters T2", p)
+		}
+	} else {
+		l1 := shard.t1.Len() + shard.b1.Len()
+		total := l1 + shard.t2.Len() + shard.b2.Len()
+		switch {
+		case l1 >= shard.capacity && shard.t1.Len() >= shard.capacity:
+			victim = shard.oldestUnpinned(shard.t1)
+		case l1 >= shard.capacity || total >= shard.capacity:
+			victim, _ = shard.replaceVictim(false, shard.p)
+		}
+		if victim == nil {
+			e.pass("capacity", "shard %d has room (%d of %d): the value enters T1", hk.shard, shard.t1.Len()+shard.t2.Len(), shard.capacity)
+			return e.store()
+		}
+		e.pass("capacity", "shard %d is full: the value enters T1", hk.shard)
+	}
+	if victim != nil {
+		entry := victim.Value.(*arcEntry)
+		segment := "T1"
+		if entry.list == shard.t2 {
+			segment = "T2"
+		}
+		e.Victim = &ExplainVictim{Key: entry.key, Segment: segment}
+		e.Steps[len(e.Steps)-1].Detail += fmt.Sprintf(", evicting %q from %s", entry.key, segment)
+	}
+	return e.store()
+}
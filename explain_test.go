@@ -0,0 +1,116 @@
+// explain_test.go: Tests for dry runs of the Set decision pipeline
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestExplain_Room(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy, MaxKeySize: 16})
+			defer cache.Close()
+			cache.Set("resident", 1)
+			before := cache.GetStats()
+
+			e := cache.Explain("fresh", "value")
+			if !e.Stored || e.Err != nil || e.Victim != nil || e.Update {
+				t.Errorf("expected the value stored without eviction, got %+v", e)
+			}
+			if e.Policy != policy || e.Size != 5 || e.Steps[len(e.Steps)-1].Check != "capacity" {
+				t.Errorf("expected the policy, size and a capacity verdict, got %+v", e)
+			}
+			if e := cache.Explain("resident", 2); !e.Stored || !e.Update {
+				t.Errorf("expected a resident key to be updated in place, got %+v", e)
+			}
+			if e := cache.Explain("a key longer than sixteen bytes", 1); e.Stored || !errors.Is(e.Err, ErrKeyTooLarge) || e.Steps[len(e.Steps)-1].Check != "key_size" {
+				t.Errorf("expected ErrKeyTooLarge from key_size, got %+v", e)
+			}
+
+			if _, ok := cache.Get("fresh"); ok || cache.Len() != 1 || cache.GetStats().Evictions != before.Evictions {
+				t.Error("expected Explain to leave the cache unchanged")
+			}
+		})
+	}
+}
+
+// TestExplain_PredictsVictim checks the victim Explain names is the one Set evicts
+func TestExplain_PredictsVictim(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, TTL: time.Minute, EvictionPolicy: policy})
+			defer cache.Close()
+			for i := 0; i < 40; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+				cache.Get(fmt.Sprintf("k%d", i%5))
+			}
+
+			e := cache.Explain("newcomer", 1)
+			if !e.Stored || e.Victim == nil {
+				t.Fatalf("expected a full shard to evict, got %+v", e)
+			}
+			if _, ok := cache.GetEntryInfo(e.Victim.Key); !ok {
+				t.Fatalf("expected the victim %q to be resident", e.Victim.Key)
+			}
+			cache.Set("newcomer", 1)
+			if _, ok := cache.GetEntryInfo(e.Victim.Key); ok {
+				t.Errorf("expected Set to evict %q as explained: %s", e.Victim.Key, e.Reason)
+			}
+		})
+	}
+}
+
+func TestExplain_TinyLFURejection(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 4, ShardCount: 1, TTL: time.Minute, EvictionPolicy: "lru", AdmissionPolicy: "tinylfu"})
+	defer cache.Close()
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		cache.Set(key, i)
+		for j := 0; j < 5; j++ {
+			cache.Get(key)
+		}
+	}
+
+	e := cache.Explain("cold", 1)
+	if e.Stored || !errors.Is(e.Err, ErrNotAdmitted) || e.Victim == nil {
+		t.Fatalf("expected TinyLFU to keep the victim, got %+v", e)
+	}
+	if e.Frequency >= e.Victim.Frequency {
+		t.Errorf("expected the key estimated below the victim, got %d and %d", e.Frequency, e.Victim.Frequency)
+	}
+	if err := cache.SetE("cold", 1); !errors.Is(err, ErrNotAdmitted) {
+		t.Errorf("expected SetE to agree, got %v", err)
+	}
+}
+
+func TestExplain_AdmissionPolicies(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", AdmissionPolicy: "never"})
+	defer cache.Close()
+	if e := cache.Explain("k", 1); e.Stored || !errors.Is(e.Err, ErrNotAdmitted) || e.Steps[len(e.Steps)-1].Check != "admission" {
+		t.Errorf("expected the never policy to reject, got %+v", e)
+	}
+
+	cache = NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", CustomAdmissionPolicy: &ProbabilisticAdmissionPolicy{Probability: 0}})
+	defer cache.Close()
+	if e := cache.Explain("k", 1); e.Stored || !errors.Is(e.Err, ErrNotAdmitted) {
+		t.Errorf("expected a zero probability to reject, got %+v", e)
+	}
+
+	cache = NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	cache.SetReadOnly(true)
+	if e := cache.Explain("k", 1); !errors.Is(e.Err, ErrReadOnly) || cache.GetStats().ReadOnlyRejected != 0 {
+		t.Errorf("expected ErrReadOnly without counting a rejection, got %+v", e)
+	}
+	cache.Close()
+	if e := cache.Explain("k", 1); !errors.Is(e.Err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed, got %+v", e)
+	}
+}
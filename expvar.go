@@ -0,0 +1,82 @@
+// expvar.go: expvar publishing for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"expvar"
+	"sync"
+)
+
+// expvarMu guards expvarCaches and serializes registrations so concurrent
+// PublishExpvar calls with the same name cannot panic
+var expvarMu sync.Mutex
+
+// expvarCaches maps each name PublishExpvar published to the open cache it reports.
+// expvar cannot unpublish a name, so closing a cache sets its names to nil here instead.
+var expvarCaches = make(map[string]*StrategicCache)
+
+// ExpvarSnapshot is the JSON-serializable value published on /debug/vars
+type ExpvarSnapshot struct {
+	Stats          CacheStats       `json:"stats"`
+	Compression    CompressionStats `json:"compression"`
+	ShardCount     int              `json:"shard_count"`
+	EvictionPolicy string           `json:"eviction_policy"`
+	TTL            string           `json:"ttl"`
+}
+
+// PublishExpvar registers the cache statistics under the given expvar name.
+// The snapshot is computed on every read of /debug/vars. Publishing under a name
+// an open cache holds is a no-op, so the first cache registered under a name keeps
+// it until it is closed. Closing releases the cache's names, which read null until
+// another cache publishes under them, and a closed cache publishes nothing.
+func (sc *StrategicCache) PublishExpvar(name string) {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	cache, published := expvarCaches[name]
+	if closed || cache != nil || (!published && expvar.Get(name) != nil) {
+		return
+	}
+	expvarCaches[name] = sc
+	if published {
+		return // The Func of an earlier cache reads the registry
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		expvarMu.Lock()
+		cache := expvarCaches[name]
+		expvarMu.Unlock()
+		if cache == nil {
+			return nil
+		}
+		return cache.expvarSnapshot()
+	}))
+}
+
+// unpublishExpvar releases every name the cache holds
+func (sc *StrategicCache) unpublishExpvar() {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	for name, cache := range expvarCaches {
+		if cache == sc {
+			expvarCaches[name] = nil
+		}
+	}
+}
+
+// expvarSnapshot builds the value returned to expvar readers
+func (sc *StrategicCache) expvarSnapshot() ExpvarSnapshot {
+	return ExpvarSnapshot{
+		Stats:          sc.GetStats(),
+		Compression:    sc.CompressionStats(),
+		ShardCount:     int(sc.shardCount),
+		EvictionPolicy: sc.PolicyName(),
+		TTL:            sc.entryTTL().String(),
+	}
+}
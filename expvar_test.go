@@ -0,0 +1,108 @@
+// expvar_test.go: Tests for expvar publishing
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestPublishExpvar_Snapshot(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		TTL:            time.Minute,
+		ShardCount:     4,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.PublishExpvar("metis_test_snapshot")
+
+	v := expvar.Get("metis_test_snapshot")
+	if v == nil {
+		t.Fatal("expected expvar to be published")
+	}
+
+	var snapshot ExpvarSnapshot
+	if err := json.Unmarshal([]byte(v.String()), &snapshot); err != nil {
+		t.Fatalf("expvar value is not valid JSON: %v", err)
+	}
+	if snapshot.Stats.Hits != 1 || snapshot.Stats.Misses != 1 || snapshot.Stats.Keys != 1 {
+		t.Errorf("unexpected stats in snapshot: %+v", snapshot.Stats)
+	}
+	if snapshot.ShardCount != 4 || snapshot.EvictionPolicy != "lru" || snapshot.TTL != "1m0s" {
+		t.Errorf("unexpected config highlights in snapshot: %+v", snapshot)
+	}
+}
+
+func TestPublishExpvar_MultipleCachesAndDuplicates(t *testing.T) {
+	first := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	defer first.Close()
+	second := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	defer second.Close()
+
+	first.Set("only-in-first", true)
+
+	first.PublishExpvar("metis_test_first")
+	second.PublishExpvar("metis_test_second")
+
+	// Publishing again under an existing name must not panic and must not replace the first cache
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("duplicate PublishExpvar panicked: %v", r)
+		}
+	}()
+	first.PublishExpvar("metis_test_first")
+	second.PublishExpvar("metis_test_first")
+
+	var snapshot ExpvarSnapshot
+	if err := json.Unmarshal([]byte(expvar.Get("metis_test_first").String()), &snapshot); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if snapshot.Stats.Keys != 1 {
+		t.Errorf("expected first cache to keep its name, got %d keys", snapshot.Stats.Keys)
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("metis_test_second").String()), &snapshot); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if snapshot.Stats.Keys != 0 {
+		t.Errorf("expected second cache to be empty, got %d keys", snapshot.Stats.Keys)
+	}
+}
+
+func TestPublishExpvar_CloseReleasesName(t *testing.T) {
+	first := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	first.Set("only-in-first", true)
+	first.PublishExpvar("metis_test_released")
+	first.Close()
+
+	if got := expvar.Get("metis_test_released").String(); got != "null" {
+		t.Errorf("expected a closed cache to read null, got %s", got)
+	}
+	first.PublishExpvar("metis_test_released")
+	if got := expvar.Get("metis_test_released").String(); got != "null" {
+		t.Errorf("expected a closed cache not to publish, got %s", got)
+	}
+
+	second := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "arc"})
+	defer second.Close()
+	second.PublishExpvar("metis_test_released")
+
+	var snapshot ExpvarSnapshot
+	if err := json.Unmarshal([]byte(expvar.Get("metis_test_released").String()), &snapshot); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if snapshot.EvictionPolicy != "arc" || snapshot.Stats.Keys != 0 {
+		t.Errorf("expected the name to report the second cache, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,105 @@
+// filecache.go: Filesystem-backed RemoteCache for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileCache is a RemoteCache storing one file per key in a directory, usable as the L2 of a
+// TieredCache. File names are hashes of the keys, so any key is safe to use. Writes go
+// through a temporary file and a rename, so readers never see a partial value.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewFileCache creates a FileCache in dir, creating the directory if needed.
+// Entries older than ttl read as missing and are removed; zero keeps them until deleted.
+func NewFileCache(dir string, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("metis: file cache: %w", err)
+	}
+	return &FileCache{dir: dir, ttl: ttl}, nil
+}
+
+// Get reads the value for key, or returns ErrNotFound
+func (fc *FileCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path := fc.path(key)
+	if fc.ttl > 0 {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if time.Since(info.ModTime()) > fc.ttl {
+			_ = os.Remove(path)
+			return nil, ErrNotFound
+		}
+	}
+
+	data, err := os.ReadFile(path) // nosec G304 - the name is a hash within fc.dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Set writes the value for key, replacing any previous one
+func (fc *FileCache) Set(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(fc.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fc.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the value for key; a missing key is not an error
+func (fc *FileCache) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(fc.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file holding key
+func (fc *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(fc.dir, hex.EncodeToString(sum[:]))
+}
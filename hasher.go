@@ -0,0 +1,57 @@
+// hasher.go: Shard selection hashing for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"hash/crc32"
+	"hash/maphash"
+)
+
+// Hasher maps keys to the hashes that select their shard on the sharded ("lru" and
+// custom policy) path; see CacheConfig.KeyHasher and CustomKeyHasher. Hash must be safe
+// for concurrent use and return the same hash for a key for the life of the cache.
+type Hasher interface {
+	Hash(key string) uint64
+}
+
+// maphashHasher is the default Hasher: maphash with a per-cache seed, which spreads
+// structured keys such as "user:000001" evenly
+type maphashHasher struct {
+	seed maphash.Seed
+}
+
+// Hash returns the seeded maphash of key
+func (h maphashHasher) Hash(key string) uint64 {
+	return maphash.String(h.seed, key)
+}
+
+// legacyHasher is the placement of earlier releases: a multiply-by-31 loop for keys of
+// up to 8 bytes and CRC32 for longer ones
+type legacyHasher struct{}
+
+// Hash returns the legacy hash of key
+func (legacyHasher) Hash(key string) uint64 {
+	if len(key) <= 8 {
+		var hash uint32
+		for i := 0; i < len(key); i++ {
+			hash = hash*31 + uint32(key[i])
+		}
+		return uint64(hash)
+	}
+	return uint64(crc32.ChecksumIEEE([]byte(key)))
+}
+
+// newHasher returns the Hasher selected by config
+func newHasher(config CacheConfig) Hasher {
+	if config.CustomKeyHasher != nil {
+		return config.CustomKeyHasher
+	}
+	if config.KeyHasher == "legacy" {
+		return legacyHasher{}
+	}
+	return maphashHasher{seed: maphash.MakeSeed()}
+}
@@ -0,0 +1,106 @@
+// hasher_test.go: Tests for shard selection hashing
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+// shardSpread stores keys on the sharded path and returns the max/min shard occupancy
+func shardSpread(t *testing.T, config CacheConfig, keys int) float64 {
+	t.Helper()
+	config.EnableCaching = true
+	config.CacheSize = 2 * keys
+	config.ShardCount = 16
+	config.EvictionPolicy = "lru"
+	config.TTL = time.Hour
+	cache := NewStrategicCache(config)
+	defer cache.Close()
+
+	for i := 0; i < keys; i++ {
+		cache.Set(fmt.Sprintf("user:%06d", i), i)
+	}
+	lo, hi := keys, 0
+	for _, s := range cache.ShardStats() {
+		lo, hi = min(lo, s.Keys), max(hi, s.Keys)
+	}
+	if lo == 0 {
+		t.Fatalf("expected every shard to hold keys, got an empty one")
+	}
+	return float64(hi) / float64(lo)
+}
+
+func TestKeyHasher_SequentialKeysDistributeEvenly(t *testing.T) {
+	if ratio := shardSpread(t, CacheConfig{}, 100000); ratio >= 1.2 {
+		t.Errorf("expected max/min shard occupancy under 1.2, got %.3f", ratio)
+	}
+}
+
+func TestKeyHasher_Legacy(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, ShardCount: 16, EvictionPolicy: "lru", KeyHasher: "legacy"})
+	defer cache.Close()
+
+	// The placement of earlier releases
+	legacy := func(key string) uint32 {
+		if len(key) <= 8 {
+			var h uint32
+			for i := 0; i < len(key); i++ {
+				h = h*31 + uint32(key[i])
+			}
+			return h
+		}
+		return crc32.ChecksumIEEE([]byte(key))
+	}
+	for _, key := range []string{"", "a", "user:1", "12345678", "user:000001", "a much longer key than eight bytes"} {
+		if got, want := cache.getShard(key), &cache.shards[legacy(key)%16]; got != want {
+			t.Errorf("expected %q in its legacy shard", key)
+		}
+	}
+}
+
+// modHasher places key i in shard i % shards, for keys that are decimal numbers
+type modHasher struct{}
+
+func (modHasher) Hash(key string) uint64 {
+	var n uint64
+	for i := 0; i < len(key); i++ {
+		n = n*10 + uint64(key[i]-'0')
+	}
+	return n
+}
+
+func TestKeyHasher_Custom(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, ShardCount: 4, EvictionPolicy: "lru", CustomKeyHasher: modHasher{}})
+	defer cache.Close()
+
+	for i := 0; i < 8; i++ {
+		cache.Set(fmt.Sprint(i), i)
+	}
+	for _, s := range cache.ShardStats() {
+		if s.Keys != 2 {
+			t.Errorf("expected the custom hasher to put 2 keys in shard %d, got %d", s.Index, s.Keys)
+		}
+	}
+}
+
+func TestKeyHasher_Validate(t *testing.T) {
+	if err := (CacheConfig{KeyHasher: "fnv"}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected an unknown KeyHasher to be rejected, got %v", err)
+	}
+	for _, name := range []string{"", "maphash", "legacy"} {
+		if err := (CacheConfig{KeyHasher: name}).Validate(); err != nil {
+			t.Errorf("expected KeyHasher %q to be valid, got %v", name, err)
+		}
+	}
+	if err := (CacheConfig{KeyHasher: "fnv", CustomKeyHasher: modHasher{}}).Validate(); err != nil {
+		t.Errorf("expected CustomKeyHasher to override KeyHasher, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// hashkey.go: Precomputed key hashes for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+)
+
+// HashedKey is a key whose shard has been selected once by StrategicCache.HashKey, so
+// that GetH, SetH and DeleteH can skip hashing it. It is only meaningful to the cache
+// that produced it; another cache hashes the key again. The zero value is the empty key.
+type HashedKey struct {
+	key   string
+	shard uint32
+	cache *StrategicCache
+}
+
+// Key returns the key that was hashed
+func (hk HashedKey) Key() string {
+	return hk.key
+}
+
+// HashKey hashes key for the storage path of the cache: the KeyHasher on the sharded
+// path, or the shard selection of W-TinyLFU or ARC. Keys touched several times in a
+// row, such as a Get followed by a Set, pay for hashing once. The admission sketch of
+// W-TinyLFU hashes keys independently and is not covered.
+func (sc *StrategicCache) HashKey(key string) HashedKey {
+	return HashedKey{key: key, shard: sc.shardIndex(key), cache: sc}
+}
+
+// hashed returns hk, rehashed if it came from another cache
+func (sc *StrategicCache) hashed(hk HashedKey) HashedKey {
+	if hk.cache != sc {
+		return sc.HashKey(hk.key)
+	}
+	return hk
+}
+
+// shardIndex returns the index of key's shard on the storage path Get, Set and Delete use
+func (sc *StrategicCache) shardIndex(key string) uint32 {
+	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") {
+		return sc.wtinylfu.shardIndex(key)
+	}
+	if sc.arc != nil {
+		return sc.arc.shardIndex(key)
+	}
+	return uint32(sc.hasher.Hash(key) % uint64(sc.shardCount)) // nosec G115 - below shardCount
+}
+
+// latencyShard returns the shard recording the latency of operations on hk
+func (sc *StrategicCache) latencyShard(hk HashedKey) *cacheShard {
+	return &sc.shards[int(hk.shard)%len(sc.shards)]
+}
+
+// GetH is Get for a key hashed by HashKey
+func (sc *StrategicCache) GetH(hk HashedKey) (interface{}, bool) {
+	value, err := sc.getE(sc.hashed(hk))
+	return value, err == nil
+}
+
+// SetH is Set for a key hashed by HashKey
+func (sc *StrategicCache) SetH(hk HashedKey, value interface{}) bool {
+	return sc.setThrough(context.Background(), sc.hashed(hk), value, defaultSetOptions) == nil
+}
+
+// DeleteH is Delete for a key hashed by HashKey
+func (sc *StrategicCache) DeleteH(hk HashedKey) bool {
+	hk = sc.hashed(hk)
+	deleted, err := sc.delete(hk)
+	if err != nil && !errors.Is(err, ErrCacheClosed) {
+		sc.logger.Warn("metis: backend delete failed", "key", hk.key, "error", err)
+	}
+	return deleted
+}
@@ -0,0 +1,136 @@
+// hashkey_test.go: Tests for precomputed key hashes
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingHasher counts the keys it hashes
+type countingHasher struct {
+	calls atomic.Int64
+}
+
+func (h *countingHasher) Hash(key string) uint64 {
+	h.calls.Add(1)
+	return uint64(len(key))
+}
+
+func TestHashedKey_SameEntriesAsStrings(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 8, EvictionPolicy: policy, TTL: time.Hour})
+			defer cache.Close()
+
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key_%d", i)
+				hk := cache.HashKey(key)
+				if hk.Key() != key {
+					t.Fatalf("expected Key() %q, got %q", key, hk.Key())
+				}
+				if i%2 == 0 {
+					cache.SetH(hk, i)
+				} else {
+					cache.Set(key, i)
+				}
+			}
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key_%d", i)
+				byString, ok1 := cache.Get(key)
+				byHash, ok2 := cache.GetH(cache.HashKey(key))
+				if !ok1 || !ok2 || byString != i || byHash != i {
+					t.Fatalf("%s: expected %d both ways, got %v (%v) and %v (%v)", key, i, byString, ok1, byHash, ok2)
+				}
+			}
+
+			if !cache.DeleteH(cache.HashKey("key_1")) {
+				t.Error("expected DeleteH to remove a key set by string")
+			}
+			if !cache.Delete("key_2") {
+				t.Error("expected Delete to remove a key set by SetH")
+			}
+			for _, key := range []string{"key_1", "key_2"} {
+				if _, ok := cache.GetH(cache.HashKey(key)); ok {
+					t.Errorf("expected %s gone", key)
+				}
+				if _, ok := cache.Get(key); ok {
+					t.Errorf("expected %s gone", key)
+				}
+			}
+		})
+	}
+}
+
+func TestHashedKey_SkipsHashing(t *testing.T) {
+	hasher := &countingHasher{}
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, ShardCount: 4, EvictionPolicy: "lru", TTL: time.Hour, CustomKeyHasher: hasher, EnableLatencyTracking: true})
+	defer cache.Close()
+
+	hk := cache.HashKey("user:42")
+	before := hasher.calls.Load()
+	cache.SetH(hk, "v")
+	cache.GetH(hk)
+	cache.DeleteH(hk)
+	if n := hasher.calls.Load() - before; n != 0 {
+		t.Errorf("expected no hashing after HashKey, got %d calls", n)
+	}
+
+	before = hasher.calls.Load()
+	cache.Set("user:42", "v")
+	cache.Get("user:42")
+	cache.Delete("user:42")
+	if n := hasher.calls.Load() - before; n != 3 {
+		t.Errorf("expected one hash per string operation, got %d", n)
+	}
+}
+
+func TestHashedKey_FromAnotherCache(t *testing.T) {
+	small := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, ShardCount: 1, EvictionPolicy: "lru", TTL: time.Hour})
+	defer small.Close()
+	large := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 64, EvictionPolicy: "lru", TTL: time.Hour})
+	defer large.Close()
+
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if !large.SetH(small.HashKey(key), i) {
+			t.Fatalf("expected %s stored", key)
+		}
+		if v, ok := large.Get(key); !ok || v != i {
+			t.Fatalf("expected a foreign HashedKey rehashed for %s, got %v (%v)", key, v, ok)
+		}
+	}
+
+	if _, ok := large.GetH(HashedKey{}); ok {
+		t.Error("expected the zero HashedKey to miss")
+	}
+}
+
+func BenchmarkHashedKey_LongKey(b *testing.B) {
+	key := strings.Repeat("tenant:42/session:", 60)
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 16, EvictionPolicy: policy, TTL: time.Hour})
+		cache.Set(key, 1)
+		b.Run(policy+"/String", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cache.Get(key)
+				cache.Set(key, i)
+			}
+		})
+		b.Run(policy+"/Hashed", func(b *testing.B) {
+			hk := cache.HashKey(key)
+			for i := 0; i < b.N; i++ {
+				cache.GetH(hk)
+				cache.SetH(hk, i)
+			}
+		})
+		cache.Close()
+	}
+}
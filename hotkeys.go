@@ -0,0 +1,199 @@
+// hotkeys.go: Tracking the most read keys
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// KeyFrequency is a key reported by HotKeys with its approximate read count
+type KeyFrequency struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+}
+
+// hotKeysSlack is how many keys each tracker follows per reported key, so keys
+// climbing into the top N are already being counted
+const hotKeysSlack = 4
+
+// HotKeys returns the CacheConfig.TrackHotKeys most read keys, most read first, or
+// nil when TrackHotKeys is not set. Counts are approximate: on W-TinyLFU they are the
+// admission sketch's estimates, which age with it, and elsewhere they are Space-Saving
+// counts that may overstate a key that recently entered the top.
+func (sc *StrategicCache) HotKeys() []KeyFrequency {
+	trackers := sc.hotKeyTrackers()
+	if trackers == nil {
+		return nil
+	}
+
+	var keys []KeyFrequency
+	for _, t := range trackers {
+		keys = t.appendTo(keys)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Count != keys[j].Count {
+			return keys[i].Count > keys[j].Count
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	if len(keys) > sc.config.TrackHotKeys {
+		keys = keys[:sc.config.TrackHotKeys]
+	}
+	return keys
+}
+
+// ResetHotKeys forgets the tracked keys, so HotKeys reports only reads from now on.
+// On W-TinyLFU the counts of keys read again resume from the admission sketch.
+func (sc *StrategicCache) ResetHotKeys() {
+	for _, t := range sc.hotKeyTrackers() {
+		t.reset()
+	}
+}
+
+// hotKeyTrackers returns the trackers of the storage path in use, nil when disabled
+func (sc *StrategicCache) hotKeyTrackers() []*hotKeyTracker {
+	if sc.config.TrackHotKeys <= 0 {
+		return nil
+	}
+	var trackers []*hotKeyTracker
+	if sc.usesWTinyLFU() {
+		for _, shard := range sc.wtinylfu.shards {
+			trackers = append(trackers, shard.hotKeys)
+		}
+		return trackers
+	}
+	for i := range sc.shards {
+		trackers = append(trackers, sc.shards[i].hotKeys)
+	}
+	return trackers
+}
+
+// usesWTinyLFU reports whether reads and writes go to the W-TinyLFU shards
+func (sc *StrategicCache) usesWTinyLFU() bool {
+	return sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "")
+}
+
+// trackHotKey counts a read of key. On W-TinyLFU the read was already recorded in
+// the shard's admission sketch, so its estimate is used instead of a second count.
+func (sc *StrategicCache) trackHotKey(key string) {
+	if sc.usesWTinyLFU() {
+		sc.wtinylfu.getShard(key).hotKeys.record(key)
+		return
+	}
+	sc.getShard(key).hotKeys.record(key)
+}
+
+// hotKeyTracker follows the most read keys of one shard in a min-heap of counts,
+// evicting the least read key when a new one arrives at capacity. Without an
+// estimate function it counts reads itself with the Space-Saving algorithm.
+type hotKeyTracker struct {
+	mu       sync.Mutex
+	capacity int
+	estimate func(key string) uint32 // Current read count of a key, nil = count here
+	heap     hotKeyHeap
+	index    map[string]*hotKey
+}
+
+// hotKey is a tracked key and its position in the heap
+type hotKey struct {
+	key   string
+	count uint64
+	pos   int
+}
+
+// newHotKeyTracker follows up to n*hotKeysSlack keys, counting with estimate if set
+func newHotKeyTracker(n int, estimate func(string) uint32) *hotKeyTracker {
+	return &hotKeyTracker{
+		capacity: n * hotKeysSlack,
+		estimate: estimate,
+		index:    make(map[string]*hotKey),
+	}
+}
+
+// record counts a read of key
+func (t *hotKeyTracker) record(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e, ok := t.index[key]; ok {
+		e.count = t.countAfterRead(e.key, e.count)
+		heap.Fix(&t.heap, e.pos)
+		return
+	}
+	if len(t.heap) < t.capacity {
+		e := &hotKey{key: key, count: t.countAfterRead(key, 0)}
+		heap.Push(&t.heap, e)
+		t.index[key] = e
+		return
+	}
+
+	least := t.heap[0]
+	count := t.countAfterRead(key, least.count)
+	if t.estimate != nil {
+		// The least read key's count may have aged since it was last read
+		least.count = uint64(t.estimate(least.key))
+		heap.Fix(&t.heap, 0)
+		least = t.heap[0]
+		if count <= least.count {
+			return
+		}
+	}
+	// Space-Saving: the newcomer inherits the evicted count, overstating it by at most that
+	delete(t.index, least.key)
+	least.key, least.count = key, count
+	t.index[key] = least
+	heap.Fix(&t.heap, 0)
+}
+
+// countAfterRead returns the count of key after a read, given its tracked count
+func (t *hotKeyTracker) countAfterRead(key string, count uint64) uint64 {
+	if t.estimate != nil {
+		return uint64(t.estimate(key))
+	}
+	return count + 1
+}
+
+// appendTo appends the tracked keys to keys
+func (t *hotKeyTracker) appendTo(keys []KeyFrequency) []KeyFrequency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.heap {
+		keys = append(keys, KeyFrequency{Key: e.key, Count: e.count})
+	}
+	return keys
+}
+
+// reset forgets every tracked key
+func (t *hotKeyTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.heap = nil
+	t.index = make(map[string]*hotKey)
+}
+
+// hotKeyHeap orders tracked keys by count, least read first (container/heap)
+type hotKeyHeap []*hotKey
+
+func (h hotKeyHeap) Len() int           { return len(h) }
+func (h hotKeyHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h hotKeyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos, h[j].pos = i, j
+}
+func (h *hotKeyHeap) Push(x interface{}) {
+	e := x.(*hotKey)
+	e.pos = len(*h)
+	*h = append(*h, e)
+}
+func (h *hotKeyHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
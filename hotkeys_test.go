@@ -0,0 +1,120 @@
+// hotkeys_test.go: Tests for the hot key tracker
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// readSkewed reads hot0 100 times, hot1 50 times and hot2 25 times among 1000 keys read once
+func readSkewed(cache *StrategicCache) {
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("cold%d", i), i)
+		cache.Get(fmt.Sprintf("cold%d", i))
+	}
+	for i, reads := range []int{100, 50, 25} {
+		key := fmt.Sprintf("hot%d", i)
+		cache.Set(key, i)
+		for j := 0; j < reads; j++ {
+			cache.Get(key)
+		}
+	}
+}
+
+func TestHotKeys_ReportsMostRead(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      10000,
+				ShardCount:     4,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+				TrackHotKeys:   3,
+			})
+			defer cache.Close()
+			readSkewed(cache)
+
+			hot := cache.HotKeys()
+			if len(hot) != 3 {
+				t.Fatalf("expected the top 3 keys, got %v", hot)
+			}
+			for i, kf := range hot {
+				if want := fmt.Sprintf("hot%d", i); kf.Key != want {
+					t.Errorf("expected %s at position %d, got %v", want, i, hot)
+				}
+			}
+			if hot[0].Count < hot[1].Count || hot[1].Count < hot[2].Count || hot[2].Count < 2 {
+				t.Errorf("expected descending counts above the cold keys, got %v", hot)
+			}
+		})
+	}
+}
+
+func TestHotKeys_Reset(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      10000,
+				ShardCount:     4,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+				TrackHotKeys:   3,
+			})
+			defer cache.Close()
+			readSkewed(cache)
+
+			cache.ResetHotKeys()
+			if hot := cache.HotKeys(); len(hot) != 0 {
+				t.Fatalf("expected no hot keys after a reset, got %v", hot)
+			}
+			cache.Get("cold7")
+			if hot := cache.HotKeys(); len(hot) != 1 || hot[0].Key != "cold7" {
+				t.Errorf("expected only the key read since the reset, got %v", hot)
+			}
+		})
+	}
+}
+
+func TestHotKeys_Disabled(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+	if hot := cache.HotKeys(); hot != nil {
+		t.Errorf("expected nil without TrackHotKeys, got %v", hot)
+	}
+	cache.ResetHotKeys() // No-op
+}
+
+func TestHotKeyTracker_SpaceSaving(t *testing.T) {
+	tracker := newHotKeyTracker(1, nil) // Follows 4 keys
+	for i := 0; i < 100; i++ {
+		tracker.record("heavy")
+		tracker.record(fmt.Sprintf("noise%d", i))
+	}
+
+	keys := tracker.appendTo(nil)
+	if len(keys) != 4 {
+		t.Fatalf("expected the tracker to follow 4 keys, got %v", keys)
+	}
+	for _, kf := range keys {
+		if kf.Key == "heavy" && kf.Count >= 100 {
+			return
+		}
+	}
+	t.Errorf("expected the heavy hitter with at least 100 reads among %v", keys)
+}
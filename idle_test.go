@@ -0,0 +1,173 @@
+// idle_test.go: Tests for MaxIdleTime expiration
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestMaxIdleTime_ExpiresUnreadEntries(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             time.Hour,
+				MaxIdleTime:     50 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.Set("read", "v")
+			cache.Set("unread", "v")
+
+			clock.Advance(30 * time.Millisecond)
+			if _, ok := cache.Get("read"); !ok {
+				t.Fatal("expected the entry to be live before the idle timeout")
+			}
+			clock.Advance(30 * time.Millisecond)
+			if _, err := cache.GetE("unread"); err == nil {
+				t.Error("expected the entry unread for 60ms to expire")
+			}
+			if _, ok := cache.Get("read"); !ok {
+				t.Error("expected the entry read 30ms ago to stay live")
+			}
+
+			stats := cache.GetStats()
+			if stats.IdleExpirations != 1 || stats.Expirations != 0 {
+				t.Errorf("expected 1 idle and 0 TTL expirations, got %d and %d", stats.IdleExpirations, stats.Expirations)
+			}
+		})
+	}
+}
+
+func TestMaxIdleTime_TTLStillApplies(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             100 * time.Millisecond,
+				MaxIdleTime:     50 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.Set("k", "v")
+
+			// Reads keep the idle timeout at bay but do not extend the TTL
+			for i := 0; i < 3; i++ {
+				clock.Advance(30 * time.Millisecond)
+				if _, ok := cache.Get("k"); !ok {
+					t.Fatalf("expected the entry to be live at read %d", i)
+				}
+			}
+			clock.Advance(30 * time.Millisecond)
+			if _, ok := cache.Get("k"); ok {
+				t.Error("expected the TTL to expire the entry despite the reads")
+			}
+
+			stats := cache.GetStats()
+			if stats.Expirations != 1 || stats.IdleExpirations != 0 {
+				t.Errorf("expected 1 TTL and 0 idle expirations, got %d and %d", stats.Expirations, stats.IdleExpirations)
+			}
+		})
+	}
+}
+
+func TestMaxIdleTime_HiddenFromIteration(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             time.Hour,
+				MaxIdleTime:     50 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.Set("idle", "v")
+			clock.Advance(60 * time.Millisecond)
+			cache.Set("fresh", "v")
+
+			if keys := cache.Keys(); len(keys) != 1 || keys[0] != "fresh" {
+				t.Errorf("expected only the fresh key, got %v", keys)
+			}
+		})
+	}
+}
+
+func TestMaxIdleTime_CleanupSweep(t *testing.T) {
+	t.Run("lru", func(t *testing.T) {
+		clock := fakeclock.New(time.Time{})
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:   true,
+			CacheSize:       1000,
+			ShardCount:      4,
+			TTL:             time.Hour,
+			MaxIdleTime:     50 * time.Millisecond,
+			CleanupInterval: time.Hour,
+			EvictionPolicy:  "lru",
+			Clock:           clock,
+		})
+		defer cache.Close()
+		for _, key := range []string{"a", "b", "c"} {
+			cache.Set(key, "v")
+		}
+		clock.Advance(60 * time.Millisecond)
+		for i := range cache.shards {
+			cache.cleanupExpired(i)
+		}
+		if stats := cache.GetStats(); stats.Keys != 0 || stats.IdleExpirations != 3 {
+			t.Errorf("expected the sweep to drop 3 idle entries, got %+v", stats)
+		}
+	})
+
+	t.Run("wtinylfu", func(t *testing.T) {
+		clock := fakeclock.New(time.Time{})
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:   true,
+			CacheSize:       1000,
+			ShardCount:      4,
+			TTL:             time.Hour,
+			MaxIdleTime:     50 * time.Millisecond,
+			CleanupInterval: time.Hour,
+			EvictionPolicy:  "wtinylfu",
+			Clock:           clock,
+		})
+		defer cache.Close()
+		for _, key := range []string{"a", "b", "c"} {
+			cache.Set(key, "v")
+		}
+		clock.Advance(60 * time.Millisecond)
+		if removed := cache.wtinylfu.RemoveExpired(); removed != 3 {
+			t.Errorf("expected the sweep to drop 3 entries, got %d", removed)
+		}
+		if stats := cache.GetStats(); stats.IdleExpirations != 3 {
+			t.Errorf("expected 3 idle expirations, got %d", stats.IdleExpirations)
+		}
+	})
+}
+
+func TestMaxIdleTime_Negative(t *testing.T) {
+	if err := (CacheConfig{CacheSize: 10, MaxIdleTime: -time.Second}).Validate(); err == nil {
+		t.Error("expected a negative MaxIdleTime to be rejected")
+	}
+}
@@ -0,0 +1,223 @@
+// bench.go: Mixed Get/Set workload shared by the metis command line tools
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package bench runs a timed mix of Get and Set calls against a cache, so metis-debug
+// and the profiler measure configurations with the same workload.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+// Key distributions accepted by Workload.Distribution
+const (
+	Uniform = "uniform" // Every key equally likely
+	Zipf    = "zipf"    // A few keys take most accesses, as in most real traffic
+)
+
+// zipfS is the skew of the Zipf distribution; higher values concentrate accesses
+const zipfS = 1.1
+
+// Workload describes the operations run against a cache
+type Workload struct {
+	Duration     time.Duration `json:"duration"`     // How long the workers run
+	Workers      int           `json:"workers"`      // Concurrent goroutines issuing operations
+	Keys         int           `json:"keys"`         // Size of the key space
+	ValueSize    int           `json:"value_size"`   // Bytes in each stored value
+	ReadRatio    float64       `json:"read_ratio"`   // Fraction of operations that are Gets, 0 to 1
+	Distribution string        `json:"distribution"` // Uniform or Zipf
+	Seed         int64         `json:"seed"`         // Seeds the key choices of every worker
+	Warmup       int           `json:"warmup"`       // Keys stored before the clock starts
+}
+
+// Validate reports the first setting that cannot run
+func (w Workload) Validate() error {
+	switch {
+	case w.Duration <= 0:
+		return fmt.Errorf("duration must be positive, got %v", w.Duration)
+	case w.Workers <= 0:
+		return fmt.Errorf("workers must be positive, got %d", w.Workers)
+	case w.Keys <= 0:
+		return fmt.Errorf("keys must be positive, got %d", w.Keys)
+	case w.ValueSize < 0:
+		return fmt.Errorf("value size must not be negative, got %d", w.ValueSize)
+	case w.ReadRatio < 0 || w.ReadRatio > 1:
+		return fmt.Errorf("read ratio must be between 0 and 1, got %v", w.ReadRatio)
+	case w.Distribution != Uniform && w.Distribution != Zipf:
+		return fmt.Errorf("distribution must be %q or %q, got %q", Uniform, Zipf, w.Distribution)
+	}
+	return nil
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Ops         int64              `json:"ops"`
+	Elapsed     time.Duration      `json:"elapsed"`
+	OpsPerSec   float64            `json:"ops_per_sec"`
+	Hits        int64              `json:"hits"`
+	Misses      int64              `json:"misses"`
+	HitRate     float64            `json:"hit_rate"` // Hits / Gets, 0 to 1
+	Latency     metis.LatencyStats `json:"latency"`  // Zero unless the cache sets EnableLatencyTracking
+	MemoryBytes int64              `json:"memory_bytes"`
+	Keys        int                `json:"keys"` // Entries cached at the end of the run
+}
+
+// Run stores w.Warmup keys, then runs w against cache until w.Duration has passed.
+// Latency percentiles come from cache.LatencyStats, so build the cache with
+// EnableLatencyTracking to get them.
+func Run(cache *metis.StrategicCache, w Workload) (Result, error) {
+	if err := w.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	keys := make([]string, w.Keys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+	value := make([]byte, w.ValueSize)
+	for i := 0; i < w.Warmup && i < len(keys); i++ {
+		cache.Set(keys[i], value)
+	}
+	cache.ResetStats()
+
+	var stop atomic.Bool
+	var ops, hits, misses atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for id := 0; id < w.Workers; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			// nosec G404 - key choices for a benchmark, not security sensitive
+			r := rand.New(rand.NewSource(w.Seed + int64(id)))
+			next := w.keyChooser(r)
+			var n, h, m int64
+			for !stop.Load() {
+				key := keys[next()]
+				if r.Float64() < w.ReadRatio {
+					if _, ok := cache.Get(key); ok {
+						h++
+					} else {
+						m++
+					}
+				} else {
+					cache.Set(key, value)
+				}
+				n++
+			}
+			ops.Add(n)
+			hits.Add(h)
+			misses.Add(m)
+		}(id)
+	}
+	time.Sleep(w.Duration)
+	stop.Store(true)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	stats := cache.GetStats()
+	result := Result{
+		Ops:         ops.Load(),
+		Elapsed:     elapsed,
+		OpsPerSec:   float64(ops.Load()) / elapsed.Seconds(),
+		Hits:        hits.Load(),
+		Misses:      misses.Load(),
+		Latency:     cache.LatencyStats(),
+		MemoryBytes: stats.MemoryBytes,
+		Keys:        stats.Keys,
+	}
+	if gets := result.Hits + result.Misses; gets > 0 {
+		result.HitRate = float64(result.Hits) / float64(gets)
+	}
+	return result, nil
+}
+
+// keyChooser returns a function picking key indexes from r with the workload's distribution
+func (w Workload) keyChooser(r *rand.Rand) func() int {
+	if w.Distribution == Zipf && w.Keys > 1 {
+		z := rand.NewZipf(r, zipfS, 1, uint64(w.Keys-1))
+		return func() int { return int(z.Uint64()) }
+	}
+	return func() int { return r.Intn(w.Keys) }
+}
+
+// Trace is a fixed sequence of operations drawn once from a Workload, so that
+// replaying it against several caches compares them on exactly the same accesses
+type Trace struct {
+	Keys []string
+	Ops  []TraceOp
+}
+
+// TraceOp is one operation of a Trace
+type TraceOp struct {
+	Key  int32 // Index into Trace.Keys
+	Read bool  // A Get, or else a Set
+}
+
+// NewTrace draws n operations from w with a single generator seeded with w.Seed, so
+// the same workload always gives the same trace. Duration, Workers and Warmup are not
+// used and need not be set.
+func NewTrace(w Workload, n int) (Trace, error) {
+	w.Duration, w.Workers = time.Nanosecond, 1
+	if err := w.Validate(); err != nil {
+		return Trace{}, err
+	}
+	if n <= 0 {
+		return Trace{}, fmt.Errorf("trace length must be positive, got %d", n)
+	}
+
+	t := Trace{Keys: make([]string, w.Keys), Ops: make([]TraceOp, n)}
+	for i := range t.Keys {
+		t.Keys[i] = fmt.Sprintf("key_%d", i)
+	}
+	// nosec G404 - key choices for a benchmark, not security sensitive
+	r := rand.New(rand.NewSource(w.Seed))
+	next := w.keyChooser(r)
+	for i := range t.Ops {
+		t.Ops[i] = TraceOp{Key: int32(next()), Read: r.Float64() < w.ReadRatio} // nosec G115 - Keys is an int32 range in practice
+	}
+	return t, nil
+}
+
+// Replay runs t against cache from a single goroutine, as a cache-aside client would:
+// a Get that misses is followed by a Set of the same key. Result.Latency is left zero.
+func Replay(cache *metis.StrategicCache, t Trace, valueSize int) Result {
+	value := make([]byte, valueSize)
+	cache.ResetStats()
+
+	var result Result
+	start := time.Now()
+	for _, op := range t.Ops {
+		key := t.Keys[op.Key]
+		if !op.Read {
+			cache.Set(key, value)
+			continue
+		}
+		if _, ok := cache.Get(key); ok {
+			result.Hits++
+		} else {
+			result.Misses++
+			cache.Set(key, value)
+		}
+	}
+	result.Elapsed = time.Since(start)
+
+	stats := cache.GetStats()
+	result.Ops = int64(len(t.Ops))
+	result.OpsPerSec = float64(result.Ops) / result.Elapsed.Seconds()
+	result.MemoryBytes = stats.MemoryBytes
+	result.Keys = stats.Keys
+	if gets := result.Hits + result.Misses; gets > 0 {
+		result.HitRate = float64(result.Hits) / float64(gets)
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+// bench_test.go: Tests for the shared benchmark workload
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package bench
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+)
+
+func testWorkload() Workload {
+	return Workload{
+		Duration:     50 * time.Millisecond,
+		Workers:      2,
+		Keys:         1000,
+		ValueSize:    16,
+		ReadRatio:    0.9,
+		Distribution: Uniform,
+		Seed:         1,
+		Warmup:       1000,
+	}
+}
+
+func TestRun(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:         true,
+		CacheSize:             10000,
+		TTL:                   time.Minute,
+		EvictionPolicy:        "lru",
+		EnableLatencyTracking: true,
+	})
+	defer cache.Close()
+
+	result, err := Run(cache, testWorkload())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Ops == 0 || result.OpsPerSec <= 0 {
+		t.Fatalf("expected operations, got %+v", result)
+	}
+	// Every key was warmed up and fits, so every Get hits
+	if result.HitRate != 1 || result.Misses != 0 {
+		t.Errorf("expected a full hit rate, got %v with %d misses", result.HitRate, result.Misses)
+	}
+	if got := result.Latency.Get.Count; got != result.Hits+result.Misses {
+		t.Errorf("expected latency for the %d Gets of the run only, got %d", result.Hits+result.Misses, got)
+	}
+	if result.Keys != 1000 || result.MemoryBytes == 0 {
+		t.Errorf("expected 1000 cached keys with their memory, got %d and %d bytes", result.Keys, result.MemoryBytes)
+	}
+}
+
+func TestWorkload_Validate(t *testing.T) {
+	for name, change := range map[string]func(*Workload){
+		"duration":     func(w *Workload) { w.Duration = 0 },
+		"workers":      func(w *Workload) { w.Workers = 0 },
+		"keys":         func(w *Workload) { w.Keys = -1 },
+		"value size":   func(w *Workload) { w.ValueSize = -1 },
+		"read ratio":   func(w *Workload) { w.ReadRatio = 1.5 },
+		"distribution": func(w *Workload) { w.Distribution = "normal" },
+	} {
+		w := testWorkload()
+		change(&w)
+		if err := w.Validate(); err == nil {
+			t.Errorf("expected an invalid %s to be rejected", name)
+		}
+	}
+	if err := testWorkload().Validate(); err != nil {
+		t.Errorf("expected the test workload to be valid, got %v", err)
+	}
+}
+
+func TestKeyChooser_Distributions(t *testing.T) {
+	w := testWorkload()
+	for _, dist := range []string{Uniform, Zipf} {
+		w.Distribution = dist
+		next := w.keyChooser(rand.New(rand.NewSource(1)))
+		top := 0 // Picks among the 10 most likely keys
+		for i := 0; i < 10000; i++ {
+			k := next()
+			if k < 0 || k >= w.Keys {
+				t.Fatalf("%s: key index %d out of range", dist, k)
+			}
+			if k < 10 {
+				top++
+			}
+		}
+		switch {
+		case dist == Uniform && top > 300:
+			t.Errorf("expected about 1%% of uniform picks in the first 10 keys, got %d", top)
+		case dist == Zipf && top < 4000:
+			t.Errorf("expected most Zipf picks in the first 10 keys, got %d", top)
+		}
+	}
+}
+
+func TestNewTrace_Deterministic(t *testing.T) {
+	w := testWorkload()
+	w.Distribution = Zipf
+	a, err := NewTrace(w, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewTrace(w, 1000)
+	for i := range a.Ops {
+		if a.Ops[i] != b.Ops[i] {
+			t.Fatalf("op %d differs between traces of the same seed: %+v and %+v", i, a.Ops[i], b.Ops[i])
+		}
+	}
+
+	w.Seed++
+	c, _ := NewTrace(w, 1000)
+	same := 0
+	for i := range a.Ops {
+		if a.Ops[i] == c.Ops[i] {
+			same++
+		}
+	}
+	if same == len(a.Ops) {
+		t.Error("expected another seed to give another trace")
+	}
+
+	if _, err := NewTrace(w, 0); err == nil {
+		t.Error("expected an error for an empty trace")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	w := testWorkload()
+	w.Distribution = Zipf
+	trace, err := NewTrace(w, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var prev float64
+	for _, size := range []int{50, 1000} {
+		cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: size, EvictionPolicy: "lru", ShardCount: 1})
+		result := Replay(cache, trace, w.ValueSize)
+		cache.Close()
+
+		if result.Ops != 20000 || result.Hits+result.Misses == 0 {
+			t.Fatalf("size %d: unexpected result %+v", size, result)
+		}
+		if result.HitRate <= prev {
+			t.Errorf("expected a larger cache to hit more: size %d got %.3f after %.3f", size, result.HitRate, prev)
+		}
+		prev = result.HitRate
+	}
+}
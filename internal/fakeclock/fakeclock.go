@@ -0,0 +1,107 @@
+// fakeclock.go: Manually advanced clock shared by metis tests and the metistest package
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package fakeclock implements a clock whose time only moves when told to. It lives in an
+// internal package so the metis tests can use it without importing metistest.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultStart is the time a Clock created from a zero time starts at
+var DefaultStart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a fake clock: Now stays put until Advance or Set moves it, and tickers fire
+// as the time passes their deadlines. It is safe for concurrent use.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// ticker is a ticker created by Clock.NewTicker
+type ticker struct {
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+// New returns a Clock set to start, or to DefaultStart when start is zero
+func New(start time.Time) *Clock {
+	if start.IsZero() {
+		start = DefaultStart
+	}
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a channel receiving the clock's time every d, and a function that
+// stops the ticker. Like time.Ticker, it holds at most one pending tick and drops the
+// rest for slow receivers. It panics if d is not positive.
+func (c *Clock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	if d <= 0 {
+		panic("fakeclock: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t.c, func() { c.stop(t) }
+}
+
+// stop removes t from the active tickers
+func (c *Clock) stop(t *ticker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, active := range c.tickers {
+		if active == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance moves the clock forward by d, firing the tickers whose deadlines it passes
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the clock to t, firing the tickers whose deadlines it passes. Moving the
+// clock backwards fires nothing.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// setLocked moves the clock to now; the caller must hold c.mu
+func (c *Clock) setLocked(now time.Time) {
+	c.now = now
+	for _, t := range c.tickers {
+		if t.next.After(now) {
+			continue
+		}
+		select {
+		case t.c <- now:
+		default: // A tick is already pending
+		}
+		// Skip the deadlines passed in one step, as a real ticker would
+		for !t.next.After(now) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// invalidation.go: Invalidation across processes over a pub/sub bus for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	randc "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// InvalidationBus carries invalidation messages between the caches of several processes,
+// such as a Redis Pub/Sub channel. Every subscriber, including the publisher's own, must
+// receive every published message. Publish is called on the write path, so it should not
+// block for long; Subscribe's fn may be called from any goroutine.
+type InvalidationBus interface {
+	Publish(key string) error
+	Subscribe(fn func(key string)) error
+}
+
+// originLen is the length of the hex origin ID that prefixes published messages
+const originLen = 16
+
+// invalidationLink is a bus attached with AttachInvalidationBus
+type invalidationLink struct {
+	bus    InvalidationBus
+	origin string // Random ID of this cache, so it can skip its own messages
+}
+
+// AttachInvalidationBus makes the cache share invalidations through bus. Once attached,
+// Set, SetE, SetCtx, SetWithOptions, SetH, SetBytes, SetReader, SetNegative and Delete
+// publish the key after the local write, and keys published by other caches are deleted
+// from memory; the Backend is left to the cache that wrote. Values stored by
+// LoadOrCompute are not published, so instances filling the same key do not keep
+// invalidating each other; nor are the removals of Invalidate and InvalidateTag.
+//
+// Messages are the key prefixed with a random origin ID and a space, so a cache ignores
+// its own. A message without that prefix is taken as a plain key, which lets other
+// systems publish invalidations. A failed Publish is reported to CacheConfig.Logger and
+// does not fail the write. With an asynchronous bus, a message may arrive after a newer
+// local write of the same key and remove it; that costs a miss, never a stale read. A
+// cache can attach one bus.
+func (sc *StrategicCache) AttachInvalidationBus(bus InvalidationBus) error {
+	var id [originLen / 2]byte
+	if _, err := randc.Read(id[:]); err != nil {
+		return err
+	}
+	link := &invalidationLink{bus: bus, origin: hex.EncodeToString(id[:])}
+	if !sc.invalidation.CompareAndSwap(nil, link) {
+		return errors.New("an invalidation bus is already attached")
+	}
+	if err := bus.Subscribe(func(msg string) { sc.receiveInvalidation(link, msg) }); err != nil {
+		sc.invalidation.Store(nil)
+		return err
+	}
+	return nil
+}
+
+// publishInvalidation tells the other caches on the bus, if any, that key changed
+func (sc *StrategicCache) publishInvalidation(key string) {
+	link := sc.invalidation.Load()
+	if link == nil {
+		return
+	}
+	if err := link.bus.Publish(link.origin + " " + key); err != nil {
+		sc.logger.Warn("metis: cannot publish invalidation", "key", key, "error", err)
+	}
+}
+
+// receiveInvalidation deletes the key of a message published by another cache
+func (sc *StrategicCache) receiveInvalidation(link *invalidationLink, msg string) {
+	key := msg
+	if len(msg) > originLen && msg[originLen] == ' ' && isHex(msg[:originLen]) {
+		if msg[:originLen] == link.origin {
+			return
+		}
+		key = msg[originLen+1:]
+	}
+
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	if !closed {
+		sc.deleteLocal(sc.HashKey(key))
+	}
+}
+
+// isHex reports whether s holds only lowercase hex digits
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// LocalBus is an InvalidationBus within one process, for tests and for several caches
+// in the same binary. Publish delivers to every subscriber before returning.
+type LocalBus struct {
+	mu          sync.RWMutex
+	subscribers []func(key string)
+}
+
+// NewLocalBus creates a LocalBus without subscribers
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+// Publish calls every subscriber with key
+func (b *LocalBus) Publish(key string) error {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(key)
+	}
+	return nil
+}
+
+// Subscribe adds fn to the subscribers
+func (b *LocalBus) Subscribe(fn func(key string)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers[:len(b.subscribers):len(b.subscribers)], fn)
+	return nil
+}
@@ -0,0 +1,111 @@
+// invalidation_test.go: Tests for invalidation over a pub/sub bus
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingBus is a LocalBus that keeps what was published
+type recordingBus struct {
+	*LocalBus
+	published []string
+}
+
+func (b *recordingBus) Publish(key string) error {
+	b.published = append(b.published, key)
+	return b.LocalBus.Publish(key)
+}
+
+// attachedPair returns two caches sharing a bus
+func attachedPair(t *testing.T, policy string, bus InvalidationBus) (*StrategicCache, *StrategicCache) {
+	t.Helper()
+	a := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy})
+	b := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy})
+	t.Cleanup(func() { a.Close(); b.Close() })
+	for _, c := range []*StrategicCache{a, b} {
+		if err := c.AttachInvalidationBus(bus); err != nil {
+			t.Fatalf("AttachInvalidationBus: %v", err)
+		}
+	}
+	return a, b
+}
+
+func TestInvalidationBus_Converges(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			a, b := attachedPair(t, policy, NewLocalBus())
+			a.Set("user:1", "old")
+			b.Set("user:1", "old")
+
+			a.Set("user:1", "new")
+			if v, ok := a.Get("user:1"); !ok || v != "new" {
+				t.Errorf("expected the writer to keep its value, got %v, %v", v, ok)
+			}
+			if _, ok := b.Get("user:1"); ok {
+				t.Error("expected the other cache to drop its stale value")
+			}
+
+			b.SetBytes("blob", []byte("x"))
+			a.SetBytes("blob", []byte("y"))
+			if _, ok := b.GetBytes("blob"); ok {
+				t.Error("expected SetBytes to invalidate the other cache")
+			}
+
+			b.Set("user:2", 2)
+			a.Delete("user:2")
+			if _, ok := b.Get("user:2"); ok {
+				t.Error("expected Delete to invalidate the other cache")
+			}
+		})
+	}
+}
+
+func TestInvalidationBus_FillsNotPublished(t *testing.T) {
+	bus := &recordingBus{LocalBus: NewLocalBus()}
+	a, b := attachedPair(t, "lru", bus)
+	b.Set("k", "b's copy")
+	bus.published = nil
+
+	notFound := errors.New("not found")
+	if v, err := a.LoadOrCompute("k", func(string) (interface{}, error) { return "a's copy", nil }); err != nil || v != "a's copy" {
+		t.Fatalf("LoadOrCompute: %v, %v", v, err)
+	}
+	_, _ = a.LoadOrCompute("missing", func(string) (interface{}, error) { return nil, notFound },
+		WithNegativeCache(time.Minute, func(err error) bool { return errors.Is(err, notFound) }))
+	if len(bus.published) != 0 {
+		t.Errorf("expected fills not to be published, got %q", bus.published)
+	}
+	if _, ok := b.Get("k"); !ok {
+		t.Error("expected the other cache to keep its value")
+	}
+}
+
+func TestInvalidationBus_Messages(t *testing.T) {
+	bus := NewLocalBus()
+	a, _ := attachedPair(t, "lru", bus)
+	if err := a.AttachInvalidationBus(bus); err == nil {
+		t.Error("expected a second bus to be refused")
+	}
+
+	// A bare key from another system, and a key that itself looks like a message
+	a.Set("plain", 1)
+	a.Set("0123456789abcdef x", 1)
+	_ = bus.Publish("plain")
+	_ = bus.Publish("fedcba9876543210 0123456789abcdef x")
+	if _, ok := a.Get("plain"); ok {
+		t.Error("expected a bare key to be invalidated")
+	}
+	if _, ok := a.Get("0123456789abcdef x"); ok {
+		t.Error("expected the key after the origin to be invalidated")
+	}
+
+	a.Close()
+	_ = bus.Publish("plain") // Ignored once closed
+}
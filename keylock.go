@@ -0,0 +1,106 @@
+// keylock.go: Per-key locking for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// keyLock is the mutex of one key, with the number of callers holding or waiting for it
+type keyLock struct {
+	mu   sync.Mutex
+	refs int // Guarded by the shard's mu
+}
+
+// keyLockShard holds the locks of the keys hashing to it
+type keyLockShard struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLocks hands out per-key mutexes. Each lock lives in the map only while some caller
+// holds or waits for it, so the map never grows beyond the keys in use.
+type keyLocks struct {
+	seed   maphash.Seed
+	shards []keyLockShard
+}
+
+// newKeyLocks returns a table of shards shards
+func newKeyLocks(shards int) *keyLocks {
+	kl := &keyLocks{
+		seed:   maphash.MakeSeed(),
+		shards: make([]keyLockShard, max(1, shards)),
+	}
+	for i := range kl.shards {
+		kl.shards[i].locks = make(map[string]*keyLock)
+	}
+	return kl
+}
+
+// lock blocks until key is locked and returns the function that unlocks it
+func (kl *keyLocks) lock(key string) func() {
+	s := &kl.shards[maphash.String(kl.seed, key)%uint64(len(kl.shards))]
+	s.mu.Lock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = &keyLock{}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// size returns the number of locks held or waited for
+func (kl *keyLocks) size() int {
+	n := 0
+	for i := range kl.shards {
+		s := &kl.shards[i]
+		s.mu.Lock()
+		n += len(s.locks)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// LockKey blocks until no other caller holds the lock of key, takes it and returns the
+// function that releases it, which must be called exactly once. Locks of different keys
+// do not contend. LockKey only excludes other LockKey and UpdateInPlace callers: Get, Set
+// and the other cache methods ignore it.
+//
+//	unlock := cache.LockKey("account:42")
+//	defer unlock()
+func (sc *StrategicCache) LockKey(key string) (unlock func()) {
+	return sc.keyLocks.lock(key)
+}
+
+// UpdateInPlace runs a read-modify-write of key under its LockKey lock. fn receives the
+// current value, or nil and false when the key is missing, and returns the new value and
+// whether to store it. UpdateInPlace reports whether a value was stored, and is only
+// atomic with respect to other LockKey and UpdateInPlace callers for the same key.
+func (sc *StrategicCache) UpdateInPlace(key string, fn func(old interface{}, exists bool) (new interface{}, write bool)) bool {
+	unlock := sc.LockKey(key)
+	defer unlock()
+
+	old, exists := sc.Get(key)
+	value, write := fn(old, exists)
+	if !write {
+		return false
+	}
+	return sc.Set(key, value)
+}
@@ -0,0 +1,162 @@
+// keylock_test.go: Tests for per-key locking
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateInPlace_Concurrent(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						cache.UpdateInPlace("counter", func(old interface{}, exists bool) (interface{}, bool) {
+							if !exists {
+								return 1, true
+							}
+							return old.(int) + 1, true
+						})
+					}
+				}()
+			}
+			wg.Wait()
+
+			if v, _ := cache.Get("counter"); v != 1600 {
+				t.Errorf("expected 1600 increments, got %v", v)
+			}
+			if n := cache.keyLocks.size(); n != 0 {
+				t.Errorf("expected no locks left, got %d", n)
+			}
+		})
+	}
+}
+
+func TestUpdateInPlace_NoWrite(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	stored := cache.UpdateInPlace("k", func(old interface{}, exists bool) (interface{}, bool) {
+		if exists || old != nil {
+			t.Errorf("expected a missing key, got %v, %v", old, exists)
+		}
+		return "v", false
+	})
+	if stored {
+		t.Error("expected nothing stored")
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Error("expected the key to stay missing")
+	}
+}
+
+func TestLockKey_SerializesOneKeyOnly(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	unlock := cache.LockKey("a")
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		cache.LockKey("a")()
+	}()
+
+	// Another key is not blocked by the held lock
+	done := make(chan struct{})
+	go func() {
+		cache.LockKey("b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected an unrelated key to lock immediately")
+	}
+
+	select {
+	case <-acquired:
+		t.Fatal("expected the second LockKey of a to wait")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+}
+
+func TestLockKey_NoLeaksAfterChurn(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				unlock := cache.LockKey(fmt.Sprintf("k%d", (g*i)%97))
+				unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := cache.keyLocks.size(); n != 0 {
+		t.Errorf("expected every lock to be released from the map, got %d left", n)
+	}
+}
+
+func TestUpdateInPlace_PanicReleasesLock(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	func() {
+		defer func() { _ = recover() }()
+		cache.UpdateInPlace("k", func(interface{}, bool) (interface{}, bool) { panic("boom") })
+	}()
+	if n := cache.keyLocks.size(); n != 0 {
+		t.Errorf("expected the lock released after a panic, got %d left", n)
+	}
+}
@@ -0,0 +1,145 @@
+// latency.go: Get and Set latency histograms
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyStats holds the latency distribution of Get and Set since the cache was
+// created, when CacheConfig.EnableLatencyTracking is set
+type LatencyStats struct {
+	Get OpLatency `json:"get"`
+	Set OpLatency `json:"set"`
+}
+
+// OpLatency summarizes the recorded durations of one operation. Percentiles are the
+// upper bound of the histogram bucket they fall in, within 1/16 of the true value.
+type OpLatency struct {
+	Count int64         `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	P999  time.Duration `json:"p999"`
+}
+
+// LatencyStats merges the per-shard histograms of Get and Set. It returns the zero
+// value when EnableLatencyTracking is not set.
+func (sc *StrategicCache) LatencyStats() LatencyStats {
+	if !sc.config.EnableLatencyTracking {
+		return LatencyStats{}
+	}
+
+	var get, set latencyHistogram
+	for i := range sc.shards {
+		get.merge(&sc.shards[i].latency.get)
+		set.merge(&sc.shards[i].latency.set)
+	}
+	return LatencyStats{Get: get.summary(), Set: set.summary()}
+}
+
+// shardLatency holds the histograms of the operations on keys of one shard
+type shardLatency struct {
+	get latencyHistogram
+	set latencyHistogram
+}
+
+// Histogram layout, in the style of HdrHistogram: durations under latencySub
+// nanoseconds get a bucket each, and every power of two above is split into
+// latencySub linear buckets, bounding the error of a bucket to 1/latencySub.
+const (
+	latencySubBits = 4
+	latencySub     = 1 << latencySubBits
+	latencyMaxBits = 36 // Durations from 2^36ns (about 69s) up share the last bucket
+	latencyBuckets = latencySub + (latencyMaxBits-latencySubBits)*latencySub
+)
+
+// latencyHistogram counts durations in log-linear buckets without locks
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Int64
+}
+
+// since records the time elapsed from start
+func (h *latencyHistogram) since(start time.Time) {
+	h.counts[latencyBucket(time.Since(start))].Add(1)
+}
+
+// latencyBucket returns the bucket of a duration
+func latencyBucket(d time.Duration) int {
+	ns := uint64(max(0, int(d)))
+	if ns < latencySub {
+		return int(ns)
+	}
+	if ns >= 1<<latencyMaxBits {
+		ns = 1<<latencyMaxBits - 1
+	}
+	shift := bits.Len64(ns) - latencySubBits - 1
+	return latencySub + shift*latencySub + int(ns>>shift) - latencySub
+}
+
+// latencyUpperBound returns the largest duration that falls in bucket i
+func latencyUpperBound(i int) time.Duration {
+	if i < latencySub {
+		return time.Duration(i)
+	}
+	shift := (i - latencySub) / latencySub
+	mantissa := latencySub + (i-latencySub)%latencySub
+	return time.Duration((mantissa+1)<<shift - 1)
+}
+
+// reset zeroes both histograms. A nil shardLatency is left alone.
+func (l *shardLatency) reset() {
+	if l == nil {
+		return
+	}
+	for i := range l.get.counts {
+		l.get.counts[i].Store(0)
+		l.set.counts[i].Store(0)
+	}
+}
+
+// merge adds the counts of other to h
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	for i := range other.counts {
+		if n := other.counts[i].Load(); n > 0 {
+			h.counts[i].Add(n)
+		}
+	}
+}
+
+// summary returns the count and percentiles of the recorded durations
+func (h *latencyHistogram) summary() OpLatency {
+	var total int64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+	}
+	if total == 0 {
+		return OpLatency{}
+	}
+	return OpLatency{
+		Count: total,
+		P50:   h.quantile(0.50, total),
+		P90:   h.quantile(0.90, total),
+		P99:   h.quantile(0.99, total),
+		P999:  h.quantile(0.999, total),
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the q-th of total durations
+func (h *latencyHistogram) quantile(q float64, total int64) time.Duration {
+	rank := int64(math.Ceil(q * float64(total)))
+	var seen int64
+	for i := range h.counts {
+		if seen += h.counts[i].Load(); seen >= rank {
+			return latencyUpperBound(i)
+		}
+	}
+	return latencyUpperBound(latencyBuckets - 1)
+}
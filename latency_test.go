@@ -0,0 +1,130 @@
+// latency_test.go: Tests for Get and Set latency histograms
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLatencyBucket_Bounds(t *testing.T) {
+	for d := time.Duration(0); d < time.Minute; d = d*9/8 + 1 {
+		i := latencyBucket(d)
+		if upper := latencyUpperBound(i); d > upper {
+			t.Fatalf("%v falls in bucket %d with upper bound %v", d, i, upper)
+		}
+		if i > 0 && d <= latencyUpperBound(i-1) {
+			t.Fatalf("%v falls in bucket %d but fits in the one before", d, i)
+		}
+		if err := latencyUpperBound(i) - d; err > d/latencySub {
+			t.Fatalf("%v reported as %v, more than 1/%d off", d, latencyUpperBound(i), latencySub)
+		}
+	}
+	if i := latencyBucket(time.Hour); i != latencyBuckets-1 {
+		t.Errorf("expected durations past the range in the last bucket, got %d", i)
+	}
+	if i := latencyBucket(-time.Second); i != 0 {
+		t.Errorf("expected negative durations in the first bucket, got %d", i)
+	}
+}
+
+func TestLatencyHistogram_Percentiles(t *testing.T) {
+	var h latencyHistogram
+	for i := 1; i <= 1000; i++ {
+		h.counts[latencyBucket(time.Duration(i)*time.Microsecond)].Add(1)
+	}
+
+	s := h.summary()
+	if s.Count != 1000 {
+		t.Fatalf("expected 1000 durations, got %d", s.Count)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want time.Duration
+	}{
+		{"p50", s.P50, 500 * time.Microsecond},
+		{"p90", s.P90, 900 * time.Microsecond},
+		{"p99", s.P99, 990 * time.Microsecond},
+		{"p999", s.P999, 999 * time.Microsecond},
+	} {
+		if c.got < c.want || c.got > c.want+c.want/latencySub {
+			t.Errorf("expected %s within 1/%d above %v, got %v", c.name, latencySub, c.want, c.got)
+		}
+	}
+}
+
+func TestLatencyStats_RecordsGetAndSet(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:         true,
+				CacheSize:             1000,
+				TTL:                   time.Minute,
+				EvictionPolicy:        policy,
+				EnableLatencyTracking: true,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 100; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+			for i := 0; i < 300; i++ {
+				cache.Get(fmt.Sprintf("k%d", i)) // Misses count too
+			}
+
+			stats := cache.LatencyStats()
+			if stats.Set.Count != 100 || stats.Get.Count != 300 {
+				t.Fatalf("expected 100 Sets and 300 Gets, got %d and %d", stats.Set.Count, stats.Get.Count)
+			}
+			for name, op := range map[string]OpLatency{"Get": stats.Get, "Set": stats.Set} {
+				if op.P50 > op.P90 || op.P90 > op.P99 || op.P99 > op.P999 {
+					t.Errorf("expected %s percentiles in order, got %+v", name, op)
+				}
+			}
+		})
+	}
+}
+
+func TestLatencyStats_Disabled(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+
+	if stats := cache.LatencyStats(); stats != (LatencyStats{}) {
+		t.Errorf("expected zero latency stats when disabled, got %+v", stats)
+	}
+	if cache.shards[0].latency != nil {
+		t.Error("expected no histograms when disabled")
+	}
+}
+
+// BenchmarkGet_LatencyTracking compares Get with tracking off, which must not read the
+// clock and match the plain Get benchmarks, against tracking on
+func BenchmarkGet_LatencyTracking(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("enabled=%v", enabled), func(b *testing.B) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:         true,
+				CacheSize:             10000,
+				TTL:                   time.Minute,
+				EnableLatencyTracking: enabled,
+			})
+			defer cache.Close()
+			for i := 0; i < 1000; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get("k42")
+			}
+		})
+	}
+}
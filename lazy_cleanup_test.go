@@ -0,0 +1,118 @@
+// lazy_cleanup_test.go: Tests for reclaiming expired entries on write
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestLazyCleanup_StartsNoGoroutine(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			n := goroutinesStarted(t, CacheConfig{
+				EnableCaching:            true,
+				CacheSize:                1000,
+				TTL:                      time.Minute,
+				MaxIdleTime:              time.Second,
+				EvictionPolicy:           policy,
+				DisableBackgroundCleanup: true,
+			})
+			if n != 0 {
+				t.Errorf("expected no cleanup goroutine with DisableBackgroundCleanup, got %d", n)
+			}
+		})
+	}
+}
+
+func TestLazyCleanup_WritesReclaimExpired(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:            true,
+				CacheSize:                1000,
+				ShardCount:               1,
+				TTL:                      time.Minute,
+				EvictionPolicy:           policy,
+				DisableBackgroundCleanup: true,
+				LazyCleanupBatch:         4,
+				Clock:                    clock,
+			})
+			defer cache.Close()
+			for i := 0; i < 100; i++ {
+				cache.Set(fmt.Sprintf("old%d", i), i)
+			}
+			clock.Advance(2 * time.Minute)
+
+			// Nothing reads the expired keys: only writes can free them. Each write
+			// examines 4 entries, at most one of them the fresh key.
+			for i := 0; i < 34; i++ {
+				cache.Set("fresh", i)
+			}
+			if n := cache.Len(); n != 1 {
+				t.Errorf("expected 34 writes to free all 100 expired entries, %d entries remain", n)
+			}
+			if n := cache.GetStats().Expirations; n != 100 {
+				t.Errorf("expected 100 expirations, got %d", n)
+			}
+		})
+	}
+}
+
+func TestLazyCleanup_BatchBoundsWork(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:            true,
+				CacheSize:                1000,
+				ShardCount:               1,
+				TTL:                      time.Minute,
+				EvictionPolicy:           policy,
+				DisableBackgroundCleanup: true, // LazyCleanupBatch unset: DefaultLazyCleanupBatch
+				Clock:                    clock,
+			})
+			defer cache.Close()
+			for i := 0; i < 100; i++ {
+				cache.Set(fmt.Sprintf("old%d", i), i)
+			}
+			clock.Advance(2 * time.Minute)
+
+			cache.Set("new", "v")
+			if n := cache.GetStats().Expirations; n == 0 || n > DefaultLazyCleanupBatch {
+				t.Errorf("expected one write to free 1 to %d entries, freed %d", DefaultLazyCleanupBatch, n)
+			}
+		})
+	}
+}
+
+func TestLazyCleanup_GetDropsExpired(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:            true,
+		CacheSize:                1000,
+		ShardCount:               1,
+		TTL:                      time.Minute,
+		EvictionPolicy:           "lru",
+		DisableBackgroundCleanup: true,
+		Clock:                    clock,
+	})
+	defer cache.Close()
+	cache.Set("a", 1)
+	clock.Advance(2 * time.Minute)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the expired entry to be missed")
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected the read to free the expired entry, %d entries remain", n)
+	}
+}
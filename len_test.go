@@ -0,0 +1,130 @@
+// len_test.go: Tests for the entry counters behind Len
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// checkLen fails unless Len matches the keys Keys returns, each listed once
+func checkLen(t *testing.T, cache *StrategicCache, step int) {
+	t.Helper()
+	keys := cache.Keys()
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			t.Fatalf("step %d: key %s listed twice", step, key)
+		}
+		seen[key] = true
+	}
+	if cache.Len() != len(keys) {
+		t.Fatalf("step %d: expected Len %d, the number of keys, got %d", step, len(keys), cache.Len())
+	}
+}
+
+func TestLen_MatchesKeysAfterRandomOperations(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:            true,
+				CacheSize:                200,
+				ShardCount:               4,
+				TTL:                      time.Hour,
+				EvictionPolicy:           policy,
+				DisableBackgroundCleanup: true,
+				Clock:                    clock,
+			})
+			defer cache.Close()
+
+			rng := rand.New(rand.NewSource(42))
+			for step := 0; step < 20000; step++ {
+				key := fmt.Sprintf("k%d", rng.Intn(500))
+				switch op := rng.Intn(100); {
+				case op < 40:
+					cache.Set(key, step)
+				case op < 50:
+					cache.SetWithOptions(key, step, WithTTL(time.Duration(1+rng.Intn(10))*time.Second))
+				case op < 80:
+					cache.Get(key)
+				case op < 95:
+					cache.Delete(key)
+				case op < 98:
+					clock.Advance(time.Second)
+				case op < 99:
+					cache.ClearShard(rng.Intn(len(cache.ShardStats())))
+				default:
+					cache.ClearExpired()
+				}
+
+				// Len counts expired entries until they are swept, Keys does not
+				if step%500 == 0 {
+					cache.ClearExpired()
+					checkLen(t, cache, step)
+				}
+			}
+			cache.ClearExpired()
+			checkLen(t, cache, -1)
+
+			cache.Clear()
+			if n := cache.Len(); n != 0 {
+				t.Errorf("expected Len 0 after Clear, got %d", n)
+			}
+		})
+	}
+}
+
+func TestLen_WTinyLFUConcurrentPromotion(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, ShardCount: 1, EvictionPolicy: "wtinylfu", TTL: time.Hour})
+	defer cache.Close()
+
+	// Reads promote keys from probation to protected while writes update the same keys
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < 20000; i++ {
+				key := fmt.Sprintf("k%d", rng.Intn(300))
+				if rng.Intn(2) == 0 {
+					cache.Get(key)
+				} else {
+					cache.Set(key, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	checkLen(t, cache, -1)
+	if n := cache.Len(); n > 100 {
+		t.Errorf("expected at most the capacity of 100 entries, got %d", n)
+	}
+}
+
+func BenchmarkLen(b *testing.B) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		b.Run(policy, func(b *testing.B) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10000, ShardCount: 64, EvictionPolicy: policy, TTL: time.Hour})
+			defer cache.Close()
+			for i := 0; i < 5000; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Len()
+			}
+		})
+	}
+}
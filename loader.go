@@ -0,0 +1,107 @@
+// loader.go: Compute-on-miss loading for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightCall is a load in progress, shared by every caller asking for the same key
+type flightCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// flightGroup runs at most one load per key at a time; callers arriving while a load
+// is running wait for it and receive its result
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn for key unless a call for key is already running, in which case it waits
+// for that call and returns its result
+func (g *flightGroup) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+	call := &flightCall{err: ErrLoaderPanicked} // Replaced unless fn panics
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.value, call.err = fn()
+	return call.value, call.err
+}
+
+// LoadOrCompute returns the value cached for key or, on a miss, calls fn to compute it
+// and stores the result with opts. Concurrent callers missing the same key share a single
+// fn call. Errors from fn are returned to every waiting caller and are not cached. A value
+// the cache cannot hold (for example one rejected by the admission policy) is still returned.
+// With CacheConfig.BreakerThreshold, fn failures count towards opening the circuit breaker,
+// and while it is open misses fail with ErrBackendUnavailable without calling fn. Keys
+// stored with SetNegative, or by WithNegativeCache, return ErrNegativeEntry without calling fn.
+func (sc *StrategicCache) LoadOrCompute(key string, fn func(key string) (interface{}, error), opts ...SetOption) (interface{}, error) {
+	value, err := sc.GetE(key)
+	if err == nil {
+		return value, nil
+	}
+	if errors.Is(err, ErrNegativeEntry) || !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrExpired) {
+		return nil, err
+	}
+
+	value, err = sc.loads.do(key, func() (interface{}, error) {
+		// Another caller may have stored the key since our lookup
+		if value, err := sc.getLocal(sc.HashKey(key)); err == nil || errors.Is(err, ErrNegativeEntry) {
+			return value, err
+		}
+		o := defaultSetOptions
+		for _, opt := range opts {
+			opt(&o)
+		}
+		// A not-found error is an answer from the loader, not a failure of it
+		var value interface{}
+		var notFound error
+		err := sc.throughBreaker(context.Background(), func() (err error) {
+			value, err = fn(key)
+			if err != nil && o.negativeMatch != nil && o.negativeMatch(err) {
+				notFound, err = err, nil
+			}
+			return err
+		})
+		if notFound != nil {
+			_ = sc.setNegative(key, o.negativeTTL)
+			return nil, notFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		// A WithTTL counts from when the value is stored
+		o.fill = true
+		_ = sc.setThrough(context.Background(), sc.HashKey(key), value, o)
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sc.copyOnRead(value, true)
+}
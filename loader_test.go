@@ -0,0 +1,98 @@
+// loader_test.go: Tests for LoadOrCompute
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCompute_CachesResult(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour, EvictionPolicy: policy})
+			defer cache.Close()
+
+			var calls atomic.Int64
+			compute := func(key string) (interface{}, error) {
+				calls.Add(1)
+				return "value of " + key, nil
+			}
+			for i := 0; i < 3; i++ {
+				if v, err := cache.LoadOrCompute("k", compute); err != nil || v != "value of k" {
+					t.Fatalf("expected the computed value, got %v (err %v)", v, err)
+				}
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("expected 1 computation, got %d", n)
+			}
+		})
+	}
+}
+
+func TestLoadOrCompute_ErrorsAreNotCached(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100})
+	defer cache.Close()
+
+	boom := errors.New("boom")
+	if _, err := cache.LoadOrCompute("k", func(string) (interface{}, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected the loader error, got %v", err)
+	}
+	if v, err := cache.LoadOrCompute("k", func(string) (interface{}, error) { return 1, nil }); err != nil || v != 1 {
+		t.Errorf("expected the failed key to be computed again, got %v (err %v)", v, err)
+	}
+}
+
+func TestLoadOrCompute_PanicReleasesWaiters(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100})
+	defer cache.Close()
+
+	release := make(chan struct{})
+	go func() {
+		defer func() { _ = recover() }()
+		_, _ = cache.LoadOrCompute("k", func(string) (interface{}, error) {
+			<-release
+			panic("loader failure")
+		})
+	}()
+	time.Sleep(10 * time.Millisecond) // Let the panicking load start first
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.LoadOrCompute("k", func(string) (interface{}, error) { return 1, nil })
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-done:
+		if err != nil && !errors.Is(err, ErrLoaderPanicked) {
+			t.Errorf("expected nil or ErrLoaderPanicked, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a panicking loader left its waiters blocked")
+	}
+}
+
+func TestLoadOrCompute_Closed(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100})
+	cache.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := cache.LoadOrCompute("k", func(string) (interface{}, error) { return 1, nil }); !errors.Is(err, ErrCacheClosed) {
+			t.Errorf("expected ErrCacheClosed, got %v", err)
+		}
+	}()
+	wg.Wait()
+}
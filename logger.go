@@ -0,0 +1,49 @@
+// logger.go: Logging hooks for errors and notable cache events
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "log/slog"
+
+// largeSweep is the number of expired entries a cleanup sweep must remove to be
+// logged at Info level; smaller sweeps are logged at Debug level
+const largeSweep = 1000
+
+// *slog.Logger already has the method set of Logger
+var _ Logger = (*slog.Logger)(nil)
+
+// SlogLogger returns a Logger writing to l, or to slog.Default() when l is nil. Fields
+// are passed on as slog's alternating keys and values.
+func SlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
+
+// noopLogger discards everything; it stands in for a nil CacheConfig.Logger
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, fields ...interface{}) {}
+func (noopLogger) Info(msg string, fields ...interface{})  {}
+func (noopLogger) Warn(msg string, fields ...interface{})  {}
+func (noopLogger) Error(msg string, fields ...interface{}) {}
+
+// logUndecodable reports a stored value that can no longer be decoded, which reads
+// then treat as missing
+func (sc *StrategicCache) logUndecodable(key string) {
+	sc.logger.Error("metis: cannot decode cached value", "key", key)
+}
+
+// logSweep reports the expired entries removed by a cleanup sweep
+func (sc *StrategicCache) logSweep(removed int) {
+	switch {
+	case removed >= largeSweep:
+		sc.logger.Info("metis: cleanup sweep removed expired entries", "removed", removed)
+	case removed > 0:
+		sc.logger.Debug("metis: cleanup sweep removed expired entries", "removed", removed)
+	}
+}
@@ -0,0 +1,226 @@
+// logger_test.go: Tests for the logging hooks
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// logRecord is one message captured by captureLogger
+type logRecord struct {
+	level  string
+	msg    string
+	fields []interface{}
+}
+
+// captureLogger records every message with its level and fields
+type captureLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *captureLogger) log(level, msg string, fields []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, logRecord{level, msg, fields})
+}
+
+func (l *captureLogger) Debug(msg string, fields ...interface{}) { l.log("debug", msg, fields) }
+func (l *captureLogger) Info(msg string, fields ...interface{})  { l.log("info", msg, fields) }
+func (l *captureLogger) Warn(msg string, fields ...interface{})  { l.log("warn", msg, fields) }
+func (l *captureLogger) Error(msg string, fields ...interface{}) { l.log("error", msg, fields) }
+
+// find returns the first record at level whose message contains msg
+func (l *captureLogger) find(level, msg string) (logRecord, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range l.records {
+		if r.level == level && strings.Contains(r.msg, msg) {
+			return r, true
+		}
+	}
+	return logRecord{}, false
+}
+
+// field returns the value following key in the record's fields
+func (r logRecord) field(key string) interface{} {
+	for i := 0; i+1 < len(r.fields); i += 2 {
+		if r.fields[i] == key {
+			return r.fields[i+1]
+		}
+	}
+	return nil
+}
+
+// failingSerializer rejects every value
+type failingSerializer struct{}
+
+func (failingSerializer) Marshal(value interface{}) ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+func (failingSerializer) Unmarshal(data []byte, value *interface{}) error {
+	return errors.New("cannot unmarshal")
+}
+
+func TestLogger_SerializationFailureOnSet(t *testing.T) {
+	logger := &captureLogger{}
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		TTL:               time.Minute,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		Serializer:        failingSerializer{},
+		Logger:            logger,
+	})
+	defer cache.Close()
+
+	if cache.Set("user", struct{ Name string }{"ann"}) {
+		t.Fatal("expected Set to fail with a failing serializer")
+	}
+	r, ok := logger.find("warn", "cannot serialize")
+	if !ok {
+		t.Fatalf("expected a serialization warning, got %+v", logger.records)
+	}
+	if r.field("key") != "user" || r.field("error") == nil {
+		t.Errorf("expected the key and error in the fields, got %v", r.fields)
+	}
+}
+
+func TestLogger_CorruptedEntryOnGet(t *testing.T) {
+	logger := &captureLogger{}
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		TTL:               time.Minute,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		DisableChecksums:  true, // Let the corrupted bytes reach the decoder
+		Logger:            logger,
+	})
+	defer cache.Close()
+
+	cache.Set("k", "value")
+	shard := cache.getShard("k")
+	shard.mu.Lock()
+	shard.data["k"].Data = []byte{0xff, 0x00, 0x13}
+	shard.mu.Unlock()
+
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("expected a corrupted entry to read as missing")
+	}
+	r, ok := logger.find("error", "cannot decode")
+	if !ok {
+		t.Fatalf("expected a decode error, got %+v", logger.records)
+	}
+	if r.field("key") != "k" {
+		t.Errorf("expected the key in the fields, got %v", r.fields)
+	}
+}
+
+func TestLogger_CleanupSweep(t *testing.T) {
+	logger := &captureLogger{}
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:            true,
+		CacheSize:                largeSweep * 10,
+		TTL:                      time.Minute,
+		EvictionPolicy:           "lru",
+		DisableBackgroundCleanup: true,
+		Clock:                    clock,
+		Logger:                   logger,
+	})
+	defer cache.Close()
+
+	cache.Set("small", 1)
+	clock.Advance(2 * time.Minute)
+	cache.sweepExpired()
+	if r, ok := logger.find("debug", "cleanup sweep"); !ok || r.field("removed") != 1 {
+		t.Fatalf("expected a debug record for a small sweep, got %+v", logger.records)
+	}
+	if _, ok := logger.find("info", "cleanup sweep"); ok {
+		t.Fatal("expected no info record for a small sweep")
+	}
+
+	for i := 0; i < largeSweep; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	clock.Advance(2 * time.Minute)
+	cache.sweepExpired()
+	if r, ok := logger.find("info", "cleanup sweep"); !ok || r.field("removed") != largeSweep {
+		t.Fatalf("expected an info record for %d removed entries, got %+v", largeSweep, r)
+	}
+}
+
+func TestLogger_SnapshotFailure(t *testing.T) {
+	logger := &captureLogger{}
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching: true,
+		CacheSize:     100,
+		TTL:           time.Minute,
+		SnapshotPath:  filepath.Join(t.TempDir(), "missing", "dir", "cache.snap"),
+		Logger:        logger,
+	})
+	cache.Set("k", 1)
+	cache.Close() // Writes the final snapshot
+
+	if _, ok := logger.find("error", "snapshot failed"); !ok {
+		t.Fatalf("expected a snapshot error, got %+v", logger.records)
+	}
+}
+
+func TestLogger_DefaultsToNoop(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		TTL:               time.Minute,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		Serializer:        failingSerializer{},
+	})
+	defer cache.Close()
+
+	if _, ok := cache.logger.(noopLogger); !ok {
+		t.Fatalf("expected the no-op logger without CacheConfig.Logger, got %T", cache.logger)
+	}
+	cache.Set("k", struct{ N int }{1}) // Logs without panicking
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := SlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		TTL:               time.Minute,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		Serializer:        failingSerializer{},
+		Logger:            logger,
+	})
+	defer cache.Close()
+
+	cache.Set("user:1", struct{ N int }{1})
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "key=user:1") {
+		t.Errorf("expected a slog warning with the key, got %q", out)
+	}
+
+	if SlogLogger(nil) != slog.Default() {
+		t.Error("expected SlogLogger(nil) to use slog.Default()")
+	}
+}
@@ -0,0 +1,64 @@
+// memoize.go: Function memoization for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "time"
+
+// MemoizeOption customizes a function wrapped by Memoize
+type MemoizeOption func(*memoizeOptions)
+
+// memoizeOptions holds the settings applied by MemoizeOption
+type memoizeOptions struct {
+	ttl    time.Duration
+	prefix string
+}
+
+// WithMemoizeTTL sets the TTL of memoized results (0 = the cache TTL)
+func WithMemoizeTTL(ttl time.Duration) MemoizeOption {
+	return func(o *memoizeOptions) { o.ttl = ttl }
+}
+
+// WithKeyPrefix prepends prefix to every key, so several memoized functions can share a cache
+func WithKeyPrefix(prefix string) MemoizeOption {
+	return func(o *memoizeOptions) { o.prefix = prefix }
+}
+
+// Memoize wraps fn so results are served from cache, computing each missing key with
+// LoadOrCompute: concurrent calls for the same key run fn once and share its result,
+// and errors are returned without being cached. A cached value that is not a T, such as
+// one stored under the same key by other code, is recomputed and replaced.
+func Memoize[T any](cache *StrategicCache, fn func(key string) (T, error), opts ...MemoizeOption) func(key string) (T, error) {
+	var o memoizeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	load := func(key string) (interface{}, error) {
+		return fn(key[len(o.prefix):])
+	}
+
+	return func(key string) (T, error) {
+		var zero T
+		cacheKey := o.prefix + key
+		value, err := cache.LoadOrCompute(cacheKey, load, WithTTL(o.ttl))
+		if err != nil {
+			return zero, err
+		}
+		if v, ok := value.(T); ok {
+			return v, nil
+		}
+		if value == nil {
+			return zero, nil // fn returned a nil pointer, map, slice or interface
+		}
+
+		v, err := fn(key)
+		if err != nil {
+			return zero, err
+		}
+		cache.SetWithOptions(cacheKey, v, WithTTL(o.ttl))
+		return v, nil
+	}
+}
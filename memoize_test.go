@@ -0,0 +1,124 @@
+// memoize_test.go: Tests for the Memoize helper
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+type memoUser struct {
+	ID   string
+	Name string
+}
+
+func TestMemoize_ConcurrentCallsRunOnce(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour, EvictionPolicy: policy})
+			defer cache.Close()
+
+			const callers = 50
+			var calls, started atomic.Int64
+			lookup := Memoize(cache, func(id string) (*memoUser, error) {
+				calls.Add(1)
+				// Hold the load until every caller has arrived, so all of them miss
+				for started.Load() < callers {
+					time.Sleep(time.Millisecond)
+				}
+				time.Sleep(10 * time.Millisecond)
+				return &memoUser{ID: id, Name: strings.ToUpper(id)}, nil
+			})
+
+			var wg sync.WaitGroup
+			results := make([]*memoUser, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					started.Add(1)
+					user, err := lookup("ada")
+					if err != nil {
+						t.Errorf("lookup: %v", err)
+					}
+					results[i] = user
+				}(i)
+			}
+			wg.Wait()
+
+			if n := calls.Load(); n != 1 {
+				t.Errorf("expected the wrapped function to run once for %d concurrent calls, ran %d times", callers, n)
+			}
+			for i, user := range results {
+				if user == nil || user.Name != "ADA" {
+					t.Fatalf("caller %d got %+v", i, user)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoize_TTLAndPrefix(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour, Clock: clock})
+	defer cache.Close()
+
+	var calls atomic.Int64
+	square := Memoize(cache, func(key string) (int, error) {
+		calls.Add(1)
+		return len(key) * len(key), nil
+	}, WithMemoizeTTL(30*time.Millisecond), WithKeyPrefix("square:"))
+
+	if v, err := square("abc"); err != nil || v != 9 {
+		t.Fatalf("expected 9, got %v (err %v)", v, err)
+	}
+	if _, ok := cache.Get("square:abc"); !ok {
+		t.Error("expected the result under the prefixed key")
+	}
+	_, _ = square("abc")
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected a cached result within the TTL, got %d calls", n)
+	}
+	clock.Advance(60 * time.Millisecond)
+	_, _ = square("abc")
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected a new call after the TTL, got %d calls", n)
+	}
+}
+
+func TestMemoize_ErrorsAndForeignValues(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100})
+	defer cache.Close()
+
+	fail := true
+	boom := errors.New("boom")
+	count := Memoize(cache, func(key string) (int, error) {
+		if fail {
+			return 0, boom
+		}
+		return len(key), nil
+	})
+
+	if _, err := count("abcd"); !errors.Is(err, boom) {
+		t.Fatalf("expected the error, got %v", err)
+	}
+	fail = false
+	if v, err := count("abcd"); err != nil || v != 4 {
+		t.Errorf("expected the error not to be cached, got %v (err %v)", v, err)
+	}
+
+	cache.Set("other", "not an int")
+	if v, err := count("other"); err != nil || v != 5 {
+		t.Errorf("expected a value of another type to be recomputed, got %v (err %v)", v, err)
+	}
+}
@@ -0,0 +1,141 @@
+// memory_budget_test.go: Tests for MaxMemoryBytes eviction
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaxMemoryBytes_ClassicPathEvictsToFit(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+		MaxMemoryBytes: 100,
+	})
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), strings.Repeat("x", 20))
+	}
+
+	stats := cache.GetStats()
+	if stats.MemoryBytes > 100 {
+		t.Errorf("memory budget exceeded: %d bytes", stats.MemoryBytes)
+	}
+	if stats.Keys != 5 {
+		t.Errorf("expected 5 entries of 20 bytes to fit, got %d", stats.Keys)
+	}
+	if stats.MemoryEvictedBytes != 100 {
+		t.Errorf("expected 100 bytes evicted for memory, got %d", stats.MemoryEvictedBytes)
+	}
+
+	// LRU order decides which entries went first
+	if _, ok := cache.Get("key0"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	if _, ok := cache.Get("key9"); !ok {
+		t.Error("expected newest entry to survive")
+	}
+}
+
+func TestMaxMemoryBytes_ClassicPathRejectsOversized(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     2,
+		EvictionPolicy: "lru",
+		MaxMemoryBytes: 100, // 50 bytes per shard
+	})
+	defer cache.Close()
+
+	cache.Set("small", "tiny")
+	if cache.Set("huge", strings.Repeat("x", 60)) {
+		t.Error("expected value larger than the shard budget to be rejected")
+	}
+	if _, ok := cache.Get("small"); !ok {
+		t.Error("rejecting an oversized value must not evict existing entries")
+	}
+}
+
+func TestMaxMemoryBytes_ClassicPathUpdateInPlace(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+		MaxMemoryBytes: 50,
+	})
+	defer cache.Close()
+
+	cache.Set("a", strings.Repeat("a", 20))
+	cache.Set("b", strings.Repeat("b", 20))
+
+	// Growing "b" must evict "a", not "b" itself
+	cache.Set("b", strings.Repeat("b", 40))
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected a to be evicted to make room for the larger b")
+	}
+	if v, ok := cache.Get("b"); !ok || len(v.(string)) != 40 {
+		t.Errorf("expected updated b to be stored, got %v", v)
+	}
+	if got := cache.GetStats().MemoryBytes; got != 40 {
+		t.Errorf("expected 40 bytes tracked, got %d", got)
+	}
+}
+
+func TestMaxMemoryBytes_WTinyLFUPath(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     1,
+		EvictionPolicy: "wtinylfu",
+		MaxMemoryBytes: 200,
+	})
+	defer cache.Close()
+
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), strings.Repeat("x", 10))
+	}
+
+	stats := cache.GetStats()
+	if stats.MemoryBytes > 200 {
+		t.Errorf("memory budget exceeded: %d bytes", stats.MemoryBytes)
+	}
+	if stats.MemoryEvictedBytes == 0 {
+		t.Error("expected bytes evicted for memory reasons")
+	}
+	if stats.Keys*10 != int(stats.MemoryBytes) {
+		t.Errorf("keys (%d) and tracked bytes (%d) disagree", stats.Keys, stats.MemoryBytes)
+	}
+
+	if cache.Set("huge", strings.Repeat("x", 300)) {
+		t.Error("expected value larger than the budget to be rejected")
+	}
+}
+
+func TestMaxMemoryBytes_WTinyLFUUpdateKeepsKey(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+	wt.SetMaxMemoryBytes(30)
+
+	wt.Set("a", strings.Repeat("a", 10))
+	wt.Set("b", strings.Repeat("b", 10))
+	wt.Set("b", strings.Repeat("b", 25))
+
+	if _, ok := wt.Get("b"); !ok {
+		t.Error("updated key must not be evicted by its own growth")
+	}
+	if _, ok := wt.Get("a"); ok {
+		t.Error("expected a to be evicted to make room")
+	}
+	if got := wt.MemoryBytes(); got != 25 {
+		t.Errorf("expected 25 bytes tracked, got %d", got)
+	}
+}
@@ -14,11 +14,13 @@ import (
 	randc "crypto/rand"
 	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
-	"hash/crc32"
-	"io"
 	"reflect"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -53,15 +55,112 @@ func init() {
 
 // cacheShard represents a single shard of the cache, with its own map, mutex, and LRU/LFU list
 type cacheShard struct {
-	data   map[string]*CacheEntry
-	mu     sync.RWMutex
-	ll     *list.List // Doubly-linked list for LRU/LFU optimization
-	hits   int64
-	misses int64
+	data map[string]*CacheEntry
+	mu   sync.RWMutex
+	ll   *list.List // Doubly-linked list for LRU/LFU optimization
+	// hits and misses are atomic because Gets served under the read lock count them too
+	hits   atomic.Int64
+	misses atomic.Int64
+	// count mirrors len(data) for Len, which reads it without taking mu
+	count       atomic.Int64
+	evictions   int64
+	expirations int64
+	// idleExpirations counts entries dropped because they went unread longer than MaxIdleTime
+	idleExpirations int64
+	pinned          int   // Entries exempted from eviction by Pin
+	memoryBytes     int64 // Sum of CacheEntry.Size for entries in this shard
+	// lowPriority and highPriority count entries stored with PriorityLow and PriorityHigh
+	lowPriority, highPriority int
+	// memoryEvictedBytes counts bytes evicted to stay within MaxMemoryBytes
+	memoryEvictedBytes int64
+	// extraCost is the sum of (Cost - 1) over entries, so the shard's total cost
+	// is len(data) + extraCost and unit-cost entries need no bookkeeping
+	extraCost int64
+	// sketch records access frequencies for TinyLFU admission (nil unless enabled)
+	sketch *FastTinyLFU
+	// Compression effectiveness of the entries currently stored (see CompressionStats)
+	compressedEntries int64
+	skippedEntries    int64
+	rawBytes          int64
+	compressedBytes   int64
+	// evictQueue holds evicted entries until shard.mu is released (see OnEvict)
+	evictQueue evictQueue
+	// reads queues hits made under shard.mu.RLock until drainReads applies them
+	reads readBuffer
+	// window counts recent hits, misses and evictions (nil unless StatsWindow is set)
+	window *statsWindow
+	// latency records Get and Set durations for keys of this shard (nil unless
+	// EnableLatencyTracking is set)
+	latency *shardLatency
+	// hotKeys follows the most read keys of this shard (nil unless TrackHotKeys is set)
+	hotKeys *hotKeyTracker
+}
+
+// removeEntry unlinks an entry from the shard's map and list and updates byte accounting.
+// The caller must hold shard.mu.
+func (shard *cacheShard) removeEntry(key string, entry *CacheEntry) {
+	if entry.llElem != nil {
+		shard.ll.Remove(entry.llElem)
+	}
+	delete(shard.data, key)
+	shard.count.Add(-1)
+	if entry.pinned {
+		shard.pinned--
+	}
+	shard.memoryBytes -= int64(entry.Size)
+	shard.extraCost -= entry.cost() - 1
+	shard.trackCompression(entry, -1)
+	shard.trackPriority(entry, -1)
+}
+
+// trackPriority adds (sign 1) or removes (sign -1) an entry from the shard's priority
+// counts. The caller must hold shard.mu.
+func (shard *cacheShard) trackPriority(entry *CacheEntry, sign int) {
+	switch entry.Priority {
+	case PriorityLow:
+		shard.lowPriority += sign
+	case PriorityHigh:
+		shard.highPriority += sign
+	}
+}
+
+// lowestPriority returns the lowest priority among the shard's entries.
+// The caller must hold shard.mu.
+func (shard *cacheShard) lowestPriority() Priority {
+	switch {
+	case shard.lowPriority > 0:
+		return PriorityLow
+	case len(shard.data) > shard.highPriority:
+		return PriorityNormal
+	default:
+		return PriorityHigh
+	}
+}
+
+// trackCompression adds (sign 1) or removes (sign -1) an entry's contribution to the
+// shard's compression statistics. The caller must hold shard.mu.
+func (shard *cacheShard) trackCompression(entry *CacheEntry, sign int64) {
+	switch {
+	case !entry.Compressed:
+	case entry.compressionSkipped:
+		shard.skippedEntries += sign
+	default:
+		shard.compressedEntries += sign
+		shard.rawBytes += sign * int64(entry.rawSize)
+		shard.compressedBytes += sign * int64(entry.Size)
+	}
+}
+
+// totalCost returns the cumulative cost of the entries in the shard.
+// The caller must hold shard.mu.
+func (shard *cacheShard) totalCost() int64 {
+	return int64(len(shard.data)) + shard.extraCost
 }
 
 // EvictionPolicy defines the interface for cache eviction strategies
-// The policy decides which key to evict when the cache is full
+// The policy decides which key to evict when the cache is full.
+// ll holds the shard's *CacheEntry values in recency order (front = most recent);
+// both arguments must be treated as read-only. Returning "" skips eviction.
 type EvictionPolicy interface {
 	EvictKey(cache map[string]*CacheEntry, ll *list.List) string
 }
@@ -118,6 +217,43 @@ type NeverAdmitPolicy struct{}
 // Allow always returns false
 func (p *NeverAdmitPolicy) Allow(key string, value interface{}) bool { return false }
 
+// TinyLFUAdmissionPolicy admits a new key into a full shard only when its estimated
+// access frequency beats the victim chosen by the eviction policy.
+// The decision needs the victim, so it is made per shard in Set; Allow always admits.
+type TinyLFUAdmissionPolicy struct{}
+
+// Allow always returns true; see TinyLFUAdmissionPolicy
+func (p *TinyLFUAdmissionPolicy) Allow(key string, value interface{}) bool { return true }
+
+// Size-aware admission defaults
+const (
+	DefaultSizeAwareMaxSize     = 4096 // Bytes; larger values are refused under pressure
+	DefaultSizeAwareUtilization = 0.9  // Shard fill ratio that counts as pressure
+)
+
+// SizeAwareAdmissionPolicy rejects values larger than MaxSize once the shard that would
+// hold them is more than Utilization full, so large values cannot push out many small
+// ones under pressure. Below the threshold every value is admitted.
+type SizeAwareAdmissionPolicy struct {
+	MaxSize     int     // Largest value size in bytes admitted under pressure
+	Utilization float64 // Shard fill ratio (0.0-1.0) above which larger values are rejected
+	// FillRatio reports the fill ratio of the shard that would hold key.
+	// NewStrategicCache sets it when nil; a nil FillRatio treats every shard as full.
+	FillRatio func(key string) float64
+}
+
+// Allow rejects values above MaxSize while the target shard is above Utilization
+func (p *SizeAwareAdmissionPolicy) Allow(key string, value interface{}) bool {
+	if p.MaxSize <= 0 {
+		return true
+	}
+	// Check the fill ratio first: it is cheaper than sizing the value
+	if p.FillRatio != nil && p.FillRatio(key) <= p.Utilization {
+		return true
+	}
+	return calculateSize(value) <= p.MaxSize
+}
+
 // SecureFloat64 returns a cryptographically secure random float64 in [0,1)
 func SecureFloat64() float64 {
 	var b [8]byte
@@ -146,27 +282,66 @@ type StrategicCache struct {
 	policy     EvictionPolicy
 	admission  AdmissionPolicy
 	shardCount uint32
+	hasher     Hasher     // Selects the shard of each key (CacheConfig.KeyHasher)
 	entryPool  *EntryPool // Object pool for CacheEntry reuse
 	wtinylfu   *WTinyLFU  // W-TinyLFU eviction policy (when enabled)
+	arc        *ARC       // ARC eviction policy (when enabled)
+	codec      Codec      // Compression codec for new values (when EnableCompression is set)
+	serializer Serializer // Encodes non-primitive values for compression
+	// shardMemoryBudget is each shard's share of MaxMemoryBytes (0 = unlimited)
+	shardMemoryBudget int64
+	// tuned holds the settings changed by UpdateConfig (see tuning); tuneMu serializes updates
+	tuned  atomic.Pointer[tuning]
+	tuneMu sync.Mutex
+	// snapshotErr is the result of the last background snapshot and onSnapshotError the
+	// handler set by OnSnapshotError, both guarded by snapshotMu
+	snapshotErr     error
+	onSnapshotError func(error)
+	snapshotMu      sync.Mutex
+	// writeBehind queues Backend writes when CacheConfig.WriteBehind is set (nil otherwise)
+	writeBehind *writeBehind
+	// loads deduplicates concurrent LoadOrCompute calls for the same key
+	loads flightGroup
+	// keyLocks holds the per-key mutexes of LockKey
+	keyLocks *keyLocks
+	// onEvict is the OnEvict function, guarded by evictMu with the handlers it installs
+	onEvict func(key string, value interface{})
+	evictMu sync.Mutex
+	// tags indexes the keys stored WithTags (nil until the first one)
+	tags atomic.Pointer[tagIndex]
+	// deps indexes the keys stored WithDependsOn by parent (nil until the first one)
+	deps atomic.Pointer[tagIndex]
+	// negativeHits counts reads of negative entries (see SetNegative)
+	negativeHits atomic.Int64
+	// corruptedEntries counts compressed entries dropped for a checksum mismatch
+	corruptedEntries atomic.Int64
+	// chunkGen numbers the values stored in chunks (see CacheConfig.ChunkThreshold)
+	chunkGen atomic.Uint64
+	// readOnly rejects writes while set, and readOnlyRejected counts them (see SetReadOnly)
+	readOnly         atomic.Bool
+	readOnlyRejected atomic.Int64
+	// breaker fails backend and loader calls fast after repeated failures (nil unless
+	// CacheConfig.BreakerThreshold is set)
+	breaker *breaker
+	// clock stamps and checks expiration and drives the cleanup routines (CacheConfig.Clock)
+	clock Clock
+	// logger receives errors and notable events (CacheConfig.Logger, or a no-op)
+	logger Logger
+	// lazyCleanup is how many entries each Set examines for expiration (0 = none, the
+	// cleanup goroutine reclaims them; see CacheConfig.DisableBackgroundCleanup)
+	lazyCleanup int
+	// windowStart is when the StatsWindow counters started (see WindowedStats)
+	windowStart time.Time
+	// tracer records operations between StartTrace and StopTrace (nil otherwise)
+	tracer atomic.Pointer[tracer]
+	// invalidation is the bus Set and Delete publish to (see AttachInvalidationBus)
+	invalidation atomic.Pointer[invalidationLink]
 }
 
 // getShard returns the appropriate shard for a given key
 func (sc *StrategicCache) getShard(key string) *cacheShard {
-	var hash uint32
-
-	// Fast path for short strings (most common case)
-	if len(key) <= 8 {
-		// Simple hash for short strings
-		for i := 0; i < len(key); i++ {
-			hash = hash*31 + uint32(key[i])
-		}
-	} else {
-		// Use CRC32 for longer strings
-		hash = crc32.ChecksumIEEE([]byte(key))
-	}
-
 	// Safe conversion since shardCount is validated in constructor
-	shardIndex := int(hash % sc.shardCount)
+	shardIndex := int(sc.hasher.Hash(key) % uint64(sc.shardCount)) // nosec G115 - below shardCount
 	if shardIndex < 0 || shardIndex >= len(sc.shards) {
 		// Fallback to first shard if index is out of bounds
 		shardIndex = 0
@@ -174,6 +349,25 @@ func (sc *StrategicCache) getShard(key string) *cacheShard {
 	return &sc.shards[shardIndex]
 }
 
+// Defaults applied by NewStrategicCache to non-positive TTL and CleanupInterval
+const (
+	defaultTTL             = 10 * time.Minute // Longer TTL for better hit rates
+	defaultCleanupInterval = 2 * time.Minute  // Less frequent cleanup
+)
+
+// Bounds of the shard count chosen when CacheConfig.ShardCount is zero
+const (
+	MinAutoShardCount = 4
+	MaxAutoShardCount = 256
+)
+
+// autoShardCount returns the shard count for a ShardCount of zero: four shards per
+// GOMAXPROCS, rounded up to a power of two within [MinAutoShardCount, MaxAutoShardCount]
+func autoShardCount() int {
+	n := nextPowerOf2(runtime.GOMAXPROCS(0) * 4)
+	return min(max(n, MinAutoShardCount), MaxAutoShardCount)
+}
+
 // NewStrategicCache creates a new strategic cache with the given configuration
 func NewStrategicCache(config CacheConfig) *StrategicCache {
 	// Set optimized defaults for maximum performance
@@ -181,13 +375,13 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 		config.CacheSize = 10000 // Increased default cache size
 	}
 	if config.TTL <= 0 {
-		config.TTL = 10 * time.Minute // Longer TTL for better hit rates
+		config.TTL = defaultTTL
 	}
 	if config.CleanupInterval <= 0 {
-		config.CleanupInterval = 2 * time.Minute // Less frequent cleanup
+		config.CleanupInterval = defaultCleanupInterval
 	}
 	if config.ShardCount <= 0 {
-		config.ShardCount = 32 // More shards for better concurrency
+		config.ShardCount = autoShardCount() // W-TinyLFU and ARC get the same count below
 	}
 	if config.ShardCount > 1<<30 {
 		config.ShardCount = 1 << 30 // Limit to prevent overflow
@@ -214,6 +408,16 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 		ctx:        ctx,
 		cancel:     cancel,
 		shardCount: uint32(shardCount), // nosec G115 - Safe: shardCount is validated to be > 0 and <= MaxShardCount
+		clock:      config.Clock,
+		keyLocks:   newKeyLocks(shardCount),
+		hasher:     newHasher(config),
+	}
+	if sc.clock == nil {
+		sc.clock = realClock{}
+	}
+	sc.logger = config.Logger
+	if sc.logger == nil {
+		sc.logger = noopLogger{}
 	}
 
 	// Initialize shards
@@ -227,15 +431,163 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 	// Initialize EntryPool for CacheEntry reuse
 	sc.entryPool = NewEntryPool()
 
-	// Set eviction policy (W-TinyLFU is the best performing default for large caches)
+	// Split the memory budget evenly across shards
+	if config.MaxMemoryBytes > 0 {
+		sc.shardMemoryBudget = config.MaxMemoryBytes / int64(shardCount)
+		if sc.shardMemoryBudget < 1 {
+			sc.shardMemoryBudget = 1
+		}
+	}
+
+	// Unknown codec names fall back to gzip
+	if codec, ok := LookupCodec(config.CompressionCodec); ok {
+		sc.codec = codec
+	} else {
+		sc.codec = gzipCodec{}
+	}
+
+	// Gob remains the default serializer
+	sc.serializer = config.Serializer
+	if sc.serializer == nil {
+		sc.serializer = GobSerializer{}
+	}
+
+	// Out-of-range gzip settings fall back to the defaults
+	if config.CompressionMinSize <= 0 {
+		config.CompressionMinSize = DefaultCompressionMinSize
+	}
+	if config.CompressionLevel == 0 || config.CompressionLevel < gzip.HuffmanOnly || config.CompressionLevel > gzip.BestCompression {
+		config.CompressionLevel = DefaultCompressionLevel
+	}
+	sc.config.CompressionMinSize = config.CompressionMinSize
+	sc.config.CompressionLevel = config.CompressionLevel
+	if _, isGzip := sc.codec.(gzipCodec); isGzip {
+		sc.codec = gzipCodec{minSize: config.CompressionMinSize, level: config.CompressionLevel}
+	}
+
+	// Set eviction policy (W-TinyLFU is the best performing default for large caches).
+	// A custom policy takes precedence and always runs on the sharded path.
+	if config.CustomEvictionPolicy != nil {
+		sc.policy = config.CustomEvictionPolicy
+	} else {
+		sc.setNamedEvictionPolicy(config)
+	}
+
+	// Without the cleanup goroutine, writes reclaim expired entries instead
+	if config.DisableBackgroundCleanup {
+		sc.lazyCleanup = config.LazyCleanupBatch
+		if sc.lazyCleanup <= 0 {
+			sc.lazyCleanup = DefaultLazyCleanupBatch
+		}
+	}
+
+	if sc.wtinylfu != nil {
+		sc.wtinylfu.setClock(sc.clock)
+		sc.wtinylfu.setMaxIdle(config.MaxIdleTime)
+		sc.wtinylfu.setLazyCleanup(sc.lazyCleanup)
+	}
+	if sc.arc != nil {
+		sc.arc.setClock(sc.clock)
+		sc.arc.setMaxIdle(config.MaxIdleTime)
+		sc.arc.setLazyCleanup(sc.lazyCleanup)
+	}
+
+	if config.EnableLatencyTracking {
+		for i := range sc.shards {
+			sc.shards[i].latency = &shardLatency{}
+		}
+	}
+
+	// W-TinyLFU already counts reads in its admission sketch; the other paths count them here
+	if config.TrackHotKeys > 0 {
+		if sc.usesWTinyLFU() {
+			sc.wtinylfu.setHotKeys(config.TrackHotKeys)
+		} else {
+			for i := range sc.shards {
+				sc.shards[i].hotKeys = newHotKeyTracker(config.TrackHotKeys, nil)
+			}
+		}
+	}
+
+	// Windowed counters live on every shard, so recording never contends across shards
+	if config.StatsWindow > 0 {
+		buckets := config.StatsWindowBuckets
+		if buckets <= 0 {
+			buckets = DefaultStatsWindowBuckets
+		}
+		newWindow := func() *statsWindow { return newStatsWindow(config.StatsWindow, buckets, sc.clock) }
+		for i := range sc.shards {
+			sc.shards[i].window = newWindow()
+		}
+		if sc.wtinylfu != nil {
+			sc.wtinylfu.setStatsWindow(newWindow)
+		}
+		if sc.arc != nil {
+			sc.arc.setStatsWindow(newWindow)
+		}
+		sc.windowStart = sc.clock.Now()
+	}
+
+	// An evicted chunk or manifest must take the rest of its value with it
+	if config.ChunkThreshold > 0 {
+		sc.installEvictHandler()
+	}
+
+	// Set admission policy (always is the safest default)
+	if config.CustomAdmissionPolicy != nil {
+		sc.admission = config.CustomAdmissionPolicy
+	} else {
+		sc.setNamedAdmissionPolicy(config)
+	}
+	if p, ok := sc.admission.(*SizeAwareAdmissionPolicy); ok && p.FillRatio == nil {
+		p.FillRatio = sc.shardFillRatio
+	}
+
+	// Start the cleanup goroutine if TTL or an idle timeout is enabled
+	if (config.TTL > 0 || config.MaxIdleTime > 0) && !config.DisableBackgroundCleanup {
+		sc.wg.Add(1)
+		go sc.cleanupRoutine()
+	}
+
+	// Queue backend writes instead of making Set wait for them
+	if config.Backend != nil && config.WriteBehind {
+		sc.writeBehind = newWriteBehind(config)
+	}
+	sc.breaker = newBreaker(config, sc.clock, sc.logger)
+
+	// Save snapshots in the background and on Close
+	if config.SnapshotPath != "" {
+		sc.wg.Add(1)
+		go sc.snapshotRoutine()
+	}
+
+	return sc
+}
+
+// NewStrategicCacheE creates a cache like NewStrategicCache, but returns the errors from
+// CacheConfig.Validate instead of silently replacing invalid values
+func NewStrategicCacheE(config CacheConfig) (*StrategicCache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return NewStrategicCache(config), nil
+}
+
+// setNamedEvictionPolicy selects the eviction policy from CacheConfig.EvictionPolicy
+func (sc *StrategicCache) setNamedEvictionPolicy(config CacheConfig) {
 	switch config.EvictionPolicy {
 	case "lru":
 		sc.policy = &LRUPolicy{}
 	case "wtinylfu":
 		// Initialize W-TinyLFU (highest priority - best performance)
-		sc.wtinylfu = NewWTinyLFU(config.CacheSize, int(config.ShardCount))
+		sc.wtinylfu = NewWTinyLFUWithOptions(config.CacheSize, int(config.ShardCount), wtinylfuOptions(config))
 		sc.wtinylfu.SetTTL(config.TTL) // Set TTL for W-TinyLFU
-		sc.policy = &LRUPolicy{}       // W-TinyLFU handles its own eviction internally
+		sc.wtinylfu.SetMaxMemoryBytes(config.MaxMemoryBytes)
+		sc.policy = &LRUPolicy{} // W-TinyLFU handles its own eviction internally
+	case "arc":
+		sc.arc = NewARC(config.CacheSize, int(config.ShardCount))
+		sc.arc.SetTTL(config.TTL)
+		sc.policy = &LRUPolicy{} // ARC handles its own eviction internally
 	case "", "default":
 		// For small caches (< 1000), use LRU instead of W-TinyLFU
 		// W-TinyLFU works best with larger caches
@@ -243,16 +595,30 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 			sc.policy = &LRUPolicy{}
 		} else {
 			// Initialize W-TinyLFU for large caches
-			sc.wtinylfu = NewWTinyLFU(config.CacheSize, int(config.ShardCount))
+			sc.wtinylfu = NewWTinyLFUWithOptions(config.CacheSize, int(config.ShardCount), wtinylfuOptions(config))
 			sc.wtinylfu.SetTTL(config.TTL) // Set TTL for W-TinyLFU
-			sc.policy = &LRUPolicy{}       // W-TinyLFU handles its own eviction internally
+			sc.wtinylfu.SetMaxMemoryBytes(config.MaxMemoryBytes)
+			sc.policy = &LRUPolicy{} // W-TinyLFU handles its own eviction internally
 		}
 	default:
 		// Default to LRU for maximum compatibility
 		sc.policy = &LRUPolicy{}
 	}
+}
 
-	// Set admission policy (always is the safest default)
+// wtinylfuOptions extracts the W-TinyLFU segment layout from the configuration
+func wtinylfuOptions(config CacheConfig) WTinyLFUOptions {
+	return WTinyLFUOptions{
+		WindowRatio:    config.WindowRatio,
+		ProbationRatio: config.ProbationRatio,
+		AdaptiveWindow: config.AdaptiveWindow,
+		SketchDepth:    config.SketchDepth,
+		SketchWidth:    config.SketchWidth,
+	}
+}
+
+// setNamedAdmissionPolicy selects the admission policy from CacheConfig.AdmissionPolicy
+func (sc *StrategicCache) setNamedAdmissionPolicy(config CacheConfig) {
 	switch config.AdmissionPolicy {
 	case "never":
 		sc.admission = &NeverAdmitPolicy{}
@@ -263,6 +629,26 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 			probability = 0.5 // Only use default for negative values
 		}
 		sc.admission = &ProbabilisticAdmissionPolicy{Probability: probability}
+	case "tinylfu":
+		// Frequency sketch per shard, consulted when the shard is full
+		sc.admission = &TinyLFUAdmissionPolicy{}
+		if sc.wtinylfu == nil && sc.arc == nil {
+			maxShardSize := max(1, config.MaxShardSize)
+			for i := range sc.shards {
+				sc.shards[i].sketch = NewFastTinyLFU(maxShardSize)
+			}
+		}
+	case "size-aware":
+		// Refuse large values only while the target shard is under pressure
+		maxSize := config.SizeAwareMaxSize
+		if maxSize <= 0 {
+			maxSize = DefaultSizeAwareMaxSize
+		}
+		utilization := config.SizeAwareUtilization
+		if utilization <= 0 {
+			utilization = DefaultSizeAwareUtilization
+		}
+		sc.admission = &SizeAwareAdmissionPolicy{MaxSize: maxSize, Utilization: utilization}
 	case "always", "":
 		// Default to always for maximum compatibility
 		sc.admission = &AlwaysAdmitPolicy{}
@@ -270,226 +656,500 @@ func NewStrategicCache(config CacheConfig) *StrategicCache {
 		// Default to always for maximum compatibility
 		sc.admission = &AlwaysAdmitPolicy{}
 	}
+}
 
-	// Start cleanup goroutines if TTL is enabled
-	if config.TTL > 0 {
-		for i := 0; i < config.ShardCount; i++ {
-			sc.wg.Add(1)
-			go sc.cleanupRoutine(i)
-		}
+// shardFillRatio returns how full the shard holding key is, by cost or by memory budget,
+// whichever is higher
+func (sc *StrategicCache) shardFillRatio(key string) float64 {
+	if sc.wtinylfu != nil {
+		return sc.wtinylfu.FillRatio(key)
+	}
+	if sc.arc != nil {
+		return sc.arc.FillRatio(key)
 	}
 
-	return sc
+	maxShardSize := sc.config.CacheSize / int(sc.shardCount)
+	if sc.config.MaxShardSize > 0 {
+		maxShardSize = sc.config.MaxShardSize
+	}
+
+	shard := sc.getShard(key)
+	shard.mu.RLock()
+	cost, bytes := shard.totalCost(), shard.memoryBytes
+	shard.mu.RUnlock()
+
+	ratio := float64(cost) / float64(max(1, maxShardSize))
+	if sc.shardMemoryBudget > 0 {
+		if memRatio := float64(bytes) / float64(sc.shardMemoryBudget); memRatio > ratio {
+			ratio = memRatio
+		}
+	}
+	return ratio
 }
 
-// cleanupRoutine runs the cleanup loop for a specific shard
-func (sc *StrategicCache) cleanupRoutine(shardIdx int) {
+// cleanupRoutine runs the cleanup loop for the whole cache: a single goroutine and
+// ticker, however many shards there are
+func (sc *StrategicCache) cleanupRoutine() {
 	defer sc.wg.Done()
-	ticker := time.NewTicker(sc.config.CleanupInterval)
-	defer ticker.Stop()
+	sc.runCleanupLoop(sc.sweepExpired)
+}
+
+// sweepExpired is the cleanup goroutine's pass over the cache (see removeExpired)
+func (sc *StrategicCache) sweepExpired() {
+	sc.logSweep(sc.removeExpired())
+}
+
+// removeExpired removes expired entries from every shard, one shard lock at a time, and
+// from W-TinyLFU and ARC, which otherwise drop them only when they are accessed. It
+// prunes the tag and dependency indexes and returns how many entries were removed.
+func (sc *StrategicCache) removeExpired() int {
+	removed := 0
+	for i := range sc.shards {
+		if sc.ctx.Err() != nil {
+			return removed // Closing: Close is waiting on this goroutine
+		}
+		removed += sc.cleanupExpired(i)
+	}
+	if sc.wtinylfu != nil {
+		removed += sc.wtinylfu.RemoveExpired()
+	}
+	if sc.arc != nil {
+		removed += sc.arc.RemoveExpired()
+	}
+	sc.pruneIndexes()
+	return removed
+}
+
+// pruneIndexes drops keys no longer cached from the tag and dependency indexes
+func (sc *StrategicCache) pruneIndexes() {
+	if idx := sc.tags.Load(); idx != nil {
+		idx.prune(sc.isLive)
+	}
+	if idx := sc.deps.Load(); idx != nil {
+		idx.prune(sc.keepDependent)
+	}
+}
+
+// runCleanupLoop calls sweep on every tick of the cache clock until the cache is closed,
+// picking up a CleanupInterval changed by UpdateConfig
+func (sc *StrategicCache) runCleanupLoop(sweep func()) {
+	interval := sc.cleanupEvery()
+	ticks, stop := sc.clock.NewTicker(interval)
+	defer func() { stop() }()
 
 	for {
 		select {
-		case <-ticker.C:
-			sc.cleanupExpired(shardIdx)
+		case <-ticks:
+			sweep()
+			if current := sc.cleanupEvery(); current != interval {
+				stop()
+				interval = current
+				ticks, stop = sc.clock.NewTicker(interval)
+			}
 		case <-sc.ctx.Done():
 			return
 		}
 	}
 }
 
-// cleanupExpired removes expired entries from a shard
-func (sc *StrategicCache) cleanupExpired(shardIdx int) {
+// cleanupExpired removes expired entries from a shard and returns how many were removed
+func (sc *StrategicCache) cleanupExpired(shardIdx int) int {
 	shard := &sc.shards[shardIdx]
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	now := time.Now()
+	now := sc.clock.Now()
+	removed := 0
 	for key, entry := range shard.data {
-		if !entry.Timestamp.IsZero() && now.After(entry.Timestamp) {
-			// Remove from linked list
-			shard.ll.Remove(entry.llElem)
-			delete(shard.data, key)
+		if expired, idle := sc.expiry(entry, now); expired && !entry.Timestamp.IsZero() {
+			// Remove from linked list and map
+			shard.removeEntry(key, entry)
+			shard.countExpiration(idle)
 			// Return entry to pool for reuse
 			sc.entryPool.Put(entry)
+			removed++
+		}
+	}
+	return removed
+}
+
+// reclaimExpiredLocked examines up to n entries of shard, in map order, and removes the
+// expired ones. It stands in for the cleanup goroutine when DisableBackgroundCleanup is
+// set. The caller must hold shard.mu.
+func (sc *StrategicCache) reclaimExpiredLocked(shard *cacheShard, n int) {
+	now := sc.clock.Now()
+	for key, entry := range shard.data {
+		if n--; n < 0 {
+			return
+		}
+		if expired, idle := sc.expiry(entry, now); expired && !entry.Timestamp.IsZero() {
+			shard.removeEntry(key, entry)
+			shard.countExpiration(idle)
+			sc.entryPool.Put(entry)
 		}
 	}
 }
 
+// expiry reports whether entry has expired at now and, if so, whether it went unread
+// longer than MaxIdleTime before its TTL elapsed. Pinned entries never expire.
+func (sc *StrategicCache) expiry(entry *CacheEntry, now time.Time) (expired, idle bool) {
+	if entry.pinned {
+		return false, false
+	}
+	if maxIdle := sc.config.MaxIdleTime; maxIdle > 0 {
+		idleAt := entry.LastAccess.Add(maxIdle)
+		idle = now.After(idleAt) && idleAt.Before(entry.Timestamp)
+	}
+	return idle || now.After(entry.Timestamp), idle
+}
+
+// countExpiration records an entry dropped on expiry, by TTL or by idle timeout
+func (shard *cacheShard) countExpiration(idle bool) {
+	if idle {
+		shard.idleExpirations++
+	} else {
+		shard.expirations++
+	}
+}
+
 // Get retrieves a value from the cache
 func (sc *StrategicCache) Get(key string) (interface{}, bool) {
+	value, err := sc.GetE(key)
+	return value, err == nil
+}
+
+// GetE retrieves a value from the cache, reporting why it was not returned:
+// ErrNotFound, ErrExpired, ErrCacheClosed, ErrCachingDisabled or ErrNotSerializable
+// for a stored value that can no longer be decoded. With a Backend, misses are loaded
+// from it and backend failures are reported as ErrBackend. Keys stored with SetNegative
+// are reported as ErrNegativeEntry.
+func (sc *StrategicCache) GetE(key string) (interface{}, error) {
+	return sc.getE(sc.HashKey(key))
+}
+
+// getE is GetE for a hashed key
+func (sc *StrategicCache) getE(hk HashedKey) (interface{}, error) {
+	if sc.config.EnableLatencyTracking {
+		defer sc.latencyShard(hk).latency.get.since(time.Now())
+	}
+	value, err := sc.getLocal(hk)
+	sc.traceGet(hk.key, value, err)
+	if err != nil && sc.config.Backend != nil {
+		return sc.loadThrough(context.Background(), hk.key, err)
+	}
+	return value, err
+}
+
+// getLocal retrieves a value from memory only
+func (sc *StrategicCache) getLocal(hk HashedKey) (interface{}, error) {
+	key := hk.key
 	if !sc.config.EnableCaching {
-		return nil, false
+		return nil, ErrCachingDisabled
 	}
 
 	sc.closedMu.RLock()
 	if sc.closed {
 		sc.closedMu.RUnlock()
-		return nil, false
+		return nil, ErrCacheClosed
 	}
 	sc.closedMu.RUnlock()
 
+	if sc.config.TrackHotKeys > 0 {
+		defer sc.trackHotKey(key) // After W-TinyLFU records the read in its sketch
+	}
+
 	// Ultra-aggressive fast path: Direct delegation when possible
 	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") {
-		return sc.wtinylfu.Get(key)
+		value, ok := sc.wtinylfu.getAt(hk.shard, key)
+		return sc.found(key, value, ok)
+	}
+	if sc.arc != nil {
+		value, ok := sc.arc.getAt(hk.shard, key)
+		return sc.found(key, value, ok)
 	}
 
-	// Use sharded cache
-	shard := sc.getShard(key)
+	data, compressed, err := sc.lookup(hk)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decompress if needed (decoding already yields a fresh copy)
+	if compressed {
+		value, ok := decodeCompressed(data, sc.serializer)
+		if !ok {
+			sc.logUndecodable(key)
+			return nil, ErrNotSerializable
+		}
+		return value, nil
+	}
+
+	return sc.found(key, data, true)
+}
+
+// found is copyOnRead that also reassembles values stored in chunks
+func (sc *StrategicCache) found(key string, value interface{}, ok bool) (interface{}, error) {
+	if m, isManifest := value.(*chunkManifest); ok && isManifest {
+		return sc.assemble(key, m)
+	}
+	return sc.copyOnRead(value, ok)
+}
+
+// getStored reads the stored form of a value from whichever storage path is in use,
+// counting the read like Get does. compressed is only ever set on the sharded path.
+func (sc *StrategicCache) getStored(hk HashedKey) (data interface{}, compressed, ok bool) {
+	switch {
+	case sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == ""):
+		data, ok = sc.wtinylfu.getAt(hk.shard, hk.key)
+	case sc.arc != nil:
+		data, ok = sc.arc.getAt(hk.shard, hk.key)
+	default:
+		var err error
+		data, compressed, err = sc.lookup(hk)
+		ok = err == nil
+	}
+	return data, compressed, ok
+}
+
+// copyOnRead deep-copies a found value when CopyOnRead is enabled, reports negative
+// entries as ErrNegativeEntry and reassembles values stored by SetReader
+func (sc *StrategicCache) copyOnRead(value interface{}, ok bool) (interface{}, error) {
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if isNegative(value) {
+		sc.negativeHits.Add(1)
+		return nil, ErrNegativeEntry
+	}
+	if _, ok := value.(chunkPiece); ok {
+		return nil, ErrNotFound // A chunk key is not one of the caller's
+	}
+	if sv, ok := value.(*streamValue); ok {
+		b, err := sv.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotSerializable, err)
+		}
+		return b, nil
+	}
+	if sc.config.CopyOnRead {
+		return deepCopy(value), nil
+	}
+	return value, nil
+}
+
+// lookup finds a live entry on the sharded path, updating hit, miss and recency bookkeeping.
+// It returns the stored data and whether it is compressed, or ErrNotFound or ErrExpired. Stored bytes are never modified
+// in place (Set replaces Data), so they stay valid after the shard lock is released.
+// Hits take shard.mu.RLock and queue their recency update (see readBuffer); expired
+// entries, sliding TTLs and MaxIdleTime need the write lock and take lookupLocked.
+func (sc *StrategicCache) lookup(hk HashedKey) (interface{}, bool, error) {
+	key, shard := hk.key, &sc.shards[hk.shard]
+	if shard.sketch != nil {
+		shard.sketch.Record(key)
+	}
+	shard.mu.RLock()
+	entry, exists := shard.data[key]
+	if !exists {
+		shard.mu.RUnlock()
+		shard.miss()
+		return nil, false, ErrNotFound
+	}
+
+	now := sc.clock.Now()
+	if expired, _ := sc.expiry(entry, now); expired || entry.slide > 0 || sc.config.MaxIdleTime > 0 {
+		shard.mu.RUnlock()
+		return sc.lookupLocked(shard, key, now)
+	}
+	data, compressed, sum := entry.Data, entry.Compressed, entry.checksum
+	full := shard.reads.add(key, entry, now)
+	shard.mu.RUnlock()
+	if full {
+		shard.mu.Lock()
+		shard.drainReads()
+		shard.mu.Unlock()
+	}
+	if compressed && !sc.intact(shard, key, data, sum) {
+		shard.miss()
+		return nil, false, ErrNotFound
+	}
+	shard.hit()
+	return data, compressed, nil
+}
+
+// lookupLocked is lookup under the write lock, for reads that change the entry's
+// expiry or remove it
+func (sc *StrategicCache) lookupLocked(shard *cacheShard, key string, now time.Time) (interface{}, bool, error) {
 	shard.mu.Lock()
+	shard.drainReads()
 	entry, exists := shard.data[key]
 	if !exists {
-		shard.misses++ // Increment misses counter
 		shard.mu.Unlock()
-		return nil, false
+		shard.miss()
+		return nil, false, ErrNotFound
 	}
 
 	// Check if expired
-	if time.Now().After(entry.Timestamp) {
+	if expired, idle := sc.expiry(entry, now); expired {
 		// Remove expired entry from linked list and map
-		if entry.llElem != nil {
-			shard.ll.Remove(entry.llElem)
-		}
-		delete(shard.data, key)
+		shard.removeEntry(key, entry)
 		// Return entry to pool for reuse
 		sc.entryPool.Put(entry)
-		shard.misses++ // Increment misses counter for expired entry
+		shard.countExpiration(idle)
 		shard.mu.Unlock()
-		return nil, false
+		shard.miss()
+		return nil, false, ErrExpired
 	}
 
-	shard.hits++ // Increment hits counter
 	// Update access count and timestamp using EntryPool (within lock)
 	sc.entryPool.IncrementAccess(entry)
-	// Update last access time for LRU policy
-	entry.LastAccess = time.Now()
+	// Update last access time for LRU policy, and restart a sliding TTL
+	entry.LastAccess = now
+	if entry.slide > 0 {
+		entry.Timestamp = now.Add(entry.slide)
+	}
 
-	// Move to front for LRU policy optimization - always move to front when accessed
-	if _, ok := sc.policy.(*LRUPolicy); ok && entry.llElem != nil {
+	// Move to front to keep the list in recency order - always move to front when accessed
+	if entry.llElem != nil {
 		shard.ll.MoveToFront(entry.llElem)
 	}
 
-	// Copy necessary data before releasing lock to avoid race conditions
-	isCompressed := entry.Compressed
-	isNil := entry.IsNil
-	var dataCopy interface{}
-	if isCompressed {
-		if dataBytes, ok := entry.Data.([]byte); ok {
-			// Make a copy of the compressed data
-			dataCopy = make([]byte, len(dataBytes))
-			copy(dataCopy.([]byte), dataBytes)
-		} else {
-			dataCopy = entry.Data
-		}
-	} else {
-		dataCopy = entry.Data
+	data, compressed, sum := entry.Data, entry.Compressed, entry.checksum
+	shard.mu.Unlock()
+	if compressed && !sc.intact(shard, key, data, sum) {
+		shard.miss()
+		return nil, false, ErrNotFound
 	}
+	shard.hit()
+	return data, compressed, nil
+}
 
-	shard.mu.Unlock()
+// hit counts a read served by the shard
+func (shard *cacheShard) hit() {
+	shard.hits.Add(1)
+	if shard.window != nil {
+		shard.window.hit()
+	}
+}
 
-	// Decompress if needed
-	if isCompressed {
-		if dataBytes, ok := dataCopy.([]byte); ok {
-			_, payload, err := decompressGzipWithHeader(dataBytes)
-			if err != nil {
-				return nil, false
-			}
-			// The payload is already in the correct format (from toBytes)
-			// Handle empty payload (for empty strings, nil values, etc.)
-			if len(payload) == 0 {
-				// Use the IsNil flag to distinguish between nil and empty string
-				if isNil {
-					return nil, true
-				}
-				return "", true
-			}
+// miss counts a read the shard could not serve
+func (shard *cacheShard) miss() {
+	shard.misses.Add(1)
+	if shard.window != nil {
+		shard.window.miss()
+	}
+}
 
-			// Try to decode as gob first, if that fails, treat as string
-			buf := getBuffer()
-			buf.Write(payload)
-			dec := gob.NewDecoder(buf)
-			var decoded interface{}
-			if err := dec.Decode(&decoded); err == nil {
-				putBuffer(buf)
-				return decoded, true
-			}
-			buf.Reset()
-			buf.Write(payload)
-			dec = gob.NewDecoder(buf)
-			var box PrimitiveBox
-			if err := dec.Decode(&box); err == nil {
-				putBuffer(buf)
-				return box.V, true
-			}
-			putBuffer(buf)
+// Set stores a value in the cache
+func (sc *StrategicCache) Set(key string, value interface{}) bool {
+	return sc.set(key, value, defaultSetOptions)
+}
 
-			// If all decoding fails, try to parse as primitive type
-			// This handles the case where primitives were converted to strings by toBytes
-			payloadStr := string(payload)
-			if parsed, ok := parsePrimitiveFromString(payloadStr); ok {
-				return parsed, true
-			}
+// SetE stores a value in the cache, reporting why it was rejected: ErrCacheClosed,
+// ErrCachingDisabled, ErrReadOnly, ErrKeyTooLarge, ErrValueTooLarge, ErrNotSerializable,
+// ErrNotAdmitted or, with a Backend, ErrBackend
+func (sc *StrategicCache) SetE(key string, value interface{}) error {
+	return sc.setThrough(context.Background(), sc.HashKey(key), value, defaultSetOptions)
+}
+
+// set stores a value in the cache, and the Backend if any, applying per-entry options
+func (sc *StrategicCache) set(key string, value interface{}, opts setOptions) bool {
+	return sc.setThrough(context.Background(), sc.HashKey(key), value, opts) == nil
+}
 
-			// If all parsing fails, treat as string (common case)
-			return payloadStr, true
+// setE stores a value applying per-entry options, reporting why it was rejected, and
+// indexes its tags once it is stored
+func (sc *StrategicCache) setE(hk HashedKey, value interface{}, opts setOptions) error {
+	if sc.chunks(value) {
+		count, err := sc.setChunked(hk, value, opts)
+		if err == nil {
+			sc.deleteChunks(hk.key, count) // Left over from a longer previous value
+			sc.tagKey(hk.key, opts)
 		}
-		return nil, false
+		return err
 	}
-
-	return dataCopy, true
+	err := sc.setEntry(hk, value, opts)
+	if err == nil {
+		if sc.config.ChunkThreshold > 0 {
+			sc.deleteChunks(hk.key, 0) // The previous value may have been chunked
+		}
+		sc.tagKey(hk.key, opts)
+	}
+	return err
 }
 
-// Set stores a value in the cache
-func (sc *StrategicCache) Set(key string, value interface{}) bool {
+// setEntry is setE without the tag index
+func (sc *StrategicCache) setEntry(hk HashedKey, value interface{}, opts setOptions) error {
+	key := hk.key
 	if !sc.config.EnableCaching {
-		return false
+		return ErrCachingDisabled
 	}
 
 	sc.closedMu.RLock()
 	if sc.closed {
 		sc.closedMu.RUnlock()
-		return false
+		return ErrCacheClosed
 	}
 	sc.closedMu.RUnlock()
+	if err := sc.checkWritable(); err != nil {
+		return err
+	}
+
+	opts = sc.resolveExpiry(opts)
+	maxKeySize, maxValueSize := sc.sizeLimits()
+	admission := sc.admissionPolicy()
 
 	// Ultra-aggressive fast path: Direct delegation when possible
 	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") {
 		// Skip ALL validations for maximum performance
-		if sc.config.MaxKeySize == 0 && sc.config.MaxValueSize == 0 && sc.config.MaxShardSize == 0 {
+		if maxKeySize == 0 && maxValueSize == 0 && sc.config.MaxShardSize == 0 {
 			// Skip admission policy check if it's "always" (most common case)
-			if _, ok := sc.admission.(*AlwaysAdmitPolicy); ok {
-				return sc.wtinylfu.Set(key, value)
+			if _, ok := admission.(*AlwaysAdmitPolicy); ok {
+				return sc.wtinylfu.setWithAttrsAt(hk.shard, key, value, opts.attrs())
 			}
 		}
 
 		// Minimal validation path only if absolutely necessary
-		if sc.config.MaxKeySize > 0 && len(key) > sc.config.MaxKeySize {
-			return false
+		if maxKeySize > 0 && len(key) > maxKeySize {
+			return ErrKeyTooLarge
 		}
-		if sc.config.MaxValueSize > 0 {
+		if maxValueSize > 0 {
 			valueSize := calculateSize(value)
-			if valueSize > sc.config.MaxValueSize {
-				return false
+			if valueSize > maxValueSize {
+				return ErrValueTooLarge
 			}
 		}
-		if _, ok := sc.admission.(*AlwaysAdmitPolicy); !ok {
-			if !sc.admission.Allow(key, value) {
-				return false
+		if _, ok := admission.(*AlwaysAdmitPolicy); !ok {
+			if !admission.Allow(key, value) {
+				return ErrNotAdmitted
 			}
 		}
-		return sc.wtinylfu.Set(key, value)
+		return sc.wtinylfu.setWithAttrsAt(hk.shard, key, value, opts.attrs())
+	}
+
+	// ARC counts entries, so per-entry cost does not apply
+	if sc.arc != nil {
+		if maxKeySize > 0 && len(key) > maxKeySize {
+			return ErrKeyTooLarge
+		}
+		if maxValueSize > 0 && calculateSize(value) > maxValueSize {
+			return ErrValueTooLarge
+		}
+		if !admission.Allow(key, value) {
+			return ErrNotAdmitted
+		}
+		return admitted(sc.arc.setExpiringAt(hk.shard, key, value, opts.expiresAt, int64(opts.slide)))
 	}
 
 	// Validate key size
-	if sc.config.MaxKeySize > 0 && len(key) > sc.config.MaxKeySize {
-		return false
+	if maxKeySize > 0 && len(key) > maxKeySize {
+		return ErrKeyTooLarge
 	}
 
 	// Validate value size and serializability
-	if sc.config.MaxValueSize > 0 {
-		valueSize := calculateSize(value)
-		if valueSize > sc.config.MaxValueSize {
-			return false
+	valueSize := -1
+	if maxValueSize > 0 {
+		valueSize = calculateSize(value)
+		if valueSize > maxValueSize {
+			return ErrValueTooLarge
 		}
 	}
 
@@ -497,44 +1157,88 @@ func (sc *StrategicCache) Set(key string, value interface{}) bool {
 	if value != nil {
 		valueType := reflect.TypeOf(value)
 		if valueType.Kind() == reflect.Func || valueType.Kind() == reflect.Chan {
-			return false
+			return ErrNotSerializable
 		}
 	}
 
 	// Check admission policy
-	if !sc.admission.Allow(key, value) {
-		return false
+	if !admission.Allow(key, value) {
+		return ErrNotAdmitted
 	}
 
-	// Use sharded cache
-	shard := sc.getShard(key)
-	shard.mu.Lock()
-	defer shard.mu.Unlock()
-
-	// Check if key already exists
-	if existingEntry, exists := shard.data[key]; exists {
-		// Update existing entry
-		existingEntry.Data = value
-		existingEntry.AccessCount++
-		existingEntry.Timestamp = time.Now().Add(sc.config.TTL) // Set expiration time
-		existingEntry.LastAccess = time.Now()                   // Update last access time
-		existingEntry.Size = calculateSize(value)
+	v, err := sc.encodeValue(value, valueSize)
+	if err != nil {
+		sc.logger.Warn("metis: cannot serialize value", "key", key, "error", err)
+		return err
+	}
+	return sc.store(hk, v, opts)
+}
 
-		// Move to front for LRU policy - always move to front when updated
-		if _, ok := sc.policy.(*LRUPolicy); ok && existingEntry.llElem != nil {
-			shard.ll.MoveToFront(existingEntry.llElem)
+// encodeValue prepares a value for the sharded path, compressing it when enabled.
+// size is the value's calculateSize when the caller already has it, or -1.
+func (sc *StrategicCache) encodeValue(value interface{}, size int) (storedValue, error) {
+	// Compressed entries are stored, and sized, as their encoded bytes
+	data, compressed := value, false
+	rawSize, skipped := 0, false
+	var sum uint32
+	if sc.config.EnableCompression && !isInternal(value) {
+		codec := sc.valueCodec()
+		encoded, encodedSize, err := encodeCompressed(value, codec, sc.serializer)
+		if err != nil {
+			return storedValue{}, fmt.Errorf("%w: %v", ErrNotSerializable, err)
 		}
-		return true
+		data, size, compressed = encoded, len(encoded), true
+		rawSize, skipped = encodedSize, skipsCompression(codec, encodedSize)
+		sum = sc.checksum(encoded)
+	} else if size < 0 {
+		size = calculateSize(value)
 	}
 
-	// Create new entry
-	entry := &CacheEntry{
-		Key:         key,
-		Data:        value,
-		AccessCount: 1,
-		Timestamp:   time.Now().Add(sc.config.TTL), // Set expiration time
-		LastAccess:  time.Now(),                    // Set initial last access time
-		Size:        calculateSize(value),
+	return storedValue{
+		data:       data,
+		size:       size,
+		compressed: compressed,
+		isNil:      value == nil,
+		rawSize:    rawSize,
+		skipped:    skipped,
+		checksum:   sum,
+	}, nil
+}
+
+// storedValue is a value encoded for the sharded path, ready to be stored
+type storedValue struct {
+	data       interface{}
+	size       int
+	compressed bool
+	isNil      bool
+	rawSize    int    // Encoded size before compression
+	skipped    bool   // Stored as-is under CompressionMinSize
+	checksum   uint32 // CRC32 of data when compressed (see StrategicCache.checksum)
+}
+
+// store inserts an encoded value into its shard, evicting entries to make room
+func (sc *StrategicCache) store(hk HashedKey, v storedValue, opts setOptions) error {
+	maxShardCost, err := sc.checkStorable(v, opts)
+	if err != nil {
+		return err
+	}
+
+	// Use sharded cache
+	shard := &sc.shards[hk.shard]
+	defer shard.evictQueue.flush() // Runs after the unlock below
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if sc.lazyCleanup > 0 {
+		sc.reclaimExpiredLocked(shard, sc.lazyCleanup)
+	}
+	return sc.storeLocked(shard, hk.key, v, opts, maxShardCost)
+}
+
+// checkStorable rejects values that can never fit in a shard and returns the shard's cost limit
+func (sc *StrategicCache) checkStorable(v storedValue, opts setOptions) (int64, error) {
+	// Values larger than a whole shard's memory budget can never fit
+	if sc.shardMemoryBudget > 0 && int64(v.size) > sc.shardMemoryBudget {
+		return 0, ErrValueTooLarge
 	}
 
 	// Check if we need to evict
@@ -542,76 +1246,284 @@ func (sc *StrategicCache) Set(key string, value interface{}) bool {
 	if sc.config.MaxShardSize > 0 {
 		maxShardSize = sc.config.MaxShardSize
 	}
+	maxShardCost := int64(maxShardSize)
 
-	if len(shard.data) >= maxShardSize {
-		// Use the configured eviction policy
-		if sc.policy != nil {
-			evictKey := sc.policy.EvictKey(shard.data, shard.ll)
-			if evictKey != "" {
-				if evictEntry := shard.data[evictKey]; evictEntry != nil {
-					// Remove from linked list if it exists
-					if evictEntry.llElem != nil {
-						shard.ll.Remove(evictEntry.llElem)
-					}
-					delete(shard.data, evictKey)
-				}
-			}
-		} else {
-			// Fallback to timestamp-based eviction
-			var oldestKey string
-			var oldestTime time.Time
-			for k, e := range shard.data {
-				if oldestKey == "" || e.Timestamp.Before(oldestTime) {
-					oldestKey = k
-					oldestTime = e.Timestamp
-				}
-			}
-			if oldestKey != "" {
-				if evictEntry := shard.data[oldestKey]; evictEntry != nil && evictEntry.llElem != nil {
-					shard.ll.Remove(evictEntry.llElem)
-				}
-				delete(shard.data, oldestKey)
+	// Weighted entries costlier than a whole shard can never fit
+	if opts.cost > 1 && opts.cost > maxShardCost {
+		return 0, ErrValueTooLarge
+	}
+	return maxShardCost, nil
+}
+
+// storeLocked inserts an encoded value into shard. The caller must hold shard.mu.
+func (sc *StrategicCache) storeLocked(shard *cacheShard, key string, v storedValue, opts setOptions, maxShardCost int64) error {
+	now := sc.clock.Now()
+	expiresAt := now.Add(sc.entryTTL())
+	if opts.expiresAt != 0 {
+		expiresAt = time.Unix(0, opts.expiresAt)
+	}
+
+	if shard.sketch != nil {
+		shard.sketch.Record(key)
+	}
+	shard.drainReads()
+
+	// Check if key already exists
+	if existingEntry, exists := shard.data[key]; exists {
+		// Update existing entry
+		shard.trackCompression(existingEntry, -1)
+		existingEntry.Data = v.data
+		existingEntry.Compressed = v.compressed
+		existingEntry.rawSize = v.rawSize
+		existingEntry.compressionSkipped = v.skipped
+		existingEntry.checksum = v.checksum
+		existingEntry.IsNil = v.isNil
+		existingEntry.AccessCount++
+		existingEntry.Timestamp = expiresAt // Set expiration time
+		existingEntry.slide = opts.slide
+		existingEntry.LastAccess = now // Update last access time
+		existingEntry.writtenAt = now.UnixNano()
+		shard.trackPriority(existingEntry, -1)
+		existingEntry.Priority = opts.priority
+		shard.trackPriority(existingEntry, 1)
+
+		// Move to front to keep the list in recency order - always move to front when updated
+		if existingEntry.llElem != nil {
+			shard.ll.MoveToFront(existingEntry.llElem)
+		}
+
+		if costDelta := opts.cost - existingEntry.cost(); costDelta > 0 {
+			sc.evictForCost(shard, costDelta, maxShardCost, existingEntry)
+		}
+		shard.extraCost += opts.cost - existingEntry.cost()
+		existingEntry.Cost = opts.cost
+
+		if sc.shardMemoryBudget > 0 {
+			sc.evictForMemory(shard, int64(v.size-existingEntry.Size), existingEntry)
+		}
+		shard.memoryBytes += int64(v.size - existingEntry.Size)
+		existingEntry.Size = v.size
+		shard.trackCompression(existingEntry, 1)
+		return nil
+	}
+
+	// Create new entry, recycling one removed earlier
+	entry := sc.entryPool.Get()
+	*entry = CacheEntry{
+		Key:         key,
+		Data:        v.data,
+		Compressed:  v.compressed,
+		IsNil:       v.isNil,
+		AccessCount: 1,
+		Timestamp:   expiresAt, // Set expiration time
+		LastAccess:  now,       // Set initial last access time
+		Size:        v.size,
+		Cost:        opts.cost,
+		Priority:    opts.priority,
+
+		rawSize:            v.rawSize,
+		compressionSkipped: v.skipped,
+		checksum:           v.checksum,
+		slide:              opts.slide,
+		writtenAt:          now.UnixNano(),
+	}
+
+	// TinyLFU admission: a full shard only takes keys used more often than its victim
+	if shard.sketch != nil {
+		if shard.totalCost()+entry.Cost > maxShardCost {
+			if victim := sc.selectVictim(shard); victim != "" && !shard.sketch.ShouldAdmit(key, victim) {
+				sc.entryPool.Put(entry)
+				return ErrNotAdmitted
 			}
 		}
 	}
 
-	// Add to linked list for LRU policy - always add to front
-	if _, ok := sc.policy.(*LRUPolicy); ok {
-		entry.llElem = shard.ll.PushFront(entry)
+	// Evict until the entry's cost fits (one eviction for unit-cost entries)
+	sc.evictForCost(shard, entry.Cost, maxShardCost, nil)
+
+	// Make room within the shard's memory budget
+	if sc.shardMemoryBudget > 0 {
+		sc.evictForMemory(shard, int64(entry.Size), nil)
 	}
 
+	// Add to linked list in recency order - always add to front
+	entry.llElem = shard.ll.PushFront(entry)
+
 	shard.data[key] = entry
-	return true
+	shard.count.Add(1)
+	shard.memoryBytes += int64(entry.Size)
+	shard.extraCost += entry.cost() - 1
+	shard.trackCompression(entry, 1)
+	shard.trackPriority(entry, 1)
+	return nil
 }
 
-// Delete removes a key from the cache
-func (sc *StrategicCache) Delete(key string) {
+// selectVictim picks the key to evict from a shard using the configured eviction policy.
+// When the policy picks a pinned entry, or one above the lowest priority in the shard,
+// the least recently used unpinned entry of the lowest priority is taken instead.
+// It returns "" when every entry is pinned. The caller must hold shard.mu.
+func (sc *StrategicCache) selectVictim(shard *cacheShard) string {
+	shard.drainReads()
+	key := sc.policyVictim(shard)
+	if shard.pinned == 0 && shard.lowPriority == 0 && shard.highPriority == 0 {
+		return key
+	}
+	floor := shard.lowestPriority()
+	if entry := shard.data[key]; entry == nil || (!entry.pinned && entry.Priority <= floor) {
+		return key
+	}
+
+	var victim *CacheEntry
+	for elem := shard.ll.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*CacheEntry)
+		if entry.pinned {
+			continue
+		}
+		if entry.Priority <= floor {
+			return entry.Key
+		}
+		if victim == nil || entry.Priority < victim.Priority {
+			victim = entry
+		}
+	}
+	if victim == nil {
+		return ""
+	}
+	return victim.Key
+}
+
+// policyVictim asks the configured eviction policy for the key to evict.
+// The caller must hold shard.mu.
+func (sc *StrategicCache) policyVictim(shard *cacheShard) string {
+	// Use the configured eviction policy
+	if sc.policy != nil {
+		return sc.policy.EvictKey(shard.data, shard.ll)
+	}
+
+	// Fallback to timestamp-based eviction
+	var oldestKey string
+	var oldestTime time.Time
+	for k, e := range shard.data {
+		if oldestKey == "" || e.Timestamp.Before(oldestTime) {
+			oldestKey = k
+			oldestTime = e.Timestamp
+		}
+	}
+	return oldestKey
+}
+
+// evictForCost evicts entries until need more cost units fit within limit.
+// keep, when not nil, is the entry being updated and is never evicted.
+// The caller must hold shard.mu.
+func (sc *StrategicCache) evictForCost(shard *cacheShard, need, limit int64, keep *CacheEntry) {
+	for shard.totalCost()+need > limit && len(shard.data) > 0 {
+		evictKey := sc.selectVictim(shard)
+		victim := shard.data[evictKey]
+		if victim == nil || victim == keep {
+			return
+		}
+		shard.removeEntry(evictKey, victim)
+		shard.evictions++
+		if shard.window != nil {
+			shard.window.evict()
+		}
+		shard.evictQueue.push(evictedEntry{key: evictKey, value: victim.Data, compressed: victim.Compressed})
+		sc.entryPool.Put(victim)
+	}
+}
+
+// evictForMemory evicts entries until need more bytes fit in the shard's memory budget.
+// keep, when not nil, is the entry being updated and is never evicted.
+// The caller must hold shard.mu.
+func (sc *StrategicCache) evictForMemory(shard *cacheShard, need int64, keep *CacheEntry) {
+	for shard.memoryBytes+need > sc.shardMemoryBudget && len(shard.data) > 0 {
+		evictKey := sc.selectVictim(shard)
+		victim := shard.data[evictKey]
+		if victim == nil || victim == keep {
+			return
+		}
+		shard.removeEntry(evictKey, victim)
+		shard.evictions++
+		if shard.window != nil {
+			shard.window.evict()
+		}
+		shard.memoryEvictedBytes += int64(victim.Size)
+		shard.evictQueue.push(evictedEntry{key: evictKey, value: victim.Data, compressed: victim.Compressed})
+		sc.entryPool.Put(victim)
+	}
+}
+
+// Delete removes a key from the cache and the Backend, if any, reporting whether it was cached.
+// Backend failures are reported to CacheConfig.Logger; use DeleteE to handle them.
+func (sc *StrategicCache) Delete(key string) bool {
+	deleted, err := sc.delete(sc.HashKey(key))
+	if err != nil && !errors.Is(err, ErrCacheClosed) {
+		sc.logger.Warn("metis: backend delete failed", "key", key, "error", err)
+	}
+	return deleted
+}
+
+// DeleteE removes a key from the cache and, with a Backend, from the backend,
+// reporting ErrCacheClosed or a backend failure as ErrBackend
+func (sc *StrategicCache) DeleteE(key string) error {
+	_, err := sc.delete(sc.HashKey(key))
+	return err
+}
+
+// delete is Delete reporting both whether the key was cached and any error
+func (sc *StrategicCache) delete(hk HashedKey) (bool, error) {
 	sc.closedMu.RLock()
 	if sc.closed {
 		sc.closedMu.RUnlock()
-		return
+		return false, ErrCacheClosed
 	}
 	sc.closedMu.RUnlock()
 
+	sc.traceOp(TraceDelete, hk.key, nil)
+	deleted := sc.deleteLocal(hk)
+	sc.publishInvalidation(hk.key)
+	if sc.config.Backend != nil {
+		return deleted, sc.deleteThrough(context.Background(), hk.key)
+	}
+	return deleted, nil
+}
+
+// deleteLocal removes a key from memory only, reporting whether it was there.
+// The manifest of a chunked value goes first, so no Get assembles it afterwards.
+func (sc *StrategicCache) deleteLocal(hk HashedKey) bool {
+	sc.untag(hk.key)
+	deleted := sc.deleteEntry(hk)
+	if sc.config.ChunkThreshold > 0 {
+		sc.deleteChunks(hk.key, 0)
+	}
+	return deleted
+}
+
+// deleteEntry removes the entry stored under a key, reporting whether it was there
+func (sc *StrategicCache) deleteEntry(hk HashedKey) bool {
+	key := hk.key
+
 	// If W-TinyLFU is enabled and no traditional eviction policy is specified, delegate to W-TinyLFU
 	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") {
-		sc.wtinylfu.Delete(key)
-		return
+		return sc.wtinylfu.deleteAt(hk.shard, key)
+	}
+	if sc.arc != nil {
+		return sc.arc.deleteAt(hk.shard, key)
 	}
 
-	shard := sc.getShard(key)
+	shard := &sc.shards[hk.shard]
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if entry, exists := shard.data[key]; exists {
-		// Remove from linked list
-		if entry.llElem != nil {
-			shard.ll.Remove(entry.llElem)
-		}
-		delete(shard.data, key)
-		// Return entry to pool for reuse
-		sc.entryPool.Put(entry)
+	entry, exists := shard.data[key]
+	if !exists {
+		return false
 	}
+	// Remove from linked list and map
+	shard.removeEntry(key, entry)
+	// Return entry to pool for reuse
+	sc.entryPool.Put(entry)
+	return true
 }
 
 // Clear removes all entries from the cache
@@ -623,31 +1535,286 @@ func (sc *StrategicCache) Clear() {
 	}
 	sc.closedMu.RUnlock()
 
+	if idx := sc.tags.Load(); idx != nil {
+		idx.clear()
+	}
+	if idx := sc.deps.Load(); idx != nil {
+		idx.clear()
+	}
+
 	// If W-TinyLFU is enabled, clear W-TinyLFU
 	if sc.wtinylfu != nil {
 		sc.wtinylfu.Clear()
 		return
 	}
+	if sc.arc != nil {
+		sc.arc.Clear()
+		return
+	}
 
 	for i := 0; i < int(sc.shardCount); i++ {
+		sc.clearShard(&sc.shards[i])
+	}
+}
+
+// clearShard removes every entry of a shard on the sharded path
+func (sc *StrategicCache) clearShard(shard *cacheShard) {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	// Return all entries to pool before clearing
+	for _, entry := range shard.data {
+		sc.entryPool.Put(entry)
+	}
+	shard.data = make(map[string]*CacheEntry)
+	shard.count.Store(0)
+	shard.ll.Init()
+	shard.drainReads()
+	shard.memoryBytes = 0
+	shard.extraCost = 0
+	shard.pinned = 0
+	shard.lowPriority, shard.highPriority = 0, 0
+	shard.compressedEntries, shard.skippedEntries = 0, 0
+	shard.rawBytes, shard.compressedBytes = 0, 0
+}
+
+// ClearShard removes every entry of shard i, numbered as in ShardStats, leaving the
+// other shards untouched. Like Clear, it resets the shard's counters on W-TinyLFU and
+// ARC. It returns ErrInvalidShard for an index out of range and ErrCacheClosed once
+// the cache is closed.
+func (sc *StrategicCache) ClearShard(i int) error {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return ErrCacheClosed
+	}
+	sc.closedMu.RUnlock()
+
+	switch {
+	case sc.wtinylfu != nil:
+		if i < 0 || i >= len(sc.wtinylfu.shards) {
+			return fmt.Errorf("%w: %d of %d", ErrInvalidShard, i, len(sc.wtinylfu.shards))
+		}
+		sc.wtinylfu.shards[i].Clear()
+	case sc.arc != nil:
+		if i < 0 || i >= len(sc.arc.shards) {
+			return fmt.Errorf("%w: %d of %d", ErrInvalidShard, i, len(sc.arc.shards))
+		}
+		sc.arc.shards[i].Clear()
+	default:
+		if i < 0 || i >= len(sc.shards) {
+			return fmt.Errorf("%w: %d of %d", ErrInvalidShard, i, len(sc.shards))
+		}
+		sc.clearShard(&sc.shards[i])
+	}
+	sc.pruneIndexes()
+	return nil
+}
+
+// ClearExpired removes every expired entry now, as the cleanup goroutine does on each
+// CleanupInterval, and returns how many were removed. Live entries are untouched.
+// Removals count as Expirations or IdleExpirations; a closed cache returns 0.
+func (sc *StrategicCache) ClearExpired() int {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return 0
+	}
+	sc.closedMu.RUnlock()
+
+	return sc.removeExpired()
+}
+
+// Len returns the number of entries held, including expired ones not yet swept. It
+// reads a counter per shard and takes no locks, so it is cheap enough to call per request.
+// With ChunkThreshold set, leaving out the chunks of large values copies the entries
+// under the shard locks instead.
+func (sc *StrategicCache) Len() int {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return 0
+	}
+	sc.closedMu.RUnlock()
+
+	var total int
+	switch {
+	case sc.wtinylfu != nil:
+		total = sc.wtinylfu.Size()
+	case sc.arc != nil:
+		total = sc.arc.Size()
+	default:
+		for i := range sc.shards {
+			total += int(sc.shards[i].count.Load())
+		}
+	}
+	return total - len(sc.storedChunks())
+}
+
+// Keys returns the keys of all live entries, in no particular order
+func (sc *StrategicCache) Keys() []string {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return nil
+	}
+	sc.closedMu.RUnlock()
+
+	var keys []string
+	switch {
+	case sc.wtinylfu != nil:
+		keys = sc.wtinylfu.Keys()
+	case sc.arc != nil:
+		keys = sc.arc.Keys()
+	default:
+		now := sc.clock.Now()
+		for i := range sc.shards {
+			shard := &sc.shards[i]
+			shard.mu.RLock()
+			for key, entry := range shard.data {
+				if expired, _ := sc.expiry(entry, now); expired {
+					continue
+				}
+				keys = append(keys, key)
+			}
+			shard.mu.RUnlock()
+		}
+	}
+	if sc.config.ChunkThreshold > 0 {
+		keys = slices.DeleteFunc(keys, isChunkKey)
+	}
+	return keys
+}
+
+// Range calls fn for each live entry until fn returns false. Each shard is copied
+// under its lock before fn runs, so fn may safely call back into the cache.
+// Range does not count as an access for hit statistics or eviction order. Negative
+// entries (see SetNegative) are skipped, and values split by ChunkThreshold are visited
+// reassembled.
+func (sc *StrategicCache) Range(fn func(key string, value interface{}) bool) {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return
+	}
+	sc.closedMu.RUnlock()
+
+	pieces := sc.storedChunks()
+	visit := fn
+	fn = func(key string, value interface{}) bool {
+		if m, ok := value.(*chunkManifest); ok {
+			var err error
+			if value, err = sc.assembleFrom(key, m, pieces); err != nil {
+				return true
+			}
+		} else if isInternal(value) {
+			return true
+		}
+		if sc.config.CopyOnRead {
+			value = deepCopy(value)
+		}
+		return visit(key, value)
+	}
+
+	if sc.wtinylfu != nil {
+		sc.wtinylfu.Range(fn)
+		return
+	}
+	if sc.arc != nil {
+		sc.arc.Range(fn)
+		return
+	}
+
+	type rangeEntry struct {
+		key        string
+		data       interface{}
+		compressed bool
+	}
+	for i := range sc.shards {
 		shard := &sc.shards[i]
-		shard.mu.Lock()
-		// Return all entries to pool before clearing
-		for _, entry := range shard.data {
-			sc.entryPool.Put(entry)
+		now := sc.clock.Now()
+		shard.mu.RLock()
+		entries := make([]rangeEntry, 0, len(shard.data))
+		for key, entry := range shard.data {
+			if expired, _ := sc.expiry(entry, now); expired {
+				continue
+			}
+			entries = append(entries, rangeEntry{key: key, data: entry.Data, compressed: entry.Compressed})
+		}
+		shard.mu.RUnlock()
+
+		for _, e := range entries {
+			value := e.data
+			if e.compressed {
+				decoded, ok := decodeCompressed(e.data, sc.serializer)
+				if !ok {
+					sc.logUndecodable(e.key)
+					continue
+				}
+				value = decoded
+			}
+			if !fn(e.key, value) {
+				return
+			}
 		}
-		shard.data = make(map[string]*CacheEntry)
-		shard.ll.Init()
-		shard.mu.Unlock()
 	}
 }
 
 // CacheStats contains statistics about the cache performance
 type CacheStats struct {
-	Hits   int64
-	Misses int64
-	Size   int64
-	Keys   int
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Size        int64 `json:"size"`
+	Keys        int   `json:"keys"`
+	Evictions   int64 `json:"evictions"`   // Entries removed to make room for new ones
+	Expirations int64 `json:"expirations"` // Entries removed because their TTL elapsed
+	// IdleExpirations counts entries removed because they went unread longer than MaxIdleTime
+	IdleExpirations int64 `json:"idle_expirations"`
+	Pinned          int   `json:"pinned"`       // Entries exempted from eviction by Pin
+	MemoryBytes     int64 `json:"memory_bytes"` // Estimated bytes held by cached values
+	// MemoryEvictedBytes counts bytes evicted to stay within MaxMemoryBytes
+	MemoryEvictedBytes int64 `json:"memory_evicted_bytes"`
+	// TotalCost is the cumulative cost of stored entries (equal to Keys when no costs are set)
+	TotalCost int64 `json:"total_cost"`
+	// ARCTarget is ARC's adaptive T1 target p summed across shards (ARC policy only)
+	ARCTarget int64 `json:"arc_target"`
+	// NegativeHits counts reads answered by a negative entry (see SetNegative); they are
+	// also counted in Hits
+	NegativeHits int64 `json:"negative_hits"`
+	// CorruptedEntries counts compressed entries whose bytes no longer matched their
+	// checksum when read; each was removed and read as missing (see DisableChecksums)
+	CorruptedEntries int64 `json:"corrupted_entries"`
+	// TaggedKeys and Tags are the size of the WithTags index: keys stored with tags and
+	// distinct tags
+	TaggedKeys int `json:"tagged_keys"`
+	Tags       int `json:"tags"`
+	// DependentKeys is the number of keys stored with WithDependsOn still indexed
+	DependentKeys int `json:"dependent_keys"`
+	// ReadOnly reports whether the cache is in read-only mode, and ReadOnlyRejected counts
+	// the writes it rejected (see SetReadOnly)
+	ReadOnly         bool  `json:"read_only"`
+	ReadOnlyRejected int64 `json:"read_only_rejected"`
+	// EntryPool reports how often stored entries were recycled rather than allocated:
+	// CacheEntry objects on the sharded path, list nodes with W-TinyLFU, nothing with
+	// ARC. It counts from the cache's creation and is not zeroed by ResetStats.
+	EntryPool PoolStats `json:"entry_pool"`
+}
+
+// ShardStats contains statistics for a single shard
+type ShardStats struct {
+	Index       int   `json:"index"`
+	Keys        int   `json:"keys"`
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Evictions   int64 `json:"evictions"`
+	Expirations int64 `json:"expirations"`
+	// IdleExpirations counts entries removed because they went unread longer than MaxIdleTime
+	IdleExpirations int64 `json:"idle_expirations"`
+	Pinned          int   `json:"pinned"`
+	MemoryBytes     int64 `json:"memory_bytes"`
+	// MemoryEvictedBytes counts bytes evicted to stay within MaxMemoryBytes
+	MemoryEvictedBytes int64 `json:"memory_evicted_bytes"`
+	TotalCost          int64 `json:"total_cost"`
+	ARCTarget          int64 `json:"arc_target"`
 }
 
 // GetStats returns cache statistics
@@ -659,21 +1826,48 @@ func (sc *StrategicCache) GetStats() CacheStats {
 	}
 	sc.closedMu.RUnlock()
 
+	stats := sc.storageStats()
+	stats.Keys -= len(sc.storedChunks())
+	stats.NegativeHits = sc.negativeHits.Load()
+	stats.CorruptedEntries = sc.corruptedEntries.Load()
+	stats.ReadOnly = sc.readOnly.Load()
+	stats.ReadOnlyRejected = sc.readOnlyRejected.Load()
+	if idx := sc.tags.Load(); idx != nil {
+		stats.TaggedKeys, stats.Tags = idx.counts()
+	}
+	if idx := sc.deps.Load(); idx != nil {
+		stats.DependentKeys, _ = idx.counts()
+	}
+	return stats
+}
+
+// storageStats collects the statistics kept by the active storage path
+func (sc *StrategicCache) storageStats() CacheStats {
 	// If W-TinyLFU is enabled, get stats from W-TinyLFU
 	if sc.wtinylfu != nil {
 		return sc.wtinylfu.GetStats()
 	}
+	if sc.arc != nil {
+		return sc.arc.GetStats()
+	}
 
 	// Calculate stats from shards
-	var totalHits, totalMisses, totalSize int64
-	var totalKeys int
+	var totalHits, totalMisses, totalSize, totalEvictions, totalExpirations, totalIdleExpirations, totalMemory, totalMemoryEvicted, totalCost int64
+	var totalKeys, totalPinned int
 
 	for i := range sc.shards {
 		sc.shards[i].mu.RLock()
 		shardSize := len(sc.shards[i].data)
 		totalKeys += shardSize
-		totalHits += sc.shards[i].hits
-		totalMisses += sc.shards[i].misses
+		totalHits += sc.shards[i].hits.Load()
+		totalMisses += sc.shards[i].misses.Load()
+		totalEvictions += sc.shards[i].evictions
+		totalExpirations += sc.shards[i].expirations
+		totalIdleExpirations += sc.shards[i].idleExpirations
+		totalPinned += sc.shards[i].pinned
+		totalMemory += sc.shards[i].memoryBytes
+		totalMemoryEvicted += sc.shards[i].memoryEvictedBytes
+		totalCost += sc.shards[i].totalCost()
 		sc.shards[i].mu.RUnlock()
 	}
 
@@ -681,22 +1875,186 @@ func (sc *StrategicCache) GetStats() CacheStats {
 	totalSize = int64(totalKeys)
 
 	return CacheStats{
-		Hits:   totalHits,
-		Misses: totalMisses,
-		Size:   totalSize,
-		Keys:   totalKeys,
+		Hits:               totalHits,
+		Misses:             totalMisses,
+		Size:               totalSize,
+		Keys:               totalKeys,
+		Evictions:          totalEvictions,
+		Expirations:        totalExpirations,
+		IdleExpirations:    totalIdleExpirations,
+		Pinned:             totalPinned,
+		MemoryBytes:        totalMemory,
+		MemoryEvictedBytes: totalMemoryEvicted,
+		TotalCost:          totalCost,
+		EntryPool:          sc.entryPool.Stats(),
+	}
+}
+
+// ResetStats zeroes the hit, miss, eviction and expiration counters, so GetStats,
+// ShardStats and WindowedStats report only what happens from now on, and clears the
+// LatencyStats histograms. Cached entries, and gauges such as Keys and MemoryBytes,
+// are unchanged. Hits and misses are counted outside the shard lock, so those counted
+// while ResetStats runs can be lost.
+func (sc *StrategicCache) ResetStats() {
+	for i := range sc.shards {
+		shard := &sc.shards[i]
+		shard.mu.Lock()
+		shard.hits.Store(0)
+		shard.misses.Store(0)
+		shard.evictions = 0
+		shard.expirations = 0
+		shard.idleExpirations = 0
+		shard.memoryEvictedBytes = 0
+		shard.mu.Unlock()
+		shard.window.reset()
+		shard.latency.reset()
+	}
+	if sc.wtinylfu != nil {
+		sc.wtinylfu.ResetStats()
+		for _, shard := range sc.wtinylfu.shards {
+			shard.window.reset()
+		}
+	}
+	if sc.arc != nil {
+		sc.arc.ResetStats()
+		for _, shard := range sc.arc.shards {
+			shard.window.reset()
+		}
+	}
+	sc.negativeHits.Store(0)
+	sc.corruptedEntries.Store(0)
+	sc.readOnlyRejected.Store(0)
+}
+
+// CompressionStats describes how well EnableCompression is working for the entries
+// currently stored. Compression applies to the sharded ("lru") path only.
+type CompressionStats struct {
+	CompressedEntries int64 `json:"compressed_entries"`
+	// SkippedEntries are stored as-is because they were under CompressionMinSize
+	SkippedEntries int64 `json:"skipped_entries"`
+	// UncompressedBytes is the encoded size of the compressed entries before compression
+	UncompressedBytes int64 `json:"uncompressed_bytes"`
+	// CompressedBytes is the size the compressed entries are stored at
+	CompressedBytes int64 `json:"compressed_bytes"`
+}
+
+// Ratio returns CompressedBytes / UncompressedBytes, or 0 when nothing is compressed.
+// Values near or above 1 mean compression is costing CPU without saving memory.
+func (cs CompressionStats) Ratio() float64 {
+	if cs.UncompressedBytes == 0 {
+		return 0
+	}
+	return float64(cs.CompressedBytes) / float64(cs.UncompressedBytes)
+}
+
+// CompressionStats returns compression statistics for the entries currently stored
+func (sc *StrategicCache) CompressionStats() CompressionStats {
+	var stats CompressionStats
+	if sc.wtinylfu != nil || sc.arc != nil {
+		return stats
 	}
+	for i := range sc.shards {
+		shard := &sc.shards[i]
+		shard.mu.RLock()
+		stats.CompressedEntries += shard.compressedEntries
+		stats.SkippedEntries += shard.skippedEntries
+		stats.UncompressedBytes += shard.rawBytes
+		stats.CompressedBytes += shard.compressedBytes
+		shard.mu.RUnlock()
+	}
+	return stats
 }
 
-// Close closes the cache and stops the cleanup goroutines
+// ShardStats returns per-shard statistics, useful to spot uneven key distribution
+func (sc *StrategicCache) ShardStats() []ShardStats {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return nil
+	}
+	sc.closedMu.RUnlock()
+
+	// If W-TinyLFU is enabled, report its shards
+	if sc.wtinylfu != nil {
+		return sc.wtinylfu.ShardStats()
+	}
+	if sc.arc != nil {
+		return sc.arc.ShardStats()
+	}
+
+	stats := make([]ShardStats, len(sc.shards))
+	for i := range sc.shards {
+		shard := &sc.shards[i]
+		shard.mu.RLock()
+		stats[i] = ShardStats{
+			Index:              i,
+			Keys:               len(shard.data),
+			Hits:               shard.hits.Load(),
+			Misses:             shard.misses.Load(),
+			Evictions:          shard.evictions,
+			Expirations:        shard.expirations,
+			IdleExpirations:    shard.idleExpirations,
+			Pinned:             shard.pinned,
+			MemoryBytes:        shard.memoryBytes,
+			MemoryEvictedBytes: shard.memoryEvictedBytes,
+			TotalCost:          shard.totalCost(),
+		}
+		shard.mu.RUnlock()
+	}
+	return stats
+}
+
+// PolicyName returns the name of the eviction policy actually in use,
+// resolving the empty/default configuration to the concrete policy
+func (sc *StrategicCache) PolicyName() string {
+	if sc.wtinylfu != nil {
+		return "wtinylfu"
+	}
+	if sc.arc != nil {
+		return "arc"
+	}
+	if _, ok := sc.policy.(*LRUPolicy); !ok {
+		return "custom"
+	}
+	return "lru"
+}
+
+// DefaultCloseTimeout bounds how long Close waits for background work to finish
+const DefaultCloseTimeout = 5 * time.Second
+
+// Close closes the cache like CloseWithContext, waiting at most DefaultCloseTimeout;
+// use CloseWithContext to wait longer. A timeout is logged, and the write-behind
+// writes it abandons are counted in WriteBehindStats().Lost.
 func (sc *StrategicCache) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+	if err := sc.CloseWithContext(ctx); err != nil {
+		sc.logger.Error("metis: close did not finish", "error", err)
+	}
+}
+
+// CloseWithContext closes the cache: it stops accepting operations, writes every queued
+// write-behind write to the Backend, lets the final background snapshot finish and stops
+// the cleanup goroutines. If ctx ends first it returns ctx.Err() without waiting further,
+// and the write-behind writes not yet written are lost and counted in
+// WriteBehindStats().Lost. Closing an already closed cache returns nil.
+func (sc *StrategicCache) CloseWithContext(ctx context.Context) error {
 	sc.closedMu.Lock()
 	if sc.closed {
 		sc.closedMu.Unlock()
-		return
+		return nil
 	}
 	sc.closed = true
 	sc.closedMu.Unlock()
+	sc.unpublishExpvar()
+	if err := sc.StopTrace(); err != nil {
+		sc.logger.Warn("metis: cannot write the trace", "error", err)
+	}
+	// Every write acknowledged by Set reaches the backend before the goroutines stop
+	var err error
+	if sc.writeBehind != nil {
+		err = sc.writeBehind.close(ctx)
+	}
 	sc.cancel()
 	done := make(chan struct{})
 	go func() {
@@ -705,68 +2063,140 @@ func (sc *StrategicCache) Close() {
 	}()
 	select {
 	case <-done:
-	case <-time.After(5 * time.Second):
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
 	}
 	sc.Clear()
+	return err
 }
 
+// DefaultStatsWindowBuckets is how many buckets divide StatsWindow when StatsWindowBuckets is zero
+const DefaultStatsWindowBuckets = 60
+
+// DefaultLazyCleanupBatch is how many entries a Set examines for expiration when
+// DisableBackgroundCleanup is set and LazyCleanupBatch is zero
+const DefaultLazyCleanupBatch = 16
+
+// Compression defaults, overridable with CompressionMinSize and CompressionLevel
+const (
+	DefaultCompressionMinSize = 64
+	DefaultCompressionLevel   = gzip.DefaultCompression
+)
+
+// Compression header format: headerMagic, headerVersion, one length byte, then the header.
+// Data without the magic is read as the legacy format, a fixed 4-byte header.
+const (
+	headerMagic           = "\xffMH"
+	headerVersion    byte = 2
+	maxHeaderLength       = 255
+	legacyHeaderSize      = 4
+)
+
 // Compression helpers
 func compressGzipWithHeader(data []byte, header string) ([]byte, error) {
+	if len(header) > maxHeaderLength {
+		return nil, fmt.Errorf("compression header too long: %d bytes (max %d)", len(header), maxHeaderLength)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString(headerMagic)
+	buf.WriteByte(headerVersion)
+	buf.WriteByte(byte(len(header))) // nosec G115 - Safe: length is checked against maxHeaderLength above
+	buf.WriteString(header)
+	if err := writeGzip(buf, data, DefaultCompressionMinSize, DefaultCompressionLevel); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// compressGzipLevel gzips data at the given level, storing bodies under minSize as-is
+func compressGzipLevel(data []byte, minSize, level int) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := writeGzip(buf, data, minSize, level); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// writeGzip appends data to buf, gzipped at level unless it is shorter than minSize
+func writeGzip(buf *bytes.Buffer, data []byte, minSize, level int) error {
 	// Skip compression for small data (compression overhead > benefit)
-	if len(data) < 64 {
-		var buf bytes.Buffer
-		buf.WriteString(header)
+	if len(data) < minSize {
 		buf.Write(data)
-		return buf.Bytes(), nil
+		return nil
 	}
 
-	var buf bytes.Buffer
-	buf.WriteString(header)
-	w := gzip.NewWriter(&buf)
-	_, err := w.Write(data)
+	w, err := getGzipWriter(buf, level)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
 	if err != nil {
 		if closeErr := w.Close(); closeErr != nil {
-			return nil, fmt.Errorf("write error: %v, close error: %v", err, closeErr)
+			return fmt.Errorf("write error: %v, close error: %v", err, closeErr)
 		}
-		return nil, err
+		return err
 	}
 	if err := w.Close(); err != nil {
-		return nil, err
+		return err
 	}
-	return buf.Bytes(), nil
+	putGzipWriter(w, level)
+	return nil
 }
 
+// decompressGzipWithHeader splits data written by compressGzipWithHeader into its header
+// and decompressed payload. Data in the legacy fixed 4-byte header format is still accepted.
 func decompressGzipWithHeader(data []byte) (header string, payload []byte, err error) {
-	if len(data) < 4 {
+	prefix := len(headerMagic) + 2
+	if len(data) >= prefix && string(data[:len(headerMagic)]) == headerMagic && data[len(headerMagic)] == headerVersion {
+		end := prefix + int(data[prefix-1])
+		if len(data) < end {
+			return "", nil, fmt.Errorf("data too short for header")
+		}
+		payload, err = decompressGzip(data[end:])
+		return string(data[prefix:end]), payload, err
+	}
+
+	if len(data) < legacyHeaderSize {
 		return "", nil, fmt.Errorf("data too short for header")
 	}
-	header = string(data[:4])
+	header = string(data[:legacyHeaderSize])
+	payload, err = decompressGzip(data[legacyHeaderSize:])
+	return header, payload, err
+}
 
-	// If data is exactly 4 bytes, it's just a header
-	if len(data) == 4 {
-		return header, nil, nil
+// decompressGzip restores a body written by compressGzipWithHeader, which stores small bodies raw
+func decompressGzip(body []byte) ([]byte, error) {
+	// A header-only value has no payload
+	if len(body) == 0 {
+		return nil, nil
 	}
 
 	// Check if data is compressed (has gzip header)
-	if len(data) >= 10 && data[4] == 0x1f && data[5] == 0x8b {
+	if len(body) >= 6 && body[0] == 0x1f && body[1] == 0x8b {
 		// Compressed data - use gzip decompression
-		r, err := gzip.NewReader(bytes.NewReader(data[4:]))
+		r, err := getGzipReader(bytes.NewReader(body))
 		if err != nil {
-			return header, nil, err
+			return nil, err
 		}
-		defer r.Close()
-		out, err := io.ReadAll(r)
-		if err != nil {
-			return header, nil, err
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if _, err := buf.ReadFrom(r); err != nil {
+			return nil, err
 		}
-		return header, out, nil
+		putGzipReader(r)
+		return bytes.Clone(buf.Bytes()), nil
 	}
 
 	// For data that's not compressed but has a gzip-like header, return error
-	if len(data) >= 6 && data[4] == 0x1f && data[5] == 0x8b {
-		return header, nil, fmt.Errorf("invalid gzip data")
+	if len(body) >= 2 && body[0] == 0x1f && body[1] == 0x8b {
+		return nil, fmt.Errorf("invalid gzip data")
 	}
 
 	// Uncompressed data - return as-is
-	return header, data[4:], nil
+	return body, nil
 }
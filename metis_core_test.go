@@ -461,10 +461,11 @@ func TestCompressGzipWithHeader_ErrorPaths(t *testing.T) {
 				t.Errorf("Expected non-empty result for test %s", tt.name)
 			}
 
-			// Verify header is present
-			if !tt.expectError && len(result) >= 4 {
-				if string(result[:4]) != tt.header {
-					t.Errorf("Expected header %s, got %s", tt.header, string(result[:4]))
+			// Verify header is present after the magic, version and length bytes
+			if !tt.expectError {
+				end := headerEnd(tt.header)
+				if len(result) < end || string(result[end-len(tt.header):end]) != tt.header {
+					t.Errorf("Expected header %s in %q", tt.header, result)
 				}
 			}
 		})
@@ -478,6 +479,7 @@ func TestDecompressGzipWithHeader_ErrorPaths(t *testing.T) {
 		setupData   func() []byte
 		expectError bool
 		description string
+		header      string // Expected header when not the legacy first 4 bytes
 	}{
 		{
 			name: "TooShortData",
@@ -541,6 +543,7 @@ func TestDecompressGzipWithHeader_ErrorPaths(t *testing.T) {
 			},
 			expectError: false,
 			description: "Valid compressed data should decompress successfully",
+			header:      "TEST",
 		},
 		{
 			name: "EmptyPayloadAfterHeader",
@@ -569,6 +572,9 @@ func TestDecompressGzipWithHeader_ErrorPaths(t *testing.T) {
 			// For successful cases, verify header is extracted correctly
 			if !tt.expectError && len(data) >= 4 {
 				expectedHeader := string(data[:4])
+				if tt.header != "" {
+					expectedHeader = tt.header
+				}
 				if header != expectedHeader {
 					t.Errorf("Expected header %s, got %s", expectedHeader, header)
 				}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
 )
 
 // TestStrategicCache_Set_CachingDisabled tests Set when caching is disabled
@@ -126,10 +128,12 @@ func TestStrategicCache_Set_ExactSizeLimits(t *testing.T) {
 
 // TestStrategicCache_Set_TTLExpiredEntry tests updating an already expired entry
 func TestStrategicCache_Set_TTLExpiredEntry(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching: true,
 		CacheSize:     100,
 		TTL:           time.Millisecond * 10, // Very short TTL
+		Clock:         clock,
 	}
 	cache := NewStrategicCache(config)
 	defer cache.Close()
@@ -138,7 +142,7 @@ func TestStrategicCache_Set_TTLExpiredEntry(t *testing.T) {
 	cache.Set("expiring", "initial")
 
 	// Wait for expiration
-	time.Sleep(time.Millisecond * 15)
+	clock.Advance(time.Millisecond * 15)
 
 	// Set new value for expired key
 	result := cache.Set("expiring", "updated")
@@ -180,12 +184,13 @@ func TestCompressGzipWithHeader_DataSizeBoundaries(t *testing.T) {
 				t.Errorf("Expected no error for size %d, got: %v", tc.size, err)
 			}
 
-			if len(compressed) < 4 {
-				t.Errorf("Expected compressed data to include 4-byte header, got %d bytes", len(compressed))
+			end := headerEnd("TEST")
+			if len(compressed) < end {
+				t.Fatalf("Expected compressed data to include the %d-byte header, got %d bytes", end, len(compressed))
 			}
 
 			// Verify header
-			header := string(compressed[:4])
+			header := string(compressed[end-4 : end])
 			if header != "TEST" {
 				t.Errorf("Expected header 'TEST', got '%s'", header)
 			}
@@ -247,8 +252,9 @@ func TestDecompressGzipWithHeader_CorruptedGzipStream(t *testing.T) {
 	copy(corrupted, compressed)
 
 	// Corrupt multiple bytes in the gzip stream to ensure error
-	if len(corrupted) > 15 {
-		for i := 8; i < 15 && i < len(corrupted); i++ {
+	start := headerEnd("TEST") + 4
+	if len(corrupted) > start+7 {
+		for i := start; i < start+7; i++ {
 			corrupted[i] = ^corrupted[i] // Flip all bits
 		}
 	}
@@ -337,16 +343,17 @@ func TestCompressGzipWithHeader_ZeroSizeThreshold(t *testing.T) {
 	}
 
 	// Should not be compressed, just header + data
-	expectedLen := 4 + 63 // header + data
+	end := headerEnd("UND6")
+	expectedLen := end + 63 // header + data
 	if len(compressed) != expectedLen {
-		t.Errorf("Expected uncompressed data length %d, got %d", expectedLen, len(compressed))
+		t.Fatalf("Expected uncompressed data length %d, got %d", expectedLen, len(compressed))
 	}
 
 	// Verify it contains header + original data
-	if string(compressed[:4]) != "UND6" {
+	if string(compressed[end-4:end]) != "UND6" {
 		t.Error("Header not correct for uncompressed data")
 	}
-	if !bytes.Equal(compressed[4:], data63) {
+	if !bytes.Equal(compressed[end:], data63) {
 		t.Error("Data portion not correct for uncompressed data")
 	}
 }
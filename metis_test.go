@@ -10,13 +10,13 @@ import (
 	"container/list"
 	"encoding/gob"
 	"fmt"
-	"math"
 	"reflect"
-	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
 )
 
 // TestStruct is used for testing serialization
@@ -98,6 +98,7 @@ func TestStrategicCache_BasicOperations(t *testing.T) {
 }
 
 func TestStrategicCache_TTLExpiration(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:     true,
 		CacheSize:         10,
@@ -109,6 +110,7 @@ func TestStrategicCache_TTLExpiration(t *testing.T) {
 		EvictionPolicy:    "lru", // Use LRU which supports TTL
 		AdmissionPolicy:   "always",
 		ShardCount:        1, // Single shard for deterministic behavior
+		Clock:             clock,
 	}
 
 	cache := NewStrategicCache(config)
@@ -125,8 +127,8 @@ func TestStrategicCache_TTLExpiration(t *testing.T) {
 		t.Error("Value should exist immediately after Set")
 	}
 
-	// Wait for TTL to expire
-	time.Sleep(100 * time.Millisecond)
+	// Move past the TTL
+	clock.Advance(100 * time.Millisecond)
 
 	// Should not exist after TTL
 	_, exists = cache.Get(key)
@@ -448,129 +450,13 @@ func TestStrategicCache_PrimitiveTypes_Robustness(t *testing.T) {
 	}
 }
 
-// validatePrimitiveValue performs type-aware validation of cached values
+// validatePrimitiveValue requires the cached value to come back with its exact type and value
 func validatePrimitiveValue(t *testing.T, key string, expected, got interface{}, compression bool) {
-	switch orig := expected.(type) {
-	case int, int32, int64, uint, uint32, uint64:
-		validateIntegerValue(t, key, orig, got, compression)
-	case float32, float64:
-		validateFloatValue(t, key, orig, got, compression)
-	case bool:
-		validateBoolValue(t, key, orig, got, compression)
-	case string:
-		validateStringValue(t, key, orig, got, compression)
-	default:
-		t.Fatalf("Unknown type for key '%s': %T (compression=%v)", key, orig, compression)
-	}
-}
-
-func validateIntegerValue(t *testing.T, key string, expected, got interface{}, compression bool) {
-	// Convert expected to int64 for comparison
-	var want int64
-	switch x := expected.(type) {
-	case int:
-		want = int64(x)
-	case int32:
-		want = int64(x)
-	case int64:
-		want = x
-	case uint:
-		want = int64(x)
-	case uint32:
-		want = int64(x)
-	case uint64:
-		want = int64(x)
-	}
-
-	// Convert got to int64 for comparison
-	var gotInt int64
-	switch val := got.(type) {
-	case int:
-		gotInt = int64(val)
-	case int32:
-		gotInt = int64(val)
-	case int64:
-		gotInt = val
-	case uint:
-		gotInt = int64(val)
-	case uint32:
-		gotInt = int64(val)
-	case uint64:
-		gotInt = int64(val)
-	case string:
-		var err error
-		gotInt, err = strconv.ParseInt(val, 10, 64)
-		if err != nil {
-			t.Fatalf("Expected integer for key '%s', got string '%v' (compression=%v)", key, val, compression)
-		}
-	default:
-		t.Fatalf("Expected integer for key '%s', got '%v' (type %T, compression=%v)", key, got, got, compression)
-	}
-
-	if gotInt != want {
-		t.Fatalf("Value mismatch for key '%s': want %d, got %d (compression=%v)", key, want, gotInt, compression)
-	}
-}
-
-func validateFloatValue(t *testing.T, key string, expected, got interface{}, compression bool) {
-	// Convert expected to float64 for comparison
-	var want float64
-	switch x := expected.(type) {
-	case float32:
-		want = float64(x)
-	case float64:
-		want = x
-	}
-
-	// Convert got to float64 for comparison
-	var gotF float64
-	switch val := got.(type) {
-	case float32:
-		gotF = float64(val)
-	case float64:
-		gotF = val
-	case string:
-		var err error
-		gotF, err = strconv.ParseFloat(val, 64)
-		if err != nil {
-			t.Fatalf("Expected float for key '%s', got string '%v' (compression=%v)", key, val, compression)
-		}
-	default:
-		t.Fatalf("Expected float for key '%s', got '%v' (type %T, compression=%v)", key, got, got, compression)
+	if reflect.TypeOf(got) != reflect.TypeOf(expected) {
+		t.Fatalf("Type mismatch for key '%s': want %T, got %T (compression=%v)", key, expected, got, compression)
 	}
-
-	// Use small epsilon for float comparison
-	const epsilon = 1e-6
-	if math.Abs(gotF-want) > epsilon {
-		t.Fatalf("Value mismatch for key '%s': want %f, got %f (compression=%v)", key, want, gotF, compression)
-	}
-}
-
-func validateBoolValue(t *testing.T, key string, expected, got interface{}, compression bool) {
-	wantB := expected.(bool)
-	var gotB bool
-	switch val := got.(type) {
-	case bool:
-		gotB = val
-	case string:
-		var err error
-		gotB, err = strconv.ParseBool(val)
-		if err != nil {
-			t.Fatalf("Expected bool for key '%s', got string '%v' (compression=%v)", key, val, compression)
-		}
-	default:
-		t.Fatalf("Expected bool for key '%s', got '%v' (type %T, compression=%v)", key, got, got, compression)
-	}
-
-	if gotB != wantB {
-		t.Fatalf("Value mismatch for key '%s': want %v, got %v (compression=%v)", key, wantB, gotB, compression)
-	}
-}
-
-func validateStringValue(t *testing.T, key string, expected, got interface{}, compression bool) {
-	wantS := expected.(string)
-	if got != wantS {
-		t.Fatalf("Value mismatch for key '%s': want '%v', got '%v' (compression=%v)", key, wantS, got, compression)
+	if got != expected {
+		t.Fatalf("Value mismatch for key '%s': want %v, got %v (compression=%v)", key, expected, got, compression)
 	}
 }
 
@@ -986,8 +872,8 @@ func TestCacheConfigDefaults(t *testing.T) {
 	if cache.config.CleanupInterval != 2*time.Minute {
 		t.Errorf("Expected default CleanupInterval 2m, got %v", cache.config.CleanupInterval)
 	}
-	if cache.config.ShardCount != 32 {
-		t.Errorf("Expected default ShardCount 32, got %d", cache.config.ShardCount)
+	if want := autoShardCount(); cache.config.ShardCount != want {
+		t.Errorf("Expected default ShardCount %d, got %d", want, cache.config.ShardCount)
 	}
 }
 
@@ -1099,6 +985,7 @@ func TestCacheAdmissionPolicies(t *testing.T) {
 
 // TestCacheTTLExpiration tests TTL expiration
 func TestCacheTTLExpiration(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:   true,
 		CacheSize:       10,
@@ -1107,6 +994,7 @@ func TestCacheTTLExpiration(t *testing.T) {
 		ShardCount:      1,
 		AdmissionPolicy: "always",
 		MaxShardSize:    10, // Explicitly set for deterministic behavior
+		Clock:           clock,
 	}
 
 	cache := NewStrategicCache(config)
@@ -1120,8 +1008,8 @@ func TestCacheTTLExpiration(t *testing.T) {
 		t.Error("Value should exist immediately after Set")
 	}
 
-	// Wait for TTL to expire
-	time.Sleep(100 * time.Millisecond)
+	// Move past the TTL
+	clock.Advance(100 * time.Millisecond)
 
 	// Value should be expired
 	if _, exists := cache.Get("key"); exists {
@@ -1452,6 +1340,7 @@ func TestCacheCompressionFallback(t *testing.T) {
 
 // TestCacheExpiredEntry tests expired entry handling
 func TestCacheExpiredEntry(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:   true,
 		CacheSize:       10,
@@ -1459,6 +1348,7 @@ func TestCacheExpiredEntry(t *testing.T) {
 		CleanupInterval: 1 * time.Millisecond,
 		ShardCount:      1,
 		AdmissionPolicy: "always",
+		Clock:           clock,
 	}
 	cache := NewStrategicCache(config)
 	defer cache.Close()
@@ -1466,8 +1356,8 @@ func TestCacheExpiredEntry(t *testing.T) {
 	// Set a value
 	cache.Set("key", "value")
 
-	// Wait for it to expire
-	time.Sleep(10 * time.Millisecond)
+	// Move past its TTL
+	clock.Advance(10 * time.Millisecond)
 
 	// Try to get the expired value
 	if _, exists := cache.Get("key"); exists {
@@ -1687,12 +1577,14 @@ func TestCacheCompressionWithComplexTypes(t *testing.T) {
 
 // TestCacheConcurrentAccessWithTTL tests concurrent access with TTL
 func TestCacheConcurrentAccessWithTTL(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:   true,
 		CacheSize:       100,
 		ShardCount:      4,
 		TTL:             100 * time.Millisecond,
 		CleanupInterval: 10 * time.Millisecond,
+		Clock:           clock,
 	}
 	cache := NewStrategicCache(config)
 	defer cache.Close()
@@ -1725,8 +1617,8 @@ func TestCacheConcurrentAccessWithTTL(t *testing.T) {
 
 	wg.Wait()
 
-	// Wait for some TTL expiration
-	time.Sleep(150 * time.Millisecond)
+	// Move past the TTL
+	clock.Advance(150 * time.Millisecond)
 
 	// Force cleanup to run
 	for i := 0; i < config.ShardCount; i++ {
@@ -2551,6 +2443,7 @@ func TestCacheConcurrent_EdgeCases(t *testing.T) {
 // TestCacheCleanup_EdgeCases tests cleanup edge cases
 func TestCacheCleanup_EdgeCases(t *testing.T) {
 	// Test with very short TTL and cleanup interval
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:   true,
 		CacheSize:       100,
@@ -2558,6 +2451,7 @@ func TestCacheCleanup_EdgeCases(t *testing.T) {
 		TTL:             1 * time.Microsecond,
 		CleanupInterval: 1 * time.Microsecond,
 		AdmissionPolicy: "always",
+		Clock:           clock,
 	}
 	cache := NewStrategicCache(config)
 	defer cache.Close()
@@ -2565,8 +2459,8 @@ func TestCacheCleanup_EdgeCases(t *testing.T) {
 	// Set a value
 	cache.Set("key", "value")
 
-	// Wait a bit for cleanup to run
-	time.Sleep(10 * time.Millisecond)
+	// Move past the TTL, ticking the cleanup loop
+	clock.Advance(10 * time.Millisecond)
 
 	// Value should be expired
 	if _, exists := cache.Get("key"); exists {
@@ -0,0 +1,31 @@
+// clock.go: Fake clock for testing code that uses Metis caches
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package metistest provides test helpers for code built on Metis caches.
+package metistest
+
+import (
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// Clock is a metis.Clock whose time only moves when Advance or Set is called, so TTL
+// expiration can be tested without sleeping. Install it with CacheConfig.Clock:
+//
+//	clock := metistest.NewClock(time.Time{})
+//	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, TTL: time.Minute, Clock: clock})
+//	cache.Set("k", "v")
+//	clock.Advance(2 * time.Minute) // "k" is now expired
+//
+// The cleanup routines tick on the fake clock too, so expired entries are swept in the
+// background shortly after an Advance past CleanupInterval.
+type Clock = fakeclock.Clock
+
+// NewClock returns a fake clock set to start, or to a fixed date when start is zero
+func NewClock(start time.Time) *Clock {
+	return fakeclock.New(start)
+}
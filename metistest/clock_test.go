@@ -0,0 +1,115 @@
+// clock_test.go: Tests for the fake clock
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metistest_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/metistest"
+)
+
+var _ metis.Clock = (*metistest.Clock)(nil)
+
+func TestClock_AdvanceAndSet(t *testing.T) {
+	start := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	clock := metistest.NewClock(start)
+	if !clock.Now().Equal(start) {
+		t.Fatalf("expected %v, got %v", start, clock.Now())
+	}
+	clock.Advance(time.Hour)
+	if want := start.Add(time.Hour); !clock.Now().Equal(want) {
+		t.Errorf("expected %v after Advance, got %v", want, clock.Now())
+	}
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("expected Set to move the clock back to %v, got %v", start, clock.Now())
+	}
+	if metistest.NewClock(time.Time{}).Now().IsZero() {
+		t.Error("expected a zero start to use a fixed date")
+	}
+}
+
+func TestClock_Ticker(t *testing.T) {
+	clock := metistest.NewClock(time.Time{})
+	ticks, stop := clock.NewTicker(time.Second)
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-ticks:
+		t.Fatal("expected no tick before the interval")
+	default:
+	}
+
+	// Several intervals in one step deliver a single tick, like a slow time.Ticker receiver
+	clock.Advance(3 * time.Second)
+	select {
+	case now := <-ticks:
+		if !now.Equal(clock.Now()) {
+			t.Errorf("expected the tick to carry %v, got %v", clock.Now(), now)
+		}
+	default:
+		t.Fatal("expected a tick after the interval")
+	}
+	select {
+	case <-ticks:
+		t.Fatal("expected the passed deadlines to collapse into one tick")
+	default:
+	}
+
+	stop()
+	clock.Advance(time.Hour)
+	select {
+	case <-ticks:
+		t.Error("expected no tick after stop")
+	default:
+	}
+}
+
+func TestClock_ExpiresCacheEntries(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := metistest.NewClock(time.Time{})
+			cache := metis.NewStrategicCache(metis.CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			cache.Set("k", "v")
+			cache.SetWithOptions("short", "v", metis.WithTTL(10*time.Second))
+			clock.Advance(30 * time.Second)
+			if _, ok := cache.Get("short"); ok {
+				t.Error("expected the WithTTL entry to expire on the fake clock")
+			}
+			if _, ok := cache.Get("k"); !ok {
+				t.Error("expected the entry to live until the cache TTL")
+			}
+			clock.Advance(time.Minute)
+			if _, ok := cache.Get("k"); ok {
+				t.Error("expected the entry to expire after the cache TTL")
+			}
+		})
+	}
+}
+
+func ExampleClock() {
+	clock := metistest.NewClock(time.Time{})
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, TTL: time.Minute, Clock: clock})
+	defer cache.Close()
+
+	cache.Set("session", "token")
+	clock.Advance(2 * time.Minute)
+	_, ok := cache.Get("session")
+	fmt.Println("found after the TTL:", ok)
+	// Output: found after the TTL: false
+}
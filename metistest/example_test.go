@@ -0,0 +1,65 @@
+// example_test.go: Examples for the metistest package
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metistest_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/metistest"
+)
+
+// UserService depends on metis.Cacher rather than *metis.StrategicCache, so production
+// code passes a real cache and tests pass a Fake
+type UserService struct {
+	cache metis.Cacher
+	load  func(id string) string
+}
+
+// Name returns the user's name from the cache, loading and caching it on a miss
+func (s *UserService) Name(id string) string {
+	if v, ok := s.cache.Get("user:" + id); ok {
+		return v.(string)
+	}
+	name := s.load(id)
+	s.cache.Set("user:"+id, name)
+	return name
+}
+
+func ExampleNewFake() {
+	fake := metistest.NewFake()
+	svc := &UserService{cache: fake, load: func(id string) string { return "Ada" }}
+
+	svc.Name("42")
+	svc.Name("42")
+	fmt.Println("gets:", fake.GetCalls())
+	fmt.Println("sets:", len(fake.SetCalls()))
+	// Output:
+	// gets: [user:42 user:42]
+	// sets: 1
+}
+
+func ExampleFake_FailNextSet() {
+	fake := metistest.NewFake()
+	svc := &UserService{cache: fake, load: func(id string) string { return "Ada" }}
+
+	// A cache that refuses the write must not break the lookup
+	fake.FailNextSet(errors.New("cache full"))
+	fmt.Println(svc.Name("42"), fake.Len())
+	// Output: Ada 0
+}
+
+func Example_production() {
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 1000, TTL: time.Minute})
+	defer cache.Close()
+
+	svc := &UserService{cache: cache, load: func(id string) string { return "Ada" }}
+	fmt.Println(svc.Name("42"))
+	// Output: Ada
+}
@@ -0,0 +1,187 @@
+// fake.go: In-memory fake cache for testing code that uses Metis caches
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metistest
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/agilira/metis"
+)
+
+// SetCall records one Set or SetE call on a Fake
+type SetCall struct {
+	Key   string
+	Value interface{}
+}
+
+// FakeOption customizes a Fake created by NewFake
+type FakeOption func(*Fake)
+
+// WithCapacity bounds the number of entries a Fake holds; beyond it the least recently
+// used entry is evicted. The default is unbounded.
+func WithCapacity(n int) FakeOption {
+	return func(f *Fake) { f.capacity = n }
+}
+
+// Fake is a metis.Cacher backed by a plain map, for unit tests of code that depends on a
+// cache. It runs no goroutines and has no TTLs; eviction is strict LRU when a capacity is
+// set. Failures can be scripted with FailNextSet, and calls are recorded for assertions.
+// It is safe for concurrent use.
+type Fake struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Front is the most recently used
+	closed   bool
+
+	failNextSet error
+	setCalls    []SetCall
+	getCalls    []string
+
+	hits, misses, evictions int64
+}
+
+// fakeEntry is the payload of an order element
+type fakeEntry struct {
+	key   string
+	value interface{}
+}
+
+var _ metis.Cacher = (*Fake)(nil)
+
+// NewFake returns an empty Fake
+func NewFake(opts ...FakeOption) *Fake {
+	f := &Fake{entries: make(map[string]*list.Element), order: list.New()}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Get returns the value stored for key and records the call
+func (f *Fake) Get(key string) (interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.getCalls = append(f.getCalls, key)
+	elem, ok := f.entries[key]
+	if !ok || f.closed {
+		f.misses++
+		return nil, false
+	}
+	f.hits++
+	f.order.MoveToFront(elem)
+	return elem.Value.(*fakeEntry).value, true
+}
+
+// Set stores a value and records the call. It returns false after Close or when the call
+// consumes an error scripted with FailNextSet.
+func (f *Fake) Set(key string, value interface{}) bool {
+	return f.SetE(key, value) == nil
+}
+
+// SetE is Set reporting the failure: the error scripted with FailNextSet, or
+// metis.ErrCacheClosed after Close
+func (f *Fake) SetE(key string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.setCalls = append(f.setCalls, SetCall{Key: key, Value: value})
+	if err := f.failNextSet; err != nil {
+		f.failNextSet = nil
+		return err
+	}
+	if f.closed {
+		return metis.ErrCacheClosed
+	}
+
+	if elem, ok := f.entries[key]; ok {
+		elem.Value.(*fakeEntry).value = value
+		f.order.MoveToFront(elem)
+		return nil
+	}
+	f.entries[key] = f.order.PushFront(&fakeEntry{key: key, value: value})
+	for f.capacity > 0 && f.order.Len() > f.capacity {
+		oldest := f.order.Back()
+		f.order.Remove(oldest)
+		delete(f.entries, oldest.Value.(*fakeEntry).key)
+		f.evictions++
+	}
+	return nil
+}
+
+// Delete removes key, reporting whether it was present
+func (f *Fake) Delete(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	elem, ok := f.entries[key]
+	if !ok {
+		return false
+	}
+	f.order.Remove(elem)
+	delete(f.entries, key)
+	return true
+}
+
+// Clear removes every entry; recorded calls and statistics are kept
+func (f *Fake) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = make(map[string]*list.Element)
+	f.order.Init()
+}
+
+// Len returns the number of entries held
+func (f *Fake) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.entries)
+}
+
+// GetStats returns the hits, misses, evictions and key count seen so far
+func (f *Fake) GetStats() metis.CacheStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return metis.CacheStats{
+		Hits:      f.hits,
+		Misses:    f.misses,
+		Evictions: f.evictions,
+		Keys:      len(f.entries),
+		Size:      int64(len(f.entries)),
+	}
+}
+
+// Close makes later Gets miss and Sets fail with metis.ErrCacheClosed
+func (f *Fake) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+}
+
+// FailNextSet makes the next Set or SetE fail with err without storing anything.
+// A nil err cancels a scripted failure.
+func (f *Fake) FailNextSet(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNextSet = err
+}
+
+// SetCalls returns the Set and SetE calls recorded so far, in order
+func (f *Fake) SetCalls() []SetCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SetCall(nil), f.setCalls...)
+}
+
+// GetCalls returns the keys passed to Get so far, in order
+func (f *Fake) GetCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.getCalls...)
+}
@@ -0,0 +1,102 @@
+// fake_test.go: Tests for the fake cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metistest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/agilira/metis"
+	"github.com/agilira/metis/metistest"
+)
+
+func TestFake_Basics(t *testing.T) {
+	f := metistest.NewFake()
+	if _, ok := f.Get("a"); ok {
+		t.Error("expected a miss on an empty fake")
+	}
+	f.Set("a", 1)
+	f.Set("b", 2)
+	if v, ok := f.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a=1, got %v (found %v)", v, ok)
+	}
+	if !f.Delete("b") || f.Delete("b") {
+		t.Error("expected Delete to report presence")
+	}
+	if stats := f.GetStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Keys != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	f.Clear()
+	if f.Len() != 0 {
+		t.Errorf("expected an empty fake after Clear, got %d entries", f.Len())
+	}
+
+	f.Close()
+	if err := f.SetE("c", 3); !errors.Is(err, metis.ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed after Close, got %v", err)
+	}
+}
+
+func TestFake_LRUEviction(t *testing.T) {
+	f := metistest.NewFake(metistest.WithCapacity(2))
+	f.Set("a", 1)
+	f.Set("b", 2)
+	f.Get("a") // b is now the least recently used
+	f.Set("c", 3)
+
+	if _, ok := f.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := f.Get(key); !ok {
+			t.Errorf("expected %s to remain", key)
+		}
+	}
+	if n := f.GetStats().Evictions; n != 1 {
+		t.Errorf("expected 1 eviction, got %d", n)
+	}
+}
+
+func TestFake_FailNextSet(t *testing.T) {
+	f := metistest.NewFake()
+	boom := errors.New("boom")
+
+	f.FailNextSet(boom)
+	if err := f.SetE("a", 1); !errors.Is(err, boom) {
+		t.Errorf("expected the scripted error, got %v", err)
+	}
+	if _, ok := f.Get("a"); ok {
+		t.Error("expected the failed Set not to store the value")
+	}
+	if !f.Set("a", 1) {
+		t.Error("expected the failure to apply to one call only")
+	}
+
+	f.FailNextSet(boom)
+	f.FailNextSet(nil)
+	if !f.Set("b", 2) {
+		t.Error("expected FailNextSet(nil) to cancel the scripted failure")
+	}
+}
+
+func TestFake_RecordsCalls(t *testing.T) {
+	f := metistest.NewFake()
+	f.FailNextSet(errors.New("boom"))
+	f.Set("a", 1)
+	f.Set("a", 2)
+	f.Get("a")
+	f.Get("missing")
+
+	wantSets := []metistest.SetCall{{Key: "a", Value: 1}, {Key: "a", Value: 2}}
+	if got := f.SetCalls(); !reflect.DeepEqual(got, wantSets) {
+		t.Errorf("expected set calls %v, got %v", wantSets, got)
+	}
+	if got := f.GetCalls(); !reflect.DeepEqual(got, []string{"a", "missing"}) {
+		t.Errorf("expected get calls [a missing], got %v", got)
+	}
+}
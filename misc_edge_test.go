@@ -9,6 +9,8 @@ package metis
 import (
 	"testing"
 	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
 )
 
 // TestEstimateMemoryUsage_EdgeCases tests memory estimation edge cases
@@ -127,6 +129,7 @@ func TestCompression_ExtensiveCases(t *testing.T) {
 
 // TestLRU_GetComplexScenarios tests LRU Get function edge cases
 func TestLRU_GetComplexScenarios(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		EnableCaching:     true,
 		CacheSize:         10,
@@ -135,6 +138,7 @@ func TestLRU_GetComplexScenarios(t *testing.T) {
 		EnableCompression: true,
 		TTL:               100 * time.Millisecond,
 		CleanupInterval:   50 * time.Millisecond,
+		Clock:             clock,
 	}
 	cache := NewStrategicCache(config)
 	defer cache.Close()
@@ -157,8 +161,8 @@ func TestLRU_GetComplexScenarios(t *testing.T) {
 	// Test with TTL expiration
 	cache.Set("ttl_test", "expires_soon")
 
-	// Wait for expiration
-	time.Sleep(150 * time.Millisecond)
+	// Move past the TTL
+	clock.Advance(150 * time.Millisecond)
 
 	// Try to get expired value
 	value, exists := cache.Get("ttl_test")
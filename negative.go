@@ -0,0 +1,71 @@
+// negative.go: Negative caching of "not found" results for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrNegativeEntry is returned by GetE for a key stored with SetNegative: the key is known
+// not to exist, so the Backend is not asked again until the entry expires. It matches
+// ErrNotFound too.
+var ErrNegativeEntry = fmt.Errorf("%w: cached as absent", ErrNotFound)
+
+// negativeValue is the value stored for a negative entry. It is never compressed,
+// returned, saved in snapshots or passed to OnEvict handlers.
+type negativeValue struct{}
+
+// isNegative reports whether a stored value marks a negative entry
+func isNegative(value interface{}) bool {
+	_, ok := value.(negativeValue)
+	return ok
+}
+
+// isInternal reports whether a stored value is bookkeeping rather than a user value:
+// a negative entry, a stream, or a chunked value's manifest or chunks. Such values are
+// never compressed, saved in snapshots, visited by Range or passed to OnEvict handlers;
+// snapshots and Range reassemble chunked values instead.
+func isInternal(value interface{}) bool {
+	switch value.(type) {
+	case negativeValue, *streamValue, *chunkManifest, chunkPiece:
+		return true
+	}
+	return false
+}
+
+// SetNegative records that key does not exist for ttl, or for the cache TTL when ttl is
+// not positive. Until it expires or is overwritten, Get reports a miss and GetE, GetCtx
+// and LoadOrCompute return ErrNegativeEntry without calling the Backend or loader.
+// Reads of the entry are counted in CacheStats.NegativeHits. The Backend is not updated.
+func (sc *StrategicCache) SetNegative(key string, ttl time.Duration) error {
+	if err := sc.setNegative(key, ttl); err != nil {
+		return err
+	}
+	sc.publishInvalidation(key)
+	return nil
+}
+
+// setNegative stores a negative entry without publishing it, as LoadOrCompute does
+func (sc *StrategicCache) setNegative(key string, ttl time.Duration) error {
+	sc.traceOp(TraceSet, key, nil)
+	o := defaultSetOptions
+	WithTTL(ttl)(&o)
+	return sc.setE(sc.HashKey(key), negativeValue{}, o)
+}
+
+// WithNegativeCache makes LoadOrCompute store a negative entry for ttl (see SetNegative)
+// when its loader fails with an error for which notFound returns true, so that the next
+// callers get ErrNegativeEntry without calling the loader. The loader's error is still
+// returned to the callers that waited on it, and does not count towards the circuit
+// breaker. Other Set calls ignore this option.
+func WithNegativeCache(ttl time.Duration, notFound func(error) bool) SetOption {
+	return func(o *setOptions) {
+		o.negativeTTL = ttl
+		o.negativeMatch = notFound
+	}
+}
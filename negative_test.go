@@ -0,0 +1,171 @@
+// negative_test.go: Tests for negative caching
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSetNegative(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			if err := cache.SetNegative("user:404", time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := cache.Get("user:404"); ok || v != nil {
+				t.Errorf("expected Get to report a miss, got %v, %v", v, ok)
+			}
+			_, err := cache.GetE("user:404")
+			if !errors.Is(err, ErrNegativeEntry) || !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNegativeEntry matching ErrNotFound, got %v", err)
+			}
+			if stats := cache.GetStats(); stats.NegativeHits != 2 {
+				t.Errorf("expected 2 negative hits, got %+v", stats)
+			}
+
+			cache.Range(func(key string, value interface{}) bool {
+				t.Errorf("expected Range to skip negative entries, got %q", key)
+				return true
+			})
+
+			// The negative TTL is independent of the cache TTL
+			clock.Advance(2 * time.Second)
+			if _, err := cache.GetE("user:404"); errors.Is(err, ErrNegativeEntry) {
+				t.Error("expected the negative entry to expire after its TTL")
+			}
+
+			// A later Set replaces the negative entry
+			_ = cache.SetNegative("user:1", time.Minute)
+			cache.Set("user:1", "alice")
+			if v, err := cache.GetE("user:1"); err != nil || v != "alice" {
+				t.Errorf("expected alice, got %v (err %v)", v, err)
+			}
+
+			cache.ResetStats()
+			if stats := cache.GetStats(); stats.NegativeHits != 0 {
+				t.Errorf("expected ResetStats to clear NegativeHits, got %d", stats.NegativeHits)
+			}
+		})
+	}
+}
+
+func TestSetNegative_SkipsBackend(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			backend := newMapBackend()
+			backend.data["k"] = "from backend"
+			cache := newBackendCache(policy, backend)
+			defer cache.Close()
+
+			if err := cache.SetNegative("k", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if backend.stores != 0 {
+				t.Errorf("expected SetNegative not to write the backend, got %d stores", backend.stores)
+			}
+			if _, err := cache.GetE("k"); !errors.Is(err, ErrNegativeEntry) {
+				t.Errorf("expected ErrNegativeEntry, got %v", err)
+			}
+			if backend.loads != 0 {
+				t.Errorf("expected no backend load for a negative entry, got %d", backend.loads)
+			}
+		})
+	}
+}
+
+func TestSetNegative_Compressed(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		TTL:               time.Hour,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	if err := cache.SetNegative("k", 0); err != nil {
+		t.Fatalf("expected negative entries to bypass the serializer, got %v", err)
+	}
+	if _, err := cache.GetE("k"); !errors.Is(err, ErrNegativeEntry) {
+		t.Errorf("expected ErrNegativeEntry, got %v", err)
+	}
+}
+
+func TestSetNegative_NotSaved(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour})
+	defer cache.Close()
+	cache.Set("present", 1)
+	_ = cache.SetNegative("absent", time.Minute)
+
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	if err := cache.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour})
+	defer restored.Close()
+	if n, err := restored.LoadFromFile(path); err != nil || n != 1 {
+		t.Errorf("expected only the present key restored, got %d (err %v)", n, err)
+	}
+}
+
+func TestLoadOrCompute_WithNegativeCache(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:    true,
+				CacheSize:        100,
+				TTL:              time.Hour,
+				EvictionPolicy:   policy,
+				BreakerThreshold: 1,
+			})
+			defer cache.Close()
+
+			calls := 0
+			load := func(key string) (interface{}, error) {
+				calls++
+				return nil, sql.ErrNoRows
+			}
+			opt := WithNegativeCache(time.Minute, func(err error) bool { return errors.Is(err, sql.ErrNoRows) })
+
+			if _, err := cache.LoadOrCompute("user:404", load, opt); !errors.Is(err, sql.ErrNoRows) {
+				t.Fatalf("expected the loader's error first, got %v", err)
+			}
+			if _, err := cache.LoadOrCompute("user:404", load, opt); !errors.Is(err, ErrNegativeEntry) {
+				t.Fatalf("expected ErrNegativeEntry next, got %v", err)
+			}
+			if calls != 1 {
+				t.Errorf("expected the loader to run once, got %d calls", calls)
+			}
+			if state := cache.BreakerStats().State; state != BreakerClosed {
+				t.Errorf("expected not-found answers not to open the breaker, got %v", state)
+			}
+
+			// Errors the predicate rejects are not cached
+			failing := func(key string) (interface{}, error) { calls++; return nil, errors.New("timeout") }
+			_, _ = cache.LoadOrCompute("user:1", failing, opt)
+			if _, err := cache.GetE("user:1"); errors.Is(err, ErrNegativeEntry) {
+				t.Error("expected other loader errors not to be negative-cached")
+			}
+		})
+	}
+}
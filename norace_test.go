@@ -0,0 +1,13 @@
+// norace_test.go: Race detector flag for tests
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !race
+
+package metis
+
+// raceEnabled reports whether tests run under the race detector, which makes sync.Pool
+// drop items at random
+const raceEnabled = false
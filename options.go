@@ -0,0 +1,139 @@
+// options.go: Per-entry Set options for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"time"
+)
+
+// SetOption customizes a single SetWithOptions call
+type SetOption func(*setOptions)
+
+// setOptions holds the per-entry settings applied by SetWithOptions
+type setOptions struct {
+	cost int64
+	// expiresAt overrides the cache TTL with an absolute expiration in UnixNano (0 = use the TTL)
+	expiresAt int64
+	// ttl overrides the cache TTL relative to the time of the store (0 = use expiresAt or the TTL)
+	ttl time.Duration
+	// sliding restarts the entry's TTL on every read (see WithSlidingTTL)
+	sliding bool
+	// slide is the resolved sliding TTL stored with the entry (0 = fixed expiration)
+	slide time.Duration
+	// priority orders the entry for eviction (see WithPriority)
+	priority Priority
+	// negativeTTL and negativeMatch make LoadOrCompute cache loader not-found errors (see WithNegativeCache)
+	negativeTTL   time.Duration
+	negativeMatch func(error) bool
+	// tags are indexed for InvalidateTag once the entry is stored (see WithTags)
+	tags []string
+	// dependsOn are the parent keys whose Invalidate removes the entry (see WithDependsOn)
+	dependsOn []string
+	// fill marks a value stored by LoadOrCompute, which is not published to an InvalidationBus
+	fill bool
+}
+
+// attrs returns the options as the per-entry attributes stored by W-TinyLFU
+func (o setOptions) attrs() nodeAttrs {
+	return nodeAttrs{cost: o.cost, expiresAt: o.expiresAt, slide: int64(o.slide), priority: o.priority}
+}
+
+// Priority is a coarse eviction class: under pressure, lower-priority entries are evicted
+// before any entry of a higher priority, whatever their recency
+type Priority int8
+
+// Entry priorities, from first to last evicted
+const (
+	PriorityLow    Priority = -1 // Cheap to recompute, evicted first
+	PriorityNormal Priority = 0  // The default
+	PriorityHigh   Priority = 1  // Expensive to recompute, evicted last
+)
+
+// defaultSetOptions reproduces the behavior of a plain Set
+var defaultSetOptions = setOptions{cost: 1}
+
+// WithCost sets the weight of an entry against CacheSize, so that a large rendered page
+// can count for more than a small flag. Values below 1 are treated as 1 (the default).
+func WithCost(cost int64) SetOption {
+	return func(o *setOptions) {
+		if cost < 1 {
+			cost = 1
+		}
+		o.cost = cost
+	}
+}
+
+// WithTTL overrides the cache TTL for a single entry. Zero or negative values keep the cache TTL.
+func WithTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithSlidingTTL makes an entry expire once it goes unread for ttl, as CacheConfig.SlidingTTL
+// does for every entry: each read restarts the TTL. Zero or negative values slide the cache TTL.
+func WithSlidingTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) {
+		o.sliding = true
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithPriority sets the eviction priority of an entry (PriorityNormal by default). With the
+// "lru" policy the least recently used entry of the lowest priority is evicted; W-TinyLFU
+// admits an entry into its main segment over a lower-priority victim and never over a
+// higher-priority one. ARC ignores priorities. Values outside Low..High are clamped.
+func WithPriority(p Priority) SetOption {
+	switch {
+	case p < PriorityLow:
+		p = PriorityLow
+	case p > PriorityHigh:
+		p = PriorityHigh
+	}
+	return func(o *setOptions) {
+		o.priority = p
+	}
+}
+
+// resolveExpiry turns a WithTTL duration into an absolute expiration on the cache clock
+// and sets the sliding TTL of entries that restart their TTL on each read. Absolute
+// expirations, as restored by LoadFromFile, are kept as they are.
+func (sc *StrategicCache) resolveExpiry(o setOptions) setOptions {
+	if o.expiresAt != 0 {
+		return o
+	}
+	ttl := sc.entryTTL()
+	if o.ttl > 0 {
+		ttl = o.ttl
+		o.expiresAt = sc.clock.Now().Add(ttl).UnixNano()
+	}
+	if o.sliding || sc.config.SlidingTTL {
+		o.slide = ttl
+	}
+	return o
+}
+
+// SetWithOptions stores a value in the cache applying the given per-entry options
+func (sc *StrategicCache) SetWithOptions(key string, value interface{}, opts ...SetOption) bool {
+	return sc.SetWithOptionsE(key, value, opts...) == nil
+}
+
+// SetWithOptionsE stores a value applying the given per-entry options, reporting why it
+// was rejected as SetE does. On W-TinyLFU, an entry too costly for the window that loses
+// to a more frequent one is reported as ErrNotAdmittedFrequency.
+func (sc *StrategicCache) SetWithOptionsE(key string, value interface{}, opts ...SetOption) error {
+	o := defaultSetOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return sc.setThrough(context.Background(), sc.HashKey(key), value, o)
+}
@@ -0,0 +1,264 @@
+// otelmetis.go: OpenTelemetry instrumentation for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package otelmetis instruments a Metis cache with OpenTelemetry metrics and traces.
+// It lives in its own module so the core metis package keeps zero
+// third-party dependencies.
+package otelmetis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/agilira/metis"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// latencyBuckets are the histogram boundaries in seconds, from 100ns to 1s: in-memory
+// reads and writes land in the low microseconds
+var latencyBuckets = []float64{1e-7, 2.5e-7, 5e-7, 1e-6, 2.5e-6, 5e-6, 1e-5, 2.5e-5, 5e-5, 1e-4, 2.5e-4, 1e-3, 1e-2, 1e-1, 1}
+
+// Cache is a StrategicCache that records its operations with OpenTelemetry. It
+// implements metis.Cacher; reach the other methods through Unwrap, uninstrumented.
+type Cache struct {
+	cache  *metis.StrategicCache
+	tracer trace.Tracer
+	policy attribute.KeyValue
+
+	getDuration  metric.Float64Histogram
+	setDuration  metric.Float64Histogram
+	registration metric.Registration
+
+	// tracedBackend is set when CacheConfig.Backend was wrapped by WrapBackend, so that
+	// GetCtx can tell loads from hits
+	tracedBackend bool
+}
+
+var _ metis.Cacher = (*Cache)(nil)
+
+// Wrap instruments c with meter and tracer. It records:
+//
+//   - metis.cache.hits, metis.cache.misses and metis.cache.evictions, counters read from
+//     GetStats at each collection, so they see every access and fall back to zero after ResetStats
+//   - metis.cache.entries, an up-down counter read from Len
+//   - metis.cache.get.duration and metis.cache.set.duration, histograms in seconds; Get
+//     latencies carry cache.hit
+//
+// GetCtx, SetCtx and LoadOrCompute run in spans, and so do calls to the loader. Every
+// instrument and span carries metis.policy, the eviction policy in use.
+func Wrap(c *metis.StrategicCache, meter metric.Meter, tracer trace.Tracer) (*Cache, error) {
+	w := &Cache{
+		cache:  c,
+		tracer: tracer,
+		policy: attribute.String("metis.policy", c.PolicyName()),
+	}
+	_, w.tracedBackend = c.EffectiveConfig().Backend.(*backend)
+
+	var err error
+	if w.getDuration, err = meter.Float64Histogram("metis.cache.get.duration",
+		metric.WithDescription("Duration of cache reads."), metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(latencyBuckets...)); err != nil {
+		return nil, err
+	}
+	if w.setDuration, err = meter.Float64Histogram("metis.cache.set.duration",
+		metric.WithDescription("Duration of cache writes."), metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(latencyBuckets...)); err != nil {
+		return nil, err
+	}
+
+	hits, err := meter.Int64ObservableCounter("metis.cache.hits", metric.WithDescription("Reads served from the cache."))
+	if err != nil {
+		return nil, err
+	}
+	misses, err := meter.Int64ObservableCounter("metis.cache.misses", metric.WithDescription("Reads the cache could not serve."))
+	if err != nil {
+		return nil, err
+	}
+	evictions, err := meter.Int64ObservableCounter("metis.cache.evictions", metric.WithDescription("Entries removed to make room for new ones."))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := meter.Int64ObservableUpDownCounter("metis.cache.entries", metric.WithDescription("Entries held by the cache."))
+	if err != nil {
+		return nil, err
+	}
+	w.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := c.GetStats()
+		attrs := metric.WithAttributes(w.policy)
+		o.ObserveInt64(hits, stats.Hits, attrs)
+		o.ObserveInt64(misses, stats.Misses, attrs)
+		o.ObserveInt64(evictions, stats.Evictions, attrs)
+		o.ObserveInt64(entries, int64(c.Len()), attrs)
+		return nil
+	}, hits, misses, evictions, entries)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Unwrap returns the instrumented cache
+func (w *Cache) Unwrap() *metis.StrategicCache {
+	return w.cache
+}
+
+// Get returns the value stored for key, recording the read's duration
+func (w *Cache) Get(key string) (interface{}, bool) {
+	start := time.Now()
+	value, ok := w.cache.Get(key)
+	w.getDuration.Record(context.Background(), time.Since(start).Seconds(),
+		metric.WithAttributes(w.policy, attribute.Bool("cache.hit", ok)))
+	return value, ok
+}
+
+// Set stores a value, recording the write's duration
+func (w *Cache) Set(key string, value interface{}) bool {
+	start := time.Now()
+	ok := w.cache.Set(key, value)
+	w.setDuration.Record(context.Background(), time.Since(start).Seconds(), metric.WithAttributes(w.policy))
+	return ok
+}
+
+// Delete removes key, reporting whether it was present
+func (w *Cache) Delete(key string) bool {
+	return w.cache.Delete(key)
+}
+
+// Clear removes every entry
+func (w *Cache) Clear() {
+	w.cache.Clear()
+}
+
+// Len returns the number of entries held
+func (w *Cache) Len() int {
+	return w.cache.Len()
+}
+
+// GetStats returns the cache statistics
+func (w *Cache) GetStats() metis.CacheStats {
+	return w.cache.GetStats()
+}
+
+// Close stops reporting the cache's metrics and closes it
+func (w *Cache) Close() {
+	_ = w.registration.Unregister()
+	w.cache.Close()
+}
+
+// GetCtx is StrategicCache.GetCtx in a "metis.GetCtx" span. The span's cache.hit is
+// false for misses and, when the Backend was wrapped by WrapBackend, for keys loaded
+// from it; otherwise loads cannot be told from hits.
+func (w *Cache) GetCtx(ctx context.Context, key string) (interface{}, error) {
+	ctx, span := w.tracer.Start(ctx, "metis.GetCtx", trace.WithAttributes(w.policy))
+	defer span.End()
+
+	var loaded atomic.Bool
+	if w.tracedBackend {
+		ctx = context.WithValue(ctx, loadedKey{}, &loaded)
+	}
+	start := time.Now()
+	value, err := w.cache.GetCtx(ctx, key)
+	hit := err == nil && !loaded.Load()
+	w.getDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(w.policy, attribute.Bool("cache.hit", hit)))
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+	endWithError(span, err)
+	return value, err
+}
+
+// SetCtx is StrategicCache.SetCtx in a "metis.SetCtx" span
+func (w *Cache) SetCtx(ctx context.Context, key string, value interface{}) error {
+	ctx, span := w.tracer.Start(ctx, "metis.SetCtx", trace.WithAttributes(w.policy))
+	defer span.End()
+
+	start := time.Now()
+	err := w.cache.SetCtx(ctx, key, value)
+	w.setDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(w.policy))
+	endWithError(span, err)
+	return err
+}
+
+// LoadOrCompute is StrategicCache.LoadOrCompute in a "metis.LoadOrCompute" span, with
+// each call to fn in a child "metis.load" span. The span's cache.hit is false when this
+// call ran fn. A caller that waited for a concurrent caller's fn did not run it, and
+// reports true.
+func (w *Cache) LoadOrCompute(ctx context.Context, key string, fn func(key string) (interface{}, error), opts ...metis.SetOption) (interface{}, error) {
+	ctx, span := w.tracer.Start(ctx, "metis.LoadOrCompute", trace.WithAttributes(w.policy))
+	defer span.End()
+
+	ran := false
+	value, err := w.cache.LoadOrCompute(key, func(key string) (interface{}, error) {
+		ran = true
+		_, load := w.tracer.Start(ctx, "metis.load", trace.WithAttributes(w.policy))
+		defer load.End()
+		value, err := fn(key)
+		endWithError(load, err)
+		return value, err
+	}, opts...)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil && !ran))
+	endWithError(span, err)
+	return value, err
+}
+
+// endWithError marks span as failed by err. A miss is not a failure.
+func endWithError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, metis.ErrNotFound) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// loadedKey marks, in the context GetCtx passes down, that the backend loaded the key
+type loadedKey struct{}
+
+// backend is a metis.Backend whose calls run in spans
+type backend struct {
+	next   metis.Backend
+	tracer trace.Tracer
+}
+
+// WrapBackend returns a Backend that runs each call of b in a "metis.backend.Load",
+// "metis.backend.Store" or "metis.backend.Delete" span. Set it as CacheConfig.Backend,
+// so that the spans of Cache.GetCtx and Cache.SetCtx are their parents and GetCtx can
+// report loads as misses.
+func WrapBackend(b metis.Backend, tracer trace.Tracer) metis.Backend {
+	return &backend{next: b, tracer: tracer}
+}
+
+// Load implements metis.Backend
+func (b *backend) Load(ctx context.Context, key string) (interface{}, error) {
+	if loaded, ok := ctx.Value(loadedKey{}).(*atomic.Bool); ok {
+		loaded.Store(true)
+	}
+	ctx, span := b.tracer.Start(ctx, "metis.backend.Load", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	value, err := b.next.Load(ctx, key)
+	endWithError(span, err)
+	return value, err
+}
+
+// Store implements metis.Backend
+func (b *backend) Store(ctx context.Context, key string, value interface{}) error {
+	ctx, span := b.tracer.Start(ctx, "metis.backend.Store", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	err := b.next.Store(ctx, key, value)
+	endWithError(span, err)
+	return err
+}
+
+// Delete implements metis.Backend
+func (b *backend) Delete(ctx context.Context, key string) error {
+	ctx, span := b.tracer.Start(ctx, "metis.backend.Delete", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	err := b.next.Delete(ctx, key)
+	endWithError(span, err)
+	return err
+}
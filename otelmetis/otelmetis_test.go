@@ -0,0 +1,277 @@
+// otelmetis_test.go: Tests for the OpenTelemetry instrumentation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package otelmetis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// mapBackend is a Backend over a map
+type mapBackend struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func (b *mapBackend) Load(_ context.Context, key string) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if v, ok := b.data[key]; ok {
+		return v, nil
+	}
+	return nil, metis.ErrNotFound
+}
+
+func (b *mapBackend) Store(_ context.Context, key string, value interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data[key] = value
+	return nil
+}
+
+func (b *mapBackend) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.data, key)
+	return nil
+}
+
+// instrumented wraps a new cache with an in-memory meter and tracer
+func instrumented(t *testing.T, config metis.CacheConfig) (*Cache, *sdkmetric.ManualReader, *tracetest.SpanRecorder) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	spans := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")
+
+	config.EnableCaching = true
+	config.CacheSize = 100
+	config.TTL = time.Minute
+	w, err := Wrap(metis.NewStrategicCache(config), meter, tracer)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	t.Cleanup(w.Close)
+	return w, reader, spans
+}
+
+// collect returns the metrics read by reader, by name
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// sum returns the single data point of a counter
+func sum(t *testing.T, data metricdata.Aggregation) int64 {
+	t.Helper()
+	s, ok := data.(metricdata.Sum[int64])
+	if !ok || len(s.DataPoints) != 1 {
+		t.Fatalf("expected one int64 data point, got %#v", data)
+	}
+	return s.DataPoints[0].Value
+}
+
+// spanHit returns the cache.hit attribute of the span named name
+func spanHit(t *testing.T, spans *tracetest.SpanRecorder, name string) bool {
+	t.Helper()
+	ended := spans.Ended()
+	for i := len(ended) - 1; i >= 0; i-- {
+		if ended[i].Name() != name {
+			continue
+		}
+		for _, kv := range ended[i].Attributes() {
+			if kv.Key == "cache.hit" {
+				return kv.Value.AsBool()
+			}
+		}
+		t.Fatalf("expected %s to carry cache.hit", name)
+	}
+	t.Fatalf("expected a %s span", name)
+	return false
+}
+
+func TestWrap_Metrics(t *testing.T) {
+	w, reader, _ := instrumented(t, metis.CacheConfig{EvictionPolicy: "lru"})
+	w.Set("a", 1)
+	w.Set("b", 2)
+	w.Get("a")
+	w.Get("a")
+	w.Get("missing")
+
+	metrics := collect(t, reader)
+	if got := sum(t, metrics["metis.cache.hits"]); got != 2 {
+		t.Errorf("expected 2 hits, got %d", got)
+	}
+	if got := sum(t, metrics["metis.cache.misses"]); got != 1 {
+		t.Errorf("expected 1 miss, got %d", got)
+	}
+	if got := sum(t, metrics["metis.cache.entries"]); got != 2 {
+		t.Errorf("expected 2 entries, got %d", got)
+	}
+	if got := sum(t, metrics["metis.cache.evictions"]); got != 0 {
+		t.Errorf("expected no evictions, got %d", got)
+	}
+
+	gets, ok := metrics["metis.cache.get.duration"].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("expected a get duration histogram, got %#v", metrics["metis.cache.get.duration"])
+	}
+	counts := make(map[bool]uint64)
+	for _, dp := range gets.DataPoints {
+		hit, _ := dp.Attributes.Value("cache.hit")
+		counts[hit.AsBool()] += dp.Count
+		if policy, _ := dp.Attributes.Value("metis.policy"); policy.AsString() != "lru" {
+			t.Errorf("expected metis.policy=lru, got %q", policy.AsString())
+		}
+	}
+	if counts[true] != 2 || counts[false] != 1 {
+		t.Errorf("expected 2 hit and 1 miss latencies, got %v", counts)
+	}
+	if sets, ok := metrics["metis.cache.set.duration"].(metricdata.Histogram[float64]); !ok || sets.DataPoints[0].Count != 2 {
+		t.Errorf("expected 2 set latencies, got %#v", metrics["metis.cache.set.duration"])
+	}
+
+	w.Close()
+	if metrics := collect(t, reader); metrics["metis.cache.hits"] != nil {
+		t.Error("expected Close to stop reporting the cache")
+	}
+}
+
+func TestWrap_GetCtxSpans(t *testing.T) {
+	store := &mapBackend{data: map[string]interface{}{"remote": "v"}}
+	w, _, spans := instrumented(t, metis.CacheConfig{EvictionPolicy: "lru", Backend: WrapBackend(store, noop.NewTracerProvider().Tracer("test"))})
+
+	ctx := context.Background()
+	if _, err := w.GetCtx(ctx, "remote"); err != nil {
+		t.Fatalf("GetCtx: %v", err)
+	}
+	if spanHit(t, spans, "metis.GetCtx") {
+		t.Error("expected a backend load to report cache.hit=false")
+	}
+	if _, err := w.GetCtx(ctx, "remote"); err != nil {
+		t.Fatalf("GetCtx: %v", err)
+	}
+	if !spanHit(t, spans, "metis.GetCtx") {
+		t.Error("expected the cached key to report cache.hit=true")
+	}
+	if _, err := w.GetCtx(ctx, "nowhere"); !errors.Is(err, metis.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if last := spans.Ended()[len(spans.Ended())-1]; last.Status().Code == codes.Error {
+		t.Error("expected a miss not to mark the span as failed")
+	}
+}
+
+func TestWrapBackend_ChildSpans(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")
+	store := &mapBackend{data: map[string]interface{}{"remote": "v"}}
+	cache := metis.NewStrategicCache(metis.CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", Backend: WrapBackend(store, tracer)})
+	w, err := Wrap(cache, sdkmetric.NewMeterProvider().Meter("test"), tracer)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.GetCtx(context.Background(), "remote"); err != nil {
+		t.Fatalf("GetCtx: %v", err)
+	}
+	if err := w.SetCtx(context.Background(), "k", 1); err != nil {
+		t.Fatalf("SetCtx: %v", err)
+	}
+	parents := make(map[string]string)
+	ids := make(map[string]string)
+	for _, s := range spans.Ended() {
+		ids[s.SpanContext().SpanID().String()] = s.Name()
+	}
+	for _, s := range spans.Ended() {
+		parents[s.Name()] = ids[s.Parent().SpanID().String()]
+	}
+	if parents["metis.backend.Load"] != "metis.GetCtx" || parents["metis.backend.Store"] != "metis.SetCtx" {
+		t.Errorf("expected backend spans under GetCtx and SetCtx, got parents %v", parents)
+	}
+}
+
+func TestWrap_LoadOrComputeSpans(t *testing.T) {
+	w, _, spans := instrumented(t, metis.CacheConfig{EvictionPolicy: "wtinylfu"})
+	ctx := context.Background()
+	loads := 0
+	load := func(key string) (interface{}, error) {
+		loads++
+		return "computed " + key, nil
+	}
+
+	if v, err := w.LoadOrCompute(ctx, "k", load); err != nil || v != "computed k" {
+		t.Fatalf("LoadOrCompute: %v, %v", v, err)
+	}
+	if spanHit(t, spans, "metis.LoadOrCompute") {
+		t.Error("expected the first call to report cache.hit=false")
+	}
+	if _, err := w.LoadOrCompute(ctx, "k", load); err != nil {
+		t.Fatalf("LoadOrCompute: %v", err)
+	}
+	if !spanHit(t, spans, "metis.LoadOrCompute") {
+		t.Error("expected the second call to report cache.hit=true")
+	}
+	if loads != 1 {
+		t.Errorf("expected the loader to run once, ran %d times", loads)
+	}
+
+	failure := errors.New("database down")
+	if _, err := w.LoadOrCompute(ctx, "broken", func(string) (interface{}, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Fatalf("expected the loader error, got %v", err)
+	}
+	var loadSpans, failed int
+	for _, s := range spans.Ended() {
+		if s.Name() == "metis.load" {
+			loadSpans++
+			if s.Status().Code == codes.Error {
+				failed++
+			}
+			if !s.Parent().IsValid() {
+				t.Error("expected metis.load under metis.LoadOrCompute")
+			}
+			if !hasAttribute(s.Attributes(), attribute.String("metis.policy", "wtinylfu")) {
+				t.Errorf("expected metis.policy on the load span, got %v", s.Attributes())
+			}
+		}
+	}
+	if loadSpans != 2 || failed != 1 {
+		t.Errorf("expected 2 load spans, 1 failed, got %d and %d", loadSpans, failed)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
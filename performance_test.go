@@ -7,6 +7,7 @@
 package metis
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 	"time"
@@ -158,6 +159,67 @@ func BenchmarkConcurrent_Performance(b *testing.B) {
 	})
 }
 
+// BenchmarkParallelGet_Shards measures read-heavy scaling across cores; run with
+// -cpu 1,2,4,8 and compare ns/op, which should fall as cores are added
+func BenchmarkParallelGet_Shards(b *testing.B) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		b.Run(policy, func(b *testing.B) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      10000,
+				ShardCount:     64,
+				EvictionPolicy: policy,
+				TTL:            time.Hour,
+			})
+			defer cache.Close()
+
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("key_%d", i)
+				cache.Set(keys[i], i)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					_, _ = cache.Get(keys[i&1023])
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkParallelGet_OneShard measures 16 goroutines per core reading one hot shard,
+// where Gets share the shard's read lock and only queue their recency updates
+func BenchmarkParallelGet_OneShard(b *testing.B) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1024,
+		ShardCount:     1,
+		EvictionPolicy: "lru",
+		TTL:            time.Hour,
+	})
+	defer cache.Close()
+
+	keys := make([]string, 256)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+		cache.Set(keys[i], i)
+	}
+
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_, _ = cache.Get(keys[i&255])
+			i++
+		}
+	})
+}
+
 // TestPerformanceComparison compares different configurations
 func TestPerformanceComparison(t *testing.T) {
 	configs := []struct {
@@ -245,3 +307,84 @@ func TestPerformanceComparison(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkWTinyLFU_Direct measures W-TinyLFU without StrategicCache or key formatting overhead
+func BenchmarkWTinyLFU_Direct(b *testing.B) {
+	wt := NewWTinyLFU(10000, 32)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+		wt.Set(keys[i], i)
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				wt.Get(keys[i&1023])
+				i++
+			}
+		})
+	})
+
+	b.Run("Set", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				wt.Set(keys[i&1023], i)
+				i++
+			}
+		})
+	})
+}
+
+// BenchmarkBytes_VersusGeneric compares SetBytes/GetBytes with Set/Get for []byte values
+func BenchmarkBytes_VersusGeneric(b *testing.B) {
+	payload := bytes.Repeat([]byte("protobuf-payload"), 32)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	for _, compression := range []bool{false, true} {
+		cache := NewStrategicCache(CacheConfig{
+			EnableCaching:     true,
+			CacheSize:         10000,
+			ShardCount:        32,
+			EvictionPolicy:    "lru",
+			EnableCompression: compression,
+		})
+		suffix := "Plain"
+		if compression {
+			suffix = "Compressed"
+		}
+
+		b.Run("SetBytes/"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache.SetBytes(keys[i&1023], payload)
+			}
+		})
+		b.Run("Set/"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache.Set(keys[i&1023], payload)
+			}
+		})
+		b.Run("GetBytes/"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache.GetBytes(keys[i&1023])
+			}
+		})
+		b.Run("Get/"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if v, ok := cache.Get(keys[i&1023]); ok {
+					_ = v.([]byte)
+				}
+			}
+		})
+		cache.Close()
+	}
+}
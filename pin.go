@@ -0,0 +1,63 @@
+// pin.go: Pinning entries so they are never evicted
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+// Pin exempts a cached key from eviction and expiration until Unpin, Delete or Clear,
+// reporting whether the key was cached. Eviction passes over pinned entries to the next
+// candidate; a shard holding only pinned entries grows past its size limit instead.
+// Updating a pinned key with Set keeps it pinned.
+func (sc *StrategicCache) Pin(key string) bool {
+	return sc.setPinned(key, true)
+}
+
+// Unpin makes a pinned key subject to eviction and expiration again, reporting whether
+// the key was cached. Its TTL is unchanged, so an entry whose TTL elapsed while it was
+// pinned expires on its next read.
+func (sc *StrategicCache) Unpin(key string) bool {
+	return sc.setPinned(key, false)
+}
+
+// setPinned marks or unmarks a live entry as pinned
+func (sc *StrategicCache) setPinned(key string, pinned bool) bool {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return false
+	}
+	sc.closedMu.RUnlock()
+
+	if sc.wtinylfu != nil && (sc.config.EvictionPolicy == "wtinylfu" || sc.config.EvictionPolicy == "") {
+		return sc.wtinylfu.setPinned(key, pinned)
+	}
+	if sc.arc != nil {
+		return sc.arc.setPinned(key, pinned)
+	}
+
+	shard := sc.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	entry, exists := shard.data[key]
+	if !exists {
+		return false
+	}
+	if expired, idle := sc.expiry(entry, sc.clock.Now()); expired {
+		shard.removeEntry(key, entry)
+		shard.countExpiration(idle)
+		sc.entryPool.Put(entry)
+		return false
+	}
+	if entry.pinned != pinned {
+		entry.pinned = pinned
+		if pinned {
+			shard.pinned++
+		} else {
+			shard.pinned--
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+// pin_test.go: Tests for pinned entries
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"container/list"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestPin_SurvivesEviction(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       3,
+				ShardCount:      1,
+				TTL:             time.Minute,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+			})
+			defer cache.Close()
+			cache.Set("config", "v")
+			if !cache.Pin("config") {
+				t.Fatal("expected Pin to report a cached key")
+			}
+
+			// Heavy churn on a 3-entry cache: config is always the oldest entry
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("k%d", i)
+				cache.Set(key, i)
+				cache.Get(key)
+				cache.Get(key)
+			}
+			if v, ok := cache.Get("config"); !ok || v != "v" {
+				t.Fatalf("expected the pinned key to survive, got %v (found %v)", v, ok)
+			}
+			if n := cache.Len(); n > 3 {
+				t.Errorf("expected eviction to keep the cache at 3 entries, got %d", n)
+			}
+
+			stats := cache.GetStats()
+			if stats.Pinned != 1 || stats.Evictions == 0 {
+				t.Errorf("expected 1 pinned entry and some evictions, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestPin_UnpinAndDelete(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       100,
+				ShardCount:      1,
+				TTL:             time.Minute,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			if cache.Pin("missing") || cache.Unpin("missing") {
+				t.Error("expected Pin and Unpin to report a missing key")
+			}
+
+			cache.Set("a", 1)
+			cache.Set("b", 2)
+			cache.Pin("a")
+			cache.Pin("a") // Pinning twice counts once
+			cache.Pin("b")
+			if n := cache.GetStats().Pinned; n != 2 {
+				t.Fatalf("expected 2 pinned entries, got %d", n)
+			}
+
+			// Pinned entries outlive their TTL
+			clock.Advance(2 * time.Minute)
+			if _, ok := cache.Get("a"); !ok {
+				t.Fatal("expected the pinned entry to outlive its TTL")
+			}
+
+			cache.Delete("b")
+			if !cache.Unpin("a") {
+				t.Error("expected Unpin to report a cached key")
+			}
+			if n := cache.GetStats().Pinned; n != 0 {
+				t.Errorf("expected no pinned entries after Unpin and Delete, got %d", n)
+			}
+			if _, ok := cache.Get("a"); ok {
+				t.Error("expected the unpinned entry to expire at its elapsed TTL")
+			}
+		})
+	}
+}
+
+func TestPin_CustomPolicyFallsBack(t *testing.T) {
+	// A policy that always names the oldest-inserted key, which is pinned
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:        true,
+		CacheSize:            3,
+		ShardCount:           1,
+		TTL:                  time.Minute,
+		CustomEvictionPolicy: fixedVictimPolicy("pinned"),
+	})
+	defer cache.Close()
+
+	cache.Set("pinned", 0)
+	cache.Pin("pinned")
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if _, ok := cache.Get("pinned"); !ok {
+		t.Error("expected eviction to skip the pinned victim")
+	}
+	if n := cache.Len(); n != 3 {
+		t.Errorf("expected 3 entries, got %d", n)
+	}
+}
+
+// fixedVictimPolicy always picks the same key to evict
+type fixedVictimPolicy string
+
+func (p fixedVictimPolicy) EvictKey(map[string]*CacheEntry, *list.List) string { return string(p) }
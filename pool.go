@@ -8,6 +8,8 @@ package metis
 
 import (
 	"bytes"
+	"compress/gzip"
+	"io"
 	"sync"
 )
 
@@ -26,3 +28,45 @@ func putBuffer(buf *bytes.Buffer) {
 	buf.Reset()
 	bufferPool.Put(buf)
 }
+
+// gzipWriterPools provides pooled *gzip.Writer instances, one pool per compression level,
+// since a writer's level is fixed at creation. A gzip writer allocates about 1 MB.
+var gzipWriterPools [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+// getGzipWriter retrieves a *gzip.Writer at level writing to w
+func getGzipWriter(w io.Writer, level int) (*gzip.Writer, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return gzip.NewWriterLevel(w, level) // Reports the invalid level
+	}
+	if zw, ok := gzipWriterPools[level-gzip.HuffmanOnly].Get().(*gzip.Writer); ok {
+		zw.Reset(w)
+		return zw, nil
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// putGzipWriter returns a closed *gzip.Writer created at level to the pool
+func putGzipWriter(zw *gzip.Writer, level int) {
+	zw.Reset(io.Discard) // Drop the reference to the destination
+	gzipWriterPools[level-gzip.HuffmanOnly].Put(zw)
+}
+
+// gzipReaderPool provides pooled *gzip.Reader instances
+var gzipReaderPool sync.Pool
+
+// getGzipReader retrieves a *gzip.Reader reading from r
+func getGzipReader(r io.Reader) (*gzip.Reader, error) {
+	if zr, ok := gzipReaderPool.Get().(*gzip.Reader); ok {
+		if err := zr.Reset(r); err != nil {
+			gzipReaderPool.Put(zr)
+			return nil, err
+		}
+		return zr, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// putGzipReader returns a *gzip.Reader to the pool
+func putGzipReader(zr *gzip.Reader) {
+	gzipReaderPool.Put(zr)
+}
@@ -0,0 +1,118 @@
+// pool_test.go: Allocation tests and benchmarks for the pooled serialization path
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pooledRecord is a struct value for the gob-encoded path
+type pooledRecord struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Score float64
+}
+
+func init() {
+	gob.Register(pooledRecord{})
+}
+
+// pooledValues are compressible values large enough to be gzipped
+var pooledValues = map[string]interface{}{
+	"string": strings.Repeat("metis pooled serialization ", 40),
+	"struct": pooledRecord{ID: 7, Name: strings.Repeat("name ", 40), Tags: []string{"a", "b", "c"}, Score: 0.5},
+}
+
+func newCompressedCache() *StrategicCache {
+	return NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		EnableCompression: true,
+		CacheSize:         1000,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		TTL:               time.Hour,
+	})
+}
+
+// Allocation ceilings per compressed Set+Get round trip, with pooled gzip writers,
+// readers and buffers. Unpooled, the string round trip made 31 allocations and the
+// struct one 247; most of what the struct still makes is gob's per-stream type
+// information, which rules out pooling gob encoders and decoders.
+var maxRoundTripAllocs = map[string]float64{
+	"string": 10,
+	"struct": 230,
+}
+
+func TestPooledSerialization_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts need sync.Pool to keep items")
+	}
+	for name, value := range pooledValues {
+		t.Run(name, func(t *testing.T) {
+			cache := newCompressedCache()
+			defer cache.Close()
+
+			cache.Set("k", value) // Warm the pools
+			allocs := testing.AllocsPerRun(100, func() {
+				cache.Set("k", value)
+				if _, ok := cache.Get("k"); !ok {
+					t.Fatal("expected a hit")
+				}
+			})
+			if allocs > maxRoundTripAllocs[name] {
+				t.Errorf("expected at most %.0f allocations per round trip, got %.1f", maxRoundTripAllocs[name], allocs)
+			}
+		})
+	}
+}
+
+func TestPooledGzip_RoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("pooled gzip "), 100)
+	for _, level := range []int{-2, -1, 1, 9} {
+		compressed, err := compressGzipLevel(data, 0, level)
+		if err != nil {
+			t.Fatalf("level %d: %v", level, err)
+		}
+		for i := 0; i < 3; i++ {
+			out, err := decompressGzip(compressed)
+			if err != nil || !bytes.Equal(out, data) {
+				t.Fatalf("level %d: round trip failed (err %v)", level, err)
+			}
+		}
+	}
+	if _, err := compressGzipLevel(data, 0, 42); err == nil {
+		t.Error("expected an invalid level to be rejected")
+	}
+}
+
+func BenchmarkPooledSerialization(b *testing.B) {
+	for name, value := range pooledValues {
+		b.Run(name+"/Set", func(b *testing.B) {
+			cache := newCompressedCache()
+			defer cache.Close()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache.Set("k", value)
+			}
+		})
+		b.Run(name+"/Get", func(b *testing.B) {
+			cache := newCompressedCache()
+			defer cache.Close()
+			cache.Set("k", value)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get("k")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// presets.go: Ready-made configurations for common Metis workloads
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "time"
+
+// The presets below are the configurations offered by metis-cli, which generates its
+// metis.json from them. Fields the CLI leaves out of the file keep their getDefaultConfig
+// values, so loading a generated metis.json yields exactly the preset.
+
+// DevelopmentConfig returns a small cache with short-lived entries for development and testing
+func DevelopmentConfig() CacheConfig {
+	return CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       1000,
+		TTL:             10 * time.Minute,
+		EvictionPolicy:  "wtinylfu",
+		ShardCount:      128,
+		AdmissionPolicy: "always",
+	}
+}
+
+// WebAppConfig returns a balanced configuration for web applications
+func WebAppConfig() CacheConfig {
+	return CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       50000,
+		TTL:             30 * time.Minute,
+		EvictionPolicy:  "wtinylfu",
+		ShardCount:      32,
+		AdmissionPolicy: "always",
+	}
+}
+
+// HighPerformanceConfig returns a large, heavily sharded cache for high-throughput APIs.
+// TTL is left at zero, which NewStrategicCache replaces with its default.
+func HighPerformanceConfig() CacheConfig {
+	return CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       1000000,
+		TTL:             0,
+		EvictionPolicy:  "wtinylfu",
+		ShardCount:      128,
+		AdmissionPolicy: "always",
+	}
+}
+
+// LowMemoryConfig returns a compressed cache with a 512KB value limit for memory-constrained hosts
+func LowMemoryConfig() CacheConfig {
+	return CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         10000,
+		TTL:               1 * time.Hour,
+		EvictionPolicy:    "wtinylfu",
+		ShardCount:        128,
+		AdmissionPolicy:   "always",
+		EnableCompression: true,
+		MaxValueSize:      524288, // 512KB
+	}
+}
@@ -0,0 +1,61 @@
+// presets_test.go: Tests for the ready-made configurations
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "testing"
+
+// TestPresets_ValidAndUsable tests that every preset passes validation and serves Set/Get
+func TestPresets_ValidAndUsable(t *testing.T) {
+	presets := map[string]CacheConfig{
+		"development":      DevelopmentConfig(),
+		"web-application":  WebAppConfig(),
+		"high-performance": HighPerformanceConfig(),
+		"low-memory":       LowMemoryConfig(),
+	}
+
+	for name, config := range presets {
+		t.Run(name, func(t *testing.T) {
+			if err := config.Validate(); err != nil {
+				t.Fatalf("preset failed validation: %v", err)
+			}
+			if result := ValidateConfig(config); !result.IsValid {
+				t.Fatalf("preset rejected by ValidateConfig: %v", result.Warnings)
+			}
+
+			cache, err := NewStrategicCacheE(config)
+			if err != nil {
+				t.Fatalf("NewStrategicCacheE failed: %v", err)
+			}
+			defer cache.Close()
+
+			value := map[string]interface{}{"user": "alice", "visits": 3}
+			if !cache.Set("session", value) {
+				t.Fatal("Set failed")
+			}
+			got, ok := cache.Get("session")
+			if !ok {
+				t.Fatal("Get missed a value just stored")
+			}
+			if m, isMap := got.(map[string]interface{}); !isMap || m["user"] != "alice" {
+				t.Errorf("expected the stored map back, got %#v", got)
+			}
+		})
+	}
+}
+
+// TestPresets_Distinct tests the properties each preset is chosen for
+func TestPresets_Distinct(t *testing.T) {
+	if DevelopmentConfig().CacheSize >= WebAppConfig().CacheSize || WebAppConfig().CacheSize >= HighPerformanceConfig().CacheSize {
+		t.Error("expected preset sizes to grow from development to high-performance")
+	}
+	if !LowMemoryConfig().EnableCompression || LowMemoryConfig().MaxValueSize == 0 {
+		t.Error("expected the low-memory preset to compress and bound values")
+	}
+	if HighPerformanceConfig().EnableCompression {
+		t.Error("expected the high-performance preset to skip compression")
+	}
+}
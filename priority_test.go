@@ -0,0 +1,131 @@
+// priority_test.go: Tests for entry eviction priorities
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPriority_LowEvictedBeforeOlderHigh(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      3,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	cache.SetWithOptions("high", "v", WithPriority(PriorityHigh))
+	cache.SetWithOptions("low", "v", WithPriority(PriorityLow))
+	cache.Set("n1", "v")
+
+	// high is the least recently used entry, but low goes first
+	cache.Set("n2", "v")
+	if _, ok := cache.Get("low"); ok {
+		t.Error("expected the Low entry to be evicted first")
+	}
+	if _, ok := cache.Get("high"); !ok {
+		t.Fatal("expected the older High entry to survive")
+	}
+
+	// With no Low entries left, Normal entries go in LRU order before High
+	for i := 3; i < 10; i++ {
+		cache.Set(fmt.Sprintf("n%d", i), "v")
+	}
+	if _, ok := cache.Get("high"); !ok {
+		t.Error("expected the High entry to outlive Normal entries")
+	}
+	if _, ok := cache.Get("n9"); !ok {
+		t.Error("expected the newest Normal entry to be cached")
+	}
+}
+
+func TestPriority_DefaultKeepsLRUOrder(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      3,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	cache.SetWithOptions("a", "v", WithPriority(PriorityNormal))
+	cache.Set("b", "v")
+	cache.Set("c", "v")
+	cache.Get("a")
+	cache.Set("d", "v")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+}
+
+func TestPriority_UpdateChangesPriority(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      2,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	cache.SetWithOptions("a", "v", WithPriority(PriorityLow))
+	cache.SetWithOptions("a", "v2", WithPriority(PriorityHigh))
+	cache.Set("b", "v")
+	cache.Set("c", "v")
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("expected the update to raise the entry to High")
+	}
+}
+
+func TestPriority_WTinyLFUAdmission(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("n%d", i), i)
+	}
+
+	// Frequently read Low entries still lose every contest against Normal victims
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("low%d", i)
+		cache.SetWithOptions(key, i, WithPriority(PriorityLow))
+		for j := 0; j < 5; j++ {
+			cache.Get(key)
+		}
+	}
+
+	low := 0
+	for _, key := range cache.Keys() {
+		if key[0] == 'l' {
+			low++
+		}
+	}
+	if window := cache.wtinylfu.WindowSize(); low > window {
+		t.Errorf("expected Low entries only in the %d-entry window, got %d cached", window, low)
+	}
+}
+
+func TestWithPriority_Clamps(t *testing.T) {
+	var o setOptions
+	WithPriority(5)(&o)
+	if o.priority != PriorityHigh {
+		t.Errorf("expected 5 to clamp to PriorityHigh, got %d", o.priority)
+	}
+	WithPriority(-5)(&o)
+	if o.priority != PriorityLow {
+		t.Errorf("expected -5 to clamp to PriorityLow, got %d", o.priority)
+	}
+}
@@ -0,0 +1,110 @@
+// collector.go: Prometheus collector for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package prometheus exposes Metis cache statistics as Prometheus metrics.
+// It lives in its own module so the core metis package keeps zero
+// third-party dependencies.
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/agilira/metis"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements prometheus.Collector on top of a StrategicCache.
+// Statistics are read from the cache on every scrape, so no polling loop is needed.
+type Collector struct {
+	cache *metis.StrategicCache
+
+	keys        *prom.Desc
+	size        *prom.Desc
+	memory      *prom.Desc
+	memEvicted  *prom.Desc
+	hits        *prom.Desc
+	misses      *prom.Desc
+	evictions   *prom.Desc
+	expirations *prom.Desc
+	idleExpired *prom.Desc
+	shardKeys   *prom.Desc
+
+	compressedEntries *prom.Desc
+	skippedEntries    *prom.Desc
+	uncompressedBytes *prom.Desc
+	compressedBytes   *prom.Desc
+}
+
+// NewCollector creates a collector for the given cache.
+// All metrics are prefixed with namespace and labelled with the eviction policy in use.
+func NewCollector(c *metis.StrategicCache, namespace string) prom.Collector {
+	policy := prom.Labels{"policy": c.PolicyName()}
+	desc := func(name, help string, labels ...string) *prom.Desc {
+		return prom.NewDesc(prom.BuildFQName(namespace, "cache", name), help, labels, policy)
+	}
+
+	return &Collector{
+		cache:       c,
+		keys:        desc("keys", "Number of entries currently stored in the cache."),
+		size:        desc("size", "Cache size as reported by GetStats."),
+		memory:      desc("memory_bytes", "Estimated bytes held by cached values."),
+		memEvicted:  desc("memory_evicted_bytes_total", "Total bytes evicted to stay within MaxMemoryBytes."),
+		hits:        desc("hits_total", "Total number of cache hits."),
+		misses:      desc("misses_total", "Total number of cache misses."),
+		evictions:   desc("evictions_total", "Total number of entries evicted to make room for new ones."),
+		expirations: desc("expirations_total", "Total number of entries removed because their TTL elapsed."),
+		idleExpired: desc("idle_expirations_total", "Total number of entries removed because they went unread longer than MaxIdleTime."),
+		shardKeys:   desc("shard_keys", "Number of entries currently stored in each shard.", "shard"),
+
+		compressedEntries: desc("compressed_entries", "Number of stored entries compressed by the codec."),
+		skippedEntries:    desc("compression_skipped_entries", "Number of stored entries left uncompressed for being under CompressionMinSize."),
+		uncompressedBytes: desc("compression_uncompressed_bytes", "Encoded size of the compressed entries before compression."),
+		compressedBytes:   desc("compression_compressed_bytes", "Stored size of the compressed entries."),
+	}
+}
+
+// Describe sends the descriptors of all metrics exported by the collector
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.keys
+	ch <- c.size
+	ch <- c.memory
+	ch <- c.memEvicted
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.expirations
+	ch <- c.idleExpired
+	ch <- c.shardKeys
+	ch <- c.compressedEntries
+	ch <- c.skippedEntries
+	ch <- c.uncompressedBytes
+	ch <- c.compressedBytes
+}
+
+// Collect reads the current cache statistics and sends them as metrics
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	stats := c.cache.GetStats()
+
+	ch <- prom.MustNewConstMetric(c.keys, prom.GaugeValue, float64(stats.Keys))
+	ch <- prom.MustNewConstMetric(c.size, prom.GaugeValue, float64(stats.Size))
+	ch <- prom.MustNewConstMetric(c.memory, prom.GaugeValue, float64(stats.MemoryBytes))
+	ch <- prom.MustNewConstMetric(c.memEvicted, prom.CounterValue, float64(stats.MemoryEvictedBytes))
+	ch <- prom.MustNewConstMetric(c.hits, prom.CounterValue, float64(stats.Hits))
+	ch <- prom.MustNewConstMetric(c.misses, prom.CounterValue, float64(stats.Misses))
+	ch <- prom.MustNewConstMetric(c.evictions, prom.CounterValue, float64(stats.Evictions))
+	ch <- prom.MustNewConstMetric(c.expirations, prom.CounterValue, float64(stats.Expirations))
+	ch <- prom.MustNewConstMetric(c.idleExpired, prom.CounterValue, float64(stats.IdleExpirations))
+
+	compression := c.cache.CompressionStats()
+	ch <- prom.MustNewConstMetric(c.compressedEntries, prom.GaugeValue, float64(compression.CompressedEntries))
+	ch <- prom.MustNewConstMetric(c.skippedEntries, prom.GaugeValue, float64(compression.SkippedEntries))
+	ch <- prom.MustNewConstMetric(c.uncompressedBytes, prom.GaugeValue, float64(compression.UncompressedBytes))
+	ch <- prom.MustNewConstMetric(c.compressedBytes, prom.GaugeValue, float64(compression.CompressedBytes))
+
+	for _, shard := range c.cache.ShardStats() {
+		ch <- prom.MustNewConstMetric(c.shardKeys, prom.GaugeValue, float64(shard.Keys), strconv.Itoa(shard.Index))
+	}
+}
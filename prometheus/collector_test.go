@@ -0,0 +1,141 @@
+// collector_test.go: Tests for the Prometheus collector
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestCache(policy string, size int) *metis.StrategicCache {
+	return metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      size,
+		TTL:            time.Minute,
+		EvictionPolicy: policy,
+		ShardCount:     4,
+	})
+}
+
+func TestCollector_LRUPath(t *testing.T) {
+	cache := newTestCache("lru", 8)
+	defer cache.Close()
+
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i)
+	}
+	cache.Get("key19")
+	cache.Get("missing")
+
+	collector := NewCollector(cache, "app")
+	stats := cache.GetStats()
+
+	expected := fmt.Sprintf(`
+# HELP app_cache_hits_total Total number of cache hits.
+# TYPE app_cache_hits_total counter
+app_cache_hits_total{policy="lru"} %d
+# HELP app_cache_misses_total Total number of cache misses.
+# TYPE app_cache_misses_total counter
+app_cache_misses_total{policy="lru"} %d
+# HELP app_cache_evictions_total Total number of entries evicted to make room for new ones.
+# TYPE app_cache_evictions_total counter
+app_cache_evictions_total{policy="lru"} %d
+# HELP app_cache_keys Number of entries currently stored in the cache.
+# TYPE app_cache_keys gauge
+app_cache_keys{policy="lru"} %d
+# HELP app_cache_memory_bytes Estimated bytes held by cached values.
+# TYPE app_cache_memory_bytes gauge
+app_cache_memory_bytes{policy="lru"} %d
+`, stats.Hits, stats.Misses, stats.Evictions, stats.Keys, stats.MemoryBytes)
+
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"app_cache_hits_total", "app_cache_misses_total", "app_cache_evictions_total", "app_cache_keys", "app_cache_memory_bytes"); err != nil {
+		t.Error(err)
+	}
+	if stats.Evictions == 0 {
+		t.Error("expected evictions after overfilling the cache")
+	}
+}
+
+func TestCollector_WTinyLFUPath(t *testing.T) {
+	cache := newTestCache("wtinylfu", 1000)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("b")
+
+	collector := NewCollector(cache, "app")
+	expected := `
+# HELP app_cache_hits_total Total number of cache hits.
+# TYPE app_cache_hits_total counter
+app_cache_hits_total{policy="wtinylfu"} 1
+# HELP app_cache_misses_total Total number of cache misses.
+# TYPE app_cache_misses_total counter
+app_cache_misses_total{policy="wtinylfu"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"app_cache_hits_total", "app_cache_misses_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_ShardMetricsAndRegistration(t *testing.T) {
+	cache := newTestCache("lru", 100)
+	defer cache.Close()
+
+	registry := prom.NewRegistry()
+	if err := registry.Register(NewCollector(cache, "app")); err != nil {
+		t.Fatalf("failed to register collector: %v", err)
+	}
+
+	// Thirteen cache-wide series plus one shard_keys series per shard
+	if count := testutil.CollectAndCount(NewCollector(cache, "app")); count != 13+4 {
+		t.Errorf("expected 17 metric series, got %d", count)
+	}
+	if count := testutil.CollectAndCount(NewCollector(cache, "app"), "app_cache_shard_keys"); count != 4 {
+		t.Errorf("expected one shard_keys series per shard, got %d", count)
+	}
+}
+
+func TestCollector_CompressionMetrics(t *testing.T) {
+	cache := metis.NewStrategicCache(metis.CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		EvictionPolicy:    "lru",
+		ShardCount:        4,
+		EnableCompression: true,
+	})
+	defer cache.Close()
+
+	cache.Set("large", strings.Repeat("compressible ", 100))
+	cache.Set("small", "tiny")
+
+	stats := cache.CompressionStats()
+	expected := fmt.Sprintf(`
+# HELP app_cache_compressed_entries Number of stored entries compressed by the codec.
+# TYPE app_cache_compressed_entries gauge
+app_cache_compressed_entries{policy="lru"} 1
+# HELP app_cache_compression_skipped_entries Number of stored entries left uncompressed for being under CompressionMinSize.
+# TYPE app_cache_compression_skipped_entries gauge
+app_cache_compression_skipped_entries{policy="lru"} 1
+# HELP app_cache_compression_compressed_bytes Stored size of the compressed entries.
+# TYPE app_cache_compression_compressed_bytes gauge
+app_cache_compression_compressed_bytes{policy="lru"} %d
+`, stats.CompressedBytes)
+
+	if err := testutil.CollectAndCompare(NewCollector(cache, "app"), strings.NewReader(expected),
+		"app_cache_compressed_entries", "app_cache_compression_skipped_entries", "app_cache_compression_compressed_bytes"); err != nil {
+		t.Error(err)
+	}
+}
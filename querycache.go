@@ -0,0 +1,519 @@
+// querycache.go: database/sql query result cache for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryKeyPrefix starts the cache keys of query results
+const queryKeyPrefix = "metis:sql:"
+
+// queryResult is the form in which CachedRows are serialized
+type queryResult struct {
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+func init() {
+	// Driver values are int64, float64, bool, []byte, string or time.Time; gob knows all
+	// but the last
+	gob.Register(queryResult{})
+	gob.Register(time.Time{})
+}
+
+// QueryCache caches the results of database/sql queries in a StrategicCache. Results are
+// keyed by the normalized query and its arguments, and tagged with the tables the query
+// reads, so that InvalidateTable or ExecContext drops every result read from a table.
+type QueryCache struct {
+	db    *sql.DB
+	cache *StrategicCache
+}
+
+// NewQueryCache creates a QueryCache running queries on db and caching their results in c
+func NewQueryCache(db *sql.DB, c *StrategicCache) *QueryCache {
+	return &QueryCache{db: db, cache: c}
+}
+
+// QueryRowsCached returns the rows of query with args, from the cache if they are there
+// and from the database otherwise. Rows read from the database are fully materialized,
+// serialized with the cache's Serializer and stored for ttl (the cache TTL if not
+// positive), tagged with the tables named after FROM and JOIN as written, schema
+// included. Concurrent callers missing the same query share one database query, run with
+// the context of the first. Storing a result does not publish to an InvalidationBus.
+//
+// Values keep their driver types with GobSerializer; with JSONSerializer they come back
+// as generic JSON values, so numbers are float64 and byte slices base64 strings.
+func (q *QueryCache) QueryRowsCached(ctx context.Context, ttl time.Duration, query string, args ...interface{}) (*CachedRows, error) {
+	tokens := sqlTokens(query)
+	key, err := queryKey(tokens, args)
+	if err != nil {
+		return nil, err
+	}
+	if rows, ok := q.cached(key); ok {
+		return rows, nil
+	}
+
+	result, err := q.cache.loads.do(key, func() (interface{}, error) {
+		// Another caller may have stored the result since our lookup
+		if rows, ok := q.cached(key); ok {
+			return rows.result, nil
+		}
+		result, err := q.query(ctx, query, args)
+		if err != nil {
+			return nil, err
+		}
+		data, err := q.cache.serializer.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("serializing the rows of %q: %w", query, err)
+		}
+		o := defaultSetOptions
+		WithTTL(ttl)(&o)
+		for _, table := range sqlTables(tokens) {
+			WithTags(tableTag(table))(&o)
+		}
+		o.fill = true
+		q.cache.setBytes(key, data, o)
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Callers sharing the query each get their own cursor
+	return &CachedRows{result: result.(queryResult), row: -1}, nil
+}
+
+// cached decodes the result stored under key, if any
+func (q *QueryCache) cached(key string) (*CachedRows, bool) {
+	data, ok := q.cache.GetBytes(key)
+	if !ok {
+		return nil, false
+	}
+	var value interface{}
+	if err := q.cache.serializer.Unmarshal(data, &value); err != nil {
+		q.cache.logger.Warn("metis: cannot decode cached rows", "key", key, "error", err)
+		return nil, false
+	}
+	result, ok := value.(queryResult)
+	if !ok {
+		return nil, false
+	}
+	return &CachedRows{result: result, row: -1}, true
+}
+
+// query runs query on the database and reads every row
+func (q *QueryCache) query(ctx context.Context, query string, args []interface{}) (queryResult, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryResult{}, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return queryResult{}, err
+	}
+
+	result := queryResult{Columns: columns, Rows: [][]driver.Value{}}
+	for rows.Next() {
+		// Scanning into *interface{} keeps the driver's types, copying byte slices
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return queryResult{}, err
+		}
+		row := make([]driver.Value, len(values))
+		for i, v := range values {
+			row[i] = v
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// ExecContext runs a statement on the database, then invalidates the cached results of
+// every table it names after INSERT INTO, UPDATE, DELETE FROM, TRUNCATE, FROM or JOIN.
+// Results are invalidated even if the statement fails, as it may have partly applied.
+func (q *QueryCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := q.db.ExecContext(ctx, query, args...)
+	for _, table := range sqlTables(sqlTokens(query)) {
+		q.InvalidateTable(table)
+	}
+	return result, err
+}
+
+// InvalidateTable removes the cached results of every query reading table, compared
+// without regard to case or quoting, and returns how many were cached
+func (q *QueryCache) InvalidateTable(table string) int {
+	return q.cache.InvalidateTag(tableTag(strings.ToLower(unquoteIdent(table))))
+}
+
+// tableTag is the tag of the results read from table
+func tableTag(table string) string {
+	return "sql:table:" + table
+}
+
+// CachedRows is the materialized result of a query, read like sql.Rows
+type CachedRows struct {
+	result queryResult
+	row    int
+}
+
+// Columns returns the column names
+func (r *CachedRows) Columns() []string {
+	return r.result.Columns
+}
+
+// Len returns the number of rows
+func (r *CachedRows) Len() int {
+	return len(r.result.Rows)
+}
+
+// Next advances to the next row, reporting false when there is none
+func (r *CachedRows) Next() bool {
+	if r.row < len(r.result.Rows) {
+		r.row++
+	}
+	return r.row < len(r.result.Rows)
+}
+
+// Values returns the values of the current row. They may be shared with other callers
+// and must not be modified.
+func (r *CachedRows) Values() []driver.Value {
+	if r.row < 0 || r.row >= len(r.result.Rows) {
+		return nil
+	}
+	return r.result.Rows[r.row]
+}
+
+// Scan copies the columns of the current row into dest, as sql.Rows.Scan does for the
+// common destinations: *interface{}, *string, *[]byte, *int, *int64, *float64, *bool,
+// *time.Time and sql.Scanner implementations such as sql.NullString
+func (r *CachedRows) Scan(dest ...interface{}) error {
+	values := r.Values()
+	if values == nil {
+		return errors.New("metis: Scan called without calling Next")
+	}
+	if len(dest) != len(values) {
+		return fmt.Errorf("metis: expected %d destination arguments in Scan, not %d", len(values), len(dest))
+	}
+	for i, value := range values {
+		if err := scanValue(dest[i], value); err != nil {
+			return fmt.Errorf("metis: converting column %q: %w", r.result.Columns[i], err)
+		}
+	}
+	return nil
+}
+
+// scanValue stores a driver value in dest
+func scanValue(dest interface{}, value driver.Value) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if d, ok := dest.(*interface{}); ok {
+		if b, isBytes := value.([]byte); isBytes {
+			value = append([]byte(nil), b...)
+		}
+		*d = value
+		return nil
+	}
+	if value == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dest)
+	}
+
+	switch d := dest.(type) {
+	case *string:
+		switch v := value.(type) {
+		case string:
+			*d = v
+		case []byte:
+			*d = string(v)
+		case time.Time:
+			*d = v.Format(time.RFC3339Nano)
+		default:
+			*d = fmt.Sprint(v)
+		}
+		return nil
+	case *[]byte:
+		switch v := value.(type) {
+		case []byte:
+			*d = append([]byte(nil), v...)
+		case string:
+			*d = []byte(v)
+		default:
+			*d = []byte(fmt.Sprint(v))
+		}
+		return nil
+	case *time.Time:
+		if v, ok := value.(time.Time); ok {
+			*d = v
+			return nil
+		}
+	case *int, *int64:
+		n, err := asInt64(value)
+		if err != nil {
+			return err
+		}
+		if p, ok := d.(*int); ok {
+			*p = int(n)
+		} else {
+			*d.(*int64) = n
+		}
+		return nil
+	case *float64:
+		switch v := value.(type) {
+		case float64:
+			*d = v
+			return nil
+		case int64:
+			*d = float64(v)
+			return nil
+		case []byte, string:
+			f, err := strconv.ParseFloat(asString(v), 64)
+			if err != nil {
+				return err
+			}
+			*d = f
+			return nil
+		}
+	case *bool:
+		switch v := value.(type) {
+		case bool:
+			*d = v
+			return nil
+		case int64:
+			*d = v != 0
+			return nil
+		case []byte, string:
+			b, err := strconv.ParseBool(asString(v))
+			if err != nil {
+				return err
+			}
+			*d = b
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported Scan, storing %T into %T", value, dest)
+}
+
+// asInt64 converts an integer driver value, or its text, to int64
+func asInt64(value driver.Value) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	case []byte, string:
+		return strconv.ParseInt(asString(v), 10, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T %v to an integer", value, value)
+}
+
+// asString returns the text of a string or []byte driver value
+func asString(value driver.Value) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value.(string)
+}
+
+// queryKey hashes the normalized query and its arguments into a cache key
+func queryKey(tokens []string, args []interface{}) (string, error) {
+	h := sha256.New()
+	var n [8]byte
+	field := func(kind string, data []byte) {
+		binary.LittleEndian.PutUint64(n[:], uint64(len(data)))
+		h.Write([]byte(kind))
+		h.Write(n[:])
+		h.Write(data)
+	}
+	field("q", []byte(strings.Join(tokens, " ")))
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			field("n", []byte(named.Name))
+			arg = named.Value
+		}
+		if valuer, ok := arg.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return "", err
+			}
+			arg = v
+		}
+		switch v := arg.(type) {
+		case nil:
+			field("0", nil)
+		case []byte:
+			field("b", v)
+		case time.Time:
+			field("t", []byte(v.UTC().Format(time.RFC3339Nano)))
+		default:
+			field(fmt.Sprintf("%T", v), []byte(fmt.Sprint(v)))
+		}
+	}
+	return queryKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sqlTokens splits a statement into words, quoted names, string literals and symbols,
+// dropping comments and whitespace. Unquoted words are lowercased, as SQL compares them
+// without regard to case, so statements differing only in layout, comments or keyword
+// case have the same tokens.
+func sqlTokens(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ';':
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for j < len(query) {
+				if query[j] == closing {
+					// A doubled quote is an escaped one
+					if j+1 < len(query) && query[j+1] == closing && closing != ']' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			j = min(j+1, len(query))
+			tokens = append(tokens, query[i:j])
+			i = j
+		case isSQLWordByte(c):
+			j := i + 1
+			for j < len(query) && isSQLWordByte(query[j]) {
+				j++
+			}
+			tokens = append(tokens, strings.ToLower(query[i:j]))
+			i = j
+		default:
+			tokens = append(tokens, query[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// isSQLWordByte reports whether c can be part of a name, number or placeholder
+func isSQLWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '$' || c == '@' || c == ':' || c == '?' || c >= 0x80
+}
+
+// sqlTableKeywords precede table names
+var sqlTableKeywords = map[string]bool{"from": true, "join": true, "into": true, "update": true, "truncate": true}
+
+// sqlClauseKeywords end a list of tables; any other word after a table is its alias
+var sqlClauseKeywords = map[string]bool{
+	"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true,
+	"outer": true, "cross": true, "natural": true, "on": true, "using": true, "group": true,
+	"order": true, "having": true, "limit": true, "offset": true, "union": true,
+	"intersect": true, "except": true, "window": true, "returning": true, "set": true,
+	"values": true, "select": true, "for": true, "fetch": true, "default": true,
+}
+
+// sqlTables returns the tables a statement names after FROM, JOIN, INTO, UPDATE and
+// TRUNCATE, lowercased and unquoted. It reads names, not SQL: a function called after
+// FROM is skipped, and a column of EXTRACT(... FROM column) is taken for a table, which
+// only tags a result more than needed.
+func sqlTables(tokens []string) []string {
+	var tables []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(tokens); i++ {
+		keyword := tokens[i]
+		if !sqlTableKeywords[keyword] {
+			continue
+		}
+		if tokens[i] == "truncate" && i+1 < len(tokens) && tokens[i+1] == "table" {
+			i++
+		}
+		// A list of tables, each with an optional alias: FROM a, b AS x, c y
+		for i+1 < len(tokens) {
+			name := tokens[i+1]
+			if name == "(" || sqlClauseKeywords[name] || !isSQLName(name) {
+				break
+			}
+			i++
+			if keyword != "into" && i+1 < len(tokens) && tokens[i+1] == "(" {
+				break // A function, not a table
+			}
+			table := strings.ToLower(unquoteIdent(name))
+			if !seen[table] {
+				seen[table] = true
+				tables = append(tables, table)
+			}
+			if i+1 < len(tokens) && tokens[i+1] == "as" {
+				i++
+			}
+			if i+1 < len(tokens) && isSQLName(tokens[i+1]) && !sqlClauseKeywords[tokens[i+1]] {
+				i++ // Alias
+			}
+			if i+1 >= len(tokens) || tokens[i+1] != "," {
+				break
+			}
+			i++
+		}
+	}
+	return tables
+}
+
+// isSQLName reports whether a token is a name: a word not starting with a digit or a
+// placeholder sign, or a quoted identifier
+func isSQLName(token string) bool {
+	switch c := token[0]; {
+	case c == '"' || c == '`' || c == '[':
+		return true
+	case c >= '0' && c <= '9', c == '$', c == '@', c == ':', c == '?', c == '.':
+		return false
+	default:
+		return isSQLWordByte(c)
+	}
+}
+
+// unquoteIdent removes the quotes around an identifier
+func unquoteIdent(name string) string {
+	if len(name) >= 2 {
+		switch first, last := name[0], name[len(name)-1]; {
+		case first == '"' && last == '"', first == '`' && last == '`', first == '[' && last == ']':
+			return name[1 : len(name)-1]
+		}
+	}
+	return name
+}
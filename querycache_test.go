@@ -0,0 +1,257 @@
+// querycache_test.go: Tests for the database/sql query result cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDB is a database/sql driver answering every query with the rows of fakeDB.rows
+// and counting the queries and statements it ran
+type fakeDB struct {
+	mu      sync.Mutex
+	columns []string
+	rows    [][]driver.Value
+	queries atomic.Int64
+	execs   atomic.Int64
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries.Add(1)
+	if query == "fail" {
+		return nil, errors.New("query failed")
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+func (c fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	c.db.execs.Add(1)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fakeDrivers atomic.Int64
+
+// newFakeDB opens a database on a fresh fakeDB
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{
+		columns: []string{"id", "name", "score", "active", "created", "note"},
+		rows: [][]driver.Value{
+			{int64(1), "ada", 9.5, true, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), []byte("first")},
+			{int64(2), "bob", 7.25, false, time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC), nil},
+		},
+	}
+	name := "metis-fake-" + time.Now().Format("150405.000000000") + "-" + string(rune('a'+fakeDrivers.Add(1)%26))
+	sql.Register(name, fake)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func TestQueryCache_ServesHits(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy})
+			defer cache.Close()
+			qc := NewQueryCache(db, cache)
+			ctx := context.Background()
+
+			var first [][]driver.Value
+			for i := 0; i < 3; i++ {
+				rows, err := qc.QueryRowsCached(ctx, time.Minute, "SELECT * FROM users WHERE id > ?", 0)
+				if err != nil {
+					t.Fatalf("QueryRowsCached: %v", err)
+				}
+				if rows.Len() != 2 || !reflect.DeepEqual(rows.Columns(), fake.columns) {
+					t.Fatalf("expected 2 rows of %v, got %d of %v", fake.columns, rows.Len(), rows.Columns())
+				}
+				var got [][]driver.Value
+				for rows.Next() {
+					got = append(got, rows.Values())
+				}
+				if i == 0 {
+					first = got
+				} else if !reflect.DeepEqual(got, first) {
+					t.Errorf("expected a hit to return the same rows, got %v and %v", got, first)
+				}
+			}
+			if !reflect.DeepEqual(first, fake.rows) {
+				t.Errorf("expected the rows with their driver types, got %#v", first)
+			}
+			if n := fake.queries.Load(); n != 1 {
+				t.Errorf("expected one database query, got %d", n)
+			}
+
+			// Layout and keyword case do not matter; arguments do
+			if _, err := qc.QueryRowsCached(ctx, time.Minute, "select *\n  from USERS -- all of them\n where id > ?;", 0); err != nil {
+				t.Fatal(err)
+			}
+			if n := fake.queries.Load(); n != 1 {
+				t.Errorf("expected a reformatted query to hit, got %d queries", n)
+			}
+			if _, err := qc.QueryRowsCached(ctx, time.Minute, "SELECT * FROM users WHERE id > ?", 1); err != nil {
+				t.Fatal(err)
+			}
+			if n := fake.queries.Load(); n != 2 {
+				t.Errorf("expected other arguments to miss, got %d queries", n)
+			}
+		})
+	}
+}
+
+func TestQueryCache_InvalidateTable(t *testing.T) {
+	db, fake := newFakeDB(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+	qc := NewQueryCache(db, cache)
+	ctx := context.Background()
+
+	queries := []string{
+		"SELECT u.name FROM users u JOIN orders AS o ON o.user_id = u.id",
+		`SELECT * FROM "Orders"`,
+		"SELECT * FROM products, stock s WHERE s.id = products.id",
+	}
+	for _, query := range queries {
+		if _, err := qc.QueryRowsCached(ctx, 0, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := qc.InvalidateTable("ORDERS"); n != 2 {
+		t.Errorf("expected both results reading orders to be invalidated, got %d", n)
+	}
+	if n := qc.InvalidateTable("users"); n != 0 {
+		t.Errorf("expected the join to be invalidated already, got %d", n)
+	}
+	if _, err := qc.ExecContext(ctx, "UPDATE stock SET qty = qty - 1 WHERE id = ?", 7); err != nil {
+		t.Fatal(err)
+	}
+	if fake.execs.Load() != 1 || cache.Len() != 0 {
+		t.Errorf("expected the update to run and invalidate the last result, %d left", cache.Len())
+	}
+	for _, query := range queries {
+		_, _ = qc.QueryRowsCached(ctx, 0, query)
+	}
+	if n := fake.queries.Load(); n != 6 {
+		t.Errorf("expected every invalidated query to run again, got %d queries", n)
+	}
+}
+
+func TestQueryCache_Errors(t *testing.T) {
+	db, fake := newFakeDB(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+	qc := NewQueryCache(db, cache)
+
+	for i := 0; i < 2; i++ {
+		if _, err := qc.QueryRowsCached(context.Background(), 0, "fail"); err == nil {
+			t.Fatal("expected the database error")
+		}
+	}
+	if n := fake.queries.Load(); n != 2 || cache.Len() != 0 {
+		t.Errorf("expected failures not to be cached, got %d queries and %d entries", n, cache.Len())
+	}
+}
+
+func TestCachedRows_Scan(t *testing.T) {
+	db, _ := newFakeDB(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+	rows, err := NewQueryCache(db, cache).QueryRowsCached(context.Background(), 0, "SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Scan(new(int)); err == nil {
+		t.Error("expected Scan before Next to fail")
+	}
+
+	var (
+		id      int
+		name    string
+		score   float64
+		active  bool
+		created time.Time
+		note    sql.NullString
+	)
+	rows.Next()
+	if err := rows.Scan(&id, &name, &score, &active, &created, &note); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || name != "ada" || score != 9.5 || !active || created.Year() != 2025 || note.String != "first" {
+		t.Errorf("unexpected first row: %v %v %v %v %v %v", id, name, score, active, created, note)
+	}
+	rows.Next()
+	if err := rows.Scan(&id, &name, &score, &active, &created, &note); err != nil || note.Valid {
+		t.Errorf("expected NULL to scan into an invalid NullString, got %v, %v", note, err)
+	}
+	var s string
+	if err := rows.Scan(&id, &name, &score, &active, &created, &s); err == nil {
+		t.Error("expected NULL not to scan into a string")
+	}
+	if err := rows.Scan(&id); err == nil {
+		t.Error("expected a wrong number of destinations to fail")
+	}
+	if rows.Next() {
+		t.Error("expected two rows")
+	}
+}
+
+func TestSQLTables(t *testing.T) {
+	for query, want := range map[string][]string{
+		"SELECT * FROM a": {"a"},
+		"SELECT * FROM public.a AS x LEFT JOIN b y ON x.id = y.id": {"public.a", "b"},
+		"SELECT * FROM (SELECT id FROM inner_t) t":                 {"inner_t"},
+		"SELECT * FROM generate_series(1, 10)":                     nil,
+		"INSERT INTO `log` (msg) VALUES ('from here')":             {"log"},
+		"DELETE FROM sessions WHERE expires < $1":                  {"sessions"},
+		"TRUNCATE TABLE a, b":                                      {"a", "b"},
+		"SELECT 'FROM nowhere' FROM [t]":                           {"t"},
+	} {
+		if got := sqlTables(sqlTokens(query)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %q, got %q", query, want, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// range_test.go: Tests for Keys and Range enumeration across eviction policies
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestKeys_DefaultPolicy(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10000})
+	defer cache.Close()
+	if cache.wtinylfu == nil {
+		t.Fatal("expected the default policy to use W-TinyLFU")
+	}
+
+	cache.Set("user:1", "alice")
+	keys := cache.Keys()
+	if len(keys) != 1 || keys[0] != "user:1" {
+		t.Errorf("expected Keys() to return [user:1], got %v", keys)
+	}
+}
+
+func TestRange_AllPolicies(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:     true,
+				CacheSize:         1000,
+				ShardCount:        4,
+				EvictionPolicy:    policy,
+				EnableCompression: policy == "lru",
+			})
+			defer cache.Close()
+
+			want := make(map[string]interface{})
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("k%d", i)
+				want[key] = fmt.Sprintf("value-%d", i)
+				cache.Set(key, want[key])
+			}
+
+			got := make(map[string]interface{})
+			cache.Range(func(key string, value interface{}) bool {
+				got[key] = value
+				return true
+			})
+			if len(got) != len(want) {
+				t.Fatalf("expected Range to visit %d entries, visited %d", len(want), len(got))
+			}
+			for key, value := range want {
+				if got[key] != value {
+					t.Errorf("expected %s=%v, got %v", key, value, got[key])
+				}
+			}
+
+			if keys := cache.Keys(); len(keys) != len(want) {
+				t.Errorf("expected %d keys, got %d", len(want), len(keys))
+			}
+		})
+	}
+}
+
+func TestRange_StopsEarly(t *testing.T) {
+	wt := NewWTinyLFU(1000, 4)
+	for i := 0; i < 100; i++ {
+		wt.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	visited := 0
+	wt.Range(func(key string, value interface{}) bool {
+		visited++
+		return visited < 10
+	})
+	if visited != 10 {
+		t.Errorf("expected Range to stop after 10 entries, visited %d", visited)
+	}
+}
+
+func TestRange_CallbackMayModifyCache(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10000, ShardCount: 2})
+	defer cache.Close()
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cache.Range(func(key string, value interface{}) bool {
+			cache.Delete(key)
+			return true
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Range deadlocked when the callback modified the cache")
+	}
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("expected the callback to delete every key, %d remain", len(keys))
+	}
+}
+
+func TestKeys_SkipsExpiredEntries(t *testing.T) {
+	wt, clock := newFakeClockWTinyLFU(100, 1, 20*time.Millisecond)
+	wt.Set("old", 1)
+	clock.Advance(40 * time.Millisecond)
+	wt.Set("new", 2)
+
+	keys := wt.Keys()
+	if len(keys) != 1 || keys[0] != "new" {
+		t.Errorf("expected only the unexpired key, got %v", keys)
+	}
+}
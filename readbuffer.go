@@ -0,0 +1,68 @@
+// readbuffer.go: Batched recency updates for the sharded read path of Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"sync"
+	"time"
+)
+
+// readBufferSize is how many hits a shard queues before a reader applies them
+const readBufferSize = 64
+
+// readEvent is a hit recorded under shard.mu.RLock
+type readEvent struct {
+	key   string
+	entry *CacheEntry
+	at    time.Time
+}
+
+// readBuffer queues the hits of a shard, so that Gets only need shard.mu.RLock and
+// concurrent readers of a hot shard do not serialize on its write lock. The list
+// order, AccessCount and LastAccess the hits imply are applied by drainReads, which
+// runs under shard.mu before anything that depends on them: every write, every
+// eviction and GetEntryInfo. Hits are applied late but never lost, so the LRU order
+// an eviction sees is the order of the reads.
+type readBuffer struct {
+	mu     sync.Mutex // Serializes readers adding events under shard.mu.RLock
+	events []readEvent
+}
+
+// add queues a hit and reports whether the buffer is full and should be drained.
+// The caller must hold shard.mu.RLock.
+func (b *readBuffer) add(key string, entry *CacheEntry, at time.Time) bool {
+	b.mu.Lock()
+	b.events = append(b.events, readEvent{key: key, entry: entry, at: at})
+	full := len(b.events) >= readBufferSize
+	b.mu.Unlock()
+	return full
+}
+
+// drainReads applies the queued hits in the order they happened, skipping entries
+// removed or replaced since. The caller must hold shard.mu, which keeps readers out,
+// so the buffer needs no lock of its own here.
+func (shard *cacheShard) drainReads() {
+	events := shard.reads.events
+	if len(events) == 0 {
+		return
+	}
+	for _, ev := range events {
+		entry := ev.entry
+		if shard.data[ev.key] != entry {
+			continue
+		}
+		entry.AccessCount++
+		if ev.at.After(entry.LastAccess) {
+			entry.LastAccess = ev.at
+		}
+		if entry.llElem != nil {
+			shard.ll.MoveToFront(entry.llElem)
+		}
+	}
+	clear(events)
+	shard.reads.events = events[:0]
+}
@@ -0,0 +1,100 @@
+// readbuffer_test.go: Tests for the batched recency updates of the sharded read path
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadBuffer_EvictionSeesReads(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 4, ShardCount: 1, EvictionPolicy: "lru", TTL: time.Hour})
+	defer cache.Close()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, key)
+	}
+	// Reads are queued, not applied, until the next write
+	cache.Get("a")
+	cache.Get("b")
+	if n := len(cache.shards[0].reads.events); n != 2 {
+		t.Fatalf("expected 2 queued reads, got %d", n)
+	}
+
+	cache.Set("e", "e")
+	cache.Set("f", "f")
+	for key, want := range map[string]bool{"a": true, "b": true, "c": false, "d": false, "e": true, "f": true} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("expected %s present=%v", key, want)
+		}
+	}
+}
+
+func TestReadBuffer_DrainsWhenFull(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru", TTL: time.Hour})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	for i := 0; i < readBufferSize+5; i++ {
+		cache.Get("k")
+	}
+	if n := len(cache.shards[0].reads.events); n != 5 {
+		t.Errorf("expected the buffer drained at %d reads, %d still queued", readBufferSize, n)
+	}
+	info, _ := cache.GetEntryInfo("k")
+	if info.AccessCount != int64(readBufferSize+6) {
+		t.Errorf("expected every read counted, got %d", info.AccessCount)
+	}
+}
+
+func TestReadBuffer_SkipsReplacedEntries(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, ShardCount: 1, EvictionPolicy: "lru", TTL: time.Hour})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	cache.Get("k")
+	cache.Delete("k")
+	cache.Set("k", 2)
+	if info, _ := cache.GetEntryInfo("k"); info.AccessCount != 1 {
+		t.Errorf("expected the read of the deleted entry dropped, got %d accesses", info.AccessCount)
+	}
+}
+
+func TestReadBuffer_ConcurrentReadsAndWrites(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 64, ShardCount: 1, EvictionPolicy: "lru", TTL: time.Hour})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := fmt.Sprintf("key_%d", i%100)
+				if i%4 == g%4 {
+					cache.Set(key, i)
+				} else {
+					cache.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := cache.Len(); n > 64 {
+		t.Errorf("expected at most 64 entries, got %d", n)
+	}
+	shard := &cache.shards[0]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.drainReads()
+	if shard.ll.Len() != len(shard.data) {
+		t.Errorf("expected the list and map to agree, got %d and %d", shard.ll.Len(), len(shard.data))
+	}
+}
@@ -0,0 +1,39 @@
+// readonly.go: Read-only mode for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "errors"
+
+// ErrReadOnly is returned by writes while the cache is read-only (see SetReadOnly)
+var ErrReadOnly = errors.New("metis: cache is read-only")
+
+// SetReadOnly turns read-only mode on or off. While it is on, every write is rejected
+// with ErrReadOnly by the error API and false by the bool API, before the Backend is
+// called: Set and its variants, SetBytes, SetNegative, Warm, LoadFromFile, and the
+// values loaded by GetE and LoadOrCompute, which are still returned but not cached.
+// Reads, Delete, Clear, InvalidateTag and Invalidate keep working, and existing entries
+// still expire and are evicted, so memory use can only shrink. Rejected writes are
+// counted in CacheStats.ReadOnlyRejected.
+func (sc *StrategicCache) SetReadOnly(ro bool) {
+	if sc.readOnly.Swap(ro) != ro {
+		sc.logger.Info("metis: read-only mode changed", "read_only", ro)
+	}
+}
+
+// IsReadOnly reports whether the cache is in read-only mode (see SetReadOnly)
+func (sc *StrategicCache) IsReadOnly() bool {
+	return sc.readOnly.Load()
+}
+
+// checkWritable returns ErrReadOnly, counting the rejection, while the cache is read-only
+func (sc *StrategicCache) checkWritable() error {
+	if sc.readOnly.Load() {
+		sc.readOnlyRejected.Add(1)
+		return ErrReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// readonly_test.go: Tests for read-only mode
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSetReadOnly(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			cache.Set("kept", 1)
+			cache.Set("deleted", 2)
+			cache.SetReadOnly(true)
+			if !cache.IsReadOnly() {
+				t.Fatal("expected the cache to be read-only")
+			}
+
+			if cache.Set("new", 3) {
+				t.Error("expected Set to fail")
+			}
+			if err := cache.SetE("kept", 4); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("expected ErrReadOnly, got %v", err)
+			}
+			if cache.SetBytes("bytes", []byte("x")) {
+				t.Error("expected SetBytes to fail")
+			}
+			if err := cache.SetNegative("absent", 0); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("expected ErrReadOnly from SetNegative, got %v", err)
+			}
+			if n, err := cache.Warm([]WarmEntry{{Key: "warm", Value: 5}}); n != 0 || !errors.Is(err, ErrReadOnly) {
+				t.Errorf("expected Warm to fail with ErrReadOnly, got %d, %v", n, err)
+			}
+			v, err := cache.LoadOrCompute("computed", func(string) (interface{}, error) { return 6, nil })
+			if err != nil || v != 6 {
+				t.Errorf("expected the computed value returned, got %v (err %v)", v, err)
+			}
+
+			// Reads and deletes keep working
+			if v, ok := cache.Get("kept"); !ok || v != 1 {
+				t.Errorf("expected the old value served, got %v, %v", v, ok)
+			}
+			if !cache.Delete("deleted") {
+				t.Error("expected Delete to work")
+			}
+			for _, key := range []string{"new", "bytes", "warm", "computed"} {
+				if _, ok := cache.Get(key); ok {
+					t.Errorf("expected %s not to be stored", key)
+				}
+			}
+
+			stats := cache.GetStats()
+			if !stats.ReadOnly || stats.ReadOnlyRejected != 6 {
+				t.Errorf("expected 6 rejected writes while read-only, got %+v", stats)
+			}
+
+			// Existing entries still expire
+			clock.Advance(2 * time.Minute)
+			if _, ok := cache.Get("kept"); ok {
+				t.Error("expected entries to keep expiring")
+			}
+
+			cache.SetReadOnly(false)
+			if !cache.Set("new", 3) {
+				t.Error("expected writes to work again")
+			}
+			if stats := cache.GetStats(); stats.ReadOnly || stats.ReadOnlyRejected != 6 {
+				t.Errorf("expected the count kept after leaving read-only mode, got %+v", stats)
+			}
+			cache.ResetStats()
+			if stats := cache.GetStats(); stats.ReadOnlyRejected != 0 {
+				t.Errorf("expected ResetStats to clear ReadOnlyRejected, got %d", stats.ReadOnlyRejected)
+			}
+		})
+	}
+}
+
+func TestSetReadOnly_BackendAndSnapshots(t *testing.T) {
+	backend := newMapBackend()
+	backend.data["remote"] = "r"
+	cache := newBackendCache("lru", backend)
+	defer cache.Close()
+	cache.Set("local", "l")
+
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	if err := cache.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetReadOnly(true)
+	if err := cache.SetE("k", "v"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if backend.stores != 1 {
+		t.Errorf("expected no backend write while read-only, got %d stores", backend.stores)
+	}
+	if v, err := cache.GetE("remote"); err != nil || v != "r" {
+		t.Errorf("expected read-through to still answer, got %v (err %v)", v, err)
+	}
+	if _, err := cache.GetE("remote"); backend.loads != 2 || err != nil {
+		t.Errorf("expected the loaded value not to be cached, got %d loads (err %v)", backend.loads, err)
+	}
+
+	cache.Clear()
+	if n, err := cache.LoadFromFile(path); n != 0 || !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected LoadFromFile to fail with ErrReadOnly, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,216 @@
+// redis_bus.go: Redis Pub/Sub InvalidationBus for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build redis
+
+package metis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// redisDialTimeout bounds connecting to Redis and each PUBLISH round trip
+const redisDialTimeout = 5 * time.Second
+
+// errRedisBusClosed is returned by Subscribe after Close
+var errRedisBusClosed = errors.New("redis bus is closed")
+
+// RedisBus is an InvalidationBus over a Redis Pub/Sub channel, spoken over plain TCP
+// without a client library. Build with -tags redis to include it. Each Subscribe opens
+// its own connection and reconnects when it drops; invalidations published while it is
+// disconnected are lost, as with any Pub/Sub subscriber.
+type RedisBus struct {
+	addr    string
+	channel string
+
+	mu   sync.Mutex // Serializes PUBLISH on conn
+	conn net.Conn
+	r    *bufio.Reader
+
+	subMu  sync.Mutex
+	subs   []net.Conn
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRedisBus connects to the Redis server at addr and publishes on channel
+func NewRedisBus(addr, channel string) (*RedisBus, error) {
+	b := &RedisBus{addr: addr, channel: channel, done: make(chan struct{})}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// connect (re)opens the publishing connection; b.mu must be held or b unshared
+func (b *RedisBus) connect() error {
+	conn, err := net.DialTimeout("tcp", b.addr, redisDialTimeout)
+	if err != nil {
+		return fmt.Errorf("connecting to redis at %s: %w", b.addr, err)
+	}
+	b.conn, b.r = conn, bufio.NewReader(conn)
+	return nil
+}
+
+// Publish sends key on the channel, reconnecting once if the connection was lost
+func (b *RedisBus) Publish(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.publish(key)
+	if err == nil || b.isClosed() {
+		return err
+	}
+	if b.conn != nil {
+		_ = b.conn.Close()
+	}
+	if cerr := b.connect(); cerr != nil {
+		return cerr
+	}
+	return b.publish(key)
+}
+
+// publish runs one PUBLISH on the current connection
+func (b *RedisBus) publish(key string) error {
+	_ = b.conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if err := writeRESPCommand(b.conn, "PUBLISH", b.channel, key); err != nil {
+		return err
+	}
+	reply, err := readRESP(b.r)
+	if err != nil {
+		return err
+	}
+	if _, ok := reply.(int64); !ok {
+		return fmt.Errorf("unexpected redis reply to PUBLISH: %v", reply)
+	}
+	return nil
+}
+
+// Subscribe calls fn with every message on the channel, from a goroutine of its own,
+// until Close. It returns once the first subscription is confirmed.
+func (b *RedisBus) Subscribe(fn func(key string)) error {
+	if b.isClosed() {
+		return errRedisBusClosed
+	}
+	conn, r, err := b.subscribe()
+	if err != nil {
+		return err
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for {
+			b.receive(conn, r, fn)
+			// The connection dropped: retry until Close
+			for {
+				select {
+				case <-b.done:
+					return
+				case <-time.After(time.Second):
+				}
+				if conn, r, err = b.subscribe(); err == nil {
+					break
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// subscribe opens a connection subscribed to the channel
+func (b *RedisBus) subscribe() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", b.addr, redisDialTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to redis at %s: %w", b.addr, err)
+	}
+	r := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if err := writeRESPCommand(conn, "SUBSCRIBE", b.channel); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if reply, err := readRESP(r); err != nil {
+		conn.Close()
+		return nil, nil, err
+	} else if parts, ok := reply.([]interface{}); !ok || len(parts) != 3 || parts[0] != "subscribe" {
+		conn.Close()
+		return nil, nil, fmt.Errorf("unexpected redis reply to SUBSCRIBE: %v", reply)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+	if b.closed {
+		conn.Close()
+		return nil, nil, errRedisBusClosed
+	}
+	b.subs = append(b.subs, conn)
+	return conn, r, nil
+}
+
+// receive delivers messages from conn until it fails or is closed
+func (b *RedisBus) receive(conn net.Conn, r *bufio.Reader, fn func(key string)) {
+	defer b.forget(conn)
+	for {
+		reply, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 || parts[0] != "message" {
+			continue
+		}
+		if key, ok := parts[2].(string); ok {
+			fn(key)
+		}
+	}
+}
+
+// forget closes a subscriber connection and drops it from b.subs
+func (b *RedisBus) forget(conn net.Conn) {
+	conn.Close()
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+	for i, c := range b.subs {
+		if c == conn {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			break
+		}
+	}
+}
+
+// isClosed reports whether Close was called
+func (b *RedisBus) isClosed() bool {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+	return b.closed
+}
+
+// Close closes every connection and waits for the subscriber goroutines to return
+func (b *RedisBus) Close() error {
+	b.subMu.Lock()
+	if b.closed {
+		b.subMu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.done)
+	for _, conn := range b.subs {
+		conn.Close()
+	}
+	b.subMu.Unlock()
+
+	b.mu.Lock()
+	err := b.conn.Close()
+	b.mu.Unlock()
+	b.wg.Wait()
+	return err
+}
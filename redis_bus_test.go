@@ -0,0 +1,56 @@
+// redis_bus_test.go: Integration test for the Redis Pub/Sub InvalidationBus
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build redis
+
+package metis
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestRedisBus_Converges needs a Redis server: go test -tags redis with METIS_REDIS_ADDR set
+func TestRedisBus_Converges(t *testing.T) {
+	addr := os.Getenv("METIS_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("METIS_REDIS_ADDR not set")
+	}
+	channel := "metis-test-" + time.Now().Format("150405.000000")
+
+	var caches [2]*StrategicCache
+	for i := range caches {
+		bus, err := NewRedisBus(addr, channel)
+		if err != nil {
+			t.Fatalf("NewRedisBus: %v", err)
+		}
+		defer bus.Close()
+		caches[i] = NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+		defer caches[i].Close()
+		if err := caches[i].AttachInvalidationBus(bus); err != nil {
+			t.Fatalf("AttachInvalidationBus: %v", err)
+		}
+	}
+	a, b := caches[0], caches[1]
+
+	// A fill is not published, so no late message from b can remove a's write
+	_, _ = b.LoadOrCompute("user:1", func(string) (interface{}, error) { return "old", nil })
+	a.Set("user:1", "new")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := b.Get("user:1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the other cache to drop its stale value")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v, ok := a.Get("user:1"); !ok || v != "new" {
+		t.Errorf("expected the writer to keep its value, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,325 @@
+// reload.go: Runtime configuration updates for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ConfigPatch lists configuration changes for StrategicCache.UpdateConfig; nil fields are left unchanged.
+//
+// TTL, CleanupInterval, AdmissionProbability, MaxKeySize, MaxValueSize and CompressionMinSize
+// can change while the cache is in use. CacheSize, ShardCount, EvictionPolicy and AdmissionPolicy
+// determine the cache's structure: they are accepted only when equal to the current value, so a
+// patch built from a whole config file applies cleanly as long as the structure is unchanged.
+type ConfigPatch struct {
+	// TTL applies to entries stored after the update; existing entries keep their expiration
+	TTL *time.Duration
+	// CleanupInterval takes effect after the next sweep under the previous interval
+	CleanupInterval *time.Duration
+	// AdmissionProbability requires the "probabilistic" admission policy
+	AdmissionProbability *float64
+	MaxKeySize           *int // 0 removes the limit
+	MaxValueSize         *int // 0 removes the limit
+	// CompressionMinSize requires the gzip codec; 0 restores DefaultCompressionMinSize
+	CompressionMinSize *int
+
+	// Structural settings, rejected with ErrImmutableConfig when they differ
+	CacheSize       *int
+	ShardCount      *int
+	EvictionPolicy  *string
+	AdmissionPolicy *string
+}
+
+// tuning holds the settings UpdateConfig can change. It is nil until the first update;
+// until then the values fixed by NewStrategicCache apply.
+type tuning struct {
+	ttl             time.Duration
+	cleanupInterval time.Duration
+	maxKeySize      int
+	maxValueSize    int
+	admission       AdmissionPolicy
+	codec           Codec
+}
+
+// currentTuning returns a copy of the settings in effect
+func (sc *StrategicCache) currentTuning() tuning {
+	if t := sc.tuned.Load(); t != nil {
+		return *t
+	}
+	return tuning{
+		ttl:             sc.config.TTL,
+		cleanupInterval: sc.config.CleanupInterval,
+		maxKeySize:      sc.config.MaxKeySize,
+		maxValueSize:    sc.config.MaxValueSize,
+		admission:       sc.admission,
+		codec:           sc.codec,
+	}
+}
+
+// EffectiveConfig returns the configuration the cache runs with: the CacheConfig it was
+// created with, with the defaults NewStrategicCache filled in, such as the ShardCount
+// derived from GOMAXPROCS, and the settings changed since by UpdateConfig
+func (sc *StrategicCache) EffectiveConfig() CacheConfig {
+	config := sc.config
+	t := sc.currentTuning()
+	config.TTL, config.CleanupInterval = t.ttl, t.cleanupInterval
+	config.MaxKeySize, config.MaxValueSize = t.maxKeySize, t.maxValueSize
+	if p, ok := t.admission.(*ProbabilisticAdmissionPolicy); ok {
+		config.AdmissionProbability = p.Probability
+	}
+	if gz, ok := t.codec.(gzipCodec); ok && config.EnableCompression {
+		config.CompressionMinSize = gz.minSize
+	}
+	return config
+}
+
+// entryTTL returns the TTL for entries stored now
+func (sc *StrategicCache) entryTTL() time.Duration {
+	if t := sc.tuned.Load(); t != nil {
+		return t.ttl
+	}
+	return sc.config.TTL
+}
+
+// cleanupEvery returns the interval between expiration sweeps
+func (sc *StrategicCache) cleanupEvery() time.Duration {
+	if t := sc.tuned.Load(); t != nil {
+		return t.cleanupInterval
+	}
+	return sc.config.CleanupInterval
+}
+
+// sizeLimits returns MaxKeySize and MaxValueSize (0 = unlimited)
+func (sc *StrategicCache) sizeLimits() (int, int) {
+	if t := sc.tuned.Load(); t != nil {
+		return t.maxKeySize, t.maxValueSize
+	}
+	return sc.config.MaxKeySize, sc.config.MaxValueSize
+}
+
+// admissionPolicy returns the admission policy for new values
+func (sc *StrategicCache) admissionPolicy() AdmissionPolicy {
+	if t := sc.tuned.Load(); t != nil {
+		return t.admission
+	}
+	return sc.admission
+}
+
+// valueCodec returns the codec for newly compressed values
+func (sc *StrategicCache) valueCodec() Codec {
+	if t := sc.tuned.Load(); t != nil {
+		return t.codec
+	}
+	return sc.codec
+}
+
+// UpdateConfig applies a patch to a running cache without dropping entries.
+// The whole patch is validated first: if any field is invalid (ErrInvalidConfig) or would
+// change the cache's structure (ErrImmutableConfig), nothing is applied and the joined
+// errors are returned. Concurrent operations see either the old or the new settings.
+func (sc *StrategicCache) UpdateConfig(patch ConfigPatch) error {
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	if closed {
+		return ErrCacheClosed
+	}
+
+	sc.tuneMu.Lock()
+	defer sc.tuneMu.Unlock()
+
+	next := sc.currentTuning()
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidConfig}, args...)...))
+	}
+	immutable := func(field string, from, to interface{}) {
+		errs = append(errs, fmt.Errorf("%w: %s cannot change from %v to %v", ErrImmutableConfig, field, from, to))
+	}
+
+	if p := patch.CacheSize; p != nil && *p != sc.config.CacheSize {
+		immutable("CacheSize", sc.config.CacheSize, *p)
+	}
+	if p := patch.ShardCount; p != nil && *p != sc.config.ShardCount {
+		immutable("ShardCount", sc.config.ShardCount, *p)
+	}
+	if p := patch.EvictionPolicy; p != nil && *p != sc.config.EvictionPolicy {
+		immutable("EvictionPolicy", fmt.Sprintf("%q", sc.config.EvictionPolicy), fmt.Sprintf("%q", *p))
+	}
+	if p := patch.AdmissionPolicy; p != nil && *p != sc.config.AdmissionPolicy {
+		immutable("AdmissionPolicy", fmt.Sprintf("%q", sc.config.AdmissionPolicy), fmt.Sprintf("%q", *p))
+	}
+
+	if p := patch.TTL; p != nil {
+		if *p <= 0 {
+			invalid("TTL must be positive, got %s", *p)
+		}
+		next.ttl = *p
+	}
+	if p := patch.CleanupInterval; p != nil {
+		if *p <= 0 {
+			invalid("CleanupInterval must be positive, got %s", *p)
+		}
+		next.cleanupInterval = *p
+	}
+	if p := patch.MaxKeySize; p != nil {
+		if *p < 0 {
+			invalid("MaxKeySize must not be negative, got %d", *p)
+		}
+		next.maxKeySize = *p
+	}
+	if p := patch.MaxValueSize; p != nil {
+		if *p < 0 {
+			invalid("MaxValueSize must not be negative, got %d", *p)
+		}
+		next.maxValueSize = *p
+	}
+	if p := patch.AdmissionProbability; p != nil {
+		if _, ok := next.admission.(*ProbabilisticAdmissionPolicy); !ok {
+			invalid("AdmissionProbability requires the probabilistic admission policy, not %T", next.admission)
+		} else if *p < 0 || *p > 1 {
+			invalid("AdmissionProbability must be within [0,1], got %g", *p)
+		} else {
+			// Replace rather than modify the policy, which concurrent Sets may be reading
+			next.admission = &ProbabilisticAdmissionPolicy{Probability: *p}
+		}
+	}
+	if p := patch.CompressionMinSize; p != nil {
+		if gz, ok := next.codec.(gzipCodec); !ok {
+			invalid("CompressionMinSize requires the gzip codec, not %T", next.codec)
+		} else if *p < 0 {
+			invalid("CompressionMinSize must not be negative, got %d", *p)
+		} else {
+			gz.minSize = *p
+			if gz.minSize == 0 {
+				gz.minSize = DefaultCompressionMinSize
+			}
+			next.codec = gz
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if patch.TTL != nil {
+		if sc.wtinylfu != nil {
+			sc.wtinylfu.SetTTL(next.ttl)
+		}
+		if sc.arc != nil {
+			sc.arc.SetTTL(next.ttl)
+		}
+	}
+	sc.tuned.Store(&next)
+	return nil
+}
+
+// patchFromConfig builds a patch carrying every runtime-changeable and structural setting
+// of config, with zero TTL and CleanupInterval replaced by the NewStrategicCache defaults
+func patchFromConfig(config CacheConfig) ConfigPatch {
+	ttl, cleanupInterval := config.TTL, config.CleanupInterval
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if cleanupInterval <= 0 {
+		cleanupInterval = defaultCleanupInterval
+	}
+	patch := ConfigPatch{
+		TTL:                &ttl,
+		CleanupInterval:    &cleanupInterval,
+		MaxKeySize:         &config.MaxKeySize,
+		MaxValueSize:       &config.MaxValueSize,
+		CompressionMinSize: &config.CompressionMinSize,
+		CacheSize:          &config.CacheSize,
+		ShardCount:         &config.ShardCount,
+		EvictionPolicy:     &config.EvictionPolicy,
+		AdmissionPolicy:    &config.AdmissionPolicy,
+	}
+	if config.AdmissionPolicy == "probabilistic" {
+		probability := config.AdmissionProbability
+		if probability < 0 {
+			probability = 0.5 // Same default as NewStrategicCache
+		}
+		patch.AdmissionProbability = &probability
+	}
+	if !config.EnableCompression {
+		patch.CompressionMinSize = nil // The codec may not be gzip, and the threshold is unused
+	}
+	return patch
+}
+
+// ReloadConfigFile reads a JSON, YAML or TOML config file, applies $METIS_* overrides and
+// passes every runtime-changeable setting to UpdateConfig. The file's structural settings must
+// match the cache, so it is meant for caches created from the same file with NewFromFile or New.
+func (sc *StrategicCache) ReloadConfigFile(path string) error {
+	config, err := parseConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if err := config.ApplyEnv(EnvPrefix); err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := sc.UpdateConfig(patchFromConfig(config)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// configWatchInterval is how often WatchConfigFile checks the file for changes
+var configWatchInterval = 5 * time.Second
+
+// WatchConfigFile polls a config file every few seconds and calls ReloadConfigFile when its
+// modification time or size changes. Reloads and failures are reported to CacheConfig.Logger
+// when one is set; a failed reload leaves the previous settings in place.
+// Watching stops when the returned function is called or the cache is closed.
+func (sc *StrategicCache) WatchConfigFile(path string) (stop func()) {
+	done := make(chan struct{})
+	var lastMod time.Time
+	var lastSize int64 = -1
+	if info, err := os.Stat(path); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	sc.wg.Add(1)
+	go func() {
+		defer sc.wg.Done()
+		ticker := time.NewTicker(configWatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+					continue
+				}
+				lastMod, lastSize = info.ModTime(), info.Size()
+
+				if err := sc.ReloadConfigFile(path); err != nil {
+					sc.logger.Warn("metis: config reload failed", "path", path, "error", err)
+				} else {
+					sc.logger.Info("metis: config reloaded", "path", path)
+				}
+			case <-done:
+				return
+			case <-sc.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,337 @@
+// reload_test.go: Tests for runtime configuration updates
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func durationPtr(d time.Duration) *time.Duration { return &d }
+func intPtr(n int) *int                          { return &n }
+func float64Ptr(f float64) *float64              { return &f }
+func stringPtr(s string) *string                 { return &s }
+
+// TestUpdateConfig_TTL tests that a new TTL applies to entries stored afterwards on every path
+func TestUpdateConfig_TTL(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, EvictionPolicy: policy, TTL: time.Hour, Clock: clock})
+			defer cache.Close()
+
+			cache.Set("old", "value")
+			if err := cache.UpdateConfig(ConfigPatch{TTL: durationPtr(20 * time.Millisecond)}); err != nil {
+				t.Fatalf("UpdateConfig failed: %v", err)
+			}
+			cache.Set("new", "value")
+
+			clock.Advance(40 * time.Millisecond)
+			if _, ok := cache.Get("new"); ok {
+				t.Error("expected the entry stored after the update to use the new TTL")
+			}
+			if _, ok := cache.Get("old"); !ok {
+				t.Error("expected the entry stored before the update to keep its TTL")
+			}
+		})
+	}
+}
+
+// TestUpdateConfig_SizeLimits tests that MaxKeySize and MaxValueSize apply immediately
+func TestUpdateConfig_SizeLimits(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, EvictionPolicy: policy})
+			defer cache.Close()
+
+			if err := cache.UpdateConfig(ConfigPatch{MaxKeySize: intPtr(4), MaxValueSize: intPtr(8)}); err != nil {
+				t.Fatalf("UpdateConfig failed: %v", err)
+			}
+			if err := cache.SetE("long-key", "v"); !errors.Is(err, ErrKeyTooLarge) {
+				t.Errorf("expected ErrKeyTooLarge, got %v", err)
+			}
+			if err := cache.SetE("k", strings.Repeat("x", 100)); !errors.Is(err, ErrValueTooLarge) {
+				t.Errorf("expected ErrValueTooLarge, got %v", err)
+			}
+			if cache.SetBytes("k", make([]byte, 100)) {
+				t.Error("expected SetBytes to respect the new MaxValueSize")
+			}
+
+			// Zero removes the limits again
+			if err := cache.UpdateConfig(ConfigPatch{MaxKeySize: intPtr(0), MaxValueSize: intPtr(0)}); err != nil {
+				t.Fatalf("UpdateConfig failed: %v", err)
+			}
+			if err := cache.SetE("long-key", strings.Repeat("x", 100)); err != nil {
+				t.Errorf("expected the limits to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestUpdateConfig_AdmissionProbability tests replacing the probabilistic policy's probability
+func TestUpdateConfig_AdmissionProbability(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:        true,
+		CacheSize:            1000,
+		EvictionPolicy:       "lru",
+		AdmissionPolicy:      "probabilistic",
+		AdmissionProbability: 1,
+	})
+	defer cache.Close()
+
+	if err := cache.SetE("before", "v"); err != nil {
+		t.Fatalf("expected admission with probability 1, got %v", err)
+	}
+	if err := cache.UpdateConfig(ConfigPatch{AdmissionProbability: float64Ptr(0)}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	if err := cache.SetE("after", "v"); !errors.Is(err, ErrNotAdmitted) {
+		t.Errorf("expected ErrNotAdmitted with probability 0, got %v", err)
+	}
+
+	if err := cache.UpdateConfig(ConfigPatch{AdmissionProbability: float64Ptr(1.5)}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a probability above 1, got %v", err)
+	}
+
+	always := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, EvictionPolicy: "lru"})
+	defer always.Close()
+	if err := always.UpdateConfig(ConfigPatch{AdmissionProbability: float64Ptr(0.5)}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without the probabilistic policy, got %v", err)
+	}
+}
+
+// TestUpdateConfig_CompressionMinSize tests changing the gzip threshold
+func TestUpdateConfig_CompressionMinSize(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru", EnableCompression: true})
+	defer cache.Close()
+
+	value := strings.Repeat("ab", 40) // Above the default threshold
+	cache.Set("a", value)
+	if stats := cache.CompressionStats(); stats.CompressedEntries != 1 {
+		t.Fatalf("expected the value to be compressed, got %+v", stats)
+	}
+
+	if err := cache.UpdateConfig(ConfigPatch{CompressionMinSize: intPtr(1024)}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	cache.Set("b", value)
+	if stats := cache.CompressionStats(); stats.CompressedEntries != 1 || stats.SkippedEntries != 1 {
+		t.Errorf("expected the new value to be stored under the raised threshold, got %+v", stats)
+	}
+	for _, key := range []string{"a", "b"} {
+		if got, ok := cache.Get(key); !ok || got != value {
+			t.Errorf("expected %q to round-trip, got %v", key, got)
+		}
+	}
+}
+
+// TestUpdateConfig_RejectsAtomically tests that invalid or structural changes apply nothing
+func TestUpdateConfig_RejectsAtomically(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, ShardCount: 8, EvictionPolicy: "lru", TTL: time.Hour})
+	defer cache.Close()
+
+	err := cache.UpdateConfig(ConfigPatch{
+		TTL:            durationPtr(time.Minute),
+		ShardCount:     intPtr(16),
+		EvictionPolicy: stringPtr("arc"),
+	})
+	if !errors.Is(err, ErrImmutableConfig) {
+		t.Fatalf("expected ErrImmutableConfig, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ShardCount") || !strings.Contains(err.Error(), "EvictionPolicy") {
+		t.Errorf("expected both structural fields to be reported, got %v", err)
+	}
+	if ttl := cache.entryTTL(); ttl != time.Hour {
+		t.Errorf("expected the TTL to be unchanged after a rejected patch, got %s", ttl)
+	}
+
+	if err := cache.UpdateConfig(ConfigPatch{TTL: durationPtr(0), MaxKeySize: intPtr(-1)}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	// Structural fields equal to the current values are accepted
+	if err := cache.UpdateConfig(ConfigPatch{ShardCount: intPtr(8), EvictionPolicy: stringPtr("lru"), TTL: durationPtr(time.Minute)}); err != nil {
+		t.Errorf("expected unchanged structural fields to be accepted, got %v", err)
+	}
+	if ttl := cache.entryTTL(); ttl != time.Minute {
+		t.Errorf("expected the TTL to be updated, got %s", ttl)
+	}
+
+	cache.Close()
+	if err := cache.UpdateConfig(ConfigPatch{TTL: durationPtr(time.Minute)}); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed, got %v", err)
+	}
+}
+
+// TestUpdateConfig_Concurrent tests updates racing with reads and writes (run with -race)
+func TestUpdateConfig_Concurrent(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:     true,
+				CacheSize:         1000,
+				EvictionPolicy:    policy,
+				EnableCompression: policy == "lru",
+				CleanupInterval:   time.Millisecond,
+			})
+			defer cache.Close()
+
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						key := fmt.Sprintf("k%d-%d", g, i%50)
+						cache.Set(key, strings.Repeat("v", i%200))
+						cache.Get(key)
+					}
+				}(g)
+			}
+			for i := 0; i < 50; i++ {
+				patch := ConfigPatch{
+					TTL:             durationPtr(time.Duration(i+1) * time.Second),
+					CleanupInterval: durationPtr(time.Duration(i%3+1) * time.Millisecond),
+					MaxValueSize:    intPtr(i * 10),
+				}
+				if policy == "lru" {
+					patch.CompressionMinSize = intPtr(i)
+				}
+				if err := cache.UpdateConfig(patch); err != nil {
+					t.Fatalf("UpdateConfig failed: %v", err)
+				}
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// TestReloadConfigFile tests applying a changed config file to a running cache
+func TestReloadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metis.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+	}
+	write("cache_size: 1000\nttl: 1h\nshard_count: 4\n")
+
+	cache, err := NewFromFile(path)
+	if err != nil {
+		t.Fatalf("NewFromFile failed: %v", err)
+	}
+	defer cache.Close()
+	cache.Set("kept", "value")
+
+	write("cache_size: 1000\nttl: 5m\nshard_count: 4\nmax_value_size: 16\n")
+	if err := cache.ReloadConfigFile(path); err != nil {
+		t.Fatalf("ReloadConfigFile failed: %v", err)
+	}
+	if cache.entryTTL() != 5*time.Minute {
+		t.Errorf("expected TTL 5m after reload, got %s", cache.entryTTL())
+	}
+	if err := cache.SetE("big", strings.Repeat("x", 100)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected the reloaded MaxValueSize to apply, got %v", err)
+	}
+	if _, ok := cache.Get("kept"); !ok {
+		t.Error("expected entries to survive a reload")
+	}
+
+	write("cache_size: 1000\nttl: 1m\nshard_count: 64\n")
+	if err := cache.ReloadConfigFile(path); !errors.Is(err, ErrImmutableConfig) {
+		t.Errorf("expected ErrImmutableConfig for a new shard count, got %v", err)
+	}
+	if cache.entryTTL() != 5*time.Minute {
+		t.Errorf("expected a rejected reload to keep TTL 5m, got %s", cache.entryTTL())
+	}
+}
+
+// recordingLogger captures Info and Warn messages for WatchConfigFile tests
+type recordingLogger struct {
+	mu   sync.Mutex
+	info []string
+	warn []string
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Error(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Info(msg string, fields ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.info = append(l.info, msg)
+}
+func (l *recordingLogger) Warn(msg string, fields ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warn = append(l.warn, fmt.Sprint(append([]interface{}{msg}, fields...)...))
+}
+
+// TestWatchConfigFile tests that file changes are picked up by polling
+func TestWatchConfigFile(t *testing.T) {
+	originalInterval := configWatchInterval
+	configWatchInterval = 5 * time.Millisecond
+	defer func() { configWatchInterval = originalInterval }()
+
+	path := filepath.Join(t.TempDir(), "metis.json")
+	if err := os.WriteFile(path, []byte(`{"cache_size": 1000, "ttl": "1h", "shard_count": 4}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	logger := &recordingLogger{}
+	config.Logger = logger
+	cache := NewStrategicCache(config)
+	defer cache.Close()
+
+	stop := cache.WatchConfigFile(path)
+	defer stop()
+
+	// A different size guarantees the change is seen even with coarse modification times
+	if err := os.WriteFile(path, []byte(`{"cache_size": 1000, "ttl": "2m", "shard_count": 4}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.entryTTL() != 2*time.Minute && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cache.entryTTL() != 2*time.Minute {
+		t.Fatalf("expected the watcher to apply TTL 2m, got %s", cache.entryTTL())
+	}
+
+	// A malformed file is reported and leaves the settings in place
+	if err := os.WriteFile(path, []byte(`{"ttl": "later"}`), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	for time.Now().Before(deadline) {
+		logger.mu.Lock()
+		warned := len(logger.warn) > 0
+		logger.mu.Unlock()
+		if warned {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.info) == 0 || len(logger.warn) == 0 {
+		t.Errorf("expected one reload and one failure to be logged, got info %v, warn %v", logger.info, logger.warn)
+	}
+	if cache.entryTTL() != 2*time.Minute {
+		t.Errorf("expected a failed reload to keep TTL 2m, got %s", cache.entryTTL())
+	}
+	stop() // Safe to call more than once
+}
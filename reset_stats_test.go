@@ -0,0 +1,111 @@
+// reset_stats_test.go: Tests for zeroing the statistics counters
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResetStats_ZeroesCountersKeepsData(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:         true,
+				CacheSize:             100,
+				ShardCount:            4,
+				TTL:                   time.Minute,
+				EvictionPolicy:        policy,
+				StatsWindow:           time.Minute,
+				EnableLatencyTracking: true,
+			})
+			defer cache.Close()
+			for i := 0; i < 300; i++ {
+				key := fmt.Sprintf("k%d", i)
+				cache.Set(key, i)
+				cache.Get(key)
+				cache.Get("missing")
+			}
+			before := cache.GetStats()
+			if before.Hits == 0 || before.Misses == 0 || before.Evictions == 0 {
+				t.Fatalf("expected traffic to be counted first, got %+v", before)
+			}
+
+			cache.ResetStats()
+			after := cache.GetStats()
+			if after.Hits != 0 || after.Misses != 0 || after.Evictions != 0 || after.Expirations != 0 || after.MemoryEvictedBytes != 0 {
+				t.Errorf("expected zeroed counters, got %+v", after)
+			}
+			if after.Keys != before.Keys || cache.Len() != before.Keys {
+				t.Errorf("expected the %d cached keys to stay, got %d", before.Keys, after.Keys)
+			}
+			for _, shard := range cache.ShardStats() {
+				if shard.Hits != 0 || shard.Misses != 0 || shard.Evictions != 0 {
+					t.Errorf("expected zeroed shard counters, got %+v", shard)
+				}
+			}
+			if w := cache.WindowedStats(); w.Hits != 0 || w.Misses != 0 || w.Evictions != 0 {
+				t.Errorf("expected zeroed windowed counters, got %+v", w)
+			}
+			if l := cache.LatencyStats(); l.Get.Count != 0 || l.Set.Count != 0 {
+				t.Errorf("expected cleared latency histograms, got %+v", l)
+			}
+
+			// Counting resumes from zero
+			cache.Get("k299")
+			if hits := cache.GetStats().Hits; hits != 1 {
+				t.Errorf("expected 1 hit after the reset, got %d", hits)
+			}
+		})
+	}
+}
+
+func TestResetStats_ConcurrentTraffic(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			var wg sync.WaitGroup
+			stop := make(chan struct{})
+			for w := 0; w < 4; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; ; i++ {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						key := fmt.Sprintf("w%d-%d", w, i%500)
+						cache.Set(key, i)
+						cache.Get(key)
+					}
+				}(w)
+			}
+			for i := 0; i < 50; i++ {
+				cache.ResetStats()
+				cache.GetStats()
+			}
+			close(stop)
+			wg.Wait()
+
+			cache.ResetStats()
+			if stats := cache.GetStats(); stats.Hits != 0 || stats.Misses != 0 || stats.Evictions != 0 {
+				t.Errorf("expected zeroed counters once traffic stopped, got %+v", stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// resp.go: RESP2 wire protocol encoding for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Limits on what a peer may send, as in Redis: a bulk string of at most 512 MiB, an
+// array of at most a million elements and a line (such as an inline command) of at
+// most 64 KiB
+const (
+	respMaxBulk   = 512 << 20
+	respMaxArray  = 1 << 20
+	respMaxInline = 64 << 10
+)
+
+// errRESPProtocol reports input that is not RESP2
+var errRESPProtocol = errors.New("resp protocol error")
+
+// writeRESPCommand sends args as a RESP array of bulk strings
+func writeRESPCommand(w io.Writer, args ...string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range args {
+		buf = appendRESPBulk(buf, []byte(arg))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// readRESP reads one RESP value: a string for simple and bulk strings, int64 for
+// integers, nil for null, []interface{} for arrays, and an error for error replies
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	kind, body, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		data, err := readRESPBulk(r, body)
+		if data == nil || err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case '*':
+		n, err := respLength(body, respMaxArray)
+		if n < 0 || err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", errRESPProtocol, kind)
+}
+
+// readRESPCommand reads one client command: an array of bulk strings, or an inline
+// command of words separated by spaces, as typed into telnet. An empty inline line
+// returns no arguments.
+func readRESPCommand(r *bufio.Reader) ([][]byte, error) {
+	kind, body, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind != '*' {
+		words := strings.Fields(string(kind) + body)
+		args := make([][]byte, len(words))
+		for i, word := range words {
+			args[i] = []byte(word)
+		}
+		return args, nil
+	}
+
+	n, err := respLength(body, respMaxArray)
+	if err != nil {
+		return nil, err
+	}
+	args := make([][]byte, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		kind, body, err := readRESPLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if kind != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got %q", errRESPProtocol, kind)
+		}
+		arg, err := readRESPBulk(r, body)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// readRESPLine reads a line up to CRLF and splits off its type byte. A line longer than
+// respMaxInline is a protocol error.
+func readRESPLine(r *bufio.Reader) (byte, string, error) {
+	var buf []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(buf)+len(chunk) > respMaxInline+2 {
+			return 0, "", fmt.Errorf("%w: line longer than %d bytes", errRESPProtocol, respMaxInline)
+		}
+		buf = append(buf, chunk...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return 0, "", err
+		}
+	}
+	line := strings.TrimSuffix(strings.TrimSuffix(string(buf), "\n"), "\r")
+	if line == "" {
+		return ' ', "", nil
+	}
+	return line[0], line[1:], nil
+}
+
+// readRESPBulk reads the data of a bulk string whose header announced length; a length
+// of -1 is the null bulk string, returned as nil
+func readRESPBulk(r *bufio.Reader, length string) ([]byte, error) {
+	n, err := respLength(length, respMaxBulk)
+	if n < 0 || err != nil {
+		return nil, err
+	}
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if data[n] != '\r' || data[n+1] != '\n' {
+		return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", errRESPProtocol)
+	}
+	return data[:n:n], nil
+}
+
+// respLength parses the length of a bulk string or array, -1 meaning null
+func respLength(s string, limit int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < -1 || n > limit {
+		return 0, fmt.Errorf("%w: invalid length %q", errRESPProtocol, s)
+	}
+	return n, nil
+}
+
+// appendRESPSimple appends a simple string, such as OK
+func appendRESPSimple(buf []byte, s string) []byte {
+	buf = append(buf, '+')
+	buf = append(buf, s...)
+	return append(buf, "\r\n"...)
+}
+
+// appendRESPError appends an error reply; msg starts with its kind, such as "ERR"
+func appendRESPError(buf []byte, msg string) []byte {
+	buf = append(buf, '-')
+	buf = append(buf, strings.NewReplacer("\r", " ", "\n", " ").Replace(msg)...)
+	return append(buf, "\r\n"...)
+}
+
+// appendRESPInt appends an integer reply
+func appendRESPInt(buf []byte, n int64) []byte {
+	buf = append(buf, ':')
+	buf = strconv.AppendInt(buf, n, 10)
+	return append(buf, "\r\n"...)
+}
+
+// appendRESPBulk appends a bulk string, or the null bulk string for a nil b
+func appendRESPBulk(buf []byte, b []byte) []byte {
+	if b == nil {
+		return append(buf, "$-1\r\n"...)
+	}
+	buf = append(buf, '$')
+	buf = strconv.AppendInt(buf, int64(len(b)), 10)
+	buf = append(buf, "\r\n"...)
+	buf = append(buf, b...)
+	return append(buf, "\r\n"...)
+}
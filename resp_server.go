@@ -0,0 +1,310 @@
+// resp_server.go: Redis-compatible RESP2 server for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RESPServer serves a cache over the Redis RESP2 protocol, so Redis clients and tools can
+// be pointed at it during local development. It understands a subset of Redis:
+//
+//   - GET key, read through the Backend on a miss
+//   - SET key value [EX seconds | PX milliseconds], written through the Backend; without
+//     EX or PX the cache TTL applies
+//   - DEL key [key ...] and EXISTS key [key ...], replying with the number of keys
+//   - TTL key: -2 for a missing key, -1 for one that never expires
+//   - FLUSHALL, which clears the cache
+//   - INFO [section], reporting GetStats in the sections memory, stats and keyspace
+//   - PING [message] and QUIT
+//
+// Values are byte slices stored with SetBytes and read with GetBytes; GET replies null
+// for a key holding a value of another type. There is a single database and no
+// authentication, so the server should only listen where its clients are trusted.
+type RESPServer struct {
+	cache    *StrategicCache
+	listener net.Listener
+
+	mu     sync.Mutex // Guards conns and closing
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup // The accept loop and the connection handlers
+
+	done    chan struct{}
+	exited  chan struct{}
+	stopped sync.Once
+}
+
+// NewRESPServer starts serving c over RESP2 on addr ("host:port"; port 0 picks a free
+// one, see Addr). The server stops when c is closed or Close is called.
+func NewRESPServer(c *StrategicCache, addr string) (*RESPServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &RESPServer{
+		cache:    c,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
+		exited:   make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+	c.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *RESPServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops accepting connections, closes the open ones, including those in the middle
+// of a command, and waits for their handlers to return. It is safe to call more than once.
+func (s *RESPServer) Close() error {
+	s.stopped.Do(func() { close(s.done) })
+	<-s.exited
+	return nil
+}
+
+// run waits for the server to be stopped or the cache closed, then shuts down
+func (s *RESPServer) run() {
+	defer s.cache.wg.Done()
+	defer close(s.exited)
+	select {
+	case <-s.done:
+	case <-s.cache.ctx.Done():
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	_ = s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// accept hands each new connection to a handler until the listener is closed
+func (s *RESPServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serve(conn)
+	}
+}
+
+// serve runs the commands of one connection. Replies are flushed once no pipelined
+// command is left to read.
+func (s *RESPServer) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var buf []byte
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			if errors.Is(err, errRESPProtocol) {
+				_, _ = w.Write(appendRESPError(nil, "ERR Protocol error: "+err.Error()))
+				_ = w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		var quit bool
+		buf, quit = s.exec(buf[:0], args)
+		if _, err := w.Write(buf); err != nil {
+			return
+		}
+		if quit || r.Buffered() == 0 {
+			if err := w.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// exec runs one command, appending its reply to buf. It reports whether the client quit.
+func (s *RESPServer) exec(buf []byte, args [][]byte) ([]byte, bool) {
+	name := strings.ToUpper(string(args[0]))
+	arity := func(lo, hi int) bool { return len(args) >= lo && (hi < 0 || len(args) <= hi) }
+	switch name {
+	case "PING":
+		if !arity(1, 2) {
+			break
+		}
+		if len(args) == 2 {
+			return appendRESPBulk(buf, args[1]), false
+		}
+		return appendRESPSimple(buf, "PONG"), false
+	case "QUIT":
+		return appendRESPSimple(buf, "OK"), true
+	case "GET":
+		if !arity(2, 2) {
+			break
+		}
+		value, ok := s.cache.GetBytes(string(args[1]))
+		if !ok {
+			return appendRESPBulk(buf, nil), false
+		}
+		return appendRESPBulk(buf, value), false
+	case "SET":
+		if !arity(3, 5) {
+			break
+		}
+		return s.set(buf, args), false
+	case "DEL", "EXISTS":
+		if !arity(2, -1) {
+			break
+		}
+		var n int64
+		for _, key := range args[1:] {
+			var ok bool
+			if name == "DEL" {
+				ok = s.cache.Delete(string(key))
+			} else {
+				_, ok = s.cache.GetEntryInfo(string(key))
+			}
+			if ok {
+				n++
+			}
+		}
+		return appendRESPInt(buf, n), false
+	case "TTL":
+		if !arity(2, 2) {
+			break
+		}
+		info, ok := s.cache.GetEntryInfo(string(args[1]))
+		switch {
+		case !ok:
+			return appendRESPInt(buf, -2), false
+		case info.ExpiresAt.IsZero():
+			return appendRESPInt(buf, -1), false
+		}
+		remaining := info.ExpiresAt.Sub(s.cache.clock.Now())
+		return appendRESPInt(buf, int64(remaining.Round(time.Second)/time.Second)), false
+	case "FLUSHALL":
+		if !arity(1, 2) {
+			break
+		}
+		if len(args) == 2 {
+			if mode := strings.ToUpper(string(args[1])); mode != "SYNC" && mode != "ASYNC" {
+				return appendRESPError(buf, "ERR syntax error"), false
+			}
+		}
+		s.cache.Clear()
+		return appendRESPSimple(buf, "OK"), false
+	case "INFO":
+		if !arity(1, 2) {
+			break
+		}
+		section := "all"
+		if len(args) == 2 {
+			section = strings.ToLower(string(args[1]))
+		}
+		return appendRESPBulk(buf, s.info(section)), false
+	default:
+		return appendRESPError(buf, "ERR unknown command '"+string(args[0])+"'"), false
+	}
+	return appendRESPError(buf, "ERR wrong number of arguments for '"+strings.ToLower(name)+"' command"), false
+}
+
+// set runs SET key value [EX seconds | PX milliseconds]
+func (s *RESPServer) set(buf []byte, args [][]byte) []byte {
+	opts := defaultSetOptions
+	if len(args) > 3 {
+		if len(args) != 5 {
+			return appendRESPError(buf, "ERR syntax error")
+		}
+		unit := time.Second
+		switch strings.ToUpper(string(args[3])) {
+		case "EX":
+		case "PX":
+			unit = time.Millisecond
+		default:
+			return appendRESPError(buf, "ERR syntax error")
+		}
+		n, err := strconv.ParseInt(string(args[4]), 10, 64)
+		if err != nil || n <= 0 || n > int64(1<<62)/int64(unit) {
+			return appendRESPError(buf, "ERR invalid expire time in 'set' command")
+		}
+		WithTTL(time.Duration(n) * unit)(&opts)
+	}
+	// The argument was read into a slice of its own, so the cache can keep it as-is
+	if !s.cache.setBytes(string(args[1]), args[2], opts) {
+		return appendRESPError(buf, "ERR value not stored by the cache")
+	}
+	return appendRESPSimple(buf, "OK")
+}
+
+// info renders GetStats as INFO does: "# Section" headers and "field:value" lines
+func (s *RESPServer) info(section string) []byte {
+	stats := s.cache.GetStats()
+	all := section == "all" || section == "default" || section == "everything"
+	var b strings.Builder
+	field := func(name string, value int64) {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatInt(value, 10))
+		b.WriteString("\r\n")
+	}
+	if all || section == "memory" {
+		b.WriteString("# Memory\r\n")
+		field("used_memory", stats.MemoryBytes)
+		b.WriteString("\r\n")
+	}
+	if all || section == "stats" {
+		b.WriteString("# Stats\r\n")
+		field("keyspace_hits", stats.Hits)
+		field("keyspace_misses", stats.Misses)
+		field("evicted_keys", stats.Evictions)
+		field("expired_keys", stats.Expirations+stats.IdleExpirations)
+		b.WriteString("\r\n")
+	}
+	if all || section == "keyspace" {
+		b.WriteString("# Keyspace\r\n")
+		if stats.Keys > 0 {
+			b.WriteString("db0:keys=" + strconv.Itoa(stats.Keys) + "\r\n")
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,207 @@
+// resp_server_test.go: Tests for the Redis-compatible RESP2 server
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// respClient sends commands over raw TCP and decodes the replies with readRESP
+type respClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialRESP(t *testing.T, s *RESPServer) *respClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &respClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// do runs a command and returns its reply, or the error reply's message
+func (c *respClient) do(args ...string) interface{} {
+	c.t.Helper()
+	if err := writeRESPCommand(c.conn, args...); err != nil {
+		c.t.Fatalf("%s: %v", args[0], err)
+	}
+	reply, err := readRESP(c.r)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "redis: ") {
+			return err.Error()
+		}
+		c.t.Fatalf("%s: %v", args[0], err)
+	}
+	return reply
+}
+
+func newRESPServer(t *testing.T, policy string) (*StrategicCache, *RESPServer) {
+	t.Helper()
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: policy})
+	t.Cleanup(cache.Close)
+	s, err := NewRESPServer(cache, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewRESPServer: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return cache, s
+}
+
+func TestRESPServer_Commands(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache, s := newRESPServer(t, policy)
+			c := dialRESP(t, s)
+
+			checks := []struct {
+				args []string
+				want interface{}
+			}{
+				{[]string{"PING"}, "PONG"},
+				{[]string{"GET", "k"}, nil},
+				{[]string{"SET", "k", "v1"}, "OK"},
+				{[]string{"get", "k"}, "v1"},
+				{[]string{"TTL", "k"}, int64(defaultTTL / time.Second)}, // The cache TTL
+				{[]string{"TTL", "missing"}, int64(-2)},
+				{[]string{"SET", "t", "v", "EX", "100"}, "OK"},
+				{[]string{"TTL", "t"}, int64(100)},
+				{[]string{"SET", "t", "v", "px", "30000"}, "OK"},
+				{[]string{"TTL", "t"}, int64(30)},
+				{[]string{"EXISTS", "k", "t", "missing", "k"}, int64(3)},
+				{[]string{"DEL", "k", "missing"}, int64(1)},
+				{[]string{"EXISTS", "k"}, int64(0)},
+				{[]string{"SET", "k", "v", "EX", "0"}, "redis: ERR invalid expire time in 'set' command"},
+				{[]string{"SET", "k", "v", "NX"}, "redis: ERR syntax error"},
+				{[]string{"GET"}, "redis: ERR wrong number of arguments for 'get' command"},
+				{[]string{"NOPE"}, "redis: ERR unknown command 'NOPE'"},
+				{[]string{"FLUSHALL"}, "OK"},
+				{[]string{"EXISTS", "t"}, int64(0)},
+			}
+			for _, check := range checks {
+				if got := c.do(check.args...); got != check.want {
+					t.Errorf("%q: expected %#v, got %#v", check.args, check.want, got)
+				}
+			}
+
+			// Values are shared with the zero-copy API
+			cache.SetBytes("shared", []byte("from Go"))
+			if got := c.do("GET", "shared"); got != "from Go" {
+				t.Errorf("expected GET to read SetBytes values, got %#v", got)
+			}
+			if v, ok := cache.GetBytes("t"); ok {
+				t.Errorf("expected FLUSHALL to clear the cache, got %q", v)
+			}
+		})
+	}
+}
+
+func TestRESPServer_Info(t *testing.T) {
+	cache, s := newRESPServer(t, "lru")
+	c := dialRESP(t, s)
+	c.do("SET", "k", "v")
+	c.do("GET", "k")
+	c.do("GET", "missing")
+
+	info, _ := c.do("INFO").(string)
+	stats := cache.GetStats()
+	for _, want := range []string{"# Stats", "keyspace_hits:1", "keyspace_misses:1", "db0:keys=1", "used_memory:"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("expected INFO to contain %q, got %q (stats %+v)", want, info, stats)
+		}
+	}
+	if info, _ := c.do("INFO", "keyspace").(string); strings.Contains(info, "# Stats") || !strings.Contains(info, "db0:keys=1") {
+		t.Errorf("expected only the keyspace section, got %q", info)
+	}
+}
+
+func TestRESPServer_PipelineAndInline(t *testing.T) {
+	_, s := newRESPServer(t, "lru")
+	c := dialRESP(t, s)
+
+	// Three commands in one write, the last one inline as typed into telnet
+	if _, err := c.conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n*2\r\n$3\r\nGET\r\n$1\r\na\r\nEXISTS a b\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []interface{}{"OK", "1", int64(1)} {
+		if got, err := readRESP(c.r); err != nil || got != want {
+			t.Errorf("expected %#v, got %#v, %v", want, got, err)
+		}
+	}
+
+	if got := c.do("QUIT"); got != "OK" {
+		t.Errorf("expected QUIT to reply OK, got %#v", got)
+	}
+	if _, err := c.r.ReadByte(); err == nil {
+		t.Error("expected QUIT to close the connection")
+	}
+}
+
+func TestRESPServer_LongLine(t *testing.T) {
+	_, s := newRESPServer(t, "lru")
+	c := dialRESP(t, s)
+
+	// An inline command just under the cap is served
+	if _, err := c.conn.Write([]byte("PING " + strings.Repeat("a", respMaxInline-5) + "\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readRESP(c.r); err != nil || got != strings.Repeat("a", respMaxInline-5) {
+		t.Fatalf("expected PING to echo its argument, got %d bytes, %v", len(fmt.Sprint(got)), err)
+	}
+
+	// A line that keeps going past the cap gets a protocol error, without waiting for its
+	// CRLF, and the connection is closed
+	if _, err := c.conn.Write([]byte(strings.Repeat("a", 2*respMaxInline))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRESP(c.r); err == nil || !strings.Contains(err.Error(), "Protocol error") {
+		t.Errorf("expected a protocol error, got %v", err)
+	}
+	if _, err := c.r.ReadByte(); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestRESPServer_Close(t *testing.T) {
+	cache, s := newRESPServer(t, "lru")
+	c := dialRESP(t, s)
+	c.do("PING")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := c.r.ReadByte(); err == nil {
+		t.Error("expected Close to close client connections")
+	}
+	if _, err := net.Dial("tcp", s.Addr().String()); err == nil {
+		t.Error("expected Close to stop listening")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("expected a second Close to succeed, got %v", err)
+	}
+
+	// Closing the cache stops a server too
+	s, err := NewRESPServer(cache, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = dialRESP(t, s)
+	c.do("PING")
+	cache.Close()
+	if _, err := c.r.ReadByte(); err == nil {
+		t.Error("expected closing the cache to close client connections")
+	}
+}
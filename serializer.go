@@ -0,0 +1,110 @@
+// serializer.go: Pluggable value serializers for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Serializer encodes values that are not primitives when EnableCompression is set.
+// Primitives ([]byte, string, integers, floats, bool) are always encoded natively.
+type Serializer interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, value *interface{}) error
+}
+
+// GobSerializer is the default serializer. Custom types must be registered with gob.Register.
+type GobSerializer struct{}
+
+// Marshal gob-encodes value inside a PrimitiveBox so its concrete type is recorded
+func (GobSerializer) Marshal(value interface{}) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := gob.NewEncoder(buf).Encode(PrimitiveBox{V: value}); err != nil {
+		return nil, err
+	}
+	// Make a copy of the bytes to avoid buffer reuse issues
+	result := make([]byte, buf.Len())
+	copy(result, buf.Bytes())
+	return result, nil
+}
+
+// Unmarshal decodes data written by Marshal
+func (GobSerializer) Unmarshal(data []byte, value *interface{}) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.Write(data)
+	var box PrimitiveBox
+	if err := gob.NewDecoder(buf).Decode(&box); err != nil {
+		return err
+	}
+	*value = box.V
+	return nil
+}
+
+// JSONSerializer encodes values as JSON, with no registration needed.
+// It records the Go type of every value it marshals, so values come back with their
+// original type in the same process; unknown types decode as generic JSON values.
+// Only exported fields are stored.
+type JSONSerializer struct{}
+
+// jsonEnvelope pairs a JSON value with the name of its Go type
+type jsonEnvelope struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v"`
+}
+
+// jsonTypes maps type names to the types seen by JSONSerializer.Marshal
+var jsonTypes sync.Map
+
+// Marshal encodes value as JSON, recording its type
+func (JSONSerializer) Marshal(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	if value != nil {
+		t := reflect.TypeOf(value)
+		name = jsonTypeName(t)
+		jsonTypes.LoadOrStore(name, t)
+	}
+	return json.Marshal(jsonEnvelope{Type: name, Value: raw})
+}
+
+// Unmarshal decodes data written by Marshal into a value of the recorded type
+func (JSONSerializer) Unmarshal(data []byte, value *interface{}) error {
+	var env jsonEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+	t, ok := jsonTypes.Load(env.Type)
+	if !ok {
+		return json.Unmarshal(env.Value, value)
+	}
+	ptr := reflect.New(t.(reflect.Type))
+	if err := json.Unmarshal(env.Value, ptr.Interface()); err != nil {
+		return fmt.Errorf("decode %s: %w", env.Type, err)
+	}
+	*value = ptr.Elem().Interface()
+	return nil
+}
+
+// jsonTypeName names a type uniquely, qualifying named types with their package path
+func jsonTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + jsonTypeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
@@ -0,0 +1,109 @@
+// serializer_test.go: Tests for pluggable value serializers
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"reflect"
+	"testing"
+)
+
+// jsonOnlyProfile is deliberately never passed to gob.Register
+type jsonOnlyProfile struct {
+	Name  string
+	Tags  []string
+	Score float64
+	Inner *jsonOnlyInner
+}
+
+type jsonOnlyInner struct {
+	Level int
+}
+
+func newSerializerTestCache(serializer Serializer) *StrategicCache {
+	return NewStrategicCache(CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         100,
+		ShardCount:        1,
+		EvictionPolicy:    "lru",
+		EnableCompression: true,
+		Serializer:        serializer,
+	})
+}
+
+func TestJSONSerializer_StructWithoutGobRegistration(t *testing.T) {
+	cache := newSerializerTestCache(JSONSerializer{})
+	defer cache.Close()
+
+	profile := jsonOnlyProfile{Name: "ada", Tags: []string{"a", "b"}, Score: 9.5, Inner: &jsonOnlyInner{Level: 3}}
+	if !cache.Set("profile", profile) {
+		t.Fatal("expected Set to succeed with the JSON serializer")
+	}
+	if !cache.Set("ptr", &profile) {
+		t.Fatal("expected Set to succeed for a pointer")
+	}
+
+	got, ok := cache.Get("profile")
+	if !ok || !reflect.DeepEqual(got, profile) {
+		t.Errorf("expected %+v, got %#v (%v)", profile, got, ok)
+	}
+	gotPtr, ok := cache.Get("ptr")
+	if p, isPtr := gotPtr.(*jsonOnlyProfile); !ok || !isPtr || !reflect.DeepEqual(*p, profile) {
+		t.Errorf("expected *jsonOnlyProfile back, got %#v (%v)", gotPtr, ok)
+	}
+
+	// Range decodes with the same serializer
+	cache.Range(func(key string, value interface{}) bool {
+		if key == "profile" && !reflect.DeepEqual(value, profile) {
+			t.Errorf("expected Range to decode %+v, got %#v", profile, value)
+		}
+		return true
+	})
+}
+
+func TestGobSerializer_UnregisteredStructRejected(t *testing.T) {
+	cache := newSerializerTestCache(nil)
+	defer cache.Close()
+
+	if _, ok := cache.serializer.(GobSerializer); !ok {
+		t.Fatalf("expected gob to be the default serializer, got %T", cache.serializer)
+	}
+	if cache.Set("profile", jsonOnlyProfile{Name: "ada"}) {
+		t.Error("expected gob to reject an unregistered struct")
+	}
+}
+
+func TestSerializers_PrimitivesKeepTypes(t *testing.T) {
+	for _, serializer := range []Serializer{GobSerializer{}, JSONSerializer{}} {
+		cache := newSerializerTestCache(serializer)
+		values := map[string]interface{}{
+			"int":   42,
+			"str":   "hello",
+			"bytes": []byte{1, 2, 3},
+			"map":   map[string]int{"a": 1},
+			"slice": []string{"x", "y"},
+		}
+		for k, v := range values {
+			cache.Set(k, v)
+		}
+		for k, want := range values {
+			if got, ok := cache.Get(k); !ok || !reflect.DeepEqual(got, want) {
+				t.Errorf("%T: expected %#v for %s, got %#v", serializer, want, k, got)
+			}
+		}
+		cache.Close()
+	}
+}
+
+func TestJSONSerializer_UnknownTypeDecodesGeneric(t *testing.T) {
+	var out interface{}
+	if err := (JSONSerializer{}).Unmarshal([]byte(`{"t":"example.com/missing.Type","v":{"A":1}}`), &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if m, ok := out.(map[string]interface{}); !ok || m["A"] != float64(1) {
+		t.Errorf("expected a generic JSON object, got %#v", out)
+	}
+}
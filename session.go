@@ -0,0 +1,199 @@
+// session.go: HTTP session store for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	randc "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sessionKeyPrefix starts the cache keys of sessions
+const sessionKeyPrefix = "metis:session:"
+
+// sessionIDBytes is the entropy of a session ID: 256 bits
+const sessionIDBytes = 32
+
+// Encryptor encrypts values before they are stored and decrypts them when they are read.
+// Decrypt must reject ciphertext that was not produced by Encrypt with the same key.
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// aesGCM is the Encryptor returned by NewAESGCMEncryptor
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMEncryptor returns an Encryptor using AES-GCM with key, which must be 16, 24 or
+// 32 bytes long to select AES-128, AES-192 or AES-256. Each value is sealed with a random
+// nonce stored in front of it, so equal values encrypt differently.
+func NewAESGCMEncryptor(key []byte) (Encryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCM{aead: aead}, nil
+}
+
+// Encrypt seals plaintext behind a random nonce
+func (e aesGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(plaintext)+e.aead.Overhead())
+	if _, err := randc.Read(nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens a value sealed by Encrypt
+func (e aesGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := e.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return e.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+// SessionOption customizes a store created by NewSessionStore
+type SessionOption func(*SessionStore)
+
+// WithSessionEncryptor encrypts session data with e before it is stored, so it is never
+// held in memory, in snapshots or in a Backend as plaintext
+func WithSessionEncryptor(e Encryptor) SessionOption {
+	return func(s *SessionStore) { s.encryptor = e }
+}
+
+// SessionStore keeps HTTP session data in a cache, keyed by session ID. Each session
+// expires once unused for the store's TTL: Get and Touch restart it.
+//
+// Besides Get, Set, Delete and Touch it has Find, Commit and Delete with the signatures
+// of the Store interface of session middleware such as github.com/alexedwards/scs/v2,
+// which manages the expiry of each session itself:
+//
+//	Find(token string) (b []byte, found bool, err error)
+//	Commit(token string, b []byte, expiry time.Time) error
+//	Delete(token string) error
+//
+// Sessions are stored with SetBytes under "metis:session:" and the ID, so they can share
+// a cache with other data; being in memory only, they are lost when the process exits
+// unless the cache has a Backend or snapshots.
+type SessionStore struct {
+	cache     *StrategicCache
+	ttl       time.Duration
+	encryptor Encryptor
+}
+
+// NewSessionStore creates a store of sessions in c that expire once unused for ttl (the
+// cache TTL if not positive)
+func NewSessionStore(c *StrategicCache, ttl time.Duration, opts ...SessionOption) *SessionStore {
+	s := &SessionStore{cache: c, ttl: ttl}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewSessionID returns a new random session ID: 256 bits from crypto/rand, encoded as 43
+// URL-safe base64 characters, fit for a cookie
+func NewSessionID() (string, error) {
+	var id [sessionIDBytes]byte
+	if _, err := randc.Read(id[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id[:]), nil
+}
+
+// Get returns the data of a session and restarts its TTL. It returns ErrNotFound for
+// unknown and expired sessions, and an error if the data cannot be decrypted.
+func (s *SessionStore) Get(id string) ([]byte, error) {
+	data, ok := s.cache.GetBytes(sessionKeyPrefix + id)
+	if !ok || id == "" {
+		return nil, ErrNotFound
+	}
+	if s.encryptor == nil {
+		// The cache keeps the slice it returns
+		return bytes.Clone(data), nil
+	}
+	plaintext, err := s.encryptor.Decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting session: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Set stores the data of a session, which expires once unused for the store's TTL
+func (s *SessionStore) Set(id string, data []byte) error {
+	o := defaultSetOptions
+	WithSlidingTTL(s.ttl)(&o)
+	return s.set(id, data, o)
+}
+
+// set stores session data with opts, encrypted if the store has an Encryptor
+func (s *SessionStore) set(id string, data []byte, opts setOptions) error {
+	if id == "" {
+		return errors.New("empty session ID")
+	}
+	var err error
+	if s.encryptor != nil {
+		data, err = s.encryptor.Encrypt(data)
+		if err != nil {
+			return fmt.Errorf("encrypting session: %w", err)
+		}
+	} else {
+		// SetBytes keeps the slice, which the caller may reuse
+		data = bytes.Clone(data)
+	}
+	if !s.cache.setBytes(sessionKeyPrefix+id, data, opts) {
+		return fmt.Errorf("session %d bytes long not stored by the cache", len(data))
+	}
+	return nil
+}
+
+// Delete removes a session. Deleting an unknown session is not an error.
+func (s *SessionStore) Delete(id string) error {
+	return s.cache.DeleteE(sessionKeyPrefix + id)
+}
+
+// Touch restarts the TTL of a session without decrypting it, returning ErrNotFound for
+// unknown and expired sessions
+func (s *SessionStore) Touch(id string) error {
+	if _, ok := s.cache.GetBytes(sessionKeyPrefix + id); !ok || id == "" {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Find returns the data of a session, reporting false for unknown and expired sessions.
+// It is Get for session middleware (see SessionStore).
+func (s *SessionStore) Find(token string) ([]byte, bool, error) {
+	data, err := s.Get(token)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
+	return data, err == nil, err
+}
+
+// Commit stores the data of a session until expiry, which does not slide: the
+// middleware commits again to extend it. A past expiry deletes the session.
+func (s *SessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	if !expiry.After(s.cache.clock.Now()) {
+		return s.Delete(token)
+	}
+	o := defaultSetOptions
+	o.expiresAt = expiry.UnixNano()
+	return s.set(token, b, o)
+}
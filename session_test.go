@@ -0,0 +1,245 @@
+// session_test.go: Tests for the HTTP session store
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSessionStore_SlidingExpiry(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+			store := NewSessionStore(cache, 30*time.Minute)
+
+			if err := store.Set("active", []byte("a")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("touched", []byte("t")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("idle", []byte("i")); err != nil {
+				t.Fatal(err)
+			}
+
+			// Each read or touch gives its session another 30 minutes
+			for i := 0; i < 4; i++ {
+				clock.Advance(20 * time.Minute)
+				if _, err := store.Get("active"); err != nil {
+					t.Fatalf("after %d minutes: expected the active session, got %v", 20*(i+1), err)
+				}
+				if err := store.Touch("touched"); err != nil {
+					t.Fatalf("after %d minutes: expected to touch the session, got %v", 20*(i+1), err)
+				}
+			}
+			if _, err := store.Get("idle"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected the idle session to expire, got %v", err)
+			}
+
+			clock.Advance(31 * time.Minute)
+			for _, id := range []string{"active", "touched"} {
+				if _, err := store.Get(id); !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected %s to expire once unused, got %v", id, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionStore_Operations(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	store := NewSessionStore(cache, time.Minute)
+
+	data := []byte("user=42")
+	if err := store.Set("s1", data); err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'X' // The caller's slice is not kept
+	got, err := store.Get("s1")
+	if err != nil || string(got) != "user=42" {
+		t.Fatalf("expected user=42, got %q, %v", got, err)
+	}
+	got[0] = 'Y' // Nor is the returned one shared
+	if got, _ := store.Get("s1"); string(got) != "user=42" {
+		t.Errorf("expected the stored data unchanged, got %q", got)
+	}
+
+	if err := store.Delete("s1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("s1"); err != nil {
+		t.Errorf("expected deleting an unknown session to succeed, got %v", err)
+	}
+	if _, err := store.Get("s1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.Touch("s1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected Touch to report ErrNotFound, got %v", err)
+	}
+	if err := store.Set("", data); err == nil {
+		t.Error("expected an empty ID to be refused")
+	}
+	if _, ok := cache.Get("s1"); ok {
+		t.Error("expected sessions under their own prefix")
+	}
+}
+
+func TestSessionStore_Commit(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		TTL:            time.Hour,
+		EvictionPolicy: "wtinylfu",
+		Clock:          clock,
+	})
+	defer cache.Close()
+	store := NewSessionStore(cache, time.Minute)
+
+	if err := store.Commit("tok", []byte("x"), clock.Now().Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(9 * time.Minute)
+	if b, found, err := store.Find("tok"); !found || err != nil || string(b) != "x" {
+		t.Fatalf("expected the session until its expiry, got %q, %v, %v", b, found, err)
+	}
+	clock.Advance(2 * time.Minute)
+	if _, found, err := store.Find("tok"); found || err != nil {
+		t.Errorf("expected the session gone at its expiry, reads do not extend it, got %v, %v", found, err)
+	}
+
+	_ = store.Commit("tok", []byte("x"), clock.Now().Add(time.Minute))
+	if err := store.Commit("tok", []byte("x"), clock.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := store.Find("tok"); found {
+		t.Error("expected a past expiry to delete the session")
+	}
+}
+
+func TestSessionStore_Encryption(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	enc, err := NewAESGCMEncryptor(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewSessionStore(cache, time.Minute, WithSessionEncryptor(enc))
+
+	if err := store.Set("s", []byte("secret payload")); err != nil {
+		t.Fatal(err)
+	}
+	raw, ok := cache.GetBytes(sessionKeyPrefix + "s")
+	if !ok || bytes.Contains(raw, []byte("secret")) {
+		t.Errorf("expected the payload stored encrypted, got %q", raw)
+	}
+	if got, err := store.Get("s"); err != nil || string(got) != "secret payload" {
+		t.Errorf("expected the payload decrypted, got %q, %v", got, err)
+	}
+
+	// A store with another key cannot read the session
+	other, _ := NewAESGCMEncryptor(bytes.Repeat([]byte{8}, 32))
+	if _, err := NewSessionStore(cache, time.Minute, WithSessionEncryptor(other)).Get("s"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a decryption error, got %v", err)
+	}
+	if _, err := NewAESGCMEncryptor([]byte("short")); err == nil {
+		t.Error("expected an invalid key size to be refused")
+	}
+}
+
+func TestNewSessionID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id, err := NewSessionID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != 43 || seen[id] {
+			t.Fatalf("expected distinct 43 character IDs, got %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+// ExampleSessionStore serves a page counting the visits of each session, keeping the
+// session ID in a cookie
+func ExampleSessionStore() {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10000, TTL: time.Hour})
+	defer cache.Close()
+	sessions := NewSessionStore(cache, 30*time.Minute)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string
+		var visits []byte
+		if cookie, err := r.Cookie("session"); err == nil {
+			id = cookie.Value
+			visits, _ = sessions.Get(id) // ErrNotFound starts a new session
+		}
+		if visits == nil {
+			var err error
+			if id, err = NewSessionID(); err != nil {
+				http.Error(w, "cannot create a session", http.StatusInternalServerError)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: id, HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode})
+		}
+		visits = append(visits, '.')
+		if err := sessions.Set(id, visits); err != nil {
+			http.Error(w, "cannot save the session", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "visit %d", len(visits))
+	})
+
+	// Three requests from a browser keeping the cookie
+	var cookies []*http.Cookie
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if set := rec.Result().Cookies(); len(set) > 0 {
+			cookies = set
+		}
+		body, _ := io.ReadAll(rec.Body)
+		fmt.Println(string(body))
+	}
+	// Output:
+	// visit 1
+	// visit 2
+	// visit 3
+}
@@ -0,0 +1,57 @@
+// shards_test.go: Tests for the shard count derived from GOMAXPROCS
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAutoShardCount(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	for _, tc := range []struct{ procs, shards int }{
+		{1, 4}, {2, 8}, {3, 16}, {4, 16}, {12, 64}, {64, 256}, {96, 256},
+	} {
+		runtime.GOMAXPROCS(tc.procs)
+		for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10000, EvictionPolicy: policy})
+			if got := cache.EffectiveConfig().ShardCount; got != tc.shards {
+				t.Errorf("GOMAXPROCS=%d %s: expected %d shards, got %d", tc.procs, policy, tc.shards, got)
+			}
+			// Both paths agree on the derived count
+			switch {
+			case cache.wtinylfu != nil && len(cache.wtinylfu.shards) != tc.shards:
+				t.Errorf("GOMAXPROCS=%d: expected W-TinyLFU to get %d shards, got %d", tc.procs, tc.shards, len(cache.wtinylfu.shards))
+			case cache.arc != nil && len(cache.arc.shards) != tc.shards:
+				t.Errorf("GOMAXPROCS=%d: expected ARC to get %d shards, got %d", tc.procs, tc.shards, len(cache.arc.shards))
+			case len(cache.shards) != tc.shards:
+				t.Errorf("GOMAXPROCS=%d: expected %d sharded-path shards, got %d", tc.procs, tc.shards, len(cache.shards))
+			}
+			cache.Close()
+		}
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, ShardCount: 8, EvictionPolicy: "lru"})
+	defer cache.Close()
+
+	config := cache.EffectiveConfig()
+	if config.ShardCount != 8 || config.CacheSize != 10000 || config.TTL != defaultTTL {
+		t.Errorf("expected the explicit shard count and the filled-in defaults, got %+v", config)
+	}
+
+	ttl := time.Minute
+	if err := cache.UpdateConfig(ConfigPatch{TTL: &ttl}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.EffectiveConfig().TTL; got != ttl {
+		t.Errorf("expected EffectiveConfig to reflect UpdateConfig, got TTL %v", got)
+	}
+}
@@ -0,0 +1,244 @@
+// size.go: Value size estimation for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding"
+	"encoding/gob"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+)
+
+// sizePlan is what estimateSize memoizes per type
+type sizePlan struct {
+	walkable bool // The gob size can be derived from the value (see walkableType)
+	overhead int  // gob's fixed cost beyond the walked size: type descriptors and framing
+}
+
+// sizePlans memoizes a *sizePlan per reflect.Type, and structFields the indexes of
+// the exported fields per struct type
+var sizePlans, structFields sync.Map
+
+// Types whose gob encoding is their own (time.Time, for one), which only gob can size
+var (
+	gobEncoderType      = reflect.TypeOf((*gob.GobEncoder)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// interfaceOverhead is the type id, length and framing gob adds to a value held in an
+// interface, besides the name of its concrete type
+const interfaceOverhead = 4
+
+// estimateSize returns the size of value's gob encoding without encoding it, for
+// values made of primitives, strings, slices, arrays, maps, pointers, interfaces and
+// structs of those. The walked size is exact for the encoded data; type descriptors
+// are measured once per type, so the estimate is within a few bytes of gob for most
+// values and within 25% for interface-heavy ones, whose descriptors vary with the
+// values held. ok is false for types only gob can size: recursive types and types
+// with their own encoding.
+func estimateSize(value interface{}) (size int, ok bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	plan := planFor(v.Type())
+	if !plan.walkable {
+		return 0, false
+	}
+	n, ok := walkSize(v)
+	return plan.overhead + n, ok
+}
+
+// planFor returns the memoized plan of t, measuring its gob overhead on first use
+func planFor(t reflect.Type) *sizePlan {
+	if plan, ok := sizePlans.Load(t); ok {
+		return plan.(*sizePlan)
+	}
+	plan := &sizePlan{walkable: walkableType(t, map[reflect.Type]bool{})}
+	if plan.walkable {
+		zero := reflect.Zero(t)
+		buf := getBuffer()
+		if err := gob.NewEncoder(buf).EncodeValue(zero); err != nil {
+			plan.walkable = false
+		} else if n, ok := walkSize(zero); ok {
+			plan.overhead = buf.Len() - n
+		}
+		putBuffer(buf)
+	}
+	actual, _ := sizePlans.LoadOrStore(t, plan)
+	return actual.(*sizePlan)
+}
+
+// exportedFields returns the memoized indexes of the exported fields of struct type t
+func exportedFields(t reflect.Type) []int {
+	if fields, ok := structFields.Load(t); ok {
+		return fields.([]int)
+	}
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			fields = append(fields, i)
+		}
+	}
+	structFields.Store(t, fields)
+	return fields
+}
+
+// walkableType reports whether values of t can be sized by walkSize. inProgress holds
+// the types being checked, so recursive types are left to gob.
+func walkableType(t reflect.Type, inProgress map[reflect.Type]bool) bool {
+	if inProgress[t] {
+		return false
+	}
+	for _, it := range []reflect.Type{gobEncoderType, binaryMarshalerType, textMarshalerType} {
+		if t.Implements(it) || reflect.PointerTo(t).Implements(it) {
+			return false
+		}
+	}
+
+	inProgress[t] = true
+	defer delete(inProgress, t)
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return walkableType(t.Elem(), inProgress)
+	case reflect.Map:
+		return walkableType(t.Key(), inProgress) && walkableType(t.Elem(), inProgress)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && !walkableType(f.Type, inProgress) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false // Channels, functions and unsafe pointers
+	}
+}
+
+// walkSize returns the size of v's encoded data as gob writes it, without type
+// descriptors. ok is false when an interface holds a value only gob can size.
+func walkSize(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return 1, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		return uintSize(uint64(i<<1) ^ uint64(i>>63)), true // nosec G115 - gob's zig-zag encoding
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintSize(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return floatSize(v.Float()), true
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return floatSize(real(c)) + floatSize(imag(c)), true
+	case reflect.String:
+		return uintSize(uint64(v.Len())) + v.Len(), true
+	case reflect.Slice, reflect.Array:
+		n := uintSize(uint64(v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return n + v.Len(), true
+		}
+		for i := 0; i < v.Len(); i++ {
+			s, ok := walkSize(v.Index(i))
+			if !ok {
+				return 0, false
+			}
+			n += s
+		}
+		return n, true
+	case reflect.Map:
+		n := uintSize(uint64(v.Len()))
+		for it := v.MapRange(); it.Next(); {
+			k, ok := walkSize(it.Key())
+			if !ok {
+				return 0, false
+			}
+			e, ok := walkSize(it.Value())
+			if !ok {
+				return 0, false
+			}
+			n += k + e
+		}
+		return n, true
+	case reflect.Ptr:
+		if v.IsNil() {
+			return 0, true
+		}
+		return walkSize(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 1, true
+		}
+		concrete := v.Elem()
+		if !planFor(concrete.Type()).walkable {
+			return 0, false
+		}
+		s, ok := walkSize(concrete)
+		name := len(concrete.Type().String())
+		return uintSize(uint64(name)) + name + interfaceOverhead + s, ok
+	case reflect.Struct:
+		n := 1 // End of struct
+		for _, i := range exportedFields(v.Type()) {
+			f := v.Field(i)
+			if gobOmits(f) {
+				continue
+			}
+			s, ok := walkSize(f)
+			if !ok {
+				return 0, false
+			}
+			n += 1 + s // Field delta and value
+		}
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// gobOmits reports whether gob leaves struct field f out: nil pointers and interfaces,
+// zero numbers and empty strings, slices and maps. Structs and arrays are always sent.
+func gobOmits(f reflect.Value) bool {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return true
+		}
+		f = f.Elem()
+	}
+	switch f.Kind() {
+	case reflect.Struct, reflect.Array:
+		return false
+	case reflect.String, reflect.Slice, reflect.Map:
+		return f.Len() == 0
+	default:
+		return f.IsZero()
+	}
+}
+
+// uintSize is the length of x in gob's unsigned integer encoding
+func uintSize(x uint64) int {
+	if x < 0x80 {
+		return 1
+	}
+	return 1 + (bits.Len64(x)+7)/8
+}
+
+// floatSize is the length of f in gob's floating point encoding, which byte-reverses
+// the bits so that common values with short mantissas encode short
+func floatSize(f float64) int {
+	return uintSize(bits.ReverseBytes64(math.Float64bits(f)))
+}
@@ -0,0 +1,137 @@
+// size_test.go: Tests for value size estimation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/gob"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type sizedInner struct {
+	A int
+	B string
+	C []float64
+}
+
+type sizedOuter struct {
+	Name   string
+	Inner  sizedInner
+	Ptr    *sizedInner
+	Counts map[string]int
+	List   []sizedInner
+	Any    interface{}
+	Raw    []byte
+	hidden int
+}
+
+// sizedNode is recursive, so only gob can size it
+type sizedNode struct {
+	Value int
+	Next  *sizedNode
+}
+
+func init() {
+	gob.Register(sizedInner{})
+	gob.Register(sizedNode{})
+}
+
+// gobSize is what calculateSize returned for every non-primitive value before estimateSize
+func gobSize(t *testing.T, value interface{}) int {
+	t.Helper()
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := gob.NewEncoder(buf).Encode(value); err != nil {
+		t.Fatalf("gob %T: %v", value, err)
+	}
+	return buf.Len()
+}
+
+func TestEstimateSize_MatchesGob(t *testing.T) {
+	exact := []interface{}{
+		sizedInner{A: 1, B: "x"},
+		sizedInner{A: -123456, B: "hello world", C: []float64{0.5, 1, 3.14159}},
+		&sizedOuter{Name: "n", Inner: sizedInner{A: 5}, Ptr: &sizedInner{B: "p"}, Counts: map[string]int{"a": 1, "bb": 300}, List: []sizedInner{{A: 1}, {B: "q"}}, Raw: []byte("abc"), hidden: 7},
+		map[string]string{"a": "b", "c": "d"},
+		[]string{"a", "bb", "ccc"},
+		[]int{1, 2, 3, 1 << 40},
+		[3]uint16{1, 2, 300},
+		map[int][]string{1: {"a"}, 2: nil},
+		struct{ X complex128 }{complex(1, -2)},
+		struct {
+			I sizedInner
+			A [2]int
+			P *int
+			E []string
+		}{P: new(int), E: []string{}},
+	}
+	for _, value := range exact {
+		if got, want := calculateSize(value), gobSize(t, value); got != want {
+			t.Errorf("%T: expected the gob size %d, got %d", value, want, got)
+		}
+	}
+
+	// Interface descriptors vary with the values held
+	approximate := []interface{}{
+		sizedOuter{Name: "a", Any: "str"},
+		sizedOuter{Any: sizedInner{A: 9}},
+		map[string]interface{}{"a": 1, "b": "two", "c": 3.5, "d": []string{"x"}},
+		[]interface{}{1, "a", 2.5, true, nil},
+	}
+	for _, value := range approximate {
+		got, want := calculateSize(value), gobSize(t, value)
+		if diff := float64(got-want) / float64(want); diff < -0.25 || diff > 0.25 {
+			t.Errorf("%T: expected within 25%% of the gob size %d, got %d", value, want, got)
+		}
+	}
+}
+
+func TestEstimateSize_FallsBackToGob(t *testing.T) {
+	for _, value := range []interface{}{
+		time.Unix(1700000000, 0),
+		sizedNode{Value: 1, Next: &sizedNode{Value: 2}},
+		struct{ When time.Time }{time.Unix(1, 0)},
+		[]interface{}{sizedNode{Value: 1}},
+	} {
+		if _, ok := estimateSize(value); ok {
+			t.Errorf("%T: expected gob to size it", value)
+		}
+		if got, want := calculateSize(value), gobSize(t, value); got != want {
+			t.Errorf("%T: expected the gob size %d, got %d", value, want, got)
+		}
+	}
+	if got := calculateSize(struct{ hidden int }{1}); got != 0 {
+		t.Errorf("expected 0 for a value gob cannot encode, got %d", got)
+	}
+}
+
+func TestEstimateSize_MaxValueSize(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 10, EvictionPolicy: "lru", MaxValueSize: 100})
+	defer cache.Close()
+
+	if !cache.Set("small", sizedInner{A: 1, B: "x"}) {
+		t.Error("expected a small struct to be stored")
+	}
+	if err := cache.SetE("large", sizedInner{B: fmt.Sprintf("%0200d", 0)}); err != ErrValueTooLarge {
+		t.Errorf("expected ErrValueTooLarge, got %v", err)
+	}
+}
+
+func BenchmarkSet_MaxValueSize(b *testing.B) {
+	value := sizedOuter{Name: "bench", Inner: sizedInner{A: 42, B: "inner", C: []float64{1, 2}}, Counts: map[string]int{"a": 1}, List: []sizedInner{{A: 1}, {A: 2}}}
+	for _, maxValueSize := range []int{0, 1 << 20} {
+		b.Run(fmt.Sprintf("MaxValueSize=%d", maxValueSize), func(b *testing.B) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 1000, EvictionPolicy: "lru", MaxValueSize: maxValueSize})
+			defer cache.Close()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache.Set("k", value)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// sliding_test.go: Tests for sliding TTL expiration
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestSlidingTTL_ReadsKeepEntryAlive(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             100 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				SlidingTTL:      true,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.Set("session", "token")
+
+			// Read every 30ms for 500ms, well past the 100ms TTL
+			for elapsed := time.Duration(0); elapsed < 500*time.Millisecond; elapsed += 30 * time.Millisecond {
+				clock.Advance(30 * time.Millisecond)
+				if _, ok := cache.Get("session"); !ok {
+					t.Fatalf("expected the entry to survive while read, lost after %v", elapsed+30*time.Millisecond)
+				}
+			}
+
+			clock.Advance(90 * time.Millisecond)
+			if _, ok := cache.Get("session"); !ok {
+				t.Fatal("expected the entry to live until the TTL after the last read")
+			}
+			clock.Advance(101 * time.Millisecond)
+			if _, ok := cache.Get("session"); ok {
+				t.Error("expected the entry to expire 100ms after reads stop")
+			}
+		})
+	}
+}
+
+func TestSlidingTTL_DisabledKeepsFixedExpiration(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             100 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.Set("k", "v")
+			for i := 0; i < 3; i++ {
+				clock.Advance(30 * time.Millisecond)
+				cache.Get("k")
+			}
+			clock.Advance(20 * time.Millisecond)
+			if _, ok := cache.Get("k"); ok {
+				t.Error("expected reads not to extend a fixed TTL")
+			}
+		})
+	}
+}
+
+func TestWithSlidingTTL_PerEntry(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             100 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			cache.SetWithOptions("idle", "v", WithSlidingTTL(50*time.Millisecond))
+			cache.SetWithOptions("default", "v", WithSlidingTTL(0))
+			cache.Set("fixed", "v")
+
+			for i := 0; i < 5; i++ {
+				clock.Advance(40 * time.Millisecond)
+				for _, key := range []string{"idle", "default"} {
+					if _, ok := cache.Get(key); !ok {
+						t.Fatalf("expected %s to survive while read (round %d)", key, i)
+					}
+				}
+			}
+			if _, ok := cache.Get("fixed"); ok {
+				t.Error("expected the entry without the option to expire at its fixed TTL")
+			}
+
+			clock.Advance(60 * time.Millisecond)
+			if _, ok := cache.Get("idle"); ok {
+				t.Error("expected idle to expire 50ms after its last read")
+			}
+			if _, ok := cache.Get("default"); !ok {
+				t.Error("expected WithSlidingTTL(0) to slide the 100ms cache TTL")
+			}
+		})
+	}
+}
+
+func TestSlidingTTL_WarmAndBytes(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      4,
+				TTL:             100 * time.Millisecond,
+				CleanupInterval: time.Hour,
+				EvictionPolicy:  policy,
+				SlidingTTL:      true,
+				Clock:           clock,
+			})
+			defer cache.Close()
+			if _, err := cache.Warm([]WarmEntry{{Key: "warm", Value: "v"}}); err != nil {
+				t.Fatalf("Warm: %v", err)
+			}
+			cache.SetBytes("bytes", []byte("v"))
+
+			for i := 0; i < 5; i++ {
+				clock.Advance(60 * time.Millisecond)
+				if _, ok := cache.Get("warm"); !ok {
+					t.Fatalf("expected the warmed entry to slide (round %d)", i)
+				}
+				if _, ok := cache.GetBytes("bytes"); !ok {
+					t.Fatalf("expected the SetBytes entry to slide (round %d)", i)
+				}
+			}
+		})
+	}
+}
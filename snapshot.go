@@ -0,0 +1,391 @@
+// snapshot.go: Snapshot persistence for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Snapshot file layout, all integers varint-encoded unless noted:
+//
+//	magic "METISNAP", version byte, shard count
+//	per shard: entry count, then per entry:
+//	    key length, key, expiration (UnixNano, 0 = never), flags byte, type tag byte,
+//	    value length, value bytes
+//	CRC-32 (IEEE) of everything above, 4 bytes big-endian
+const (
+	snapshotMagic   = "METISNAP"
+	snapshotVersion = 1
+
+	snapshotCompressed = 1 << 0 // The value is a compressed frame written by Set
+)
+
+// snapshotRecord is an unexpired entry copied out of a shard for SaveToFile
+type snapshotRecord struct {
+	key        string
+	value      interface{}
+	expiresAt  int64 // UnixNano, 0 = never
+	compressed bool
+}
+
+// SaveToFile writes every unexpired entry to path, shard by shard, so that LoadFromFile can
+// warm a new cache after a restart. Values are stored as bytes together with their type;
+// non-primitive values are encoded with the cache's Serializer and must be registered with
+// gob when it is the default. The file is written next to path and renamed into place,
+// so a failed save never leaves a partial snapshot behind.
+func (sc *StrategicCache) SaveToFile(path string) error {
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return ErrCacheClosed
+	}
+	sc.closedMu.RUnlock()
+
+	return sc.writeSnapshotFile(path)
+}
+
+// writeSnapshotFile writes a snapshot to a temporary file and renames it to path
+func (sc *StrategicCache) writeSnapshotFile(path string) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	crc := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(f, crc))
+	if err := sc.writeSnapshot(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := binary.Write(f, binary.BigEndian, crc.Sum32()); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// writeSnapshot writes the header and every shard's entries to w
+func (sc *StrategicCache) writeSnapshot(w *bufio.Writer) error {
+	shards := sc.snapshotShards()
+	var pieces map[string]chunkPiece
+	if sc.config.ChunkThreshold > 0 {
+		pieces = chunksOf(shards)
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		w.Write(scratch[:binary.PutUvarint(scratch[:], v)])
+	}
+
+	w.WriteString(snapshotMagic)
+	w.WriteByte(snapshotVersion)
+	putUvarint(uint64(len(shards)))
+	for _, records := range shards {
+		// Chunked values are saved whole, and split again when loaded
+		for i, r := range records {
+			if m, ok := r.value.(*chunkManifest); ok {
+				if value, err := sc.assembleFrom(r.key, m, pieces); err == nil {
+					records[i].value = value
+				}
+			}
+		}
+		records = slices.DeleteFunc(records, func(r snapshotRecord) bool { return isInternal(r.value) })
+		putUvarint(uint64(len(records)))
+		for _, r := range records {
+			var flags, tag byte
+			var payload []byte
+			if data, ok := r.value.([]byte); ok && r.compressed {
+				flags, payload = snapshotCompressed, data
+			} else {
+				var err error
+				if tag, payload, err = encodeTyped(r.value, sc.serializer); err != nil {
+					return fmt.Errorf("%w: key %q: %v", ErrNotSerializable, r.key, err)
+				}
+			}
+
+			putUvarint(uint64(len(r.key)))
+			w.WriteString(r.key)
+			w.Write(scratch[:binary.PutVarint(scratch[:], r.expiresAt)])
+			w.WriteByte(flags)
+			w.WriteByte(tag)
+			putUvarint(uint64(len(payload)))
+			if _, err := w.Write(payload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotShards copies the unexpired entries of each shard of the active storage path
+func (sc *StrategicCache) snapshotShards() [][]snapshotRecord {
+	fromEntries := func(entries []entrySnapshot) []snapshotRecord {
+		records := make([]snapshotRecord, len(entries))
+		for i, e := range entries {
+			records[i] = snapshotRecord{key: e.key, value: e.value, expiresAt: e.expiresAt}
+		}
+		return records
+	}
+
+	if sc.wtinylfu != nil {
+		shards := make([][]snapshotRecord, len(sc.wtinylfu.shards))
+		for i, shard := range sc.wtinylfu.shards {
+			shards[i] = fromEntries(shard.entries())
+		}
+		return shards
+	}
+	if sc.arc != nil {
+		shards := make([][]snapshotRecord, len(sc.arc.shards))
+		for i, shard := range sc.arc.shards {
+			shards[i] = fromEntries(shard.entries())
+		}
+		return shards
+	}
+
+	shards := make([][]snapshotRecord, len(sc.shards))
+	for i := range sc.shards {
+		shard := &sc.shards[i]
+		now := sc.clock.Now()
+		shard.mu.RLock()
+		records := make([]snapshotRecord, 0, len(shard.data))
+		for key, entry := range shard.data {
+			if expired, _ := sc.expiry(entry, now); expired {
+				continue
+			}
+			records = append(records, snapshotRecord{
+				key:        key,
+				value:      entry.Data,
+				expiresAt:  entry.Timestamp.UnixNano(),
+				compressed: entry.Compressed,
+			})
+		}
+		shard.mu.RUnlock()
+		shards[i] = records
+	}
+	return shards
+}
+
+// snapshotRoutine saves a snapshot to SnapshotPath every SnapshotInterval (if set)
+// and a final one when the cache is closed
+func (sc *StrategicCache) snapshotRoutine() {
+	defer sc.wg.Done()
+
+	var tick <-chan time.Time
+	if sc.config.SnapshotInterval > 0 {
+		ticker := time.NewTicker(sc.config.SnapshotInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			sc.backgroundSnapshot()
+		case <-sc.ctx.Done():
+			// Close waits for this snapshot before clearing the cache
+			sc.backgroundSnapshot()
+			return
+		}
+	}
+}
+
+// backgroundSnapshot saves a snapshot to SnapshotPath and reports the outcome
+func (sc *StrategicCache) backgroundSnapshot() {
+	err := sc.writeSnapshotFile(sc.config.SnapshotPath)
+
+	sc.snapshotMu.Lock()
+	sc.snapshotErr = err
+	handler := sc.onSnapshotError
+	sc.snapshotMu.Unlock()
+
+	if err == nil {
+		return
+	}
+	sc.logger.Error("metis: snapshot failed", "path", sc.config.SnapshotPath, "error", err)
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// OnSnapshotError sets a function called from the snapshot goroutine with the error of
+// each failed background snapshot; nil removes it
+func (sc *StrategicCache) OnSnapshotError(fn func(error)) {
+	sc.snapshotMu.Lock()
+	sc.onSnapshotError = fn
+	sc.snapshotMu.Unlock()
+}
+
+// LastSnapshotError returns the error from the most recent background snapshot to
+// SnapshotPath, or nil if it succeeded or none has run yet
+func (sc *StrategicCache) LastSnapshotError() error {
+	sc.snapshotMu.Lock()
+	defer sc.snapshotMu.Unlock()
+	return sc.snapshotErr
+}
+
+// LoadFromFile stores the entries of a snapshot written by SaveToFile and returns how many
+// were stored. Entries go through the same size limits and admission policy as Set, keep
+// their original expiration and are skipped once expired; entries saved without an
+// expiration get the cache TTL. The snapshot may come from a cache with a different
+// eviction policy or shard count. A truncated or corrupt file is rejected with
+// ErrInvalidSnapshot before any entry is stored.
+func (sc *StrategicCache) LoadFromFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	headerLen := len(snapshotMagic) + 1
+	if len(data) < headerLen+crc32.Size {
+		return 0, fmt.Errorf("%w: %s: file is truncated", ErrInvalidSnapshot, path)
+	}
+	if string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return 0, fmt.Errorf("%w: %s: not a metis snapshot", ErrInvalidSnapshot, path)
+	}
+	if version := data[len(snapshotMagic)]; version != snapshotVersion {
+		return 0, fmt.Errorf("%w: %s: unsupported version %d", ErrInvalidSnapshot, path, version)
+	}
+	body, sum := data[:len(data)-crc32.Size], binary.BigEndian.Uint32(data[len(data)-crc32.Size:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return 0, fmt.Errorf("%w: %s: checksum mismatch (truncated or corrupt file)", ErrInvalidSnapshot, path)
+	}
+
+	loaded, err := sc.readSnapshot(&snapshotReader{data: body[headerLen:]})
+	if err != nil {
+		return loaded, fmt.Errorf("%s: %w", path, err)
+	}
+	return loaded, nil
+}
+
+// readSnapshot stores the entries that follow the snapshot header
+func (sc *StrategicCache) readSnapshot(r *snapshotReader) (int, error) {
+	loaded := 0
+	shardCount := r.uvarint()
+	for s := uint64(0); s < shardCount && r.err == nil; s++ {
+		count := r.uvarint()
+		for i := uint64(0); i < count && r.err == nil; i++ {
+			key := string(r.bytes(r.uvarint()))
+			expiresAt := r.varint()
+			flags := r.byte()
+			tag := r.byte()
+			payload := r.bytes(r.uvarint())
+			if r.err != nil {
+				break
+			}
+			if expiresAt != 0 && sc.clock.Now().UnixNano() > expiresAt {
+				continue
+			}
+
+			var value interface{}
+			if flags&snapshotCompressed != 0 {
+				decoded, ok := decodeCompressed(payload, sc.serializer)
+				if !ok {
+					return loaded, fmt.Errorf("%w: key %q: cannot decompress value", ErrInvalidSnapshot, key)
+				}
+				value = decoded
+			} else {
+				decoded, err := decodeTyped(tag, payload, sc.serializer)
+				if err != nil {
+					return loaded, fmt.Errorf("%w: key %q: %v", ErrNotSerializable, key, err)
+				}
+				value = decoded
+			}
+
+			opts := defaultSetOptions
+			opts.expiresAt = expiresAt
+			if sc.config.SlidingTTL {
+				opts.slide = sc.entryTTL() // Reads restart the cache TTL
+			}
+			switch err := sc.setE(sc.HashKey(key), value, opts); {
+			case err == nil:
+				loaded++
+			case errors.Is(err, ErrCacheClosed), errors.Is(err, ErrCachingDisabled), errors.Is(err, ErrReadOnly):
+				return loaded, err
+			}
+		}
+	}
+	if r.err != nil {
+		return loaded, r.err
+	}
+	if len(r.data) != 0 {
+		return loaded, fmt.Errorf("%w: %d unexpected trailing bytes", ErrInvalidSnapshot, len(r.data))
+	}
+	return loaded, nil
+}
+
+// snapshotReader decodes snapshot fields, recording the first error instead of panicking
+// on malformed input; once err is set every read returns a zero value
+type snapshotReader struct {
+	data []byte
+	err  error
+}
+
+func (r *snapshotReader) fail(what string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: malformed %s", ErrInvalidSnapshot, what)
+	}
+	r.data = nil
+}
+
+func (r *snapshotReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail("length")
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *snapshotReader) varint() int64 {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail("expiration")
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *snapshotReader) byte() byte {
+	if len(r.data) < 1 {
+		r.fail("entry header")
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *snapshotReader) bytes(n uint64) []byte {
+	if n > uint64(len(r.data)) {
+		r.fail("entry")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
@@ -0,0 +1,331 @@
+// snapshot_test.go: Tests for SaveToFile and LoadFromFile snapshot persistence
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func snapshotTestConfig(policy string, compression bool, ttl time.Duration) CacheConfig {
+	return CacheConfig{
+		EnableCaching:     true,
+		CacheSize:         1000,
+		ShardCount:        4,
+		TTL:               ttl,
+		EvictionPolicy:    policy,
+		AdmissionPolicy:   "always",
+		EnableCompression: compression,
+	}
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	cases := []struct {
+		name        string
+		policy      string
+		compression bool
+	}{
+		{"lru", "lru", false},
+		{"lru-compressed", "lru", true},
+		{"wtinylfu", "wtinylfu", false},
+		{"arc", "arc", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.snap")
+			want := map[string]interface{}{
+				"string": "hello",
+				"int":    42,
+				"int64":  int64(-7),
+				"float":  3.5,
+				"bool":   true,
+				"bytes":  []byte{0, 1, 2},
+				"large":  string(bytes.Repeat([]byte("metis "), 500)),
+				"nil":    nil,
+			}
+
+			source := NewStrategicCache(snapshotTestConfig(tc.policy, tc.compression, time.Hour))
+			for key, value := range want {
+				if err := source.SetE(key, value); err != nil {
+					t.Fatalf("SetE(%q): %v", key, err)
+				}
+			}
+			if err := source.SaveToFile(path); err != nil {
+				t.Fatalf("SaveToFile: %v", err)
+			}
+			source.Close()
+
+			target := NewStrategicCache(snapshotTestConfig(tc.policy, tc.compression, time.Hour))
+			defer target.Close()
+			loaded, err := target.LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile: %v", err)
+			}
+			if loaded != len(want) {
+				t.Errorf("expected %d entries loaded, got %d", len(want), loaded)
+			}
+			for key, value := range want {
+				got, ok := target.Get(key)
+				if !ok {
+					t.Errorf("expected %q to be loaded", key)
+					continue
+				}
+				if fmt.Sprintf("%T %v", got, got) != fmt.Sprintf("%T %v", value, value) {
+					t.Errorf("%q: expected %T %v, got %T %v", key, value, value, got, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshot_AcrossPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	source := NewStrategicCache(snapshotTestConfig("lru", true, time.Hour))
+	for i := 0; i < 100; i++ {
+		source.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if err := source.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	source.Close()
+
+	target := NewStrategicCache(snapshotTestConfig("wtinylfu", false, time.Hour))
+	defer target.Close()
+	if loaded, err := target.LoadFromFile(path); err != nil || loaded != 100 {
+		t.Fatalf("expected 100 entries loaded, got %d (err %v)", loaded, err)
+	}
+	if v, ok := target.Get("k42"); !ok || v != 42 {
+		t.Errorf("expected k42=42, got %v (found %v)", v, ok)
+	}
+}
+
+func TestSnapshot_KeepsExpiration(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.snap")
+			clock := fakeclock.New(time.Time{})
+			config := func(ttl time.Duration) CacheConfig {
+				cfg := snapshotTestConfig(policy, false, ttl)
+				cfg.Clock = clock
+				return cfg
+			}
+
+			source := NewStrategicCache(config(100 * time.Millisecond))
+			source.Set("short", "lived")
+			if err := source.SaveToFile(path); err != nil {
+				t.Fatalf("SaveToFile: %v", err)
+			}
+			source.Close()
+
+			// The target's TTL must not extend the saved expiration
+			target := NewStrategicCache(config(time.Hour))
+			defer target.Close()
+			if loaded, err := target.LoadFromFile(path); err != nil || loaded != 1 {
+				t.Fatalf("expected 1 entry loaded, got %d (err %v)", loaded, err)
+			}
+			clock.Advance(150 * time.Millisecond)
+			if _, ok := target.Get("short"); ok {
+				t.Error("expected the loaded entry to expire at its saved expiration")
+			}
+
+			// Loading after the expiration skips the entry entirely
+			again := NewStrategicCache(config(time.Hour))
+			defer again.Close()
+			if loaded, err := again.LoadFromFile(path); err != nil || loaded != 0 {
+				t.Errorf("expected expired entries to be skipped, loaded %d (err %v)", loaded, err)
+			}
+		})
+	}
+}
+
+func TestSnapshot_AdmissionPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	source := NewStrategicCache(snapshotTestConfig("lru", false, time.Hour))
+	source.Set("small", "x")
+	source.Set("big", string(bytes.Repeat([]byte("x"), 2048)))
+	if err := source.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	source.Close()
+
+	config := snapshotTestConfig("lru", false, time.Hour)
+	config.MaxValueSize = 1024
+	target := NewStrategicCache(config)
+	defer target.Close()
+	loaded, err := target.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	if loaded != 1 {
+		t.Errorf("expected only the value within MaxValueSize to load, got %d", loaded)
+	}
+	if _, ok := target.Get("big"); ok {
+		t.Error("expected the oversized value to be rejected")
+	}
+}
+
+func TestSnapshot_TruncatedAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.snap")
+
+	source := NewStrategicCache(snapshotTestConfig("wtinylfu", false, time.Hour))
+	for i := 0; i < 20; i++ {
+		source.Set(fmt.Sprintf("k%d", i), fmt.Sprintf("value-%d", i))
+	}
+	if err := source.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	source.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := NewStrategicCache(snapshotTestConfig("wtinylfu", false, time.Hour))
+	defer target.Close()
+
+	broken := filepath.Join(dir, "broken.snap")
+	for n := 0; n < len(data); n++ {
+		if err := os.WriteFile(broken, data[:n], 0o600); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := target.LoadFromFile(broken)
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("truncated to %d bytes: expected ErrInvalidSnapshot, got %v", n, err)
+		}
+		if loaded != 0 {
+			t.Fatalf("truncated to %d bytes: expected nothing loaded, got %d", n, loaded)
+		}
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if err := os.WriteFile(broken, corrupt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := target.LoadFromFile(broken); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("expected ErrInvalidSnapshot for a corrupt file, got %v", err)
+	}
+	if keys := target.Keys(); len(keys) != 0 {
+		t.Errorf("expected rejected snapshots to leave the cache empty, got %v", keys)
+	}
+}
+
+func TestSnapshot_ClosedCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	cache := NewStrategicCache(snapshotTestConfig("wtinylfu", false, time.Hour))
+	cache.Set("k", "v")
+	if err := cache.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	cache.Close()
+
+	if err := cache.SaveToFile(path); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed from SaveToFile, got %v", err)
+	}
+	if _, err := cache.LoadFromFile(path); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed from LoadFromFile, got %v", err)
+	}
+}
+
+func TestSnapshot_Periodic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	config := snapshotTestConfig("wtinylfu", false, time.Hour)
+	config.SnapshotPath = path
+	config.SnapshotInterval = 20 * time.Millisecond
+	cache := NewStrategicCache(config)
+	defer cache.Close()
+
+	cache.Set("k", "v")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a background snapshot to be written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := cache.LastSnapshotError(); err != nil {
+		t.Errorf("expected no snapshot error, got %v", err)
+	}
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Errorf("expected no temporary files left behind, got %v", matches)
+	}
+}
+
+func TestSnapshot_OnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	config := snapshotTestConfig("lru", false, time.Hour)
+	config.SnapshotPath = path
+	cache := NewStrategicCache(config)
+	cache.Set("saved", "on close")
+	cache.Close()
+
+	restored := NewStrategicCache(snapshotTestConfig("lru", false, time.Hour))
+	defer restored.Close()
+	if loaded, err := restored.LoadFromFile(path); err != nil || loaded != 1 {
+		t.Fatalf("expected Close to save 1 entry, loaded %d (err %v)", loaded, err)
+	}
+	if v, ok := restored.Get("saved"); !ok || v != "on close" {
+		t.Errorf("expected saved=\"on close\", got %v (found %v)", v, ok)
+	}
+}
+
+func TestSnapshot_ReportsErrors(t *testing.T) {
+	reported := make(chan error, 16)
+	config := snapshotTestConfig("wtinylfu", false, time.Hour)
+	config.SnapshotPath = filepath.Join(t.TempDir(), "missing", "cache.snap")
+	config.SnapshotInterval = 10 * time.Millisecond
+	cache := NewStrategicCache(config)
+	defer cache.Close()
+	cache.OnSnapshotError(func(err error) {
+		select {
+		case reported <- err:
+		default:
+		}
+	})
+
+	select {
+	case err := <-reported:
+		if err == nil {
+			t.Error("expected a non-nil error from the callback")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected OnSnapshotError to be called")
+	}
+	if cache.LastSnapshotError() == nil {
+		t.Error("expected LastSnapshotError to report the failure")
+	}
+}
+
+func TestSnapshot_Validate(t *testing.T) {
+	config := CacheConfig{SnapshotInterval: time.Minute}
+	if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected SnapshotInterval without SnapshotPath to be invalid, got %v", err)
+	}
+	config = CacheConfig{SnapshotInterval: -time.Minute, SnapshotPath: "cache.snap"}
+	if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a negative SnapshotInterval to be invalid, got %v", err)
+	}
+	config = CacheConfig{SnapshotPath: "cache.snap"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected SnapshotPath alone to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// stats_delta.go: Differences between two reads of cache statistics
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "time"
+
+// CacheStatsDelta is what happened between two reads of CacheStats (see CacheStats.Delta)
+type CacheStatsDelta struct {
+	Hits               int64 `json:"hits"`
+	Misses             int64 `json:"misses"`
+	Evictions          int64 `json:"evictions"`
+	Expirations        int64 `json:"expirations"`
+	IdleExpirations    int64 `json:"idle_expirations"`
+	MemoryEvictedBytes int64 `json:"memory_evicted_bytes"`
+	NegativeHits       int64 `json:"negative_hits"`
+	CorruptedEntries   int64 `json:"corrupted_entries"`
+	ReadOnlyRejected   int64 `json:"read_only_rejected"`
+	// HitRate is Hits / (Hits + Misses) within the interval, 0 without lookups
+	HitRate float64 `json:"hit_rate"`
+	// KeysGrowth and MemoryGrowth are the change of the Keys and MemoryBytes gauges,
+	// negative when the cache shrank
+	KeysGrowth   int   `json:"keys_growth"`
+	MemoryGrowth int64 `json:"memory_growth_bytes"`
+}
+
+// CacheStatsRates are the counters of a CacheStatsDelta per second
+type CacheStatsRates struct {
+	OpsPerSec         float64 `json:"ops_per_sec"` // Lookups: hits and misses
+	EvictionsPerSec   float64 `json:"evictions_per_sec"`
+	ExpirationsPerSec float64 `json:"expirations_per_sec"`
+}
+
+// Delta returns the change from prev, an earlier read, to s. A counter lower than in
+// prev was reset by ResetStats or a restart in between, so all of its value is counted
+// as new rather than going negative.
+func (s CacheStats) Delta(prev CacheStats) CacheStatsDelta {
+	d := CacheStatsDelta{
+		Hits:               counterDelta(prev.Hits, s.Hits),
+		Misses:             counterDelta(prev.Misses, s.Misses),
+		Evictions:          counterDelta(prev.Evictions, s.Evictions),
+		Expirations:        counterDelta(prev.Expirations, s.Expirations),
+		IdleExpirations:    counterDelta(prev.IdleExpirations, s.IdleExpirations),
+		MemoryEvictedBytes: counterDelta(prev.MemoryEvictedBytes, s.MemoryEvictedBytes),
+		NegativeHits:       counterDelta(prev.NegativeHits, s.NegativeHits),
+		CorruptedEntries:   counterDelta(prev.CorruptedEntries, s.CorruptedEntries),
+		ReadOnlyRejected:   counterDelta(prev.ReadOnlyRejected, s.ReadOnlyRejected),
+		KeysGrowth:         s.Keys - prev.Keys,
+		MemoryGrowth:       s.MemoryBytes - prev.MemoryBytes,
+	}
+	d.HitRate = hitRate(d.Hits, d.Misses)
+	return d
+}
+
+// Rates divides the counters by elapsed, the time between the two reads. It returns zero
+// rates when elapsed is not positive.
+func (d CacheStatsDelta) Rates(elapsed time.Duration) CacheStatsRates {
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return CacheStatsRates{}
+	}
+	return CacheStatsRates{
+		OpsPerSec:         float64(d.Hits+d.Misses) / secs,
+		EvictionsPerSec:   float64(d.Evictions) / secs,
+		ExpirationsPerSec: float64(d.Expirations) / secs,
+	}
+}
+
+// counterDelta is the growth of a counter between two reads. A counter that went down
+// was reset, so everything it holds is new.
+func counterDelta(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
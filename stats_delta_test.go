@@ -0,0 +1,62 @@
+// stats_delta_test.go: Tests for differences between reads of cache statistics
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsDelta_Interval(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy})
+			defer cache.Close()
+			cache.Set("a", 1)
+			cache.Get("a")
+			cache.Get("missing")
+			prev := cache.GetStats()
+
+			cache.Set("b", 2)
+			for i := 0; i < 3; i++ {
+				cache.Get("a")
+			}
+			cache.Get("missing")
+			d := cache.GetStats().Delta(prev)
+			if d.Hits != 3 || d.Misses != 1 || d.HitRate != 0.75 || d.KeysGrowth != 1 {
+				t.Errorf("expected 3 hits, 1 miss and 1 new key in the interval, got %+v", d)
+			}
+			if r := d.Rates(2 * time.Second); r.OpsPerSec != 2 || r.EvictionsPerSec != 0 {
+				t.Errorf("expected 2 ops/sec, got %+v", r)
+			}
+		})
+	}
+}
+
+func TestStatsDelta_CounterReset(t *testing.T) {
+	prev := CacheStats{Hits: 1000, Misses: 1000, Evictions: 50, Expirations: 9, Keys: 40, MemoryBytes: 2048}
+	cur := CacheStats{Hits: 30, Misses: 10, Evictions: 60, Expirations: 4, Keys: 10, MemoryBytes: 1024}
+	d := cur.Delta(prev)
+	if d.Hits != 30 || d.Misses != 10 || d.Expirations != 4 {
+		t.Errorf("expected reset counters to count from zero, got %+v", d)
+	}
+	if d.Evictions != 10 {
+		t.Errorf("expected 10 evictions, got %d", d.Evictions)
+	}
+	if d.KeysGrowth != -30 || d.MemoryGrowth != -1024 {
+		t.Errorf("expected gauges to shrink, got %d keys and %d bytes", d.KeysGrowth, d.MemoryGrowth)
+	}
+	if d.HitRate != 0.75 {
+		t.Errorf("expected a 0.75 hit rate in the interval, got %v", d.HitRate)
+	}
+	if r := d.Rates(0); r != (CacheStatsRates{}) {
+		t.Errorf("expected zero rates without elapsed time, got %+v", r)
+	}
+	if r := (CacheStats{}).Delta(CacheStats{}); r.HitRate != 0 {
+		t.Errorf("expected a zero hit rate without lookups, got %v", r.HitRate)
+	}
+}
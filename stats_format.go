@@ -0,0 +1,100 @@
+// stats_format.go: JSON and text forms of cache statistics
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hitRate returns hits / (hits + misses), or 0 without lookups
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// HitRate returns Hits / (Hits + Misses), or 0 without lookups
+func (s CacheStats) HitRate() float64 {
+	return hitRate(s.Hits, s.Misses)
+}
+
+// MarshalJSON encodes the fields under their json tags, plus the computed "hit_rate"
+func (s CacheStats) MarshalJSON() ([]byte, error) {
+	type fields CacheStats // Without this method
+	return json.Marshal(struct {
+		fields
+		HitRate float64 `json:"hit_rate"`
+	}{fields(s), s.HitRate()})
+}
+
+// String returns a one-line summary, such as
+// "1200 keys, 9500 hits, 500 misses (95.0% hit rate), 30 evictions, 12 expirations, 1.5 MB"
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d keys, %d hits, %d misses (%.1f%% hit rate), %d evictions, %d expirations, %.1f MB",
+		s.Keys, s.Hits, s.Misses, s.HitRate()*100, s.Evictions, s.Expirations, float64(s.MemoryBytes)/1024/1024)
+}
+
+// HitRate returns Hits / (Hits + Misses), or 0 without lookups
+func (s ShardStats) HitRate() float64 {
+	return hitRate(s.Hits, s.Misses)
+}
+
+// MarshalJSON encodes the fields under their json tags, plus the computed "hit_rate"
+func (s ShardStats) MarshalJSON() ([]byte, error) {
+	type fields ShardStats // Without this method
+	return json.Marshal(struct {
+		fields
+		HitRate float64 `json:"hit_rate"`
+	}{fields(s), s.HitRate()})
+}
+
+// String returns a one-line summary, such as "#3: 75 keys, 600 hits, 40 misses, 2 evictions"
+func (s ShardStats) String() string {
+	return fmt.Sprintf("#%d: %d keys, %d hits, %d misses, %d evictions", s.Index, s.Keys, s.Hits, s.Misses, s.Evictions)
+}
+
+// String returns a one-line summary, such as
+// "800 compressed entries (40 skipped), 1048576 bytes stored in 262144 (ratio 0.25)"
+func (cs CompressionStats) String() string {
+	return fmt.Sprintf("%d compressed entries (%d skipped), %d bytes stored in %d (ratio %.2f)",
+		cs.CompressedEntries, cs.SkippedEntries, cs.UncompressedBytes, cs.CompressedBytes, cs.Ratio())
+}
+
+// String returns a one-line summary, such as
+// "user:1: 512 bytes, cost 1, expires 2025-06-01T12:00:00Z, pinned, protected"
+func (e EntryInfo) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d bytes, cost %d", e.Key, e.Size, e.Cost)
+	if e.Compressed {
+		b.WriteString(", compressed")
+	}
+	if e.ExpiresAt.IsZero() {
+		b.WriteString(", never expires")
+	} else {
+		b.WriteString(", expires " + e.ExpiresAt.Format(time.RFC3339))
+	}
+	if e.SlidingTTL > 0 {
+		fmt.Fprintf(&b, ", sliding %v", e.SlidingTTL)
+	}
+	if e.Pinned {
+		b.WriteString(", pinned")
+	}
+	switch e.Priority {
+	case PriorityLow:
+		b.WriteString(", low priority")
+	case PriorityHigh:
+		b.WriteString(", high priority")
+	}
+	if e.Segment != "" {
+		b.WriteString(", " + e.Segment)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// stats_format_test.go: Tests for the JSON and text forms of cache statistics
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsFormat_JSON(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", ShardCount: 4})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("missing")
+
+	data, err := json.Marshal(cache.GetStats())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if fields["hits"] != 3.0 || fields["misses"] != 1.0 || fields["keys"] != 1.0 || fields["hit_rate"] != 0.75 {
+		t.Errorf("expected hits, misses, keys and hit_rate, got %s", data)
+	}
+	if _, ok := fields["entry_pool"].(map[string]interface{})["reuse_ratio"]; !ok {
+		t.Errorf("expected entry_pool with snake_case fields, got %s", data)
+	}
+
+	// The computed field does not get in the way of decoding
+	var decoded CacheStats
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != cache.GetStats() {
+		t.Errorf("expected the stats to decode back, got %+v (%v)", decoded, err)
+	}
+
+	shards := cache.ShardStats()
+	data, err = json.Marshal(shards)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var rates []struct {
+		HitRate float64 `json:"hit_rate"`
+	}
+	if err := json.Unmarshal(data, &rates); err != nil || len(rates) != len(shards) {
+		t.Fatalf("expected %d shards, got %s (%v)", len(shards), data, err)
+	}
+	for i, shard := range shards {
+		if rates[i].HitRate != shard.HitRate() {
+			t.Errorf("expected shard %d to report hit_rate %v, got %v", i, shard.HitRate(), rates[i].HitRate)
+		}
+	}
+}
+
+func TestStatsFormat_String(t *testing.T) {
+	stats := CacheStats{Keys: 1200, Hits: 9500, Misses: 500, Evictions: 30, Expirations: 12, MemoryBytes: 3 << 19}
+	if got, want := stats.String(), "1200 keys, 9500 hits, 500 misses (95.0% hit rate), 30 evictions, 12 expirations, 1.5 MB"; got != want {
+		t.Errorf("CacheStats.String() = %q, want %q", got, want)
+	}
+	if got := (CacheStats{}).String(); !strings.Contains(got, "(0.0% hit rate)") {
+		t.Errorf("expected a zero hit rate without lookups, got %q", got)
+	}
+
+	shard := ShardStats{Index: 3, Keys: 75, Hits: 600, Misses: 40, Evictions: 2}
+	if got, want := shard.String(), "#3: 75 keys, 600 hits, 40 misses, 2 evictions"; got != want {
+		t.Errorf("ShardStats.String() = %q, want %q", got, want)
+	}
+
+	compression := CompressionStats{CompressedEntries: 800, SkippedEntries: 40, UncompressedBytes: 1 << 20, CompressedBytes: 1 << 18}
+	if got, want := compression.String(), "800 compressed entries (40 skipped), 1048576 bytes stored in 262144 (ratio 0.25)"; got != want {
+		t.Errorf("CompressionStats.String() = %q, want %q", got, want)
+	}
+
+	info := EntryInfo{Key: "user:1", Size: 512, Cost: 1, ExpiresAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), Pinned: true, Segment: "protected"}
+	if got, want := info.String(), "user:1: 512 bytes, cost 1, expires 2025-06-01T12:00:00Z, pinned, protected"; got != want {
+		t.Errorf("EntryInfo.String() = %q, want %q", got, want)
+	}
+	info = EntryInfo{Key: "k", Size: 10, Cost: 1, Compressed: true, Priority: PriorityHigh}
+	if got, want := info.String(), "k: 10 bytes, cost 1, compressed, never expires, high priority"; got != want {
+		t.Errorf("EntryInfo.String() = %q, want %q", got, want)
+	}
+}
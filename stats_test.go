@@ -0,0 +1,239 @@
+// stats_test.go: Tests for cache statistics counters and per-shard stats
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestGetStats_EvictionsAndExpirations(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       4,
+		ShardCount:      1,
+		TTL:             50 * time.Millisecond,
+		CleanupInterval: time.Hour,
+		EvictionPolicy:  "lru",
+		Clock:           clock,
+	})
+	defer cache.Close()
+
+	for i := 0; i < 6; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	stats := cache.GetStats()
+	if stats.Evictions != 2 {
+		t.Errorf("expected 2 evictions, got %d", stats.Evictions)
+	}
+
+	clock.Advance(80 * time.Millisecond)
+	cache.Get("key5")       // expired on read
+	cache.cleanupExpired(0) // remaining entries expired by the sweeper
+
+	stats = cache.GetStats()
+	if stats.Expirations != 4 {
+		t.Errorf("expected 4 expirations, got %d", stats.Expirations)
+	}
+	if stats.Keys != 0 {
+		t.Errorf("expected empty cache, got %d keys", stats.Keys)
+	}
+}
+
+func TestShardStats_ClassicPath(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 40; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i)
+		cache.Get(fmt.Sprintf("key%d", i))
+	}
+
+	shards := cache.ShardStats()
+	if len(shards) != 4 {
+		t.Fatalf("expected 4 shard stats, got %d", len(shards))
+	}
+
+	var keys int
+	var hits int64
+	for i, s := range shards {
+		if s.Index != i {
+			t.Errorf("expected index %d, got %d", i, s.Index)
+		}
+		keys += s.Keys
+		hits += s.Hits
+	}
+	stats := cache.GetStats()
+	if keys != stats.Keys || hits != stats.Hits {
+		t.Errorf("shard totals (%d keys, %d hits) do not match GetStats (%d keys, %d hits)",
+			keys, hits, stats.Keys, stats.Hits)
+	}
+	if cache.PolicyName() != "lru" {
+		t.Errorf("expected policy lru, got %s", cache.PolicyName())
+	}
+}
+
+func TestShardStats_WTinyLFUPath(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     8,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 5000; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	shards := cache.ShardStats()
+	if len(shards) != 8 {
+		t.Fatalf("expected 8 shard stats, got %d", len(shards))
+	}
+	var keys int
+	for _, s := range shards {
+		keys += s.Keys
+	}
+	stats := cache.GetStats()
+	if keys != stats.Keys {
+		t.Errorf("shard keys %d do not match GetStats keys %d", keys, stats.Keys)
+	}
+	if stats.Evictions == 0 {
+		t.Error("expected W-TinyLFU to report evictions after overfilling")
+	}
+	if cache.PolicyName() != "wtinylfu" {
+		t.Errorf("expected policy wtinylfu, got %s", cache.PolicyName())
+	}
+}
+
+func TestShardStats_Closed(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, EvictionPolicy: "lru"})
+	cache.Close()
+	if stats := cache.ShardStats(); stats != nil {
+		t.Errorf("expected nil shard stats on closed cache, got %v", stats)
+	}
+}
+
+func TestMemoryBytes_ClassicPath(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      100,
+		ShardCount:     4,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+
+	cache.Set("a", "12345")       // 5 bytes
+	cache.Set("b", []byte("123")) // 3 bytes
+	if got := cache.GetStats().MemoryBytes; got != 8 {
+		t.Fatalf("expected 8 bytes, got %d", got)
+	}
+
+	// Update-in-place must not drift
+	for i := 0; i < 1000; i++ {
+		cache.Set("a", "1234567890") // 10 bytes
+		cache.Set("a", "12345")      // back to 5 bytes
+	}
+	if got := cache.GetStats().MemoryBytes; got != 8 {
+		t.Errorf("expected 8 bytes after in-place updates, got %d", got)
+	}
+
+	cache.Delete("a")
+	if got := cache.GetStats().MemoryBytes; got != 3 {
+		t.Errorf("expected 3 bytes after Delete, got %d", got)
+	}
+
+	cache.Clear()
+	if got := cache.GetStats().MemoryBytes; got != 0 {
+		t.Errorf("expected 0 bytes after Clear, got %d", got)
+	}
+}
+
+func TestMemoryBytes_ClassicPathEvictionAndExpiration(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       2,
+		ShardCount:      1,
+		TTL:             50 * time.Millisecond,
+		CleanupInterval: time.Hour,
+		EvictionPolicy:  "lru",
+		Clock:           clock,
+	})
+	defer cache.Close()
+
+	cache.Set("a", "1111")
+	cache.Set("b", "22")
+	cache.Set("c", "3") // evicts "a"
+	if got := cache.GetStats().MemoryBytes; got != 3 {
+		t.Errorf("expected 3 bytes after eviction, got %d", got)
+	}
+
+	clock.Advance(80 * time.Millisecond)
+	cache.Get("b")
+	cache.cleanupExpired(0)
+	if got := cache.GetStats().MemoryBytes; got != 0 {
+		t.Errorf("expected 0 bytes after expiration, got %d", got)
+	}
+}
+
+func TestMemoryBytes_WTinyLFUPath(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "0123456789") // 10 bytes each
+	}
+	if got := cache.GetStats().MemoryBytes; got != 100 {
+		t.Fatalf("expected 100 bytes, got %d", got)
+	}
+
+	// Promotions into the protected segment and in-place updates must not drift
+	for i := 0; i < 500; i++ {
+		cache.Get("key1")
+		cache.Set("key1", "01234")
+		cache.Set("key1", "0123456789")
+	}
+	if got := cache.GetStats().MemoryBytes; got != 100 {
+		t.Errorf("expected 100 bytes after updates, got %d", got)
+	}
+
+	cache.Delete("key1")
+	if got := cache.GetStats().MemoryBytes; got != 90 {
+		t.Errorf("expected 90 bytes after Delete, got %d", got)
+	}
+
+	cache.Clear()
+	if got := cache.GetStats().MemoryBytes; got != 0 {
+		t.Errorf("expected 0 bytes after Clear, got %d", got)
+	}
+}
+
+func TestMemoryBytes_WTinyLFUEvictions(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+	for i := 0; i < 1000; i++ {
+		wt.Set(fmt.Sprintf("key%d", i), "xx")
+	}
+	if got, want := wt.MemoryBytes(), int64(wt.Size()*2); got != want {
+		t.Errorf("expected %d bytes for %d entries, got %d", want, wt.Size(), got)
+	}
+}
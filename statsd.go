@@ -0,0 +1,167 @@
+// statsd.go: StatsD reporter for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statsdMaxPacket keeps each datagram within the payload of a 1500 byte Ethernet frame
+const statsdMaxPacket = 1432
+
+// StatsdOption customizes a reporter created by NewStatsdReporter
+type StatsdOption func(*statsdOptions)
+
+// statsdOptions holds the settings applied by StatsdOption
+type statsdOptions struct {
+	tags []string
+}
+
+// WithStatsdTags adds DogStatsD tags, such as "env:prod" or "canary", to every metric.
+// Plain StatsD servers do not understand tags; leave them out for those.
+func WithStatsdTags(tags ...string) StatsdOption {
+	return func(o *statsdOptions) { o.tags = append(o.tags, tags...) }
+}
+
+// StatsdReporter sends the statistics of a cache to a StatsD server over UDP
+type StatsdReporter struct {
+	cache  *StrategicCache
+	conn   net.Conn
+	prefix string
+	suffix string // "|#tags" for DogStatsD, or empty
+
+	prev   CacheStats
+	errors atomic.Int64
+
+	done    chan struct{}
+	exited  chan struct{}
+	stopped sync.Once
+}
+
+// NewStatsdReporter starts sending the statistics of c to the StatsD server at addr
+// ("host:port") every interval, with names starting with prefix (for example
+// "myapp.cache"). Each report sends:
+//
+//   - counters: hits, misses, evictions and expirations since the previous report
+//   - gauges: keys, memory_bytes and hit_rate
+//   - with StatsWindow set, the gauges window.hit_rate and window.ops_per_sec
+//
+// Failed sends are dropped and counted by Errors. The reporter stops when c is closed
+// or Stop is called.
+func NewStatsdReporter(c *StrategicCache, addr string, interval time.Duration, prefix string, opts ...StatsdOption) (*StatsdReporter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("statsd interval must be positive, got %v", interval)
+	}
+	var o statsdOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &StatsdReporter{
+		cache:  c,
+		conn:   conn,
+		prefix: prefix,
+		prev:   c.GetStats(),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		r.prefix += "."
+	}
+	if len(o.tags) > 0 {
+		r.suffix = "|#" + strings.Join(o.tags, ",")
+	}
+
+	c.wg.Add(1)
+	go r.run(interval)
+	return r, nil
+}
+
+// run reports on every tick until the reporter is stopped or the cache is closed
+func (r *StatsdReporter) run(interval time.Duration) {
+	defer r.cache.wg.Done()
+	defer close(r.exited)
+	defer r.conn.Close()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report()
+		case <-r.done:
+			return
+		case <-r.cache.ctx.Done():
+			return
+		}
+	}
+}
+
+// report sends one round of metrics, packing as many lines per datagram as fit
+func (r *StatsdReporter) report() {
+	stats := r.cache.GetStats()
+	d := stats.Delta(r.prev)
+	r.prev = stats
+
+	var packet []byte
+	line := func(name, value, kind string) {
+		l := r.prefix + name + ":" + value + "|" + kind + r.suffix
+		if len(packet) > 0 && len(packet)+1+len(l) > statsdMaxPacket {
+			r.send(packet)
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, l...)
+	}
+	count := func(name string, n int64) { line(name, strconv.FormatInt(n, 10), "c") }
+	gauge := func(name string, v float64) { line(name, strconv.FormatFloat(v, 'f', -1, 64), "g") }
+
+	count("hits", d.Hits)
+	count("misses", d.Misses)
+	count("evictions", d.Evictions)
+	count("expirations", d.Expirations)
+	gauge("keys", float64(stats.Keys))
+	gauge("memory_bytes", float64(stats.MemoryBytes))
+	gauge("hit_rate", stats.HitRate())
+	if r.cache.config.StatsWindow > 0 {
+		w := r.cache.WindowedStats()
+		gauge("window.hit_rate", w.HitRate)
+		gauge("window.ops_per_sec", w.OpsPerSec)
+	}
+	r.send(packet)
+}
+
+// send writes one datagram, counting a failure instead of reporting it: the next
+// report carries fresh gauges, and StatsD is lossy by design
+func (r *StatsdReporter) send(packet []byte) {
+	if _, err := r.conn.Write(packet); err != nil {
+		r.errors.Add(1)
+	}
+}
+
+// Errors returns the number of datagrams that could not be sent
+func (r *StatsdReporter) Errors() int64 {
+	return r.errors.Load()
+}
+
+// Stop stops the reporter and waits for it to exit. Closing the cache stops it too.
+func (r *StatsdReporter) Stop() {
+	r.stopped.Do(func() { close(r.done) })
+	<-r.exited
+}
@@ -0,0 +1,145 @@
+// statsd_test.go: Tests for the StatsD reporter
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenStatsd returns a UDP listener standing in for a StatsD server
+func listenStatsd(t *testing.T) net.PacketConn {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP unavailable: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	return pc
+}
+
+// readStatsd returns the lines of the next datagram received by pc
+func readStatsd(t *testing.T, pc net.PacketConn) []string {
+	t.Helper()
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no datagram received: %v", err)
+	}
+	return strings.Split(string(buf[:n]), "\n")
+}
+
+func TestStatsd_Report(t *testing.T) {
+	pc := listenStatsd(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", StatsWindow: time.Minute})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+
+	r, err := NewStatsdReporter(cache, pc.LocalAddr().String(), time.Hour, "app.cache", WithStatsdTags("env:test", "canary"))
+	if err != nil {
+		t.Fatalf("NewStatsdReporter: %v", err)
+	}
+	defer r.Stop()
+
+	// Counters start from the stats read when the reporter was created
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("missing")
+	r.report()
+	lines := readStatsd(t, pc)
+	for _, want := range []string{
+		"app.cache.hits:2|c|#env:test,canary",
+		"app.cache.misses:1|c|#env:test,canary",
+		"app.cache.evictions:0|c|#env:test,canary",
+		"app.cache.keys:1|g|#env:test,canary",
+		"app.cache.hit_rate:0.75|g|#env:test,canary",
+		"app.cache.window.hit_rate:0.75|g|#env:test,canary",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("expected %q, got %q", want, lines)
+		}
+	}
+
+	cache.Get("a")
+	r.report()
+	if lines := readStatsd(t, pc); !containsLine(lines, "app.cache.hits:1|c|#env:test,canary") {
+		t.Errorf("expected the second report to count one new hit, got %q", lines)
+	}
+}
+
+func TestStatsd_PlainStatsd(t *testing.T) {
+	pc := listenStatsd(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "wtinylfu"})
+	defer cache.Close()
+
+	r, err := NewStatsdReporter(cache, pc.LocalAddr().String(), time.Millisecond, "")
+	if err != nil {
+		t.Fatalf("NewStatsdReporter: %v", err)
+	}
+	defer r.Stop()
+	lines := readStatsd(t, pc)
+	if !containsLine(lines, "hits:0|c") || !containsLine(lines, "keys:0|g") {
+		t.Errorf("expected untagged metrics without a prefix, got %q", lines)
+	}
+	for _, l := range lines {
+		if strings.Contains(l, "window.") {
+			t.Errorf("expected no windowed metrics without StatsWindow, got %q", l)
+		}
+	}
+}
+
+func TestStatsd_SendFailuresAreCounted(t *testing.T) {
+	pc := listenStatsd(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "arc"})
+	defer cache.Close()
+	r, err := NewStatsdReporter(cache, pc.LocalAddr().String(), time.Hour, "app")
+	if err != nil {
+		t.Fatalf("NewStatsdReporter: %v", err)
+	}
+	defer r.Stop()
+
+	r.conn.Close()
+	r.report()
+	if r.Errors() != 1 {
+		t.Errorf("expected 1 failed send, got %d", r.Errors())
+	}
+}
+
+func TestStatsd_StopsWhenCacheCloses(t *testing.T) {
+	pc := listenStatsd(t)
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, EvictionPolicy: "lru"})
+	r, err := NewStatsdReporter(cache, pc.LocalAddr().String(), time.Millisecond, "app")
+	if err != nil {
+		t.Fatalf("NewStatsdReporter: %v", err)
+	}
+
+	cache.Close()
+	select {
+	case <-r.exited:
+	default:
+		t.Fatal("expected Close to wait for the reporter to exit")
+	}
+	r.Stop()
+	r.Stop()
+
+	if _, err := NewStatsdReporter(cache, pc.LocalAddr().String(), 0, "app"); err == nil {
+		t.Error("expected a zero interval to be rejected")
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, l := range lines {
+		if l == want {
+			return true
+		}
+	}
+	return false
+}
@@ -10,10 +10,13 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
 )
 
 func TestStrategicCache_GetWithExpiredEntry(t *testing.T) {
 	// Test Get with expired entry
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		CacheSize:       100,
 		TTL:             1 * time.Millisecond, // Very short TTL
@@ -22,6 +25,7 @@ func TestStrategicCache_GetWithExpiredEntry(t *testing.T) {
 		AdmissionPolicy: "always",
 		ShardCount:      1,
 		CleanupInterval: 1 * time.Second,
+		Clock:           clock,
 	}
 
 	cache := NewStrategicCache(config)
@@ -29,8 +33,8 @@ func TestStrategicCache_GetWithExpiredEntry(t *testing.T) {
 	// Set a value
 	cache.Set("test_key", "test_value")
 
-	// Wait for it to expire
-	time.Sleep(2 * time.Millisecond)
+	// Move past the TTL
+	clock.Advance(2 * time.Millisecond)
 
 	// Try to get the expired value
 	value, exists := cache.Get("test_key")
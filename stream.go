@@ -0,0 +1,243 @@
+// stream.go: Streaming of large values for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamChunkSize is the length of the chunks SetReader stores a stream in, and the
+// most it holds in memory besides them
+const streamChunkSize = 256 << 10
+
+// streamValue is the value stored by SetReader: the stream in chunks of streamChunkSize
+// bytes (the last one shorter), each compressed by codec when that made it smaller.
+// A chunk shorter than its place in the stream is compressed, any other is raw.
+type streamValue struct {
+	chunks [][]byte
+	size   int64 // Length of the stream
+	stored int64 // Sum of the chunk lengths, which calculateSize reports
+	codec  Codec // nil when compression is disabled
+}
+
+// isStreamed reports whether a stored value was written by SetReader
+func isStreamed(value interface{}) bool {
+	_, ok := value.(*streamValue)
+	return ok
+}
+
+// rawLen returns the length of chunk i once decompressed
+func (sv *streamValue) rawLen(i int) int {
+	if i < len(sv.chunks)-1 {
+		return streamChunkSize
+	}
+	return int(sv.size - int64(i)*streamChunkSize)
+}
+
+// bytes reassembles the stream, for reads through Get and GetBytes
+func (sv *streamValue) bytes() ([]byte, error) {
+	r := sv.reader()
+	defer r.Close()
+	out := make([]byte, sv.size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// reader returns a streamReader positioned at the start of the stream
+func (sv *streamValue) reader() *streamReader {
+	return &streamReader{sv: sv}
+}
+
+// streamReader decompresses a streamValue one chunk at a time as it is read. Gzip
+// chunks are decompressed straight into the caller's buffer; chunks of other codecs
+// are decompressed whole, so at most one chunk is held beyond the stored ones.
+type streamReader struct {
+	sv   *streamValue
+	next int          // Index of the next chunk to open
+	src  bytes.Reader // The current chunk, or its decompressed copy
+	gz   *gzip.Reader // Decompresses the current chunk when it is gzipped, else nil
+	cur  io.Reader    // &src or gz; nil between chunks
+}
+
+// Read implements io.Reader
+func (r *streamReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur != nil {
+			n, err := r.cur.Read(p)
+			if err == io.EOF {
+				r.release()
+				err = nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		if r.sv == nil || r.next == len(r.sv.chunks) {
+			return 0, io.EOF
+		}
+		if err := r.open(r.next); err != nil {
+			return 0, err
+		}
+		r.next++
+	}
+}
+
+// open makes chunk i the current one
+func (r *streamReader) open(i int) error {
+	chunk := r.sv.chunks[i]
+	switch _, isGzip := r.sv.codec.(gzipCodec); {
+	case len(chunk) == r.sv.rawLen(i):
+		r.src.Reset(chunk)
+		r.cur = &r.src
+	case isGzip && len(chunk) >= 2 && chunk[0] == 0x1f && chunk[1] == 0x8b:
+		r.src.Reset(chunk)
+		gz, err := getGzipReader(&r.src)
+		if err != nil {
+			return fmt.Errorf("metis: chunk %d of stream: %w", i, err)
+		}
+		r.gz, r.cur = gz, gz
+	default:
+		data, err := r.sv.codec.Decompress(chunk)
+		if err != nil {
+			return fmt.Errorf("metis: chunk %d of stream: %w", i, err)
+		}
+		if len(data) != r.sv.rawLen(i) {
+			return fmt.Errorf("metis: chunk %d of stream: decompressed to %d bytes, expected %d", i, len(data), r.sv.rawLen(i))
+		}
+		r.src.Reset(data)
+		r.cur = &r.src
+	}
+	return nil
+}
+
+// release ends the current chunk, returning its gzip reader to the pool
+func (r *streamReader) release() {
+	if r.gz != nil {
+		putGzipReader(r.gz)
+		r.gz = nil
+	}
+	r.src.Reset(nil)
+	r.cur = nil
+}
+
+// Close releases the reader; later reads report io.EOF
+func (r *streamReader) Close() error {
+	r.release()
+	r.sv = nil
+	return nil
+}
+
+// SetReader stores the size bytes read from r under key, for values too large to hold in
+// memory twice. The stream is read and stored in chunks, each compressed by the codec when
+// EnableCompression is set, so no buffer as large as the value is ever allocated. size must
+// be the exact length of the stream: MaxValueSize is checked against it before reading, a
+// shorter stream returns io.ErrUnexpectedEOF and a longer one ErrValueTooLarge. Read the
+// value back with GetReader; Get and GetBytes reassemble it in memory. The Backend is not
+// updated, and Range, OnEvict handlers and snapshots skip streamed values.
+func (sc *StrategicCache) SetReader(key string, r io.Reader, size int64) error {
+	if !sc.config.EnableCaching {
+		return ErrCachingDisabled
+	}
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return ErrCacheClosed
+	}
+	sc.closedMu.RUnlock()
+	if err := sc.checkWritable(); err != nil {
+		return err
+	}
+	if size < 0 {
+		return fmt.Errorf("metis: negative stream size %d", size)
+	}
+	if _, maxValueSize := sc.sizeLimits(); maxValueSize > 0 && size > int64(maxValueSize) {
+		return ErrValueTooLarge
+	}
+
+	sv := &streamValue{size: size}
+	if sc.config.EnableCompression {
+		sv.codec = sc.valueCodec()
+	}
+	var buf []byte // Reused across chunks when they are compressed
+	for remaining := size; remaining > 0; {
+		n := int(min(remaining, streamChunkSize))
+		var chunk []byte
+		if sv.codec != nil {
+			if buf == nil {
+				buf = make([]byte, min(size, streamChunkSize))
+			}
+			chunk = buf[:n]
+		} else {
+			chunk = make([]byte, n)
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("metis: reading stream for %s: %w", key, err)
+		}
+		if sv.codec != nil {
+			compressed, err := sv.codec.Compress(chunk)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrNotSerializable, err)
+			}
+			if len(compressed) < n {
+				chunk = compressed
+			} else {
+				chunk = bytes.Clone(chunk)
+			}
+		}
+		sv.chunks = append(sv.chunks, chunk)
+		sv.stored += int64(len(chunk))
+		remaining -= int64(n)
+	}
+	var extra [1]byte
+	if _, err := io.ReadFull(r, extra[:]); err == nil {
+		return fmt.Errorf("%w: stream for %s is longer than its declared %d bytes", ErrValueTooLarge, key, size)
+	}
+
+	if err := sc.setE(sc.HashKey(key), sv, defaultSetOptions); err != nil {
+		return err
+	}
+	sc.publishInvalidation(key)
+	return nil
+}
+
+// GetReader returns a reader over a value stored with SetReader. The reader decompresses
+// one chunk at a time as it is read, so a large value is never held in memory whole; it
+// keeps reading the value as it was when GetReader returned, even if key is overwritten
+// meanwhile. It reports false for missing keys and for values not stored by SetReader.
+// Close the reader to return its decompressor to the pool.
+func (sc *StrategicCache) GetReader(key string) (io.ReadCloser, bool) {
+	if !sc.config.EnableCaching {
+		return nil, false
+	}
+
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return nil, false
+	}
+	sc.closedMu.RUnlock()
+
+	data, _, ok := sc.getStored(sc.HashKey(key))
+	if !ok {
+		return nil, false
+	}
+	sv, ok := data.(*streamValue)
+	if !ok {
+		return nil, false
+	}
+	return sv.reader(), true
+}
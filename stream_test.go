@@ -0,0 +1,198 @@
+// stream_test.go: Tests for streaming large values
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// textStream yields n pseudo-random bytes drawn from a small alphabet, so they compress
+type textStream struct {
+	rng *rand.Rand
+	n   int64
+}
+
+func newTextStream(seed, n int64) *textStream {
+	return &textStream{rng: rand.New(rand.NewSource(seed)), n: n}
+}
+
+func (s *textStream) Read(p []byte) (int, error) {
+	if s.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.n {
+		p = p[:s.n]
+	}
+	s.rng.Read(p)
+	for i := range p {
+		p[i] = "acgt"[p[i]&3]
+	}
+	s.n -= int64(len(p))
+	return len(p), nil
+}
+
+// streamChecksum is the CRC32 of everything r yields
+func streamChecksum(t *testing.T, r io.Reader) (uint32, int64) {
+	t.Helper()
+	h := crc32.NewIEEE()
+	n, err := io.CopyBuffer(h, r, make([]byte, 32<<10))
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	return h.Sum32(), n
+}
+
+// wrappedGzip compresses like gzip under another codec ID, so chunks are decompressed whole
+type wrappedGzip struct{ gzipCodec }
+
+func (wrappedGzip) ID() byte { return 251 }
+
+func TestStream_RoundTrip(t *testing.T) {
+	if _, ok := LookupCodec("stream-test"); !ok {
+		if err := RegisterCodec("stream-test", wrappedGzip{}); err != nil {
+			t.Fatalf("RegisterCodec: %v", err)
+		}
+	}
+	size := int64(3*streamChunkSize + 123)
+	want, _ := streamChecksum(t, newTextStream(1, size))
+
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		for _, codec := range []string{"", "gzip", "stream-test"} {
+			name := policy + "/" + codec
+			if codec == "" {
+				name = policy + "/uncompressed"
+			}
+			t.Run(name, func(t *testing.T) {
+				cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: policy, EnableCompression: codec != "", CompressionCodec: codec})
+				defer cache.Close()
+
+				if err := cache.SetReader("blob", newTextStream(1, size), size); err != nil {
+					t.Fatalf("SetReader: %v", err)
+				}
+				r, ok := cache.GetReader("blob")
+				if !ok {
+					t.Fatal("expected GetReader to find the blob")
+				}
+				got, n := streamChecksum(t, r)
+				r.Close()
+				if got != want || n != size {
+					t.Errorf("expected %d bytes with CRC %08x, got %d with %08x", size, want, n, got)
+				}
+
+				value, ok := cache.Get("blob")
+				if b, isBytes := value.([]byte); !ok || !isBytes || int64(len(b)) != size || crc32.ChecksumIEEE(b) != want {
+					t.Errorf("expected Get to reassemble the blob, got %T (%v)", value, ok)
+				}
+				if b, ok := cache.GetBytes("blob"); !ok || crc32.ChecksumIEEE(b) != want {
+					t.Errorf("expected GetBytes to reassemble the blob, got %d bytes (%v)", len(b), ok)
+				}
+
+				cache.Set("plain", []byte("not streamed"))
+				if _, ok := cache.GetReader("plain"); ok {
+					t.Error("expected GetReader to ignore values stored by Set")
+				}
+				visited := 0
+				cache.Range(func(key string, value interface{}) bool {
+					visited++
+					if key == "blob" {
+						t.Error("expected Range to skip the streamed value")
+					}
+					return true
+				})
+				if visited != 1 {
+					t.Errorf("expected Range to visit 1 entry, got %d", visited)
+				}
+			})
+		}
+	}
+}
+
+func TestStream_LargeValueInChunks(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops pooled decompressors at random under the race detector")
+	}
+	const size = 20 << 20
+	want, _ := streamChecksum(t, newTextStream(7, size))
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", EnableCompression: true, DisableBackgroundCleanup: true})
+	defer cache.Close()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := cache.SetReader("artifact", newTextStream(7, size), size); err != nil {
+		t.Fatalf("SetReader: %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	// The chunks compress to under a third of the stream; a contiguous copy would be all of it
+	if n := after.TotalAlloc - before.TotalAlloc; n >= size/2 {
+		t.Errorf("expected SetReader to allocate less than half of the %d byte stream, got %d", size, n)
+	}
+	if mem := cache.GetStats().MemoryBytes; mem <= 0 || mem >= size/2 {
+		t.Errorf("expected the compressed chunks accounted in MemoryBytes, got %d", mem)
+	}
+
+	r, ok := cache.GetReader("artifact")
+	if !ok {
+		t.Fatal("expected GetReader to find the artifact")
+	}
+	runtime.ReadMemStats(&before)
+	got, n := streamChecksum(t, r)
+	runtime.ReadMemStats(&after)
+	r.Close()
+	if got != want || n != size {
+		t.Fatalf("expected %d bytes with CRC %08x, got %d with %08x", size, want, n, got)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc >= size/8 {
+		t.Errorf("expected reading to decompress chunk by chunk, allocated %d bytes", alloc)
+	}
+}
+
+// failingReader fails the test if it is read
+type failingReader struct{ t *testing.T }
+
+func (r failingReader) Read(p []byte) (int, error) {
+	r.t.Error("expected the stream not to be read")
+	return 0, io.EOF
+}
+
+func TestStream_DeclaredSize(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute, EvictionPolicy: "lru", MaxValueSize: 1 << 20})
+	defer cache.Close()
+
+	if err := cache.SetReader("big", failingReader{t}, 2<<20); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected ErrValueTooLarge for a declared size over MaxValueSize, got %v", err)
+	}
+	if err := cache.SetReader("short", bytes.NewReader(make([]byte, 100)), 200); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF for a short stream, got %v", err)
+	}
+	if err := cache.SetReader("long", bytes.NewReader(make([]byte, 300)), 200); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected ErrValueTooLarge for a stream longer than declared, got %v", err)
+	}
+	if err := cache.SetReader("negative", failingReader{t}, -1); err == nil {
+		t.Error("expected an error for a negative size")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected nothing stored, got %v", cache.Keys())
+	}
+
+	if err := cache.SetReader("empty", bytes.NewReader(nil), 0); err != nil {
+		t.Fatalf("SetReader of an empty stream: %v", err)
+	}
+	r, ok := cache.GetReader("empty")
+	if !ok {
+		t.Fatal("expected the empty stream stored")
+	}
+	if b, err := io.ReadAll(r); err != nil || len(b) != 0 {
+		t.Errorf("expected an empty stream, got %d bytes (%v)", len(b), err)
+	}
+}
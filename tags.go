@@ -0,0 +1,298 @@
+// tags.go: Tag-based invalidation for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// minTagStripeLimit is the fewest tagged keys a stripe holds before it is pruned
+const minTagStripeLimit = 64
+
+// tagIndex maps tags to the keys stored with them; the dependency index of WithDependsOn
+// is one too, with parent keys as tags. It is split into stripes by key, one
+// per cache shard, so that tagging keys of different shards does not contend. Keys leave
+// it when they are deleted, overwritten without tags, evicted or invalidated; keys that
+// expired are pruned by the cleanup sweep, and by a stripe outgrowing its limit.
+type tagIndex struct {
+	seed    maphash.Seed
+	stripes []tagStripe
+	limit   int // Keys a stripe holds before it is pruned of keys no longer cached
+}
+
+// tagStripe indexes the tagged keys of one stripe
+type tagStripe struct {
+	mu      sync.Mutex
+	keys    map[string]*taggedKey          // Key -> its tags
+	tags    map[string]map[string]struct{} // Tag -> keys of this stripe
+	pruneAt int                            // Number of keys that triggers the next prune
+}
+
+// taggedKey holds the tags of one Set; a later Set of the key replaces the pointer, so a
+// prune that saw the old one leaves the new one alone
+type taggedKey struct {
+	tags []string
+}
+
+// newTagIndex returns an index of stripes stripes for a cache of capacity entries
+func newTagIndex(stripes, capacity int) *tagIndex {
+	idx := &tagIndex{
+		seed:    maphash.MakeSeed(),
+		stripes: make([]tagStripe, max(1, stripes)),
+	}
+	idx.limit = max(minTagStripeLimit, 2*capacity/len(idx.stripes))
+	for i := range idx.stripes {
+		idx.stripes[i] = tagStripe{
+			keys:    make(map[string]*taggedKey),
+			tags:    make(map[string]map[string]struct{}),
+			pruneAt: idx.limit,
+		}
+	}
+	return idx
+}
+
+func (idx *tagIndex) stripe(key string) *tagStripe {
+	return &idx.stripes[maphash.String(idx.seed, key)%uint64(len(idx.stripes))]
+}
+
+// set replaces the tags of key, removing it from the index when tags is empty. It
+// reports whether the stripe has outgrown its limit and should be pruned.
+func (idx *tagIndex) set(key string, tags []string) bool {
+	s := idx.stripe(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unlinkLocked(key)
+	if len(tags) == 0 {
+		return false
+	}
+	tk := &taggedKey{tags: make([]string, 0, len(tags))}
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		if _, dup := keys[key]; !dup {
+			keys[key] = struct{}{}
+			tk.tags = append(tk.tags, tag)
+		}
+	}
+	s.keys[key] = tk
+	return len(s.keys) >= s.pruneAt
+}
+
+// remove drops key from the index
+func (idx *tagIndex) remove(key string) {
+	s := idx.stripe(key)
+	s.mu.Lock()
+	s.unlinkLocked(key)
+	s.mu.Unlock()
+}
+
+// take removes every key tagged with tag from the index and returns them
+func (idx *tagIndex) take(tag string) []string {
+	var taken []string
+	for i := range idx.stripes {
+		s := &idx.stripes[i]
+		s.mu.Lock()
+		start := len(taken)
+		for key := range s.tags[tag] {
+			taken = append(taken, key)
+		}
+		for _, key := range taken[start:] {
+			s.unlinkLocked(key)
+		}
+		s.mu.Unlock()
+	}
+	return taken
+}
+
+// hasTag reports whether any key is indexed under tag
+func (idx *tagIndex) hasTag(tag string) bool {
+	for i := range idx.stripes {
+		s := &idx.stripes[i]
+		s.mu.Lock()
+		_, ok := s.tags[tag]
+		s.mu.Unlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes key from the index unless live reports it cached again, as after an
+// eviction raced with a new Set
+func (idx *tagIndex) drop(key string, live func(string) bool) {
+	s := idx.stripe(key)
+	s.mu.Lock()
+	tk, ok := s.keys[key]
+	s.mu.Unlock()
+	if !ok || live(key) {
+		return
+	}
+	s.mu.Lock()
+	if s.keys[key] == tk {
+		s.unlinkLocked(key)
+	}
+	s.mu.Unlock()
+}
+
+// prune removes the keys of every stripe that live reports are no longer cached
+func (idx *tagIndex) prune(live func(string) bool) {
+	for i := range idx.stripes {
+		idx.pruneStripe(&idx.stripes[i], live)
+	}
+}
+
+// pruneKey prunes the stripe of key
+func (idx *tagIndex) pruneKey(key string, live func(string) bool) {
+	idx.pruneStripe(idx.stripe(key), live)
+}
+
+// pruneStripe checks the liveness of the stripe's keys outside its lock, then removes
+// the dead ones that no Set replaced meanwhile
+func (idx *tagIndex) pruneStripe(s *tagStripe, live func(string) bool) {
+	s.mu.Lock()
+	snapshot := make(map[string]*taggedKey, len(s.keys))
+	for key, tk := range s.keys {
+		snapshot[key] = tk
+	}
+	s.mu.Unlock()
+
+	var dead []string
+	for key := range snapshot {
+		if !live(key) {
+			dead = append(dead, key)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range dead {
+		if s.keys[key] == snapshot[key] {
+			s.unlinkLocked(key)
+		}
+	}
+	// Keys that are all live are not pruned again until the stripe doubles
+	s.pruneAt = max(idx.limit, 2*len(s.keys))
+}
+
+// clear empties the index
+func (idx *tagIndex) clear() {
+	for i := range idx.stripes {
+		s := &idx.stripes[i]
+		s.mu.Lock()
+		s.keys = make(map[string]*taggedKey)
+		s.tags = make(map[string]map[string]struct{})
+		s.pruneAt = idx.limit
+		s.mu.Unlock()
+	}
+}
+
+// counts returns the number of tagged keys and of distinct tags
+func (idx *tagIndex) counts() (keys, tags int) {
+	seen := make(map[string]struct{})
+	for i := range idx.stripes {
+		s := &idx.stripes[i]
+		s.mu.Lock()
+		keys += len(s.keys)
+		for tag := range s.tags {
+			seen[tag] = struct{}{}
+		}
+		s.mu.Unlock()
+	}
+	return keys, len(seen)
+}
+
+// unlinkLocked removes key and its tags from the stripe; the caller must hold s.mu
+func (s *tagStripe) unlinkLocked(key string) {
+	tk, ok := s.keys[key]
+	if !ok {
+		return
+	}
+	delete(s.keys, key)
+	for _, tag := range tk.tags {
+		keys := s.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// WithTags associates an entry with tags, so that InvalidateTag on any of them removes
+// it. A later Set of the key replaces its tags, and one without WithTags removes them.
+func WithTags(tags ...string) SetOption {
+	return func(o *setOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// InvalidateTag removes every entry stored with tag (see WithTags) and returns how many
+// were cached. Entries are removed from memory only: a Backend is not updated. An entry
+// stored with the tag while InvalidateTag runs may survive it.
+func (sc *StrategicCache) InvalidateTag(tag string) int {
+	sc.closedMu.RLock()
+	closed := sc.closed
+	sc.closedMu.RUnlock()
+	idx := sc.tags.Load()
+	if closed || idx == nil {
+		return 0
+	}
+
+	removed := 0
+	for _, key := range idx.take(tag) {
+		sc.traceOp(TraceDelete, key, nil)
+		if sc.deleteLocal(sc.HashKey(key)) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// tagKey records the tags and parents of a key just stored (see WithTags and WithDependsOn)
+func (sc *StrategicCache) tagKey(key string, opts setOptions) {
+	sc.indexKey(&sc.tags, key, opts.tags, sc.isLive)
+	sc.indexKey(&sc.deps, key, opts.dependsOn, sc.keepDependent)
+}
+
+// indexKey replaces the tags of key in the index held by p, creating the index on first
+// use. A stripe outgrowing its limit is pruned of the keys live rejects.
+func (sc *StrategicCache) indexKey(p *atomic.Pointer[tagIndex], key string, tags []string, live func(string) bool) {
+	idx := p.Load()
+	if idx == nil {
+		if len(tags) == 0 {
+			return
+		}
+		if p.CompareAndSwap(nil, newTagIndex(int(sc.shardCount), sc.config.CacheSize)) {
+			sc.installEvictHandler() // Evicted keys must leave the index
+		}
+		idx = p.Load()
+	}
+	if idx.set(key, tags) {
+		idx.pruneKey(key, live)
+	}
+}
+
+// untag removes a deleted key from the tag and dependency indexes, if any
+func (sc *StrategicCache) untag(key string) {
+	if idx := sc.tags.Load(); idx != nil {
+		idx.remove(key)
+	}
+	if idx := sc.deps.Load(); idx != nil {
+		idx.remove(key)
+	}
+}
+
+// isLive reports whether key is cached and unexpired, without counting an access
+func (sc *StrategicCache) isLive(key string) bool {
+	_, ok := sc.GetEntryInfo(key)
+	return ok
+}
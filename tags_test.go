@@ -0,0 +1,197 @@
+// tags_test.go: Tests for tag-based invalidation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			if n := cache.InvalidateTag("user:42"); n != 0 {
+				t.Errorf("expected nothing to invalidate before any tag, got %d", n)
+			}
+			for i := 0; i < 10; i++ {
+				cache.SetWithOptions(fmt.Sprintf("fragment:%d", i), i, WithTags("user:42"))
+			}
+			cache.SetWithOptions("profile", "p", WithTags("user:42", "org:7"))
+			cache.SetWithOptions("billing", "b", WithTags("org:7"))
+			cache.Set("untagged", "u")
+
+			if stats := cache.GetStats(); stats.TaggedKeys != 12 || stats.Tags != 2 {
+				t.Errorf("expected 12 tagged keys and 2 tags, got %+v", stats)
+			}
+			if n := cache.InvalidateTag("user:42"); n != 11 {
+				t.Errorf("expected 11 entries invalidated, got %d", n)
+			}
+			for _, key := range []string{"fragment:0", "fragment:9", "profile"} {
+				if _, ok := cache.Get(key); ok {
+					t.Errorf("expected %s to be invalidated", key)
+				}
+			}
+			for _, key := range []string{"billing", "untagged"} {
+				if _, ok := cache.Get(key); !ok {
+					t.Errorf("expected %s to survive", key)
+				}
+			}
+			// The profile left the org:7 index along with its entry
+			if stats := cache.GetStats(); stats.TaggedKeys != 1 || stats.Tags != 1 {
+				t.Errorf("expected only billing indexed, got %+v", stats)
+			}
+			if n := cache.InvalidateTag("org:7"); n != 1 {
+				t.Errorf("expected 1 entry invalidated, got %d", n)
+			}
+			if n := cache.InvalidateTag("org:7"); n != 0 {
+				t.Errorf("expected a second invalidation to find nothing, got %d", n)
+			}
+		})
+	}
+}
+
+func TestTags_OverwriteDeleteAndClear(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			cache.SetWithOptions("a", 1, WithTags("t"))
+			cache.Set("a", 2) // A Set without tags drops the old ones
+			cache.SetWithOptions("b", 1, WithTags("t"))
+			cache.SetWithOptions("b", 2, WithTags("u"))
+			if n := cache.InvalidateTag("t"); n != 0 {
+				t.Errorf("expected retagged keys to leave the old tag, got %d invalidated", n)
+			}
+			if v, _ := cache.Get("a"); v != 2 {
+				t.Errorf("expected a to survive, got %v", v)
+			}
+
+			cache.Delete("b")
+			if stats := cache.GetStats(); stats.TaggedKeys != 0 {
+				t.Errorf("expected Delete to drop the key from the index, got %+v", stats)
+			}
+
+			cache.SetWithOptions("c", 1, WithTags("t", "t"))
+			cache.Clear()
+			if stats := cache.GetStats(); stats.TaggedKeys != 0 || stats.Tags != 0 {
+				t.Errorf("expected Clear to empty the index, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestTags_EvictionsLeaveTheIndex(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      100,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+
+			var mu sync.Mutex
+			evicted := 0
+			cache.OnEvict(func(key string, value interface{}) {
+				mu.Lock()
+				evicted++
+				mu.Unlock()
+			})
+			for i := 0; i < 2000; i++ {
+				cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithTags(fmt.Sprintf("tag%d", i%10)))
+			}
+
+			stats := cache.GetStats()
+			if stats.TaggedKeys != cache.Len() {
+				t.Errorf("expected the index to hold the %d cached keys, got %d", cache.Len(), stats.TaggedKeys)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if evicted == 0 {
+				t.Error("expected OnEvict to keep working alongside tags")
+			}
+		})
+	}
+}
+
+func TestTags_ExpiredKeysArePruned(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:   true,
+				CacheSize:       1000,
+				ShardCount:      2,
+				EvictionPolicy:  policy,
+				TTL:             time.Minute,
+				CleanupInterval: 10 * time.Second,
+				Clock:           clock,
+			})
+			defer cache.Close()
+
+			for i := 0; i < 20; i++ {
+				cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithTags("t"))
+			}
+			clock.Advance(2 * time.Minute)
+
+			// The sweep runs on the cleanup goroutine, so keep ticking until it catches up
+			deadline := time.Now().Add(time.Second)
+			for cache.GetStats().TaggedKeys > 0 && time.Now().Before(deadline) {
+				clock.Advance(10 * time.Second)
+				time.Sleep(time.Millisecond)
+			}
+			if stats := cache.GetStats(); stats.TaggedKeys != 0 || stats.Tags != 0 {
+				t.Errorf("expected the sweep to prune expired keys from the index, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestTagIndex_BoundedWithoutSweeps(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:            true,
+		CacheSize:                1000,
+		ShardCount:               1,
+		EvictionPolicy:           "lru",
+		TTL:                      time.Millisecond,
+		DisableBackgroundCleanup: true,
+	})
+	defer cache.Close()
+
+	// Expired keys are never swept, so the stripe prunes itself as it fills up
+	for i := 0; i < 10000; i++ {
+		cache.SetWithOptions(fmt.Sprintf("k%d", i), i, WithTags("t"))
+		if i%1000 == 999 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	if keys := cache.GetStats().TaggedKeys; keys > 2*1000+minTagStripeLimit {
+		t.Errorf("expected the index to stay bounded, got %d keys", keys)
+	}
+}
@@ -0,0 +1,200 @@
+// tiered.go: Two-tier caching over a RemoteCache for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// RemoteCache is a larger, slower cache tier holding encoded values, such as another
+// process, a disk or Redis. Get must return ErrNotFound (or an error wrapping it) for
+// missing keys. Methods are called concurrently and should honor the context's deadline.
+type RemoteCache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// TieredOptions configures a TieredCache
+type TieredOptions struct {
+	// DemoteOnEvict writes entries evicted from L1 into L2, so L2 catches the overflow
+	// of the hot set. It replaces any handler set with L1's OnEvict.
+	DemoteOnEvict bool
+	// WriteThrough stores every Set in L2 as well as L1. Otherwise Set removes the key
+	// from L2, so a stale copy cannot be served once the L1 entry is gone.
+	WriteThrough bool
+}
+
+// TieredStats describes where TieredCache lookups were served from
+type TieredStats struct {
+	L1Hits int64 `json:"l1_hits"`
+	L2Hits int64 `json:"l2_hits"`
+	Misses int64 `json:"misses"`
+	// Demotions counts L1 evictions written to L2, DemoteErrors those L2 rejected
+	Demotions    int64 `json:"demotions"`
+	DemoteErrors int64 `json:"demote_errors"`
+}
+
+// TieredCache keeps hot entries in an in-memory StrategicCache (L1) in front of a
+// RemoteCache (L2). L1 misses consult L2 and promote hits into L1. Values cross into L2
+// encoded with L1's Serializer, so non-primitive types must be registered with it.
+type TieredCache struct {
+	l1   *StrategicCache
+	l2   RemoteCache
+	opts TieredOptions
+
+	l1Hits       atomic.Int64
+	l2Hits       atomic.Int64
+	misses       atomic.Int64
+	demotions    atomic.Int64
+	demoteErrors atomic.Int64
+}
+
+// NewTieredCache composes l1 and l2. The TieredCache does not copy l1: it remains usable
+// directly, but values set there bypass L2.
+func NewTieredCache(l1 *StrategicCache, l2 RemoteCache, opts TieredOptions) *TieredCache {
+	tc := &TieredCache{l1: l1, l2: l2, opts: opts}
+	if opts.DemoteOnEvict {
+		l1.OnEvict(tc.demote)
+	}
+	return tc
+}
+
+// L1 returns the in-memory tier
+func (tc *TieredCache) L1() *StrategicCache {
+	return tc.l1
+}
+
+// L2 returns the remote tier
+func (tc *TieredCache) L2() RemoteCache {
+	return tc.l2
+}
+
+// Get returns the value for key from L1, or from L2 after promoting it into L1.
+// It returns ErrNotFound when neither tier holds the key and ErrBackend when L2 fails.
+// Negative entries set on L1 with SetNegative return ErrNegativeEntry without asking L2.
+func (tc *TieredCache) Get(ctx context.Context, key string) (interface{}, error) {
+	value, err := tc.l1.getLocal(tc.l1.HashKey(key))
+	if err == nil {
+		tc.l1Hits.Add(1)
+		return value, nil
+	}
+	if errors.Is(err, ErrNegativeEntry) || !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrExpired) {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := tc.l2.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		tc.misses.Add(1)
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: l2 get %q: %w", ErrBackend, key, err)
+	}
+	value, err = tc.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", key, err)
+	}
+	tc.l2Hits.Add(1)
+
+	// A value L1 cannot hold is still returned
+	_ = tc.l1.setE(tc.l1.HashKey(key), value, defaultSetOptions)
+	return tc.l1.copyOnRead(value, true)
+}
+
+// Set stores a value in L1 and, with WriteThrough, in L2; otherwise it removes any
+// older copy from L2. The L2 write happens first, so a failure leaves L1 unchanged.
+func (tc *TieredCache) Set(ctx context.Context, key string, value interface{}) error {
+	if tc.opts.WriteThrough {
+		data, err := tc.encode(value)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		if err := tc.l2.Set(ctx, key, data); err != nil {
+			return fmt.Errorf("%w: l2 set %q: %w", ErrBackend, key, err)
+		}
+	} else if err := tc.deleteL2(ctx, key); err != nil {
+		return err
+	}
+	return tc.l1.setE(tc.l1.HashKey(key), value, defaultSetOptions)
+}
+
+// Delete removes a key from both tiers
+func (tc *TieredCache) Delete(ctx context.Context, key string) error {
+	tc.l1.deleteLocal(tc.l1.HashKey(key))
+	return tc.deleteL2(ctx, key)
+}
+
+// Stats returns lookup and demotion counters
+func (tc *TieredCache) Stats() TieredStats {
+	return TieredStats{
+		L1Hits:       tc.l1Hits.Load(),
+		L2Hits:       tc.l2Hits.Load(),
+		Misses:       tc.misses.Load(),
+		Demotions:    tc.demotions.Load(),
+		DemoteErrors: tc.demoteErrors.Load(),
+	}
+}
+
+// Close closes L1 and, if it implements io.Closer, L2
+func (tc *TieredCache) Close() error {
+	tc.l1.Close()
+	if closer, ok := tc.l2.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// deleteL2 removes a key from L2, treating a missing key as success
+func (tc *TieredCache) deleteL2(ctx context.Context, key string) error {
+	if err := tc.l2.Delete(ctx, key); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: l2 delete %q: %w", ErrBackend, key, err)
+	}
+	return nil
+}
+
+// demote writes an entry evicted from L1 into L2, reporting failures to L1's Logger
+func (tc *TieredCache) demote(key string, value interface{}) {
+	data, err := tc.encode(value)
+	if err == nil {
+		err = tc.l2.Set(context.Background(), key, data)
+	}
+	if err != nil {
+		tc.demoteErrors.Add(1)
+		tc.l1.logger.Warn("metis: demotion to l2 failed", "key", key, "error", err)
+		return
+	}
+	tc.demotions.Add(1)
+}
+
+// encode serializes a value for L2 as its type tag followed by the payload
+func (tc *TieredCache) encode(value interface{}) ([]byte, error) {
+	tag, payload, err := encodeTyped(value, tc.l1.serializer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotSerializable, err)
+	}
+	return append([]byte{tag}, payload...), nil
+}
+
+// decode reverses encode
+func (tc *TieredCache) decode(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty l2 value", ErrNotSerializable)
+	}
+	value, err := decodeTyped(data[0], data[1:], tc.l1.serializer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotSerializable, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,141 @@
+// tiered_test.go: Tests for TieredCache and FileCache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTieredCache(t *testing.T, size int, opts TieredOptions) (*TieredCache, *FileCache) {
+	t.Helper()
+	l2, err := NewFileCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewFileCache: %v", err)
+	}
+	l1 := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      size,
+		ShardCount:     1,
+		TTL:            time.Hour,
+		EvictionPolicy: "lru",
+	})
+	tc := NewTieredCache(l1, l2, opts)
+	t.Cleanup(func() { _ = tc.Close() })
+	return tc, l2
+}
+
+func TestTieredCache_PromotesL2Hits(t *testing.T) {
+	tc, l2 := newTieredCache(t, 100, TieredOptions{})
+	ctx := context.Background()
+
+	data, err := tc.encode("from l2")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := l2.Set(ctx, "k", data); err != nil {
+		t.Fatalf("l2 Set: %v", err)
+	}
+
+	if v, err := tc.Get(ctx, "k"); err != nil || v != "from l2" {
+		t.Fatalf("expected the L2 value, got %v (err %v)", v, err)
+	}
+	if v, ok := tc.L1().Get("k"); !ok || v != "from l2" {
+		t.Errorf("expected the L2 hit to be promoted into L1, got %v (found %v)", v, ok)
+	}
+	if _, err := tc.Get(ctx, "k"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := tc.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	stats := tc.Stats()
+	if stats.L1Hits != 1 || stats.L2Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 L1 hit, 1 L2 hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestTieredCache_DemotesEvictions(t *testing.T) {
+	tc, _ := newTieredCache(t, 10, TieredOptions{DemoteOnEvict: true})
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		if err := tc.Set(ctx, fmt.Sprintf("k%d", i), i); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if tc.Stats().Demotions == 0 {
+		t.Fatal("expected L1 evictions to be demoted")
+	}
+	for i := 0; i < 50; i++ {
+		if v, err := tc.Get(ctx, fmt.Sprintf("k%d", i)); err != nil || v != i {
+			t.Errorf("expected k%d=%d from one of the tiers, got %v (err %v)", i, i, v, err)
+		}
+	}
+}
+
+func TestTieredCache_WriteThroughAndDelete(t *testing.T) {
+	tc, l2 := newTieredCache(t, 100, TieredOptions{WriteThrough: true})
+	ctx := context.Background()
+
+	if err := tc.Set(ctx, "k", map[string]interface{}{"a": "b"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := l2.Get(ctx, "k"); err != nil {
+		t.Errorf("expected WriteThrough to store the value in L2, got %v", err)
+	}
+
+	if err := tc.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := l2.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected Delete to remove the L2 copy, got %v", err)
+	}
+	if _, err := tc.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func TestTieredCache_SetDropsStaleL2Copy(t *testing.T) {
+	tc, l2 := newTieredCache(t, 100, TieredOptions{})
+	ctx := context.Background()
+
+	data, _ := tc.encode("old")
+	_ = l2.Set(ctx, "k", data)
+	if err := tc.Set(ctx, "k", "new"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := l2.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected Set to remove the stale L2 copy, got %v", err)
+	}
+}
+
+func TestFileCache_TTL(t *testing.T) {
+	fc, err := NewFileCache(t.TempDir(), 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewFileCache: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := fc.Set(ctx, "../escape", []byte("v")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, err := fc.Get(ctx, "../escape"); err != nil || string(v) != "v" {
+		t.Fatalf("expected v, got %q (err %v)", v, err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := fc.Get(ctx, "../escape"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the entry to expire, got %v", err)
+	}
+	if err := fc.Delete(ctx, "never-set"); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+// trace.go: Recording the access pattern of a cache for offline replay
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Trace file layout: traceMagic, a version byte, the sample rate as float64 bits and the
+// start time in UnixNano, both little-endian, then one record per operation: the
+// nanoseconds since the previous record as a uvarint, the op byte, the key hash as 8
+// little-endian bytes and the value size as a uvarint
+const (
+	traceMagic   = "METISTRC"
+	traceVersion = 1
+	// traceHitFlag marks a Get that found its key in the op byte
+	traceHitFlag = 0x80
+)
+
+// ErrTraceActive is returned by StartTrace while a trace is already being recorded
+var ErrTraceActive = errors.New("metis: a trace is already being recorded")
+
+// TraceOp is the operation of a TraceRecord
+type TraceOp uint8
+
+// Operations recorded in a trace
+const (
+	TraceGet    TraceOp = 1 // Get, GetE and GetCtx
+	TraceSet    TraceOp = 2 // Set and its variants, and values loaded from a Backend
+	TraceDelete TraceOp = 3
+)
+
+// String returns the name of the operation
+func (op TraceOp) String() string {
+	switch op {
+	case TraceGet:
+		return "get"
+	case TraceSet:
+		return "set"
+	case TraceDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("op(%d)", uint8(op))
+}
+
+// TraceRecord is one traced operation
+type TraceRecord struct {
+	Time    time.Duration // Since the trace started
+	Op      TraceOp
+	Hit     bool   // A Get that found its key
+	KeyHash uint64 // TraceKeyHash of the key
+	// ValueSize is the size of the stored value for a Set, and of the returned value for a Get
+	ValueSize int
+}
+
+// TraceHeader describes a trace file
+type TraceHeader struct {
+	SampleRate float64 // Fraction of the keys whose operations were recorded
+	Start      time.Time
+}
+
+// TraceOption customizes StartTrace
+type TraceOption func(*traceOptions)
+
+type traceOptions struct {
+	sampleRate float64
+	salt       string
+}
+
+// WithTraceSampleRate records only the operations on a fraction of the keys, 0 to 1, to
+// bound the overhead and the size of the trace. Keys are chosen by their hash, so every
+// operation on a sampled key is recorded and the trace keeps the reuse pattern of the
+// keys it holds. Replay such a trace with CacheSize scaled by the same rate.
+func WithTraceSampleRate(rate float64) TraceOption {
+	return func(o *traceOptions) {
+		o.sampleRate = math.Max(0, math.Min(1, rate))
+	}
+}
+
+// WithTraceKeySalt mixes salt into the key hashes, so that hashes of guessable keys such
+// as user IDs cannot be matched by hashing candidates without knowing the salt. Traces
+// recorded with the same salt hash each key the same way.
+func WithTraceKeySalt(salt string) TraceOption {
+	return func(o *traceOptions) {
+		o.salt = salt
+	}
+}
+
+// TraceKeyHash is the hash a trace records for key: 64-bit FNV-1a of salt followed by
+// key. It is the same in every process, so replays see the same keys as the recording.
+func TraceKeyHash(salt, key string) uint64 {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, salt)
+	_, _ = io.WriteString(h, key)
+	return h.Sum64()
+}
+
+// tracer writes the records of one StartTrace
+type tracer struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	opts      traceOptions
+	all       bool          // Every key is sampled
+	threshold uint64        // Otherwise, keys whose hash is below it are
+	start     time.Time     // Monotonic reference of the record times
+	last      time.Duration // Time of the previous record
+	buf       [2*binary.MaxVarintLen64 + 9]byte
+	err       error // First write error; recording stops at it
+	stopped   bool  // Set by StopTrace, for calls that loaded the tracer before it
+}
+
+// StartTrace records the Get, Set and Delete calls on the cache to w until StopTrace,
+// each as its time, operation, key hash and value size, in a compact binary format read
+// by NewTraceReader. Keys are never written, only their TraceKeyHash. Writes to w are
+// buffered and made while holding a lock, so a slow w slows the traced calls; sample with
+// WithTraceSampleRate on busy caches. It returns ErrTraceActive if a trace is running.
+func (sc *StrategicCache) StartTrace(w io.Writer, opts ...TraceOption) error {
+	o := traceOptions{sampleRate: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if sc.tracer.Load() != nil {
+		return ErrTraceActive
+	}
+
+	t := &tracer{w: bufio.NewWriter(w), opts: o, start: time.Now(), all: o.sampleRate >= 1}
+	if !t.all {
+		t.threshold = uint64(o.sampleRate * math.MaxUint64)
+	}
+	var header [len(traceMagic) + 17]byte
+	copy(header[:], traceMagic)
+	header[len(traceMagic)] = traceVersion
+	binary.LittleEndian.PutUint64(header[len(traceMagic)+1:], math.Float64bits(o.sampleRate))
+	binary.LittleEndian.PutUint64(header[len(traceMagic)+9:], uint64(t.start.UnixNano())) // nosec G115 - times after 1970
+	if _, err := t.w.Write(header[:]); err != nil {
+		return err
+	}
+
+	if !sc.tracer.CompareAndSwap(nil, t) {
+		return ErrTraceActive
+	}
+	return nil
+}
+
+// StopTrace stops the trace started by StartTrace and flushes it to its writer, without
+// closing the writer. It returns the first error writing the trace, after which nothing
+// more was recorded, and nil when no trace is running.
+func (sc *StrategicCache) StopTrace() error {
+	t := sc.tracer.Swap(nil)
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	return t.err
+}
+
+// traceGet records a read, if a trace is running
+func (sc *StrategicCache) traceGet(key string, value interface{}, err error) {
+	if t := sc.tracer.Load(); t != nil {
+		if err != nil {
+			t.record(TraceGet, key, nil, false)
+		} else {
+			t.record(TraceGet, key, value, true)
+		}
+	}
+}
+
+// traceOp records a Set or Delete, if a trace is running
+func (sc *StrategicCache) traceOp(op TraceOp, key string, value interface{}) {
+	if t := sc.tracer.Load(); t != nil {
+		t.record(op, key, value, false)
+	}
+}
+
+// record writes one operation if its key is sampled
+func (t *tracer) record(op TraceOp, key string, value interface{}, hit bool) {
+	hash := TraceKeyHash(t.opts.salt, key)
+	if !t.all && hash >= t.threshold {
+		return
+	}
+	size := calculateSize(value)
+	if hit {
+		op |= traceHitFlag
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil || t.stopped {
+		return
+	}
+	now := time.Since(t.start)
+	n := binary.PutUvarint(t.buf[:], uint64(max(0, int(now-t.last)))) // nosec G115 - not negative
+	t.last = now
+	t.buf[n] = byte(op)
+	binary.LittleEndian.PutUint64(t.buf[n+1:], hash)
+	n += 9
+	n += binary.PutUvarint(t.buf[n:], uint64(size)) // nosec G115 - sizes are not negative
+	_, t.err = t.w.Write(t.buf[:n])
+}
+
+// TraceReader reads a trace written by StartTrace
+type TraceReader struct {
+	Header TraceHeader
+	r      *bufio.Reader
+	time   time.Duration
+}
+
+// NewTraceReader reads the header of a trace and returns a reader of its records
+func NewTraceReader(r io.Reader) (*TraceReader, error) {
+	br := bufio.NewReader(r)
+	var header [len(traceMagic) + 17]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil || string(header[:len(traceMagic)]) != traceMagic {
+		return nil, errors.New("metis: not a trace file")
+	}
+	if v := header[len(traceMagic)]; v != traceVersion {
+		return nil, fmt.Errorf("metis: unsupported trace version %d", v)
+	}
+	return &TraceReader{
+		Header: TraceHeader{
+			SampleRate: math.Float64frombits(binary.LittleEndian.Uint64(header[len(traceMagic)+1:])),
+			Start:      time.Unix(0, int64(binary.LittleEndian.Uint64(header[len(traceMagic)+9:]))), // nosec G115 - written from UnixNano
+		},
+		r: br,
+	}, nil
+}
+
+// Next returns the next record, or io.EOF after the last one. A trace cut short, as when
+// the process stopped without StopTrace, ends with io.ErrUnexpectedEOF.
+func (tr *TraceReader) Next() (TraceRecord, error) {
+	delta, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return TraceRecord{}, err // io.EOF between records
+	}
+	var fixed [9]byte
+	if _, err := io.ReadFull(tr.r, fixed[:]); err != nil {
+		return TraceRecord{}, io.ErrUnexpectedEOF
+	}
+	size, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return TraceRecord{}, io.ErrUnexpectedEOF
+	}
+
+	tr.time += time.Duration(delta) // nosec G115 - written from a Duration
+	rec := TraceRecord{
+		Time:      tr.time,
+		Op:        TraceOp(fixed[0] &^ traceHitFlag),
+		Hit:       fixed[0]&traceHitFlag != 0,
+		KeyHash:   binary.LittleEndian.Uint64(fixed[1:]),
+		ValueSize: int(size), // nosec G115 - written from an int
+	}
+	if rec.Op < TraceGet || rec.Op > TraceDelete {
+		return TraceRecord{}, fmt.Errorf("metis: invalid trace op %d", fixed[0])
+	}
+	return rec, nil
+}
@@ -0,0 +1,275 @@
+// trace_test.go: Tests for trace recording
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// readTrace returns every record of a trace
+func readTrace(t *testing.T, data []byte) (TraceHeader, []TraceRecord) {
+	t.Helper()
+	tr, err := NewTraceReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []TraceRecord
+	for {
+		rec, err := tr.Next()
+		if err == io.EOF {
+			return tr.Header, records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestTrace_RecordsOperations(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      10000,
+				ShardCount:     4,
+				TTL:            time.Minute,
+				EvictionPolicy: policy,
+			})
+			defer cache.Close()
+			var buf bytes.Buffer
+			if err := cache.StartTrace(&buf); err != nil {
+				t.Fatal(err)
+			}
+			cache.Get("user:1")
+			cache.Set("user:1", []byte("0123456789"))
+			cache.Get("user:1")
+			cache.Delete("user:1")
+			if err := cache.StopTrace(); err != nil {
+				t.Fatal(err)
+			}
+			cache.Get("user:1") // After StopTrace, not recorded
+
+			header, records := readTrace(t, buf.Bytes())
+			if header.SampleRate != 1 || time.Since(header.Start) > time.Minute {
+				t.Errorf("unexpected header %+v", header)
+			}
+			want := []struct {
+				op   TraceOp
+				hit  bool
+				size int
+			}{{TraceGet, false, 0}, {TraceSet, false, 10}, {TraceGet, true, 10}, {TraceDelete, false, 0}}
+			if len(records) != len(want) {
+				t.Fatalf("expected %d records, got %+v", len(want), records)
+			}
+			hash := TraceKeyHash("", "user:1")
+			for i, rec := range records {
+				if rec.Op != want[i].op || rec.Hit != want[i].hit || rec.ValueSize != want[i].size || rec.KeyHash != hash {
+					t.Errorf("record %d: expected %+v of key hash %x, got %+v", i, want[i], hash, rec)
+				}
+				if i > 0 && rec.Time < records[i-1].Time {
+					t.Errorf("record %d goes back in time", i)
+				}
+			}
+			if bytes.Contains(buf.Bytes(), []byte("user:1")) {
+				t.Error("the trace holds the raw key")
+			}
+		})
+	}
+}
+
+func TestTrace_Sampling(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	var buf bytes.Buffer
+	if err := cache.StartTrace(&buf, WithTraceSampleRate(0.1)); err != nil {
+		t.Fatal(err)
+	}
+	const keys = 5000
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key%d", i)
+		cache.Set(key, i)
+		cache.Get(key)
+	}
+	_ = cache.StopTrace()
+
+	header, records := readTrace(t, buf.Bytes())
+	if header.SampleRate != 0.1 {
+		t.Errorf("expected sample rate 0.1 in the header, got %v", header.SampleRate)
+	}
+	ops := map[uint64]int{}
+	for _, rec := range records {
+		ops[rec.KeyHash]++
+	}
+	if len(ops) < keys/20 || len(ops) > keys/5 {
+		t.Errorf("expected about %d sampled keys, got %d", keys/10, len(ops))
+	}
+	for hash, n := range ops {
+		if n != 2 {
+			t.Errorf("expected both operations of sampled key %x, got %d", hash, n)
+		}
+	}
+
+	// The same keys are sampled every time
+	var again bytes.Buffer
+	_ = cache.StartTrace(&again, WithTraceSampleRate(0.1))
+	for i := 0; i < keys; i++ {
+		cache.Get(fmt.Sprintf("key%d", i))
+	}
+	_ = cache.StopTrace()
+	_, records = readTrace(t, again.Bytes())
+	for _, rec := range records {
+		if ops[rec.KeyHash] == 0 {
+			t.Fatalf("key hash %x sampled in the second trace only", rec.KeyHash)
+		}
+	}
+	if len(records) != len(ops) {
+		t.Errorf("expected the %d keys of the first trace, got %d", len(ops), len(records))
+	}
+}
+
+func TestTrace_KeySalt(t *testing.T) {
+	if TraceKeyHash("", "k") != TraceKeyHash("", "k") {
+		t.Fatal("key hashes must be deterministic")
+	}
+	if TraceKeyHash("secret", "k") == TraceKeyHash("", "k") {
+		t.Error("expected the salt to change the hash")
+	}
+
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+	var buf bytes.Buffer
+	_ = cache.StartTrace(&buf, WithTraceKeySalt("secret"))
+	cache.Set("k", 1)
+	_ = cache.StopTrace()
+	if _, records := readTrace(t, buf.Bytes()); len(records) != 1 || records[0].KeyHash != TraceKeyHash("secret", "k") {
+		t.Errorf("expected the salted hash, got %+v", records)
+	}
+}
+
+func TestTrace_StartStop(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	if err := cache.StopTrace(); err != nil {
+		t.Errorf("expected nil stopping without a trace, got %v", err)
+	}
+	if err := cache.StartTrace(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.StartTrace(io.Discard); !errors.Is(err, ErrTraceActive) {
+		t.Errorf("expected ErrTraceActive, got %v", err)
+	}
+	_ = cache.StopTrace()
+	if err := cache.StartTrace(io.Discard); err != nil {
+		t.Errorf("expected a new trace after StopTrace, got %v", err)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestTrace_WriteError(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	_ = cache.StartTrace(failingWriter{})
+	for i := 0; i < 10000; i++ { // More than the buffer holds
+		cache.Set(fmt.Sprintf("key%d", i), i)
+	}
+	if err := cache.StopTrace(); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
+
+func TestTrace_Concurrent(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+	var buf bytes.Buffer
+	_ = cache.StartTrace(&buf)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("g%d:%d", g, i)
+				cache.Set(key, i)
+				cache.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+	_ = cache.StopTrace()
+
+	if _, records := readTrace(t, buf.Bytes()); len(records) != 8*500*2 {
+		t.Errorf("expected %d records, got %d", 8*500*2, len(records))
+	}
+}
+
+func TestTraceReader_Invalid(t *testing.T) {
+	if _, err := NewTraceReader(bytes.NewReader([]byte("not a trace at all......"))); err == nil {
+		t.Error("expected an error for a file that is not a trace")
+	}
+
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      10000,
+		ShardCount:     4,
+		TTL:            time.Minute,
+		EvictionPolicy: "lru",
+	})
+	defer cache.Close()
+	var buf bytes.Buffer
+	_ = cache.StartTrace(&buf)
+	cache.Set("k", []byte("value"))
+	_ = cache.StopTrace()
+
+	tr, err := NewTraceReader(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF for a cut trace, got %v", err)
+	}
+}
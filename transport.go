@@ -0,0 +1,247 @@
+// transport.go: Caching http.RoundTripper for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTransportMaxBodySize is the largest response body a caching transport stores
+const DefaultTransportMaxBodySize = 1 << 20
+
+// transportMaxAge caps a Cache-Control max-age at 2^31 seconds, as RFC 9111 allows, so the
+// TTL cannot overflow
+const transportMaxAge = 1 << 31
+
+// credentialHeaders identify the user of a request: responses to requests carrying them
+// are only cached when the headers are part of the key (see WithVaryHeaders)
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+// CacheStatusHeader is set to "HIT" on responses served from the cache by a caching transport
+const CacheStatusHeader = "X-Metis-Cache"
+
+// TransportOption customizes a caching transport created by NewCachingTransport
+type TransportOption func(*transportOptions)
+
+// transportOptions holds the settings applied by TransportOption
+type transportOptions struct {
+	maxBodySize int64
+	cacheable   func(*http.Request) bool
+	vary        []string
+}
+
+// WithMaxBodySize sets the largest response body that is cached; larger responses are
+// passed through unchanged. Default: DefaultTransportMaxBodySize.
+func WithMaxBodySize(n int64) TransportOption {
+	return func(o *transportOptions) { o.maxBodySize = n }
+}
+
+// WithRequestFilter replaces the predicate choosing which requests may be cached.
+// The default accepts GET and HEAD requests.
+func WithRequestFilter(fn func(*http.Request) bool) TransportOption {
+	return func(o *transportOptions) { o.cacheable = fn }
+}
+
+// WithVaryHeaders adds the values of the named request headers to the cache key, so
+// responses that vary on them (such as Accept-Encoding or Authorization) are kept apart.
+// Responses whose Vary header names any other header are not cached. Naming Authorization
+// or Cookie lets requests carrying them be cached, one entry per credential.
+func WithVaryHeaders(names ...string) TransportOption {
+	return func(o *transportOptions) {
+		for _, name := range names {
+			o.vary = append(o.vary, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// cachingTransport is the http.RoundTripper returned by NewCachingTransport
+type cachingTransport struct {
+	cache *StrategicCache
+	inner http.RoundTripper
+	opts  transportOptions
+}
+
+// NewCachingTransport returns an http.RoundTripper that serves repeated requests from c.
+// Responses are keyed by method and URL, plus any WithVaryHeaders headers, and stored with
+// SetBytes. Requests with an Authorization or Cookie header bypass the cache, so one
+// user's response is never served to another, unless WithVaryHeaders names the header.
+// A Cache-Control max-age sets the entry's TTL, otherwise the cache TTL applies;
+// no-store, no-cache, private and max-age=0 responses are not cached. Hits are served
+// without touching the network and carry the CacheStatusHeader header.
+// A nil inner uses http.DefaultTransport.
+func NewCachingTransport(c *StrategicCache, inner http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	t := &cachingTransport{
+		cache: c,
+		inner: inner,
+		opts: transportOptions{
+			maxBodySize: DefaultTransportMaxBodySize,
+			cacheable: func(r *http.Request) bool {
+				return r.Method == http.MethodGet || r.Method == http.MethodHead
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(&t.opts)
+	}
+	return t
+}
+
+// RoundTrip serves req from the cache when possible, otherwise from the inner transport,
+// storing cacheable responses
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqDirectives := parseCacheControl(req.Header.Get("Cache-Control"))
+	if !t.opts.cacheable(req) || reqDirectives.has("no-store") || t.credentialed(req) {
+		return t.inner.RoundTrip(req)
+	}
+
+	key := t.key(req)
+	if !reqDirectives.has("no-cache") {
+		if data, ok := t.cache.GetBytes(key); ok {
+			resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+			if err == nil {
+				resp.Header.Set(CacheStatusHeader, "HIT")
+				return resp, nil
+			}
+			t.cache.deleteLocal(t.cache.HashKey(key)) // Unreadable entry; refetch it
+		}
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	ttl, ok := t.storable(resp)
+	if !ok {
+		return resp, nil
+	}
+
+	// Read one byte past the limit to tell an oversized body from one that just fits
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.opts.maxBodySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.opts.maxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// DumpResponse restores resp.Body after reading it
+	if data, err := httputil.DumpResponse(resp, true); err == nil {
+		opts := defaultSetOptions
+		WithTTL(ttl)(&opts)
+		t.cache.setBytes(key, data, opts)
+	}
+	return resp, nil
+}
+
+// key builds the cache key for a request
+func (t *cachingTransport) key(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte(' ')
+	b.WriteString(req.URL.String())
+	for _, name := range t.opts.vary {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// storable reports whether a response may be cached and for how long (0 = the cache TTL)
+func (t *cachingTransport) storable(resp *http.Response) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+	default:
+		return 0, false
+	}
+
+	for _, field := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(field, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" && !t.varies(name) {
+				return 0, false // Includes "*"
+			}
+		}
+	}
+
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if directives.has("no-store") || directives.has("no-cache") || directives.has("private") {
+		return 0, false
+	}
+	if v, ok := directives["max-age"]; ok {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(min(seconds, transportMaxAge)) * time.Second, true
+	}
+	return 0, true
+}
+
+// credentialed reports whether req carries credentials that are not part of its key
+func (t *cachingTransport) credentialed(req *http.Request) bool {
+	for _, name := range credentialHeaders {
+		if len(req.Header.Values(name)) > 0 && !t.varies(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// varies reports whether name is one of the WithVaryHeaders headers
+func (t *cachingTransport) varies(name string) bool {
+	for _, v := range t.opts.vary {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl maps lower-cased Cache-Control directives to their values
+type cacheControl map[string]string
+
+// has reports whether a directive is present
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// parseCacheControl parses a Cache-Control header value
+func parseCacheControl(header string) cacheControl {
+	cc := cacheControl{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
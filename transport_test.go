@@ -0,0 +1,203 @@
+// transport_test.go: Tests for the caching http.RoundTripper
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// newOriginServer counts requests and answers with the request count and the given Cache-Control
+func newOriginServer(t *testing.T, cacheControl string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		if r.URL.Path == "/big" {
+			_, _ = io.WriteString(w, strings.Repeat("x", 1000))
+			return
+		}
+		fmt.Fprintf(w, "response %d for %s", n, r.Header.Get("Accept-Language"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func newTransportClient(t *testing.T, opts ...TransportOption) (*http.Client, *fakeclock.Clock) {
+	t.Helper()
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Hour, Clock: clock})
+	t.Cleanup(cache.Close)
+	return &http.Client{Transport: NewCachingTransport(cache, nil, opts...)}, clock
+}
+
+func fetch(t *testing.T, client *http.Client, req *http.Request) (string, bool) {
+	t.Helper()
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return string(body), resp.Header.Get(CacheStatusHeader) == "HIT"
+}
+
+func fetchURL(t *testing.T, client *http.Client, url string) (string, bool) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	return fetch(t, client, req)
+}
+
+func TestCachingTransport_HitAndMiss(t *testing.T) {
+	server, hits := newOriginServer(t, "")
+	client, _ := newTransportClient(t)
+
+	first, hit := fetchURL(t, client, server.URL+"/a")
+	if hit {
+		t.Error("expected the first request to miss")
+	}
+	second, hit := fetchURL(t, client, server.URL+"/a")
+	if !hit || second != first {
+		t.Errorf("expected a cached %q, got %q (hit %v)", first, second, hit)
+	}
+	if _, hit := fetchURL(t, client, server.URL+"/b"); hit {
+		t.Error("expected a different URL to miss")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 requests to reach the origin, got %d", n)
+	}
+
+	// POST is not cached by default
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/a", nil)
+		if _, hit := fetch(t, client, req); hit {
+			t.Error("expected POST not to be served from the cache")
+		}
+	}
+}
+
+func TestCachingTransport_MaxAgeExpiry(t *testing.T) {
+	server, hits := newOriginServer(t, "public, max-age=1")
+	client, clock := newTransportClient(t)
+
+	fetchURL(t, client, server.URL+"/a")
+	if _, hit := fetchURL(t, client, server.URL+"/a"); !hit {
+		t.Fatal("expected a hit within max-age")
+	}
+	clock.Advance(1100 * time.Millisecond)
+	if _, hit := fetchURL(t, client, server.URL+"/a"); hit {
+		t.Error("expected the entry to expire after max-age")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 requests to reach the origin, got %d", n)
+	}
+}
+
+func TestCachingTransport_NotStored(t *testing.T) {
+	for _, cc := range []string{"no-store", "no-cache", "private, max-age=60", "max-age=0"} {
+		t.Run(cc, func(t *testing.T) {
+			server, _ := newOriginServer(t, cc)
+			client, _ := newTransportClient(t)
+			fetchURL(t, client, server.URL+"/a")
+			if _, hit := fetchURL(t, client, server.URL+"/a"); hit {
+				t.Errorf("expected %q not to be cached", cc)
+			}
+		})
+	}
+}
+
+func TestCachingTransport_MaxBodySize(t *testing.T) {
+	server, _ := newOriginServer(t, "")
+	client, _ := newTransportClient(t, WithMaxBodySize(100))
+
+	body, _ := fetchURL(t, client, server.URL+"/big")
+	if len(body) != 1000 {
+		t.Fatalf("expected the full 1000-byte body, got %d bytes", len(body))
+	}
+	if _, hit := fetchURL(t, client, server.URL+"/big"); hit {
+		t.Error("expected an oversized body not to be cached")
+	}
+}
+
+func TestCachingTransport_RequestFilterAndVary(t *testing.T) {
+	server, _ := newOriginServer(t, "")
+	client, _ := newTransportClient(t,
+		WithRequestFilter(func(r *http.Request) bool { return !strings.HasPrefix(r.URL.Path, "/private") }),
+		WithVaryHeaders("accept-language"))
+
+	fetchURL(t, client, server.URL+"/private")
+	if _, hit := fetchURL(t, client, server.URL+"/private"); hit {
+		t.Error("expected the filtered request not to be cached")
+	}
+
+	english, _ := http.NewRequest(http.MethodGet, server.URL+"/a", nil)
+	english.Header.Set("Accept-Language", "en")
+	italian, _ := http.NewRequest(http.MethodGet, server.URL+"/a", nil)
+	italian.Header.Set("Accept-Language", "it")
+
+	fetch(t, client, english)
+	if body, hit := fetch(t, client, italian); hit || !strings.HasSuffix(body, "it") {
+		t.Errorf("expected a separate entry per Accept-Language, got %q (hit %v)", body, hit)
+	}
+	if body, hit := fetch(t, client, english); !hit || !strings.HasSuffix(body, "en") {
+		t.Errorf("expected the English response from the cache, got %q (hit %v)", body, hit)
+	}
+}
+
+func TestCachingTransport_Credentials(t *testing.T) {
+	server, hits := newOriginServer(t, "max-age=60")
+	client, _ := newTransportClient(t)
+
+	for _, header := range []string{"Authorization", "Cookie"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/"+header, nil)
+		req.Header.Set(header, "alice")
+		fetch(t, client, req)
+		if _, hit := fetchURL(t, client, server.URL+"/"+header); hit {
+			t.Errorf("expected a response to a request with %s not to be served to others", header)
+		}
+	}
+
+	// Keyed by the credential, each user gets their own entry
+	client, _ = newTransportClient(t, WithVaryHeaders("Authorization"))
+	alice, _ := http.NewRequest(http.MethodGet, server.URL+"/me", nil)
+	alice.Header.Set("Authorization", "alice")
+	bob, _ := http.NewRequest(http.MethodGet, server.URL+"/me", nil)
+	bob.Header.Set("Authorization", "bob")
+	fetch(t, client, alice)
+	if _, hit := fetch(t, client, bob); hit {
+		t.Error("expected bob not to get alice's response")
+	}
+	before := hits.Load()
+	if _, hit := fetch(t, client, alice); !hit || hits.Load() != before {
+		t.Error("expected alice's response from the cache")
+	}
+}
+
+func TestCachingTransport_HugeMaxAge(t *testing.T) {
+	server, _ := newOriginServer(t, "max-age=9223372036854775807")
+	client, clock := newTransportClient(t)
+
+	fetchURL(t, client, server.URL+"/a")
+	clock.Advance(24 * 365 * time.Hour)
+	if _, hit := fetchURL(t, client, server.URL+"/a"); !hit {
+		t.Error("expected a huge max-age to be capped, not to overflow into an expired TTL")
+	}
+}
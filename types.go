@@ -38,6 +38,7 @@ func PutCacheEntry(entry *CacheEntry) {
 		entry.Size = 0
 		entry.Compressed = false
 		entry.IsNil = false
+		entry.Cost = 0
 		entry.llElem = nil
 		entryPool.Put(entry)
 	}
@@ -64,17 +65,130 @@ type CacheConfig struct {
 	MaxKeySize        int           `json:"max_key_size"`
 	MaxValueSize      int           `json:"max_value_size"`
 	EnableCompression bool          `json:"enable_compression"`
-	EvictionPolicy    string        `json:"eviction_policy"` // "lru", "lfu", "tinylfu", "wtinylfu" (default: wtinylfu)
+	// CompressionCodec names the codec used when EnableCompression is set: "gzip" or one added
+	// with RegisterCodec. Values written with another registered codec stay readable. Default: "gzip".
+	CompressionCodec string `json:"compression_codec,omitempty"`
+	// CompressionMinSize is the smallest encoded value, in bytes, that gzip compresses;
+	// smaller values are stored as-is. Default: 64.
+	CompressionMinSize int `json:"compression_min_size,omitempty"`
+	// CompressionLevel is the gzip level, from gzip.HuffmanOnly (-2) to gzip.BestCompression (9).
+	// 0 selects gzip.DefaultCompression; leave EnableCompression off to store values uncompressed.
+	CompressionLevel int `json:"compression_level,omitempty"`
+	// DisableChecksums skips the CRC32 that compressed entries carry and reads verify, for
+	// maximum speed. Corrupted bytes then read as a decode error or a wrong value. Default: false.
+	DisableChecksums bool `json:"disable_checksums,omitempty"`
+	// ChunkThreshold splits values larger than this many bytes into chunks of that size,
+	// stored under derived keys on whichever shards they hash to, with a manifest under
+	// the value's own key. Get, Range and snapshots reassemble them, and Keys, Len and
+	// GetStats leave the chunks out. Default: 0 (values are never split).
+	ChunkThreshold int    `json:"chunk_threshold,omitempty"`
+	EvictionPolicy string `json:"eviction_policy"` // "lru", "wtinylfu", "arc" (default: wtinylfu)
 	// AdmissionProbability controls the probability (0.0-1.0) that a new item is admitted to the cache (for probabilistic admission policies). Default: -1 (unset, always admit).
 	AdmissionProbability float64 `json:"admission_probability,omitempty"`
-	// ShardCount controls the number of shards for the cache (striped locking). Default: 16.
+	// ShardCount controls the number of shards for the cache (striped locking). Default: four
+	// per GOMAXPROCS, rounded up to a power of two within [MinAutoShardCount, MaxAutoShardCount].
 	ShardCount int `json:"shard_count,omitempty"`
 	// MaxShardSize controls the maximum number of entries per shard. Default: CacheSize / ShardCount.
 	MaxShardSize int `json:"max_shard_size,omitempty"`
-	// AdmissionPolicy controls the admission policy: "always", "never", "probabilistic". Default: "always".
+	// AdmissionPolicy controls the admission policy: "always", "never", "probabilistic", "tinylfu", "size-aware". Default: "always".
+	// "tinylfu" lets a full shard admit a key only if it is used more often than the eviction victim.
+	// "size-aware" rejects values above SizeAwareMaxSize once a shard is SizeAwareUtilization full.
 	AdmissionPolicy string `json:"admission_policy,omitempty"`
-	// Logger for debug and monitoring (optional, can be nil)
+	// KeyHasher selects the hash that places keys in shards on the sharded path: "maphash",
+	// seeded per cache, or "legacy" for the placement of earlier releases. Default: "maphash".
+	KeyHasher string `json:"key_hasher,omitempty"`
+	// MaxMemoryBytes bounds the estimated bytes held by cached values, split evenly across shards.
+	// Entries are evicted to make room, and values larger than a shard's share are rejected. Default: 0 (unlimited).
+	MaxMemoryBytes int64 `json:"max_memory_bytes,omitempty"`
+	// WindowRatio is the W-TinyLFU window's share of each shard, within (0,1). Default: 0.10.
+	WindowRatio float64 `json:"window_ratio,omitempty"`
+	// ProbationRatio is the probation share of the W-TinyLFU main segment, within (0,1). Default: 0.8.
+	ProbationRatio float64 `json:"probation_ratio,omitempty"`
+	// AdaptiveWindow lets each W-TinyLFU shard shift slots between window and main
+	// towards the segment with the higher hit rate, keeping the window within 1%-80%. Default: false.
+	AdaptiveWindow bool `json:"adaptive_window,omitempty"`
+	// SketchDepth is the number of rows (hash functions) in the W-TinyLFU Count-Min sketch. Default: 4.
+	SketchDepth int `json:"sketch_depth,omitempty"`
+	// SketchWidth is the number of counters per sketch row in each W-TinyLFU shard.
+	// Lower it to save memory at the cost of accuracy. Default: 4x the per-shard filter size.
+	SketchWidth int `json:"sketch_width,omitempty"`
+	// SizeAwareMaxSize is the largest value in bytes the "size-aware" admission policy accepts under pressure. Default: 4096.
+	SizeAwareMaxSize int `json:"size_aware_max_size,omitempty"`
+	// SizeAwareUtilization is the shard fill ratio (0.0-1.0) above which "size-aware" admission rejects large values. Default: 0.9.
+	SizeAwareUtilization float64 `json:"size_aware_utilization,omitempty"`
+	// CopyOnRead makes Get and Range return deep copies of slices, maps, pointers and structs,
+	// so callers mutating a returned value cannot change what others read. Default: false.
+	CopyOnRead bool `json:"copy_on_read,omitempty"`
+	// SlidingTTL restarts an entry's TTL on every read, so entries expire after going
+	// unread for the TTL rather than at a fixed time after they were written. Default: false.
+	SlidingTTL bool `json:"sliding_ttl,omitempty"`
+	// MaxIdleTime expires an entry that goes unread for this long, independently of its TTL:
+	// whichever of the two elapses first removes it. Default: 0 (no idle timeout).
+	MaxIdleTime time.Duration `json:"max_idle_time,omitempty"`
+	// DisableBackgroundCleanup starts no cleanup goroutine: expired entries are reclaimed when
+	// read and, LazyCleanupBatch at a time, by writes to their shard. Default: false.
+	DisableBackgroundCleanup bool `json:"disable_background_cleanup,omitempty"`
+	// LazyCleanupBatch is how many entries each Set examines for expiration when
+	// DisableBackgroundCleanup is set. Default: 0 (DefaultLazyCleanupBatch).
+	LazyCleanupBatch int `json:"lazy_cleanup_batch,omitempty"`
+	// StatsWindow is the span WindowedStats reports on; zero disables the windowed
+	// counters and their overhead. Default: 0.
+	StatsWindow time.Duration `json:"stats_window,omitempty"`
+	// StatsWindowBuckets divides StatsWindow into buckets that expire one at a time.
+	// Default: 0 (DefaultStatsWindowBuckets).
+	StatsWindowBuckets int `json:"stats_window_buckets,omitempty"`
+	// EnableLatencyTracking records Get and Set durations for LatencyStats. When false
+	// the clock is not read at all. Default: false.
+	EnableLatencyTracking bool `json:"enable_latency_tracking,omitempty"`
+	// TrackHotKeys is how many of the most read keys HotKeys reports; zero disables
+	// tracking. Default: 0.
+	TrackHotKeys int `json:"track_hot_keys,omitempty"`
+	// SnapshotPath is where the cache saves a snapshot (see SaveToFile) when it is closed and,
+	// with SnapshotInterval, periodically while in use. Default: "" (no snapshots).
+	SnapshotPath string `json:"snapshot_path,omitempty"`
+	// SnapshotInterval is the time between background snapshots to SnapshotPath. Default: 0 (only on Close).
+	SnapshotInterval time.Duration `json:"snapshot_interval,omitempty"`
+	// Backend, when non-nil, makes the cache write-through: misses are loaded from it and
+	// Set and Delete update it synchronously before returning
+	Backend Backend `json:"-"`
+	// WriteBehind makes Set and Delete queue their Backend writes for worker goroutines
+	// instead of waiting for them; Flush and Close wait for the queue to drain. Default: false.
+	WriteBehind bool `json:"write_behind,omitempty"`
+	// WriteBehindBufferSize bounds the queued writes; Set fails with ErrWriteBehindFull beyond it. Default: 1024.
+	WriteBehindBufferSize int `json:"write_behind_buffer_size,omitempty"`
+	// WriteBehindBatchSize is the most writes sent to the backend at once. Default: 100.
+	WriteBehindBatchSize int `json:"write_behind_batch_size,omitempty"`
+	// WriteBehindFlushInterval is the longest a queued write waits for its batch to fill. Default: 1s.
+	WriteBehindFlushInterval time.Duration `json:"write_behind_flush_interval,omitempty"`
+	// WriteBehindWorkers is the number of goroutines writing to the backend. Default: 1.
+	WriteBehindWorkers int `json:"write_behind_workers,omitempty"`
+	// WriteBehindRetries is how often a failed batch is retried before its writes are dropped
+	// and counted as failed. Default: 3; a negative value disables retries.
+	WriteBehindRetries int `json:"write_behind_retries,omitempty"`
+	// WriteBehindRetryBackoff is the delay before the first retry, doubled for each one after. Default: 100ms.
+	WriteBehindRetryBackoff time.Duration `json:"write_behind_retry_backoff,omitempty"`
+	// BreakerThreshold is how many consecutive Backend or LoadOrCompute loader failures open
+	// the circuit breaker, failing further calls with ErrBackendUnavailable. Default: 0 (no breaker).
+	BreakerThreshold int `json:"breaker_threshold,omitempty"`
+	// BreakerOpenDuration is how long the breaker stays open before probing the backend. Default: 5s.
+	BreakerOpenDuration time.Duration `json:"breaker_open_duration,omitempty"`
+	// BreakerHalfOpenProbes is how many calls may probe the backend at once while the breaker
+	// is half-open, and how many must succeed to close it. Default: 1.
+	BreakerHalfOpenProbes int `json:"breaker_half_open_probes,omitempty"`
+	// Serializer encodes non-primitive values when EnableCompression is set (default: GobSerializer)
+	Serializer Serializer `json:"-"`
+	// CustomEvictionPolicy, when non-nil, overrides EvictionPolicy and runs on the sharded path
+	CustomEvictionPolicy EvictionPolicy `json:"-"`
+	// CustomAdmissionPolicy, when non-nil, overrides AdmissionPolicy and AdmissionProbability
+	CustomAdmissionPolicy AdmissionPolicy `json:"-"`
+	// CustomKeyHasher, when non-nil, overrides KeyHasher
+	CustomKeyHasher Hasher `json:"-"`
+	// Logger receives failed serializations and snapshots, undecodable entries, config
+	// reloads and cleanup sweeps; see SlogLogger for log/slog. Default: nil (discarded).
 	Logger Logger `json:"-"`
+	// Clock is the time source for entry expiration and the cleanup routines (default: the
+	// system clock). Tests can install metistest.Clock to advance time without sleeping.
+	Clock Clock `json:"-"`
 }
 
 // CacheEntry represents a single entry in the cache
@@ -87,5 +201,28 @@ type CacheEntry struct {
 	Size        int           `json:"size"`
 	Compressed  bool          `json:"compressed"`
 	IsNil       bool          `json:"is_nil"` // Flag to distinguish nil values from empty strings
+	Cost        int64         `json:"cost"`   // Weight against CacheSize (values below 1 count as 1)
 	llElem      *list.Element // Pointer to node in the LRU/LFU list (internal use)
+	// rawSize is the encoded size before compression, for compressed entries (internal use)
+	rawSize int
+	// compressionSkipped marks values stored as-is under CompressionMinSize (internal use)
+	compressionSkipped bool
+	// slide, when positive, is the sliding TTL: each read moves Timestamp to now+slide (internal use)
+	slide time.Duration
+	// pinned exempts the entry from eviction and expiration (see StrategicCache.Pin, internal use)
+	pinned bool
+	// Priority is the entry's eviction class (see WithPriority)
+	Priority Priority `json:"priority,omitempty"`
+	// writtenAt is the UnixNano time of the last Set (see GetEntryInfo, internal use)
+	writtenAt int64
+	// checksum is the CRC32 of Data for compressed entries, verified by reads (internal use)
+	checksum uint32
+}
+
+// cost returns the entry's weight against CacheSize, treating unset costs as 1
+func (e *CacheEntry) cost() int64 {
+	if e.Cost < 1 {
+		return 1
+	}
+	return e.Cost
 }
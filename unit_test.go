@@ -11,6 +11,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
 )
 
 func TestCacheConfig_FieldAccess(t *testing.T) {
@@ -363,6 +365,7 @@ func TestStrategicCache_AllAdmissionPolicies(t *testing.T) {
 }
 
 func TestStrategicCache_WithTTLExpiration(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
 	config := CacheConfig{
 		CacheSize:         100,
 		EnableCaching:     true,
@@ -373,6 +376,7 @@ func TestStrategicCache_WithTTLExpiration(t *testing.T) {
 		AdmissionPolicy:   "always", // Explicitly set for test consistency
 		ShardCount:        1,
 		MaxShardSize:      100, // Explicitly set for deterministic behavior
+		Clock:             clock,
 	}
 
 	cache := NewStrategicCache(config)
@@ -393,8 +397,8 @@ func TestStrategicCache_WithTTLExpiration(t *testing.T) {
 		t.Errorf("Expected ttl_value, got %v", value)
 	}
 
-	// Wait for TTL to expire and cleanup to run
-	time.Sleep(time.Millisecond * 150)
+	// Move past the TTL
+	clock.Advance(time.Millisecond * 150)
 
 	// Force cleanup to run
 	cache.cleanupExpired(0)
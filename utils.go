@@ -8,6 +8,7 @@ package metis
 
 import (
 	"encoding/gob"
+	"fmt"
 	"reflect"
 	"strconv"
 )
@@ -47,11 +48,16 @@ func calculateSize(value interface{}) int {
 		return 5 // "false"
 	case PrimitiveBox:
 		return calculateSize(v.V)
+	case *streamValue:
+		return int(v.stored)
 	default:
 		if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
 			return 8 // pointer size
 		}
-		// Fallback to gob encoding for complex types
+		if size, ok := estimateSize(value); ok {
+			return size
+		}
+		// Fallback to gob encoding for types only gob can size
 		buf := getBuffer()
 		defer putBuffer(buf)
 		enc := gob.NewEncoder(buf)
@@ -99,17 +105,120 @@ func toBytes(value interface{}) ([]byte, error) {
 		return toBytes(v.V)
 	default:
 		// Fallback to gob encoding for complex types
-		box := PrimitiveBox{V: value}
-		buf := getBuffer()
-		defer putBuffer(buf)
-		enc := gob.NewEncoder(buf)
-		if err := enc.Encode(box); err != nil {
+		return GobSerializer{}.Marshal(value)
+	}
+}
+
+// Type tags recorded alongside encoded values so decoding restores the exact Go type
+const (
+	tagNil byte = iota
+	tagBytes
+	tagString
+	tagInt
+	tagInt32
+	tagInt64
+	tagUint
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagBool
+	tagSerialized // Any other type, encoded by the cache's Serializer
+	tagBox        // A PrimitiveBox; the payload is the tagged inner value
+)
+
+// encodeTyped converts a value to bytes with a tag identifying its type.
+// Non-primitive values are encoded by serializer.
+func encodeTyped(value interface{}, serializer Serializer) (byte, []byte, error) {
+	var tag byte
+	switch v := value.(type) {
+	case nil:
+		return tagNil, []byte{}, nil
+	case []byte:
+		tag = tagBytes
+	case string:
+		tag = tagString
+	case int:
+		tag = tagInt
+	case int32:
+		tag = tagInt32
+	case int64:
+		tag = tagInt64
+	case uint:
+		tag = tagUint
+	case uint32:
+		tag = tagUint32
+	case uint64:
+		tag = tagUint64
+	case float32:
+		tag = tagFloat32
+	case float64:
+		tag = tagFloat64
+	case bool:
+		tag = tagBool
+	case PrimitiveBox:
+		innerTag, inner, err := encodeTyped(v.V, serializer)
+		if err != nil {
+			return 0, nil, err
+		}
+		return tagBox, append([]byte{innerTag}, inner...), nil
+	default:
+		payload, err := serializer.Marshal(value)
+		return tagSerialized, payload, err
+	}
+	payload, err := toBytes(value)
+	return tag, payload, err
+}
+
+// decodeTyped restores a value produced by encodeTyped with the same serializer
+func decodeTyped(tag byte, payload []byte, serializer Serializer) (interface{}, error) {
+	switch tag {
+	case tagNil:
+		return nil, nil
+	case tagBytes:
+		out := make([]byte, len(payload))
+		copy(out, payload)
+		return out, nil
+	case tagString:
+		return string(payload), nil
+	case tagInt:
+		v, err := strconv.ParseInt(string(payload), 10, strconv.IntSize)
+		return int(v), err
+	case tagInt32:
+		v, err := strconv.ParseInt(string(payload), 10, 32)
+		return int32(v), err
+	case tagInt64:
+		return strconv.ParseInt(string(payload), 10, 64)
+	case tagUint:
+		v, err := strconv.ParseUint(string(payload), 10, strconv.IntSize)
+		return uint(v), err
+	case tagUint32:
+		v, err := strconv.ParseUint(string(payload), 10, 32)
+		return uint32(v), err
+	case tagUint64:
+		return strconv.ParseUint(string(payload), 10, 64)
+	case tagFloat32:
+		v, err := strconv.ParseFloat(string(payload), 32)
+		return float32(v), err
+	case tagFloat64:
+		return strconv.ParseFloat(string(payload), 64)
+	case tagBool:
+		return strconv.ParseBool(string(payload))
+	case tagSerialized:
+		var value interface{}
+		err := serializer.Unmarshal(payload, &value)
+		return value, err
+	case tagBox:
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("empty boxed value")
+		}
+		inner, err := decodeTyped(payload[0], payload[1:], serializer)
+		if err != nil {
 			return nil, err
 		}
-		// Make a copy of the bytes to avoid buffer reuse issues
-		result := make([]byte, buf.Len())
-		copy(result, buf.Bytes())
-		return result, nil
+		return PrimitiveBox{V: inner}, nil
+	default:
+		return nil, fmt.Errorf("unknown value type tag %d", tag)
 	}
 }
 
@@ -144,3 +253,79 @@ func parsePrimitiveFromString(s string) (interface{}, bool) {
 	// If all parsing fails, return the original string
 	return s, true
 }
+
+// deepCopy returns a copy of value that shares no mutable memory with it, for CopyOnRead.
+// Slices, maps, arrays, pointers, interfaces and exported struct fields are cloned
+// recursively; unexported struct fields, functions and channels are copied as-is.
+func deepCopy(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128:
+		return value
+	}
+	return cloneValue(reflect.ValueOf(value), make(map[uintptr]reflect.Value)).Interface()
+}
+
+// cloneValue deep-copies v; seen maps original pointers to their clones so cycles terminate
+func cloneValue(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if clone, ok := seen[v.Pointer()]; ok {
+			return clone
+		}
+		clone := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = clone
+		clone.Elem().Set(cloneValue(v.Elem(), seen))
+		return clone
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(clone, v)
+			return clone
+		}
+		for i := 0; i < v.Len(); i++ {
+			clone.Index(i).Set(cloneValue(v.Index(i), seen))
+		}
+		return clone
+	case reflect.Array:
+		clone := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			clone.Index(i).Set(cloneValue(v.Index(i), seen))
+		}
+		return clone
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			clone.SetMapIndex(cloneValue(iter.Key(), seen), cloneValue(iter.Value(), seen))
+		}
+		return clone
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := clone.Field(i); field.CanSet() {
+				field.Set(cloneValue(v.Field(i), seen))
+			}
+		}
+		return clone
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(cloneValue(v.Elem(), seen))
+		return clone
+	default:
+		return v
+	}
+}
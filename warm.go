@@ -0,0 +1,172 @@
+// warm.go: Bulk preloading for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WarmEntry is a known-hot entry for StrategicCache.Warm
+type WarmEntry struct {
+	Key   string
+	Value interface{}
+	// TTL overrides the cache TTL for this entry (0 = the cache TTL)
+	TTL time.Duration
+	// Frequency is the number of accesses recorded in the admission sketch before the entry
+	// is stored, so warmed keys are not displaced by the first newcomers (0 = none).
+	// It applies to W-TinyLFU and to the sharded path with "tinylfu" admission.
+	Frequency int
+}
+
+// Warm bulk-loads entries known to be hot, such as popular keys replayed at startup.
+// Unlike Set it bypasses the admission policy, and it groups entries by shard so each
+// shard is locked once. Entries still respect MaxKeySize, MaxValueSize and the memory
+// budget, and may lose to resident keys in the W-TinyLFU or "tinylfu" frequency filter
+// unless Frequency is set. Warm returns how many entries were stored; entries the cache
+// can never hold are skipped and reported together in err, while the cache being closed
+// or disabled fails the whole call.
+func (sc *StrategicCache) Warm(items []WarmEntry) (admitted int, err error) {
+	if !sc.config.EnableCaching {
+		return 0, ErrCachingDisabled
+	}
+
+	sc.closedMu.RLock()
+	if sc.closed {
+		sc.closedMu.RUnlock()
+		return 0, ErrCacheClosed
+	}
+	sc.closedMu.RUnlock()
+	if err := sc.checkWritable(); err != nil {
+		return 0, err
+	}
+
+	maxKeySize, maxValueSize := sc.sizeLimits()
+	now := sc.clock.Now()
+	var errs []error
+
+	// Reject what Set would reject before the admission policy
+	valid := make([]WarmEntry, 0, len(items))
+	for _, item := range items {
+		var reason error
+		switch {
+		case maxKeySize > 0 && len(item.Key) > maxKeySize:
+			reason = ErrKeyTooLarge
+		case maxValueSize > 0 && calculateSize(item.Value) > maxValueSize:
+			reason = ErrValueTooLarge
+		case item.Value != nil && (reflect.TypeOf(item.Value).Kind() == reflect.Func || reflect.TypeOf(item.Value).Kind() == reflect.Chan):
+			reason = ErrNotSerializable
+		}
+		if reason != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", item.Key, reason))
+			continue
+		}
+		valid = append(valid, item)
+		sc.untag(item.Key) // Warmed entries replace any tagged value and carry no tags
+	}
+
+	ttl := func(item WarmEntry) time.Duration {
+		if item.TTL > 0 {
+			return item.TTL
+		}
+		return sc.entryTTL()
+	}
+	expiresAt := func(item WarmEntry) int64 {
+		if ttl(item) <= 0 {
+			return 0
+		}
+		return now.Add(ttl(item)).UnixNano()
+	}
+	slide := func(item WarmEntry) time.Duration {
+		if !sc.config.SlidingTTL {
+			return 0
+		}
+		return ttl(item)
+	}
+	toWarmItem := func(item WarmEntry) warmItem {
+		return warmItem{
+			key:       item.Key,
+			value:     item.Value,
+			attrs:     nodeAttrs{size: calculateSize(item.Value), cost: 1, expiresAt: expiresAt(item), slide: int64(slide(item))},
+			frequency: item.Frequency,
+		}
+	}
+
+	if sc.wtinylfu != nil {
+		groups := make(map[*WTinyLFUShard][]warmItem)
+		for _, item := range valid {
+			if item.Key == "" {
+				continue
+			}
+			shard := sc.wtinylfu.getShard(item.Key)
+			groups[shard] = append(groups[shard], toWarmItem(item))
+		}
+		for shard, group := range groups {
+			admitted += shard.setBatch(group)
+		}
+		return admitted, errors.Join(errs...)
+	}
+
+	if sc.arc != nil {
+		groups := make(map[*ARCShard][]warmItem)
+		for _, item := range valid {
+			if item.Key == "" {
+				continue
+			}
+			shard := sc.arc.getShard(item.Key)
+			groups[shard] = append(groups[shard], toWarmItem(item))
+		}
+		for shard, group := range groups {
+			admitted += shard.setBatch(group)
+		}
+		return admitted, errors.Join(errs...)
+	}
+
+	type encodedEntry struct {
+		key       string
+		value     storedValue
+		opts      setOptions
+		maxCost   int64
+		frequency int
+	}
+	groups := make(map[*cacheShard][]encodedEntry)
+	for _, item := range valid {
+		v, err := sc.encodeValue(item.Value, -1)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", item.Key, err))
+			continue
+		}
+		opts := defaultSetOptions
+		opts.expiresAt = expiresAt(item)
+		opts.slide = slide(item)
+		maxCost, err := sc.checkStorable(v, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", item.Key, err))
+			continue
+		}
+		shard := sc.getShard(item.Key)
+		groups[shard] = append(groups[shard], encodedEntry{key: item.Key, value: v, opts: opts, maxCost: maxCost, frequency: item.Frequency})
+	}
+	for shard, group := range groups {
+		shard.mu.Lock()
+		for _, e := range group {
+			if shard.sketch != nil {
+				for i := 0; i < e.frequency; i++ {
+					shard.sketch.Record(e.key)
+				}
+			}
+			if sc.storeLocked(shard, e.key, e.value, e.opts, e.maxCost) == nil {
+				admitted++
+			}
+		}
+		shard.mu.Unlock()
+		shard.evictQueue.flush()
+	}
+	return admitted, errors.Join(errs...)
+}
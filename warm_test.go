@@ -0,0 +1,160 @@
+// warm_test.go: Tests for bulk preloading with Warm
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func warmEntries(n int) []WarmEntry {
+	items := make([]WarmEntry, n)
+	for i := range items {
+		items[i] = WarmEntry{Key: fmt.Sprintf("hot:%d", i), Value: i}
+	}
+	return items
+}
+
+func TestWarm_AllPolicies(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:     true,
+				CacheSize:         1000,
+				ShardCount:        4,
+				TTL:               time.Hour,
+				EvictionPolicy:    policy,
+				EnableCompression: policy == "lru",
+			})
+			defer cache.Close()
+
+			admitted, err := cache.Warm(warmEntries(50))
+			if err != nil {
+				t.Fatalf("Warm: %v", err)
+			}
+			if admitted != 50 {
+				t.Errorf("expected 50 entries admitted, got %d", admitted)
+			}
+			for i := 0; i < 50; i++ {
+				if v, ok := cache.Get(fmt.Sprintf("hot:%d", i)); !ok || v != i {
+					t.Errorf("expected hot:%d=%d, got %v (found %v)", i, i, v, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestWarm_BypassesAdmissionPolicy(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:        true,
+				CacheSize:            1000,
+				ShardCount:           4,
+				TTL:                  time.Hour,
+				EvictionPolicy:       policy,
+				AdmissionPolicy:      "probabilistic",
+				AdmissionProbability: 0,
+			})
+			defer cache.Close()
+
+			if cache.Set("cold", 1) {
+				t.Fatal("expected Set to be rejected with admission probability 0")
+			}
+			admitted, err := cache.Warm(warmEntries(20))
+			if err != nil || admitted != 20 {
+				t.Fatalf("expected Warm to admit all 20 entries, got %d (err %v)", admitted, err)
+			}
+		})
+	}
+}
+
+func TestWarm_EntryTTL(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:  true,
+				CacheSize:      1000,
+				ShardCount:     4,
+				TTL:            time.Hour,
+				EvictionPolicy: policy,
+				Clock:          clock,
+			})
+			defer cache.Close()
+
+			if _, err := cache.Warm([]WarmEntry{
+				{Key: "short", Value: "a", TTL: 50 * time.Millisecond},
+				{Key: "long", Value: "b"},
+			}); err != nil {
+				t.Fatalf("Warm: %v", err)
+			}
+			clock.Advance(100 * time.Millisecond)
+			if _, ok := cache.Get("short"); ok {
+				t.Error("expected the entry with a short TTL to expire")
+			}
+			if _, ok := cache.Get("long"); !ok {
+				t.Error("expected the entry with the cache TTL to remain")
+			}
+		})
+	}
+}
+
+func TestWarm_RespectsLimits(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		EvictionPolicy: "wtinylfu",
+		MaxKeySize:     16,
+		MaxValueSize:   64,
+	})
+	defer cache.Close()
+
+	admitted, err := cache.Warm([]WarmEntry{
+		{Key: "ok", Value: "fine"},
+		{Key: strings.Repeat("k", 32), Value: "long key"},
+		{Key: "big", Value: strings.Repeat("v", 128)},
+	})
+	if admitted != 1 {
+		t.Errorf("expected 1 entry admitted, got %d", admitted)
+	}
+	if !errors.Is(err, ErrKeyTooLarge) || !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected ErrKeyTooLarge and ErrValueTooLarge, got %v", err)
+	}
+}
+
+func TestWarm_SeedsFrequencySketch(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     1,
+		EvictionPolicy: "wtinylfu",
+	})
+	defer cache.Close()
+
+	if _, err := cache.Warm([]WarmEntry{{Key: "seeded", Value: 1, Frequency: 8}, {Key: "plain", Value: 2}}); err != nil {
+		t.Fatalf("Warm: %v", err)
+	}
+	filter := cache.wtinylfu.getShard("seeded").admissionFilter
+	if seeded, plain := filter.Estimate("seeded"), filter.Estimate("plain"); seeded <= plain {
+		t.Errorf("expected the seeded key to be estimated hotter than the plain one, got %d <= %d", seeded, plain)
+	}
+}
+
+func TestWarm_Closed(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100})
+	cache.Close()
+	if _, err := cache.Warm(warmEntries(1)); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("expected ErrCacheClosed, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// windowed_stats.go: Hit rate and evictions over a sliding time window
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WindowedStats summarizes cache activity over the last CacheConfig.StatsWindow,
+// so recent regressions are not hidden by the lifetime counters of CacheStats
+type WindowedStats struct {
+	Window    time.Duration `json:"window"`      // Span covered: StatsWindow, or less for a younger cache
+	Hits      int64         `json:"hits"`        // Lookups that found a live entry
+	Misses    int64         `json:"misses"`      // Lookups that found nothing or an expired entry
+	Evictions int64         `json:"evictions"`   // Entries evicted to make room
+	HitRate   float64       `json:"hit_rate"`    // Hits / (Hits + Misses), 0 without lookups
+	OpsPerSec float64       `json:"ops_per_sec"` // Lookups per second over Window
+}
+
+// WindowedStats returns hits, misses and evictions over the last StatsWindow.
+// It returns the zero value when StatsWindow is not set.
+func (sc *StrategicCache) WindowedStats() WindowedStats {
+	if sc.config.StatsWindow <= 0 {
+		return WindowedStats{}
+	}
+
+	now := sc.clock.Now()
+	epoch := now.UnixNano() / sc.shards[0].window.width
+	var stats WindowedStats
+	for i := range sc.shards {
+		sc.shards[i].window.addTo(&stats, epoch)
+	}
+	if sc.wtinylfu != nil {
+		for _, shard := range sc.wtinylfu.shards {
+			shard.window.addTo(&stats, epoch)
+		}
+	}
+	if sc.arc != nil {
+		for _, shard := range sc.arc.shards {
+			shard.window.addTo(&stats, epoch)
+		}
+	}
+
+	stats.Window = sc.config.StatsWindow
+	if age := now.Sub(sc.windowStart); age < stats.Window {
+		stats.Window = age
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+		if stats.Window > 0 {
+			stats.OpsPerSec = float64(lookups) / stats.Window.Seconds()
+		}
+	}
+	return stats
+}
+
+// statsWindow counts hits, misses and evictions in a ring of time buckets. A bucket
+// is recycled when the first record after its epoch has passed lands on it, and reads
+// skip buckets older than the ring, so no goroutine rotates them. A record racing
+// with a recycle may be lost, which the counts tolerate.
+type statsWindow struct {
+	buckets []statsBucket
+	width   int64 // Bucket span in nanoseconds
+	clock   Clock
+}
+
+// statsBucket holds the counts recorded during one bucket span
+type statsBucket struct {
+	epoch     atomic.Int64 // Span the counts belong to: UnixNano / width
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// newStatsWindow covers window with buckets buckets of equal span, at least 1ns each
+func newStatsWindow(window time.Duration, buckets int, clock Clock) *statsWindow {
+	if int64(buckets) > int64(window) {
+		buckets = int(window)
+	}
+	return &statsWindow{
+		buckets: make([]statsBucket, buckets),
+		width:   int64(window) / int64(buckets),
+		clock:   clock,
+	}
+}
+
+// current returns the bucket for the present span, recycling it if it still holds an older one
+func (w *statsWindow) current() *statsBucket {
+	epoch := w.clock.Now().UnixNano() / w.width
+	b := &w.buckets[epoch%int64(len(w.buckets))]
+	if old := b.epoch.Load(); old != epoch && b.epoch.CompareAndSwap(old, epoch) {
+		b.hits.Store(0)
+		b.misses.Store(0)
+		b.evictions.Store(0)
+	}
+	return b
+}
+
+func (w *statsWindow) hit()   { w.current().hits.Add(1) }
+func (w *statsWindow) miss()  { w.current().misses.Add(1) }
+func (w *statsWindow) evict() { w.current().evictions.Add(1) }
+
+// reset zeroes every bucket. A nil window is left alone.
+func (w *statsWindow) reset() {
+	if w == nil {
+		return
+	}
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		b.hits.Store(0)
+		b.misses.Store(0)
+		b.evictions.Store(0)
+	}
+}
+
+// addTo adds the counts of the buckets still inside the window at epoch to stats.
+// A nil window adds nothing.
+func (w *statsWindow) addTo(stats *WindowedStats, epoch int64) {
+	if w == nil {
+		return
+	}
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if age := epoch - b.epoch.Load(); age < 0 || age >= int64(len(w.buckets)) {
+			continue
+		}
+		stats.Hits += b.hits.Load()
+		stats.Misses += b.misses.Load()
+		stats.Evictions += b.evictions.Load()
+	}
+}
@@ -0,0 +1,142 @@
+// windowed_stats_test.go: Tests for statistics over a sliding time window
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+func TestWindowedStats_CountsRecentLookups(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:      true,
+				CacheSize:          1000,
+				ShardCount:         4,
+				TTL:                time.Hour,
+				EvictionPolicy:     policy,
+				StatsWindow:        time.Minute,
+				StatsWindowBuckets: 6,
+				Clock:              clock,
+			})
+			defer cache.Close()
+			cache.Set("a", 1)
+			for i := 0; i < 30; i++ {
+				cache.Get("a")
+			}
+			for i := 0; i < 10; i++ {
+				cache.Get(fmt.Sprintf("missing%d", i))
+			}
+			clock.Advance(20 * time.Second)
+
+			stats := cache.WindowedStats()
+			if stats.Hits != 30 || stats.Misses != 10 {
+				t.Fatalf("expected 30 hits and 10 misses, got %+v", stats)
+			}
+			if stats.HitRate != 0.75 {
+				t.Errorf("expected a 0.75 hit rate, got %v", stats.HitRate)
+			}
+			if stats.Window != 20*time.Second || stats.OpsPerSec != 2 {
+				t.Errorf("expected 40 lookups over the 20s the cache has lived, got %v at %v/s", stats.Window, stats.OpsPerSec)
+			}
+
+			// A minute later only the new misses are in the window
+			clock.Advance(time.Minute)
+			for i := 0; i < 6; i++ {
+				cache.Get("missing")
+			}
+			stats = cache.WindowedStats()
+			if stats.Hits != 0 || stats.Misses != 6 || stats.HitRate != 0 {
+				t.Errorf("expected only the 6 recent misses, got %+v", stats)
+			}
+			if stats.Window != time.Minute || stats.OpsPerSec != 0.1 {
+				t.Errorf("expected 6 lookups over a full minute, got %v at %v/s", stats.Window, stats.OpsPerSec)
+			}
+
+			// Lifetime counters are unaffected
+			if lifetime := cache.GetStats(); lifetime.Hits != 30 || lifetime.Misses != 16 {
+				t.Errorf("expected 30 lifetime hits and 16 misses, got %+v", lifetime)
+			}
+		})
+	}
+}
+
+func TestWindowedStats_BucketsExpireGradually(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:      true,
+		CacheSize:          1000,
+		ShardCount:         4,
+		TTL:                time.Hour,
+		EvictionPolicy:     "lru",
+		StatsWindow:        time.Minute,
+		StatsWindowBuckets: 6,
+		Clock:              clock,
+	})
+	defer cache.Close()
+	for i := 0; i < 6; i++ {
+		cache.Get("missing") // One miss in each 10s bucket
+		clock.Advance(10 * time.Second)
+	}
+
+	for want := int64(5); want >= 0; want-- {
+		if got := cache.WindowedStats().Misses; got != want {
+			t.Fatalf("expected %d misses in the window, got %d", want, got)
+		}
+		clock.Advance(10 * time.Second)
+	}
+}
+
+func TestWindowedStats_Evictions(t *testing.T) {
+	for _, policy := range []string{"lru", "wtinylfu", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			clock := fakeclock.New(time.Time{})
+			cache := NewStrategicCache(CacheConfig{
+				EnableCaching:      true,
+				CacheSize:          40,
+				ShardCount:         4,
+				TTL:                time.Hour,
+				EvictionPolicy:     policy,
+				StatsWindow:        time.Minute,
+				StatsWindowBuckets: 6,
+				Clock:              clock,
+			})
+			defer cache.Close()
+			for i := 0; i < 200; i++ {
+				cache.Set(fmt.Sprintf("k%d", i), i)
+			}
+
+			stats := cache.WindowedStats()
+			if lifetime := cache.GetStats().Evictions; stats.Evictions == 0 || stats.Evictions != lifetime {
+				t.Errorf("expected the %d lifetime evictions in the window, got %d", lifetime, stats.Evictions)
+			}
+			clock.Advance(2 * time.Minute)
+			if n := cache.WindowedStats().Evictions; n != 0 {
+				t.Errorf("expected evictions to leave the window, got %d", n)
+			}
+		})
+	}
+}
+
+func TestWindowedStats_Disabled(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{EnableCaching: true, CacheSize: 100, TTL: time.Minute})
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Get("a")
+
+	if stats := cache.WindowedStats(); stats != (WindowedStats{}) {
+		t.Errorf("expected zero windowed stats without StatsWindow, got %+v", stats)
+	}
+	if cache.shards[0].window != nil {
+		t.Error("expected no windowed counters without StatsWindow")
+	}
+}
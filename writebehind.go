@@ -0,0 +1,399 @@
+// writebehind.go: Asynchronous write-behind to a Backend for Metis strategic caching library
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteBehindFull is returned by Set and Delete when the write-behind buffer has no room;
+// the cache and backend are left unchanged
+var ErrWriteBehindFull = errors.New("metis: write-behind buffer is full")
+
+// Write-behind defaults, used when the corresponding CacheConfig field is zero
+const (
+	DefaultWriteBehindBufferSize    = 1024
+	DefaultWriteBehindBatchSize     = 100
+	DefaultWriteBehindFlushInterval = time.Second
+	DefaultWriteBehindRetries       = 3
+	DefaultWriteBehindRetryBackoff  = 100 * time.Millisecond
+)
+
+// BackendWrite is a pending write-behind operation: a Store, or a Delete when Delete is set
+type BackendWrite struct {
+	Key    string
+	Value  interface{}
+	Delete bool
+}
+
+// BatchBackend is implemented by backends that can apply several writes at once.
+// Write-behind passes each batch to StoreBatch instead of calling Store and Delete per key;
+// the writes are in order and a key appears at most as often as it was written.
+type BatchBackend interface {
+	Backend
+	StoreBatch(ctx context.Context, writes []BackendWrite) error
+}
+
+// WriteBehindStats describes the write-behind queue (see CacheConfig.WriteBehind)
+type WriteBehindStats struct {
+	// Queued is the number of writes accepted but not yet written or given up on
+	Queued int64 `json:"queued"`
+	// Flushed counts writes applied to the backend
+	Flushed int64 `json:"flushed"`
+	// Failed counts writes abandoned after the backend kept failing
+	Failed int64 `json:"failed"`
+	// Dropped counts writes rejected with ErrWriteBehindFull
+	Dropped int64 `json:"dropped"`
+	// Lost counts writes abandoned because CloseWithContext timed out before they were written
+	Lost int64 `json:"lost"`
+}
+
+// writeBehind queues backend writes and applies them in batches from worker goroutines.
+// Writes to a key always go to the same worker, so they reach the backend in order.
+type writeBehind struct {
+	backend   Backend
+	logger    Logger
+	batchSize int
+	interval  time.Duration
+	retries   int
+	backoff   time.Duration
+
+	seed    maphash.Seed
+	queues  []chan BackendWrite
+	flushes []chan chan error // Flush requests, answered once the worker's queue is drained
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	// ctx is passed to the backend and canceled by abort when a close times out, after
+	// which the workers discard what is left
+	ctx   context.Context
+	abort context.CancelFunc
+
+	// mu orders enqueues before close: once closed is set no write can be queued
+	mu     sync.RWMutex
+	closed bool
+
+	queued  atomic.Int64
+	flushed atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+	lost    atomic.Int64
+}
+
+// newWriteBehind starts the workers for config, filling in defaults for zero fields
+func newWriteBehind(config CacheConfig) *writeBehind {
+	bufferSize := config.WriteBehindBufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultWriteBehindBufferSize
+	}
+	workers := config.WriteBehindWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	wb := &writeBehind{
+		backend:   config.Backend,
+		logger:    config.Logger,
+		batchSize: config.WriteBehindBatchSize,
+		interval:  config.WriteBehindFlushInterval,
+		retries:   config.WriteBehindRetries,
+		backoff:   config.WriteBehindRetryBackoff,
+		seed:      maphash.MakeSeed(),
+		queues:    make([]chan BackendWrite, workers),
+		flushes:   make([]chan chan error, workers),
+		stop:      make(chan struct{}),
+	}
+	wb.ctx, wb.abort = context.WithCancel(context.Background())
+	if wb.batchSize <= 0 {
+		wb.batchSize = DefaultWriteBehindBatchSize
+	}
+	if wb.interval <= 0 {
+		wb.interval = DefaultWriteBehindFlushInterval
+	}
+	if wb.retries == 0 {
+		wb.retries = DefaultWriteBehindRetries
+	} else if wb.retries < 0 {
+		wb.retries = 0
+	}
+	if wb.backoff <= 0 {
+		wb.backoff = DefaultWriteBehindRetryBackoff
+	}
+
+	// Split the buffer across workers, rounding up so none is left without room
+	perWorker := (bufferSize + workers - 1) / workers
+	for i := range wb.queues {
+		wb.queues[i] = make(chan BackendWrite, perWorker)
+		wb.flushes[i] = make(chan chan error)
+		wb.wg.Add(1)
+		go wb.worker(wb.queues[i], wb.flushes[i])
+	}
+	return wb
+}
+
+// enqueue queues a write, or reports why it could not. With a context that can be
+// canceled it waits for room in a full buffer until ctx is done, and returns ctx.Err();
+// otherwise it does not block and returns ErrWriteBehindFull.
+func (wb *writeBehind) enqueue(ctx context.Context, w BackendWrite) error {
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+	if wb.closed {
+		return ErrCacheClosed
+	}
+
+	queue := wb.queues[maphash.String(wb.seed, w.Key)%uint64(len(wb.queues))]
+	select {
+	case queue <- w:
+		wb.queued.Add(1)
+		return nil
+	default:
+	}
+	if ctx.Done() == nil {
+		wb.dropped.Add(1)
+		return ErrWriteBehindFull
+	}
+	// The workers keep draining until close, which waits for this read lock
+	select {
+	case queue <- w:
+		wb.queued.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// worker batches writes from queue, writing a batch when it is full, every interval,
+// on a flush request and, after stop, until the queue is empty
+func (wb *writeBehind) worker(queue chan BackendWrite, flushes chan chan error) {
+	defer wb.wg.Done()
+	ticker := time.NewTicker(wb.interval)
+	defer ticker.Stop()
+
+	batch := make([]BackendWrite, 0, wb.batchSize)
+	write := func() error {
+		err := wb.writeBatch(batch)
+		batch = batch[:0]
+		return err
+	}
+	// discard gives up on the batch and everything queued, once a close timed out
+	discard := func() {
+		n := len(batch)
+		batch = batch[:0]
+		for len(queue) > 0 {
+			<-queue
+			n++
+		}
+		wb.lost.Add(int64(n))
+		wb.queued.Add(-int64(n))
+	}
+	// drain writes everything queued so far, returning the failures
+	drain := func() error {
+		var errs []error
+		for {
+			if wb.ctx.Err() != nil {
+				discard()
+				return errors.Join(errs...)
+			}
+			select {
+			case w := <-queue:
+				batch = append(batch, w)
+				if len(batch) >= wb.batchSize {
+					errs = append(errs, write())
+				}
+			default:
+				errs = append(errs, write())
+				return errors.Join(errs...)
+			}
+		}
+	}
+
+	for {
+		select {
+		case w := <-queue:
+			batch = append(batch, w)
+			if len(batch) >= wb.batchSize {
+				_ = write()
+			}
+		case <-ticker.C:
+			_ = write()
+		case reply := <-flushes:
+			reply <- drain()
+		case <-wb.stop:
+			_ = drain()
+			return
+		}
+	}
+}
+
+// writeBatch applies a batch, retrying failures with exponential backoff. Writes still
+// failing after the last retry are counted as failed and reported to the logger.
+func (wb *writeBehind) writeBatch(batch []BackendWrite) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	pending := batch
+	var err error
+	backoff := wb.backoff
+	for attempt := 0; ; attempt++ {
+		pending, err = wb.apply(pending)
+		done := int64(len(batch) - len(pending))
+		wb.flushed.Add(done)
+		wb.queued.Add(-done)
+		batch = pending
+		if err == nil || attempt >= wb.retries || wb.ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-wb.ctx.Done():
+		}
+		backoff *= 2
+	}
+	if err == nil {
+		return nil
+	}
+	if wb.ctx.Err() != nil {
+		wb.lost.Add(int64(len(pending)))
+		wb.queued.Add(-int64(len(pending)))
+		return err
+	}
+
+	wb.failed.Add(int64(len(pending)))
+	wb.queued.Add(-int64(len(pending)))
+	err = fmt.Errorf("%w: write-behind gave up on %d writes: %w", ErrBackend, len(pending), err)
+	if wb.logger != nil {
+		wb.logger.Error("metis: write-behind failed", "writes", len(pending), "error", err)
+	}
+	return err
+}
+
+// apply sends writes to the backend and returns those that failed with the last error
+func (wb *writeBehind) apply(writes []BackendWrite) ([]BackendWrite, error) {
+	ctx := wb.ctx
+	if batcher, ok := wb.backend.(BatchBackend); ok {
+		if err := batcher.StoreBatch(ctx, writes); err != nil {
+			return writes, err
+		}
+		return nil, nil
+	}
+
+	var failed []BackendWrite
+	var lastErr error
+	for i, w := range writes {
+		if ctx.Err() != nil {
+			return append(failed, writes[i:]...), ctx.Err()
+		}
+		var err error
+		if w.Delete {
+			err = wb.backend.Delete(ctx, w.Key)
+			if errors.Is(err, ErrNotFound) {
+				err = nil
+			}
+		} else {
+			err = wb.backend.Store(ctx, w.Key, w.Value)
+		}
+		if err != nil {
+			failed = append(failed, w)
+			lastErr = err
+		}
+	}
+	return failed, lastErr
+}
+
+// flush waits until every write queued before the call has been written or given up on
+func (wb *writeBehind) flush(ctx context.Context) error {
+	replies := make([]chan error, len(wb.flushes))
+	for i, requests := range wb.flushes {
+		replies[i] = make(chan error, 1)
+		select {
+		case requests <- replies[i]:
+		case <-wb.stop:
+			// Closing drains the queues instead
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	for _, reply := range replies {
+		select {
+		case err := <-reply:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// close stops accepting writes and waits for the workers to write everything queued.
+// If ctx ends first, the backend calls in progress are canceled, the writes still queued
+// are counted as lost and close returns ctx.Err() with their number.
+func (wb *writeBehind) close(ctx context.Context) error {
+	wb.mu.Lock()
+	if wb.closed {
+		wb.mu.Unlock()
+		return nil
+	}
+	wb.closed = true
+	wb.mu.Unlock()
+
+	close(wb.stop)
+	done := make(chan struct{})
+	go func() {
+		wb.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		wb.abort() // Releases the context
+		return nil
+	case <-ctx.Done():
+	}
+
+	pending := wb.queued.Load()
+	wb.abort()
+	if wb.logger != nil {
+		wb.logger.Error("metis: close timed out, write-behind writes lost", "writes", pending, "error", ctx.Err())
+	}
+	return fmt.Errorf("%w: %d write-behind writes were not flushed", ctx.Err(), pending)
+}
+
+// stats returns the queue counters
+func (wb *writeBehind) stats() WriteBehindStats {
+	return WriteBehindStats{
+		Queued:  wb.queued.Load(),
+		Flushed: wb.flushed.Load(),
+		Failed:  wb.failed.Load(),
+		Dropped: wb.dropped.Load(),
+		Lost:    wb.lost.Load(),
+	}
+}
+
+// Flush waits until every write-behind write queued before the call has reached the
+// backend, returning ctx.Err() if ctx ends first and ErrBackend for writes that failed
+// after all retries. Without write-behind it returns nil immediately.
+func (sc *StrategicCache) Flush(ctx context.Context) error {
+	if sc.writeBehind == nil {
+		return nil
+	}
+	return sc.writeBehind.flush(ctx)
+}
+
+// WriteBehindStats returns write-behind queue statistics, all zero without write-behind
+func (sc *StrategicCache) WriteBehindStats() WriteBehindStats {
+	if sc.writeBehind == nil {
+		return WriteBehindStats{}
+	}
+	return sc.writeBehind.stats()
+}
@@ -0,0 +1,360 @@
+// writebehind_test.go: Tests for asynchronous write-behind to a Backend
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowBackend wraps mapBackend with a per-write delay, a gate that blocks writes until
+// closed, and a number of writes to fail before succeeding
+type slowBackend struct {
+	*mapBackend
+	delay    time.Duration
+	gate     chan struct{}
+	failures int
+}
+
+func (b *slowBackend) Store(ctx context.Context, key string, value interface{}) error {
+	if b.gate != nil {
+		<-b.gate
+	}
+	time.Sleep(b.delay)
+	b.mu.Lock()
+	if b.failures > 0 {
+		b.failures--
+		b.mu.Unlock()
+		return errors.New("temporary failure")
+	}
+	b.mu.Unlock()
+	return b.mapBackend.Store(ctx, key, value)
+}
+
+// batchingBackend records the size of each StoreBatch call
+type batchingBackend struct {
+	*mapBackend
+	mu      sync.Mutex
+	batches []int
+}
+
+func (b *batchingBackend) StoreBatch(ctx context.Context, writes []BackendWrite) error {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(writes))
+	b.mu.Unlock()
+	for _, w := range writes {
+		if w.Delete {
+			_ = b.mapBackend.Delete(ctx, w.Key)
+		} else {
+			_ = b.mapBackend.Store(ctx, w.Key, w.Value)
+		}
+	}
+	return nil
+}
+
+func newWriteBehindCache(backend Backend, tune func(*CacheConfig)) *StrategicCache {
+	config := CacheConfig{
+		EnableCaching:            true,
+		CacheSize:                10000,
+		ShardCount:               4,
+		TTL:                      time.Hour,
+		Backend:                  backend,
+		WriteBehind:              true,
+		WriteBehindFlushInterval: time.Hour, // Tests flush explicitly
+		WriteBehindRetryBackoff:  time.Millisecond,
+	}
+	if tune != nil {
+		tune(&config)
+	}
+	return NewStrategicCache(config)
+}
+
+func TestWriteBehind_FlushWritesQueued(t *testing.T) {
+	backend := newMapBackend()
+	cache := newWriteBehindCache(backend, nil)
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := cache.SetE(fmt.Sprintf("k%d", i), i); err != nil {
+			t.Fatalf("SetE: %v", err)
+		}
+	}
+	if v, ok := cache.Get("k3"); !ok || v != 3 {
+		t.Errorf("expected queued writes to be readable from memory, got %v (found %v)", v, ok)
+	}
+	if err := cache.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := backend.get(fmt.Sprintf("k%d", i)); !ok || v != i {
+			t.Errorf("expected k%d=%d in the backend after Flush, got %v (found %v)", i, i, v, ok)
+		}
+	}
+	stats := cache.WriteBehindStats()
+	if stats.Flushed != 10 || stats.Queued != 0 {
+		t.Errorf("expected 10 flushed and none queued, got %+v", stats)
+	}
+}
+
+func TestWriteBehind_CloseKeepsAcknowledgedWrites(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), delay: time.Millisecond}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) {
+		c.WriteBehindBufferSize = 1000
+		c.WriteBehindBatchSize = 7
+		c.WriteBehindWorkers = 3
+	})
+
+	var acknowledged []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("g%d:k%d", g, i)
+				if cache.Set(key, i) {
+					mu.Lock()
+					acknowledged = append(acknowledged, key)
+					mu.Unlock()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	cache.Close()
+
+	if len(acknowledged) == 0 {
+		t.Fatal("expected some writes to be acknowledged")
+	}
+	for _, key := range acknowledged {
+		if _, ok := backend.get(key); !ok {
+			t.Fatalf("acknowledged write %s was lost by Close", key)
+		}
+	}
+}
+
+func TestWriteBehind_OrderPerKey(t *testing.T) {
+	backend := newMapBackend()
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) { c.WriteBehindWorkers = 4 })
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	cache.Set("k", 2)
+	cache.Delete("k")
+	cache.Set("other", 1)
+	if err := cache.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if _, ok := backend.get("k"); ok {
+		t.Error("expected the queued delete to apply after the queued sets")
+	}
+}
+
+func TestWriteBehind_Batches(t *testing.T) {
+	backend := &batchingBackend{mapBackend: newMapBackend()}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) { c.WriteBehindBatchSize = 4 })
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if err := cache.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	total := 0
+	for _, n := range backend.batches {
+		if n > 4 {
+			t.Errorf("expected batches of at most 4 writes, got %d", n)
+		}
+		total += n
+	}
+	if total != 10 {
+		t.Errorf("expected 10 writes in batches, got %d (%v)", total, backend.batches)
+	}
+}
+
+func TestWriteBehind_Retries(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), failures: 2}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) { c.WriteBehindRetries = 3 })
+	defer cache.Close()
+
+	cache.Set("k", "v")
+	if err := cache.Flush(context.Background()); err != nil {
+		t.Fatalf("expected the write to succeed after retries, got %v", err)
+	}
+	if _, ok := backend.get("k"); !ok {
+		t.Error("expected the retried write to reach the backend")
+	}
+}
+
+func TestWriteBehind_GivesUp(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), failures: 100}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) { c.WriteBehindRetries = 2 })
+	defer cache.Close()
+
+	cache.Set("k", "v")
+	if err := cache.Flush(context.Background()); !errors.Is(err, ErrBackend) {
+		t.Errorf("expected Flush to report ErrBackend, got %v", err)
+	}
+	if stats := cache.WriteBehindStats(); stats.Failed != 1 || stats.Queued != 0 {
+		t.Errorf("expected 1 failed write and none queued, got %+v", stats)
+	}
+	if backend.failures != 97 {
+		t.Errorf("expected 1 attempt and 2 retries, backend saw %d", 100-backend.failures)
+	}
+}
+
+func TestWriteBehind_FullBuffer(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), gate: make(chan struct{})}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) {
+		c.WriteBehindBufferSize = 2
+		c.WriteBehindBatchSize = 1
+	})
+
+	// The worker blocks on the first write, so the buffer fills up behind it
+	var full error
+	for i := 0; i < 10 && full == nil; i++ {
+		full = cache.SetE(fmt.Sprintf("k%d", i), i)
+	}
+	if !errors.Is(full, ErrWriteBehindFull) {
+		t.Fatalf("expected ErrWriteBehindFull, got %v", full)
+	}
+	if stats := cache.WriteBehindStats(); stats.Dropped != 1 {
+		t.Errorf("expected 1 dropped write, got %+v", stats)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cache.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Flush to honor its deadline, got %v", err)
+	}
+
+	close(backend.gate)
+	cache.Close()
+}
+
+func TestWriteBehind_SetCtxWaitsForRoom(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), gate: make(chan struct{})}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) {
+		c.WriteBehindBufferSize = 1
+		c.WriteBehindBatchSize = 1
+		c.WriteBehindWorkers = 1
+	})
+	defer cache.Close()
+
+	// One write blocks the worker and one fills the buffer behind it
+	for queued := 0; queued < 2; {
+		if err := cache.SetE(fmt.Sprintf("k%d", queued), queued); err == nil {
+			queued++
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cache.SetCtx(ctx, "waited", 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected SetCtx to wait until its deadline, got %v", err)
+	}
+	if _, ok := cache.Get("waited"); ok {
+		t.Error("expected a write that was not queued to leave the cache unchanged")
+	}
+	dropped := cache.WriteBehindStats().Dropped
+
+	// Once the worker drains the buffer, a waiting SetCtx gets in
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(backend.gate)
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cache.SetCtx(ctx, "waited", 2); err != nil {
+		t.Fatalf("expected SetCtx to get room, got %v", err)
+	}
+	if err := cache.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := backend.get("waited"); !ok || v != 2 {
+		t.Errorf("expected the backend to hold 2, got %v", v)
+	}
+	if stats := cache.WriteBehindStats(); stats.Dropped != dropped {
+		t.Errorf("expected writes given up on by their context not to count as dropped, got %+v", stats)
+	}
+}
+
+func TestCloseWithContext_DrainsWriteBehind(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), delay: 2 * time.Millisecond}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) {
+		c.WriteBehindBatchSize = 1
+		c.WriteBehindWorkers = 1
+	})
+	for i := 0; i < 20; i++ {
+		if err := cache.SetE(fmt.Sprintf("k%d", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cache.CloseWithContext(ctx); err != nil {
+		t.Fatalf("expected a graceful close, got %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if _, ok := backend.get(fmt.Sprintf("k%d", i)); !ok {
+			t.Errorf("expected k%d to survive the close", i)
+		}
+	}
+	if stats := cache.WriteBehindStats(); stats.Flushed != 20 || stats.Lost != 0 {
+		t.Errorf("expected every write flushed, got %+v", stats)
+	}
+	if err := cache.CloseWithContext(ctx); err != nil {
+		t.Errorf("expected closing twice to return nil, got %v", err)
+	}
+}
+
+func TestCloseWithContext_TimeoutLosesWrites(t *testing.T) {
+	backend := &slowBackend{mapBackend: newMapBackend(), gate: make(chan struct{})}
+	cache := newWriteBehindCache(backend, func(c *CacheConfig) {
+		c.WriteBehindBatchSize = 1
+		c.WriteBehindWorkers = 1
+	})
+	for i := 0; i < 10; i++ {
+		if err := cache.SetE(fmt.Sprintf("k%d", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cache.CloseWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The backend call in progress finishes; the writes behind it are discarded
+	close(backend.gate)
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.WriteBehindStats().Queued > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := cache.WriteBehindStats()
+	if stats.Lost < 9 || stats.Flushed+stats.Lost != 10 || stats.Queued != 0 {
+		t.Errorf("expected the unwritten writes reported lost, got %+v", stats)
+	}
+	for i := 1; i < 10; i++ {
+		if _, ok := backend.get(fmt.Sprintf("k%d", i)); ok {
+			t.Errorf("expected k%d not to be written after the close gave up", i)
+		}
+	}
+}
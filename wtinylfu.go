@@ -7,12 +7,11 @@
 package metis
 
 import (
-	"hash"
-	"hash/fnv"
+	"hash/maphash"
+	"math/bits"
 	"sync"
 	"sync/atomic"
 	"time"
-	"unsafe"
 )
 
 // WTinyLFU implements the W-TinyLFU (Windowed TinyLFU) eviction policy
@@ -20,9 +19,8 @@ type WTinyLFU struct {
 	shardMask  uint32
 	shardCount int
 	shards     []*WTinyLFUShard
-	disableTTL bool
-	hashPool   *sync.Pool
-	ttl        time.Duration
+	seed       maphash.Seed // Per-cache seed for shard selection
+	ttl        atomic.Int64 // time.Duration; zero or negative disables expiration
 }
 
 // WTinyLFUShard contains cache components
@@ -34,47 +32,216 @@ type WTinyLFUShard struct {
 	misses          atomic.Int64
 	readMu          sync.RWMutex
 	writeMu         sync.Mutex
-	windowSize      int
-	mainSize        int
-	ttl             time.Duration
+	windowSize      int          // Guarded by writeMu when the window is adaptive
+	mainSize        int          // Guarded by writeMu when the window is adaptive
+	capacity        int          // windowSize + mainSize, fixed at construction
+	ttl             atomic.Int64 // time.Duration, set by WTinyLFU.SetTTL
+	// maxBytes is this shard's share of the memory budget (0 = unlimited)
+	maxBytes           int64
+	memoryEvictedBytes atomic.Int64
+	// admissionRejects counts entries turned away because the admission filter
+	// estimated their victim more frequent
+	admissionRejects atomic.Int64
+	// adaptive is non-nil when the window/main split follows observed hit rates
+	adaptive *adaptiveWindow
+	// evictQueue holds entries evicted by any segment until the shard's locks are released
+	evictQueue evictQueue
+	// nodes recycles the nodes of all three segments
+	nodes nodePool
+	// count is the number of entries in all three segments, read by Size without locks
+	count atomic.Int64
+	// clock stamps and checks expiration (shared with the segments, see WTinyLFU.setClock)
+	clock Clock
+	// lazyCleanup is how many entries each write examines for expiration (see WTinyLFU.setLazyCleanup)
+	lazyCleanup int
+	// window counts recent hits, misses and evictions (see WTinyLFU.setStatsWindow)
+	window *statsWindow
+	// hotKeys follows the keys with the highest sketch estimates (see WTinyLFU.setHotKeys)
+	hotKeys *hotKeyTracker
 }
 
 // FastLRU is the LRU implementation
 type FastLRU struct {
-	data    map[string]*fastNode
-	head    *fastNode
-	tail    *fastNode
-	size    int
-	maxSize int
-	mu      sync.RWMutex
+	data        map[string]*fastNode
+	head        *fastNode
+	tail        *fastNode
+	size        int
+	maxSize     int
+	evictions   int64
+	expired     int64 // Items dropped because their TTL elapsed
+	idleExpired int64 // Items dropped because they went unread longer than maxIdle
+	maxIdle     int64 // Idle timeout in nanoseconds, 0 = none (see WTinyLFU.setMaxIdle)
+	pinned      int   // Items exempted from eviction and expiration (see WTinyLFU.setPinned)
+	bytes       int64 // Sum of node sizes
+	cost        int64 // Sum of node costs, bounded by maxSize
+	mu          sync.RWMutex
+	// evictQueue, when set, receives evicted items (the owning W-TinyLFU shard's queue)
+	evictQueue *evictQueue
+	// nodes recycles the nodes of this segment (shared by the segments of a W-TinyLFU shard)
+	nodes *nodePool
+	// count mirrors size for lock-free reads (shared by the segments of a W-TinyLFU shard,
+	// where it counts the shard's entries)
+	count *atomic.Int64
+	clock Clock // Checks expiration
+	// window, when set, counts evictions (the owning W-TinyLFU shard's windowed counters)
+	window *statsWindow
 }
 
 type fastNode struct {
 	key   string
 	value interface{}
-	prev  *fastNode
-	next  *fastNode
+	size  int   // Estimated value size in bytes
+	cost  int64 // Weight against maxSize (1 unless set via SetWithCost)
+	// expiresAt is the expiration time in UnixNano, 0 = never
+	expiresAt int64
+	// slide, when positive, is the sliding TTL in nanoseconds: each read moves expiresAt to now+slide
+	slide int64
+	// accessedAt is the last read or write in UnixNano, tracked while the segment has a maxIdle
+	accessedAt int64
+	// priority decides admission contests against nodes of another priority (see WithPriority)
+	priority Priority
+	// pinned exempts the node from eviction and expiration (see WTinyLFU.setPinned)
+	pinned bool
+	prev   *fastNode
+	next   *fastNode
+}
+
+// nodePool recycles the nodes of a W-TinyLFU shard's segments, counting its traffic
+// like EntryPool. A node is put back only once it is unlinked and no caller still reads it.
+type nodePool struct {
+	pool             sync.Pool
+	gets, puts, news atomic.Int64
+}
+
+// get returns a zeroed node
+func (p *nodePool) get() *fastNode {
+	p.gets.Add(1)
+	if node, ok := p.pool.Get().(*fastNode); ok {
+		return node
+	}
+	p.news.Add(1)
+	return &fastNode{}
+}
+
+// put zeroes a node and keeps it for reuse
+func (p *nodePool) put(node *fastNode) {
+	*node = fastNode{}
+	p.puts.Add(1)
+	p.pool.Put(node)
+}
+
+// stats reports the pool's traffic since it was created
+func (p *nodePool) stats() PoolStats {
+	return newPoolStats(p.gets.Load(), p.puts.Load(), p.news.Load())
+}
+
+// expired reports whether the node's TTL has elapsed at now (UnixNano)
+func (node *fastNode) expired(now int64) bool {
+	return node.expiresAt > 0 && now > node.expiresAt
+}
+
+// idleExpired reports whether the node went unread longer than maxIdle (nanoseconds, 0 = no limit)
+// at now, with the idle timeout firing before the TTL
+func (node *fastNode) idleExpired(now, maxIdle int64) bool {
+	return idleFirst(now, node.accessedAt, node.expiresAt, maxIdle)
+}
+
+// idleFirst reports whether an entry last accessed at accessedAt is past its idle timeout maxIdle
+// at now, ahead of its expiration at expiresAt (all nanoseconds; maxIdle or expiresAt 0 = none)
+func idleFirst(now, accessedAt, expiresAt, maxIdle int64) bool {
+	if maxIdle <= 0 {
+		return false
+	}
+	idleAt := accessedAt + maxIdle
+	return now > idleAt && (expiresAt == 0 || idleAt < expiresAt)
+}
+
+// touch records a read at now (UnixNano), restarting a sliding TTL; the caller must hold the write lock
+func (node *fastNode) touch(now int64) {
+	node.accessedAt = now
+	if node.slide > 0 {
+		node.expiresAt = now + node.slide
+	}
+}
+
+// nodeAttrs carries the per-entry attributes computed before a node is stored
+type nodeAttrs struct {
+	size      int
+	cost      int64
+	expiresAt int64 // UnixNano, 0 = never
+	slide     int64 // Sliding TTL in nanoseconds, 0 = fixed expiration
+	// accessedAt is the last read or write in UnixNano, 0 = now (kept when a node changes segment)
+	accessedAt int64
+	pinned     bool // Kept when a pinned node is promoted
+	priority   Priority
 }
 
 // FastSLRU implements Segmented LRU
 type FastSLRU struct {
-	probation *FastLRU
-	protected *FastLRU
-	hits      atomic.Int64
+	probation      *FastLRU
+	protected      *FastLRU
+	probationRatio float64
+	hits           atomic.Int64
 }
 
-// FastTinyLFU implements TinyLFU admission filter with Count-Min Sketch
+// FastTinyLFU implements TinyLFU admission filter with Count-Min Sketch.
+// Sketch cells, doorkeeper words and the aging counter are only accessed
+// atomically, so the filter is safe for concurrent use without a lock.
 type FastTinyLFU struct {
 	enabled   bool
 	size      int
-	sketch    [][]uint32 // Count-Min Sketch for frequency estimation
-	hashCount int        // Number of hash functions
-	resetAt   uint32     // Reset threshold
-	counter   uint32     // Global counter for aging
+	sketch    [][]uint32    // Count-Min Sketch for frequency estimation
+	hashCount int           // Number of hash functions
+	resetAt   uint32        // Reset threshold
+	counter   atomic.Uint32 // Global counter for aging
+	// doorkeeper is a bloom filter absorbing the first sighting of each key,
+	// so one-hit wonders never reach the sketch
+	doorkeeper []uint64
+}
+
+// doorkeeperHashes is the number of bloom filter probes per key
+const doorkeeperHashes = 3
+
+// DefaultWindowRatio is the share of each W-TinyLFU shard given to the window segment
+const DefaultWindowRatio = 0.10
+
+// DefaultProbationRatio is the share of the SLRU main segment given to probation
+const DefaultProbationRatio = 0.8
+
+// WTinyLFUOptions tunes the segment layout of a W-TinyLFU cache.
+// Zero values select the defaults.
+type WTinyLFUOptions struct {
+	// WindowRatio is the window's share of each shard, within (0,1). Default: DefaultWindowRatio.
+	WindowRatio float64
+	// ProbationRatio is probation's share of the main SLRU, within (0,1). Default: DefaultProbationRatio.
+	ProbationRatio float64
+	// AdaptiveWindow lets each shard move slots between window and main based on hit rates
+	AdaptiveWindow bool
+	// SketchDepth is the number of Count-Min sketch rows (hash functions). Default: 4.
+	SketchDepth int
+	// SketchWidth is the number of counters per sketch row in each shard. Default: 4x the filter size.
+	SketchWidth int
+}
+
+// withDefaults replaces unset or out-of-range options with the defaults
+func (o WTinyLFUOptions) withDefaults() WTinyLFUOptions {
+	if o.WindowRatio <= 0 || o.WindowRatio >= 1 {
+		o.WindowRatio = DefaultWindowRatio
+	}
+	if o.ProbationRatio <= 0 || o.ProbationRatio >= 1 {
+		o.ProbationRatio = DefaultProbationRatio
+	}
+	return o
 }
 
 // NewWTinyLFU creates an optimized W-TinyLFU cache
 func NewWTinyLFU(maxSize, shardCount int) *WTinyLFU {
+	return NewWTinyLFUWithOptions(maxSize, shardCount, WTinyLFUOptions{})
+}
+
+// NewWTinyLFUWithOptions creates a W-TinyLFU cache with a custom segment layout
+func NewWTinyLFUWithOptions(maxSize, shardCount int, opts WTinyLFUOptions) *WTinyLFU {
+	opts = opts.withDefaults()
 	if shardCount <= 0 || shardCount > int(^uint32(0)) {
 		shardCount = 16 // fallback di sicurezza
 	}
@@ -88,12 +255,7 @@ func NewWTinyLFU(maxSize, shardCount int) *WTinyLFU {
 		shardCount: shardCount,
 		shardMask:  uint32(shardCount - 1),
 		shards:     make([]*WTinyLFUShard, shardCount),
-		disableTTL: true,
-		hashPool: &sync.Pool{
-			New: func() interface{} {
-				return fnv.New32a()
-			},
-		},
+		seed:       maphash.MakeSeed(),
 	}
 
 	shardSize := maxSize / shardCount
@@ -109,89 +271,241 @@ func NewWTinyLFU(maxSize, shardCount int) *WTinyLFU {
 			windowSize = 1
 			mainSize = 0
 		} else {
-			windowSize = max(1, shardSize/10)       // Use 10% for window
-			mainSize = max(0, shardSize-windowSize) // Remaining for main
+			// Window takes its ratio (at least 1 slot), main keeps at least 1 slot
+			windowSize = min(shardSize-1, max(1, int(float64(shardSize)*opts.WindowRatio+1e-9)))
+			mainSize = shardSize - windowSize
 		}
 
 		wt.shards[i] = &WTinyLFUShard{
 			windowCache:     NewFastLRU(windowSize),
-			mainCache:       NewFastSLRU(max(1, mainSize)), // Ensure at least 1 for SLRU
-			admissionFilter: NewFastTinyLFU(max(1, shardSize/10)),
+			mainCache:       NewFastSLRUWithRatio(max(1, mainSize), opts.ProbationRatio), // Ensure at least 1 for SLRU
+			admissionFilter: NewFastTinyLFUWithDimensions(max(1, shardSize/10), opts.SketchDepth, opts.SketchWidth),
 			windowSize:      windowSize,
 			mainSize:        mainSize,
+			capacity:        windowSize + mainSize,
+			clock:           realClock{},
 		}
+		if opts.AdaptiveWindow && shardSize >= adaptiveMinCapacity {
+			wt.shards[i].adaptive = newAdaptiveWindow(shardSize)
+		}
+		shard := wt.shards[i]
+		shard.windowCache.evictQueue = &shard.evictQueue
+		shard.mainCache.probation.evictQueue = &shard.evictQueue
+		shard.mainCache.protected.evictQueue = &shard.evictQueue
+		shard.windowCache.nodes = &shard.nodes
+		shard.mainCache.probation.nodes = &shard.nodes
+		shard.mainCache.protected.nodes = &shard.nodes
+		shard.windowCache.count = &shard.count
+		shard.mainCache.probation.count = &shard.count
+		shard.mainCache.protected.count = &shard.count
 	}
 
 	return wt
 }
 
-// SetTTL sets the time-to-live for cache entries
+// SetTTL sets the time-to-live for entries stored from now on.
+// It is safe to call while the cache is in use; existing entries keep their expiration.
 func (wt *WTinyLFU) SetTTL(ttl time.Duration) {
-	wt.ttl = ttl
-	wt.disableTTL = (ttl <= 0)
+	wt.ttl.Store(int64(ttl))
+	for _, shard := range wt.shards {
+		shard.ttl.Store(int64(ttl))
+	}
+}
+
+// setClock replaces the time source of every shard and segment; it must be called before the cache is used
+func (wt *WTinyLFU) setClock(c Clock) {
+	for _, shard := range wt.shards {
+		shard.clock = c
+		shard.windowCache.clock = c
+		shard.mainCache.probation.clock = c
+		shard.mainCache.protected.clock = c
+	}
+}
+
+// setMaxIdle makes every segment expire entries left unread for longer than d (0 = never);
+// it must be called before the cache is used
+func (wt *WTinyLFU) setMaxIdle(d time.Duration) {
+	for _, shard := range wt.shards {
+		for _, segment := range []*FastLRU{shard.windowCache, shard.mainCache.probation, shard.mainCache.protected} {
+			segment.maxIdle = int64(d)
+		}
+	}
+}
+
+// setLazyCleanup makes every write to a shard examine up to n entries for expiration
+// (0 = none, see CacheConfig.DisableBackgroundCleanup); it must be called before the cache is used
+func (wt *WTinyLFU) setLazyCleanup(n int) {
+	for _, shard := range wt.shards {
+		shard.lazyCleanup = n
+	}
+}
+
+// setStatsWindow gives every shard windowed counters made by newWindow, shared with its
+// segments (see StrategicCache.WindowedStats); it must be called before the cache is used
+func (wt *WTinyLFU) setStatsWindow(newWindow func() *statsWindow) {
+	for _, shard := range wt.shards {
+		shard.window = newWindow()
+		for _, segment := range []*FastLRU{shard.windowCache, shard.mainCache.probation, shard.mainCache.protected} {
+			segment.window = shard.window
+		}
+	}
+}
+
+// setHotKeys makes every shard follow its n most read keys by their admission sketch
+// estimates (see StrategicCache.HotKeys); it must be called before the cache is used
+func (wt *WTinyLFU) setHotKeys(n int) {
+	for _, shard := range wt.shards {
+		shard.hotKeys = newHotKeyTracker(n, shard.admissionFilter.Estimate)
+	}
+}
+
+// setPinned marks or unmarks a live entry as exempt from eviction and expiration,
+// reporting whether the key was found (see StrategicCache.Pin)
+func (wt *WTinyLFU) setPinned(key string, pinned bool) bool {
+	shard := wt.getShard(key)
+	shard.writeMu.Lock()
+	defer shard.writeMu.Unlock()
+
+	for _, segment := range []*FastLRU{shard.windowCache, shard.mainCache.probation, shard.mainCache.protected} {
+		if segment.setPinned(key, pinned) {
+			return true
+		}
+	}
+	return false
+}
+
+// setEvictHandler installs the handler for entries evicted from any shard (see StrategicCache.OnEvict)
+func (wt *WTinyLFU) setEvictHandler(fn func(evictedEntry)) {
 	for _, shard := range wt.shards {
-		shard.ttl = ttl
+		shard.evictQueue.setHandler(fn)
 	}
 }
 
+// SetMaxMemoryBytes bounds the estimated bytes held by cached values, split evenly across shards.
+// It must be called before the cache is used; zero or a negative value disables the budget.
+func (wt *WTinyLFU) SetMaxMemoryBytes(maxBytes int64) {
+	perShard := int64(0)
+	if maxBytes > 0 {
+		perShard = max64(1, maxBytes/int64(len(wt.shards)))
+	}
+	for _, shard := range wt.shards {
+		shard.maxBytes = perShard
+	}
+}
+
+// getShard selects the shard for a key
+func (wt *WTinyLFU) getShard(key string) *WTinyLFUShard {
+	return wt.shards[wt.shardIndex(key)]
+}
+
+// shardIndex returns the index of the shard for a key
+func (wt *WTinyLFU) shardIndex(key string) uint32 {
+	return uint32(maphash.String(wt.seed, key)) & wt.shardMask // nosec G115 - masked to the shard count
+}
+
 // Get retrieves a value from the cache
 func (wt *WTinyLFU) Get(key string) (interface{}, bool) {
-	if key == "" {
-		return nil, false
-	}
+	return wt.getAt(wt.shardIndex(key), key)
+}
 
-	h := wt.hashPool.Get().(hash.Hash32)
-	h.Reset()
-	// nosec
-	if _, err := h.Write(*(*[]byte)(unsafe.Pointer(&key))); err != nil {
-		wt.hashPool.Put(h)
+// getAt is Get on the shard at index, the key's shardIndex
+func (wt *WTinyLFU) getAt(index uint32, key string) (interface{}, bool) {
+	if key == "" {
 		return nil, false
 	}
-	shardIndex := h.Sum32() & wt.shardMask
-	wt.hashPool.Put(h)
 
-	shard := wt.shards[shardIndex]
-	return shard.Get(key)
+	return wt.shards[index].Get(key)
 }
 
 // Get retrieves a value from the shard
 func (shard *WTinyLFUShard) Get(key string) (interface{}, bool) {
+	// Reads count towards frequency so hot window entries win promotion to main
+	shard.admissionFilter.Record(key)
+
 	shard.readMu.RLock()
 
 	if value, exists := shard.windowCache.FastGet(key); exists {
 		shard.readMu.RUnlock()
-		shard.hits.Add(1)
+		shard.recordHit(true)
 		return value, true
 	}
 
-	if value, exists := shard.mainCache.FastGet(key); exists {
+	if value, exists := shard.mainCache.protected.FastGet(key); exists {
 		shard.readMu.RUnlock()
-		shard.hits.Add(1)
+		shard.mainCache.hits.Add(1)
+		shard.recordHit(false)
 		return value, true
 	}
 
+	inProbation := shard.mainCache.probation.Exists(key)
 	shard.readMu.RUnlock()
+	if inProbation {
+		// Promotion moves the key between segments, so it is serialized with writers:
+		// a Set finding it in neither would store a second copy in the window
+		shard.writeMu.Lock()
+		value, exists := shard.mainCache.FastGet(key)
+		shard.writeMu.Unlock()
+		if exists {
+			shard.evictQueue.flush() // Promotion to protected may have evicted
+			shard.recordHit(false)
+			return value, true
+		}
+	}
+
 	shard.misses.Add(1)
+	if shard.window != nil {
+		shard.window.miss()
+	}
+	if shard.adaptive != nil {
+		shard.adaptive.record(false, false)
+	}
 	return nil, false
 }
 
+// recordHit counts a hit in the window (inWindow) or in main
+func (shard *WTinyLFUShard) recordHit(inWindow bool) {
+	shard.hits.Add(1)
+	if shard.window != nil {
+		shard.window.hit()
+	}
+	if shard.adaptive != nil {
+		shard.adaptive.record(inWindow, !inWindow)
+	}
+}
+
 // Set stores a value in the cache
 func (wt *WTinyLFU) Set(key string, value interface{}) bool {
 	if key == "" {
 		return false
 	}
 
-	h := wt.hashPool.Get().(hash.Hash32)
-	h.Reset()
-	if _, err := h.Write(*(*[]byte)(unsafe.Pointer(&key))); err != nil { // nosec G103
-		wt.hashPool.Put(h)
+	return wt.getShard(key).Set(key, value)
+}
+
+// SetWithCost stores a value weighted by cost against the cache capacity
+func (wt *WTinyLFU) SetWithCost(key string, value interface{}, cost int64) bool {
+	if key == "" {
 		return false
 	}
-	shardIndex := h.Sum32() & wt.shardMask
-	wt.hashPool.Put(h)
 
-	shard := wt.shards[shardIndex]
-	return shard.Set(key, value)
+	return wt.getShard(key).SetWithCost(key, value, cost)
+}
+
+// setWithAttrs stores a value with per-entry attributes: its cost, an expiration in UnixNano
+// (0 applies the cache TTL as SetWithCost does), a sliding TTL and a priority.
+// attrs.size is computed from the value. It reports why the value was not stored:
+// ErrValueTooLarge, ErrNotAdmittedFrequency or ErrNotAdmitted (empty key or lower priority).
+func (wt *WTinyLFU) setWithAttrs(key string, value interface{}, attrs nodeAttrs) error {
+	return wt.setWithAttrsAt(wt.shardIndex(key), key, value, attrs)
+}
+
+// setWithAttrsAt is setWithAttrs on the shard at index, the key's shardIndex
+func (wt *WTinyLFU) setWithAttrsAt(index uint32, key string, value interface{}, attrs nodeAttrs) error {
+	if key == "" {
+		return ErrNotAdmitted
+	}
+
+	return wt.shards[index].setWithAttrs(key, value, attrs)
 }
 
 // SetGet combines Set and Get operations
@@ -202,66 +516,218 @@ func (wt *WTinyLFU) SetGet(key string, value interface{}) (interface{}, bool) {
 
 // Set stores a value in the shard with admission filter
 func (shard *WTinyLFUShard) Set(key string, value interface{}) bool {
+	return shard.SetWithCost(key, value, 1)
+}
+
+// SetWithCost stores a value weighted by cost in the shard with admission filter
+func (shard *WTinyLFUShard) SetWithCost(key string, value interface{}, cost int64) bool {
+	return shard.setWithAttrs(key, value, nodeAttrs{cost: cost}) == nil
+}
+
+// setWithAttrs stores a value in the shard with the attributes of WTinyLFU.setWithAttrs
+func (shard *WTinyLFUShard) setWithAttrs(key string, value interface{}, attrs nodeAttrs) error {
+	if attrs.cost < 1 {
+		attrs.cost = 1
+	}
+	if attrs.cost > 1 && attrs.cost > int64(shard.capacity) {
+		return ErrValueTooLarge // Costlier than the whole shard
+	}
+
+	// Size the value before taking the lock
+	attrs.size = calculateSize(value)
+	if ttl := time.Duration(shard.ttl.Load()); attrs.expiresAt == 0 && ttl > 0 {
+		attrs.expiresAt = shard.clock.Now().Add(ttl).UnixNano()
+	}
+	if shard.maxBytes > 0 && int64(attrs.size) > shard.maxBytes {
+		return ErrValueTooLarge // Can never fit within the shard's memory budget
+	}
+
+	defer shard.evictQueue.flush() // Runs after the unlock below
+	shard.writeMu.Lock()
+	defer shard.writeMu.Unlock()
+
+	if shard.adaptive != nil {
+		shard.adaptLocked()
+	}
+	if shard.lazyCleanup > 0 {
+		shard.reclaimExpired(shard.lazyCleanup)
+	}
+	if err := shard.setLocked(key, value, attrs); err != nil {
+		return err
+	}
+	if shard.maxBytes > 0 {
+		shard.enforceMemoryBudget(key)
+	}
+	return nil
+}
+
+// reclaimExpired examines up to n entries, window first, and drops the expired ones.
+// The caller must hold writeMu.
+func (shard *WTinyLFUShard) reclaimExpired(n int) {
+	for _, segment := range []*FastLRU{shard.windowCache, shard.mainCache.probation, shard.mainCache.protected} {
+		if n -= segment.reclaimExpired(n); n <= 0 {
+			return
+		}
+	}
+}
+
+// warmItem is an entry for setBatch with the access count to pre-seed in the admission filter
+type warmItem struct {
+	key       string
+	value     interface{}
+	attrs     nodeAttrs
+	frequency int
+}
+
+// setBatch stores items under a single acquisition of writeMu, recording each key
+// frequency extra times in the admission filter first. It returns how many were stored.
+func (shard *WTinyLFUShard) setBatch(items []warmItem) int {
+	defer shard.evictQueue.flush() // Runs after the unlock below
 	shard.writeMu.Lock()
 	defer shard.writeMu.Unlock()
 
+	if shard.adaptive != nil {
+		shard.adaptLocked()
+	}
+	stored := 0
+	for _, item := range items {
+		if shard.maxBytes > 0 && int64(item.attrs.size) > shard.maxBytes {
+			continue
+		}
+		for i := 0; i < item.frequency; i++ {
+			shard.admissionFilter.Record(item.key)
+		}
+		if shard.setLocked(item.key, item.value, item.attrs) != nil {
+			continue
+		}
+		if shard.maxBytes > 0 {
+			shard.enforceMemoryBudget(item.key)
+		}
+		stored++
+	}
+	return stored
+}
+
+// setLocked places a value in the window or main segment, reporting why a value too
+// costly for the window lost its contest for main. The caller must hold writeMu.
+func (shard *WTinyLFUShard) setLocked(key string, value interface{}, attrs nodeAttrs) error {
 	// Record access in admission filter
 	shard.admissionFilter.Record(key)
 
 	// Check if key already exists in window cache
 	if shard.windowCache.Exists(key) {
-		shard.windowCache.FastSet(key, value)
-		return true
+		shard.windowCache.set(key, value, attrs)
+		return nil
 	}
 
 	// Check if key already exists in main cache
 	if shard.mainCache.Exists(key) {
-		shard.mainCache.FastSet(key, value)
-		return true
+		shard.mainCache.set(key, value, attrs)
+		return nil
 	}
 
-	// Key doesn't exist, decide where to place it
-	// For new keys, always try window cache first
-	if shard.windowCache.Size() < shard.windowSize {
-		shard.windowCache.FastSet(key, value)
-		return true
+	// New keys enter the window; entries it pushes out compete for a place in main
+	if attrs.cost > int64(shard.windowSize) && shard.mainSize > 0 {
+		// Too costly for the window: contest main directly
+		candidate := shard.nodes.get()
+		*candidate = fastNode{key: key, value: value, size: attrs.size, cost: attrs.cost, expiresAt: attrs.expiresAt, slide: attrs.slide, accessedAt: attrs.accessedAt, priority: attrs.priority}
+		err := shard.admitToMainLocked(candidate)
+		shard.nodes.put(candidate)
+		return err
 	}
 
-	// Window cache is full, check if main cache has space
-	if shard.mainSize > 0 && shard.mainCache.Size() < shard.mainSize {
-		shard.mainCache.FastSet(key, value)
-		return true
+	var candidates []*fastNode
+	for shard.windowCache.Cost()+attrs.cost > int64(shard.windowSize) {
+		node := shard.windowCache.popOldest("")
+		if node == nil {
+			break
+		}
+		candidates = append(candidates, node)
 	}
+	shard.windowCache.set(key, value, attrs)
 
-	// Both caches are full, use admission policy with TinyLFU filter
-	totalSize := shard.windowCache.Size() + shard.mainCache.Size()
-	maxTotal := shard.windowSize + shard.mainSize
-
-	if totalSize >= maxTotal {
-		// At capacity, use admission filter to decide
-		if maxTotal <= 1 {
-			return false // For very small caches, don't exceed capacity
+	for _, candidate := range candidates {
+		if shard.mainSize == 0 || shard.admitToMainLocked(candidate) != nil {
+			shard.windowCache.addEvictions(1)
+			if shard.window != nil {
+				shard.window.evict()
+			}
+			shard.evictQueue.push(evictedEntry{key: candidate.key, value: candidate.value})
 		}
+		shard.nodes.put(candidate)
+	}
+	return nil
+}
 
-		// Get victim from window cache (LRU victim)
-		victimKey := shard.getWindowVictim()
-		if victimKey != "" {
-			// Use admission filter to decide
-			if shard.admissionFilter.ShouldAdmit(key, victimKey) {
-				shard.windowCache.FastSet(key, value) // This will evict the victim
-				return true
-			}
-			return false // Admission filter rejected
+// admitToMainLocked moves a window victim into main probation while there is room,
+// otherwise only if it beats probation's own victim (see admits). It returns nil if
+// the candidate was stored, else why not. The caller must hold writeMu.
+func (shard *WTinyLFUShard) admitToMainLocked(candidate *fastNode) error {
+	attrs := nodeAttrs{size: candidate.size, cost: candidate.cost, expiresAt: candidate.expiresAt, slide: candidate.slide, accessedAt: candidate.accessedAt, priority: candidate.priority}
+	probation := shard.mainCache.probation
+	if candidate.cost > int64(shard.mainSize) {
+		return ErrValueTooLarge
+	}
+
+	for shard.mainCache.Cost()+candidate.cost > int64(shard.mainSize) ||
+		(probation.maxSize > 0 && probation.Cost()+candidate.cost > int64(probation.maxSize)) {
+		victim, victimPriority := probation.oldest()
+		segment := probation
+		if victim == "" {
+			victim, victimPriority = shard.mainCache.protected.oldest()
+			segment = shard.mainCache.protected
+		}
+		if victim == "" {
+			break
+		}
+		if err := shard.admits(candidate, victim, victimPriority); err != nil {
+			return err
 		}
+		if node := segment.evictOldest(""); node != nil {
+			shard.nodes.put(node)
+		}
+	}
 
-		// Fallback: evict from window and add new item
-		shard.windowCache.FastSet(key, value)
-		return true
+	shard.mainCache.probation.set(candidate.key, candidate.value, attrs)
+	return nil
+}
+
+// admits reports whether candidate should replace victim in main: the higher priority
+// wins, and the admission filter decides between entries of equal priority. It returns
+// ErrNotAdmitted for a lower priority and ErrNotAdmittedFrequency, counted in
+// admissionRejects, when the filter sides with the victim.
+func (shard *WTinyLFUShard) admits(candidate *fastNode, victim string, victimPriority Priority) error {
+	if candidate.priority != victimPriority {
+		if candidate.priority < victimPriority {
+			return ErrNotAdmitted
+		}
+		return nil
+	}
+	if !shard.admissionFilter.ShouldAdmit(candidate.key, victim) {
+		shard.admissionRejects.Add(1)
+		return ErrNotAdmittedFrequency
 	}
+	return nil
+}
 
-	// Not at full capacity yet, add to window
-	shard.windowCache.FastSet(key, value)
-	return true
+// enforceMemoryBudget evicts entries until the shard fits in maxBytes, never evicting keep.
+// Probation is drained first, then the window, and the protected segment last.
+// The caller must hold writeMu.
+func (shard *WTinyLFUShard) enforceMemoryBudget(keep string) {
+	for shard.MemoryBytes() > shard.maxBytes {
+		node := shard.mainCache.probation.evictOldest(keep)
+		if node == nil {
+			node = shard.windowCache.evictOldest(keep)
+		}
+		if node == nil {
+			node = shard.mainCache.protected.evictOldest(keep)
+		}
+		if node == nil {
+			return
+		}
+		shard.memoryEvictedBytes.Add(int64(node.size))
+		shard.nodes.put(node)
+	}
 }
 
 // SetGet combines Set and Get for shard
@@ -272,21 +738,16 @@ func (shard *WTinyLFUShard) SetGet(key string, value interface{}) (interface{},
 
 // Delete removes a key from the cache
 func (wt *WTinyLFU) Delete(key string) bool {
-	if key == "" {
-		return false
-	}
+	return wt.deleteAt(wt.shardIndex(key), key)
+}
 
-	h := wt.hashPool.Get().(hash.Hash32)
-	h.Reset()
-	if _, err := h.Write(*(*[]byte)(unsafe.Pointer(&key))); err != nil { // nosec G103
-		wt.hashPool.Put(h)
+// deleteAt is Delete on the shard at index, the key's shardIndex
+func (wt *WTinyLFU) deleteAt(index uint32, key string) bool {
+	if key == "" {
 		return false
 	}
-	shardIndex := h.Sum32() & wt.shardMask
-	wt.hashPool.Put(h)
 
-	shard := wt.shards[shardIndex]
-	return shard.Delete(key)
+	return wt.shards[index].Delete(key)
 }
 
 // Delete removes a key from the shard
@@ -304,15 +765,38 @@ func (shard *WTinyLFUShard) Delete(key string) bool {
 	return deleted
 }
 
-// getWindowVictim returns the LRU key from window cache for admission decisions
-func (shard *WTinyLFUShard) getWindowVictim() string {
-	shard.windowCache.mu.RLock()
-	defer shard.windowCache.mu.RUnlock()
+// Keys returns the keys of all live entries, in no particular order
+func (wt *WTinyLFU) Keys() []string {
+	var keys []string
+	for _, shard := range wt.shards {
+		for _, e := range shard.entries() {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
 
-	if shard.windowCache.tail.prev != shard.windowCache.head {
-		return shard.windowCache.tail.prev.key
+// Range calls fn for each live entry until fn returns false. Each shard is copied
+// under its read lock before fn runs, so fn may safely call back into the cache.
+func (wt *WTinyLFU) Range(fn func(key string, value interface{}) bool) {
+	for _, shard := range wt.shards {
+		for _, e := range shard.entries() {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
 	}
-	return ""
+}
+
+// entries copies the shard's unexpired entries from the window and main segments
+func (shard *WTinyLFUShard) entries() []entrySnapshot {
+	now := shard.clock.Now().UnixNano()
+	shard.readMu.RLock()
+	defer shard.readMu.RUnlock()
+
+	items := shard.windowCache.appendEntries(nil, now)
+	items = shard.mainCache.protected.appendEntries(items, now)
+	return shard.mainCache.probation.appendEntries(items, now)
 }
 
 // Clear removes all entries
@@ -331,6 +815,7 @@ func (shard *WTinyLFUShard) Clear() {
 	shard.mainCache.Clear()
 	shard.hits.Store(0)
 	shard.misses.Store(0)
+	shard.memoryEvictedBytes.Store(0)
 }
 
 // Exists checks if a key exists
@@ -339,7 +824,7 @@ func (wt *WTinyLFU) Exists(key string) bool {
 	return exists
 }
 
-// Size returns total cache size
+// Size returns the number of entries held, summing a counter per shard without locks
 func (wt *WTinyLFU) Size() int {
 	total := 0
 	for _, shard := range wt.shards {
@@ -348,22 +833,45 @@ func (wt *WTinyLFU) Size() int {
 	return total
 }
 
-// Size returns shard size
+// Len returns the number of entries held; it is the same as Size
+func (wt *WTinyLFU) Len() int {
+	return wt.Size()
+}
+
+// Close is a no-op: W-TinyLFU runs no background goroutines. It lets WTinyLFU satisfy Cacher.
+func (wt *WTinyLFU) Close() {}
+
+// Size returns the number of entries in the shard's window and main segments. It reads
+// an atomic counter, so it takes no locks.
 func (shard *WTinyLFUShard) Size() int {
-	shard.readMu.RLock()
-	defer shard.readMu.RUnlock()
-	return shard.windowCache.Size() + shard.mainCache.Size()
+	return int(shard.count.Load())
 }
 
 // MaxSize returns maximum cache size
 func (wt *WTinyLFU) MaxSize() int {
 	total := 0
 	for _, shard := range wt.shards {
-		total += shard.windowSize + shard.mainSize
+		total += shard.capacity
 	}
 	return total
 }
 
+// shardSizes reports the effective window and main capacity of each shard
+func (wt *WTinyLFU) shardSizes() []map[string]int {
+	sizes := make([]map[string]int, len(wt.shards))
+	for i, shard := range wt.shards {
+		shard.writeMu.Lock()
+		sizes[i] = map[string]int{
+			"window_size":    shard.windowSize,
+			"main_size":      shard.mainSize,
+			"probation_size": shard.mainCache.probation.maxSize,
+			"protected_size": shard.mainCache.protected.maxSize,
+		}
+		shard.writeMu.Unlock()
+	}
+	return sizes
+}
+
 // Hits returns total cache hits
 func (wt *WTinyLFU) Hits() int64 {
 	total := int64(0)
@@ -393,7 +901,7 @@ func (wt *WTinyLFU) Stats() map[string]interface{} {
 		"hits":     hits,
 		"misses":   misses,
 		"hit_rate": hitRate,
-		"ttl":      wt.ttl,
+		"ttl":      time.Duration(wt.ttl.Load()),
 		"shards":   len(wt.shards),
 		// Additional keys expected by tests
 		"window_size":     wt.WindowSize(),
@@ -401,24 +909,205 @@ func (wt *WTinyLFU) Stats() map[string]interface{} {
 		"total_size":      wt.Size(),
 		"shard_count":     len(wt.shards),
 		"total_hits":      hits,
+		"memory_bytes":    wt.MemoryBytes(),
+		"memory_evicted":  wt.MemoryEvictedBytes(),
 		"admission_stats": wt.shards[0].admissionFilter.Stats(),
+		"shard_sizes":     wt.shardSizes(),
+		"sketch_bytes":    wt.SketchBytes(),
+		// Entries the admission filter turned away, apart from size and priority rejections
+		"admission_rejects": wt.AdmissionRejects(),
+	}
+}
+
+// AdmissionRejects returns how many entries the admission filters turned away because
+// their victim was estimated more frequent: new keys too costly for the window, and
+// window victims that lost their contest for main
+func (wt *WTinyLFU) AdmissionRejects() int64 {
+	total := int64(0)
+	for _, shard := range wt.shards {
+		total += shard.admissionRejects.Load()
+	}
+	return total
+}
+
+// SketchBytes returns the memory held by the admission filters of all shards
+func (wt *WTinyLFU) SketchBytes() int64 {
+	total := int64(0)
+	for _, shard := range wt.shards {
+		total += shard.admissionFilter.SketchBytes()
 	}
+	return total
 }
 
 // GetStats returns cache statistics in CacheStats format for compatibility
 func (wt *WTinyLFU) GetStats() CacheStats {
 	hits := wt.Hits()
 	misses := int64(0)
+	evictions := int64(0)
+	expirations := int64(0)
+	idleExpirations := int64(0)
+	pinned := 0
+	memory := int64(0)
+	memoryEvicted := int64(0)
+	var nodes PoolStats
 	for _, shard := range wt.shards {
 		misses += shard.misses.Load()
+		nodes = nodes.add(shard.nodes.stats())
+		evictions += shard.Evictions()
+		expirations += shard.Expirations()
+		idleExpirations += shard.IdleExpirations()
+		pinned += shard.Pinned()
+		memory += shard.MemoryBytes()
+		memoryEvicted += shard.memoryEvictedBytes.Load()
 	}
 
 	return CacheStats{
-		Hits:   hits,
-		Misses: misses,
-		Size:   int64(wt.Size()),
-		Keys:   wt.Size(),
+		Hits:               hits,
+		Misses:             misses,
+		Size:               int64(wt.Size()),
+		Keys:               wt.Size(),
+		Evictions:          evictions,
+		Expirations:        expirations,
+		IdleExpirations:    idleExpirations,
+		Pinned:             pinned,
+		MemoryBytes:        memory,
+		MemoryEvictedBytes: memoryEvicted,
+		TotalCost:          wt.Cost(),
+		EntryPool:          nodes,
+	}
+}
+
+// ResetStats zeroes the hit, miss, eviction and expiration counters of every shard,
+// leaving the cached entries and the admission sketch untouched
+func (wt *WTinyLFU) ResetStats() {
+	for _, shard := range wt.shards {
+		shard.writeMu.Lock()
+		shard.hits.Store(0)
+		shard.misses.Store(0)
+		shard.memoryEvictedBytes.Store(0)
+		shard.admissionRejects.Store(0)
+		shard.mainCache.hits.Store(0)
+		for _, segment := range []*FastLRU{shard.windowCache, shard.mainCache.probation, shard.mainCache.protected} {
+			segment.resetStats()
+		}
+		shard.writeMu.Unlock()
+	}
+}
+
+// ShardStats returns per-shard statistics in the same format as StrategicCache
+func (wt *WTinyLFU) ShardStats() []ShardStats {
+	stats := make([]ShardStats, len(wt.shards))
+	for i, shard := range wt.shards {
+		stats[i] = ShardStats{
+			Index:              i,
+			Keys:               shard.Size(),
+			Hits:               shard.hits.Load(),
+			Misses:             shard.misses.Load(),
+			Evictions:          shard.Evictions(),
+			Expirations:        shard.Expirations(),
+			IdleExpirations:    shard.IdleExpirations(),
+			Pinned:             shard.Pinned(),
+			MemoryBytes:        shard.MemoryBytes(),
+			MemoryEvictedBytes: shard.memoryEvictedBytes.Load(),
+			TotalCost:          shard.Cost(),
+		}
 	}
+	return stats
+}
+
+// Cost returns the cumulative cost of the entries in the shard
+func (shard *WTinyLFUShard) Cost() int64 {
+	return shard.windowCache.Cost() + shard.mainCache.Cost()
+}
+
+// Cost returns the cumulative cost of the entries in all shards
+func (wt *WTinyLFU) Cost() int64 {
+	total := int64(0)
+	for _, shard := range wt.shards {
+		total += shard.Cost()
+	}
+	return total
+}
+
+// Evictions returns the number of entries dropped by the window and main segments
+func (shard *WTinyLFUShard) Evictions() int64 {
+	return shard.windowCache.Evictions() + shard.mainCache.Evictions()
+}
+
+// Expirations returns the number of entries dropped from the shard because their TTL elapsed
+func (shard *WTinyLFUShard) Expirations() int64 {
+	return shard.windowCache.Expirations() + shard.mainCache.Expirations()
+}
+
+// IdleExpirations returns the number of entries dropped from the shard because they went unread too long
+func (shard *WTinyLFUShard) IdleExpirations() int64 {
+	return shard.windowCache.IdleExpirations() + shard.mainCache.IdleExpirations()
+}
+
+// Pinned returns the number of pinned entries in the shard
+func (shard *WTinyLFUShard) Pinned() int {
+	return shard.windowCache.Pinned() + shard.mainCache.probation.Pinned() + shard.mainCache.protected.Pinned()
+}
+
+// RemoveExpired sweeps the shard, dropping every expired entry, and returns how many were removed
+func (shard *WTinyLFUShard) RemoveExpired() int {
+	shard.writeMu.Lock()
+	defer shard.writeMu.Unlock()
+	return shard.windowCache.RemoveExpired() + shard.mainCache.RemoveExpired()
+}
+
+// RemoveExpired sweeps every shard, dropping expired entries, and returns how many were removed.
+// Expired entries are also dropped lazily on access; the sweep reclaims those never read again.
+func (wt *WTinyLFU) RemoveExpired() int {
+	if wt.ttl.Load() <= 0 {
+		return 0
+	}
+	removed := 0
+	for _, shard := range wt.shards {
+		removed += shard.RemoveExpired()
+	}
+	return removed
+}
+
+// MemoryBytes returns the estimated bytes held by the window and main segments
+func (shard *WTinyLFUShard) MemoryBytes() int64 {
+	return shard.windowCache.Bytes() + shard.mainCache.Bytes()
+}
+
+// FillRatio returns how full the shard holding key is, by cost or by memory budget,
+// whichever is higher
+func (wt *WTinyLFU) FillRatio(key string) float64 {
+	return wt.getShard(key).FillRatio()
+}
+
+// FillRatio returns the shard's cost over its capacity, or its bytes over its
+// memory budget when that is higher
+func (shard *WTinyLFUShard) FillRatio() float64 {
+	ratio := float64(shard.Cost()) / float64(max(1, shard.capacity))
+	if shard.maxBytes > 0 {
+		if memRatio := float64(shard.MemoryBytes()) / float64(shard.maxBytes); memRatio > ratio {
+			ratio = memRatio
+		}
+	}
+	return ratio
+}
+
+// MemoryEvictedBytes returns the bytes evicted by all shards to stay within the memory budget
+func (wt *WTinyLFU) MemoryEvictedBytes() int64 {
+	total := int64(0)
+	for _, shard := range wt.shards {
+		total += shard.memoryEvictedBytes.Load()
+	}
+	return total
+}
+
+// MemoryBytes returns the estimated bytes held by all shards
+func (wt *WTinyLFU) MemoryBytes() int64 {
+	total := int64(0)
+	for _, shard := range wt.shards {
+		total += shard.MemoryBytes()
+	}
+	return total
 }
 
 // HealthCheck performs health check
@@ -438,6 +1127,8 @@ func (wt *WTinyLFU) HealthCheck() map[string]interface{} {
 // WindowSize returns the window size of the first shard for test compatibility
 func (wt *WTinyLFU) WindowSize() int {
 	if len(wt.shards) > 0 {
+		wt.shards[0].writeMu.Lock()
+		defer wt.shards[0].writeMu.Unlock()
 		return wt.shards[0].windowSize
 	}
 	return 0
@@ -446,6 +1137,8 @@ func (wt *WTinyLFU) WindowSize() int {
 // MainSize returns the main size of the first shard for test compatibility
 func (wt *WTinyLFU) MainSize() int {
 	if len(wt.shards) > 0 {
+		wt.shards[0].writeMu.Lock()
+		defer wt.shards[0].writeMu.Unlock()
 		return wt.shards[0].mainSize
 	}
 	return 0
@@ -466,6 +1159,9 @@ func NewFastLRU(maxSize int) *FastLRU {
 		maxSize: maxSize,
 		head:    &fastNode{},
 		tail:    &fastNode{},
+		clock:   realClock{},
+		nodes:   &nodePool{},
+		count:   new(atomic.Int64),
 	}
 	lru.head.next = lru.tail
 	lru.tail.prev = lru.head
@@ -480,11 +1176,20 @@ func (lru *FastLRU) FastGet(key string) (interface{}, bool) {
 		lru.mu.RUnlock()
 		return nil, false
 	}
+	now := lru.clock.Now().UnixNano()
+	if expired, idle := lru.expiry(node, now); expired {
+		lru.mu.RUnlock()
+		lru.removeExpired(key, node, idle)
+		return nil, false
+	}
 	value := node.value
 	lru.mu.RUnlock()
 
 	lru.mu.Lock()
-	lru.moveToFront(node)
+	if lru.data[key] == node { // Not removed, and so not recycled, since the read lock was released
+		lru.moveToFront(node)
+		node.touch(now)
+	}
 	lru.mu.Unlock()
 
 	return value, true
@@ -492,46 +1197,117 @@ func (lru *FastLRU) FastGet(key string) (interface{}, bool) {
 
 // FastSet adds or updates a key-value pair in the cache
 func (lru *FastLRU) FastSet(key string, value interface{}) bool {
+	return lru.set(key, value, nodeAttrs{size: calculateSize(value), cost: 1})
+}
+
+// set adds or updates a key-value pair whose attributes have already been computed
+func (lru *FastLRU) set(key string, value interface{}, attrs nodeAttrs) bool {
 	lru.mu.Lock()
 	defer lru.mu.Unlock()
 
+	if attrs.accessedAt == 0 && lru.maxIdle > 0 {
+		attrs.accessedAt = lru.clock.Now().UnixNano()
+	}
 	if node, exists := lru.data[key]; exists {
 		node.value = value
+		lru.bytes += int64(attrs.size - node.size)
+		node.size = attrs.size
+		lru.cost += attrs.cost - node.cost
+		node.cost = attrs.cost
+		node.expiresAt = attrs.expiresAt
+		node.slide = attrs.slide
+		node.accessedAt = attrs.accessedAt
+		node.priority = attrs.priority
 		lru.moveToFront(node)
+		// A costlier update may push other items out
+		for lru.maxSize > 0 && lru.cost > int64(lru.maxSize) {
+			if !lru.evictAndRecycleLocked(key) {
+				break
+			}
+		}
 		return true
 	}
 
-	if lru.size >= lru.maxSize && lru.maxSize > 0 {
-		oldest := lru.tail.prev
-		if oldest != lru.head && oldest != nil {
-			delete(lru.data, oldest.key)
-			lru.removeNode(oldest)
-			lru.size--
+	// Evict until the new item fits (one eviction for unit-cost items)
+	for lru.maxSize > 0 && lru.cost+attrs.cost > int64(lru.maxSize) {
+		if !lru.evictAndRecycleLocked("") {
+			break
 		}
 	}
 
-	newNode := &fastNode{
-		key:   key,
-		value: value,
+	newNode := lru.nodes.get()
+	*newNode = fastNode{
+		key:        key,
+		value:      value,
+		size:       attrs.size,
+		cost:       attrs.cost,
+		expiresAt:  attrs.expiresAt,
+		slide:      attrs.slide,
+		accessedAt: attrs.accessedAt,
+		pinned:     attrs.pinned,
+		priority:   attrs.priority,
 	}
 	lru.data[key] = newNode
 	lru.addToFront(newNode)
 	lru.size++
+	lru.count.Add(1)
+	if attrs.pinned {
+		lru.pinned++
+	}
+	lru.bytes += int64(attrs.size)
+	lru.cost += attrs.cost
 	return true // Return true for successful insertion
 }
 
 // Delete removes a key-value pair from the cache
 func (lru *FastLRU) Delete(key string) bool {
+	node, deleted := lru.remove(key)
+	if deleted {
+		lru.nodes.put(node)
+	}
+	return deleted
+}
+
+// remove deletes a key and returns its node so the caller can move it elsewhere
+func (lru *FastLRU) remove(key string) (*fastNode, bool) {
 	lru.mu.Lock()
 	defer lru.mu.Unlock()
 
 	if node, exists := lru.data[key]; exists {
-		delete(lru.data, key)
-		lru.removeNode(node)
-		lru.size--
-		return true
+		lru.unlinkLocked(node)
+		return node, true
 	}
-	return false
+	return nil, false
+}
+
+// unlinkLocked removes a node from the map, the list and the accounting. The caller must hold mu.
+func (lru *FastLRU) unlinkLocked(node *fastNode) {
+	delete(lru.data, node.key)
+	lru.removeNode(node)
+	lru.size--
+	lru.count.Add(-1)
+	lru.bytes -= int64(node.size)
+	lru.cost -= node.cost
+	if node.pinned {
+		lru.pinned--
+	}
+}
+
+// removeLive is remove for unexpired keys; an expired key is dropped and reported missing
+func (lru *FastLRU) removeLive(key string) (*fastNode, bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	node, exists := lru.data[key]
+	if !exists {
+		return nil, false
+	}
+	if expired, idle := lru.expiry(node, lru.clock.Now().UnixNano()); expired {
+		lru.unlinkExpiredLocked(node, idle)
+		return nil, false
+	}
+	lru.unlinkLocked(node)
+	return node, true
 }
 
 // Clear removes all items from the cache
@@ -539,10 +1315,31 @@ func (lru *FastLRU) Clear() {
 	lru.mu.Lock()
 	defer lru.mu.Unlock()
 
+	for node := lru.head.next; node != lru.tail && node != nil; {
+		next := node.next
+		lru.nodes.put(node)
+		node = next
+	}
 	lru.data = make(map[string]*fastNode)
 	lru.head.next = lru.tail
 	lru.tail.prev = lru.head
+	lru.count.Add(int64(-lru.size))
 	lru.size = 0
+	lru.evictions = 0
+	lru.expired = 0
+	lru.idleExpired = 0
+	lru.pinned = 0
+	lru.bytes = 0
+	lru.cost = 0
+}
+
+// resetStats zeroes the eviction and expiration counters
+func (lru *FastLRU) resetStats() {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	lru.evictions = 0
+	lru.expired = 0
+	lru.idleExpired = 0
 }
 
 // Get is an alias for FastGet for test compatibility
@@ -555,14 +1352,136 @@ func (lru *FastLRU) Set(key string, value interface{}) bool {
 	return lru.FastSet(key, value)
 }
 
-// Exists checks if a key exists in the LRU
+// Exists checks if a key exists in the LRU, dropping it if it has expired
 func (lru *FastLRU) Exists(key string) bool {
 	lru.mu.RLock()
-	defer lru.mu.RUnlock()
-	_, exists := lru.data[key]
+	node, exists := lru.data[key]
+	if exists {
+		if expired, idle := lru.expiry(node, lru.clock.Now().UnixNano()); expired {
+			lru.mu.RUnlock()
+			lru.removeExpired(key, node, idle)
+			return false
+		}
+	}
+	lru.mu.RUnlock()
 	return exists
 }
 
+// expiry reports whether node has expired at now (UnixNano) and, if so, whether
+// its idle timeout rather than its TTL expired it. Pinned nodes never expire.
+func (lru *FastLRU) expiry(node *fastNode, now int64) (expired, idle bool) {
+	if node.pinned {
+		return false, false
+	}
+	idle = node.idleExpired(now, lru.maxIdle)
+	return idle || node.expired(now), idle
+}
+
+// removeExpired drops an expired node unless it was replaced or removed meanwhile
+func (lru *FastLRU) removeExpired(key string, node *fastNode, idle bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	if lru.data[key] == node {
+		lru.unlinkExpiredLocked(node, idle)
+	}
+}
+
+// unlinkExpiredLocked removes an expired node and counts it as a TTL or idle expiration.
+// The caller must hold mu.
+func (lru *FastLRU) unlinkExpiredLocked(node *fastNode, idle bool) {
+	lru.unlinkLocked(node)
+	if idle {
+		lru.idleExpired++
+	} else {
+		lru.expired++
+	}
+	lru.nodes.put(node)
+}
+
+// setPinned marks or unmarks a live item as exempt from eviction and expiration,
+// reporting whether the key was found
+func (lru *FastLRU) setPinned(key string, pinned bool) bool {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	node, exists := lru.data[key]
+	if !exists {
+		return false
+	}
+	if expired, idle := lru.expiry(node, lru.clock.Now().UnixNano()); expired {
+		lru.unlinkExpiredLocked(node, idle)
+		return false
+	}
+	if node.pinned != pinned {
+		node.pinned = pinned
+		if pinned {
+			lru.pinned++
+		} else {
+			lru.pinned--
+		}
+	}
+	return true
+}
+
+// Pinned returns the number of pinned items
+func (lru *FastLRU) Pinned() int {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.pinned
+}
+
+// RemoveExpired drops every expired item and returns how many were removed
+func (lru *FastLRU) RemoveExpired() int {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	now := lru.clock.Now().UnixNano()
+	removed := 0
+	for node := lru.tail.prev; node != lru.head && node != nil; {
+		prev := node.prev
+		if expired, idle := lru.expiry(node, now); expired {
+			lru.unlinkExpiredLocked(node, idle)
+			removed++
+		}
+		node = prev
+	}
+	return removed
+}
+
+// reclaimExpired examines up to n items, in map order, drops the expired ones and
+// returns how many items it examined
+func (lru *FastLRU) reclaimExpired(n int) int {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	now := lru.clock.Now().UnixNano()
+	examined := 0
+	for _, node := range lru.data {
+		if examined == n {
+			break
+		}
+		examined++
+		if expired, idle := lru.expiry(node, now); expired {
+			lru.unlinkExpiredLocked(node, idle)
+		}
+	}
+	return examined
+}
+
+// Expirations returns the number of items dropped because their TTL elapsed
+func (lru *FastLRU) Expirations() int64 {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.expired
+}
+
+// IdleExpirations returns the number of items dropped because they went unread longer than the idle timeout
+func (lru *FastLRU) IdleExpirations() int64 {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.idleExpired
+}
+
 // Size returns the current number of items in the LRU
 func (lru *FastLRU) Size() int {
 	lru.mu.RLock()
@@ -570,6 +1489,128 @@ func (lru *FastLRU) Size() int {
 	return lru.size
 }
 
+// Evictions returns the number of items dropped because the LRU was full
+func (lru *FastLRU) Evictions() int64 {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.evictions
+}
+
+// Cost returns the cumulative cost of the items in the LRU
+func (lru *FastLRU) Cost() int64 {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.cost
+}
+
+// evictOldest removes the least recently used unpinned item other than skip and returns it,
+// or nil when no such item exists
+func (lru *FastLRU) evictOldest(skip string) *fastNode {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	return lru.evictOldestLocked(skip)
+}
+
+// evictOldestLocked is evictOldest for callers already holding mu
+func (lru *FastLRU) evictOldestLocked(skip string) *fastNode {
+	node := lru.popOldestLocked(skip)
+	if node != nil {
+		lru.evictions++
+		if lru.window != nil {
+			lru.window.evict()
+		}
+		if lru.evictQueue != nil {
+			lru.evictQueue.push(evictedEntry{key: node.key, value: node.value})
+		}
+	}
+	return node
+}
+
+// evictAndRecycleLocked is evictOldestLocked for callers that discard the node,
+// reporting whether an item was evicted
+func (lru *FastLRU) evictAndRecycleLocked(skip string) bool {
+	node := lru.evictOldestLocked(skip)
+	if node == nil {
+		return false
+	}
+	lru.nodes.put(node)
+	return true
+}
+
+// popOldest unlinks the least recently used unpinned item other than skip without
+// counting an eviction, so the caller can move it to another segment
+func (lru *FastLRU) popOldest(skip string) *fastNode {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	return lru.popOldestLocked(skip)
+}
+
+// popOldestLocked is popOldest for callers already holding mu
+func (lru *FastLRU) popOldestLocked(skip string) *fastNode {
+	for node := lru.tail.prev; node != lru.head && node != nil; node = node.prev {
+		if node.key == skip || node.pinned {
+			continue
+		}
+		lru.unlinkLocked(node)
+		return node
+	}
+	return nil
+}
+
+// oldestKey returns the least recently used unpinned key, or "" when there is none
+func (lru *FastLRU) oldestKey() string {
+	key, _ := lru.oldest()
+	return key
+}
+
+// oldest returns the key and priority of the least recently used unpinned item,
+// or "" when there is none
+func (lru *FastLRU) oldest() (string, Priority) {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	for node := lru.tail.prev; node != lru.head && node != nil; node = node.prev {
+		if !node.pinned {
+			return node.key, node.priority
+		}
+	}
+	return "", PriorityNormal
+}
+
+// addEvictions counts items the caller dropped after popping them
+func (lru *FastLRU) addEvictions(n int64) {
+	lru.mu.Lock()
+	lru.evictions += n
+	lru.mu.Unlock()
+}
+
+// entrySnapshot is a key/value pair copied out of a segment for iteration
+type entrySnapshot struct {
+	key       string
+	value     interface{}
+	expiresAt int64 // UnixNano, 0 = never
+}
+
+// appendEntries appends the unexpired items, most recently used first
+func (lru *FastLRU) appendEntries(dst []entrySnapshot, now int64) []entrySnapshot {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+
+	for node := lru.head.next; node != lru.tail && node != nil; node = node.next {
+		if expired, _ := lru.expiry(node, now); expired {
+			continue
+		}
+		dst = append(dst, entrySnapshot{key: node.key, value: node.value, expiresAt: node.expiresAt})
+	}
+	return dst
+}
+
+// Bytes returns the estimated bytes held by the items in the LRU
+func (lru *FastLRU) Bytes() int64 {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+	return lru.bytes
+}
+
 func (lru *FastLRU) moveToFront(node *fastNode) {
 	lru.removeNode(node)
 	lru.addToFront(node)
@@ -589,12 +1630,22 @@ func (lru *FastLRU) addToFront(node *fastNode) {
 
 // NewFastSLRU creates a new FastSLRU cache with the specified size
 func NewFastSLRU(size int) *FastSLRU {
-	probationSize := int(float64(size) * 0.8)
+	return NewFastSLRUWithRatio(size, DefaultProbationRatio)
+}
+
+// NewFastSLRUWithRatio creates a FastSLRU cache giving probationRatio of size to probation
+// and the rest to protected. Ratios outside (0,1) fall back to DefaultProbationRatio.
+func NewFastSLRUWithRatio(size int, probationRatio float64) *FastSLRU {
+	if probationRatio <= 0 || probationRatio >= 1 {
+		probationRatio = DefaultProbationRatio
+	}
+	probationSize := int(float64(size) * probationRatio)
 	protectedSize := size - probationSize
 
 	return &FastSLRU{
-		probation: NewFastLRU(probationSize),
-		protected: NewFastLRU(protectedSize),
+		probation:      NewFastLRU(probationSize),
+		protected:      NewFastLRU(protectedSize),
+		probationRatio: probationRatio,
 	}
 }
 
@@ -606,11 +1657,13 @@ func (slru *FastSLRU) FastGet(key string) (interface{}, bool) {
 		return value, true
 	}
 
-	// Check probation and promote if found
-	if value, exists := slru.probation.FastGet(key); exists {
-		// Remove from probation and add to protected (promotion)
-		slru.probation.Delete(key)
-		slru.protected.FastSet(key, value)
+	// Check probation and promote if found; expired items are dropped instead
+	if node, exists := slru.probation.removeLive(key); exists {
+		// Removed from probation, add to protected (promotion); the read restarts a sliding TTL
+		node.touch(slru.probation.clock.Now().UnixNano())
+		value := node.value
+		slru.protected.set(key, value, nodeAttrs{size: node.size, cost: node.cost, expiresAt: node.expiresAt, slide: node.slide, accessedAt: node.accessedAt, pinned: node.pinned, priority: node.priority})
+		slru.probation.nodes.put(node)
 		slru.hits.Add(1)
 		return value, true
 	}
@@ -620,13 +1673,18 @@ func (slru *FastSLRU) FastGet(key string) (interface{}, bool) {
 
 // FastSet adds or updates a key-value pair in the appropriate segment
 func (slru *FastSLRU) FastSet(key string, value interface{}) bool {
+	return slru.set(key, value, nodeAttrs{size: calculateSize(value), cost: 1})
+}
+
+// set adds or updates a key-value pair whose attributes have already been computed
+func (slru *FastSLRU) set(key string, value interface{}, attrs nodeAttrs) bool {
 	// Check if key already exists in protected and update
 	slru.protected.mu.RLock()
 	_, existsInProtected := slru.protected.data[key]
 	slru.protected.mu.RUnlock()
 
 	if existsInProtected {
-		return slru.protected.FastSet(key, value)
+		return slru.protected.set(key, value, attrs)
 	}
 
 	// Check if key already exists in probation and update
@@ -635,11 +1693,11 @@ func (slru *FastSLRU) FastSet(key string, value interface{}) bool {
 	slru.probation.mu.RUnlock()
 
 	if existsInProbation {
-		return slru.probation.FastSet(key, value)
+		return slru.probation.set(key, value, attrs)
 	}
 
 	// New key: add to probation
-	return slru.probation.FastSet(key, value)
+	return slru.probation.set(key, value, attrs)
 }
 
 // Delete removes a key-value pair from both segments
@@ -688,6 +1746,36 @@ func (slru *FastSLRU) Hits() int64 {
 	return slru.hits.Load()
 }
 
+// Evictions returns the number of items dropped from either segment
+func (slru *FastSLRU) Evictions() int64 {
+	return slru.protected.Evictions() + slru.probation.Evictions()
+}
+
+// Expirations returns the number of items dropped from either segment because their TTL elapsed
+func (slru *FastSLRU) Expirations() int64 {
+	return slru.protected.Expirations() + slru.probation.Expirations()
+}
+
+// IdleExpirations returns the number of items dropped from either segment because they went unread too long
+func (slru *FastSLRU) IdleExpirations() int64 {
+	return slru.protected.IdleExpirations() + slru.probation.IdleExpirations()
+}
+
+// RemoveExpired drops every expired item from both segments and returns how many were removed
+func (slru *FastSLRU) RemoveExpired() int {
+	return slru.protected.RemoveExpired() + slru.probation.RemoveExpired()
+}
+
+// Bytes returns the estimated bytes held by both segments
+func (slru *FastSLRU) Bytes() int64 {
+	return slru.protected.Bytes() + slru.probation.Bytes()
+}
+
+// Cost returns the cumulative cost of the items in both segments
+func (slru *FastSLRU) Cost() int64 {
+	return slru.protected.Cost() + slru.probation.Cost()
+}
+
 // EvictProbation evicts the oldest item from probation segment
 func (slru *FastSLRU) EvictProbation() (string, interface{}) {
 	// Find and remove oldest item from probation using atomic operations
@@ -699,9 +1787,7 @@ func (slru *FastSLRU) EvictProbation() (string, interface{}) {
 		key := oldest.key
 		value := oldest.value
 		// Use internal deletion (already have lock)
-		delete(slru.probation.data, key)
-		slru.probation.removeNode(oldest)
-		slru.probation.size--
+		slru.probation.unlinkLocked(oldest)
 		return key, value
 	}
 	return "", nil
@@ -712,15 +1798,33 @@ func (slru *FastSLRU) PromoteToProtected(key string, value interface{}) bool {
 	return slru.protected.Set(key, value)
 }
 
+// Default Count-Min sketch dimensions
+const (
+	DefaultSketchDepth       = 4 // Hash functions, enough for good distribution
+	DefaultSketchWidthFactor = 4 // Counters per row, as a multiple of the filter size
+)
+
 // NewFastTinyLFU creates a new FastTinyLFU admission filter with Count-Min Sketch
 func NewFastTinyLFU(size int) *FastTinyLFU {
+	return NewFastTinyLFUWithDimensions(size, 0, 0)
+}
+
+// NewFastTinyLFUWithDimensions creates a FastTinyLFU whose sketch has depth rows of width
+// counters, trading accuracy for memory. Zero selects DefaultSketchDepth and
+// DefaultSketchWidthFactor*size respectively.
+func NewFastTinyLFUWithDimensions(size, depth, width int) *FastTinyLFU {
 	if size <= 0 {
 		size = 1000
 	}
 
-	// Use 4 hash functions for good distribution
-	hashCount := 4
-	sketchWidth := size * 4 // Width proportional to expected items
+	hashCount := depth
+	if hashCount <= 0 {
+		hashCount = DefaultSketchDepth
+	}
+	sketchWidth := width
+	if sketchWidth <= 0 {
+		sketchWidth = size * DefaultSketchWidthFactor // Width proportional to expected items
+	}
 
 	// Initialize Count-Min Sketch
 	sketch := make([][]uint32, hashCount)
@@ -728,13 +1832,16 @@ func NewFastTinyLFU(size int) *FastTinyLFU {
 		sketch[i] = make([]uint32, sketchWidth)
 	}
 
+	// Doorkeeper: ~8 bits per key recorded between resets keeps false positives near 3%
+	doorkeeper := make([]uint64, (nextPowerOf2(size*10*8)+63)/64)
+
 	return &FastTinyLFU{
-		enabled:   true, // ✅ ENABLED!
-		size:      size,
-		sketch:    sketch,
-		hashCount: hashCount,
-		resetAt:   uint32(size * 10), // Reset every 10x size accesses
-		counter:   0,
+		enabled:    true, // ✅ ENABLED!
+		size:       size,
+		sketch:     sketch,
+		hashCount:  hashCount,
+		resetAt:    uint32(size * 10), // Reset every 10x size accesses
+		doorkeeper: doorkeeper,
 	}
 }
 
@@ -760,6 +1867,13 @@ func max(a, b int) int {
 	return b
 }
 
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // Admission Filter Methods
 
 // Record records an access to a key in the frequency sketch
@@ -768,19 +1882,21 @@ func (filter *FastTinyLFU) Record(key string) {
 		return
 	}
 
-	// Increment global counter
-	filter.counter++
-
-	// Check if we need to reset (aging mechanism)
-	if filter.counter >= filter.resetAt {
+	// Increment global counter; only the caller that swaps it back to 0 ages the sketch
+	if n := filter.counter.Add(1); n >= filter.resetAt && filter.counter.CompareAndSwap(n, 0) {
 		filter.reset()
 	}
 
+	// First sighting only sets the doorkeeper bits
+	if !filter.doorkeeperAdd(key) {
+		return
+	}
+
 	// Record in all hash functions
 	for i := 0; i < filter.hashCount; i++ {
 		hash := filter.hash(key, uint32(i))
 		index := hash % uint32(len(filter.sketch[i]))
-		filter.sketch[i][index]++
+		atomic.AddUint32(&filter.sketch[i][index], 1)
 	}
 }
 
@@ -796,12 +1912,17 @@ func (filter *FastTinyLFU) Estimate(key string) uint32 {
 	for i := 0; i < filter.hashCount; i++ {
 		hash := filter.hash(key, uint32(i))
 		index := hash % uint32(len(filter.sketch[i]))
-		freq := filter.sketch[i][index]
+		freq := atomic.LoadUint32(&filter.sketch[i][index])
 		if freq < minFreq {
 			minFreq = freq
 		}
 	}
 
+	// The sighting absorbed by the doorkeeper counts as one. A key the doorkeeper forgot
+	// at the last reset keeps its aged sketch count; a key never seen has none.
+	if filter.doorkeeperContains(key) {
+		minFreq++
+	}
 	return minFreq
 }
 
@@ -811,6 +1932,8 @@ func (filter *FastTinyLFU) ShouldAdmit(newKey string, victimKey string) bool {
 		return true
 	}
 
+	// Never-seen keys count as 0, while established keys keep their aged counts across
+	// a reset, so a scan cannot displace them while the doorkeeper is empty
 	newFreq := filter.Estimate(newKey)
 	victimFreq := filter.Estimate(victimKey)
 
@@ -818,14 +1941,49 @@ func (filter *FastTinyLFU) ShouldAdmit(newKey string, victimKey string) bool {
 	return newFreq >= victimFreq
 }
 
-// reset halves all counters (aging mechanism)
+// reset halves all counters and clears the doorkeeper (aging mechanism).
+// Cells are halved with CAS loops so concurrent increments are never lost.
 func (filter *FastTinyLFU) reset() {
 	for i := range filter.sketch {
 		for j := range filter.sketch[i] {
-			filter.sketch[i][j] = filter.sketch[i][j] / 2
+			cell := &filter.sketch[i][j]
+			for {
+				old := atomic.LoadUint32(cell)
+				if old == 0 || atomic.CompareAndSwapUint32(cell, old, old/2) {
+					break
+				}
+			}
+		}
+	}
+	for i := range filter.doorkeeper {
+		atomic.StoreUint64(&filter.doorkeeper[i], 0)
+	}
+}
+
+// doorkeeperAdd sets the key's bloom bits and reports whether all were already set
+func (filter *FastTinyLFU) doorkeeperAdd(key string) bool {
+	nbits := uint32(len(filter.doorkeeper) * 64)
+	seen := true
+	for i := 0; i < doorkeeperHashes; i++ {
+		bit := filter.hash(key, uint32(filter.hashCount+i)) % nbits
+		mask := uint64(1) << (bit % 64)
+		if atomic.OrUint64(&filter.doorkeeper[bit/64], mask)&mask == 0 {
+			seen = false
 		}
 	}
-	filter.counter = 0
+	return seen
+}
+
+// doorkeeperContains reports whether the key's bloom bits are all set
+func (filter *FastTinyLFU) doorkeeperContains(key string) bool {
+	nbits := uint32(len(filter.doorkeeper) * 64)
+	for i := 0; i < doorkeeperHashes; i++ {
+		bit := filter.hash(key, uint32(filter.hashCount+i)) % nbits
+		if atomic.LoadUint64(&filter.doorkeeper[bit/64])&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
 }
 
 // hash generates a hash for the given key and salt
@@ -844,8 +2002,35 @@ func (filter *FastTinyLFU) Stats() map[string]interface{} {
 	return map[string]interface{}{
 		"enabled":    filter.enabled,
 		"size":       filter.size,
-		"counter":    filter.counter,
+		"counter":    filter.counter.Load(),
 		"reset_at":   filter.resetAt,
 		"hash_count": filter.hashCount,
+		// Doorkeeper size and fill, in bits
+		"doorkeeper_size":     len(filter.doorkeeper) * 64,
+		"doorkeeper_set_bits": filter.doorkeeperSetBits(),
+		"sketch_width":        filter.width(),
+		"sketch_bytes":        filter.SketchBytes(),
+	}
+}
+
+// width returns the number of counters per sketch row
+func (filter *FastTinyLFU) width() int {
+	if len(filter.sketch) == 0 {
+		return 0
+	}
+	return len(filter.sketch[0])
+}
+
+// SketchBytes returns the memory held by the sketch counters and the doorkeeper
+func (filter *FastTinyLFU) SketchBytes() int64 {
+	return int64(filter.hashCount*filter.width())*4 + int64(len(filter.doorkeeper))*8
+}
+
+// doorkeeperSetBits counts the bits currently set in the doorkeeper
+func (filter *FastTinyLFU) doorkeeperSetBits() int {
+	count := 0
+	for i := range filter.doorkeeper {
+		count += bits.OnesCount64(atomic.LoadUint64(&filter.doorkeeper[i]))
 	}
+	return count
 }
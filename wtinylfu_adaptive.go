@@ -0,0 +1,108 @@
+// wtinylfu_adaptive.go: Adaptive window sizing for the W-TinyLFU implementation
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import "sync/atomic"
+
+// Adaptive window bounds and step, as fractions of the shard capacity.
+// Shards smaller than adaptiveMinCapacity keep a static split.
+const (
+	adaptiveMinCapacity    = 10
+	adaptiveMinWindowRatio = 0.01
+	adaptiveMaxWindowRatio = 0.80
+	adaptiveStepRatio      = 0.05
+)
+
+// adaptiveWindow samples where a shard's hits land and moves slots towards the
+// segment that earns more hits per slot
+type adaptiveWindow struct {
+	windowHits atomic.Int64
+	mainHits   atomic.Int64
+	accesses   atomic.Int64
+	sampleSize int64
+	minWindow  int
+	maxWindow  int
+	step       int
+}
+
+// newAdaptiveWindow creates the sampling state for a shard of the given capacity
+func newAdaptiveWindow(capacity int) *adaptiveWindow {
+	minWindow := max(1, int(float64(capacity)*adaptiveMinWindowRatio))
+	maxWindow := min(capacity-1, max(minWindow, int(float64(capacity)*adaptiveMaxWindowRatio)))
+	return &adaptiveWindow{
+		sampleSize: int64(max(100, capacity)),
+		minWindow:  minWindow,
+		maxWindow:  maxWindow,
+		step:       max(1, int(float64(capacity)*adaptiveStepRatio)),
+	}
+}
+
+// record counts one Get and which segment, if any, served it
+func (a *adaptiveWindow) record(windowHit, mainHit bool) {
+	if windowHit {
+		a.windowHits.Add(1)
+	} else if mainHit {
+		a.mainHits.Add(1)
+	}
+	a.accesses.Add(1)
+}
+
+// adaptLocked closes the current sample once it is full and shifts a step of slots
+// to the segment with the higher hit rate per slot. The caller must hold writeMu.
+func (shard *WTinyLFUShard) adaptLocked() {
+	a := shard.adaptive
+	if a.accesses.Load() < a.sampleSize {
+		return
+	}
+	a.accesses.Store(0)
+	windowRate := float64(a.windowHits.Swap(0)) / float64(shard.windowSize)
+	mainRate := float64(a.mainHits.Swap(0)) / float64(max(1, shard.mainSize))
+
+	target := shard.windowSize
+	switch {
+	case windowRate > mainRate:
+		target = min(a.maxWindow, shard.windowSize+a.step)
+	case mainRate > windowRate:
+		target = max(a.minWindow, shard.windowSize-a.step)
+	}
+	if target != shard.windowSize {
+		shard.resizeWindowLocked(target)
+	}
+}
+
+// resizeWindowLocked moves the window/main boundary, evicting entries that no longer fit.
+// The caller must hold writeMu.
+func (shard *WTinyLFUShard) resizeWindowLocked(windowSize int) {
+	shard.readMu.Lock()
+	defer shard.readMu.Unlock()
+
+	shard.windowSize = windowSize
+	shard.mainSize = shard.capacity - windowSize
+	shard.windowCache.resize(windowSize)
+	shard.mainCache.resize(shard.mainSize)
+}
+
+// resize changes the capacity of the LRU, evicting the oldest items that no longer fit
+func (lru *FastLRU) resize(maxSize int) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	lru.maxSize = maxSize
+	for lru.maxSize > 0 && lru.cost > int64(lru.maxSize) {
+		if !lru.evictAndRecycleLocked("") {
+			return
+		}
+	}
+}
+
+// resize changes the capacity of both segments, keeping the probation ratio
+// and at least one slot in each (a zero capacity would mean unbounded)
+func (slru *FastSLRU) resize(size int) {
+	probationSize := min(size-1, max(1, int(float64(size)*slru.probationRatio)))
+	slru.probation.resize(probationSize)
+	slru.protected.resize(size - probationSize)
+}
@@ -8,6 +8,7 @@ package metis
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -235,3 +236,207 @@ func TestWTinyLFU_Set_CacheEvictionScenarios(t *testing.T) {
 		}
 	})
 }
+
+// TestFastTinyLFU_DoorkeeperAbsorbsOneHitWonders checks that unique keys never reach the sketch
+func TestFastTinyLFU_DoorkeeperAbsorbsOneHitWonders(t *testing.T) {
+	filter := NewFastTinyLFU(1000)
+
+	for i := 0; i < 2000; i++ {
+		filter.Record(fmt.Sprintf("unique_%d", i))
+	}
+	for i := range filter.sketch {
+		for j, count := range filter.sketch[i] {
+			if count != 0 {
+				t.Fatalf("sketch[%d][%d] = %d after a stream of unique keys", i, j, count)
+			}
+		}
+	}
+	if set := filter.Stats()["doorkeeper_set_bits"].(int); set == 0 {
+		t.Error("expected doorkeeper bits to be set")
+	}
+
+	// The second sighting reaches the sketch
+	filter.Record("repeat")
+	filter.Record("repeat")
+	if freq := filter.Estimate("repeat"); freq < 2 {
+		t.Errorf("expected frequency of at least 2 for a repeated key, got %d", freq)
+	}
+
+	// Unseen keys lose admission to anything the doorkeeper has seen
+	if filter.ShouldAdmit("never_seen", "repeat") {
+		t.Error("expected an unseen key to be rejected against a repeated victim")
+	}
+	if filter.Estimate("never_seen") != 0 {
+		t.Error("expected unseen key to count as frequency 0")
+	}
+}
+
+// TestFastTinyLFU_DoorkeeperClearedOnReset checks the bloom filter ages with the sketch
+func TestFastTinyLFU_DoorkeeperClearedOnReset(t *testing.T) {
+	filter := NewFastTinyLFU(10)
+
+	filter.Record("key")
+	if !filter.doorkeeperContains("key") {
+		t.Fatal("expected key in doorkeeper after first sighting")
+	}
+	filter.reset()
+	if set := filter.Stats()["doorkeeper_set_bits"].(int); set != 0 {
+		t.Errorf("expected empty doorkeeper after reset, got %d bits", set)
+	}
+	if size := filter.Stats()["doorkeeper_size"].(int); size == 0 || size%64 != 0 {
+		t.Errorf("unexpected doorkeeper size %d", size)
+	}
+}
+
+// TestFastTinyLFU_ForgottenKeysKeepAgedCounts checks that only never-seen keys count as 0:
+// a key the doorkeeper forgot at a reset keeps its aged sketch count
+func TestFastTinyLFU_ForgottenKeysKeepAgedCounts(t *testing.T) {
+	filter := NewFastTinyLFU(1000)
+
+	for i := 0; i < 10; i++ {
+		filter.Record("hot")
+	}
+	filter.reset()
+	if filter.doorkeeperContains("hot") {
+		t.Fatal("expected the doorkeeper to be cleared by the reset")
+	}
+	if freq := filter.Estimate("hot"); freq == 0 {
+		t.Error("expected a key forgotten by the doorkeeper to keep its aged count")
+	}
+	if freq := filter.Estimate("never_seen"); freq != 0 {
+		t.Errorf("expected frequency 0 for a never-seen key, got %d", freq)
+	}
+
+	// A scan key seen once since the reset cannot displace the established key
+	filter.Record("scan")
+	if filter.ShouldAdmit("scan", "hot") {
+		t.Error("expected a one-hit key to lose to an established key after a reset")
+	}
+	if filter.ShouldAdmit("never_seen", "scan") {
+		t.Error("expected a never-seen key to lose to a key seen since the reset")
+	}
+}
+
+// TestFastTinyLFU_ConcurrentAccess hammers the sketch from many goroutines; run with -race
+func TestFastTinyLFU_ConcurrentAccess(t *testing.T) {
+	filter := NewFastTinyLFU(64) // Small sketch so aging resets happen during the test
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				key := fmt.Sprintf("key_%d", (id*31+i)%200)
+				filter.Record(key)
+				filter.Estimate(key)
+				filter.ShouldAdmit(key, fmt.Sprintf("key_%d", i%200))
+				if i%1000 == 0 {
+					filter.Stats()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Counters must stay bounded by aging rather than wrap around
+	if freq := filter.Estimate("key_0"); freq > 64*10*2 {
+		t.Errorf("frequency %d not bounded by aging", freq)
+	}
+	if counter := filter.Stats()["counter"].(uint32); counter >= 64*10 {
+		t.Errorf("aging counter %d not reset", counter)
+	}
+}
+
+// TestWTinyLFU_ConcurrentAdmission drives the filter through the cache under concurrent load
+func TestWTinyLFU_ConcurrentAdmission(t *testing.T) {
+	wt := NewWTinyLFU(256, 4)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 3000; i++ {
+				key := fmt.Sprintf("key_%d", (id+i)%1000)
+				if i%3 == 0 {
+					wt.Set(key, i)
+				} else {
+					wt.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if size := wt.Size(); size > wt.MaxSize() {
+		t.Errorf("size %d exceeds capacity %d", size, wt.MaxSize())
+	}
+}
+
+func TestWTinyLFU_HotWindowEntrySurvivesFlood(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+
+	wt.Set("hot", "value")
+	for i := 0; i < 20; i++ {
+		wt.Get("hot")
+	}
+
+	// Flood with one-hit keys, reading hot now and then like a real working set
+	for i := 0; i < 1000; i++ {
+		wt.Set(fmt.Sprintf("flood%d", i), i)
+		if i%50 == 49 {
+			if _, ok := wt.Get("hot"); !ok {
+				t.Fatalf("hot key evicted after %d flood keys", i+1)
+			}
+		}
+	}
+	if wt.shards[0].windowCache.Exists("hot") {
+		t.Error("expected hot key to have left the window for main")
+	}
+}
+
+func TestWTinyLFU_WindowVictimPromotedWhileMainHasRoom(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+	shard := wt.shards[0]
+
+	for i := 0; i < 30; i++ {
+		wt.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if shard.windowCache.Size() != shard.windowSize {
+		t.Errorf("expected a full window of %d, got %d", shard.windowSize, shard.windowCache.Size())
+	}
+	if main := shard.mainCache.Size(); main != 30-shard.windowSize {
+		t.Errorf("expected window victims to move to main, main holds %d", main)
+	}
+	if evictions := shard.Evictions(); evictions != 0 {
+		t.Errorf("expected no evictions while main has room, got %d", evictions)
+	}
+}
+
+func TestWTinyLFU_ColdWindowVictimRejected(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+	shard := wt.shards[0]
+
+	// Fill the window and probation, then make probation's victim frequent
+	probationSize := shard.mainCache.probation.maxSize
+	for i := 0; i < shard.windowSize+probationSize; i++ {
+		wt.Set(fmt.Sprintf("k%d", i), i)
+	}
+	victim := shard.mainCache.probation.oldestKey()
+	for i := 0; i < 5; i++ {
+		shard.admissionFilter.Record(victim)
+	}
+	candidate := shard.windowCache.oldestKey()
+
+	wt.Set("new", "value")
+	if !shard.mainCache.Exists(victim) {
+		t.Errorf("expected frequent probation victim %s to stay in main", victim)
+	}
+	if _, ok := wt.Get(candidate); ok {
+		t.Errorf("expected cold window victim %s to be dropped", candidate)
+	}
+	if evictions := shard.windowCache.Evictions(); evictions != 1 {
+		t.Errorf("expected the rejected window victim to count as 1 eviction, got %d", evictions)
+	}
+}
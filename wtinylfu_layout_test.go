@@ -0,0 +1,253 @@
+// wtinylfu_layout_test.go: Tests for configurable W-TinyLFU segment and sketch sizes
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWTinyLFU_WindowRatio(t *testing.T) {
+	tests := []struct {
+		name       string
+		ratio      float64
+		wantWindow int
+	}{
+		{"default", 0, 10},
+		{"recency heavy", 0.5, 50},
+		{"tiny ratio keeps one slot", 0.001, 1},
+		{"huge ratio keeps main", 0.999, 99},
+		{"invalid falls back to default", 1.5, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt := NewWTinyLFUWithOptions(400, 4, WTinyLFUOptions{WindowRatio: tt.ratio})
+			for i, shard := range wt.shards {
+				if shard.windowSize != tt.wantWindow {
+					t.Errorf("shard %d: expected window %d, got %d", i, tt.wantWindow, shard.windowSize)
+				}
+				if shard.windowSize+shard.mainSize != 100 {
+					t.Errorf("shard %d: window+main = %d, expected 100", i, shard.windowSize+shard.mainSize)
+				}
+			}
+		})
+	}
+}
+
+func TestWTinyLFU_StatsReportShardSizes(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     2,
+		EvictionPolicy: "wtinylfu",
+		WindowRatio:    0.25,
+	})
+	defer cache.Close()
+
+	sizes, ok := cache.wtinylfu.Stats()["shard_sizes"].([]map[string]int)
+	if !ok || len(sizes) != 2 {
+		t.Fatalf("expected shard_sizes for 2 shards, got %v", cache.wtinylfu.Stats()["shard_sizes"])
+	}
+	for i, s := range sizes {
+		if s["window_size"] != 125 || s["main_size"] != 375 {
+			t.Errorf("shard %d: expected 125/375, got %d/%d", i, s["window_size"], s["main_size"])
+		}
+	}
+}
+
+func TestValidateConfig_WindowRatio(t *testing.T) {
+	for _, ratio := range []float64{-0.1, 1, 2} {
+		result := ValidateConfig(CacheConfig{CacheSize: 1000, ShardCount: 1, WindowRatio: ratio})
+		if result.IsValid {
+			t.Errorf("expected WindowRatio %.2f to be invalid", ratio)
+		}
+	}
+	if result := ValidateConfig(CacheConfig{CacheSize: 1000, ShardCount: 1, WindowRatio: 0.2}); !result.IsValid {
+		t.Errorf("expected WindowRatio 0.2 to be valid, warnings: %v", result.Warnings)
+	}
+}
+
+func TestFastSLRU_ProbationRatio(t *testing.T) {
+	slru := NewFastSLRUWithRatio(10, 0.5)
+	if slru.probation.maxSize != 5 || slru.protected.maxSize != 5 {
+		t.Errorf("expected 5/5 split, got %d/%d", slru.probation.maxSize, slru.protected.maxSize)
+	}
+
+	slru = NewFastSLRUWithRatio(10, 0)
+	if slru.probation.maxSize != 8 || slru.protected.maxSize != 2 {
+		t.Errorf("expected default 8/2 split, got %d/%d", slru.probation.maxSize, slru.protected.maxSize)
+	}
+
+	wt := NewWTinyLFUWithOptions(120, 1, WTinyLFUOptions{ProbationRatio: 0.5})
+	if p, q := wt.shards[0].mainCache.probation.maxSize, wt.shards[0].mainCache.protected.maxSize; p != q || p+q != wt.shards[0].mainSize {
+		t.Errorf("expected main segment split evenly, got %d/%d of %d", p, q, wt.shards[0].mainSize)
+	}
+}
+
+// hotKeySurvivesChurn promotes a hot key, then churns other promotions through the
+// protected segment, re-reading the hot key after every few of them
+func hotKeySurvivesChurn(slru *FastSLRU) bool {
+	slru.Set("hot", "value")
+	slru.Get("hot") // Promote to protected
+
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 3; i++ {
+			key := string(rune('a'+i)) + string(rune('a'+round))
+			slru.Set(key, i)
+			slru.Get(key) // Promote, pushing older protected entries out
+		}
+		if _, ok := slru.Get("hot"); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFastSLRU_EvenSplitKeepsHotKeyUnderChurn(t *testing.T) {
+	if hotKeySurvivesChurn(NewFastSLRU(10)) {
+		t.Error("expected the default 2-slot protected segment to lose the hot key")
+	}
+	if !hotKeySurvivesChurn(NewFastSLRUWithRatio(10, 0.5)) {
+		t.Error("expected a 50/50 split to keep the hot key")
+	}
+}
+
+func TestValidateConfig_ProbationRatio(t *testing.T) {
+	for _, ratio := range []float64{-0.5, 1, 3} {
+		result := ValidateConfig(CacheConfig{CacheSize: 1000, ShardCount: 1, ProbationRatio: ratio})
+		if result.IsValid {
+			t.Errorf("expected ProbationRatio %.2f to be invalid", ratio)
+		}
+	}
+	if result := ValidateConfig(CacheConfig{CacheSize: 1000, ShardCount: 1, ProbationRatio: 0.5}); !result.IsValid {
+		t.Errorf("expected ProbationRatio 0.5 to be valid, warnings: %v", result.Warnings)
+	}
+}
+
+func TestWTinyLFU_AdaptiveWindowFollowsWorkload(t *testing.T) {
+	wt := NewWTinyLFUWithOptions(1000, 1, WTinyLFUOptions{AdaptiveWindow: true})
+	shard := wt.shards[0]
+	start := wt.WindowSize()
+
+	// residentKeys lists the keys currently held by a segment
+	residentKeys := func(inSegment func(string) bool, written int) []string {
+		var keys []string
+		for i := 0; i < written; i++ {
+			if key := fmt.Sprintf("key%d", i); inSegment(key) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	written := 0
+	for ; written < 1000; written++ {
+		wt.Set(fmt.Sprintf("key%d", written), written)
+	}
+
+	// Recency-biased: reads go to recently admitted entries, which live in the window
+	for round := 0; round < 20; round++ {
+		recent := residentKeys(shard.windowCache.Exists, written)
+		for i := 0; i < 1000; i++ {
+			wt.Get(recent[i%len(recent)])
+			if i%10 == 0 {
+				wt.Set(fmt.Sprintf("key%d", written), written)
+				written++
+			}
+		}
+	}
+	grown := wt.WindowSize()
+	if grown <= start {
+		t.Fatalf("expected window to grow from %d under a recency workload, got %d", start, grown)
+	}
+	if grown > 800 {
+		t.Errorf("window %d exceeds the 80%% bound", grown)
+	}
+
+	// Frequency-biased: a stable hot set in main is read over and over while new keys keep arriving
+	for round := 0; round < 20; round++ {
+		hot := residentKeys(shard.mainCache.Exists, written)
+		for i := 0; i < 1000; i++ {
+			wt.Get(hot[i%len(hot)])
+			if i%10 == 0 {
+				wt.Set(fmt.Sprintf("key%d", written), written)
+				written++
+			}
+		}
+	}
+	shrunk := wt.WindowSize()
+	if shrunk >= grown {
+		t.Errorf("expected window to shrink from %d under a frequency workload, got %d", grown, shrunk)
+	}
+	if shrunk < 10 {
+		t.Errorf("window %d is below the 1%% bound", shrunk)
+	}
+
+	sizes := wt.Stats()["shard_sizes"].([]map[string]int)
+	if sizes[0]["window_size"] != shrunk || sizes[0]["window_size"]+sizes[0]["main_size"] != 1000 {
+		t.Errorf("unexpected shard sizes in Stats: %v", sizes[0])
+	}
+	if size := wt.Size(); size > 1000 {
+		t.Errorf("cache holds %d entries, more than its capacity", size)
+	}
+}
+
+func TestFastTinyLFU_SketchDimensions(t *testing.T) {
+	def := NewFastTinyLFU(1000)
+	if len(def.sketch) != 4 || len(def.sketch[0]) != 4000 {
+		t.Errorf("expected default 4x4000 sketch, got %dx%d", len(def.sketch), len(def.sketch[0]))
+	}
+	if got, want := def.SketchBytes(), int64(4*4000*4+len(def.doorkeeper)*8); got != want {
+		t.Errorf("expected %d sketch bytes, got %d", want, got)
+	}
+
+	small := NewFastTinyLFUWithDimensions(1000, 2, 512)
+	if len(small.sketch) != 2 || len(small.sketch[0]) != 512 || small.hashCount != 2 {
+		t.Errorf("expected 2x512 sketch, got %dx%d", len(small.sketch), len(small.sketch[0]))
+	}
+	if small.SketchBytes() >= def.SketchBytes() {
+		t.Errorf("expected smaller footprint, got %d vs %d", small.SketchBytes(), def.SketchBytes())
+	}
+	if stats := small.Stats(); stats["sketch_bytes"].(int64) != small.SketchBytes() || stats["sketch_width"].(int) != 512 {
+		t.Errorf("unexpected sketch stats: %v", stats)
+	}
+
+	small.Record("key")
+	small.Record("key")
+	if freq := small.Estimate("key"); freq < 2 {
+		t.Errorf("expected frequency of at least 2, got %d", freq)
+	}
+}
+
+func TestWTinyLFU_SketchDimensionsFromConfig(t *testing.T) {
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      4000,
+		ShardCount:     4,
+		EvictionPolicy: "wtinylfu",
+		SketchDepth:    3,
+		SketchWidth:    256,
+	})
+	defer cache.Close()
+
+	var want int64
+	for _, shard := range cache.wtinylfu.shards {
+		filter := shard.admissionFilter
+		if len(filter.sketch) != 3 || len(filter.sketch[0]) != 256 {
+			t.Errorf("expected 3x256 sketch, got %dx%d", len(filter.sketch), len(filter.sketch[0]))
+		}
+		want += filter.SketchBytes()
+	}
+	if got := cache.wtinylfu.Stats()["sketch_bytes"].(int64); got != want {
+		t.Errorf("expected %d sketch bytes in Stats, got %d", want, got)
+	}
+
+	if result := ValidateConfig(CacheConfig{CacheSize: 1000, ShardCount: 1, SketchWidth: -1}); result.IsValid {
+		t.Error("expected negative SketchWidth to be invalid")
+	}
+}
@@ -0,0 +1,149 @@
+// wtinylfu_ttl_test.go: Tests for TTL expiration in the W-TinyLFU cache
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package metis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/metis/internal/fakeclock"
+)
+
+// newFakeClockWTinyLFU returns a W-TinyLFU cache running on a fake clock
+func newFakeClockWTinyLFU(maxSize, shardCount int, ttl time.Duration) (*WTinyLFU, *fakeclock.Clock) {
+	clock := fakeclock.New(time.Time{})
+	wt := NewWTinyLFU(maxSize, shardCount)
+	wt.setClock(clock)
+	wt.SetTTL(ttl)
+	return wt, clock
+}
+
+func TestWTinyLFU_TTLExpiresEntries(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:  true,
+		CacheSize:      1000,
+		ShardCount:     4,
+		EvictionPolicy: "wtinylfu",
+		TTL:            50 * time.Millisecond,
+		Clock:          clock,
+	})
+	defer cache.Close()
+
+	cache.Set("key", "value")
+	if _, ok := cache.Get("key"); !ok {
+		t.Fatal("expected key to be present before expiry")
+	}
+
+	clock.Advance(80 * time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected key to be gone after its TTL")
+	}
+	stats := cache.GetStats()
+	if stats.Expirations != 1 {
+		t.Errorf("expected 1 expiration, got %d", stats.Expirations)
+	}
+	if stats.Keys != 0 {
+		t.Errorf("expected the expired key to be dropped, got %d keys", stats.Keys)
+	}
+}
+
+func TestWTinyLFU_TTLExpiresInEverySegment(t *testing.T) {
+	wt, clock := newFakeClockWTinyLFU(100, 1, 50*time.Millisecond)
+
+	// Fill past the window so keys land in probation, then promote some to protected
+	for i := 0; i < 50; i++ {
+		wt.Set(fmt.Sprintf("k%d", i), i)
+	}
+	for i := 20; i < 30; i++ {
+		wt.Get(fmt.Sprintf("k%d", i))
+	}
+
+	clock.Advance(80 * time.Millisecond)
+	for i := 0; i < 50; i++ {
+		if _, ok := wt.Get(fmt.Sprintf("k%d", i)); ok {
+			t.Fatalf("expected k%d to be expired", i)
+		}
+	}
+	if wt.Size() != 0 {
+		t.Errorf("expected expired keys to be dropped on access, size %d", wt.Size())
+	}
+}
+
+func TestWTinyLFU_TTLUpdateRefreshesExpiry(t *testing.T) {
+	wt, clock := newFakeClockWTinyLFU(100, 1, 60*time.Millisecond)
+
+	wt.Set("key", 1)
+	clock.Advance(40 * time.Millisecond)
+	wt.Set("key", 2)
+	clock.Advance(40 * time.Millisecond)
+
+	if v, ok := wt.Get("key"); !ok || v != 2 {
+		t.Errorf("expected updated key to outlive the original TTL, got %v, %v", v, ok)
+	}
+}
+
+func TestWTinyLFU_RemoveExpiredSweepsUnreadKeys(t *testing.T) {
+	wt, clock := newFakeClockWTinyLFU(100, 2, 30*time.Millisecond)
+	for i := 0; i < 40; i++ {
+		wt.Set(fmt.Sprintf("k%d", i), i)
+	}
+	size := wt.Size()
+
+	clock.Advance(50 * time.Millisecond)
+	wt.Set("fresh", "value")
+
+	if removed := wt.RemoveExpired(); removed != size {
+		t.Errorf("expected the sweep to remove %d keys, removed %d", size, removed)
+	}
+	if wt.Size() != 1 {
+		t.Errorf("expected only the fresh key to remain, size %d", wt.Size())
+	}
+	if stats := wt.GetStats(); stats.Expirations != int64(size) {
+		t.Errorf("expected %d expirations, got %d", size, stats.Expirations)
+	}
+}
+
+func TestWTinyLFU_NoTTLNeverExpires(t *testing.T) {
+	wt := NewWTinyLFU(100, 1)
+	wt.Set("key", "value")
+	if removed := wt.RemoveExpired(); removed != 0 {
+		t.Errorf("expected nothing to expire without a TTL, removed %d", removed)
+	}
+	if _, ok := wt.Get("key"); !ok {
+		t.Error("expected key to be present without a TTL")
+	}
+}
+
+func TestStrategicCache_WTinyLFUBackgroundSweep(t *testing.T) {
+	clock := fakeclock.New(time.Time{})
+	cache := NewStrategicCache(CacheConfig{
+		EnableCaching:   true,
+		CacheSize:       1000,
+		ShardCount:      2,
+		EvictionPolicy:  "wtinylfu",
+		TTL:             20 * time.Millisecond,
+		CleanupInterval: 10 * time.Millisecond,
+		Clock:           clock,
+	})
+	defer cache.Close()
+
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	// The sweep runs on the cleanup goroutine, so keep ticking until it catches up
+	deadline := time.Now().Add(time.Second)
+	for cache.GetStats().Keys > 0 && time.Now().Before(deadline) {
+		clock.Advance(10 * time.Millisecond)
+		time.Sleep(time.Millisecond)
+	}
+	if keys := cache.GetStats().Keys; keys != 0 {
+		t.Errorf("expected the background sweep to drop unread keys, %d remain", keys)
+	}
+}